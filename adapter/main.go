@@ -25,30 +25,25 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
-	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	openapinamer "k8s.io/apiserver/pkg/endpoints/openapi"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	basecmd "sigs.k8s.io/custom-metrics-apiserver/pkg/cmd"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
 
 	generatedopenapi "github.com/kedacore/keda/v2/adapter/generated/openapi"
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
-	kedacontrollers "github.com/kedacore/keda/v2/controllers/keda"
 	prommetrics "github.com/kedacore/keda/v2/pkg/metrics"
+	"github.com/kedacore/keda/v2/pkg/metricsservice"
 	kedaprovider "github.com/kedacore/keda/v2/pkg/provider"
-	"github.com/kedacore/keda/v2/pkg/scaling"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	"github.com/kedacore/keda/v2/version"
 )
 
@@ -58,6 +53,8 @@ type Adapter struct {
 
 	// Message is printed on successful startup
 	Message string
+
+	grpcClient *metricsservice.GrpcClient
 }
 
 var logger = klogr.New().WithName("keda_metrics_adapter")
@@ -67,9 +64,14 @@ var (
 	prometheusMetricsPath     string
 	adapterClientRequestQPS   float32
 	adapterClientRequestBurst int
+	shardLabelSelector        string
+	metricsServiceAddr        string
+	metricsServiceCAFile      string
+	metricsServiceStaleTTL    time.Duration
+	metricsServiceRefresh     time.Duration
 )
 
-func (a *Adapter) makeProvider(ctx context.Context, globalHTTPTimeout time.Duration) (provider.MetricsProvider, <-chan struct{}, error) {
+func (a *Adapter) makeProvider(ctx context.Context, globalHTTPTimeout time.Duration) (provider.MetricsProvider, error) {
 	// Get a config to talk to the apiserver
 	cfg, err := config.GetConfig()
 	if cfg != nil {
@@ -79,17 +81,17 @@ func (a *Adapter) makeProvider(ctx context.Context, globalHTTPTimeout time.Durat
 
 	if err != nil {
 		logger.Error(err, "failed to get the config")
-		return nil, nil, fmt.Errorf("failed to get the config (%s)", err)
+		return nil, fmt.Errorf("failed to get the config (%s)", err)
 	}
 
 	scheme := scheme.Scheme
 	if err := appsv1.SchemeBuilder.AddToScheme(scheme); err != nil {
 		logger.Error(err, "failed to add apps/v1 scheme to runtime scheme")
-		return nil, nil, fmt.Errorf("failed to add apps/v1 scheme to runtime scheme (%s)", err)
+		return nil, fmt.Errorf("failed to add apps/v1 scheme to runtime scheme (%s)", err)
 	}
 	if err := kedav1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
 		logger.Error(err, "failed to add keda scheme to runtime scheme")
-		return nil, nil, fmt.Errorf("failed to add keda scheme to runtime scheme (%s)", err)
+		return nil, fmt.Errorf("failed to add keda scheme to runtime scheme (%s)", err)
 	}
 
 	kubeclient, err := client.New(cfg, client.Options{
@@ -97,53 +99,36 @@ func (a *Adapter) makeProvider(ctx context.Context, globalHTTPTimeout time.Durat
 	})
 	if err != nil {
 		logger.Error(err, "unable to construct new client")
-		return nil, nil, fmt.Errorf("unable to construct new client (%s)", err)
+		return nil, fmt.Errorf("unable to construct new client (%s)", err)
 	}
 
-	broadcaster := record.NewBroadcaster()
-	recorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: "keda-metrics-adapter"})
-	handler := scaling.NewScaleHandler(kubeclient, nil, scheme, globalHTTPTimeout, recorder)
-
 	namespace, err := getWatchNamespace()
 	if err != nil {
 		logger.Error(err, "failed to get watch namespace")
-		return nil, nil, fmt.Errorf("failed to get watch namespace (%s)", err)
+		return nil, fmt.Errorf("failed to get watch namespace (%s)", err)
 	}
 
-	prometheusServer := &prommetrics.PrometheusMetricServer{}
-	go func() { prometheusServer.NewServer(fmt.Sprintf(":%v", prometheusMetricsPort), prometheusMetricsPath) }()
-	stopCh := make(chan struct{})
-	if err := runScaledObjectController(ctx, scheme, namespace, handler, logger, stopCh); err != nil {
-		return nil, nil, err
+	shardSelector, err := kedautil.ParseShardSelector(shardLabelSelector)
+	if err != nil {
+		logger.Error(err, "invalid shard label selector")
+		return nil, fmt.Errorf("invalid shard label selector (%s)", err)
 	}
 
-	return kedaprovider.NewProvider(ctx, logger, handler, kubeclient, namespace), stopCh, nil
-}
+	prometheusServer := &prommetrics.PrometheusMetricServer{}
+	go func() { prometheusServer.NewServer(fmt.Sprintf(":%v", prometheusMetricsPort), prometheusMetricsPath) }()
 
-func runScaledObjectController(ctx context.Context, scheme *k8sruntime.Scheme, namespace string, scaleHandler scaling.ScaleHandler, logger logr.Logger, stopCh chan<- struct{}) error {
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:    scheme,
-		Namespace: namespace,
-	})
+	// the metric values are fetched from the KEDA Operator, the adapter doesn't instantiate any scalers
+	grpcClient, err := metricsservice.NewGrpcClient(metricsServiceAddr, metricsServiceCAFile, globalHTTPTimeout, metricsServiceStaleTTL, metricsServiceRefresh)
 	if err != nil {
-		return err
-	}
-
-	if err := (&kedacontrollers.MetricsScaledObjectReconciler{
-		ScaleHandler: scaleHandler,
-	}).SetupWithManager(mgr, controller.Options{}); err != nil {
-		return err
+		logger.Error(err, "unable to connect to KEDA Operator Metrics Service")
+		return nil, fmt.Errorf("unable to connect to KEDA Operator Metrics Service (%s)", err)
 	}
+	// the metrics are refreshed in the background so they are served while the KEDA Operator fails over, and the
+	// stale ones are dropped
+	go grpcClient.Run(ctx)
+	a.grpcClient = grpcClient
 
-	go func() {
-		if err := mgr.Start(ctx); err != nil {
-			logger.Error(err, "controller-runtime encountered an error")
-			stopCh <- struct{}{}
-			close(stopCh)
-		}
-	}()
-
-	return nil
+	return kedaprovider.NewGrpcProvider(ctx, logger, kubeclient, grpcClient, namespace, shardSelector), nil
 }
 
 func printVersion() {
@@ -188,6 +173,11 @@ func main() {
 	cmd.Flags().StringVar(&prometheusMetricsPath, "metrics-path", "/metrics", "Set the path for the prometheus metrics endpoint")
 	cmd.Flags().Float32Var(&adapterClientRequestQPS, "kube-api-qps", 20.0, "Set the QPS rate for throttling requests sent to the apiserver")
 	cmd.Flags().IntVar(&adapterClientRequestBurst, "kube-api-burst", 30, "Set the burst for throttling requests sent to the apiserver")
+	cmd.Flags().StringVar(&shardLabelSelector, "shard-label-selector", "", "Set the label selector of the ScaledObjects served by this adapter, it should match the selector of the operator shard")
+	cmd.Flags().StringVar(&metricsServiceAddr, "metrics-service-address", "keda-operator.keda.svc.cluster.local:9666", "Set the address of the KEDA Operator Metrics Service the metric values are fetched from")
+	cmd.Flags().StringVar(&metricsServiceCAFile, "metrics-service-ca-file", "", "Set the CA the TLS certificate of the KEDA Operator Metrics Service is verified with, the connection doesn't use TLS if empty and the metric values travel in plaintext")
+	cmd.Flags().DurationVar(&metricsServiceStaleTTL, "metrics-service-stale-ttl", time.Minute, "Set how long the last known metric values are served if the KEDA Operator Metrics Service is unavailable")
+	cmd.Flags().DurationVar(&metricsServiceRefresh, "metrics-service-refresh-interval", 10*time.Second, "Set how often the served metric values are refreshed from the KEDA Operator Metrics Service in the background, 0 fetches them on every request")
 	if err := cmd.Flags().Parse(os.Args); err != nil {
 		return
 	}
@@ -206,15 +196,17 @@ func main() {
 		return
 	}
 
-	kedaProvider, stopCh, err := cmd.makeProvider(ctx, time.Duration(globalHTTPTimeoutMS)*time.Millisecond)
+	kedaProvider, err := cmd.makeProvider(ctx, time.Duration(globalHTTPTimeoutMS)*time.Millisecond)
 	if err != nil {
 		logger.Error(err, "making provider")
 		return
 	}
 	cmd.WithExternalMetrics(kedaProvider)
+	// the connection to the KEDA Operator is closed once the requests are drained
+	defer cmd.grpcClient.Close()
 
 	logger.Info(cmd.Message)
-	if err = cmd.Run(stopCh); err != nil {
+	if err = cmd.Run(ctx.Done()); err != nil {
 		return
 	}
 }
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterTriggerTemplate defines a reusable trigger that triggers reference with the values of its parameters
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:resource:path=clustertriggertemplates,scope=Cluster,shortName=ctt;clustertriggertemplate
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="Parameters",type="string",JSONPath=".spec.parameters[*].name"
+type ClusterTriggerTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterTriggerTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterTriggerTemplateList contains a list of ClusterTriggerTemplate
+type ClusterTriggerTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []ClusterTriggerTemplate `json:"items"`
+}
+
+// ClusterTriggerTemplateSpec is the trigger block of the template, the metadata values can hold ${parameter}
+// placeholders replaced with the values given by the triggers
+type ClusterTriggerTemplateSpec struct {
+	Type string `json:"type"`
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// +optional
+	Parameters []TriggerTemplateParameter `json:"parameters,omitempty"`
+	// AuthenticationRef is used by the triggers that don't set their own
+	// +optional
+	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
+}
+
+// TriggerTemplateParameter is a parameter of a ClusterTriggerTemplate, it is required unless it has a default
+type TriggerTemplateParameter struct {
+	Name string `json:"name"`
+	// +optional
+	Description string `json:"description,omitempty"`
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// TriggerTemplateRef points to the ClusterTriggerTemplate of a trigger
+type TriggerTemplateRef struct {
+	Name string `json:"name"`
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterTriggerTemplate{}, &ClusterTriggerTemplateList{})
+}
@@ -32,6 +32,9 @@ const (
 	ConditionActive ConditionType = "Active"
 	// ConditionFallback specifies that the resource has a fallback active.
 	ConditionFallback ConditionType = "Fallback"
+	// ConditionPaused specifies that the scaling of the resource is paused.
+	// It is added only to resources which were paused.
+	ConditionPaused ConditionType = "Paused"
 )
 
 // Condition to store the condition state
@@ -140,6 +143,17 @@ func (c *Conditions) SetFallbackCondition(status metav1.ConditionStatus, reason
 	c.setCondition(ConditionFallback, status, reason, message)
 }
 
+// SetPausedCondition modifies Paused Condition according to input parameters, the condition is added if it is missing
+func (c *Conditions) SetPausedCondition(status metav1.ConditionStatus, reason string, message string) {
+	if *c == nil {
+		*c = *GetInitializedConditions()
+	}
+	if c.getCondition(ConditionPaused).Type == "" {
+		*c = append(*c, Condition{Type: ConditionPaused})
+	}
+	c.setCondition(ConditionPaused, status, reason, message)
+}
+
 // GetActiveCondition returns Condition of type Active
 func (c *Conditions) GetActiveCondition() Condition {
 	if *c == nil {
@@ -164,6 +178,14 @@ func (c *Conditions) GetFallbackCondition() Condition {
 	return c.getCondition(ConditionFallback)
 }
 
+// GetPausedCondition returns Condition of type Paused
+func (c *Conditions) GetPausedCondition() Condition {
+	if *c == nil {
+		c = GetInitializedConditions()
+	}
+	return c.getCondition(ConditionPaused)
+}
+
 func (c Conditions) getCondition(conditionType ConditionType) Condition {
 	for i := range c {
 		if c[i].Type == conditionType {
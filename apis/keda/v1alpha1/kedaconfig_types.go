@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KedaConfig defines the defaults and the limits of the ScaledObjects and ScaledJobs of its namespace,
+// its limits can only tighten the limits of the ClusterKedaConfigs
+// +genclient
+// +kubebuilder:resource:path=kedaconfigs,scope=Namespaced,shortName=kc
+// +kubebuilder:printcolumn:name="MaxReplicas",type="integer",JSONPath=".spec.limits.maxReplicaCount"
+// +kubebuilder:printcolumn:name="MinPollingInterval",type="integer",JSONPath=".spec.limits.minPollingInterval"
+// +kubebuilder:printcolumn:name="BannedTypes",type="string",JSONPath=".spec.limits.bannedScalerTypes"
+type KedaConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KedaConfigSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KedaConfigList contains a list of KedaConfig
+type KedaConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []KedaConfig `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterKedaConfig defines the defaults and the limits of the ScaledObjects and ScaledJobs of all the namespaces
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:resource:path=clusterkedaconfigs,scope=Cluster,shortName=ckc
+// +kubebuilder:printcolumn:name="MaxReplicas",type="integer",JSONPath=".spec.limits.maxReplicaCount"
+// +kubebuilder:printcolumn:name="MinPollingInterval",type="integer",JSONPath=".spec.limits.minPollingInterval"
+// +kubebuilder:printcolumn:name="BannedTypes",type="string",JSONPath=".spec.limits.bannedScalerTypes"
+type ClusterKedaConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KedaConfigSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterKedaConfigList contains a list of ClusterKedaConfig
+type ClusterKedaConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []ClusterKedaConfig `json:"items"`
+}
+
+// KedaConfigSpec is the spec of KedaConfig and ClusterKedaConfig
+type KedaConfigSpec struct {
+	// Defaults are used by the ScaledObjects and ScaledJobs which don't set the fields
+	// +optional
+	Defaults *KedaConfigDefaults `json:"defaults,omitempty"`
+	// Limits are enforced on the ScaledObjects and ScaledJobs, the ones breaking them aren't scaled
+	// +optional
+	Limits *KedaConfigLimits `json:"limits,omitempty"`
+}
+
+// KedaConfigDefaults are the default values of the fields of the ScaledObjects and ScaledJobs
+type KedaConfigDefaults struct {
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+	// CooldownPeriod is only used by the ScaledObjects
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+	// +optional
+	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
+}
+
+// KedaConfigLimits are the limits of the ScaledObjects and ScaledJobs, the most restrictive of the KedaConfigs and
+// ClusterKedaConfigs applies
+type KedaConfigLimits struct {
+	// MaxReplicaCount is the highest maxReplicaCount allowed
+	// +optional
+	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
+	// MinPollingInterval is the lowest pollingInterval allowed, in seconds
+	// +optional
+	MinPollingInterval *int32 `json:"minPollingInterval,omitempty"`
+	// BannedScalerTypes are the trigger types which can't be used
+	// +optional
+	BannedScalerTypes []string `json:"bannedScalerTypes,omitempty"`
+	// RequiredAuthenticationProviders are the only providers the TriggerAuthentications and
+	// ClusterTriggerAuthentications referenced by the triggers can read the credentials from
+	// +optional
+	RequiredAuthenticationProviders []AuthenticationProvider `json:"requiredAuthenticationProviders,omitempty"`
+}
+
+// AuthenticationProvider is a source of credentials of a TriggerAuthentication
+// +kubebuilder:validation:Enum=podIdentity;secretTargetRef;env;hashiCorpVault;ldap
+type AuthenticationProvider string
+
+// AuthenticationProvider<SOURCE> is the field of the TriggerAuthenticationSpec reading the credentials from SOURCE
+const (
+	AuthenticationProviderPodIdentity     AuthenticationProvider = "podIdentity"
+	AuthenticationProviderSecretTargetRef AuthenticationProvider = "secretTargetRef"
+	AuthenticationProviderEnv             AuthenticationProvider = "env"
+	AuthenticationProviderHashiCorpVault  AuthenticationProvider = "hashiCorpVault"
+	AuthenticationProviderLDAP            AuthenticationProvider = "ldap"
+)
+
+// GetProviders returns the providers the TriggerAuthenticationSpec reads credentials from
+func (spec *TriggerAuthenticationSpec) GetProviders() []AuthenticationProvider {
+	var providers []AuthenticationProvider
+	if spec.PodIdentity != nil && spec.PodIdentity.Provider != "" && spec.PodIdentity.Provider != PodIdentityProviderNone {
+		providers = append(providers, AuthenticationProviderPodIdentity)
+	}
+	if len(spec.SecretTargetRef) > 0 {
+		providers = append(providers, AuthenticationProviderSecretTargetRef)
+	}
+	if len(spec.Env) > 0 {
+		providers = append(providers, AuthenticationProviderEnv)
+	}
+	if spec.HashiCorpVault != nil {
+		providers = append(providers, AuthenticationProviderHashiCorpVault)
+	}
+	if spec.LDAP != nil {
+		providers = append(providers, AuthenticationProviderLDAP)
+	}
+	return providers
+}
+
+func init() {
+	SchemeBuilder.Register(&KedaConfig{}, &KedaConfigList{}, &ClusterKedaConfig{}, &ClusterKedaConfigList{})
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,11 +57,51 @@ type ScaledJobSpec struct {
 	EnvSourceContainerName string `json:"envSourceContainerName,omitempty"`
 	// +optional
 	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
+	// MinReplicaCount is the number of Jobs kept running even if the triggers are not active,
+	// finished Jobs are replaced by new ones
+	// +optional
+	MinReplicaCount *int32 `json:"minReplicaCount,omitempty"`
 	// +optional
 	ScalingStrategy ScalingStrategy `json:"scalingStrategy,omitempty"`
-	Triggers        []ScaleTriggers `json:"triggers"`
+	// UrgencyTiers override the scheduling settings of the Jobs created while the queue of a trigger reaches the
+	// threshold of a tier, eg. a higher priority class once the backlog is older than an hour
+	// +optional
+	UrgencyTiers []UrgencyTier   `json:"urgencyTiers,omitempty"`
+	Triggers     []ScaleTriggers `json:"triggers"`
+}
+
+// UrgencyTier overrides the scheduling settings of the Jobs created while the queue length of Trigger reaches
+// Threshold, the tier with the highest threshold reached applies
+type UrgencyTier struct {
+	// Name of the tier, the Jobs and their Pods are labeled with UrgencyTierLabel
+	Name string `json:"name"`
+	// Trigger is the name of the trigger compared to Threshold, defaults to any of the triggers
+	// +optional
+	Trigger string `json:"trigger,omitempty"`
+	// Threshold is the queue length of the trigger from which the tier applies, it is the metric value of the
+	// trigger, eg. the age of the backlog in seconds
+	// +kubebuilder:validation:Minimum=0
+	Threshold int64 `json:"threshold"`
+	// PriorityClassName replaces the priority class of the Pods of the Jobs
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// PreemptionPolicy replaces the preemption policy of the Pods of the Jobs, Never or PreemptLowerPriority
+	// +optional
+	PreemptionPolicy *corev1.PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	// TTLSecondsAfterFinished replaces the time the finished Jobs are kept before they are deleted
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+	// SafeToEvict sets the cluster-autoscaler.kubernetes.io/safe-to-evict annotation of the Pods of the Jobs,
+	// false keeps the node autoscalers from disrupting the urgent Jobs
+	// +optional
+	SafeToEvict *bool `json:"safeToEvict,omitempty"`
 }
 
+// UrgencyTierLabel is the label of the Jobs created by a ScaledJob and their Pods set to the name of the
+// UrgencyTier they were created with
+const UrgencyTierLabel = "scaledjob.keda.sh/urgency-tier"
+
 // ScaledJobStatus defines the observed state of ScaledJob
 // +optional
 type ScaledJobStatus struct {
@@ -91,8 +132,30 @@ type ScalingStrategy struct {
 	PendingPodConditions []string `json:"pendingPodConditions,omitempty"`
 	// +optional
 	MultipleScalersCalculation string `json:"multipleScalersCalculation,omitempty"`
+	// TargetDrainTime is the time in seconds in which the drain strategy plans to process the queue, defaults to 300
+	// +optional
+	TargetDrainTime *int32 `json:"targetDrainTime,omitempty"`
+	// ShareGroup is the name of the group of ScaledJobs of the namespace consuming the same queue, the queue is shared
+	// between them in proportion to their Weight instead of each of them creating Jobs for the whole queue
+	// +optional
+	ShareGroup string `json:"shareGroup,omitempty"`
+	// Weight is the share of the queue of the ScaledJob in its ShareGroup, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// ShareWeight returns the Weight of the ScaledJob in its ShareGroup
+func (s ScalingStrategy) ShareWeight() int64 {
+	if s.Weight != nil && *s.Weight > 0 {
+		return int64(*s.Weight)
+	}
+	return 1
 }
 
+// PausedAnnotation set to "true" stops the scaling of a ScaledJob, running Jobs are left untouched
+const PausedAnnotation = "autoscaling.keda.sh/paused"
+
 func init() {
 	SchemeBuilder.Register(&ScaledJob{}, &ScaledJobList{})
 }
@@ -105,3 +168,17 @@ func (s ScaledJob) MaxReplicaCount() int64 {
 
 	return 100
 }
+
+// MinReplicaCount returns MinReplicaCount
+func (s ScaledJob) MinReplicaCount() int64 {
+	if s.Spec.MinReplicaCount != nil {
+		return int64(*s.Spec.MinReplicaCount)
+	}
+
+	return 0
+}
+
+// IsPaused returns true if the ScaledJob doesn't create new Jobs
+func (s *ScaledJob) IsPaused() bool {
+	return s.Annotations[PausedAnnotation] == "true"
+}
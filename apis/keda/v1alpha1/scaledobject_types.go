@@ -17,7 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -63,15 +67,42 @@ const (
 
 	// HealthStatusFailing means the status of the health object is failing
 	HealthStatusFailing HealthStatusType = "Failing"
+
+	// HealthStatusStale means the trigger didn't answer within the latency budget and its last value is used
+	HealthStatusStale HealthStatusType = "Stale"
 )
 
+// ScaledObjectTransferHpaOwnershipAnnotation is the annotation with the name of an existing HPA,
+// the ScaledObject adopts the HPA instead of creating a new one
+const ScaledObjectTransferHpaOwnershipAnnotation = "scaledobject.keda.sh/transfer-hpa-ownership"
+
+// ScaleTargetAllowedNamespacesAnnotation on a Namespace is the comma separated list of the namespaces whose
+// ScaledObjects may scale the workloads of the Namespace with a scaleTargetRef naming it, "*" allows all of them
+const ScaleTargetAllowedNamespacesAnnotation = "keda.sh/allowed-scaledobject-namespaces"
+
+// ScaledObjectNamespaceLabel is set on the HPA and in the metric selectors of a ScaledObject scaling a workload of
+// another namespace, the HPA is created in the namespace of the workload
+const ScaledObjectNamespaceLabel = "scaledobject.keda.sh/namespace"
+
+// PodBusyAnnotation set to "true" on a pod of the scale target delays its scale down to idleReplicaCount or
+// minReplicaCount, eg. while a long-running message handler finishes
+const PodBusyAnnotation = "keda.sh/busy"
+
 // ScaledObjectSpec is the spec for a ScaledObject resource
 type ScaledObjectSpec struct {
 	ScaleTargetRef *ScaleTarget `json:"scaleTargetRef"`
+	// AdditionalScaleTargets follow the replica count of the scaleTargetRef, they are scaled by KEDA
+	// every polling interval while the HPA only scales the scaleTargetRef
+	// +optional
+	AdditionalScaleTargets []AdditionalScaleTarget `json:"additionalScaleTargets,omitempty"`
 	// +optional
 	PollingInterval *int32 `json:"pollingInterval,omitempty"`
 	// +optional
 	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+	// ScaleToZeroGracePeriod is how long the triggers have to be inactive before the scale target is scaled to zero
+	// or to idleReplicaCount, when it is set the cooldownPeriod is the scale down stabilization window of the HPA
+	// +optional
+	ScaleToZeroGracePeriod *int32 `json:"scaleToZeroGracePeriod,omitempty"`
 	// +optional
 	IdleReplicaCount *int32 `json:"idleReplicaCount,omitempty"`
 	// +optional
@@ -84,12 +115,138 @@ type ScaledObjectSpec struct {
 	Triggers []ScaleTriggers `json:"triggers"`
 	// +optional
 	Fallback *Fallback `json:"fallback,omitempty"`
+	// Schedules override the replica bounds of the ScaledObject during time windows,
+	// the triggers still scale the target within the overridden bounds
+	// +optional
+	Schedules []ScheduleWindow `json:"schedules,omitempty"`
+	// Budget caps the replicas of the scale target to control its cost, it is applied after the schedules
+	// +optional
+	Budget *Budget `json:"budget,omitempty"`
+	// Shadow evaluates candidate triggers next to the triggers without scaling on them, the replica counts of both
+	// are recorded in the status so the candidate triggers can be compared before they are promoted to triggers
+	// +optional
+	Shadow *ShadowTriggers `json:"shadow,omitempty"`
+}
+
+// ShadowTriggers are evaluated for DurationSeconds after the last change of the ScaledObject
+type ShadowTriggers struct {
+	Triggers []ScaleTriggers `json:"triggers"`
+	// DurationSeconds is how long the shadow triggers are evaluated, defaults to 86400 (a day)
+	// +optional
+	DurationSeconds *int32 `json:"durationSeconds,omitempty"`
+}
+
+// ShadowStatus compares the replica counts of the shadow triggers with the ones of the triggers
+type ShadowStatus struct {
+	// ObservedGeneration of the ScaledObject the shadow triggers are evaluated for, the evaluation restarts
+	// when the ScaledObject changes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// ReplicaCount is the last replica count computed from the shadow triggers
+	// +optional
+	ReplicaCount *int32 `json:"replicaCount,omitempty"`
+	// LiveReplicaCount is the replica count computed from the triggers at the same time
+	// +optional
+	LiveReplicaCount *int32 `json:"liveReplicaCount,omitempty"`
+	// MaxReplicaDifference is the largest difference between the two replica counts during the evaluation
+	// +optional
+	MaxReplicaDifference int32 `json:"maxReplicaDifference,omitempty"`
+	// Completed is set once the shadow triggers were evaluated for DurationSeconds
+	// +optional
+	Completed bool `json:"completed,omitempty"`
+}
+
+// Budget caps the MaxReplicaCount of the ScaledObject, the lowest cap wins and it never goes below MinReplicaCount
+type Budget struct {
+	// MaxReplicaHoursPerDay is the replica-hours the scale target can consume per day,
+	// once they are spent the replicas are capped at MinReplicaCount (at least 1) until the end of the day
+	// +optional
+	MaxReplicaHoursPerDay *int32 `json:"maxReplicaHoursPerDay,omitempty"`
+	// Timezone of the day of MaxReplicaHoursPerDay in the IANA Time Zone Database format, defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// PricingWindows cap the replicas between their Start and End, eg. during the peak pricing hours
+	// +optional
+	PricingWindows []PricingWindow `json:"pricingWindows,omitempty"`
+}
+
+// PricingWindow caps the replicas at MaxReplicaCount between Start and End
+type PricingWindow struct {
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Timezone of Start and End in the IANA Time Zone Database format, defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// Start is a cron expression of the window start, eg. `0 17 * * 1-5`
+	Start string `json:"start"`
+	// End is a cron expression of the window end, eg. `0 21 * * 1-5`
+	End             string `json:"end"`
+	MaxReplicaCount int32  `json:"maxReplicaCount"`
+}
+
+// BudgetStatus is the consumption of the Budget of the ScaledObject
+type BudgetStatus struct {
+	// Day of the consumed replica-hours in the timezone of the Budget, eg. 2021-12-20
+	// +optional
+	Day string `json:"day,omitempty"`
+	// ReplicaSeconds consumed by the scale target during Day
+	// +optional
+	ReplicaSeconds int64 `json:"replicaSeconds,omitempty"`
+	// LastUpdateTime is when ReplicaSeconds was last recorded, unset while the scale target has no replicas
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// ClampedReplicaCount is the replica count the Budget caps the scale target at, unset when it doesn't clamp it
+	// +optional
+	ClampedReplicaCount *int32 `json:"clampedReplicaCount,omitempty"`
+}
+
+// ScheduleWindow overrides MinReplicaCount and MaxReplicaCount between Start and End
+type ScheduleWindow struct {
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Timezone of Start and End in the IANA Time Zone Database format, defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// Start is a cron expression of the window start, eg. `0 8 * * 1-5`
+	Start string `json:"start"`
+	// End is a cron expression of the window end, eg. `0 18 * * 1-5`
+	End string `json:"end"`
+	// +optional
+	MinReplicaCount *int32 `json:"minReplicaCount,omitempty"`
+	// +optional
+	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
 }
 
 // Fallback is the spec for fallback options
 type Fallback struct {
 	FailureThreshold int32 `json:"failureThreshold"`
-	Replicas         int32 `json:"replicas"`
+	// Replicas is the replica count of the static behavior
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// Behavior is static (default) to fall back on Replicas or currentReplicasIfError
+	// to keep the current replica count of the scale target while the triggers are failing
+	// +kubebuilder:validation:Enum=static;currentReplicasIfError
+	// +optional
+	Behavior FallbackBehavior `json:"behavior,omitempty"`
+}
+
+// FallbackBehavior specifies the replica count used when the triggers are failing
+type FallbackBehavior string
+
+const (
+	// FallbackBehaviorStatic falls back on the Replicas of the Fallback
+	FallbackBehaviorStatic FallbackBehavior = "static"
+	// FallbackBehaviorCurrentReplicasIfError freezes the replica count of the scale target
+	FallbackBehaviorCurrentReplicasIfError FallbackBehavior = "currentReplicasIfError"
+)
+
+// IsCurrentReplicasIfError returns true if the fallback keeps the current replica count
+func (f *Fallback) IsCurrentReplicasIfError() bool {
+	return f != nil && f.Behavior == FallbackBehaviorCurrentReplicasIfError
 }
 
 // AdvancedConfig specifies advance scaling options
@@ -98,17 +255,281 @@ type AdvancedConfig struct {
 	HorizontalPodAutoscalerConfig *HorizontalPodAutoscalerConfig `json:"horizontalPodAutoscalerConfig,omitempty"`
 	// +optional
 	RestoreToOriginalReplicaCount bool `json:"restoreToOriginalReplicaCount,omitempty"`
+	// DryRun enables evaluation of triggers without scaling, the desired replica count is only recorded in the status
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// ActivationStrategy specifies which triggers have to be active to activate the scale target:
+	// any (default), all or a boolean expression over the trigger names, eg. `queue && businessHours`
+	// +optional
+	ActivationStrategy string `json:"activationStrategy,omitempty"`
+	// OnDelete specifies the replica count of the scale target after the ScaledObject is deleted,
+	// it takes precedence over RestoreToOriginalReplicaCount
+	// +optional
+	OnDelete *OnDeletePolicy `json:"onDelete,omitempty"`
+	// ScalingHooks are webhooks called around the activation and the deactivation of the scale target
+	// +optional
+	ScalingHooks *ScalingHooks `json:"scalingHooks,omitempty"`
+	// MaxReplicaFromPartitions caps the maxReplicas of the HPA at the partition count of the partitioned triggers
+	// (eg. Kafka, Event Hubs) discovered at runtime, consumers beyond the partition count would be idle
+	// +optional
+	MaxReplicaFromPartitions bool `json:"maxReplicaFromPartitions,omitempty"`
+	// CapacityCap caps the maxReplicas of the HPA at the replicas the matching nodes can place, eg. the GPUs of
+	// the cluster or its Windows nodes, the replicas beyond it would stay pending
+	// +optional
+	CapacityCap *CapacityCap `json:"capacityCap,omitempty"`
+	// BusyPodsTimeoutSeconds is how long the scale down waits for the pods annotated with keda.sh/busy
+	// after the cooldown period, defaults to 600
+	// +optional
+	BusyPodsTimeoutSeconds *int32 `json:"busyPodsTimeoutSeconds,omitempty"`
+	// LatencyBudgetMilliseconds is how long the metrics of a trigger are waited for when the HPA requests them,
+	// a trigger answering later gets its last value, marked Stale in the health status, disabled if not set
+	// +optional
+	LatencyBudgetMilliseconds *int32 `json:"latencyBudgetMilliseconds,omitempty"`
+	// PreProvisioning signals the node autoscalers (eg. Karpenter, cluster-autoscaler) ahead of the scale up
+	// of the scale target, so the nodes are provisioned before the replicas are raised
+	// +optional
+	PreProvisioning *PreProvisioning `json:"preProvisioning,omitempty"`
+	// Tolerance is the hysteresis band around the target of the metrics, eg. 0.2, the HPA keeps the replica count
+	// while the usage ratio of the metrics is within the band, it overrides the HPA tolerance of 0.1
+	// +optional
+	Tolerance *resource.Quantity `json:"tolerance,omitempty"`
+	// Draining delays the scale downs of KEDA (to idleReplicaCount or minReplicaCount) until the pods to remove
+	// are drained, eg. the sessions or the websockets they serve are closed
+	// +optional
+	Draining *Draining `json:"draining,omitempty"`
+	// ReplicaCalculatorWebhook replaces the metrics of the triggers in the HPA with the replica count decided by a
+	// webhook receiving the values of all the triggers, the cpu and memory triggers are still evaluated by the HPA
+	// +optional
+	ReplicaCalculatorWebhook *ReplicaCalculatorWebhook `json:"replicaCalculatorWebhook,omitempty"`
+}
+
+// CapacityCap caps the replicas at the allocatable ResourceName of the schedulable nodes matching NodeSelector
+type CapacityCap struct {
+	// ResourceName is the resource of the nodes each replica requests, eg. nvidia.com/gpu, defaults to pods,
+	// the Pod slots of the nodes
+	// +optional
+	ResourceName corev1.ResourceName `json:"resourceName,omitempty"`
+	// ResourcePerReplica is the amount of ResourceName requested by each replica, defaults to 1
+	// +optional
+	ResourcePerReplica *resource.Quantity `json:"resourcePerReplica,omitempty"`
+	// NodeSelector selects the nodes the replicas are placed on, eg. kubernetes.io/os=windows, defaults to all
+	// the nodes
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// Draining labels the pods to remove with PodDrainingLabel before the scale down and waits for their drain
+// condition, up to timeoutSeconds
+type Draining struct {
+	// Condition is Annotation (default) to wait for the PodDrainedAnnotation set to "true" by the pod,
+	// or Readiness to wait for the pod to report itself not ready
+	// +optional
+	Condition DrainingCondition `json:"condition,omitempty"`
+	// TimeoutSeconds is how long the scale down waits for the pods to drain, defaults to 300
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// DrainingCondition is how a pod signals it is drained
+// +kubebuilder:validation:Enum=Annotation;Readiness
+type DrainingCondition string
+
+const (
+	// DrainingAnnotation waits for the PodDrainedAnnotation on the pod
+	DrainingAnnotation DrainingCondition = "Annotation"
+
+	// DrainingReadiness waits for the readiness probe of the pod to fail, the pod flips it once drained
+	DrainingReadiness DrainingCondition = "Readiness"
+)
+
+const (
+	// PodDrainingLabel is set to "true" on the pods expected to be removed by the scale down, the pods watch it
+	// (eg. through the downward API) to stop accepting new sessions
+	PodDrainingLabel = "keda.sh/draining"
+
+	// PodDrainingSinceAnnotation is when the pod was labeled with PodDrainingLabel, in RFC 3339
+	PodDrainingSinceAnnotation = "keda.sh/draining-since"
+
+	// PodDrainedAnnotation is set to "true" by a draining pod once its sessions are closed
+	PodDrainedAnnotation = "keda.sh/drained"
+)
+
+// PreProvisioning is signaled when the scale target is activated or when the replica count computed from the metrics
+// is at least stepUpReplicas above the current replica count
+type PreProvisioning struct {
+	// Mode is Annotation (default) to annotate the scale target with the expected replica count,
+	// or PlaceholderPods to create a low priority pod for each missing replica
+	// +optional
+	Mode PreProvisioningMode `json:"mode,omitempty"`
+	// StepUpReplicas is the number of missing replicas signaled as a step up, defaults to 1
+	// +optional
+	StepUpReplicas *int32 `json:"stepUpReplicas,omitempty"`
+	// PriorityClassName of the placeholder pods, it must have a lower priority than the pods of the scale target
+	// so they preempt the placeholders, required by the PlaceholderPods mode
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// PreProvisioningMode is how the expected replicas are signaled to the node autoscalers
+// +kubebuilder:validation:Enum=Annotation;PlaceholderPods
+type PreProvisioningMode string
+
+const (
+	// PreProvisioningAnnotation sets the PreProvisioningReplicasAnnotation on the scale target
+	PreProvisioningAnnotation PreProvisioningMode = "Annotation"
+
+	// PreProvisioningPlaceholderPods creates pause pods requesting the resources of the missing replicas,
+	// the node autoscalers provision nodes for them and the pods of the scale target preempt them
+	PreProvisioningPlaceholderPods PreProvisioningMode = "PlaceholderPods"
+)
+
+// PreProvisioningReplicasAnnotation is set on the scale target with the replica count expected after the step up,
+// it is removed once no step up is expected
+const PreProvisioningReplicasAnnotation = "autoscaling.keda.sh/pre-provisioning-replicas"
+
+// PreProvisioningPlaceholderLabel is the label of the placeholder pods, its value is the name of the ScaledObject
+const PreProvisioningPlaceholderLabel = "autoscaling.keda.sh/pre-provisioning"
+
+// ScalingHooks let stateful consumers warm caches before the scale target is activated
+// and drain or checkpoint after it is deactivated
+type ScalingHooks struct {
+	// PreActivation is called before the scale target is scaled from zero (or idleReplicaCount)
+	// +optional
+	PreActivation *ScalingHook `json:"preActivation,omitempty"`
+	// PostDeactivation is called after the scale target is scaled to zero (or idleReplicaCount)
+	// +optional
+	PostDeactivation *ScalingHook `json:"postDeactivation,omitempty"`
+}
+
+// ScalingHook is a webhook receiving a POST request with a ScalingHookRequest
+type ScalingHook struct {
+	URL string `json:"url"`
+	// TimeoutSeconds of the request, defaults to 10
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy is Fail (default) to retry the activation in the next polling interval
+	// when the preActivation hook fails, or Ignore to scale anyway
+	// +optional
+	FailurePolicy ScalingHookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// ScalingHookFailurePolicy specifies how a failed call of a ScalingHook is handled
+// +kubebuilder:validation:Enum=Fail;Ignore
+type ScalingHookFailurePolicy string
+
+const (
+	// ScalingHookFail doesn't activate the scale target if its preActivation hook fails
+	ScalingHookFail ScalingHookFailurePolicy = "Fail"
+
+	// ScalingHookIgnore scales the scale target even if its hook fails
+	ScalingHookIgnore ScalingHookFailurePolicy = "Ignore"
+)
+
+// ScalingHookRequest is the body of the request sent to a ScalingHook
+type ScalingHookRequest struct {
+	Hook            string `json:"hook"`
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	ScaleTargetKind string `json:"scaleTargetKind"`
+	ScaleTargetName string `json:"scaleTargetName"`
+	Replicas        int32  `json:"replicas"`
+}
+
+// ReplicaCalculatorMetricName is the external metric of the HPA of a ScaledObject with a ReplicaCalculatorWebhook,
+// its value is the replica count decided by the webhook
+const ReplicaCalculatorMetricName = "keda-replica-calculator"
+
+// ReplicaCalculatorWebhook is a webhook receiving a POST request with a ReplicaCalculatorRequest each time the HPA
+// reads the metrics, it answers with a ReplicaCalculatorResponse
+type ReplicaCalculatorWebhook struct {
+	URL string `json:"url"`
+	// TimeoutSeconds of the request, defaults to 10
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy is Fail (default) to report an error to the HPA, which keeps the replica count, when the
+	// webhook fails, or Ignore to use the replica count proposed from the triggers
+	// +optional
+	FailurePolicy ScalingHookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// ReplicaCalculatorRequest is the body of the request sent to a ReplicaCalculatorWebhook
+type ReplicaCalculatorRequest struct {
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	CurrentReplicas int32  `json:"currentReplicas"`
+	// ProposedReplicas is the replica count the HPA would compute from the triggers
+	ProposedReplicas int32                      `json:"proposedReplicas"`
+	Triggers         []ReplicaCalculatorTrigger `json:"triggers"`
+}
+
+// ReplicaCalculatorTrigger is the value of a trigger metric sent to a ReplicaCalculatorWebhook
+type ReplicaCalculatorTrigger struct {
+	// +optional
+	Name       string                              `json:"name,omitempty"`
+	MetricName string                              `json:"metricName"`
+	MetricType autoscalingv2beta2.MetricTargetType `json:"metricType"`
+	Value      float64                             `json:"value"`
+	Target     float64                             `json:"target"`
+	// Error is set when the value of the trigger couldn't be read
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ReplicaCalculatorResponse is the body of the answer of a ReplicaCalculatorWebhook
+type ReplicaCalculatorResponse struct {
+	// Replicas overrides the proposed replica count, the proposed replica count is kept if it isn't set
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// OnDeletePolicyType is the type of replica handling when a ScaledObject is deleted
+// +kubebuilder:validation:Enum=RestoreOriginal;KeepCurrent;FixedReplicas
+type OnDeletePolicyType string
+
+const (
+	// OnDeleteRestoreOriginal scales the target back to the replica count it had before scaling with KEDA
+	OnDeleteRestoreOriginal OnDeletePolicyType = "RestoreOriginal"
+
+	// OnDeleteKeepCurrent keeps the current replica count of the target
+	OnDeleteKeepCurrent OnDeletePolicyType = "KeepCurrent"
+
+	// OnDeleteFixedReplicas scales the target to OnDeletePolicy.Replicas
+	OnDeleteFixedReplicas OnDeletePolicyType = "FixedReplicas"
+)
+
+// OnDeletePolicy specifies the replica count of the scale target after the ScaledObject is deleted
+type OnDeletePolicy struct {
+	Policy OnDeletePolicyType `json:"policy"`
+	// Replicas is required by the FixedReplicas policy
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
 }
 
 // HorizontalPodAutoscalerConfig specifies horizontal scale config
 type HorizontalPodAutoscalerConfig struct {
 	// +optional
 	Behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+	// Name of the HPA, defaults to keda-hpa-<ScaledObject name>
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Labels are added to the HPA
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the HPA
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // ScaleTarget holds the a reference to the scale target Object
 type ScaleTarget struct {
 	Name string `json:"name"`
+	// Namespace of the scale target, defaults to the namespace of the ScaledObject. Another namespace has to list
+	// the namespace of the ScaledObject in its keda.sh/allowed-scaledobject-namespaces annotation and grant the
+	// service accounts of the namespace of the ScaledObject the update of the scale subresource of the target.
+	// The environment of the containers of the target isn't resolved for the triggers. It can't be set in the
+	// additionalScaleTargets.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
 	// +optional
@@ -117,18 +538,123 @@ type ScaleTarget struct {
 	EnvSourceContainerName string `json:"envSourceContainerName,omitempty"`
 }
 
+// AdditionalScaleTarget selects workloads scaled to the replica count of the scaleTargetRef multiplied by Ratio,
+// either ScaleTargetRef or Selector has to be set
+type AdditionalScaleTarget struct {
+	// +optional
+	ScaleTargetRef *ScaleTarget `json:"scaleTargetRef,omitempty"`
+	// Selector selects Deployments of the namespace of the ScaledObject, the scaleTargetRef is never selected
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Ratio is a decimal number multiplied with the replica count of the scaleTargetRef, the result is
+	// rounded up, defaults to 1
+	// +optional
+	Ratio string `json:"ratio,omitempty"`
+}
+
 // ScaleTriggers reference the scaler that will be used
 type ScaleTriggers struct {
+	// Type is required unless the trigger references a ClusterTriggerTemplate
+	// +optional
 	Type string `json:"type"`
 	// +optional
 	Name     string            `json:"name,omitempty"`
 	Metadata map[string]string `json:"metadata"`
+	// MetadataValueFrom sets metadata fields from keys of ConfigMaps or Secrets in the namespace of the trigger,
+	// it overrides the fields of the metadata and the scalers are rebuilt when the values change
+	// +optional
+	MetadataValueFrom map[string]MetadataValueSource `json:"metadataValueFrom,omitempty"`
 	// +optional
 	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
+	// TemplateRef fills the type and the metadata of the trigger from a ClusterTriggerTemplate,
+	// the metadata of the trigger overrides the metadata of the template
+	// +optional
+	TemplateRef *TriggerTemplateRef `json:"templateRef,omitempty"`
 	// +optional
 	FallbackReplicas *int32 `json:"fallback,omitempty"`
+	// MetricMode specifies whether the trigger value or its per-second rate of change is reported, defaults to value
+	// +optional
+	MetricMode MetricMode `json:"metricMode,omitempty"`
+	// MetricType is the target type of the trigger metric in the HPA, defaults to AverageValue,
+	// Value compares the total metric value with the target regardless of the replica count
+	// +optional
+	// +kubebuilder:validation:Enum=AverageValue;Value;Utilization
+	MetricType autoscalingv2beta2.MetricTargetType `json:"metricType,omitempty"`
+	// Transform is an expression applied to the trigger value before it is reported, eg. `value * 0.001 + 5`
+	// +optional
+	Transform string `json:"transform,omitempty"`
+	// Ratio reports the value of the trigger divided by the value of another trigger
+	// +optional
+	Ratio *TriggerRatio `json:"ratio,omitempty"`
+	// MaxMetricAge is the age in seconds above which the metric values of the trigger are rejected as stale,
+	// their age isn't checked by default
+	// +optional
+	MaxMetricAge *int32 `json:"maxMetricAge,omitempty"`
+	// Schedule restricts the trigger to time windows, outside of them the trigger is inactive and reports 0
+	// without querying its backend
+	// +optional
+	Schedule []TriggerScheduleWindow `json:"schedule,omitempty"`
+	// Weight multiplies the replica count computed from the trigger before the HPA takes the highest one, eg. 0.5,
+	// it balances triggers of different units as the targets of the trigger metrics are divided by it
+	// +optional
+	Weight *resource.Quantity `json:"weight,omitempty"`
+	// RequiredConsecutiveSamples is the number of consecutive checks the trigger has to be active in before it
+	// activates the scale target from zero, defaults to 1
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RequiredConsecutiveSamples *int32 `json:"requiredConsecutiveSamples,omitempty"`
+	// ActivationWindow is the time in seconds the trigger has to stay active before it activates the scale target
+	// from zero, so a single spike doesn't activate it, defaults to 0
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ActivationWindow *int32 `json:"activationWindow,omitempty"`
+}
+
+// TriggerRatio divides the value of a trigger by the value of the Denominator trigger, eg. a backlog by the throughput
+// of a replica, the Denominator trigger isn't used by the HPA on its own
+type TriggerRatio struct {
+	// Denominator is the name of the trigger the value is divided by
+	Denominator string `json:"denominator"`
+	// ValueIfZero is the decimal value reported when the denominator is 0, by default the metric fails
+	// +optional
+	ValueIfZero string `json:"valueIfZero,omitempty"`
+	// MaxDenominatorAgeSeconds is how long the last value of the denominator is used while the Denominator trigger
+	// fails, defaults to 0
+	// +optional
+	MaxDenominatorAgeSeconds *int32 `json:"maxDenominatorAgeSeconds,omitempty"`
+}
+
+// TriggerScheduleWindow is a time window a trigger is evaluated in
+type TriggerScheduleWindow struct {
+	// Timezone of Start and End in the IANA Time Zone Database format, defaults to UTC
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// Start is a cron expression of the window start, eg. `0 8 * * 1-5`
+	Start string `json:"start"`
+	// End is a cron expression of the window end, eg. `0 18 * * 1-5`
+	End string `json:"end"`
+}
+
+// MetadataValueSource is the ConfigMap or the Secret key holding the value of a metadata field
+type MetadataValueSource struct {
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
+// MetricMode is the mode of reporting the trigger value
+// +kubebuilder:validation:Enum=value;rate
+type MetricMode string
+
+const (
+	// MetricModeValue reports the value returned by the scaler
+	MetricModeValue MetricMode = "value"
+
+	// MetricModeRate reports the per-second rate of change of the value returned by the scaler
+	MetricModeRate MetricMode = "rate"
+)
+
 // +k8s:openapi-gen=true
 
 // ScaledObjectStatus is the status for a ScaledObject resource
@@ -150,6 +676,38 @@ type ScaledObjectStatus struct {
 	Conditions Conditions `json:"conditions,omitempty"`
 	// +optional
 	Health map[string]HealthStatus `json:"health,omitempty"`
+	// HpaName is the name of the HPA of the ScaledObject, the HPA is deleted when the name changes
+	// +optional
+	HpaName string `json:"hpaName,omitempty"`
+	// +optional
+	DryRunReplicaCount *int32 `json:"dryRunReplicaCount,omitempty"`
+	// +optional
+	Budget *BudgetStatus `json:"budget,omitempty"`
+	// +optional
+	Shadow *ShadowStatus `json:"shadow,omitempty"`
+	// LastActivation is the last time the ScaledObject became active and the triggers which activated it
+	// +optional
+	LastActivation *ActivationEvent `json:"lastActivation,omitempty"`
+	// LastDeactivation is the last time the ScaledObject became inactive and the triggers which were active until then
+	// +optional
+	LastDeactivation *ActivationEvent `json:"lastDeactivation,omitempty"`
+	// TriggersLastActiveTime is the last time each trigger was found active, by the name of the trigger or the
+	// metric name of the unnamed triggers
+	// +optional
+	TriggersLastActiveTime map[string]metav1.Time `json:"triggersLastActiveTime,omitempty"`
+}
+
+// ActivationEvent is a change of the activity of a ScaledObject
+type ActivationEvent struct {
+	Time metav1.Time `json:"time"`
+	// Triggers are the names of the triggers, or the metric names of the unnamed triggers, which caused the change.
+	// With the default activation strategy the first active trigger is enough to activate the ScaledObject and the
+	// other triggers aren't evaluated
+	// +optional
+	Triggers []string `json:"triggers,omitempty"`
+	// Reason is TriggersActive, TriggersNotActive or TriggersError when the triggers couldn't be evaluated
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -164,12 +722,98 @@ type ScaledObjectList struct {
 // ScaledObjectAuthRef points to the TriggerAuthentication or ClusterTriggerAuthentication object that
 // is used to authenticate the scaler with the environment
 type ScaledObjectAuthRef struct {
-	Name string `json:"name"`
+	// Name is required unless the ClusterTriggerAuthentication is selected by labels
+	// +optional
+	Name string `json:"name,omitempty"`
 	// Kind of the resource being referred to. Defaults to TriggerAuthentication.
 	// +optional
 	Kind string `json:"kind,omitempty"`
+	// Selector selects the ClusterTriggerAuthentication by labels when no name is given, the newest matching
+	// one is used so the credentials are rotated by creating a new ClusterTriggerAuthentication
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 func init() {
 	SchemeBuilder.Register(&ScaledObject{}, &ScaledObjectList{})
 }
+
+// GetOnDeletePolicy returns the policy applied to the scale target after the ScaledObject is deleted
+func (so *ScaledObject) GetOnDeletePolicy() OnDeletePolicyType {
+	switch {
+	case so.Spec.Advanced == nil:
+		return OnDeleteKeepCurrent
+	case so.Spec.Advanced.OnDelete != nil:
+		return so.Spec.Advanced.OnDelete.Policy
+	case so.Spec.Advanced.RestoreToOriginalReplicaCount:
+		return OnDeleteRestoreOriginal
+	default:
+		return OnDeleteKeepCurrent
+	}
+}
+
+// GetActivationStrategy returns the activation strategy of the ScaledObject, empty means any
+func (so *ScaledObject) GetActivationStrategy() string {
+	if so.Spec.Advanced == nil {
+		return ""
+	}
+	return so.Spec.Advanced.ActivationStrategy
+}
+
+// GetPreProvisioning returns the pre-provisioning of the ScaledObject, nil if it is disabled
+func (so *ScaledObject) GetPreProvisioning() *PreProvisioning {
+	if so.Spec.Advanced == nil {
+		return nil
+	}
+	return so.Spec.Advanced.PreProvisioning
+}
+
+// GetTolerance returns the tolerance of the ScaledObject, nil if the HPA tolerance applies
+func (so *ScaledObject) GetTolerance() *resource.Quantity {
+	if so.Spec.Advanced == nil {
+		return nil
+	}
+	return so.Spec.Advanced.Tolerance
+}
+
+// GetDraining returns the draining of the scale downs of the ScaledObject, nil if it is disabled
+func (so *ScaledObject) GetDraining() *Draining {
+	if so.Spec.Advanced == nil {
+		return nil
+	}
+	return so.Spec.Advanced.Draining
+}
+
+// IsDryRun returns true if the ScaledObject only evaluates triggers without scaling the target
+func (so *ScaledObject) IsDryRun() bool {
+	return so.Spec.Advanced != nil && so.Spec.Advanced.DryRun
+}
+
+// GetHPAName returns the name of the HPA of the ScaledObject, the name of the HPA the ScaledObject adopted,
+// the name set in horizontalPodAutoscalerConfig or keda-hpa-<ScaledObject name>, the HPA of a ScaledObject scaling
+// a workload of another namespace is keda-hpa-<ScaledObject namespace>-<ScaledObject name>
+func (so *ScaledObject) GetHPAName() string {
+	if name := so.Annotations[ScaledObjectTransferHpaOwnershipAnnotation]; name != "" {
+		return name
+	}
+	if so.Spec.Advanced != nil && so.Spec.Advanced.HorizontalPodAutoscalerConfig != nil && so.Spec.Advanced.HorizontalPodAutoscalerConfig.Name != "" {
+		return so.Spec.Advanced.HorizontalPodAutoscalerConfig.Name
+	}
+	if so.IsCrossNamespace() {
+		return fmt.Sprintf("keda-hpa-%s-%s", so.Namespace, so.Name)
+	}
+	return fmt.Sprintf("keda-hpa-%s", so.Name)
+}
+
+// GetScaleTargetNamespace returns the namespace of the scale target, it is also the namespace of the HPA
+func (so *ScaledObject) GetScaleTargetNamespace() string {
+	if so.Spec.ScaleTargetRef != nil && so.Spec.ScaleTargetRef.Namespace != "" {
+		return so.Spec.ScaleTargetRef.Namespace
+	}
+	return so.Namespace
+}
+
+// IsCrossNamespace returns true if the scale target is in another namespace than the ScaledObject
+func (so *ScaledObject) IsCrossNamespace() bool {
+	return so.GetScaleTargetNamespace() != so.Namespace
+}
@@ -75,6 +75,9 @@ type TriggerAuthenticationSpec struct {
 
 	// +optional
 	HashiCorpVault *HashiCorpVault `json:"hashiCorpVault,omitempty"`
+
+	// +optional
+	LDAP *LDAP `json:"ldap,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -92,12 +95,13 @@ type PodIdentityProvider string
 // PodIdentityProviderNone specifies the default state when there is no Identity Provider
 // PodIdentityProvider<IDENTITY_PROVIDER> specifies other available Identity providers
 const (
-	PodIdentityProviderNone    PodIdentityProvider = "none"
-	PodIdentityProviderAzure   PodIdentityProvider = "azure"
-	PodIdentityProviderGCP     PodIdentityProvider = "gcp"
-	PodIdentityProviderSpiffe  PodIdentityProvider = "spiffe"
-	PodIdentityProviderAwsEKS  PodIdentityProvider = "aws-eks"
-	PodIdentityProviderAwsKiam PodIdentityProvider = "aws-kiam"
+	PodIdentityProviderNone          PodIdentityProvider = "none"
+	PodIdentityProviderAzure         PodIdentityProvider = "azure"
+	PodIdentityProviderAzureWorkload PodIdentityProvider = "azure-workload"
+	PodIdentityProviderGCP           PodIdentityProvider = "gcp"
+	PodIdentityProviderSpiffe        PodIdentityProvider = "spiffe"
+	PodIdentityProviderAwsEKS        PodIdentityProvider = "aws-eks"
+	PodIdentityProviderAwsKiam       PodIdentityProvider = "aws-kiam"
 )
 
 // PodIdentityAnnotationEKS specifies aws role arn for aws-eks Identity Provider
@@ -136,6 +140,7 @@ type HashiCorpVault struct {
 	Authentication VaultAuthentication `json:"authentication"`
 	Secrets        []VaultSecret       `json:"secrets"`
 
+	// Namespace is the Vault Enterprise namespace of the secrets and of the authentication
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
@@ -147,6 +152,30 @@ type HashiCorpVault struct {
 
 	// +optional
 	Mount string `json:"mount,omitempty"`
+
+	// +optional
+	TLS *VaultTLS `json:"tls,omitempty"`
+}
+
+// VaultTLS configures the TLS connection to Vault
+type VaultTLS struct {
+	// CAFile is the file with the CA certificates of Vault
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+
+	// ClientCertFile and ClientKeyFile are the files of the client certificate presented to Vault for mTLS
+	// +optional
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+
+	// +optional
+	ClientKeyFile string `json:"clientKeyFile,omitempty"`
+
+	// ServerName is the name the certificate of Vault is verified against, by default the host of the address
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 // Credential defines the Hashicorp Vault credentials depending on the authentication method
@@ -175,6 +204,74 @@ type VaultSecret struct {
 	Key       string `json:"key"`
 }
 
+// LDAP is used to authenticate using the credentials stored in an LDAP directory
+type LDAP struct {
+	// Address is the URL of the directory, ldap:// or ldaps://
+	Address string `json:"address"`
+	// Entries maps the attributes of the directory entries to the parameters
+	// +optional
+	Entries []LDAPEntry `json:"entries,omitempty"`
+
+	// Credential binds to the directory, the bind is anonymous without it
+	// +optional
+	Credential *LDAPCredential `json:"credential,omitempty"`
+
+	// ValidateCredentials binds with resolved parameters to check them before the scalers use them
+	// +optional
+	ValidateCredentials *LDAPCredentialValidation `json:"validateCredentials,omitempty"`
+
+	// +optional
+	TLS *LDAPTLS `json:"tls,omitempty"`
+
+	// CacheDurationSeconds is how long the attributes are reused before being read again, 300 by default
+	// +optional
+	CacheDurationSeconds *int32 `json:"cacheDurationSeconds,omitempty"`
+}
+
+// LDAPEntry defines the mapping between an attribute of a directory entry to the parameter
+type LDAPEntry struct {
+	Parameter string `json:"parameter"`
+	// DN is the entry, or the search base of Filter
+	DN        string `json:"dn"`
+	Attribute string `json:"attribute"`
+
+	// Filter searches the entry in the subtree of DN, it must match a single entry
+	// +optional
+	Filter string `json:"filter,omitempty"`
+}
+
+// LDAPCredential defines the DN and the password the directory is bound with
+type LDAPCredential struct {
+	BindDN string `json:"bindDN"`
+	// PasswordFile is the file with the password, eg. mounted by a CSI driver
+	PasswordFile string `json:"passwordFile"`
+}
+
+// LDAPCredentialValidation checks the username and password parameters by binding with them
+type LDAPCredentialValidation struct {
+	UsernameParameter string `json:"usernameParameter"`
+	PasswordParameter string `json:"passwordParameter"`
+
+	// UserDNTemplate builds the DN of the username, eg. uid={{username}},ou=services,dc=example,dc=com,
+	// the username is bound as is without it
+	// +optional
+	UserDNTemplate string `json:"userDNTemplate,omitempty"`
+}
+
+// LDAPTLS configures the TLS connection to the directory
+type LDAPTLS struct {
+	// StartTLS upgrades an ldap:// connection
+	// +optional
+	StartTLS bool `json:"startTLS,omitempty"`
+
+	// CAFile is the file with the CA certificates of the directory
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&ClusterTriggerAuthentication{}, &ClusterTriggerAuthenticationList{})
 	SchemeBuilder.Register(&TriggerAuthentication{}, &TriggerAuthenticationList{})
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +32,10 @@ const (
 	defaultPollingInterval = 30
 )
 
+// LogVerbosityAnnotation raises the verbosity of the logs of the scalers of a ScaledObject or a ScaledJob, eg. "1"
+// logs their debug messages without raising the verbosity of the operator
+const LogVerbosityAnnotation = "autoscaling.keda.sh/log-verbosity"
+
 // +kubebuilder:object:root=true
 
 // WithTriggers is a specification for a resource with triggers
@@ -88,6 +93,15 @@ func (t *WithTriggers) GetPollingInterval() time.Duration {
 	return time.Second * time.Duration(defaultPollingInterval)
 }
 
+// GetLogVerbosity returns the verbosity set by the LogVerbosityAnnotation, 0 if it isn't set or isn't a positive integer
+func (t *WithTriggers) GetLogVerbosity() int {
+	verbosity, err := strconv.Atoi(t.Annotations[LogVerbosityAnnotation])
+	if err != nil || verbosity < 0 {
+		return 0
+	}
+	return verbosity
+}
+
 // GenerateIdenitifier returns identifier for the object in for "kind.namespace.name"
 func (t *WithTriggers) GenerateIdenitifier() string {
 	return fmt.Sprintf("%s.%s.%s", t.Kind, t.Namespace, t.Name)
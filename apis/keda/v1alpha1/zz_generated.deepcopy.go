@@ -24,9 +24,57 @@ package v1alpha1
 import (
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActivationEvent) DeepCopyInto(out *ActivationEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActivationEvent.
+func (in *ActivationEvent) DeepCopy() *ActivationEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ActivationEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalScaleTarget) DeepCopyInto(out *AdditionalScaleTarget) {
+	*out = *in
+	if in.ScaleTargetRef != nil {
+		in, out := &in.ScaleTargetRef, &out.ScaleTargetRef
+		*out = new(ScaleTarget)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalScaleTarget.
+func (in *AdditionalScaleTarget) DeepCopy() *AdditionalScaleTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalScaleTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvancedConfig) DeepCopyInto(out *AdvancedConfig) {
 	*out = *in
@@ -35,6 +83,51 @@ func (in *AdvancedConfig) DeepCopyInto(out *AdvancedConfig) {
 		*out = new(HorizontalPodAutoscalerConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OnDelete != nil {
+		in, out := &in.OnDelete, &out.OnDelete
+		*out = new(OnDeletePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScalingHooks != nil {
+		in, out := &in.ScalingHooks, &out.ScalingHooks
+		*out = new(ScalingHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityCap != nil {
+		in, out := &in.CapacityCap, &out.CapacityCap
+		*out = new(CapacityCap)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BusyPodsTimeoutSeconds != nil {
+		in, out := &in.BusyPodsTimeoutSeconds, &out.BusyPodsTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LatencyBudgetMilliseconds != nil {
+		in, out := &in.LatencyBudgetMilliseconds, &out.LatencyBudgetMilliseconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PreProvisioning != nil {
+		in, out := &in.PreProvisioning, &out.PreProvisioning
+		*out = new(PreProvisioning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerance != nil {
+		in, out := &in.Tolerance, &out.Tolerance
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Draining != nil {
+		in, out := &in.Draining, &out.Draining
+		*out = new(Draining)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaCalculatorWebhook != nil {
+		in, out := &in.ReplicaCalculatorWebhook, &out.ReplicaCalculatorWebhook
+		*out = new(ReplicaCalculatorWebhook)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedConfig.
@@ -92,6 +185,140 @@ func (in *AuthSecretTargetRef) DeepCopy() *AuthSecretTargetRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Budget) DeepCopyInto(out *Budget) {
+	*out = *in
+	if in.MaxReplicaHoursPerDay != nil {
+		in, out := &in.MaxReplicaHoursPerDay, &out.MaxReplicaHoursPerDay
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PricingWindows != nil {
+		in, out := &in.PricingWindows, &out.PricingWindows
+		*out = make([]PricingWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Budget.
+func (in *Budget) DeepCopy() *Budget {
+	if in == nil {
+		return nil
+	}
+	out := new(Budget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BudgetStatus) DeepCopyInto(out *BudgetStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ClampedReplicaCount != nil {
+		in, out := &in.ClampedReplicaCount, &out.ClampedReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetStatus.
+func (in *BudgetStatus) DeepCopy() *BudgetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BudgetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityCap) DeepCopyInto(out *CapacityCap) {
+	*out = *in
+	if in.ResourcePerReplica != nil {
+		in, out := &in.ResourcePerReplica, &out.ResourcePerReplica
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityCap.
+func (in *CapacityCap) DeepCopy() *CapacityCap {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityCap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKedaConfig) DeepCopyInto(out *ClusterKedaConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKedaConfig.
+func (in *ClusterKedaConfig) DeepCopy() *ClusterKedaConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKedaConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKedaConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKedaConfigList) DeepCopyInto(out *ClusterKedaConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterKedaConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKedaConfigList.
+func (in *ClusterKedaConfigList) DeepCopy() *ClusterKedaConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKedaConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKedaConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterTriggerAuthentication) DeepCopyInto(out *ClusterTriggerAuthentication) {
 	*out = *in
@@ -150,6 +377,98 @@ func (in *ClusterTriggerAuthenticationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTriggerTemplate) DeepCopyInto(out *ClusterTriggerTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTriggerTemplate.
+func (in *ClusterTriggerTemplate) DeepCopy() *ClusterTriggerTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTriggerTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTriggerTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTriggerTemplateList) DeepCopyInto(out *ClusterTriggerTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterTriggerTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTriggerTemplateList.
+func (in *ClusterTriggerTemplateList) DeepCopy() *ClusterTriggerTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTriggerTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTriggerTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTriggerTemplateSpec) DeepCopyInto(out *ClusterTriggerTemplateSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]TriggerTemplateParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AuthenticationRef != nil {
+		in, out := &in.AuthenticationRef, &out.AuthenticationRef
+		*out = new(ScaledObjectAuthRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTriggerTemplateSpec.
+func (in *ClusterTriggerTemplateSpec) DeepCopy() *ClusterTriggerTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTriggerTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -199,6 +518,26 @@ func (in *Credential) DeepCopy() *Credential {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Draining) DeepCopyInto(out *Draining) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Draining.
+func (in *Draining) DeepCopy() *Draining {
+	if in == nil {
+		return nil
+	}
+	out := new(Draining)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Fallback) DeepCopyInto(out *Fallback) {
 	*out = *in
@@ -209,87 +548,569 @@ func (in *Fallback) DeepCopy() *Fallback {
 	if in == nil {
 		return nil
 	}
-	out := new(Fallback)
+	out := new(Fallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupVersionKindResource) DeepCopyInto(out *GroupVersionKindResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupVersionKindResource.
+func (in *GroupVersionKindResource) DeepCopy() *GroupVersionKindResource {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupVersionKindResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HashiCorpVault) DeepCopyInto(out *HashiCorpVault) {
+	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]VaultSecret, len(*in))
+		copy(*out, *in)
+	}
+	if in.Credential != nil {
+		in, out := &in.Credential, &out.Credential
+		*out = new(Credential)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(VaultTLS)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HashiCorpVault.
+func (in *HashiCorpVault) DeepCopy() *HashiCorpVault {
+	if in == nil {
+		return nil
+	}
+	out := new(HashiCorpVault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthStatus) DeepCopyInto(out *HealthStatus) {
+	*out = *in
+	if in.NumberOfFailures != nil {
+		in, out := &in.NumberOfFailures, &out.NumberOfFailures
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthStatus.
+func (in *HealthStatus) DeepCopy() *HealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HorizontalPodAutoscalerConfig) DeepCopyInto(out *HorizontalPodAutoscalerConfig) {
+	*out = *in
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2beta2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalPodAutoscalerConfig.
+func (in *HorizontalPodAutoscalerConfig) DeepCopy() *HorizontalPodAutoscalerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HorizontalPodAutoscalerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaConfig) DeepCopyInto(out *KedaConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaConfig.
+func (in *KedaConfig) DeepCopy() *KedaConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KedaConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaConfigDefaults) DeepCopyInto(out *KedaConfigDefaults) {
+	*out = *in
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicaCount != nil {
+		in, out := &in.MaxReplicaCount, &out.MaxReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaConfigDefaults.
+func (in *KedaConfigDefaults) DeepCopy() *KedaConfigDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaConfigDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaConfigLimits) DeepCopyInto(out *KedaConfigLimits) {
+	*out = *in
+	if in.MaxReplicaCount != nil {
+		in, out := &in.MaxReplicaCount, &out.MaxReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinPollingInterval != nil {
+		in, out := &in.MinPollingInterval, &out.MinPollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BannedScalerTypes != nil {
+		in, out := &in.BannedScalerTypes, &out.BannedScalerTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredAuthenticationProviders != nil {
+		in, out := &in.RequiredAuthenticationProviders, &out.RequiredAuthenticationProviders
+		*out = make([]AuthenticationProvider, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaConfigLimits.
+func (in *KedaConfigLimits) DeepCopy() *KedaConfigLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaConfigLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaConfigList) DeepCopyInto(out *KedaConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KedaConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaConfigList.
+func (in *KedaConfigList) DeepCopy() *KedaConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KedaConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaConfigSpec) DeepCopyInto(out *KedaConfigSpec) {
+	*out = *in
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(KedaConfigDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(KedaConfigLimits)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaConfigSpec.
+func (in *KedaConfigSpec) DeepCopy() *KedaConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAP) DeepCopyInto(out *LDAP) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]LDAPEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.Credential != nil {
+		in, out := &in.Credential, &out.Credential
+		*out = new(LDAPCredential)
+		**out = **in
+	}
+	if in.ValidateCredentials != nil {
+		in, out := &in.ValidateCredentials, &out.ValidateCredentials
+		*out = new(LDAPCredentialValidation)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(LDAPTLS)
+		**out = **in
+	}
+	if in.CacheDurationSeconds != nil {
+		in, out := &in.CacheDurationSeconds, &out.CacheDurationSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAP.
+func (in *LDAP) DeepCopy() *LDAP {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPCredential) DeepCopyInto(out *LDAPCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPCredential.
+func (in *LDAPCredential) DeepCopy() *LDAPCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPCredentialValidation) DeepCopyInto(out *LDAPCredentialValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPCredentialValidation.
+func (in *LDAPCredentialValidation) DeepCopy() *LDAPCredentialValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPCredentialValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPEntry) DeepCopyInto(out *LDAPEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPEntry.
+func (in *LDAPEntry) DeepCopy() *LDAPEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPTLS) DeepCopyInto(out *LDAPTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPTLS.
+func (in *LDAPTLS) DeepCopy() *LDAPTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataValueSource) DeepCopyInto(out *MetadataValueSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataValueSource.
+func (in *MetadataValueSource) DeepCopy() *MetadataValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnDeletePolicy) DeepCopyInto(out *OnDeletePolicy) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnDeletePolicy.
+func (in *OnDeletePolicy) DeepCopy() *OnDeletePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OnDeletePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreProvisioning) DeepCopyInto(out *PreProvisioning) {
+	*out = *in
+	if in.StepUpReplicas != nil {
+		in, out := &in.StepUpReplicas, &out.StepUpReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreProvisioning.
+func (in *PreProvisioning) DeepCopy() *PreProvisioning {
+	if in == nil {
+		return nil
+	}
+	out := new(PreProvisioning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PricingWindow) DeepCopyInto(out *PricingWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingWindow.
+func (in *PricingWindow) DeepCopy() *PricingWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(PricingWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaCalculatorRequest) DeepCopyInto(out *ReplicaCalculatorRequest) {
+	*out = *in
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]ReplicaCalculatorTrigger, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaCalculatorRequest.
+func (in *ReplicaCalculatorRequest) DeepCopy() *ReplicaCalculatorRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaCalculatorRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaCalculatorResponse) DeepCopyInto(out *ReplicaCalculatorResponse) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaCalculatorResponse.
+func (in *ReplicaCalculatorResponse) DeepCopy() *ReplicaCalculatorResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaCalculatorResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaCalculatorTrigger) DeepCopyInto(out *ReplicaCalculatorTrigger) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaCalculatorTrigger.
+func (in *ReplicaCalculatorTrigger) DeepCopy() *ReplicaCalculatorTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaCalculatorTrigger)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GroupVersionKindResource) DeepCopyInto(out *GroupVersionKindResource) {
+func (in *ReplicaCalculatorWebhook) DeepCopyInto(out *ReplicaCalculatorWebhook) {
 	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupVersionKindResource.
-func (in *GroupVersionKindResource) DeepCopy() *GroupVersionKindResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaCalculatorWebhook.
+func (in *ReplicaCalculatorWebhook) DeepCopy() *ReplicaCalculatorWebhook {
 	if in == nil {
 		return nil
 	}
-	out := new(GroupVersionKindResource)
+	out := new(ReplicaCalculatorWebhook)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HashiCorpVault) DeepCopyInto(out *HashiCorpVault) {
+func (in *ScalingHook) DeepCopyInto(out *ScalingHook) {
 	*out = *in
-	if in.Secrets != nil {
-		in, out := &in.Secrets, &out.Secrets
-		*out = make([]VaultSecret, len(*in))
-		copy(*out, *in)
-	}
-	if in.Credential != nil {
-		in, out := &in.Credential, &out.Credential
-		*out = new(Credential)
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HashiCorpVault.
-func (in *HashiCorpVault) DeepCopy() *HashiCorpVault {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingHook.
+func (in *ScalingHook) DeepCopy() *ScalingHook {
 	if in == nil {
 		return nil
 	}
-	out := new(HashiCorpVault)
+	out := new(ScalingHook)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HealthStatus) DeepCopyInto(out *HealthStatus) {
+func (in *ScalingHookRequest) DeepCopyInto(out *ScalingHookRequest) {
 	*out = *in
-	if in.NumberOfFailures != nil {
-		in, out := &in.NumberOfFailures, &out.NumberOfFailures
-		*out = new(int32)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthStatus.
-func (in *HealthStatus) DeepCopy() *HealthStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingHookRequest.
+func (in *ScalingHookRequest) DeepCopy() *ScalingHookRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(HealthStatus)
+	out := new(ScalingHookRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HorizontalPodAutoscalerConfig) DeepCopyInto(out *HorizontalPodAutoscalerConfig) {
+func (in *ScalingHooks) DeepCopyInto(out *ScalingHooks) {
 	*out = *in
-	if in.Behavior != nil {
-		in, out := &in.Behavior, &out.Behavior
-		*out = new(v2beta2.HorizontalPodAutoscalerBehavior)
+	if in.PreActivation != nil {
+		in, out := &in.PreActivation, &out.PreActivation
+		*out = new(ScalingHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostDeactivation != nil {
+		in, out := &in.PostDeactivation, &out.PostDeactivation
+		*out = new(ScalingHook)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalPodAutoscalerConfig.
-func (in *HorizontalPodAutoscalerConfig) DeepCopy() *HorizontalPodAutoscalerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingHooks.
+func (in *ScalingHooks) DeepCopy() *ScalingHooks {
 	if in == nil {
 		return nil
 	}
-	out := new(HorizontalPodAutoscalerConfig)
+	out := new(ScalingHooks)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -319,16 +1140,58 @@ func (in *ScaleTriggers) DeepCopyInto(out *ScaleTriggers) {
 			(*out)[key] = val
 		}
 	}
+	if in.MetadataValueFrom != nil {
+		in, out := &in.MetadataValueFrom, &out.MetadataValueFrom
+		*out = make(map[string]MetadataValueSource, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.AuthenticationRef != nil {
 		in, out := &in.AuthenticationRef, &out.AuthenticationRef
 		*out = new(ScaledObjectAuthRef)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(TriggerTemplateRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ratio != nil {
+		in, out := &in.Ratio, &out.Ratio
+		*out = new(TriggerRatio)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.FallbackReplicas != nil {
 		in, out := &in.FallbackReplicas, &out.FallbackReplicas
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxMetricAge != nil {
+		in, out := &in.MaxMetricAge, &out.MaxMetricAge
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = make([]TriggerScheduleWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RequiredConsecutiveSamples != nil {
+		in, out := &in.RequiredConsecutiveSamples, &out.RequiredConsecutiveSamples
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActivationWindow != nil {
+		in, out := &in.ActivationWindow, &out.ActivationWindow
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTriggers.
@@ -428,7 +1291,19 @@ func (in *ScaledJobSpec) DeepCopyInto(out *ScaledJobSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MinReplicaCount != nil {
+		in, out := &in.MinReplicaCount, &out.MinReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
 	in.ScalingStrategy.DeepCopyInto(&out.ScalingStrategy)
+	if in.UrgencyTiers != nil {
+		in, out := &in.UrgencyTiers, &out.UrgencyTiers
+		*out = make([]UrgencyTier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Triggers != nil {
 		in, out := &in.Triggers, &out.Triggers
 		*out = make([]ScaleTriggers, len(*in))
@@ -502,6 +1377,11 @@ func (in *ScaledObject) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaledObjectAuthRef) DeepCopyInto(out *ScaledObjectAuthRef) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectAuthRef.
@@ -554,6 +1434,13 @@ func (in *ScaledObjectSpec) DeepCopyInto(out *ScaledObjectSpec) {
 		*out = new(ScaleTarget)
 		**out = **in
 	}
+	if in.AdditionalScaleTargets != nil {
+		in, out := &in.AdditionalScaleTargets, &out.AdditionalScaleTargets
+		*out = make([]AdditionalScaleTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PollingInterval != nil {
 		in, out := &in.PollingInterval, &out.PollingInterval
 		*out = new(int32)
@@ -564,6 +1451,11 @@ func (in *ScaledObjectSpec) DeepCopyInto(out *ScaledObjectSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ScaleToZeroGracePeriod != nil {
+		in, out := &in.ScaleToZeroGracePeriod, &out.ScaleToZeroGracePeriod
+		*out = new(int32)
+		**out = **in
+	}
 	if in.IdleReplicaCount != nil {
 		in, out := &in.IdleReplicaCount, &out.IdleReplicaCount
 		*out = new(int32)
@@ -596,6 +1488,23 @@ func (in *ScaledObjectSpec) DeepCopyInto(out *ScaledObjectSpec) {
 		*out = new(Fallback)
 		**out = **in
 	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ScheduleWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(Budget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shadow != nil {
+		in, out := &in.Shadow, &out.Shadow
+		*out = new(ShadowTriggers)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectSpec.
@@ -647,6 +1556,38 @@ func (in *ScaledObjectStatus) DeepCopyInto(out *ScaledObjectStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.DryRunReplicaCount != nil {
+		in, out := &in.DryRunReplicaCount, &out.DryRunReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(BudgetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shadow != nil {
+		in, out := &in.Shadow, &out.Shadow
+		*out = new(ShadowStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastActivation != nil {
+		in, out := &in.LastActivation, &out.LastActivation
+		*out = new(ActivationEvent)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastDeactivation != nil {
+		in, out := &in.LastDeactivation, &out.LastDeactivation
+		*out = new(ActivationEvent)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TriggersLastActiveTime != nil {
+		in, out := &in.TriggersLastActiveTime, &out.TriggersLastActiveTime
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectStatus.
@@ -672,6 +1613,16 @@ func (in *ScalingStrategy) DeepCopyInto(out *ScalingStrategy) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TargetDrainTime != nil {
+		in, out := &in.TargetDrainTime, &out.TargetDrainTime
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingStrategy.
@@ -684,6 +1635,91 @@ func (in *ScalingStrategy) DeepCopy() *ScalingStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
+	*out = *in
+	if in.MinReplicaCount != nil {
+		in, out := &in.MinReplicaCount, &out.MinReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicaCount != nil {
+		in, out := &in.MaxReplicaCount, &out.MaxReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowStatus) DeepCopyInto(out *ShadowStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ReplicaCount != nil {
+		in, out := &in.ReplicaCount, &out.ReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LiveReplicaCount != nil {
+		in, out := &in.LiveReplicaCount, &out.LiveReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowStatus.
+func (in *ShadowStatus) DeepCopy() *ShadowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowTriggers) DeepCopyInto(out *ShadowTriggers) {
+	*out = *in
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]ScaleTriggers, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DurationSeconds != nil {
+		in, out := &in.DurationSeconds, &out.DurationSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowTriggers.
+func (in *ShadowTriggers) DeepCopy() *ShadowTriggers {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowTriggers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerAuthentication) DeepCopyInto(out *TriggerAuthentication) {
 	*out = *in
@@ -765,6 +1801,11 @@ func (in *TriggerAuthenticationSpec) DeepCopyInto(out *TriggerAuthenticationSpec
 		*out = new(HashiCorpVault)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(LDAP)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerAuthenticationSpec.
@@ -777,6 +1818,113 @@ func (in *TriggerAuthenticationSpec) DeepCopy() *TriggerAuthenticationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerRatio) DeepCopyInto(out *TriggerRatio) {
+	*out = *in
+	if in.MaxDenominatorAgeSeconds != nil {
+		in, out := &in.MaxDenominatorAgeSeconds, &out.MaxDenominatorAgeSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerRatio.
+func (in *TriggerRatio) DeepCopy() *TriggerRatio {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerRatio)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerScheduleWindow) DeepCopyInto(out *TriggerScheduleWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerScheduleWindow.
+func (in *TriggerScheduleWindow) DeepCopy() *TriggerScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerTemplateParameter) DeepCopyInto(out *TriggerTemplateParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerTemplateParameter.
+func (in *TriggerTemplateParameter) DeepCopy() *TriggerTemplateParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerTemplateParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerTemplateRef) DeepCopyInto(out *TriggerTemplateRef) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerTemplateRef.
+func (in *TriggerTemplateRef) DeepCopy() *TriggerTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UrgencyTier) DeepCopyInto(out *UrgencyTier) {
+	*out = *in
+	if in.PreemptionPolicy != nil {
+		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
+		*out = new(corev1.PreemptionPolicy)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SafeToEvict != nil {
+		in, out := &in.SafeToEvict, &out.SafeToEvict
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UrgencyTier.
+func (in *UrgencyTier) DeepCopy() *UrgencyTier {
+	if in == nil {
+		return nil
+	}
+	out := new(UrgencyTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultSecret) DeepCopyInto(out *VaultSecret) {
 	*out = *in
@@ -792,6 +1940,21 @@ func (in *VaultSecret) DeepCopy() *VaultSecret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultTLS) DeepCopyInto(out *VaultTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTLS.
+func (in *VaultTLS) DeepCopy() *VaultTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WithTriggers) DeepCopyInto(out *WithTriggers) {
 	*out = *in
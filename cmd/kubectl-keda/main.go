@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-keda is a kubectl plugin which queries the KEDA Operator debug endpoint
+// and prints the current state of the triggers of a ScaledObject or ScaledJob,
+// it also validates the triggers of the manifests before they are applied.
+//
+// Usage:
+//   kubectl port-forward -n keda deployment/keda-operator 8082
+//   kubectl keda check [scaledobject|scaledjob] <name> [-n <namespace>] [--endpoint http://localhost:8082] --token <token>
+//   kubectl keda validate -f <file>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kedacore/keda/v2/pkg/debug"
+)
+
+const usage = `Usage: kubectl keda check [scaledobject|scaledjob] <name> [flags]
+
+Executes the triggers of a ScaledObject or ScaledJob once from the KEDA Operator
+and prints their current values, targets and errors.
+The KEDA Operator must be started with --debug-bind-address, the owner of the
+token must be allowed to get the ScaledObject or ScaledJob.
+
+       kubectl keda validate -f <file>
+
+Parses the triggers of the ScaledObjects and ScaledJobs of a manifest without
+connecting to their backends, see kubectl keda validate -h.
+
+Flags:
+`
+
+func main() {
+	flags := flag.NewFlagSet("kubectl-keda", flag.ExitOnError)
+	namespace := flags.String("n", "default", "The namespace of the ScaledObject or ScaledJob.")
+	endpoint := flags.String("endpoint", "http://localhost:8082", "The address of the KEDA Operator debug endpoint.")
+	timeout := flags.Duration("timeout", 30*time.Second, "The timeout of the request to the KEDA Operator.")
+	token := flags.String("token", os.Getenv("KUBECTL_KEDA_TOKEN"), "The bearer token the request is authenticated with, eg. the token of a ServiceAccount. Defaults to $KUBECTL_KEDA_TOKEN.")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	if len(os.Args) >= 2 && os.Args[1] == "validate" {
+		validate(os.Args[2:])
+		return
+	}
+	if len(os.Args) < 2 || os.Args[1] != "check" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// allow flags both before and after the positional arguments
+	args := parseInterspersed(flags, os.Args[2:])
+
+	resource := "scaledobjects"
+	switch {
+	case len(args) == 1:
+	case len(args) == 2:
+		var err error
+		resource, err = normalizeResource(args[0])
+		if err != nil {
+			exitWithError(err)
+		}
+		args = args[1:]
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	result, err := check(*endpoint, *namespace, resource, args[0], *token, *timeout)
+	if err != nil {
+		exitWithError(err)
+	}
+	printCheck(os.Stdout, result)
+}
+
+func parseInterspersed(flags *flag.FlagSet, arguments []string) []string {
+	var positional []string
+	for {
+		if err := flags.Parse(arguments); err != nil {
+			exitWithError(err)
+		}
+		arguments = flags.Args()
+		if len(arguments) == 0 {
+			return positional
+		}
+		positional = append(positional, arguments[0])
+		arguments = arguments[1:]
+	}
+}
+
+func normalizeResource(resource string) (string, error) {
+	switch strings.ToLower(resource) {
+	case "so", "scaledobject", "scaledobjects":
+		return "scaledobjects", nil
+	case "sj", "scaledjob", "scaledjobs":
+		return "scaledjobs", nil
+	default:
+		return "", fmt.Errorf("unknown resource %q, expected scaledobject or scaledjob", resource)
+	}
+}
+
+func check(endpoint, namespace, resource, name, token string, timeout time.Duration) (*debug.ScalableObjectCheck, error) {
+	u := strings.TrimSuffix(endpoint, "/") + debug.CheckPathPrefix +
+		"namespaces/" + url.PathEscape(namespace) + "/" + resource + "/" + url.PathEscape(name)
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("KEDA Operator returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	result := &debug.ScalableObjectCheck{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func printCheck(out io.Writer, result *debug.ScalableObjectCheck) {
+	fmt.Fprintf(out, "%s %s/%s: %d trigger(s), %d scaler(s)\n\n", result.Kind, result.Namespace, result.Name, result.Triggers, len(result.Scalers))
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tSCALER\tACTIVE\tMETRIC\tVALUE\tTARGET\tERROR")
+	for _, s := range result.Scalers {
+		if len(s.Metrics) == 0 {
+			fmt.Fprintf(w, "%d\t%s\t%s\t-\t-\t-\t%s\n", s.Index, s.Type, strconv.FormatBool(s.IsActive), s.Error)
+		}
+		for _, m := range s.Metrics {
+			errMsg := m.Error
+			if errMsg == "" {
+				errMsg = s.Error
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Index, s.Type, strconv.FormatBool(s.IsActive), m.Name, m.Value, m.Target, errMsg)
+		}
+	}
+	w.Flush()
+
+	if len(result.Errors) > 0 {
+		fmt.Fprintln(out, "\nErrors:")
+		for _, e := range result.Errors {
+			fmt.Fprintf(out, "  %s\n", e)
+		}
+	}
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/webhooks"
+)
+
+const validateUsage = `Usage: kubectl keda validate -f <file>
+
+Parses the trigger metadata of the ScaledObjects and ScaledJobs of a YAML or JSON
+manifest with the parsers of their scalers, without connecting to the backends.
+The other documents of the manifest are skipped.
+
+The values resolved in the cluster, from the authenticationRef, templateRef and
+metadataValueFrom of the triggers or from the env of the scale target, aren't
+available, so the issues of these triggers are reported as warnings.
+The command exits with 1 if a trigger is invalid.
+
+Flags:
+`
+
+func validate(arguments []string) {
+	flags := flag.NewFlagSet("kubectl-keda validate", flag.ExitOnError)
+	file := flags.String("f", "", "The manifest to validate, - reads it from the standard input.")
+	namespace := flags.String("n", "default", "The namespace of the objects which don't set one.")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, validateUsage)
+		flags.PrintDefaults()
+	}
+
+	if len(parseInterspersed(flags, arguments)) > 0 || *file == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	invalid, err := validateFile(*file, *namespace)
+	if err != nil {
+		exitWithError(err)
+	}
+	if invalid {
+		os.Exit(1)
+	}
+}
+
+func validateFile(file, namespace string) (bool, error) {
+	if file == "-" {
+		return validateManifest(os.Stdout, os.Stdin, namespace)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return validateManifest(os.Stdout, f, namespace)
+}
+
+// validateManifest prints the issues of the triggers of the ScaledObjects and ScaledJobs of the manifest, it returns
+// true if one of them is invalid
+func validateManifest(out io.Writer, in io.Reader, namespace string) (bool, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(in, 4096)
+	invalid := false
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return invalid, nil
+			}
+			return invalid, err
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return invalid, err
+		}
+		var objectMeta metav1.ObjectMeta
+		var triggers []kedav1alpha1.ScaleTriggers
+		switch typeMeta.Kind {
+		case "ScaledObject":
+			scaledObject := &kedav1alpha1.ScaledObject{}
+			if err := json.Unmarshal(raw, scaledObject); err != nil {
+				return invalid, fmt.Errorf("error decoding ScaledObject: %s", err)
+			}
+			objectMeta, triggers = scaledObject.ObjectMeta, scaledObject.Spec.Triggers
+		case "ScaledJob":
+			scaledJob := &kedav1alpha1.ScaledJob{}
+			if err := json.Unmarshal(raw, scaledJob); err != nil {
+				return invalid, fmt.Errorf("error decoding ScaledJob: %s", err)
+			}
+			objectMeta, triggers = scaledJob.ObjectMeta, scaledJob.Spec.Triggers
+		default:
+			continue
+		}
+		if objectMeta.Namespace == "" {
+			objectMeta.Namespace = namespace
+		}
+
+		issues := webhooks.ValidateTriggers(objectMeta.Namespace, objectMeta.Name, triggers)
+		if len(issues) == 0 {
+			fmt.Fprintf(out, "%s %s/%s: %d trigger(s) valid\n", typeMeta.Kind, objectMeta.Namespace, objectMeta.Name, len(triggers))
+			continue
+		}
+		fmt.Fprintf(out, "%s %s/%s:\n", typeMeta.Kind, objectMeta.Namespace, objectMeta.Name)
+		for _, issue := range issues {
+			if issue.Unresolved {
+				fmt.Fprintf(out, "  warning: %s\n", issue)
+				continue
+			}
+			invalid = true
+			fmt.Fprintf(out, "  error: %s\n", issue)
+		}
+	}
+}
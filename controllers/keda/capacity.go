@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// capacityResyncInterval is how often the HPA maxReplicas is capped again at the capacity of the nodes
+const capacityResyncInterval = 5 * time.Minute
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// capHPAMaxReplicasAtCapacity returns maxReplicas capped at the replicas the nodes selected by the capacityCap can
+// place, it is never lower than MinReplicas
+func (r *ScaledObjectReconciler) capHPAMaxReplicasAtCapacity(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, maxReplicas int32) int32 {
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.CapacityCap == nil {
+		return maxReplicas
+	}
+	capacityCap := scaledObject.Spec.Advanced.CapacityCap
+
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(ctx, nodes, client.MatchingLabels(capacityCap.NodeSelector)); err != nil {
+		logger.Error(err, "Error listing the nodes, the HPA maxReplicas is not capped at their capacity")
+		return maxReplicas
+	}
+
+	capacity := getSchedulableReplicas(nodes.Items, capacityCap)
+	if capacity < int64(maxReplicas) {
+		logger.V(1).Info("Capping the HPA maxReplicas at the capacity of the nodes", "maxReplicas", maxReplicas, "capacity", capacity, "resource", getCapacityResourceName(capacityCap))
+		maxReplicas = int32(capacity)
+	}
+	if minReplicas := getHPAMinReplicas(scaledObject); maxReplicas < *minReplicas {
+		maxReplicas = *minReplicas
+	}
+	return maxReplicas
+}
+
+// getSchedulableReplicas returns the replicas requesting the resource of the capacityCap the schedulable nodes can
+// place, the resource requested by the other Pods isn't deducted, it is the capacity the cluster can ever offer
+func getSchedulableReplicas(nodes []corev1.Node, capacityCap *kedav1alpha1.CapacityCap) int64 {
+	resourceName := getCapacityResourceName(capacityCap)
+	perReplica := int64(1000)
+	if capacityCap.ResourcePerReplica != nil {
+		perReplica = capacityCap.ResourcePerReplica.MilliValue()
+	}
+
+	var replicas int64
+	for _, node := range nodes {
+		if !isNodeSchedulable(&node) {
+			continue
+		}
+		if allocatable, ok := node.Status.Allocatable[resourceName]; ok {
+			// the replicas of a node are whole, a replica can't span nodes
+			replicas += allocatable.MilliValue() / perReplica
+		}
+	}
+	return replicas
+}
+
+// getCapacityResourceName returns the resource of the capacityCap, the Pod slots of the nodes by default
+func getCapacityResourceName(capacityCap *kedav1alpha1.CapacityCap) corev1.ResourceName {
+	if capacityCap.ResourceName != "" {
+		return capacityCap.ResourceName
+	}
+	return corev1.ResourcePods
+}
+
+// isNodeSchedulable returns whether new Pods can be placed on the node, it isn't cordoned and it is ready
+func isNodeSchedulable(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// validateCapacityCap checks the capacityCap of the ScaledObject
+func validateCapacityCap(capacityCap *kedav1alpha1.CapacityCap) error {
+	if capacityCap == nil {
+		return nil
+	}
+	if capacityCap.ResourcePerReplica != nil && capacityCap.ResourcePerReplica.MilliValue() <= 0 {
+		return fmt.Errorf("capacityCap resourcePerReplica %s must be positive", capacityCap.ResourcePerReplica.String())
+	}
+	return nil
+}
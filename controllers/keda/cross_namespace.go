@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// +kubebuilder:rbac:groups="authorization.k8s.io",resources=subjectaccessreviews,verbs=create
+
+// checkCrossNamespaceTarget checks that the namespace of the scale target of a ScaledObject scaling a workload of
+// another namespace consents to it with its annotation, and that it grants the service accounts of the namespace of
+// the ScaledObject the update of the scale subresource of the target, so the ScaledObject can't scale workloads its
+// namespace couldn't scale itself
+func (r *ScaledObjectReconciler) checkCrossNamespaceTarget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) error {
+	for _, target := range scaledObject.Spec.AdditionalScaleTargets {
+		if target.ScaleTargetRef != nil && target.ScaleTargetRef.Namespace != "" {
+			return fmt.Errorf("the namespace of the scaleTargetRef of the additionalScaleTargets can't be set")
+		}
+	}
+	if !scaledObject.IsCrossNamespace() {
+		return nil
+	}
+	targetNamespace := scaledObject.GetScaleTargetNamespace()
+	if len(scaledObject.Spec.AdditionalScaleTargets) > 0 {
+		return fmt.Errorf("additionalScaleTargets can't be used with a scaleTargetRef in namespace %s", targetNamespace)
+	}
+	if _, transfer := scaledObject.Annotations[kedav1alpha1.ScaledObjectTransferHpaOwnershipAnnotation]; transfer {
+		return fmt.Errorf("the ownership of an HPA in namespace %s can't be transferred", targetNamespace)
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: targetNamespace}, namespace); err != nil {
+		return fmt.Errorf("error getting the namespace %s of the scaleTargetRef: %s", targetNamespace, err)
+	}
+	if !isNamespaceAllowed(namespace.Annotations[kedav1alpha1.ScaleTargetAllowedNamespacesAnnotation], scaledObject.Namespace) {
+		return fmt.Errorf("namespace %s doesn't allow the ScaledObjects of namespace %s in its %s annotation", targetNamespace, scaledObject.Namespace, kedav1alpha1.ScaleTargetAllowedNamespacesAnnotation)
+	}
+
+	gvkr, err := kedautil.ParseGVKR(r.restMapper, scaledObject.Spec.ScaleTargetRef.APIVersion, scaledObject.Spec.ScaleTargetRef.Kind)
+	if err != nil {
+		return err
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			Groups: []string{"system:serviceaccounts:" + scaledObject.Namespace},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   targetNamespace,
+				Verb:        "update",
+				Group:       gvkr.Group,
+				Resource:    gvkr.Resource,
+				Subresource: "scale",
+				Name:        scaledObject.Spec.ScaleTargetRef.Name,
+			},
+		},
+	}
+	if err := r.Client.Create(ctx, review); err != nil {
+		return fmt.Errorf("error reviewing the access to the scaleTargetRef: %s", err)
+	}
+	if !review.Status.Allowed {
+		return fmt.Errorf("the service accounts of namespace %s aren't allowed to update %s/scale %s/%s", scaledObject.Namespace, gvkr.Resource, targetNamespace, scaledObject.Spec.ScaleTargetRef.Name)
+	}
+	return nil
+}
+
+// isNamespaceAllowed returns true if the comma separated list of namespaces allowed includes namespace or "*"
+func isNamespaceAllowed(allowed string, namespace string) bool {
+	for _, entry := range strings.Split(allowed, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == namespace || entry == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// mapCrossNamespaceHPA returns the request of the ScaledObject of an HPA created in the namespace of the scale target
+// of a ScaledObject of another namespace
+func mapCrossNamespaceHPA(obj client.Object) []reconcile.Request {
+	namespace, ok := obj.GetLabels()[kedav1alpha1.ScaledObjectNamespaceLabel]
+	name := obj.GetLabels()[partOfLabel]
+	if !ok || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
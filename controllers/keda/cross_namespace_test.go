@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// reviewingClient answers the SubjectAccessReviews with allowed
+type reviewingClient struct {
+	client.Client
+	allowed bool
+	reviews []*authorizationv1.SubjectAccessReview
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		review.Status.Allowed = c.allowed
+		c.reviews = append(c.reviews, review)
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("cross namespace scale target", func() {
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	newScaledObject := func(targetNamespace string) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "platform"},
+			Spec:       kedav1alpha1.ScaledObjectSpec{ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders-worker", Namespace: targetNamespace}},
+		}
+	}
+	newReconciler := func(allowed bool, annotation string) (*ScaledObjectReconciler, *reviewingClient) {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+		if annotation != "" {
+			namespace.Annotations = map[string]string{kedav1alpha1.ScaleTargetAllowedNamespacesAnnotation: annotation}
+		}
+		kubeClient := &reviewingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace).Build(), allowed: allowed}
+		return &ScaledObjectReconciler{Client: kubeClient, Scheme: scheme}, kubeClient
+	}
+
+	It("should not check a scale target in the namespace of the ScaledObject", func() {
+		r, kubeClient := newReconciler(false, "")
+		Expect(r.checkCrossNamespaceTarget(context.Background(), newScaledObject(""))).To(Succeed())
+		Expect(r.checkCrossNamespaceTarget(context.Background(), newScaledObject("platform"))).To(Succeed())
+		Expect(kubeClient.reviews).To(BeEmpty())
+	})
+
+	It("should allow a namespace consenting and granting the scale subresource", func() {
+		r, kubeClient := newReconciler(true, "ops, platform")
+		Expect(r.checkCrossNamespaceTarget(context.Background(), newScaledObject("shop"))).To(Succeed())
+		Expect(kubeClient.reviews).To(HaveLen(1))
+		Expect(kubeClient.reviews[0].Spec.Groups).To(Equal([]string{"system:serviceaccounts:platform"}))
+		Expect(*kubeClient.reviews[0].Spec.ResourceAttributes).To(Equal(authorizationv1.ResourceAttributes{
+			Namespace: "shop", Verb: "update", Group: "apps", Resource: "deployments", Subresource: "scale", Name: "orders-worker",
+		}))
+	})
+
+	It("should reject a namespace not consenting", func() {
+		r, kubeClient := newReconciler(true, "ops")
+		Expect(r.checkCrossNamespaceTarget(context.Background(), newScaledObject("shop"))).NotTo(Succeed())
+		r, _ = newReconciler(true, "")
+		Expect(r.checkCrossNamespaceTarget(context.Background(), newScaledObject("shop"))).NotTo(Succeed())
+		Expect(kubeClient.reviews).To(BeEmpty())
+	})
+
+	It("should reject a namespace not granting the scale subresource", func() {
+		r, _ := newReconciler(false, "*")
+		Expect(r.checkCrossNamespaceTarget(context.Background(), newScaledObject("shop"))).NotTo(Succeed())
+	})
+
+	It("should reject a namespace in the additionalScaleTargets", func() {
+		r, _ := newReconciler(true, "*")
+		scaledObject := newScaledObject("")
+		scaledObject.Spec.AdditionalScaleTargets = []kedav1alpha1.AdditionalScaleTarget{{ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders-api", Namespace: "shop"}}}
+		Expect(r.checkCrossNamespaceTarget(context.Background(), scaledObject)).NotTo(Succeed())
+	})
+
+	It("should create the HPA in the namespace of the scale target", func() {
+		scaledObject := newScaledObject("shop")
+		Expect(scaledObject.GetHPAName()).To(Equal("keda-hpa-platform-orders"))
+		Expect(getMetricSelectorLabels(scaledObject)).To(Equal(map[string]string{"scaledobject.keda.sh/name": "orders", kedav1alpha1.ScaledObjectNamespaceLabel: "platform"}))
+
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		hpa.Namespace = scaledObject.GetScaleTargetNamespace()
+		hpa.Labels = map[string]string{kedav1alpha1.ScaledObjectNamespaceLabel: "platform", partOfLabel: "orders"}
+		requests := mapCrossNamespaceHPA(hpa)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Namespace).To(Equal("platform"))
+		Expect(requests[0].Name).To(Equal("orders"))
+
+		delete(hpa.Labels, kedav1alpha1.ScaledObjectNamespaceLabel)
+		Expect(mapCrossNamespaceHPA(hpa)).To(BeEmpty())
+	})
+})
@@ -21,37 +21,60 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/go-logr/logr"
 	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/scaling/schedule"
 	version "github.com/kedacore/keda/v2/version"
 )
 
 const (
 	defaultHPAMinReplicas int32 = 1
 	defaultHPAMaxReplicas int32 = 100
+
+	// partitionCountResyncInterval is how often the HPA maxReplicas is capped again at the partition count
+	partitionCountResyncInterval = 5 * time.Minute
+
+	// budgetResyncInterval is how often the HPA maxReplicas is capped again at the daily budget
+	budgetResyncInterval = time.Minute
+
+	// managedLabelsAnnotation and managedAnnotationsAnnotation on an HPA are the comma separated keys of the labels
+	// and annotations of the ScaledObject set on it, the keys removed from the ScaledObject are removed from the HPA
+	// and the ones set by others are kept
+	managedLabelsAnnotation      = "scaledobject.keda.sh/managed-labels"
+	managedAnnotationsAnnotation = "scaledobject.keda.sh/managed-annotations"
+
+	// maxHPAStabilizationWindow is the longest stabilization window accepted by the HPA, one hour
+	maxHPAStabilizationWindow int32 = 3600
 )
 
 // createAndDeployNewHPA creates and deploy HPA in the cluster for specified ScaledObject
 func (r *ScaledObjectReconciler) createAndDeployNewHPA(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource) error {
 	hpaName := getHPAName(scaledObject)
-	logger.Info("Creating a new HPA", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", hpaName)
+	hpaNamespace := scaledObject.GetScaleTargetNamespace()
+	logger.Info("Creating a new HPA", "HPA.Namespace", hpaNamespace, "HPA.Name", hpaName)
 	hpa, err := r.newHPAForScaledObject(ctx, logger, scaledObject, gvkr)
 	if err != nil {
-		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", hpaName)
+		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", hpaNamespace, "HPA.Name", hpaName)
 		return err
 	}
 
 	err = r.Client.Create(ctx, hpa)
 	if err != nil {
-		logger.Error(err, "Failed to create new HPA in cluster", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", hpaName)
+		logger.Error(err, "Failed to create new HPA in cluster", "HPA.Namespace", hpaNamespace, "HPA.Name", hpaName)
 		return err
 	}
 
@@ -66,10 +89,8 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 	}
 
 	var behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior
-	if r.kubeVersion.MinorVersion >= 18 && scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig != nil {
-		behavior = scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior
-	} else {
-		behavior = nil
+	if r.kubeVersion.MinorVersion >= 18 {
+		behavior = getHPABehavior(scaledObject)
 	}
 
 	// label can have max 63 chars
@@ -89,11 +110,42 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 	for key, value := range scaledObject.ObjectMeta.Labels {
 		labels[key] = value
 	}
+	if scaledObject.IsCrossNamespace() {
+		labels[kedav1alpha1.ScaledObjectNamespaceLabel] = scaledObject.Namespace
+	}
+
+	annotations := map[string]string{}
+	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig != nil {
+		for key, value := range scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Labels {
+			labels[key] = value
+		}
+		for key, value := range scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Annotations {
+			annotations[key] = value
+		}
+	}
+	annotations[managedAnnotationsAnnotation] = joinSortedKeys(annotations)
+	annotations[managedLabelsAnnotation] = joinSortedKeys(labels)
+
+	// the replica bounds of active schedule windows, the reconciler is requeued when a window starts or ends
+	now := time.Now()
+	scheduled, _, err := schedule.Apply(scaledObject, now)
+	if err != nil {
+		return nil, err
+	}
+	// capped by the budget, after the schedules so they can't raise the replicas above it
+	scheduled, _, err = schedule.ApplyBudget(scheduled, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// the replicas beyond the partition count would be idle and the ones beyond the capacity of the nodes pending
+	maxReplicas := r.getHPAMaxReplicasFromPartitions(ctx, logger, scheduled)
+	maxReplicas = r.capHPAMaxReplicasAtCapacity(ctx, logger, scheduled, maxReplicas)
 
 	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
 		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
-			MinReplicas: getHPAMinReplicas(scaledObject),
-			MaxReplicas: getHPAMaxReplicas(scaledObject),
+			MinReplicas: getHPAMinReplicas(scheduled),
+			MaxReplicas: maxReplicas,
 			Metrics:     scaledObjectMetricSpecs,
 			Behavior:    behavior,
 			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
@@ -102,16 +154,21 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 				APIVersion: gvkr.GroupVersion().String(),
 			}},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getHPAName(scaledObject),
-			Namespace: scaledObject.Namespace,
-			Labels:    labels,
+			Name:        getHPAName(scaledObject),
+			Namespace:   scaledObject.GetScaleTargetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v2beta2",
 		},
 	}
 
-	// Set ScaledObject instance as the owner and controller
+	// Set ScaledObject instance as the owner and controller, an HPA in another namespace can't reference it so it is
+	// deleted by the finalizer of the ScaledObject
+	if scaledObject.IsCrossNamespace() {
+		return hpa, nil
+	}
 	if err := controllerutil.SetControllerReference(scaledObject, hpa, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -119,41 +176,165 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 	return hpa, nil
 }
 
-// updateHPAIfNeeded checks whether update of HPA is needed
+// updateHPAIfNeeded checks whether update of HPA is needed, the changes of the spec, labels and annotations are
+// sent in a single update
 func (r *ScaledObjectReconciler) updateHPAIfNeeded(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, foundHpa *autoscalingv2beta2.HorizontalPodAutoscaler, gvkr *kedav1alpha1.GroupVersionKindResource) error {
 	hpa, err := r.newHPAForScaledObject(ctx, logger, scaledObject, gvkr)
 	if err != nil {
-		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", getHPAName(scaledObject))
+		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", getHPAName(scaledObject))
 		return err
 	}
 
 	// DeepDerivative ignores extra entries in arrays which makes removing the last trigger not update things, so trigger and update any time the metrics count is different.
-	if len(hpa.Spec.Metrics) != len(foundHpa.Spec.Metrics) || !equality.Semantic.DeepDerivative(hpa.Spec, foundHpa.Spec) {
+	specChanged := len(hpa.Spec.Metrics) != len(foundHpa.Spec.Metrics) || !equality.Semantic.DeepDerivative(hpa.Spec, foundHpa.Spec)
+	if specChanged {
 		logger.V(1).Info("Found difference in the HPA spec accordint to ScaledObject", "currentHPA", foundHpa.Spec, "newHPA", hpa.Spec)
-		if r.Client.Update(ctx, hpa) != nil {
-			foundHpa.Spec = hpa.Spec
-			logger.Error(err, "Failed to update HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
-			return err
-		}
+	}
+	labels, labelsChanged := mergeManagedKeys(foundHpa.ObjectMeta.Labels, hpa.ObjectMeta.Labels, foundHpa.ObjectMeta.Annotations[managedLabelsAnnotation])
+	if labelsChanged {
+		logger.V(1).Info("Found difference in the HPA labels accordint to ScaledObject", "currentHPA", foundHpa.ObjectMeta.Labels, "newHPA", labels)
+	}
+	annotations, annotationsChanged := mergeManagedKeys(foundHpa.ObjectMeta.Annotations, hpa.ObjectMeta.Annotations, foundHpa.ObjectMeta.Annotations[managedAnnotationsAnnotation])
+	if annotationsChanged {
+		logger.V(1).Info("Found difference in the HPA annotations according to ScaledObject", "currentHPA", foundHpa.ObjectMeta.Annotations, "newHPA", annotations)
+	}
+	hpa.ObjectMeta.Labels, hpa.ObjectMeta.Annotations = labels, annotations
+	// the entries set by others were merged from foundHpa, a newer HPA fails the update and is merged again
+	hpa.ResourceVersion = foundHpa.ResourceVersion
+	if !specChanged && !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	if err := r.Client.Update(ctx, hpa); err != nil {
+		logger.Error(err, "Failed to update HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+		return err
+	}
+	foundHpa.Spec, foundHpa.ObjectMeta.Labels, foundHpa.ObjectMeta.Annotations = hpa.Spec, hpa.ObjectMeta.Labels, hpa.ObjectMeta.Annotations
+	if specChanged {
 		// check if scaledObject.spec.behavior was defined, because it is supported only on k8s >= 1.18
 		r.checkMinK8sVersionforHPABehavior(logger, scaledObject)
+	}
 
-		logger.Info("Updated HPA according to ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+	logger.Info("Updated HPA according to ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+	return nil
+}
+
+// mergeManagedKeys returns the entries of found with the desired ones set and the previously managed ones which aren't
+// desired anymore removed, and whether they differ from found. The entries set by others are kept.
+func mergeManagedKeys(found, desired map[string]string, previouslyManaged string) (map[string]string, bool) {
+	merged := make(map[string]string, len(found)+len(desired))
+	for key, value := range found {
+		merged[key] = value
+	}
+	changed := false
+	if previouslyManaged != "" {
+		for _, key := range strings.Split(previouslyManaged, ",") {
+			if _, ok := desired[key]; ok {
+				continue
+			}
+			if _, ok := merged[key]; ok {
+				delete(merged, key)
+				changed = true
+			}
+		}
+	}
+	for key, value := range desired {
+		if current, ok := merged[key]; !ok || current != value {
+			merged[key] = value
+			changed = true
+		}
 	}
+	return merged, changed
+}
 
-	if !equality.Semantic.DeepDerivative(hpa.ObjectMeta.Labels, foundHpa.ObjectMeta.Labels) {
-		logger.V(1).Info("Found difference in the HPA labels accordint to ScaledObject", "currentHPA", foundHpa.ObjectMeta.Labels, "newHPA", hpa.ObjectMeta.Labels)
-		if r.Client.Update(ctx, hpa) != nil {
-			foundHpa.ObjectMeta.Labels = hpa.ObjectMeta.Labels
-			logger.Error(err, "Failed to update HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
-			return err
+// joinSortedKeys returns the comma separated sorted keys of entries
+func joinSortedKeys(entries map[string]string) string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// adoptHPA transfers the ownership of an existing HPA to the ScaledObject, the HPA is updated in place
+// so the scale target keeps its replica count
+func (r *ScaledObjectReconciler) adoptHPA(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, foundHpa *autoscalingv2beta2.HorizontalPodAutoscaler, gvkr *kedav1alpha1.GroupVersionKindResource) error {
+	if owner := metav1.GetControllerOf(foundHpa); owner != nil {
+		return fmt.Errorf("HPA %s is already controlled by %s %s, it can't be adopted by ScaledObject %s", foundHpa.Name, owner.Kind, owner.Name, scaledObject.Name)
+	}
+
+	hpa, err := r.newHPAForScaledObject(ctx, logger, scaledObject, gvkr)
+	if err != nil {
+		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+		return err
+	}
+	hpa.ResourceVersion = foundHpa.ResourceVersion
+	for key, value := range foundHpa.Labels {
+		if _, ok := hpa.Labels[key]; !ok {
+			hpa.Labels[key] = value
 		}
-		logger.Info("Updated HPA according to ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+	}
+	for key, value := range foundHpa.Annotations {
+		if _, ok := hpa.Annotations[key]; !ok {
+			hpa.Annotations[key] = value
+		}
+	}
+
+	if err := r.Client.Update(ctx, hpa); err != nil {
+		logger.Error(err, "Failed to adopt HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+		return err
+	}
+	logger.Info("Adopted existing HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+	r.Recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAHPAOwnershipTransferred, "Ownership of HPA %s was transferred to ScaledObject", foundHpa.Name)
+	return nil
+}
+
+// deleteHPAOfScaledObject deletes the HPA of the name in the namespace of the scale target if the ScaledObject manages
+// it, the HPAs of other owners are kept
+func (r *ScaledObjectReconciler) deleteHPAOfScaledObject(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, name string) error {
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: scaledObject.GetScaleTargetNamespace()}, hpa)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !isHPAOfScaledObject(hpa, scaledObject) {
+		return nil
 	}
 
+	if err := r.Client.Delete(ctx, hpa); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete the HPA of the ScaledObject", "HPA.Namespace", hpa.Namespace, "HPA.Name", hpa.Name)
+		return err
+	}
+	logger.Info("Deleted the HPA of the ScaledObject", "HPA.Namespace", hpa.Namespace, "HPA.Name", hpa.Name)
+	r.Recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAHPADeleted, "HPA %s was deleted", hpa.Name)
 	return nil
 }
 
+// updateHPANameStatus stores the name of the HPA of the ScaledObject, an empty name once it has no HPA
+func (r *ScaledObjectReconciler) updateHPANameStatus(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, name string) error {
+	if scaledObject.Status.HpaName == name {
+		return nil
+	}
+	status := scaledObject.Status.DeepCopy()
+	status.HpaName = name
+	return kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
+}
+
+// isHPAOfScaledObject returns whether the HPA is controlled by the ScaledObject, the HPAs in the namespace of a scale
+// target of another namespace can't reference it and are matched by their labels
+func isHPAOfScaledObject(hpa *autoscalingv2beta2.HorizontalPodAutoscaler, scaledObject *kedav1alpha1.ScaledObject) bool {
+	if scaledObject.IsCrossNamespace() {
+		return metav1.GetControllerOf(hpa) == nil &&
+			hpa.Labels["app.kubernetes.io/managed-by"] == "keda-operator" &&
+			hpa.Labels["app.kubernetes.io/part-of"] == scaledObject.Name &&
+			hpa.Labels[kedav1alpha1.ScaledObjectNamespaceLabel] == scaledObject.Namespace
+	}
+	return metav1.IsControlledBy(hpa, scaledObject)
+}
+
 // getScaledObjectMetricSpecs returns MetricSpec for HPA, generater from Triggers defitinion in ScaledObject
 func (r *ScaledObjectReconciler) getScaledObjectMetricSpecs(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) ([]autoscalingv2beta2.MetricSpec, error) {
 	var scaledObjectMetricSpecs []autoscalingv2beta2.MetricSpec
@@ -173,6 +354,10 @@ func (r *ScaledObjectReconciler) getScaledObjectMetricSpecs(ctx context.Context,
 			resourceMetricNames = append(resourceMetricNames, string(metricSpec.Resource.Name))
 		}
 
+		if metricSpec.ContainerResource != nil {
+			resourceMetricNames = append(resourceMetricNames, string(metricSpec.ContainerResource.Name))
+		}
+
 		if metricSpec.External != nil {
 			externalMetricName := metricSpec.External.Metric.Name
 			if kedacontrollerutil.Contains(externalMetricNames, externalMetricName) {
@@ -180,11 +365,14 @@ func (r *ScaledObjectReconciler) getScaledObjectMetricSpecs(ctx context.Context,
 			}
 
 			// add the scaledobject.keda.sh/name label. This is how the MetricsAdapter will know which scaledobject a metric is for when the HPA queries it.
-			metricSpec.External.Metric.Selector = &metav1.LabelSelector{MatchLabels: make(map[string]string)}
-			metricSpec.External.Metric.Selector.MatchLabels["scaledobject.keda.sh/name"] = scaledObject.Name
+			metricSpec.External.Metric.Selector = &metav1.LabelSelector{MatchLabels: getMetricSelectorLabels(scaledObject)}
 			externalMetricNames = append(externalMetricNames, externalMetricName)
 		}
 	}
+	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.ReplicaCalculatorWebhook != nil {
+		metricSpecs = getReplicaCalculatorMetricSpecs(scaledObject, metricSpecs)
+		externalMetricNames = append(externalMetricNames, kedav1alpha1.ReplicaCalculatorMetricName)
+	}
 	scaledObjectMetricSpecs = append(scaledObjectMetricSpecs, metricSpecs...)
 
 	// sort metrics in ScaledObject, this way we always check the same resource in Reconcile loop and we can prevent unnecessary HPA updates,
@@ -200,15 +388,52 @@ func (r *ScaledObjectReconciler) getScaledObjectMetricSpecs(ctx context.Context,
 
 	updateHealthStatus(scaledObject, externalMetricNames, status)
 
-	err = kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
-	if err != nil {
-		logger.Error(err, "Error updating scaledObject status with used externalMetricNames")
-		return nil, err
+	// the status is only patched when the metric names change, the specs are the same on most reconciles
+	if !equality.Semantic.DeepEqual(status, &scaledObject.Status) {
+		err = kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
+		if err != nil {
+			logger.Error(err, "Error updating scaledObject status with used externalMetricNames")
+			return nil, err
+		}
 	}
 
 	return scaledObjectMetricSpecs, nil
 }
 
+// getReplicaCalculatorMetricSpecs replaces the external metrics of the triggers with the metric of the replica
+// calculator, its value is the replica count decided by the webhook so its average target is 1 replica
+func getReplicaCalculatorMetricSpecs(scaledObject *kedav1alpha1.ScaledObject, metricSpecs []autoscalingv2beta2.MetricSpec) []autoscalingv2beta2.MetricSpec {
+	replicaCalculatorMetricSpecs := make([]autoscalingv2beta2.MetricSpec, 0, len(metricSpecs))
+	for _, metricSpec := range metricSpecs {
+		if metricSpec.External == nil {
+			replicaCalculatorMetricSpecs = append(replicaCalculatorMetricSpecs, metricSpec)
+		}
+	}
+	return append(replicaCalculatorMetricSpecs, autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ExternalMetricSourceType,
+		External: &autoscalingv2beta2.ExternalMetricSource{
+			Metric: autoscalingv2beta2.MetricIdentifier{
+				Name:     kedav1alpha1.ReplicaCalculatorMetricName,
+				Selector: &metav1.LabelSelector{MatchLabels: getMetricSelectorLabels(scaledObject)},
+			},
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:         autoscalingv2beta2.AverageValueMetricType,
+				AverageValue: resource.NewQuantity(1, resource.DecimalSI),
+			},
+		},
+	})
+}
+
+// getMetricSelectorLabels returns the labels of the selectors of the external metrics of the HPA, the metrics adapter
+// looks up the ScaledObject of the metrics with them, in the namespace of the HPA unless the namespace label is set
+func getMetricSelectorLabels(scaledObject *kedav1alpha1.ScaledObject) map[string]string {
+	selectorLabels := map[string]string{"scaledobject.keda.sh/name": scaledObject.Name}
+	if scaledObject.IsCrossNamespace() {
+		selectorLabels[kedav1alpha1.ScaledObjectNamespaceLabel] = scaledObject.Namespace
+	}
+	return selectorLabels
+}
+
 func updateHealthStatus(scaledObject *kedav1alpha1.ScaledObject, externalMetricNames []string, status *kedav1alpha1.ScaledObjectStatus) {
 	health := scaledObject.Status.Health
 	newHealth := make(map[string]kedav1alpha1.HealthStatus)
@@ -230,9 +455,41 @@ func (r *ScaledObjectReconciler) checkMinK8sVersionforHPABehavior(logger logr.Lo
 	}
 }
 
-// getHPAName returns generated HPA name for ScaledObject specified in the parameter
+// getHPABehavior returns the behavior of the HPA, with a scaleToZeroGracePeriod the cooldownPeriod is the
+// scale down stabilization window unless the behavior sets it
+func getHPABehavior(scaledObject *kedav1alpha1.ScaledObject) *autoscalingv2beta2.HorizontalPodAutoscalerBehavior {
+	var behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior
+	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig != nil {
+		behavior = scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior
+	}
+	// without cooldownPeriod the default window of the HPA is kept
+	if scaledObject.Spec.ScaleToZeroGracePeriod == nil || scaledObject.Spec.CooldownPeriod == nil {
+		return behavior
+	}
+	if behavior != nil && behavior.ScaleDown != nil && behavior.ScaleDown.StabilizationWindowSeconds != nil {
+		return behavior
+	}
+
+	if behavior == nil {
+		behavior = &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{}
+	} else {
+		behavior = behavior.DeepCopy()
+	}
+	if behavior.ScaleDown == nil {
+		behavior.ScaleDown = &autoscalingv2beta2.HPAScalingRules{}
+	}
+	window := *scaledObject.Spec.CooldownPeriod
+	if window > maxHPAStabilizationWindow {
+		window = maxHPAStabilizationWindow
+	}
+	behavior.ScaleDown.StabilizationWindowSeconds = &window
+	return behavior
+}
+
+// getHPAName returns generated HPA name for ScaledObject specified in the parameter,
+// the name of the HPA the ScaledObject adopted or the name set in horizontalPodAutoscalerConfig
 func getHPAName(scaledObject *kedav1alpha1.ScaledObject) string {
-	return fmt.Sprintf("keda-hpa-%s", scaledObject.Name)
+	return scaledObject.GetHPAName()
 }
 
 // getHPAMinReplicas returns MinReplicas based on definition in ScaledObject or default value if not defined
@@ -244,6 +501,35 @@ func getHPAMinReplicas(scaledObject *kedav1alpha1.ScaledObject) *int32 {
 	return &tmp
 }
 
+// getHPAMaxReplicasFromPartitions returns MaxReplicas capped at the partition count of the partitioned triggers
+// when maxReplicaFromPartitions is enabled, it is never lower than MinReplicas
+func (r *ScaledObjectReconciler) getHPAMaxReplicasFromPartitions(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) int32 {
+	maxReplicas := getHPAMaxReplicas(scaledObject)
+	if scaledObject.Spec.Advanced == nil || !scaledObject.Spec.Advanced.MaxReplicaFromPartitions {
+		return maxReplicas
+	}
+
+	cache, err := r.scaleHandler.GetScalersCache(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting scalers, the HPA maxReplicas is not capped at the partition count")
+		return maxReplicas
+	}
+	partitionCount, err := cache.GetPartitionCount(ctx)
+	if err != nil {
+		logger.Error(err, "Error getting the partition count, the HPA maxReplicas is not capped at the partition count")
+		return maxReplicas
+	}
+
+	if partitionCount > 0 && partitionCount < int64(maxReplicas) {
+		logger.V(1).Info("Capping the HPA maxReplicas at the partition count", "maxReplicas", maxReplicas, "partitionCount", partitionCount)
+		maxReplicas = int32(partitionCount)
+	}
+	if minReplicas := getHPAMinReplicas(scaledObject); maxReplicas < *minReplicas {
+		maxReplicas = *minReplicas
+	}
+	return maxReplicas
+}
+
 // getHPAMaxReplicas returns MaxReplicas based on definition in ScaledObject or default value if not defined
 func getHPAMaxReplicas(scaledObject *kedav1alpha1.ScaledObject) int32 {
 	if scaledObject.Spec.MaxReplicaCount != nil {
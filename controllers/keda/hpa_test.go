@@ -24,7 +24,16 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
@@ -118,6 +127,307 @@ var _ = Describe("hpa", func() {
 		Expect(capturedScaledObject.Status.Health).To(Equal(expectedHealth))
 	})
 
+	It("should adopt existing HPA with transfer-hpa-ownership annotation", func() {
+		scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+		scaledObject.Annotations = map[string]string{v1alpha1.ScaledObjectTransferHpaOwnershipAnnotation: "existing-hpa"}
+		scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "deployment"}
+		Expect(getHPAName(scaledObject)).To(Equal("existing-hpa"))
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reconciler.Scheme = scheme
+		reconciler.Recorder = record.NewFakeRecorder(1)
+
+		foundHpa := &v2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name:            "existing-hpa",
+				ResourceVersion: "42",
+				Labels:          map[string]string{"team": "a"},
+			},
+		}
+
+		var capturedHpa *v2beta2.HorizontalPodAutoscaler
+		client.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+		client.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(arg interface{}, hpa *v2beta2.HorizontalPodAutoscaler, opts ...interface{}) {
+			capturedHpa = hpa
+		})
+
+		gvkr := &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+		err := reconciler.adoptHPA(context.Background(), logger, scaledObject, foundHpa, gvkr)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capturedHpa.Name).To(Equal("existing-hpa"))
+		Expect(capturedHpa.ResourceVersion).To(Equal("42"))
+		Expect(capturedHpa.Labels).To(HaveKeyWithValue("team", "a"))
+		Expect(v1.IsControlledBy(capturedHpa, scaledObject)).To(BeTrue())
+	})
+
+	It("should use name, labels and annotations from horizontalPodAutoscalerConfig", func() {
+		scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+		scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "deployment"}
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{
+			HorizontalPodAutoscalerConfig: &v1alpha1.HorizontalPodAutoscalerConfig{
+				Name:        "custom-hpa",
+				Labels:      map[string]string{"cost-center": "42"},
+				Annotations: map[string]string{"policy": "allowed"},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reconciler.Scheme = scheme
+
+		client.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+
+		gvkr := &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+		hpa, err := reconciler.newHPAForScaledObject(context.Background(), logger, scaledObject, gvkr)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hpa.Name).To(Equal("custom-hpa"))
+		Expect(hpa.Labels).To(HaveKeyWithValue("cost-center", "42"))
+		Expect(hpa.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "keda-operator"))
+		Expect(hpa.Annotations).To(HaveKeyWithValue("policy", "allowed"))
+		Expect(hpa.Annotations).To(HaveKeyWithValue(managedAnnotationsAnnotation, "policy"))
+		Expect(hpa.Annotations[managedLabelsAnnotation]).To(ContainSubstring("cost-center"))
+	})
+
+	It("should update the HPA once when its spec, labels and annotations differ", func() {
+		scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+		scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "deployment"}
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{
+			HorizontalPodAutoscalerConfig: &v1alpha1.HorizontalPodAutoscalerConfig{
+				Labels:      map[string]string{"cost-center": "42"},
+				Annotations: map[string]string{"policy": "allowed"},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reconciler.Scheme = scheme
+
+		client.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+		client.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1)
+
+		foundHpa := &v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{Name: "keda-hpa-some scaled object name"}}
+		gvkr := &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+		err := reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa, gvkr)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(foundHpa.Labels).To(HaveKeyWithValue("cost-center", "42"))
+		Expect(foundHpa.Annotations).To(HaveKeyWithValue("policy", "allowed"))
+	})
+
+	It("should remove the labels and annotations removed from the ScaledObject", func() {
+		scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+		scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "deployment"}
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{
+			HorizontalPodAutoscalerConfig: &v1alpha1.HorizontalPodAutoscalerConfig{
+				Labels:      map[string]string{"cost-center": "42"},
+				Annotations: map[string]string{"policy": "allowed"},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		reconciler.Scheme = scheme
+
+		client.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+		client.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1)
+
+		gvkr := &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+		foundHpa, err := reconciler.newHPAForScaledObject(context.Background(), logger, scaledObject, gvkr)
+		Expect(err).ToNot(HaveOccurred())
+		// set by another controller
+		foundHpa.Labels["argocd.argoproj.io/instance"] = "shop"
+		foundHpa.Annotations["kubectl.kubernetes.io/last-applied-configuration"] = "{}"
+
+		scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Labels = nil
+		scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Annotations = nil
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{{External: &v2beta2.ExternalMetricSource{Metric: v2beta2.MetricIdentifier{Name: "some metric name"}}}})
+		scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Any()).Return(&cache.ScalersCache{Scalers: []cache.ScalerBuilder{{Scaler: scaler}}}, nil)
+		Expect(reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa, gvkr)).To(Succeed())
+
+		Expect(foundHpa.Labels).ToNot(HaveKey("cost-center"))
+		Expect(foundHpa.Labels).To(HaveKeyWithValue("argocd.argoproj.io/instance", "shop"))
+		Expect(foundHpa.Annotations).ToNot(HaveKey("policy"))
+		Expect(foundHpa.Annotations).To(HaveKeyWithValue("kubectl.kubernetes.io/last-applied-configuration", "{}"))
+	})
+
+	It("should not patch the status when the metric names are unchanged", func() {
+		scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+		scaledObject.Status.ExternalMetricNames = []string{"some metric name"}
+
+		specs, err := reconciler.getScaledObjectMetricSpecs(context.Background(), logger, scaledObject)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(specs).To(HaveLen(1))
+	})
+
+	It("should cap maxReplicas at the partition count with maxReplicaFromPartitions", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "so"}}
+		maxReplicas := int32(50)
+		scaledObject.Spec.MaxReplicaCount = &maxReplicas
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{MaxReplicaFromPartitions: true}
+
+		scalersCache := cache.ScalersCache{Scalers: []cache.ScalerBuilder{{Scaler: partitionedScaler{scaler, 12}}}}
+		scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Eq(scaledObject)).Return(&scalersCache, nil)
+		Expect(reconciler.getHPAMaxReplicasFromPartitions(context.Background(), logger, scaledObject)).To(Equal(int32(12)))
+
+		// not below minReplicas
+		minReplicas := int32(20)
+		scaledObject.Spec.MinReplicaCount = &minReplicas
+		scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Eq(scaledObject)).Return(&scalersCache, nil)
+		Expect(reconciler.getHPAMaxReplicasFromPartitions(context.Background(), logger, scaledObject)).To(Equal(int32(20)))
+
+		// disabled
+		scaledObject.Spec.Advanced.MaxReplicaFromPartitions = false
+		Expect(reconciler.getHPAMaxReplicasFromPartitions(context.Background(), logger, scaledObject)).To(Equal(int32(50)))
+	})
+
+	It("should cap maxReplicas at the capacity of the nodes with capacityCap", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "so"}}
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{CapacityCap: &v1alpha1.CapacityCap{ResourceName: "nvidia.com/gpu", NodeSelector: map[string]string{"accelerator": "gpu"}}}
+
+		ready := []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+		gpus := func(count string) corev1.ResourceList {
+			return corev1.ResourceList{"nvidia.com/gpu": resource.MustParse(count)}
+		}
+		nodes := []corev1.Node{
+			{Status: corev1.NodeStatus{Allocatable: gpus("4"), Conditions: ready}},
+			{Status: corev1.NodeStatus{Allocatable: gpus("2"), Conditions: ready}},
+			// cordoned and not ready nodes can't place the replicas
+			{Spec: corev1.NodeSpec{Unschedulable: true}, Status: corev1.NodeStatus{Allocatable: gpus("8"), Conditions: ready}},
+			{Status: corev1.NodeStatus{Allocatable: gpus("8")}},
+		}
+		client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, list runtime.Object, _ ...runtimeclient.ListOption) {
+			list.(*corev1.NodeList).Items = nodes
+		}).Return(nil).AnyTimes()
+		Expect(reconciler.capHPAMaxReplicasAtCapacity(context.Background(), logger, scaledObject, 50)).To(Equal(int32(6)))
+
+		// two GPUs per replica
+		perReplica := resource.MustParse("2")
+		scaledObject.Spec.Advanced.CapacityCap.ResourcePerReplica = &perReplica
+		Expect(reconciler.capHPAMaxReplicasAtCapacity(context.Background(), logger, scaledObject, 50)).To(Equal(int32(3)))
+		Expect(reconciler.capHPAMaxReplicasAtCapacity(context.Background(), logger, scaledObject, 2)).To(Equal(int32(2)))
+
+		// not below minReplicas
+		minReplicas := int32(5)
+		scaledObject.Spec.MinReplicaCount = &minReplicas
+		Expect(reconciler.capHPAMaxReplicasAtCapacity(context.Background(), logger, scaledObject, 50)).To(Equal(int32(5)))
+
+		// disabled
+		scaledObject.Spec.Advanced.CapacityCap = nil
+		Expect(reconciler.capHPAMaxReplicasAtCapacity(context.Background(), logger, scaledObject, 50)).To(Equal(int32(50)))
+
+		zero := resource.MustParse("0")
+		Expect(validateCapacityCap(&v1alpha1.CapacityCap{ResourcePerReplica: &zero})).To(HaveOccurred())
+		Expect(validateCapacityCap(&v1alpha1.CapacityCap{ResourcePerReplica: &perReplica})).To(Succeed())
+	})
+
+	It("should use cooldownPeriod as scale down window with scaleToZeroGracePeriod", func() {
+		cooldownPeriod := int32(60)
+		gracePeriod := int32(1800)
+		scaledObject := &v1alpha1.ScaledObject{Spec: v1alpha1.ScaledObjectSpec{CooldownPeriod: &cooldownPeriod}}
+		Expect(getHPABehavior(scaledObject)).To(BeNil())
+
+		scaledObject.Spec.ScaleToZeroGracePeriod = &gracePeriod
+		behavior := getHPABehavior(scaledObject)
+		Expect(*behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(int32(60)))
+
+		// the scale down window of the behavior is kept
+		window := int32(120)
+		configured := &v2beta2.HorizontalPodAutoscalerBehavior{ScaleDown: &v2beta2.HPAScalingRules{StabilizationWindowSeconds: &window}}
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{HorizontalPodAutoscalerConfig: &v1alpha1.HorizontalPodAutoscalerConfig{Behavior: configured}}
+		Expect(getHPABehavior(scaledObject)).To(Equal(configured))
+
+		// the scale up rules of the behavior are kept along with the window
+		configured = &v2beta2.HorizontalPodAutoscalerBehavior{ScaleUp: &v2beta2.HPAScalingRules{StabilizationWindowSeconds: &window}}
+		scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior = configured
+		behavior = getHPABehavior(scaledObject)
+		Expect(behavior.ScaleUp).To(Equal(configured.ScaleUp))
+		Expect(*behavior.ScaleDown.StabilizationWindowSeconds).To(Equal(int32(60)))
+		Expect(configured.ScaleDown).To(BeNil())
+	})
+
+	It("should not adopt HPA controlled by another object", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "so"}}
+		controller := true
+		foundHpa := &v2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name:            "existing-hpa",
+				OwnerReferences: []v1.OwnerReference{{Kind: "ScaledObject", Name: "other", Controller: &controller}},
+			},
+		}
+
+		err := reconciler.adoptHPA(context.Background(), logger, scaledObject, foundHpa, &v1alpha1.GroupVersionKindResource{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should delete the HPA of a ScaledObject switched to dry-run mode", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		scaledObject := &v1alpha1.ScaledObject{
+			ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "default", UID: "uid"},
+			Spec:       v1alpha1.ScaledObjectSpec{ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "orders"}},
+		}
+		ownedHpa := &v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{Name: "keda-hpa-orders", Namespace: "default"}}
+		Expect(controllerutil.SetControllerReference(scaledObject, ownedHpa, scheme)).To(Succeed())
+		foreignHpa := &v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "default"}}
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ownedHpa, foreignHpa).Build()
+		recorder := record.NewFakeRecorder(1)
+		dryRunReconciler := ScaledObjectReconciler{Client: kubeClient, Scheme: scheme, Recorder: recorder}
+
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{DryRun: true}
+		Expect(scaledObject.IsDryRun()).To(BeTrue())
+		Expect(dryRunReconciler.deleteHPAOfScaledObject(context.Background(), logger, scaledObject, getHPAName(scaledObject))).To(Succeed())
+		err := kubeClient.Get(context.Background(), runtimeclient.ObjectKeyFromObject(ownedHpa), &v2beta2.HorizontalPodAutoscaler{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(<-recorder.Events).To(ContainSubstring("KEDAHPADeleted"))
+
+		// the HPAs the ScaledObject doesn't control are kept, a missing HPA is no error
+		Expect(dryRunReconciler.deleteHPAOfScaledObject(context.Background(), logger, scaledObject, "orders")).To(Succeed())
+		Expect(kubeClient.Get(context.Background(), runtimeclient.ObjectKeyFromObject(foreignHpa), &v2beta2.HorizontalPodAutoscaler{})).To(Succeed())
+		Expect(dryRunReconciler.deleteHPAOfScaledObject(context.Background(), logger, scaledObject, getHPAName(scaledObject))).To(Succeed())
+	})
+
+	It("should delete the HPA of the previous name of a ScaledObject", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		scaledObject := &v1alpha1.ScaledObject{
+			ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "default", UID: "uid"},
+			Spec: v1alpha1.ScaledObjectSpec{
+				ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "orders"},
+				Advanced:       &v1alpha1.AdvancedConfig{HorizontalPodAutoscalerConfig: &v1alpha1.HorizontalPodAutoscalerConfig{Name: "orders-hpa"}},
+			},
+			Status: v1alpha1.ScaledObjectStatus{HpaName: "keda-hpa-orders"},
+		}
+		previousHpa := &v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{Name: "keda-hpa-orders", Namespace: "default"}}
+		Expect(controllerutil.SetControllerReference(scaledObject, previousHpa, scheme)).To(Succeed())
+		currentHpa := &v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{Name: "orders-hpa", Namespace: "default"}}
+		Expect(controllerutil.SetControllerReference(scaledObject, currentHpa, scheme)).To(Succeed())
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(scaledObject, previousHpa, currentHpa).Build()
+		renameReconciler := ScaledObjectReconciler{Client: kubeClient, Scheme: scheme, Recorder: record.NewFakeRecorder(1), scaleHandler: scaleHandler}
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{{External: &v2beta2.ExternalMetricSource{Metric: v2beta2.MetricIdentifier{Name: "orders"}}}})
+		scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Any()).Return(&cache.ScalersCache{Scalers: []cache.ScalerBuilder{{Scaler: scaler}}}, nil)
+
+		gvkr := &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+		created, err := renameReconciler.ensureHPAForScaledObjectExists(context.Background(), logger, scaledObject, gvkr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(BeFalse())
+
+		err = kubeClient.Get(context.Background(), runtimeclient.ObjectKeyFromObject(previousHpa), &v2beta2.HorizontalPodAutoscaler{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(kubeClient.Get(context.Background(), runtimeclient.ObjectKeyFromObject(currentHpa), &v2beta2.HorizontalPodAutoscaler{})).To(Succeed())
+		stored := &v1alpha1.ScaledObject{}
+		Expect(kubeClient.Get(context.Background(), runtimeclient.ObjectKeyFromObject(scaledObject), stored)).To(Succeed())
+		Expect(stored.Status.HpaName).To(Equal("orders-hpa"))
+	})
 })
 
 func setupTest(health map[string]v1alpha1.HealthStatus, scaler *mock_scalers.MockScaler, scaleHandler *mock_scaling.MockScaleHandler) *v1alpha1.ScaledObject {
@@ -154,3 +464,13 @@ func setupTest(health map[string]v1alpha1.HealthStatus, scaler *mock_scalers.Moc
 
 	return scaledObject
 }
+
+// partitionedScaler is a scaler of a partitioned source
+type partitionedScaler struct {
+	*mock_scalers.MockScaler
+	partitionCount int64
+}
+
+func (s partitionedScaler) GetPartitionCount(context.Context) (int64, error) {
+	return s.partitionCount, nil
+}
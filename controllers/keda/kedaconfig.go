@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// mapKedaConfig returns the requests of the objects of the shard listed with list, in the namespace of a KedaConfig
+// or in all the namespaces for a ClusterKedaConfig, so the objects are checked again when the limits change
+func mapKedaConfig(kubeClient client.Client, shardSelector labels.Selector, list client.ObjectList) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		var opts []client.ListOption
+		if _, ok := obj.(*kedav1alpha1.KedaConfig); ok {
+			opts = append(opts, client.InNamespace(obj.GetNamespace()))
+		}
+		objects := list.DeepCopyObject().(client.ObjectList)
+		if err := kubeClient.List(context.Background(), objects, opts...); err != nil {
+			log.Log.WithName("kedaconfig").Error(err, "Error listing the objects of the KedaConfig", "kedaConfig", obj.GetName())
+			return nil
+		}
+		items, err := meta.ExtractList(objects)
+		if err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, item := range items {
+			object, ok := item.(client.Object)
+			if !ok || !kedautil.IsInShard(shardSelector, object) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}})
+		}
+		return requests
+	}
+}
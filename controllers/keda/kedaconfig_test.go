@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+var _ = Describe("KedaConfig watch", func() {
+	var mapFunc handler.MapFunc
+
+	newScaledObject := func(namespace, name, shard string) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"shard": shard}}}
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newScaledObject("shop", "orders", "a"),
+			newScaledObject("shop", "refunds", "b"),
+			newScaledObject("dev", "orders", "a"),
+		).Build()
+		shardSelector, err := kedautil.ParseShardSelector("shard=a")
+		Expect(err).ToNot(HaveOccurred())
+		mapFunc = mapKedaConfig(kubeClient, shardSelector, &kedav1alpha1.ScaledObjectList{})
+	})
+
+	It("reconciles the ScaledObjects of the shard in the namespace of a KedaConfig", func() {
+		Expect(mapFunc(&kedav1alpha1.KedaConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "limits"}})).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "shop", Name: "orders"}},
+		))
+	})
+
+	It("reconciles the ScaledObjects of the shard in all the namespaces for a ClusterKedaConfig", func() {
+		Expect(mapFunc(&kedav1alpha1.ClusterKedaConfig{ObjectMeta: metav1.ObjectMeta{Name: "limits"}})).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "shop", Name: "orders"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "dev", Name: "orders"}},
+		))
+	})
+})
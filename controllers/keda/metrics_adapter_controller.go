@@ -19,27 +19,64 @@ package keda
 import (
 	"context"
 
-	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
-	"github.com/kedacore/keda/v2/pkg/scaling"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
+// MetricsScaledObjectReconciler clears the scalers cache the Metrics Service built for a ScaledObject once it is
+// deleted or moved to another shard. It runs on every replica, the ones which aren't the leader serve the metrics
+// without running the ScaledObject controller, and the caches are rebuilt on their own when the ScaledObjects change.
 type MetricsScaledObjectReconciler struct {
-	ScaleHandler scaling.ScaleHandler
+	Client        client.Client
+	ScaleHandler  scaling.ScaleHandler
+	ShardSelector labels.Selector
 }
 
+// Reconcile clears the scalers cache of the ScaledObject if it is gone, being deleted or not in the shard anymore
 func (r *MetricsScaledObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.ScaleHandler.ClearScalersCache(ctx, req.Name, req.Namespace)
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	err := r.Client.Get(ctx, req.NamespacedName, scaledObject)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if errors.IsNotFound(err) || scaledObject.GetDeletionTimestamp() != nil || !kedautil.IsInShard(r.ShardSelector, scaledObject) {
+		// the cache is keyed by the kind, name and namespace, the spec isn't needed
+		deleted := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace}}
+		return ctrl.Result{}, r.ScaleHandler.ClearScalersCache(ctx, deleted)
+	}
 	return ctrl.Result{}, nil
 }
 
+// SetupWithManager starts the controller on every replica managed by mgr, not only on the leader
 func (r *MetricsScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kedav1alpha1.ScaledObject{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
-		Owns(&kedav1alpha1.ScaledObject{}).
-		WithOptions(options).
-		Complete(r)
+	options.Reconciler = r
+	c, err := controller.NewUnmanaged("metrics-scaledobject", mgr, options)
+	if err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &kedav1alpha1.ScaledObject{}}, &handler.EnqueueRequestForObject{},
+		predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}), kedautil.ShardPredicate(r.ShardSelector)); err != nil {
+		return err
+	}
+	return mgr.Add(everyReplica{c})
+}
+
+// everyReplica runs the controller on every replica, it implements manager.LeaderElectionRunnable
+type everyReplica struct {
+	controller.Controller
+}
+
+func (everyReplica) NeedLeaderElection() bool {
+	return false
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scaling"
+)
+
+var _ = Describe("MetricsScaledObjectController", func() {
+	var (
+		reconciler       MetricsScaledObjectReconciler
+		mockScaleHandler *mock_scaling.MockScaleHandler
+		mockClient       *mock_client.MockClient
+		key              = types.NamespacedName{Name: "orders", Namespace: "shop"}
+	)
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoTestReporter{})
+		mockScaleHandler = mock_scaling.NewMockScaleHandler(mockCtrl)
+		mockClient = mock_client.NewMockClient(mockCtrl)
+		reconciler = MetricsScaledObjectReconciler{Client: mockClient, ScaleHandler: mockScaleHandler}
+	})
+
+	It("clears the scalers cache of a deleted ScaledObject", func() {
+		mockClient.EXPECT().Get(gomock.Any(), key, gomock.Any()).Return(errors.NewNotFound(schema.GroupResource{Group: "keda.sh", Resource: "scaledobjects"}, key.Name))
+		mockScaleHandler.EXPECT().ClearScalersCache(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scalableObject interface{}) error {
+				scaledObject, ok := scalableObject.(*kedav1alpha1.ScaledObject)
+				Ω(ok).To(BeTrue())
+				Ω(scaledObject.Name).To(Equal(key.Name))
+				Ω(scaledObject.Namespace).To(Equal(key.Namespace))
+				return nil
+			})
+
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+		Ω(err).ToNot(HaveOccurred())
+	})
+
+	It("keeps the scalers cache of a ScaledObject which still exists", func() {
+		mockClient.EXPECT().Get(gomock.Any(), key, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ types.NamespacedName, obj *kedav1alpha1.ScaledObject) error {
+				obj.ObjectMeta = metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}
+				return nil
+			})
+		mockScaleHandler.EXPECT().ClearScalersCache(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+		Ω(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,251 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+// OrphanPolicy is what the OrphanCollector does with the orphaned HPAs and Jobs
+type OrphanPolicy string
+
+const (
+	// OrphanPolicyDelete deletes the orphans
+	OrphanPolicyDelete OrphanPolicy = "delete"
+	// OrphanPolicyReport only reports the orphans in events
+	OrphanPolicyReport OrphanPolicy = "report"
+)
+
+const (
+	managedByLabel     = "app.kubernetes.io/managed-by"
+	partOfLabel        = "app.kubernetes.io/part-of"
+	scaledJobNameLabel = "scaledjob.keda.sh/name"
+)
+
+// ParseOrphanPolicy returns the OrphanPolicy named policy
+func ParseOrphanPolicy(policy string) (OrphanPolicy, error) {
+	switch OrphanPolicy(policy) {
+	case OrphanPolicyDelete, OrphanPolicyReport:
+		return OrphanPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown orphan policy %q, supported are %s and %s", policy, OrphanPolicyDelete, OrphanPolicyReport)
+	}
+}
+
+// OrphanCollector periodically sweeps the HPAs of the ScaledObjects and the Jobs of the ScaledJobs left behind by
+// their owner, eg. after an operator crash or a forced deletion of the owner. The ones whose owner has been recreated
+// are adopted by it, the others are handled according to the Policy.
+type OrphanCollector struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Interval time.Duration
+	Policy   OrphanPolicy
+	// ShardSelector restricts the sweep to the objects of the shard, the labels of the owners are set on them
+	ShardSelector labels.Selector
+}
+
+var orphanLog = logf.Log.WithName("orphan_collector")
+
+// Start sweeps the orphans every Interval until ctx is done
+func (c *OrphanCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.sweep(ctx); err != nil {
+				orphanLog.Error(err, "error collecting orphaned objects")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader collects the orphans
+func (c *OrphanCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// sweep handles the HPAs and the Jobs managed by keda-operator whose owner is gone
+func (c *OrphanCollector) sweep(ctx context.Context) error {
+	selector := labels.SelectorFromSet(labels.Set{managedByLabel: "keda-operator"})
+	if c.ShardSelector != nil {
+		if requirements, selectable := c.ShardSelector.Requirements(); selectable {
+			selector = selector.Add(requirements...)
+		}
+	}
+
+	hpas := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+	if err := c.Client.List(ctx, hpas, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		owner, err := c.getHPAOwner(ctx, hpa)
+		if err != nil {
+			// an owner which can't be read, eg. without permission, isn't known to be gone
+			orphanLog.Error(err, "error getting the owner, skipping", "namespace", hpa.Namespace, "name", hpa.Name, "kind", "HPA")
+			continue
+		}
+		if err := c.handle(ctx, hpa, owner, "HPA"); err != nil {
+			return err
+		}
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := c.Client.List(ctx, jobs, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		owner, err := c.getJobOwner(ctx, job)
+		if err != nil {
+			// an owner which can't be read, eg. without permission, isn't known to be gone
+			orphanLog.Error(err, "error getting the owner, skipping", "namespace", job.Namespace, "name", job.Name, "kind", "Job")
+			continue
+		}
+		if err := c.handle(ctx, job, owner, "Job"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getHPAOwner returns the ScaledObject the HPA belongs to, nil if it doesn't exist anymore, the errors other than
+// NotFound are returned
+func (c *OrphanCollector) getHPAOwner(ctx context.Context, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) (client.Object, error) {
+	name := getOwnerName(hpa, "ScaledObject", hpa.Labels[partOfLabel])
+	if name == "" {
+		return nil, nil
+	}
+	// the HPA of a ScaledObject scaling a workload of another namespace is in the namespace of the workload
+	namespace := hpa.Namespace
+	if ownerNamespace, ok := hpa.Labels[kedav1alpha1.ScaledObjectNamespaceLabel]; ok {
+		namespace = ownerNamespace
+	}
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, scaledObject); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	// a recreated ScaledObject could use another HPA, or a scale target of another namespace
+	if scaledObject.GetHPAName() != hpa.Name || scaledObject.GetScaleTargetNamespace() != hpa.Namespace {
+		return nil, nil
+	}
+	return scaledObject, nil
+}
+
+// getJobOwner returns the ScaledJob the Job belongs to, nil if it doesn't exist anymore, the errors other than
+// NotFound are returned
+func (c *OrphanCollector) getJobOwner(ctx context.Context, job *batchv1.Job) (client.Object, error) {
+	name := getOwnerName(job, "ScaledJob", job.Labels[scaledJobNameLabel])
+	if name == "" {
+		return nil, nil
+	}
+	scaledJob := &kedav1alpha1.ScaledJob{}
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: name}, scaledJob); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return scaledJob, nil
+}
+
+// getOwnerName returns the name of the controller of kind of the object, the name in its labels without controller
+func getOwnerName(obj client.Object, kind string, labelName string) string {
+	if controller := metav1.GetControllerOf(obj); controller != nil {
+		if controller.Kind != kind {
+			return ""
+		}
+		return controller.Name
+	}
+	return labelName
+}
+
+// handle adopts the object when owner doesn't control it yet, an object without owner is an orphan
+func (c *OrphanCollector) handle(ctx context.Context, obj client.Object, owner client.Object, kind string) error {
+	logger := orphanLog.WithValues("namespace", obj.GetNamespace(), "name", obj.GetName(), "kind", kind)
+	if owner == nil {
+		// the owner being deleted still cleans up its objects
+		if obj.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		return c.collect(ctx, logger, obj, kind)
+	}
+	// an owner of another namespace can't be referenced, it deletes its object itself
+	if owner.GetDeletionTimestamp() != nil || owner.GetNamespace() != obj.GetNamespace() {
+		return nil
+	}
+
+	controller := metav1.GetControllerOf(obj)
+	if controller != nil && controller.UID == owner.GetUID() {
+		return nil
+	}
+
+	// the controller reference of the previous owner with the same name is replaced
+	references := []metav1.OwnerReference{}
+	for _, reference := range obj.GetOwnerReferences() {
+		if reference.Controller == nil || !*reference.Controller {
+			references = append(references, reference)
+		}
+	}
+	obj.SetOwnerReferences(references)
+	if err := controllerutil.SetControllerReference(owner, obj, c.Scheme); err != nil {
+		return err
+	}
+	if err := c.Client.Update(ctx, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logger.Info("Adopted orphaned object", "owner", owner.GetName())
+	c.Recorder.Eventf(obj, corev1.EventTypeNormal, eventreason.KEDAOrphanAdopted, "Orphaned %s was adopted by %s", kind, owner.GetName())
+	return nil
+}
+
+// collect applies the Policy to the orphaned object
+func (c *OrphanCollector) collect(ctx context.Context, logger logr.Logger, obj client.Object, kind string) error {
+	if c.Policy != OrphanPolicyDelete {
+		logger.Info("Found orphaned object")
+		c.Recorder.Eventf(obj, corev1.EventTypeWarning, eventreason.KEDAOrphanDetected, "%s is orphaned, its owner doesn't exist anymore", kind)
+		return nil
+	}
+
+	// the pods of the Jobs are deleted with them
+	if err := c.Client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	logger.Info("Deleted orphaned object")
+	c.Recorder.Eventf(obj, corev1.EventTypeNormal, eventreason.KEDAOrphanDeleted, "Orphaned %s was deleted, its owner doesn't exist anymore", kind)
+	return nil
+}
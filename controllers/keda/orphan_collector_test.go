@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/controllers/keda/util"
+)
+
+var _ = Describe("orphan collector", func() {
+	var (
+		scheme   *runtime.Scheme
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	managedHPA := func(name, owner string, ownerUID types.UID) *autoscalingv2beta2.HorizontalPodAutoscaler {
+		controller := true
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{managedByLabel: "keda-operator", partOfLabel: owner},
+			},
+		}
+		if ownerUID != "" {
+			hpa.OwnerReferences = []metav1.OwnerReference{{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Name: owner, UID: ownerUID, Controller: &controller}}
+		}
+		return hpa
+	}
+
+	It("should delete orphaned HPAs and Jobs", func() {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:      "gone-1a2b3",
+			Namespace: "default",
+			Labels:    map[string]string{managedByLabel: "keda-operator", scaledJobNameLabel: "gone"},
+		}}
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedHPA("keda-hpa-gone", "gone", "old-uid"), job).Build()
+		collector := &OrphanCollector{Client: kubeClient, Scheme: scheme, Recorder: recorder, Policy: OrphanPolicyDelete}
+
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "keda-hpa-gone"}, &autoscalingv2beta2.HorizontalPodAutoscaler{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		err = kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "gone-1a2b3"}, &batchv1.Job{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.Events).To(HaveLen(2))
+	})
+
+	It("should skip the objects whose owner can't be read", func() {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:      "gone-1a2b3",
+			Namespace: "default",
+			Labels:    map[string]string{managedByLabel: "keda-operator", scaledJobNameLabel: "gone"},
+		}}
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedHPA("keda-hpa-orders", "orders", "uid"), job).Build()
+		failingClient := &ownerLookupFailingClient{Client: kubeClient, err: errors.NewForbidden(kedav1alpha1.Resource("scaledobjects"), "orders", nil)}
+		collector := &OrphanCollector{Client: failingClient, Scheme: scheme, Recorder: recorder, Policy: OrphanPolicyDelete}
+
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		Expect(kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "keda-hpa-orders"}, &autoscalingv2beta2.HorizontalPodAutoscaler{})).To(Succeed())
+		// the other objects are still collected
+		err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "gone-1a2b3"}, &batchv1.Job{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.Events).To(HaveLen(1))
+	})
+
+	It("should only report orphaned HPAs with the report policy", func() {
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedHPA("keda-hpa-gone", "gone", "")).Build()
+		collector := &OrphanCollector{Client: kubeClient, Scheme: scheme, Recorder: recorder, Policy: OrphanPolicyReport}
+
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		Expect(kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "keda-hpa-gone"}, &autoscalingv2beta2.HorizontalPodAutoscaler{})).To(Succeed())
+		Expect(<-recorder.Events).To(ContainSubstring("KEDAOrphanDetected"))
+	})
+
+	It("should adopt HPAs of a recreated ScaledObject", func() {
+		scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default", UID: "new-uid"}}
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(scaledObject, managedHPA("keda-hpa-orders", "orders", "old-uid")).Build()
+		collector := &OrphanCollector{Client: kubeClient, Scheme: scheme, Recorder: recorder, Policy: OrphanPolicyDelete}
+
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		Expect(kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "keda-hpa-orders"}, hpa)).To(Succeed())
+		Expect(hpa.OwnerReferences).To(HaveLen(1))
+		Expect(hpa.OwnerReferences[0].UID).To(Equal(types.UID("new-uid")))
+		Expect(<-recorder.Events).To(ContainSubstring("KEDAOrphanAdopted"))
+
+		// the HPAs controlled by their ScaledObject are left alone
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should keep the HPAs of a ScaledObject of another namespace", func() {
+		scaledObject := &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "platform", UID: "uid"},
+			Spec:       kedav1alpha1.ScaledObjectSpec{ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders", Namespace: "default"}},
+		}
+		hpa := managedHPA("keda-hpa-platform-orders", "orders", "")
+		hpa.Labels[kedav1alpha1.ScaledObjectNamespaceLabel] = "platform"
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(scaledObject, hpa).Build()
+		collector := &OrphanCollector{Client: kubeClient, Scheme: scheme, Recorder: recorder, Policy: OrphanPolicyDelete}
+
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		Expect(kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "keda-hpa-platform-orders"}, hpa)).To(Succeed())
+		Expect(hpa.OwnerReferences).To(BeEmpty())
+		Expect(recorder.Events).To(BeEmpty())
+
+		// the HPA is orphaned once the ScaledObject scales another namespace
+		Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(scaledObject), scaledObject)).To(Succeed())
+		scaledObject.Spec.ScaleTargetRef.Namespace = "shop"
+		Expect(kubeClient.Update(context.Background(), scaledObject)).To(Succeed())
+		Expect(collector.sweep(context.Background())).To(Succeed())
+		err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "keda-hpa-platform-orders"}, hpa)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should remove finalizers of stale objects", func() {
+		scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default", Finalizers: []string{scaledObjectFinalizer}}}
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(scaledObject).Build()
+
+		stale := &kedav1alpha1.ScaledObject{}
+		Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(scaledObject), stale)).To(Succeed())
+		latest := stale.DeepCopy()
+		latest.Labels = map[string]string{"team": "billing"}
+		Expect(kubeClient.Update(context.Background(), latest)).To(Succeed())
+
+		Expect(util.RemoveFinalizer(context.Background(), kubeClient, stale, scaledObjectFinalizer)).To(Succeed())
+		Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(scaledObject), latest)).To(Succeed())
+		Expect(latest.Finalizers).To(BeEmpty())
+		Expect(latest.Labels).To(HaveKeyWithValue("team", "billing"))
+	})
+})
+
+// ownerLookupFailingClient fails the lookups of the ScaledObjects
+type ownerLookupFailingClient struct {
+	client.Client
+	err error
+}
+
+func (c *ownerLookupFailingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*kedav1alpha1.ScaledObject); ok {
+		return c.err
+	}
+	return c.Client.Get(ctx, key, obj)
+}
@@ -19,6 +19,7 @@ package keda
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -26,18 +27,27 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
+	"github.com/kedacore/keda/v2/pkg/audit"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/kedaconfig"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 // +kubebuilder:rbac:groups=keda.sh,resources=scaledjobs;scaledjobs/finalizers;scaledjobs/status,verbs="*"
@@ -49,17 +59,34 @@ type ScaledJobReconciler struct {
 	Scheme            *runtime.Scheme
 	GlobalHTTPTimeout time.Duration
 	Recorder          record.EventRecorder
-	scaleHandler      scaling.ScaleHandler
+	// ShardSelector restricts the reconciler to the ScaledJobs matching it, nil means all ScaledJobs
+	ShardSelector labels.Selector
+	// DecisionLogger records the scaling decisions, nil disables the decision log
+	DecisionLogger audit.DecisionLogger
+	// MaxConcurrentReconciles is the number of ScaledJobs reconciled in parallel, 1 if 0
+	MaxConcurrentReconciles int
+	scaleHandler            scaling.ScaleHandler
+	// scaleLoops are the keys of the ScaledJobs whose scale loop is running
+	scaleLoops sync.Map
 }
 
 // SetupWithManager initializes the ScaledJobReconciler instance and starts a new controller managed by the passed Manager instance.
 func (r *ScaledJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	r.scaleHandler = scaling.NewScaleHandler(mgr.GetClient(), nil, mgr.GetScheme(), r.GlobalHTTPTimeout, mgr.GetEventRecorderFor("scale-handler"))
+	r.scaleHandler = scaling.NewScaleHandler(mgr.GetClient(), nil, mgr.GetScheme(), r.GlobalHTTPTimeout, mgr.GetEventRecorderFor("scale-handler"), r.DecisionLogger)
+	// the scale loops are drained on shutdown
+	if err := mgr.Add(r.scaleHandler); err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		// Ignore updates to ScaledJob Status (in this case metadata.Generation does not change)
-		// so reconcile loop is not started on Status updates
-		For(&kedav1alpha1.ScaledJob{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// so reconcile loop is not started on Status updates, annotation changes pause or resume the ScaledJob
+		// and label changes move it between the shards
+		For(&kedav1alpha1.ScaledJob{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}), kedautil.ShardPredicate(r.ShardSelector))).
+		// the changes of the limits are checked against the existing ScaledJobs
+		Watches(&source.Kind{Type: &kedav1alpha1.KedaConfig{}}, handler.EnqueueRequestsFromMapFunc(mapKedaConfig(r.Client, r.ShardSelector, &kedav1alpha1.ScaledJobList{}))).
+		Watches(&source.Kind{Type: &kedav1alpha1.ClusterKedaConfig{}}, handler.EnqueueRequestsFromMapFunc(mapKedaConfig(r.Client, r.ShardSelector, &kedav1alpha1.ScaledJobList{}))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -82,6 +109,15 @@ func (r *ScaledJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
+	// the ScaledJobs relabeled to another shard are scaled by it
+	if !kedautil.IsInShard(r.ShardSelector, scaledJob) {
+		if _, running := r.scaleLoops.Load(req.NamespacedName.String()); running {
+			reqLogger.Info("ScaledJob moved to another shard, stopping its scale loop")
+			return ctrl.Result{}, r.stopScaleLoop(ctx, reqLogger, scaledJob)
+		}
+		return ctrl.Result{}, nil
+	}
+
 	reqLogger.Info("Reconciling ScaledJob")
 
 	// Check if the ScaledJob instance is marked to be deleted, which is
@@ -134,13 +170,43 @@ func (r *ScaledJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 // reconcileScaledJob implements reconciler logic for K8s Jobs based ScaledJob
 func (r *ScaledJobReconciler) reconcileScaledJob(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) (string, error) {
-	msg, err := r.deletePreviousVersionScaleJobs(ctx, logger, scaledJob)
-	if err != nil {
+	// the scale loop of a paused ScaledJob is stopped, Jobs that are already running are left untouched
+	if scaledJob.IsPaused() {
+		if err := r.stopScaleLoop(ctx, logger, scaledJob); err != nil {
+			return "Failed to stop the scale loop of paused ScaledJob", err
+		}
+		if err := r.setPausedCondition(ctx, logger, scaledJob, true); err != nil {
+			return "Failed to set the paused condition of ScaledJob", err
+		}
+		logger.Info("ScaledJob is paused")
+		return "ScaledJob is paused", nil
+	}
+
+	// a resumed ScaledJob keeps the Jobs created before it was paused
+	pausedCondition := scaledJob.Status.Conditions.GetPausedCondition()
+	if pausedCondition.IsTrue() {
+		if err := r.setPausedCondition(ctx, logger, scaledJob, false); err != nil {
+			return "Failed to set the paused condition of ScaledJob", err
+		}
+		logger.Info("ScaledJob is resumed")
+	} else {
+		msg, err := r.deletePreviousVersionScaleJobs(ctx, logger, scaledJob)
+		if err != nil {
+			return msg, err
+		}
+	}
+
+	// The defaults of the KedaConfigs are applied on the in-memory copy only, they aren't persisted
+	if msg, err := r.applyKedaConfig(ctx, scaledJob); err != nil {
 		return msg, err
 	}
 
+	if err := cache.ValidateUrgencyTiers(scaledJob); err != nil {
+		return "ScaledJob doesn't have correct urgencyTiers specification", err
+	}
+
 	// Check ScaledJob is Ready or not
-	_, err = r.scaleHandler.GetScalersCache(ctx, scaledJob)
+	_, err := r.scaleHandler.GetScalersCache(ctx, scaledJob)
 	if err != nil {
 		logger.Error(err, "Error getting scalers")
 		return "Failed to ensure ScaledJob is correctly created", err
@@ -155,6 +221,19 @@ func (r *ScaledJobReconciler) reconcileScaledJob(ctx context.Context, logger log
 	return "ScaledJob is defined correctly and is ready to scaling", nil
 }
 
+// applyKedaConfig sets the defaults of the KedaConfigs on the ScaledJob and checks it doesn't break their limits
+func (r *ScaledJobReconciler) applyKedaConfig(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (string, error) {
+	policy, err := kedaconfig.Resolve(ctx, r.Client, scaledJob.Namespace)
+	if err != nil {
+		return "Failed to resolve the KedaConfigs of ScaledJob", err
+	}
+	policy.ApplyToScaledJob(scaledJob)
+	if err := policy.ValidateScaledJob(ctx, r.Client, scaledJob); err != nil {
+		return "ScaledJob breaks the limits of the KedaConfigs", err
+	}
+	return "", nil
+}
+
 // Delete Jobs owned by the previous version of the scaledJob based on the rolloutStrategy given for this scaledJob, if any
 func (r *ScaledJobReconciler) deletePreviousVersionScaleJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) (string, error) {
 	switch scaledJob.Spec.RolloutStrategy {
@@ -186,14 +265,33 @@ func (r *ScaledJobReconciler) deletePreviousVersionScaleJobs(ctx context.Context
 	return fmt.Sprintf("RolloutStrategy: %s", scaledJob.Spec.RolloutStrategy), nil
 }
 
+// setPausedCondition updates the Paused condition of the ScaledJob
+func (r *ScaledJobReconciler) setPausedCondition(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, paused bool) error {
+	conditions := scaledJob.Status.Conditions.DeepCopy()
+	if paused {
+		conditions.SetPausedCondition(metav1.ConditionTrue, "ScaledJobPaused", "New Jobs are not created because ScaledJob is paused")
+	} else {
+		conditions.SetPausedCondition(metav1.ConditionFalse, "ScaledJobResumed", "ScaledJob is not paused")
+	}
+	return kedacontrollerutil.SetStatusConditions(ctx, r.Client, logger, scaledJob, &conditions)
+}
+
 // requestScaleLoop request ScaleLoop handler for the respective ScaledJob
 func (r *ScaledJobReconciler) requestScaleLoop(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) error {
 	logger.V(1).Info("Starting a new ScaleLoop")
-	return r.scaleHandler.HandleScalableObject(ctx, scaledJob)
+	if err := r.scaleHandler.HandleScalableObject(ctx, scaledJob); err != nil {
+		return err
+	}
+	r.scaleLoops.Store(types.NamespacedName{Namespace: scaledJob.Namespace, Name: scaledJob.Name}.String(), struct{}{})
+	return nil
 }
 
 // stopScaleLoop stops ScaleLoop handler for the respective ScaledJob
 func (r *ScaledJobReconciler) stopScaleLoop(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) error {
 	logger.V(1).Info("Stopping a ScaleLoop")
-	return r.scaleHandler.DeleteScalableObject(ctx, scaledJob)
+	if err := r.scaleHandler.DeleteScalableObject(ctx, scaledJob); err != nil {
+		return err
+	}
+	r.scaleLoops.Delete(types.NamespacedName{Namespace: scaledJob.Namespace, Name: scaledJob.Name}.String())
+	return nil
 }
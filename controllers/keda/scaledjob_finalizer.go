@@ -43,8 +43,7 @@ func (r *ScaledJobReconciler) finalizeScaledJob(ctx context.Context, logger logr
 
 		// Remove scaledJobFinalizer. Once all finalizers have been
 		// removed, the object will be deleted.
-		scaledJob.SetFinalizers(util.Remove(scaledJob.GetFinalizers(), scaledJobFinalizer))
-		if err := r.Client.Update(ctx, scaledJob); err != nil {
+		if err := util.RemoveFinalizer(ctx, r.Client, scaledJob, scaledJobFinalizer); err != nil {
 			logger.Error(err, "Failed to update ScaledJob after removing a finalizer", "finalizer", scaledJobFinalizer)
 			return err
 		}
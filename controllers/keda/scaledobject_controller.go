@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
@@ -40,25 +41,37 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
+	"github.com/kedacore/keda/v2/pkg/audit"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	"github.com/kedacore/keda/v2/pkg/scaling/activation"
+	"github.com/kedacore/keda/v2/pkg/scaling/kedaconfig"
+	"github.com/kedacore/keda/v2/pkg/scaling/schedule"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 // +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects;scaledobjects/finalizers;scaledobjects/status,verbs="*"
+// +kubebuilder:rbac:groups=keda.sh,resources=clustertriggertemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=keda.sh,resources=clusterkedaconfigs;kedaconfigs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs="*"
 // +kubebuilder:rbac:groups="",resources=configmaps;configmaps/status;events,verbs="*"
 // +kubebuilder:rbac:groups="",resources=pods;services;services;secrets;external,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=create;delete;patch
 // +kubebuilder:rbac:groups="*",resources="*/scale",verbs="*"
 // +kubebuilder:rbac:groups="*",resources="*",verbs=get
-// +kubebuilder:rbac:groups="apps",resources=deployments;statefulsets,verbs=list;watch
+// +kubebuilder:rbac:groups="apps",resources=deployments;statefulsets,verbs=list;watch;patch
 // +kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs="*"
+// +kubebuilder:rbac:groups="tekton.dev",resources=pipelineruns;taskruns,verbs=list
+// +kubebuilder:rbac:groups="argoproj.io",resources=workflows,verbs=list
 
 // ScaledObjectReconciler reconciles a ScaledObject object
 type ScaledObjectReconciler struct {
@@ -66,6 +79,12 @@ type ScaledObjectReconciler struct {
 	Scheme            *runtime.Scheme
 	GlobalHTTPTimeout time.Duration
 	Recorder          record.EventRecorder
+	// ShardSelector restricts the reconciler to the ScaledObjects matching it, nil means all ScaledObjects
+	ShardSelector labels.Selector
+	// DecisionLogger records the scaling decisions, nil disables the decision log
+	DecisionLogger audit.DecisionLogger
+	// MaxConcurrentReconciles is the number of ScaledObjects reconciled in parallel, 1 if 0
+	MaxConcurrentReconciles int
 
 	scaleClient              scale.ScalesGetter
 	restMapper               meta.RESTMapper
@@ -74,15 +93,14 @@ type ScaledObjectReconciler struct {
 	kubeVersion              kedautil.K8sVersion
 }
 
-// A cache mapping "resource.group" to true or false if we know if this resource is scalable.
-var isScalableCache map[string]bool
+// A cache mapping "resource.group" to true if we know this resource is scalable,
+// it is shared by the ScaledObjects reconciled in parallel (see MaxConcurrentReconciles).
+var isScalableCache sync.Map
 
 func init() {
-	// Prefill the cache with some known values for core resources in case of future parallelism to avoid stampeding herd on startup.
-	isScalableCache = map[string]bool{
-		"deployments.apps":  true,
-		"statefulsets.apps": true,
-	}
+	// Prefill the cache with some known values for core resources to avoid stampeding herd on startup.
+	isScalableCache.Store("deployments.apps", true)
+	isScalableCache.Store("statefulsets.apps", true)
 }
 
 // SetupWithManager initializes the ScaledObjectReconciler instance and starts a new controller managed by the passed Manager instance.
@@ -90,7 +108,7 @@ func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	setupLog := log.Log.WithName("setup")
 
 	// create Discovery clientset
-	// TODO If we need to increase the QPS of scaling API calls, copy and tweak this RESTConfig.
+	// the scaling API calls share the QPS and burst of the manager config, see --kube-api-qps and --kube-api-burst
 	clientset, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
 	if err != nil {
 		setupLog.Error(err, "Not able to create Discovery clientset")
@@ -113,18 +131,34 @@ func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Init the rest of ScaledObjectReconciler
 	r.restMapper = mgr.GetRESTMapper()
 	r.scaledObjectsGenerations = &sync.Map{}
-	r.scaleHandler = scaling.NewScaleHandler(mgr.GetClient(), r.scaleClient, mgr.GetScheme(), r.GlobalHTTPTimeout, r.Recorder)
+	r.scaleHandler = scaling.NewScaleHandler(mgr.GetClient(), r.scaleClient, mgr.GetScheme(), r.GlobalHTTPTimeout, r.Recorder, r.DecisionLogger)
+	// the scale loops are drained on shutdown
+	if err := mgr.Add(r.scaleHandler); err != nil {
+		return err
+	}
 
 	// Start controller
 	return ctrl.NewControllerManagedBy(mgr).
 		// predicate.GenerationChangedPredicate{} ignore updates to ScaledObject Status
 		// (in this case metadata.Generation does not change)
 		// so reconcile loop is not started on Status updates
-		For(&kedav1alpha1.ScaledObject{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// and the label changes move the ScaledObject between the shards
+		For(&kedav1alpha1.ScaledObject{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}), kedautil.ShardPredicate(r.ShardSelector))).
 		Owns(&autoscalingv2beta2.HorizontalPodAutoscaler{}).
+		// the HPAs in other namespaces aren't owned by their ScaledObject
+		Watches(&source.Kind{Type: &autoscalingv2beta2.HorizontalPodAutoscaler{}}, handler.EnqueueRequestsFromMapFunc(mapCrossNamespaceHPA)).
+		// the changes of the limits are checked against the existing ScaledObjects
+		Watches(&source.Kind{Type: &kedav1alpha1.KedaConfig{}}, handler.EnqueueRequestsFromMapFunc(mapKedaConfig(r.Client, r.ShardSelector, &kedav1alpha1.ScaledObjectList{}))).
+		Watches(&source.Kind{Type: &kedav1alpha1.ClusterKedaConfig{}}, handler.EnqueueRequestsFromMapFunc(mapKedaConfig(r.Client, r.ShardSelector, &kedav1alpha1.ScaledObjectList{}))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
+// ScaleHandler returns the ScaleHandler of the reconciler once it is set up, the Metrics Service shares its scalers
+func (r *ScaledObjectReconciler) ScaleHandler() scaling.ScaleHandler {
+	return r.scaleHandler
+}
+
 func initScaleClient(mgr manager.Manager, clientset *discovery.DiscoveryClient) scale.ScalesGetter {
 	scaleKindResolver := scale.NewDiscoveryScaleKindResolver(clientset)
 	return scale.New(
@@ -153,6 +187,16 @@ func (r *ScaledObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// HPA events are not filtered by the shard predicate, ignore ScaledObjects owned by another shard and stop
+	// scaling the ones relabeled to another shard, it takes them over
+	if !kedautil.IsInShard(r.ShardSelector, scaledObject) {
+		if _, running := r.scaledObjectsGenerations.Load(req.NamespacedName.String()); running {
+			reqLogger.Info("ScaledObject moved to another shard, stopping its scale loop")
+			return ctrl.Result{}, r.stopScaleLoop(ctx, reqLogger, scaledObject)
+		}
+		return ctrl.Result{}, nil
+	}
+
 	reqLogger.Info("Reconciling ScaledObject")
 
 	// Check if the ScaledObject instance is marked to be deleted, which is
@@ -195,6 +239,32 @@ func (r *ScaledObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// reconcile again when a schedule window starts or ends, so the HPA gets the new replica bounds
+	if err == nil {
+		var requeueAfter time.Duration
+		if _, nextChange, scheduleErr := schedule.Apply(scaledObject, time.Now()); scheduleErr == nil && !nextChange.IsZero() {
+			requeueAfter = time.Until(nextChange)
+		}
+		// or a pricing window of the budget, and periodically to follow the consumption of the daily budget
+		if _, nextChange, budgetErr := schedule.ApplyBudget(scaledObject, time.Now()); budgetErr == nil && !nextChange.IsZero() && (requeueAfter <= 0 || time.Until(nextChange) < requeueAfter) {
+			requeueAfter = time.Until(nextChange)
+		}
+		if scaledObject.Spec.Budget != nil && scaledObject.Spec.Budget.MaxReplicaHoursPerDay != nil && (requeueAfter <= 0 || requeueAfter > budgetResyncInterval) {
+			requeueAfter = budgetResyncInterval
+		}
+		// and periodically to follow the partition count the HPA maxReplicas is capped at
+		if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.MaxReplicaFromPartitions && (requeueAfter <= 0 || requeueAfter > partitionCountResyncInterval) {
+			requeueAfter = partitionCountResyncInterval
+		}
+		// and the capacity of the nodes, eg. when GPU nodes join or leave the cluster
+		if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.CapacityCap != nil && (requeueAfter <= 0 || requeueAfter > capacityResyncInterval) {
+			requeueAfter = capacityResyncInterval
+		}
+		if requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+
 	return ctrl.Result{}, err
 }
 
@@ -212,6 +282,16 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 		return "Failed to update ScaledObject with scaledObjectName label", err
 	}
 
+	// The defaults of the KedaConfigs are applied on the in-memory copy only, they aren't persisted
+	if msg, err := r.applyKedaConfig(ctx, scaledObject); err != nil {
+		return msg, err
+	}
+
+	// The namespace of a scale target in another namespace has to consent, before the target is read
+	if err := r.checkCrossNamespaceTarget(ctx, scaledObject); err != nil {
+		return "ScaledObject isn't allowed to scale the scaleTargetRef in another namespace", err
+	}
+
 	// Check if resource targeted for scaling exists and exposes /scale subresource
 	gvkr, err := r.checkTargetResourceIsScalable(ctx, logger, scaledObject)
 	if err != nil {
@@ -223,10 +303,32 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 		return "ScaledObject doesn't have correct Idle/Min/Max Replica Counts specification", err
 	}
 
-	// Create a new HPA or update existing one according to ScaledObject
-	newHPACreated, err := r.ensureHPAForScaledObjectExists(ctx, logger, scaledObject, &gvkr)
+	err = checkActivationStrategyIsValid(scaledObject)
 	if err != nil {
-		return "Failed to ensure HPA is correctly created for ScaledObject", err
+		return "ScaledObject doesn't have correct activationStrategy specification", err
+	}
+
+	// Create a new HPA or update existing one according to ScaledObject, the HPA is deleted in dry-run mode so it
+	// doesn't keep scaling the target
+	newHPACreated := false
+	if scaledObject.IsDryRun() {
+		logger.V(1).Info("ScaledObject is in dry-run mode, skipping HPA reconciliation")
+		for _, name := range []string{getHPAName(scaledObject), scaledObject.Status.HpaName} {
+			if name == "" {
+				continue
+			}
+			if err := r.deleteHPAOfScaledObject(ctx, logger, scaledObject, name); err != nil {
+				return "Failed to delete the HPA of the ScaledObject in dry-run mode", err
+			}
+		}
+		if err := r.updateHPANameStatus(ctx, logger, scaledObject, ""); err != nil {
+			return "Failed to update the HPA name in the ScaledObject status", err
+		}
+	} else {
+		newHPACreated, err = r.ensureHPAForScaledObjectExists(ctx, logger, scaledObject, &gvkr)
+		if err != nil {
+			return "Failed to ensure HPA is correctly created for ScaledObject", err
+		}
 	}
 	scaleObjectSpecChanged := false
 	if !newHPACreated {
@@ -249,6 +351,19 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 	return "ScaledObject is defined correctly and is ready for scaling", nil
 }
 
+// applyKedaConfig sets the defaults of the KedaConfigs on the ScaledObject and checks it doesn't break their limits
+func (r *ScaledObjectReconciler) applyKedaConfig(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (string, error) {
+	policy, err := kedaconfig.Resolve(ctx, r.Client, scaledObject.Namespace)
+	if err != nil {
+		return "Failed to resolve the KedaConfigs of ScaledObject", err
+	}
+	policy.ApplyToScaledObject(scaledObject)
+	if err := policy.ValidateScaledObject(ctx, r.Client, scaledObject); err != nil {
+		return "ScaledObject breaks the limits of the KedaConfigs", err
+	}
+	return "", nil
+}
+
 // ensureScaledObjectLabel ensures that scaledobject.keda.sh/name=<scaledObject.Name> label exist in the ScaledObject
 // This is how the MetricsAdapter will know which ScaledObject a metric is for when the HPA queries it.
 func (r *ScaledObjectReconciler) ensureScaledObjectLabel(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) error {
@@ -284,17 +399,17 @@ func (r *ScaledObjectReconciler) checkTargetResourceIsScalable(ctx context.Conte
 	// check if we already know.
 	var scale *autoscalingv1.Scale
 	gr := gvkr.GroupResource()
-	isScalable := isScalableCache[gr.String()]
+	_, isScalable := isScalableCache.Load(gr.String())
 	if !isScalable || wantStatusUpdate {
 		// not cached, let's try to detect /scale subresource
 		// also rechecks when we need to update the status.
 		var errScale error
-		scale, errScale = (r.scaleClient).Scales(scaledObject.Namespace).Get(ctx, gr, scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
+		scale, errScale = (r.scaleClient).Scales(scaledObject.GetScaleTargetNamespace()).Get(ctx, gr, scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
 		if errScale != nil {
 			// not able to get /scale subresource -> let's check if the resource even exist in the cluster
 			unstruct := &unstructured.Unstructured{}
 			unstruct.SetGroupVersionKind(gvkr.GroupVersionKind())
-			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: scaledObject.Namespace, Name: scaledObject.Spec.ScaleTargetRef.Name}, unstruct); err != nil {
+			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: scaledObject.GetScaleTargetNamespace(), Name: scaledObject.Spec.ScaleTargetRef.Name}, unstruct); err != nil {
 				// resource doesn't exist
 				logger.Error(err, "Target resource doesn't exist", "resource", gvkString, "name", scaledObject.Spec.ScaleTargetRef.Name)
 				return gvkr, err
@@ -303,7 +418,7 @@ func (r *ScaledObjectReconciler) checkTargetResourceIsScalable(ctx context.Conte
 			logger.Error(errScale, "Target resource doesn't expose /scale subresource", "resource", gvkString, "name", scaledObject.Spec.ScaleTargetRef.Name)
 			return gvkr, errScale
 		}
-		isScalableCache[gr.String()] = true
+		isScalableCache.Store(gr.String(), true)
 	}
 
 	// if it is not already present in ScaledObject Status:
@@ -345,21 +460,85 @@ func (r *ScaledObjectReconciler) checkReplicaCountBoundsAreValid(scaledObject *k
 		return fmt.Errorf("IdleReplicaCount=%d must be less than MinReplicaCount=%d", *scaledObject.Spec.IdleReplicaCount, min)
 	}
 
+	if err := schedule.Validate(scaledObject.Spec.Schedules); err != nil {
+		return err
+	}
+	if err := schedule.ValidateBudget(scaledObject.Spec.Budget); err != nil {
+		return err
+	}
+	if scaledObject.Spec.Advanced != nil {
+		if err := validateCapacityCap(scaledObject.Spec.Advanced.CapacityCap); err != nil {
+			return err
+		}
+	}
+	if tolerance := scaledObject.GetTolerance(); tolerance != nil && (tolerance.Sign() < 0 || tolerance.AsApproximateFloat64() >= 1) {
+		return fmt.Errorf("tolerance %s must be within [0, 1)", tolerance.String())
+	}
+	if draining := scaledObject.GetDraining(); draining != nil && draining.TimeoutSeconds != nil && *draining.TimeoutSeconds < 0 {
+		return fmt.Errorf("draining timeoutSeconds must not be negative")
+	}
+	if shadow := scaledObject.Spec.Shadow; shadow != nil {
+		if len(shadow.Triggers) == 0 {
+			return fmt.Errorf("shadow requires at least one trigger")
+		}
+		if shadow.DurationSeconds != nil && *shadow.DurationSeconds <= 0 {
+			return fmt.Errorf("shadow durationSeconds must be greater than 0")
+		}
+	}
+
+	if scaledObject.GetOnDeletePolicy() == kedav1alpha1.OnDeleteFixedReplicas {
+		replicas := scaledObject.Spec.Advanced.OnDelete.Replicas
+		if replicas == nil || *replicas < 0 {
+			return fmt.Errorf("OnDelete policy %s requires non-negative replicas", kedav1alpha1.OnDeleteFixedReplicas)
+		}
+	}
+
+	return nil
+}
+
+// checkActivationStrategyIsValid checks that activationStrategy can be parsed and it references only defined triggers
+func checkActivationStrategyIsValid(scaledObject *kedav1alpha1.ScaledObject) error {
+	strategy, err := activation.Parse(scaledObject.GetActivationStrategy())
+	if err != nil {
+		return err
+	}
+
+	for _, name := range strategy.TriggerNames() {
+		found := false
+		for _, trigger := range scaledObject.Spec.Triggers {
+			if trigger.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("activationStrategy references trigger %s which isn't defined, please set the name of the trigger", name)
+		}
+	}
 	return nil
 }
 
 // ensureHPAForScaledObjectExists ensures that in cluster exist up-to-date HPA for specified ScaledObject, returns true if a new HPA was created
 func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource) (bool, error) {
 	hpaName := getHPAName(scaledObject)
+	// the HPA of the previous name, eg. before horizontalPodAutoscalerConfig.name changed, would keep scaling the target
+	if previous := scaledObject.Status.HpaName; previous != "" && previous != hpaName {
+		if err := r.deleteHPAOfScaledObject(ctx, logger, scaledObject, previous); err != nil {
+			return false, err
+		}
+	}
 	foundHpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
 	// Check if HPA for this ScaledObject already exists
-	err := r.Client.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: scaledObject.Namespace}, foundHpa)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: scaledObject.GetScaleTargetNamespace()}, foundHpa)
 	if err != nil && errors.IsNotFound(err) {
 		// HPA wasn't found -> let's create a new one
 		err = r.createAndDeployNewHPA(ctx, logger, scaledObject, gvkr)
 		if err != nil {
 			return false, err
 		}
+		if err := r.updateHPANameStatus(ctx, logger, scaledObject, hpaName); err != nil {
+			return false, err
+		}
 
 		// check if scaledObject.spec.behavior was defined, because it is supported only on k8s >= 1.18
 		r.checkMinK8sVersionforHPABehavior(logger, scaledObject)
@@ -371,6 +550,14 @@ func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Cont
 		return false, err
 	}
 
+	// HPA was found but it isn't owned by the ScaledObject yet -> adopt it if requested, the new ownership fires a new ScaleLoop
+	if _, transfer := scaledObject.Annotations[kedav1alpha1.ScaledObjectTransferHpaOwnershipAnnotation]; transfer && !metav1.IsControlledBy(foundHpa, scaledObject) {
+		if err := r.adoptHPA(ctx, logger, scaledObject, foundHpa, gvkr); err != nil {
+			return false, err
+		}
+		return true, r.updateHPANameStatus(ctx, logger, scaledObject, hpaName)
+	}
+
 	// HPA was found -> let's check if we need to update it
 	err = r.updateHPAIfNeeded(ctx, logger, scaledObject, foundHpa, gvkr)
 	if err != nil {
@@ -378,7 +565,7 @@ func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Cont
 		return false, err
 	}
 
-	return false, nil
+	return false, r.updateHPANameStatus(ctx, logger, scaledObject, hpaName)
 }
 
 // startScaleLoop starts ScaleLoop handler for the respective ScaledObject
@@ -19,6 +19,7 @@ package keda
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang/mock/gomock"
@@ -30,12 +31,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_scaling"
 	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 type GinkgoTestReporter struct{}
@@ -227,6 +230,45 @@ var _ = Describe("ScaledObjectController", func() {
 		})
 	})
 
+	Describe("Shards", func() {
+		It("stops the scale loop of a ScaledObject moved to another shard", func() {
+			mockCtrl := gomock.NewController(GinkgoTestReporter{})
+			mockScaleHandler := mock_scaling.NewMockScaleHandler(mockCtrl)
+			mockClient := mock_client.NewMockClient(mockCtrl)
+			shardSelector, err := kedautil.ParseShardSelector("shard=a")
+			Ω(err).ToNot(HaveOccurred())
+
+			reconciler := ScaledObjectReconciler{
+				Client:                   mockClient,
+				ShardSelector:            shardSelector,
+				scaleHandler:             mockScaleHandler,
+				scaledObjectsGenerations: &sync.Map{},
+			}
+			key := types.NamespacedName{Name: "moved", Namespace: "default"}
+			// the scale loop was started while the ScaledObject was in shard a
+			reconciler.scaledObjectsGenerations.Store(key.String(), int64(1))
+
+			moved := &kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: map[string]string{"shard": "b"}},
+			}
+			mockClient.EXPECT().Get(gomock.Any(), key, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ types.NamespacedName, obj *kedav1alpha1.ScaledObject) error {
+					moved.DeepCopyInto(obj)
+					return nil
+				}).Times(2)
+			mockScaleHandler.EXPECT().DeleteScalableObject(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+			_, err = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+			Ω(err).ToNot(HaveOccurred())
+			_, running := reconciler.scaledObjectsGenerations.Load(key.String())
+			Ω(running).To(BeFalse())
+
+			// the ScaledObject is left to shard b
+			_, err = reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+			Ω(err).ToNot(HaveOccurred())
+		})
+	})
+
 	Describe("functional tests", func() {
 		It("cleans up a deleted trigger from the HPA", func() {
 			// Create the scaling target.
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,9 +44,10 @@ func (r *ScaledObjectReconciler) finalizeScaledObject(ctx context.Context, logge
 			return err
 		}
 
-		// if enabled, scale scaleTarget back to the original replica count (to the state it was before scaling with KEDA)
-		if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.RestoreToOriginalReplicaCount {
-			scale, err := r.scaleClient.Scales(scaledObject.Namespace).Get(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
+		// scale scaleTarget according to the onDelete policy (eg. back to the state it was before scaling with KEDA)
+		// the scaleTarget is never modified in dry-run mode, so there is nothing to restore
+		if replicas, ok := getOnDeleteReplicaCount(scaledObject); ok && !scaledObject.IsDryRun() {
+			scale, err := r.scaleClient.Scales(scaledObject.GetScaleTargetNamespace()).Get(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
 			if err != nil {
 				if errors.IsNotFound(err) {
 					logger.V(1).Info("Failed to get scaleTarget's scale status, because it was probably deleted", "error", err)
@@ -53,19 +55,29 @@ func (r *ScaledObjectReconciler) finalizeScaledObject(ctx context.Context, logge
 					logger.Error(err, "Failed to get scaleTarget's scale status from a finalizer", "finalizer", scaledObjectFinalizer)
 				}
 			} else {
-				scale.Spec.Replicas = *scaledObject.Status.OriginalReplicaCount
-				_, err = r.scaleClient.Scales(scaledObject.Namespace).Update(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scale, metav1.UpdateOptions{})
+				scale.Spec.Replicas = replicas
+				_, err = r.scaleClient.Scales(scaledObject.GetScaleTargetNamespace()).Update(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scale, metav1.UpdateOptions{})
 				if err != nil {
-					logger.Error(err, "Failed to restore scaleTarget's replica count back to the original", "finalizer", scaledObjectFinalizer)
+					logger.Error(err, "Failed to restore scaleTarget's replica count", "finalizer", scaledObjectFinalizer, "policy", scaledObject.GetOnDeletePolicy())
+				} else {
+					logger.Info("Successfully restored scaleTarget's replica count", "replicaCount", scale.Spec.Replicas, "policy", scaledObject.GetOnDeletePolicy())
 				}
-				logger.Info("Successfully restored scaleTarget's replica count back to the original", "replicaCount", scale.Spec.Replicas)
+			}
+		}
+
+		// the HPA in the namespace of a scale target of another namespace doesn't reference the ScaledObject,
+		// it isn't garbage collected with it
+		if scaledObject.IsCrossNamespace() {
+			hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: getHPAName(scaledObject), Namespace: scaledObject.GetScaleTargetNamespace()}}
+			if err := r.Client.Delete(ctx, hpa); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to delete the HPA of the ScaledObject", "HPA.Namespace", hpa.Namespace, "HPA.Name", hpa.Name)
+				return err
 			}
 		}
 
 		// Remove scaledObjectFinalizer. Once all finalizers have been
 		// removed, the object will be deleted.
-		scaledObject.SetFinalizers(util.Remove(scaledObject.GetFinalizers(), scaledObjectFinalizer))
-		if err := r.Client.Update(ctx, scaledObject); err != nil {
+		if err := util.RemoveFinalizer(ctx, r.Client, scaledObject, scaledObjectFinalizer); err != nil {
 			logger.Error(err, "Failed to update ScaledObject after removing a finalizer", "finalizer", scaledObjectFinalizer)
 			return err
 		}
@@ -76,6 +88,25 @@ func (r *ScaledObjectReconciler) finalizeScaledObject(ctx context.Context, logge
 	return nil
 }
 
+// getOnDeleteReplicaCount returns the replica count the scaleTarget is scaled to after the ScaledObject is deleted,
+// false is returned if the current replica count is kept
+func getOnDeleteReplicaCount(scaledObject *kedav1alpha1.ScaledObject) (int32, bool) {
+	switch scaledObject.GetOnDeletePolicy() {
+	case kedav1alpha1.OnDeleteRestoreOriginal:
+		if scaledObject.Status.OriginalReplicaCount == nil {
+			return 0, false
+		}
+		return *scaledObject.Status.OriginalReplicaCount, true
+	case kedav1alpha1.OnDeleteFixedReplicas:
+		if scaledObject.Spec.Advanced.OnDelete.Replicas == nil {
+			return 0, false
+		}
+		return *scaledObject.Spec.Advanced.OnDelete.Replicas, true
+	default:
+		return 0, false
+	}
+}
+
 // ensureFinalizer check there is finalizer present on the ScaledObject, if not it adds one
 func (r *ScaledObjectReconciler) ensureFinalizer(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) error {
 	if !util.Contains(scaledObject.GetFinalizers(), scaledObjectFinalizer) {
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+var _ = Describe("finalizer", func() {
+	var (
+		original = int32(4)
+		fixed    = int32(2)
+	)
+
+	newScaledObject := func(advanced *v1alpha1.AdvancedConfig) *v1alpha1.ScaledObject {
+		return &v1alpha1.ScaledObject{
+			Spec:   v1alpha1.ScaledObjectSpec{Advanced: advanced},
+			Status: v1alpha1.ScaledObjectStatus{OriginalReplicaCount: &original},
+		}
+	}
+
+	It("should keep current replicas by default", func() {
+		_, ok := getOnDeleteReplicaCount(newScaledObject(nil))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should restore original replicas with restoreToOriginalReplicaCount", func() {
+		replicas, ok := getOnDeleteReplicaCount(newScaledObject(&v1alpha1.AdvancedConfig{RestoreToOriginalReplicaCount: true}))
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(original))
+	})
+
+	It("should prefer onDelete policy over restoreToOriginalReplicaCount", func() {
+		_, ok := getOnDeleteReplicaCount(newScaledObject(&v1alpha1.AdvancedConfig{
+			RestoreToOriginalReplicaCount: true,
+			OnDelete:                      &v1alpha1.OnDeletePolicy{Policy: v1alpha1.OnDeleteKeepCurrent},
+		}))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should scale to fixed replicas", func() {
+		replicas, ok := getOnDeleteReplicaCount(newScaledObject(&v1alpha1.AdvancedConfig{
+			OnDelete: &v1alpha1.OnDeletePolicy{Policy: v1alpha1.OnDeleteFixedReplicas, Replicas: &fixed},
+		}))
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(fixed))
+	})
+})
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RemoveFinalizer removes the finalizer from the object, the update is retried with the latest version of the object
+// when it conflicts so a stale object doesn't leave the finalizer behind. An object already gone is not an error.
+func RemoveFinalizer(ctx context.Context, c client.Client, obj client.Object, finalizer string) error {
+	refresh := false
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if refresh {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+		}
+		refresh = true
+
+		if !Contains(obj.GetFinalizers(), finalizer) {
+			return nil
+		}
+		obj.SetFinalizers(Remove(obj.GetFinalizers(), finalizer))
+		err := c.Update(ctx, obj)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+}
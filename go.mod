@@ -16,7 +16,7 @@ require (
 	github.com/Huawei/gophercloud v1.0.21
 	github.com/Shopify/sarama v1.30.0
 	github.com/aws/aws-sdk-go v1.42.3
-	github.com/denisenkom/go-mssqldb v0.11.0
+	github.com/go-ldap/ldap/v3 v3.4.1
 	github.com/go-logr/logr v0.4.0
 	github.com/go-playground/assert/v2 v2.0.1
 	github.com/go-redis/redis/v8 v8.11.4
@@ -24,11 +24,13 @@ require (
 	github.com/gocql/gocql v0.0.0-20211015133455-b225f9b53fa1
 	github.com/golang/mock v1.6.0
 	github.com/golang/protobuf v1.5.2
+	github.com/golang/snappy v0.0.4
 	github.com/google/go-cmp v0.5.6
 	github.com/hashicorp/vault/api v1.3.0
 	github.com/imdario/mergo v0.3.12
 	github.com/influxdata/influxdb-client-go/v2 v2.5.1
 	github.com/lib/pq v1.10.4
+	github.com/microsoft/go-mssqldb v0.18.0
 	github.com/mitchellh/hashstructure v1.1.0
 	github.com/onsi/ginkgo v1.16.5
 	github.com/onsi/gomega v1.17.0
@@ -37,12 +39,16 @@ require (
 	github.com/rcrowley/go-metrics v0.0.0-20201227073835-cf1acfcdf475
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/afero v1.6.0 // indirect
+	github.com/spiffe/go-spiffe/v2 v2.0.0
 	github.com/streadway/amqp v1.0.0
 	github.com/stretchr/testify v1.7.0
+	github.com/tetratelabs/wazero v1.0.0
 	github.com/tidwall/gjson v1.11.0
 	github.com/xdg/scram v1.0.3
 	github.com/xdg/stringprep v1.0.3 // indirect
 	go.mongodb.org/mongo-driver v1.7.4
+	go.uber.org/zap v1.19.1
+	golang.org/x/oauth2 v0.0.0-20211028175245-ba495a64dcb5
 	google.golang.org/api v0.60.0
 	google.golang.org/genproto v0.0.0-20211111162719-482062a4217b
 	google.golang.org/grpc v1.42.0
@@ -58,4 +64,5 @@ require (
 	knative.dev/pkg v0.0.0-20211111114938-0b0c3390a475
 	sigs.k8s.io/controller-runtime v0.10.3
 	sigs.k8s.io/custom-metrics-apiserver v1.22.0
+	sigs.k8s.io/yaml v1.3.0
 )
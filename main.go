@@ -20,20 +20,41 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollers "github.com/kedacore/keda/v2/controllers/keda"
+	"github.com/kedacore/keda/v2/pkg/audit"
+	"github.com/kedacore/keda/v2/pkg/certificates"
+	"github.com/kedacore/keda/v2/pkg/debug"
+	"github.com/kedacore/keda/v2/pkg/eventemitter"
+	prommetrics "github.com/kedacore/keda/v2/pkg/metrics"
+	"github.com/kedacore/keda/v2/pkg/metricsservice"
+	"github.com/kedacore/keda/v2/pkg/operatorconfig"
+	kedaprovider "github.com/kedacore/keda/v2/pkg/provider"
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+	"github.com/kedacore/keda/v2/pkg/scaling"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	"github.com/kedacore/keda/v2/pkg/scaling/state"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+	"github.com/kedacore/keda/v2/pkg/webhooks"
 	"github.com/kedacore/keda/v2/version"
 	//+kubebuilder:scaffold:imports
 )
@@ -50,6 +71,17 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
+// splitList returns the non empty items of a comma separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getWatchNamespace returns the namespace the operator should be watching for changes
 func getWatchNamespace() (string, error) {
 	const WatchNamespaceEnvVar = "WATCH_NAMESPACE"
@@ -64,17 +96,98 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var cloudEventsSink string
+	var debugAddr string
+	var leaderElectionID string
+	var shardLabelSelector string
+	var metricsServiceAddr string
+	var decisionLogPath string
+	var rateLimits kedaprovider.RateLimits
+	var httpTransport kedautil.HTTPTransportOptions
+	var enableHTTP2 bool
+	var namespaceQPS, hostQPS float64
+	var orphanCollectionInterval time.Duration
+	var orphanPolicy string
+	var scaledObjectConcurrency, scaledJobConcurrency int
+	var kubeAPIQPS float64
+	var kubeAPIBurst int
+	var syncPeriod time.Duration
+	var enableProfiling bool
+	var notificationAddr string
+	var egressPolicyPath string
+	var remoteWriteURL, remoteWriteBearerTokenFile string
+	var remoteWriteInterval time.Duration
+	var enableDefaultingWebhook bool
+	var enableValidatingWebhook bool
+	var pollingJitter float64
+	var credentialsCacheTTL time.Duration
+	var credentialsCacheKMSKeyID string
+	var stateStore, stateStoreNamespace, stateStoreRedisAddr string
+	var stateSyncInterval time.Duration
+	var operatorConfigMap string
+	var enableCertRotation, metricsServiceTLS bool
+	var certDir, certSecretNamespace, certSecretName, certServiceNames, certWebhookConfigurations, certValidatingWebhookConfigurations, certAPIServices string
+	var certValidity time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&cloudEventsSink, "cloudevents-sink", "", "The HTTP endpoint KEDA events are emitted to as CloudEvents. Disabled if empty.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "operator.keda.sh", "The name of the resource used for leader election, it has to be unique for every shard.")
+	flag.StringVar(&shardLabelSelector, "shard-label-selector", "", "The label selector of the ScaledObjects and ScaledJobs managed by this operator instance (shard). All objects are managed if empty.")
+	flag.StringVar(&metricsServiceAddr, "metrics-service-bind-address", ":9666", "The address the gRPC Metrics Service used by KEDA Metrics Server binds to, it is served in plaintext to anyone who can reach it unless --metrics-service-tls is set, the keda-operator NetworkPolicy only admits the KEDA Metrics Server. Disabled if empty.")
+	flag.StringVar(&decisionLogPath, "decision-log", "", "The file the scaling decisions are written to as JSON lines, '-' writes them to stdout. Disabled if empty.")
+	flag.StringVar(&debugAddr, "debug-bind-address", "", "The address the debug endpoint used by kubectl-keda binds to. Disabled if empty.")
 	opts := zap.Options{}
+	flag.Float64Var(&namespaceQPS, "metrics-namespace-qps", 0, "The QPS of the metric queries of the ScaledObjects of a namespace served by the Metrics Service. Unlimited if 0.")
+	flag.IntVar(&rateLimits.NamespaceBurst, "metrics-namespace-burst", 10, "The burst of the metric queries of the ScaledObjects of a namespace served by the Metrics Service.")
+	flag.Float64Var(&hostQPS, "metrics-host-qps", 0, "The QPS of the metric queries to a scaler backend host served by the Metrics Service. Unlimited if 0.")
+	flag.IntVar(&rateLimits.HostBurst, "metrics-host-burst", 10, "The burst of the metric queries to a scaler backend host served by the Metrics Service.")
+	flag.DurationVar(&orphanCollectionInterval, "orphan-collection-interval", 10*time.Minute, "The interval of the sweeps of the HPAs and Jobs left behind by their ScaledObject or ScaledJob. Disabled if 0.")
+	flag.StringVar(&orphanPolicy, "orphan-collection-policy", string(kedacontrollers.OrphanPolicyReport), "What is done with the HPAs and Jobs left behind by their ScaledObject or ScaledJob, 'report' in events or 'delete'.")
+	flag.IntVar(&scaledObjectConcurrency, "scaledobject-max-concurrent-reconciles", 1, "The number of ScaledObjects reconciled in parallel.")
+	flag.IntVar(&scaledJobConcurrency, "scaledjob-max-concurrent-reconciles", 1, "The number of ScaledJobs reconciled in parallel.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 20, "The QPS of the requests to the Kubernetes API server.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 30, "The burst of the requests to the Kubernetes API server.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour, "The period all the watched objects are reconciled at, even without changes.")
+	flag.BoolVar(&enableProfiling, "enable-profiling", false, "Expose the pprof endpoints on the debug endpoint, the callers need the permission to get the non resource URLs /debug/pprof/*. Requires --debug-bind-address.")
+	flag.StringVar(&notificationAddr, "notification-bind-address", "", "The address the endpoint receiving the activation notifications of the brokers, eg. GCP Pub/Sub push and Azure Event Grid, and the webhooks of the alertmanager and webhook triggers binds to. Disabled if empty.")
+	flag.StringVar(&egressPolicyPath, "scaler-egress-policy", "", "The YAML file of the allow-lists of the hosts and CIDRs the scalers of each namespace can connect to. The scalers are unrestricted if empty. The trigger types whose clients dial on their own, eg. kafka, postgresql, mysql, mssql, mongodb, cassandra and the cloud SDK triggers, are refused in the restricted namespaces.")
+	flag.StringVar(&remoteWriteURL, "metrics-remote-write-url", "", "The Prometheus remote write endpoint the external metric values computed by the Metrics Service are sent to. Disabled if empty.")
+	flag.DurationVar(&remoteWriteInterval, "metrics-remote-write-interval", 30*time.Second, "The interval the external metric values are sent to the Prometheus remote write endpoint at.")
+	flag.StringVar(&remoteWriteBearerTokenFile, "metrics-remote-write-bearer-token-file", "", "The file of the bearer token of the requests to the Prometheus remote write endpoint.")
+	flag.Float64Var(&pollingJitter, "polling-jitter", 0, "The fraction of the pollingInterval, between 0 and 1, the first checks of the ScaledObjects and ScaledJobs are spread over by a hash of their name, so the objects created together don't query their backends at the same moment. Disabled if 0.")
+	flag.DurationVar(&credentialsCacheTTL, "credentials-cache-ttl", 0, "How long the credentials read from the external secret stores of the TriggerAuthentications, eg. HashiCorp Vault, are cached, the scalers are rebuilt with the credentials read again once it elapsed. Disabled if 0.")
+	flag.StringVar(&credentialsCacheKMSKeyID, "credentials-cache-kms-key-id", "", "The AWS KMS key the data keys encrypting the cached credentials are wrapped with, the credentials are cached unencrypted if empty.")
+	flag.StringVar(&stateStore, "state-store", "", "Where the state of the scalers and of the rate metric mode, eg. the previous samples, is persisted so it survives the restarts of the operator, 'configmap' or 'redis'. Disabled if empty.")
+	flag.StringVar(&stateStoreNamespace, "state-store-namespace", "keda", "The namespace of the ConfigMaps of the 'configmap' state store.")
+	flag.StringVar(&stateStoreRedisAddr, "state-store-redis-address", "", "The address of the Redis server of the 'redis' state store, the password is read from the KEDA_STATE_STORE_REDIS_PASSWORD environment variable.")
+	flag.DurationVar(&stateSyncInterval, "state-sync-interval", time.Minute, "The minimal interval between two saves of the state of a ScaledObject or ScaledJob, it is saved on shutdown too.")
+	flag.StringVar(&operatorConfigMap, "config-map", "", "The ConfigMap, as namespace/name, the settings of the operator are reloaded from on every change: logLevel, httpTimeout, httpMaxIdleConns, httpIdleConnTimeout, httpEnableHTTP2, credentialsCacheTTL, pollingJitter and stateSyncInterval. The settings it doesn't give keep the values of the flags. Disabled if empty.")
+	flag.IntVar(&httpTransport.MaxIdleConns, "http-max-idle-conns", 0, "The number of idle connections to a host kept by the HTTP clients of the scalers, the triggers can override it with httpMaxIdleConns. The default of Go, 2, if 0.")
+	flag.DurationVar(&httpTransport.IdleConnTimeout, "http-idle-conn-timeout", 0, "How long the idle connections of the HTTP clients of the scalers are kept, the triggers can override it with httpIdleConnTimeout. Kept until the scaler is closed if 0.")
+	flag.BoolVar(&enableHTTP2, "http-enable-http2", false, "Attempt HTTP/2 with the TLS backends of the HTTP clients of the scalers, the triggers can override it with httpEnableHTTP2.")
+	flag.BoolVar(&enableDefaultingWebhook, "enable-scaledobject-defaulting-webhook", false, "Serve the mutating webhook normalizing the deprecated trigger metadata of the ScaledObjects and setting their KedaConfig defaults, with a warning for each change. Requires the serving certificates of the webhook server.")
+	flag.BoolVar(&enableValidatingWebhook, "enable-kedaconfig-validating-webhook", false, "Serve the validating webhooks denying the ScaledObjects and ScaledJobs which break the limits of their KedaConfigs. Requires the serving certificates of the webhook server.")
+	flag.BoolVar(&enableCertRotation, "enable-cert-rotation", false, "Issue the serving certificates of the webhook server, the Metrics Service and the KEDA Metrics Server from a self-signed CA kept in --cert-secret-name, rotate them before they expire and inject the CA in the webhook configurations and APIServices. Disable it when the certificates are managed by cert-manager.")
+	flag.StringVar(&certDir, "cert-dir", filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"), "The directory of the serving certificates of the webhook server and the Metrics Service, tls.crt and tls.key, and of their CA, ca.crt.")
+	flag.StringVar(&certSecretNamespace, "cert-secret-namespace", "keda", "The namespace of the Secret of the certificates and of the Services they are issued for.")
+	flag.StringVar(&certSecretName, "cert-secret-name", "kedaorg-certs", "The Secret the certificates issued with --enable-cert-rotation are kept in, the KEDA Metrics Server mounts it. The Role of the operator only allows updating the kedaorg-certs Secret of the keda namespace.")
+	flag.StringVar(&certServiceNames, "cert-service-names", "keda-operator,keda-operator-webhook,keda-metrics-apiserver", "The comma separated Services the serving certificate issued with --enable-cert-rotation is valid for.")
+	flag.StringVar(&certWebhookConfigurations, "cert-webhook-configurations", "mutating-webhook-configuration", "The comma separated MutatingWebhookConfigurations the CA is injected in with --enable-cert-rotation.")
+	flag.StringVar(&certValidatingWebhookConfigurations, "cert-validating-webhook-configurations", "validating-webhook-configuration", "The comma separated ValidatingWebhookConfigurations the CA is injected in with --enable-cert-rotation.")
+	flag.StringVar(&certAPIServices, "cert-api-services", "v1beta1.external.metrics.k8s.io", "The comma separated APIServices the CA is injected in with --enable-cert-rotation.")
+	flag.DurationVar(&certValidity, "cert-validity", 365*24*time.Hour, "How long the serving certificates issued with --enable-cert-rotation are valid, they are rotated once less than a third of it remains. The CA is valid ten times longer.")
+	flag.BoolVar(&metricsServiceTLS, "metrics-service-tls", false, "Serve the Metrics Service over TLS with the serving certificate of --cert-dir, the KEDA Metrics Server verifies it with --metrics-service-ca-file. The metric values and the ScaledObjects are served in plaintext without it.")
 	opts.BindFlags(flag.CommandLine)
 
 	flag.Parse()
+	rateLimits.NamespaceQPS, rateLimits.HostQPS = float32(namespaceQPS), float32(hostQPS)
 
+	logLevel := operatorconfig.NewLogLevel(&opts)
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	ctx := ctrl.SetupSignalHandler()
 
 	namespace, err := getWatchNamespace()
 	if err != nil {
@@ -82,20 +195,75 @@ func main() {
 		os.Exit(1)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	shardSelector, err := kedautil.ParseShardSelector(shardLabelSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid shard label selector")
+		os.Exit(1)
+	}
+
+	if egressPolicyPath != "" {
+		egressPolicy, err := kedautil.LoadEgressPolicy(egressPolicyPath)
+		if err != nil {
+			setupLog.Error(err, "invalid scaler egress policy")
+			os.Exit(1)
+		}
+		kedautil.SetEgressPolicy(egressPolicy)
+	}
+
+	if err := scaling.SetPollingJitter(pollingJitter); err != nil {
+		setupLog.Error(err, "invalid polling jitter")
+		os.Exit(1)
+	}
+	httpTransport.HTTP2 = &enableHTTP2
+	if err := kedautil.SetHTTPTransportOptions(httpTransport); err != nil {
+		setupLog.Error(err, "invalid HTTP transport options")
+		os.Exit(1)
+	}
+	if err := resolver.SetCredentialsCache(credentialsCacheTTL, credentialsCacheKMSKeyID); err != nil {
+		setupLog.Error(err, "invalid credentials cache")
+		os.Exit(1)
+	}
+
+	options := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
+		CertDir:                certDir,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "operator.keda.sh",
-		Namespace:              namespace,
-	})
+		LeaderElectionID:       leaderElectionID,
+		SyncPeriod:             &syncPeriod,
+	}
+	// WATCH_NAMESPACE can contain a comma separated list of namespaces
+	kedautil.ConfigureWatchNamespaces(&options, kedautil.ParseWatchNamespaces(namespace))
+
+	cfg := ctrl.GetConfigOrDie()
+	cfg.QPS, cfg.Burst = float32(kubeAPIQPS), kubeAPIBurst
+
+	mgr, err := ctrl.NewManager(cfg, options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	var store state.Store
+	switch stateStore {
+	case "":
+	case "configmap":
+		store, err = state.NewConfigMapStore(mgr.GetClient(), mgr.GetAPIReader(), stateStoreNamespace)
+	case "redis":
+		store, err = state.NewRedisStore(stateStoreRedisAddr, os.Getenv("KEDA_STATE_STORE_REDIS_PASSWORD"))
+	default:
+		err = fmt.Errorf("unknown state store %q, supported are configmap and redis", stateStore)
+	}
+	if err == nil {
+		err = state.SetStore(store, stateSyncInterval)
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid state store")
+		os.Exit(1)
+	}
+
 	globalHTTPTimeoutStr := os.Getenv("KEDA_HTTP_DEFAULT_TIMEOUT")
 	if globalHTTPTimeoutStr == "" {
 		// default to 3 seconds if they don't pass the env var
@@ -109,14 +277,36 @@ func main() {
 	}
 
 	globalHTTPTimeout := time.Duration(globalHTTPTimeoutMS) * time.Millisecond
-	eventRecorder := mgr.GetEventRecorderFor("keda-operator")
+	var eventRecorder record.EventRecorder = mgr.GetEventRecorderFor("keda-operator")
+	if cloudEventsSink != "" {
+		cloudEventsRecorder := eventemitter.NewCloudEventsRecorder(eventRecorder, cloudEventsSink, globalHTTPTimeout)
+		if err := mgr.Add(cloudEventsRecorder); err != nil {
+			setupLog.Error(err, "unable to set up CloudEvents emitter")
+			os.Exit(1)
+		}
+		eventRecorder = cloudEventsRecorder
+	}
 
-	if err = (&kedacontrollers.ScaledObjectReconciler{
+	var decisionLogger audit.DecisionLogger
+	if decisionLogPath != "" {
+		decisionLogger, err = audit.NewDecisionLogger(decisionLogPath)
+		if err != nil {
+			setupLog.Error(err, "unable to set up decision log")
+			os.Exit(1)
+		}
+	}
+
+	scaledObjectReconciler := &kedacontrollers.ScaledObjectReconciler{
 		Client:            mgr.GetClient(),
 		Scheme:            mgr.GetScheme(),
 		GlobalHTTPTimeout: globalHTTPTimeout,
 		Recorder:          eventRecorder,
-	}).SetupWithManager(mgr); err != nil {
+		ShardSelector:     shardSelector,
+		DecisionLogger:    decisionLogger,
+
+		MaxConcurrentReconciles: scaledObjectConcurrency,
+	}
+	if err = scaledObjectReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScaledObject")
 		os.Exit(1)
 	}
@@ -125,6 +315,10 @@ func main() {
 		Scheme:            mgr.GetScheme(),
 		GlobalHTTPTimeout: globalHTTPTimeout,
 		Recorder:          eventRecorder,
+		ShardSelector:     shardSelector,
+		DecisionLogger:    decisionLogger,
+
+		MaxConcurrentReconciles: scaledJobConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScaledJob")
 		os.Exit(1)
@@ -147,6 +341,155 @@ func main() {
 	}
 	//+kubebuilder:scaffold:builder
 
+	if operatorConfigMap != "" {
+		parts := strings.Split(operatorConfigMap, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			setupLog.Error(fmt.Errorf("%q isn't namespace/name", operatorConfigMap), "invalid operator ConfigMap")
+			os.Exit(1)
+		}
+		kubeClientset, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			setupLog.Error(err, "unable to create the Kubernetes client of the operator ConfigMap")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&operatorconfig.Reloader{
+			Client:    kubeClientset,
+			Namespace: parts[0],
+			Name:      parts[1],
+			Defaults: operatorconfig.Settings{
+				LogLevel:            logLevel.Level(),
+				HTTPTransport:       httpTransport,
+				CredentialsCacheTTL: credentialsCacheTTL,
+				PollingJitter:       pollingJitter,
+				StateSyncInterval:   stateSyncInterval,
+			},
+			LogLevel: logLevel,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the reload of the operator ConfigMap")
+			os.Exit(1)
+		}
+	}
+
+	if orphanCollectionInterval > 0 {
+		policy, err := kedacontrollers.ParseOrphanPolicy(orphanPolicy)
+		if err != nil {
+			setupLog.Error(err, "invalid orphan collection policy")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&kedacontrollers.OrphanCollector{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      eventRecorder,
+			Interval:      orphanCollectionInterval,
+			Policy:        policy,
+			ShardSelector: shardSelector,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up orphan collector")
+			os.Exit(1)
+		}
+	}
+
+	// the metrics are served by every replica with the scalers of the ScaledObject controller, the replicas which
+	// aren't the leader clear the caches of the deleted ScaledObjects on their own
+	var metricsHandler scaling.ScaleHandler
+	if metricsServiceAddr != "" {
+		metricsHandler = scaledObjectReconciler.ScaleHandler()
+		if err := (&kedacontrollers.MetricsScaledObjectReconciler{
+			Client:        mgr.GetClient(),
+			ScaleHandler:  metricsHandler,
+			ShardSelector: shardSelector,
+		}).SetupWithManager(mgr, controller.Options{}); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "MetricsScaledObject")
+			os.Exit(1)
+		}
+	}
+
+	if enableProfiling && debugAddr == "" {
+		setupLog.Info("Profiling requires the debug endpoint, set --debug-bind-address to enable it")
+	}
+	if debugAddr != "" {
+		if err := mgr.Add(debug.NewServer(debugAddr, mgr.GetClient(), mgr.GetScheme(), globalHTTPTimeout, metricsHandler, enableProfiling)); err != nil {
+			setupLog.Error(err, "unable to set up debug server")
+			os.Exit(1)
+		}
+	}
+
+	if enableDefaultingWebhook {
+		mgr.GetWebhookServer().Register(webhooks.ScaledObjectDefaulterPath, &webhook.Admission{Handler: &webhooks.ScaledObjectDefaulter{Client: mgr.GetClient()}})
+	}
+	if enableValidatingWebhook {
+		mgr.GetWebhookServer().Register(webhooks.ScaledObjectValidatorPath, &webhook.Admission{Handler: &webhooks.LimitsValidator{Client: mgr.GetClient()}})
+		mgr.GetWebhookServer().Register(webhooks.ScaledJobValidatorPath, &webhook.Admission{Handler: &webhooks.LimitsValidator{Client: mgr.GetClient()}})
+	}
+
+	if notificationAddr != "" {
+		if err := mgr.Add(notification.NewServer(notificationAddr, notification.Default, notification.DefaultSignals, notification.DefaultWebhooks)); err != nil {
+			setupLog.Error(err, "unable to set up notification server")
+			os.Exit(1)
+		}
+	}
+
+	if metricsServiceAddr != "" && remoteWriteURL != "" {
+		remoteWriter, err := prommetrics.NewRemoteWriter(remoteWriteURL, remoteWriteInterval, remoteWriteBearerTokenFile)
+		if err != nil {
+			setupLog.Error(err, "invalid metrics remote write endpoint")
+			os.Exit(1)
+		}
+		if err := mgr.Add(remoteWriter); err != nil {
+			setupLog.Error(err, "unable to set up metrics remote write")
+			os.Exit(1)
+		}
+		prommetrics.SetRemoteWriter(remoteWriter)
+	}
+
+	if metricsServiceAddr != "" {
+		metricsProvider := kedaprovider.NewProvider(ctx, ctrl.Log.WithName("metricsservice"), metricsHandler, mgr.GetClient(), namespace, shardSelector, rateLimits)
+		var metricsServiceCertDir string
+		if metricsServiceTLS {
+			metricsServiceCertDir = certDir
+		} else {
+			setupLog.Info("the Metrics Service is served in plaintext, restrict its clients with a NetworkPolicy or set --metrics-service-tls", "address", metricsServiceAddr)
+		}
+		if err := mgr.Add(metricsservice.NewGrpcServer(metricsProvider, metricsServiceAddr, metricsServiceCertDir)); err != nil {
+			setupLog.Error(err, "unable to set up Metrics Service gRPC server")
+			os.Exit(1)
+		}
+	}
+
+	if enableCertRotation {
+		kubeClientset, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			setupLog.Error(err, "unable to create the Kubernetes client of the certificate rotation")
+			os.Exit(1)
+		}
+		dynamicClient, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			setupLog.Error(err, "unable to create the dynamic client of the certificate rotation")
+			os.Exit(1)
+		}
+		rotator := &certificates.Rotator{
+			Client:             kubeClientset,
+			DynamicClient:      dynamicClient,
+			SecretNamespace:    certSecretNamespace,
+			SecretName:         certSecretName,
+			CertDir:            certDir,
+			DNSNames:           certificates.ServiceDNSNames(certSecretNamespace, splitList(certServiceNames)),
+			Validity:           certValidity,
+			MutatingWebhooks:   splitList(certWebhookConfigurations),
+			ValidatingWebhooks: splitList(certValidatingWebhookConfigurations),
+			APIServices:        splitList(certAPIServices),
+		}
+		// the servers read their certificates when they start, they are issued before
+		if err := rotator.Ensure(ctx); err != nil {
+			setupLog.Error(err, "unable to issue the certificates")
+			os.Exit(1)
+		}
+		if err := mgr.Add(rotator); err != nil {
+			setupLog.Error(err, "unable to set up the certificate rotation")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -162,7 +505,7 @@ func main() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
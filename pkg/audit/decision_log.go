@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// StdoutPath is the decision log path that writes the decisions to the standard output
+const StdoutPath = "-"
+
+// Decision is a single evaluation of the triggers of a ScaledObject or ScaledJob
+type Decision struct {
+	Time            time.Time         `json:"time"`
+	Kind            string            `json:"kind"`
+	Namespace       string            `json:"namespace"`
+	Name            string            `json:"name"`
+	Active          bool              `json:"active"`
+	Error           bool              `json:"error"`
+	CurrentReplicas *int32            `json:"currentReplicas,omitempty"`
+	DesiredReplicas *int32            `json:"desiredReplicas,omitempty"`
+	Triggers        []TriggerDecision `json:"triggers"`
+}
+
+// TriggerDecision holds the evaluated metric of a single trigger
+type TriggerDecision struct {
+	Index      int    `json:"index"`
+	Type       string `json:"type"`
+	MetricName string `json:"metricName,omitempty"`
+	Value      string `json:"value,omitempty"`
+	Target     string `json:"target,omitempty"`
+	TargetType string `json:"targetType,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DecisionLogger records autoscaling decisions
+type DecisionLogger interface {
+	Log(decision Decision)
+}
+
+type jsonLinesLogger struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+// NewDecisionLogger returns a DecisionLogger writing the decisions as JSON lines to the file on path,
+// the file is created if it doesn't exist. StdoutPath writes the decisions to the standard output.
+func NewDecisionLogger(path string) (DecisionLogger, error) {
+	if path == StdoutPath {
+		return NewWriterDecisionLogger(os.Stdout), nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening decision log %s: %s", path, err)
+	}
+	return NewWriterDecisionLogger(f), nil
+}
+
+// NewWriterDecisionLogger returns a DecisionLogger writing the decisions as JSON lines to out
+func NewWriterDecisionLogger(out io.Writer) DecisionLogger {
+	return &jsonLinesLogger{out: out}
+}
+
+func (l *jsonLinesLogger) Log(decision Decision) {
+	line, err := json.Marshal(decision)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, _ = l.out.Write(line)
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecisionLoggerWritesJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewWriterDecisionLogger(&out)
+
+	replicas := int32(3)
+	logger.Log(Decision{
+		Time:            time.Unix(0, 0).UTC(),
+		Kind:            "ScaledObject",
+		Namespace:       "default",
+		Name:            "so",
+		Active:          true,
+		DesiredReplicas: &replicas,
+		Triggers:        []TriggerDecision{{Index: 0, Type: "cronScaler", MetricName: "s0-cron", Value: "1", Target: "1"}},
+	})
+	logger.Log(Decision{Kind: "ScaledJob", Namespace: "default", Name: "sj"})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var decision Decision
+	if err := json.Unmarshal([]byte(lines[0]), &decision); err != nil {
+		t.Fatal(err)
+	}
+	if decision.Name != "so" || !decision.Active || *decision.DesiredReplicas != 3 || len(decision.Triggers) != 1 {
+		t.Errorf("unexpected decision %+v", decision)
+	}
+	if strings.Contains(lines[1], "desiredReplicas") {
+		t.Errorf("expected desiredReplicas to be omitted: %s", lines[1])
+	}
+}
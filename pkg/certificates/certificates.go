@@ -0,0 +1,258 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certificates manages the TLS certificates the operator serves its admission webhook, the Metrics Service
+// gRPC server and the KEDA Metrics Server with, without cert-manager. The certificates are issued by a self-signed
+// CA, kept in a Secret shared by the replicas and rotated before they expire.
+package certificates
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// the keys of the certificates in the Secret and the names of their files in the certificate directory, the
+// names of the serving certificate are the ones expected by the webhook server of controller-runtime
+const (
+	CACertName = "ca.crt"
+	caKeyName  = "ca.key"
+	CertName   = "tls.crt"
+	KeyName    = "tls.key"
+)
+
+// caValidityFactor is how many times longer than the serving certificates the CA is valid
+const caValidityFactor = 10
+
+// notBeforeSkew backdates the certificates so they are already valid for the clients whose clock is late
+const notBeforeSkew = time.Hour
+
+// keyPair is a PEM encoded certificate and its PEM encoded private key
+type keyPair struct {
+	cert []byte
+	key  []byte
+}
+
+// newCA creates a self-signed CA valid from now for validity
+func newCA(now time.Time, validity time.Duration) (keyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "keda-ca", Organization: []string{"KEDA"}},
+		NotBefore:             now.Add(-notBeforeSkew),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newKeyPair(template, nil, nil)
+}
+
+// newServingCert creates a serving certificate for the DNS names issued by ca, valid from now for validity
+func newServingCert(ca keyPair, dnsNames []string, now time.Time, validity time.Duration) (keyPair, error) {
+	caCert, caKey, err := parseKeyPair(ca)
+	if err != nil {
+		return keyPair{}, fmt.Errorf("invalid CA: %s", err)
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0], Organization: []string{"KEDA"}},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-notBeforeSkew),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	// the serving certificate never outlives its CA
+	if template.NotAfter.After(caCert.NotAfter) {
+		template.NotAfter = caCert.NotAfter
+	}
+	return newKeyPair(template, caCert, caKey)
+}
+
+// newKeyPair creates a new key and signs the certificate of template with it, or with the key of parent if set
+func newKeyPair(template, parent *x509.Certificate, parentKey crypto.Signer) (keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return keyPair{}, fmt.Errorf("error generating the key: %s", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return keyPair{}, fmt.Errorf("error generating the serial number: %s", err)
+	}
+	template.SerialNumber = serial
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return keyPair{}, fmt.Errorf("error signing the certificate: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return keyPair{}, fmt.Errorf("error encoding the key: %s", err)
+	}
+	return keyPair{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// withPreviousCA returns the bundle of caCert followed by the current CA of bundle, its first certificate, if it
+// hasn't expired at now, so the certificates issued by the previous CA are still trusted once the new one is
+// published
+func withPreviousCA(caCert, bundle []byte, now time.Time) []byte {
+	block, _ := pem.Decode(bundle)
+	if block == nil {
+		return caCert
+	}
+	previous, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || !previous.NotAfter.After(now) {
+		return caCert
+	}
+	return append(append([]byte{}, caCert...), pem.EncodeToMemory(block)...)
+}
+
+// withoutPreviousCA returns bundle without the previous CA once a validity of the serving certificates has passed
+// since ca, the current CA, was issued, the serving certificates issued by the previous CA have expired by then
+func withoutPreviousCA(bundle []byte, ca *x509.Certificate, now time.Time, validity time.Duration) []byte {
+	block, rest := pem.Decode(bundle)
+	if block == nil || len(bytes.TrimSpace(rest)) == 0 || now.Before(ca.NotBefore.Add(notBeforeSkew+validity)) {
+		return bundle
+	}
+	return pem.EncodeToMemory(block)
+}
+
+// parseKeyPair returns the certificate and the key of pair, they have to match, the certificate is the first
+// one of a bundle
+func parseKeyPair(pair keyPair) (*x509.Certificate, crypto.Signer, error) {
+	tlsCert, err := tls.X509KeyPair(pair.cert, pair.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := tlsCert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("the key can't sign")
+	}
+	return cert, signer, nil
+}
+
+// needsRotation returns the reason pair has to be replaced at now, it is empty if pair is still valid for more
+// than a third of its validity, is issued by ca if set and is valid for the DNS names
+func needsRotation(pair keyPair, ca *x509.Certificate, dnsNames []string, now time.Time) string {
+	cert, _, err := parseKeyPair(pair)
+	if err != nil {
+		return fmt.Sprintf("invalid certificate: %s", err)
+	}
+	if remaining := cert.NotAfter.Sub(now); remaining < cert.NotAfter.Sub(cert.NotBefore)/3 {
+		return fmt.Sprintf("expiring at %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if ca != nil {
+		if err := cert.CheckSignatureFrom(ca); err != nil {
+			return "not issued by the current CA"
+		}
+	}
+	if dnsNames != nil && !equalNames(cert.DNSNames, dnsNames) {
+		return "issued for other DNS names"
+	}
+	return ""
+}
+
+// ServiceDNSNames returns the DNS names the Services of namespace are reached with from the cluster, eg.
+// `keda-operator.keda.svc` and `keda-operator.keda.svc.cluster.local`
+func ServiceDNSNames(namespace string, services []string) []string {
+	var names []string
+	for _, service := range services {
+		names = append(names,
+			service,
+			fmt.Sprintf("%s.%s", service, namespace),
+			fmt.Sprintf("%s.%s.svc", service, namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace))
+	}
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// NewClientTLSConfig returns the TLS config of the clients of a server whose certificate is issued by the CA of
+// caFile, the file is read again on every handshake so the CA rotations are picked up without a restart
+func NewClientTLSConfig(caFile string) (*tls.Config, error) {
+	if _, err := loadCAPool(caFile); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the chain is verified by VerifyConnection against the CA read on each handshake
+		InsecureSkipVerify: true, // #nosec G402
+		VerifyConnection: func(state tls.ConnectionState) error {
+			pool, err := loadCAPool(caFile)
+			if err != nil {
+				return err
+			}
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("the server sent no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err = state.PeerCertificates[0].Verify(x509.VerifyOptions{
+				DNSName:       state.ServerName,
+				Roots:         pool,
+				Intermediates: intermediates,
+			})
+			return err
+		},
+	}, nil
+}
+
+func loadCAPool(caFile string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the CA: %s", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bytes.TrimSpace(data)) {
+		return nil, fmt.Errorf("no certificate in the CA file %s", caFile)
+	}
+	return pool, nil
+}
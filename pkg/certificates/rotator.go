@@ -0,0 +1,336 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// the certificates Secret is written with a Role of the keda namespace, only its update is restricted to its name
+// as the creations can't be
+// +kubebuilder:rbac:groups="",namespace=keda,resources=secrets,verbs=create
+// +kubebuilder:rbac:groups="",namespace=keda,resources=secrets,resourceNames=kedaorg-certs,verbs=update
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;patch
+// +kubebuilder:rbac:groups=apiregistration.k8s.io,resources=apiservices,verbs=get;patch
+
+var rotatorLog = logf.Log.WithName("cert_rotator")
+
+// defaultCheckInterval is how often the certificates are checked when the Rotator doesn't set it
+const defaultCheckInterval = time.Hour
+
+var apiServiceResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// Rotator keeps the serving certificate of the DNS names and its CA in a Secret, writes them to CertDir and injects
+// the CA in the caBundle of the webhook configurations and the APIServices. The certificates are replaced
+// once less than a third of their validity remains, the CA is valid for ten times the serving certificate. The
+// previous CA is published with the new one for a validity of the serving certificates after a CA rotation, so the
+// serving certificates it issued are trusted until the replicas serve the new ones.
+// Every replica runs it so they all write the certificates they serve, the Secret is updated with optimistic
+// concurrency so the replicas converge on the certificates of the first one to rotate them.
+type Rotator struct {
+	Client        kubernetes.Interface
+	DynamicClient dynamic.Interface
+	// SecretNamespace and SecretName are the Secret the certificates are kept in
+	SecretNamespace string
+	SecretName      string
+	// CertDir is the directory the certificates are written to, CACertName, CertName and KeyName
+	CertDir string
+	// DNSNames are the names of the serving certificate, eg. the names of the Services of the operator
+	DNSNames []string
+	// Validity is how long the serving certificates are valid
+	Validity time.Duration
+	// MutatingWebhooks are the names of the MutatingWebhookConfigurations the CA is injected in
+	MutatingWebhooks []string
+	// ValidatingWebhooks are the names of the ValidatingWebhookConfigurations the CA is injected in
+	ValidatingWebhooks []string
+	// APIServices are the names of the APIServices the CA is injected in
+	APIServices []string
+	// CheckInterval is how often the certificates are checked, an hour if 0
+	CheckInterval time.Duration
+
+	now func() time.Time
+}
+
+// Start checks the certificates every CheckInterval until ctx is done, it implements manager.Runnable
+func (r *Rotator) Start(ctx context.Context) error {
+	interval := r.CheckInterval
+	if interval <= 0 {
+		interval = defaultCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Ensure(ctx); err != nil {
+				rotatorLog.Error(err, "Error checking the certificates, they are checked again later", "secret", r.SecretName)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica writes the certificates it serves
+func (r *Rotator) NeedLeaderElection() bool {
+	return false
+}
+
+// Ensure rotates the certificates of the Secret if needed, writes them to CertDir and injects the CA, it is called
+// once before the servers are started so they find their certificates
+func (r *Rotator) Ensure(ctx context.Context) error {
+	if len(r.DNSNames) == 0 {
+		return fmt.Errorf("the serving certificate needs a DNS name")
+	}
+	if r.Validity <= 0 {
+		return fmt.Errorf("the validity of the certificates must be positive, got %s", r.Validity)
+	}
+
+	var ca, serving keyPair
+	var err error
+	// a conflict means another replica rotated the certificates first, they are read again
+	for attempt := 0; attempt < 3; attempt++ {
+		ca, serving, err = r.rotate(ctx)
+		if !errors.IsConflict(err) && !errors.IsAlreadyExists(err) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.writeFiles(ca, serving); err != nil {
+		return err
+	}
+	return r.injectCA(ctx, ca.cert)
+}
+
+// rotate returns the certificates of the Secret, replacing the ones which are invalid or about to expire
+func (r *Rotator) rotate(ctx context.Context) (keyPair, keyPair, error) {
+	secrets := r.Client.CoreV1().Secrets(r.SecretNamespace)
+	secret, err := secrets.Get(ctx, r.SecretName, metav1.GetOptions{})
+	exists := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return keyPair{}, keyPair{}, fmt.Errorf("error getting the certificates Secret %s/%s: %s", r.SecretNamespace, r.SecretName, err)
+	}
+	if !exists {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: r.SecretName, Namespace: r.SecretNamespace},
+			Type:       corev1.SecretTypeTLS,
+		}
+	}
+
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	ca := keyPair{cert: secret.Data[CACertName], key: secret.Data[caKeyName]}
+	serving := keyPair{cert: secret.Data[CertName], key: secret.Data[KeyName]}
+
+	rotated := false
+	if reason := needsRotation(ca, nil, nil, now); reason != "" {
+		rotatorLog.Info("Rotating the CA", "secret", r.SecretName, "reason", reason)
+		previous := ca.cert
+		if ca, err = newCA(now, caValidityFactor*r.Validity); err != nil {
+			return keyPair{}, keyPair{}, err
+		}
+		ca.cert = withPreviousCA(ca.cert, previous, now)
+		rotated = true
+	}
+	caCert, _, err := parseKeyPair(ca)
+	if err != nil {
+		return keyPair{}, keyPair{}, err
+	}
+	if bundle := withoutPreviousCA(ca.cert, caCert, now, r.Validity); !bytes.Equal(bundle, ca.cert) {
+		rotatorLog.Info("Removing the previous CA", "secret", r.SecretName)
+		ca.cert = bundle
+		rotated = true
+	}
+	if reason := needsRotation(serving, caCert, r.DNSNames, now); reason != "" {
+		rotatorLog.Info("Rotating the serving certificate", "secret", r.SecretName, "reason", reason, "dnsNames", r.DNSNames)
+		if serving, err = newServingCert(ca, r.DNSNames, now, r.Validity); err != nil {
+			return keyPair{}, keyPair{}, err
+		}
+		rotated = true
+	}
+	if !rotated {
+		return ca, serving, nil
+	}
+
+	secret.Data = map[string][]byte{
+		CACertName: ca.cert,
+		caKeyName:  ca.key,
+		CertName:   serving.cert,
+		KeyName:    serving.key,
+	}
+	if exists {
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	} else {
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return keyPair{}, keyPair{}, err
+	}
+	return ca, serving, nil
+}
+
+// writeFiles writes the certificates which changed to CertDir, each file is replaced atomically so the servers
+// watching them never read a partial certificate
+func (r *Rotator) writeFiles(ca, serving keyPair) error {
+	if err := os.MkdirAll(r.CertDir, 0700); err != nil {
+		return fmt.Errorf("error creating the certificate directory: %s", err)
+	}
+	files := []struct {
+		name string
+		data []byte
+		mode os.FileMode
+	}{
+		{CACertName, ca.cert, 0644},
+		{CertName, serving.cert, 0644},
+		{KeyName, serving.key, 0600},
+	}
+	for _, file := range files {
+		path := filepath.Join(r.CertDir, file.name)
+		if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, file.data) {
+			continue
+		}
+		tmp := path + ".tmp"
+		if err := ioutil.WriteFile(tmp, file.data, file.mode); err != nil {
+			return fmt.Errorf("error writing %s: %s", path, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("error writing %s: %s", path, err)
+		}
+	}
+	return nil
+}
+
+// injectCA sets the CA in the caBundle of the webhooks of the webhook configurations and of the APIServices
+func (r *Rotator) injectCA(ctx context.Context, caCert []byte) error {
+	for _, name := range r.MutatingWebhooks {
+		configuration, err := r.Client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			// the webhooks are optional, the CA is injected once they are installed
+			rotatorLog.V(1).Info("No MutatingWebhookConfiguration to inject the CA in", "mutatingWebhookConfiguration", name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error getting the MutatingWebhookConfiguration %s: %s", name, err)
+		}
+		var bundles [][]byte
+		for _, webhook := range configuration.Webhooks {
+			bundles = append(bundles, webhook.ClientConfig.CABundle)
+		}
+		data, err := caBundlePatch(bundles, caCert)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+		if _, err := r.Client.AdmissionregistrationV1().MutatingWebhookConfigurations().Patch(ctx, name, types.JSONPatchType, data, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("error injecting the CA in the MutatingWebhookConfiguration %s: %s", name, err)
+		}
+		rotatorLog.Info("Injected the CA", "mutatingWebhookConfiguration", name)
+	}
+
+	for _, name := range r.ValidatingWebhooks {
+		configuration, err := r.Client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			rotatorLog.V(1).Info("No ValidatingWebhookConfiguration to inject the CA in", "validatingWebhookConfiguration", name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error getting the ValidatingWebhookConfiguration %s: %s", name, err)
+		}
+		var bundles [][]byte
+		for _, webhook := range configuration.Webhooks {
+			bundles = append(bundles, webhook.ClientConfig.CABundle)
+		}
+		data, err := caBundlePatch(bundles, caCert)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+		if _, err := r.Client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Patch(ctx, name, types.JSONPatchType, data, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("error injecting the CA in the ValidatingWebhookConfiguration %s: %s", name, err)
+		}
+		rotatorLog.Info("Injected the CA", "validatingWebhookConfiguration", name)
+	}
+
+	for _, name := range r.APIServices {
+		apiService, err := r.DynamicClient.Resource(apiServiceResource).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			rotatorLog.V(1).Info("No APIService to inject the CA in", "apiService", name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error getting the APIService %s: %s", name, err)
+		}
+		// the caBundle is base64 encoded in the unstructured APIService
+		current, _, _ := unstructured.NestedString(apiService.Object, "spec", "caBundle")
+		if current == base64.StdEncoding.EncodeToString(caCert) {
+			continue
+		}
+		// the CA can't be set while the TLS verification is skipped
+		data, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"caBundle": caCert, "insecureSkipTLSVerify": false}})
+		if err != nil {
+			return err
+		}
+		if _, err := r.DynamicClient.Resource(apiServiceResource).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("error injecting the CA in the APIService %s: %s", name, err)
+		}
+		rotatorLog.Info("Injected the CA", "apiService", name)
+	}
+	return nil
+}
+
+// caBundlePatch returns the JSON patch setting caCert as the caBundle of the webhooks whose bundles differ, nil if
+// they are all up to date
+func caBundlePatch(bundles [][]byte, caCert []byte) ([]byte, error) {
+	patch := make([]map[string]interface{}, 0, len(bundles))
+	for i, bundle := range bundles {
+		if !bytes.Equal(bundle, caCert) {
+			patch = append(patch, map[string]interface{}{"op": "add", "path": fmt.Sprintf("/webhooks/%d/clientConfig/caBundle", i), "value": caCert})
+		}
+	}
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(patch)
+}
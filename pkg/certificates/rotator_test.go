@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestRotator(t *testing.T, now *time.Time) *Rotator {
+	webhooks := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "mutating-webhook-configuration"},
+		Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "mscaledobject.keda.sh"}},
+	}
+	validatingWebhooks := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "validating-webhook-configuration"},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "vscaledjob.keda.sh"}, {Name: "vscaledobject.keda.sh"}},
+	}
+	apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]interface{}{"name": "v1beta1.external.metrics.k8s.io"},
+		"spec":       map[string]interface{}{"insecureSkipTLSVerify": true},
+	}}
+	return &Rotator{
+		Client:             fake.NewSimpleClientset(webhooks, validatingWebhooks),
+		DynamicClient:      dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), apiService),
+		SecretNamespace:    "keda",
+		SecretName:         "kedaorg-certs",
+		CertDir:            t.TempDir(),
+		DNSNames:           ServiceDNSNames("keda", []string{"keda-operator"}),
+		Validity:           90 * 24 * time.Hour,
+		MutatingWebhooks:   []string{"mutating-webhook-configuration", "missing"},
+		ValidatingWebhooks: []string{"validating-webhook-configuration"},
+		APIServices:        []string{"v1beta1.external.metrics.k8s.io"},
+		now:                func() time.Time { return *now },
+	}
+}
+
+func TestRotatorIssuesCertificates(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r := newTestRotator(t, &now)
+	assert.NoError(t, r.Ensure(ctx))
+
+	secret, err := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+	assert.NoError(t, err)
+	for _, name := range []string{CACertName, CertName, KeyName} {
+		data, err := ioutil.ReadFile(filepath.Join(r.CertDir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, secret.Data[name], data, name)
+	}
+
+	webhooks, err := r.Client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, "mutating-webhook-configuration", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, secret.Data[CACertName], webhooks.Webhooks[0].ClientConfig.CABundle)
+	validatingWebhooks, err := r.Client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "validating-webhook-configuration", metav1.GetOptions{})
+	assert.NoError(t, err)
+	for _, webhook := range validatingWebhooks.Webhooks {
+		assert.Equal(t, secret.Data[CACertName], webhook.ClientConfig.CABundle, webhook.Name)
+	}
+	apiService, err := r.DynamicClient.Resource(apiServiceResource).Get(ctx, "v1beta1.external.metrics.k8s.io", metav1.GetOptions{})
+	assert.NoError(t, err)
+	caBundle, _, _ := unstructured.NestedString(apiService.Object, "spec", "caBundle")
+	assert.Equal(t, base64.StdEncoding.EncodeToString(secret.Data[CACertName]), caBundle)
+	insecure, _, _ := unstructured.NestedBool(apiService.Object, "spec", "insecureSkipTLSVerify")
+	assert.False(t, insecure)
+
+	// the certificates are kept while they are valid
+	assert.NoError(t, r.Ensure(ctx))
+	kept, _ := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+	assert.Equal(t, secret.Data, kept.Data)
+}
+
+func TestRotatorRotatesCertificates(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r := newTestRotator(t, &now)
+	assert.NoError(t, r.Ensure(ctx))
+	issued, _ := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+
+	// the serving certificate is rotated once less than a third of its validity remains, the CA is kept
+	now = now.Add(70 * 24 * time.Hour)
+	assert.NoError(t, r.Ensure(ctx))
+	rotated, _ := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+	assert.Equal(t, issued.Data[CACertName], rotated.Data[CACertName])
+	assert.NotEqual(t, issued.Data[CertName], rotated.Data[CertName])
+	served, _ := ioutil.ReadFile(filepath.Join(r.CertDir, CertName))
+	assert.Equal(t, rotated.Data[CertName], served)
+
+	// a change of the DNS names issues a new serving certificate
+	r.DNSNames = ServiceDNSNames("keda", []string{"keda-operator", "keda-metrics-apiserver"})
+	assert.NoError(t, r.Ensure(ctx))
+	renamed, _ := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+	assert.NotEqual(t, rotated.Data[CertName], renamed.Data[CertName])
+
+	// the CA is rotated with the serving certificate and injected again
+	now = now.Add(700 * 24 * time.Hour)
+	assert.NoError(t, r.Ensure(ctx))
+	renewed, _ := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+	assert.NotEqual(t, issued.Data[CACertName], renewed.Data[CACertName])
+	webhooks, _ := r.Client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, "mutating-webhook-configuration", metav1.GetOptions{})
+	assert.Equal(t, renewed.Data[CACertName], webhooks.Webhooks[0].ClientConfig.CABundle)
+
+	// the previous CA is published with the new one, the serving certificates of both are trusted
+	renamedAt := now.Add(-700 * 24 * time.Hour)
+	assert.True(t, bytes.HasSuffix(renewed.Data[CACertName], issued.Data[CACertName]))
+	assert.NoError(t, verifyServingCert(renewed.Data[CACertName], renamed.Data[CertName], renamedAt))
+	assert.NoError(t, verifyServingCert(renewed.Data[CACertName], renewed.Data[CertName], now))
+
+	// and removed once the serving certificates it issued have expired
+	now = now.Add(91 * 24 * time.Hour)
+	assert.NoError(t, r.Ensure(ctx))
+	trimmed, _ := r.Client.CoreV1().Secrets("keda").Get(ctx, "kedaorg-certs", metav1.GetOptions{})
+	assert.Error(t, verifyServingCert(trimmed.Data[CACertName], renamed.Data[CertName], renamedAt))
+	assert.NoError(t, verifyServingCert(trimmed.Data[CACertName], trimmed.Data[CertName], now))
+	served, _ = ioutil.ReadFile(filepath.Join(r.CertDir, CACertName))
+	assert.Equal(t, trimmed.Data[CACertName], served)
+}
+
+// verifyServingCert checks the serving certificate cert is issued by a CA of bundle at now
+func verifyServingCert(bundle, cert []byte, now time.Time) error {
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(bundle)
+	block, _ := pem.Decode(cert)
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	_, err = parsed.Verify(x509.VerifyOptions{Roots: pool, CurrentTime: now})
+	return err
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	now := time.Now()
+	ca, err := newCA(now, time.Hour)
+	assert.NoError(t, err)
+	serving, err := newServingCert(ca, []string{"keda-operator.keda.svc"}, now, time.Hour)
+	assert.NoError(t, err)
+	cert, err := tls.X509KeyPair(serving.cert, serving.key)
+	assert.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), CACertName)
+	_, err = NewClientTLSConfig(caFile)
+	assert.Error(t, err, "the CA file must exist")
+
+	get := func(serverName string) error {
+		config, err := NewClientTLSConfig(caFile)
+		assert.NoError(t, err)
+		config.ServerName = serverName
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	assert.NoError(t, ioutil.WriteFile(caFile, ca.cert, 0600))
+	assert.NoError(t, get("keda-operator.keda.svc"))
+	assert.Error(t, get("keda-metrics-apiserver.keda.svc"))
+
+	// the CA is read again on each handshake
+	other, err := newCA(now, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(caFile, other.cert, 0600))
+	assert.Error(t, get("keda-operator.keda.svc"))
+}
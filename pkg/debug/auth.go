@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// authorizeRequest authenticates the bearer token of the request with a TokenReview and checks
+// with a SubjectAccessReview that its user can get the object, it returns the HTTP status with the error
+func authorizeRequest(ctx context.Context, kubeClient client.Client, r *http.Request, namespace, resource, name string) (int, error) {
+	return reviewRequest(ctx, kubeClient, r, func(spec *authorizationv1.SubjectAccessReviewSpec) string {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      "get",
+			Group:     kedav1alpha1.GroupVersion.Group,
+			Resource:  resource,
+			Name:      name,
+		}
+		return fmt.Sprintf("get %s %s/%s", resource, namespace, name)
+	})
+}
+
+// authorizeListRequest authenticates the bearer token of the request and checks that its user can list
+// the objects of the namespace, or of all the namespaces if namespace is empty
+func authorizeListRequest(ctx context.Context, kubeClient client.Client, r *http.Request, namespace, resource string) (int, error) {
+	return reviewRequest(ctx, kubeClient, r, func(spec *authorizationv1.SubjectAccessReviewSpec) string {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      "list",
+			Group:     kedav1alpha1.GroupVersion.Group,
+			Resource:  resource,
+		}
+		if namespace == "" {
+			return fmt.Sprintf("list %s in all the namespaces", resource)
+		}
+		return fmt.Sprintf("list %s in %s", resource, namespace)
+	})
+}
+
+// authorizeNonResourceRequest authenticates the bearer token of the request and checks that its user
+// can get the path of the request, eg. with a ClusterRole granting `get` on the nonResourceURLs `/debug/pprof/*`
+func authorizeNonResourceRequest(ctx context.Context, kubeClient client.Client, r *http.Request) (int, error) {
+	return reviewRequest(ctx, kubeClient, r, func(spec *authorizationv1.SubjectAccessReviewSpec) string {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: r.URL.Path,
+			Verb: "get",
+		}
+		return fmt.Sprintf("get %s", r.URL.Path)
+	})
+}
+
+// reviewRequest authenticates the bearer token of the request with a TokenReview, then checks the access
+// described by setAttributes with a SubjectAccessReview, setAttributes returns the description of the access
+func reviewRequest(ctx context.Context, kubeClient client.Client, r *http.Request, setAttributes func(*authorizationv1.SubjectAccessReviewSpec) string) (int, error) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" || token == r.Header.Get("Authorization") {
+		return http.StatusUnauthorized, fmt.Errorf("bearer token is missing")
+	}
+
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if err := kubeClient.Create(ctx, tokenReview); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("error reviewing the token: %s", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, fmt.Errorf("invalid token: %s", tokenReview.Status.Error)
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	accessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+		},
+	}
+	access := setAttributes(&accessReview.Spec)
+	if err := kubeClient.Create(ctx, accessReview); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("error reviewing the access: %s", err)
+	}
+	if !accessReview.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("user %s is not allowed to %s", user.Username, access)
+	}
+	return http.StatusOK, nil
+}
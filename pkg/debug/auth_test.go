@@ -0,0 +1,89 @@
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+)
+
+type authorizeTestData struct {
+	name          string
+	header        string
+	authenticated bool
+	allowed       bool
+	status        int
+}
+
+var authorizeTestDataset = []authorizeTestData{
+	{"missing token", "", false, false, http.StatusUnauthorized},
+	{"basic auth", "Basic dXNlcjpwYXNz", false, false, http.StatusUnauthorized},
+	{"invalid token", "Bearer token", false, false, http.StatusUnauthorized},
+	{"forbidden", "Bearer token", true, false, http.StatusForbidden},
+	{"allowed", "Bearer token", true, true, http.StatusOK},
+}
+
+func TestAuthorizeRequest(t *testing.T) {
+	for _, testData := range authorizeTestDataset {
+		ctrl := gomock.NewController(t)
+		kubeClient := mock_client.NewMockClient(ctrl)
+		kubeClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				assert.Equal(t, "token", review.Spec.Token, testData.name)
+				review.Status.Authenticated = testData.authenticated
+				review.Status.User = authenticationv1.UserInfo{Username: "alice", Groups: []string{"dev"}}
+			case *authorizationv1.SubjectAccessReview:
+				assert.Equal(t, "alice", review.Spec.User, testData.name)
+				assert.Equal(t, authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Group: "keda.sh", Resource: "scaledobjects", Name: "app"}, *review.Spec.ResourceAttributes, testData.name)
+				review.Status.Allowed = testData.allowed
+			}
+			return nil
+		}).AnyTimes()
+
+		r := httptest.NewRequest(http.MethodGet, MetricsPathPrefix+"namespaces/default/scaledobjects/app", nil)
+		if testData.header != "" {
+			r.Header.Set("Authorization", testData.header)
+		}
+		status, err := authorizeRequest(context.Background(), kubeClient, r, "default", "scaledobjects", "app")
+		assert.Equal(t, testData.status, status, testData.name)
+		assert.Equal(t, testData.status != http.StatusOK, err != nil, testData.name)
+		ctrl.Finish()
+	}
+}
+
+func TestAuthorizeNonResourceRequest(t *testing.T) {
+	for _, testData := range authorizeTestDataset {
+		ctrl := gomock.NewController(t)
+		kubeClient := mock_client.NewMockClient(ctrl)
+		kubeClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				review.Status.Authenticated = testData.authenticated
+				review.Status.User = authenticationv1.UserInfo{Username: "alice"}
+			case *authorizationv1.SubjectAccessReview:
+				assert.Nil(t, review.Spec.ResourceAttributes, testData.name)
+				assert.Equal(t, authorizationv1.NonResourceAttributes{Path: PprofPathPrefix + "heap", Verb: "get"}, *review.Spec.NonResourceAttributes, testData.name)
+				review.Status.Allowed = testData.allowed
+			}
+			return nil
+		}).AnyTimes()
+
+		r := httptest.NewRequest(http.MethodGet, PprofPathPrefix+"heap", nil)
+		if testData.header != "" {
+			r.Header.Set("Authorization", testData.header)
+		}
+		status, err := authorizeNonResourceRequest(context.Background(), kubeClient, r)
+		assert.Equal(t, testData.status, status, testData.name)
+		assert.Equal(t, testData.status != http.StatusOK, err != nil, testData.name)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// ScalableObjectCheck is the result of checking all the triggers of a ScaledObject or a ScaledJob
+type ScalableObjectCheck struct {
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Triggers  int           `json:"triggers"`
+	Scalers   []ScalerCheck `json:"scalers"`
+	Errors    []string      `json:"errors,omitempty"`
+}
+
+// ScalerCheck is the result of executing a single scaler once
+type ScalerCheck struct {
+	Index    int           `json:"index"`
+	Type     string        `json:"type"`
+	IsActive bool          `json:"isActive"`
+	Metrics  []MetricCheck `json:"metrics,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// MetricCheck contains the current value and the target of a single metric exposed by a scaler
+type MetricCheck struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+	Value  string `json:"value,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// checkScaler executes the passed scaler once and reports its activity, metric values and targets
+func checkScaler(ctx context.Context, index int, scaler scalers.Scaler) ScalerCheck {
+	check := ScalerCheck{
+		Index: index,
+		Type:  strings.TrimPrefix(fmt.Sprintf("%T", scaler), "*scalers."),
+	}
+
+	isActive, err := scaler.IsActive(ctx)
+	if err != nil {
+		check.Error = err.Error()
+	}
+	check.IsActive = isActive
+
+	for _, spec := range scaler.GetMetricSpecForScaling(ctx) {
+		switch {
+		case spec.External != nil:
+			metric := MetricCheck{
+				Name: spec.External.Metric.Name,
+			}
+			switch {
+			case spec.External.Target.AverageValue != nil:
+				metric.Target = spec.External.Target.AverageValue.String() + " (AverageValue)"
+			case spec.External.Target.Value != nil:
+				metric.Target = spec.External.Target.Value.String() + " (Value)"
+			}
+
+			values, err := scaler.GetMetrics(ctx, metric.Name, labels.Everything())
+			if err != nil {
+				metric.Error = err.Error()
+			} else {
+				formatted := make([]string, 0, len(values))
+				for _, value := range values {
+					formatted = append(formatted, value.Value.String())
+				}
+				metric.Value = strings.Join(formatted, ",")
+			}
+			check.Metrics = append(check.Metrics, metric)
+		case spec.Resource != nil:
+			metric := MetricCheck{
+				Name: string(spec.Resource.Name),
+				// resource metrics are served by the Kubernetes metrics server and not by KEDA
+				Value: "n/a",
+			}
+			switch {
+			case spec.Resource.Target.AverageUtilization != nil:
+				metric.Target = fmt.Sprintf("%d%% (Utilization)", *spec.Resource.Target.AverageUtilization)
+			case spec.Resource.Target.AverageValue != nil:
+				metric.Target = spec.Resource.Target.AverageValue.String() + " (AverageValue)"
+			}
+			check.Metrics = append(check.Metrics, metric)
+		case spec.ContainerResource != nil:
+			metric := MetricCheck{
+				Name:  fmt.Sprintf("%s (container %s)", spec.ContainerResource.Name, spec.ContainerResource.Container),
+				Value: "n/a",
+			}
+			switch {
+			case spec.ContainerResource.Target.AverageUtilization != nil:
+				metric.Target = fmt.Sprintf("%d%% (Utilization)", *spec.ContainerResource.Target.AverageUtilization)
+			case spec.ContainerResource.Target.AverageValue != nil:
+				metric.Target = spec.ContainerResource.Target.AverageValue.String() + " (AverageValue)"
+			}
+			check.Metrics = append(check.Metrics, metric)
+		}
+	}
+
+	return check
+}
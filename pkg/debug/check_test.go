@@ -0,0 +1,124 @@
+package debug
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+)
+
+func TestCheckScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	target := resource.NewQuantity(5, resource.DecimalSI)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().IsActive(ctx).Return(true, nil)
+	scaler.EXPECT().GetMetricSpecForScaling(ctx).Return([]v2beta2.MetricSpec{{
+		External: &v2beta2.ExternalMetricSource{
+			Metric: v2beta2.MetricIdentifier{Name: "s0-queue"},
+			Target: v2beta2.MetricTarget{AverageValue: target},
+		},
+	}})
+	scaler.EXPECT().GetMetrics(ctx, "s0-queue", gomock.Any()).Return([]external_metrics.ExternalMetricValue{{
+		MetricName: "s0-queue",
+		Value:      *resource.NewQuantity(12, resource.DecimalSI),
+	}}, nil)
+
+	check := checkScaler(ctx, 0, scaler)
+
+	assert.Equal(t, 0, check.Index)
+	assert.True(t, check.IsActive)
+	assert.Empty(t, check.Error)
+	assert.Equal(t, []MetricCheck{{Name: "s0-queue", Target: "5 (AverageValue)", Value: "12"}}, check.Metrics)
+}
+
+func TestCheckScalerWithErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	target := resource.NewQuantity(5, resource.DecimalSI)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().IsActive(ctx).Return(false, errors.New("connection refused"))
+	scaler.EXPECT().GetMetricSpecForScaling(ctx).Return([]v2beta2.MetricSpec{{
+		External: &v2beta2.ExternalMetricSource{
+			Metric: v2beta2.MetricIdentifier{Name: "s0-queue"},
+			Target: v2beta2.MetricTarget{Value: target},
+		},
+	}})
+	scaler.EXPECT().GetMetrics(ctx, "s0-queue", gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	check := checkScaler(ctx, 1, scaler)
+
+	assert.Equal(t, 1, check.Index)
+	assert.False(t, check.IsActive)
+	assert.Equal(t, "connection refused", check.Error)
+	assert.Equal(t, []MetricCheck{{Name: "s0-queue", Target: "5 (Value)", Error: "connection refused"}}, check.Metrics)
+}
+
+func TestHandleCheckInvalidPath(t *testing.T) {
+	s := NewServer("", nil, nil, 0, nil, false)
+
+	paths := []string{
+		CheckPathPrefix,
+		CheckPathPrefix + "namespaces/default",
+		CheckPathPrefix + "namespaces/default/deployments/name",
+		CheckPathPrefix + "default/scaledobjects/name",
+	}
+	for _, path := range paths {
+		rec := httptest.NewRecorder()
+		s.handleCheck(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleCheck(rec, httptest.NewRequest(http.MethodPost, CheckPathPrefix+"namespaces/default/scaledobjects/name", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandleCheckUnauthorized(t *testing.T) {
+	for _, testData := range authorizeTestDataset {
+		if testData.status == http.StatusOK {
+			continue
+		}
+		ctrl := gomock.NewController(t)
+		kubeClient := mock_client.NewMockClient(ctrl)
+		kubeClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				review.Status.Authenticated = testData.authenticated
+				review.Status.User = authenticationv1.UserInfo{Username: "alice"}
+			case *authorizationv1.SubjectAccessReview:
+				review.Status.Allowed = testData.allowed
+			}
+			return nil
+		}).AnyTimes()
+		// the ScaledObject isn't read before its caller is authorized
+		kubeClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		s := NewServer("", kubeClient, nil, 0, nil, false)
+
+		r := httptest.NewRequest(http.MethodGet, CheckPathPrefix+"namespaces/default/scaledobjects/app", nil)
+		if testData.header != "" {
+			r.Header.Set("Authorization", testData.header)
+		}
+		rec := httptest.NewRecorder()
+		s.handleCheck(rec, r)
+		assert.Equal(t, testData.status, rec.Code, testData.name)
+		ctrl.Finish()
+	}
+}
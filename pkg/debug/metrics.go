@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/activation"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+// ScalableObjectMetrics contains the metric values and the activity of all the triggers of a ScaledObject or a ScaledJob
+type ScalableObjectMetrics struct {
+	Kind      string           `json:"kind"`
+	Namespace string           `json:"namespace"`
+	Name      string           `json:"name"`
+	IsActive  bool             `json:"isActive"`
+	Triggers  []TriggerMetrics `json:"triggers"`
+}
+
+// TriggerMetrics contains the metric values of a single trigger as they are served to the HPA,
+// with the rate, transform and metricType of the trigger applied
+type TriggerMetrics struct {
+	Index    int           `json:"index"`
+	Name     string        `json:"name,omitempty"`
+	Type     string        `json:"type"`
+	IsActive bool          `json:"isActive"`
+	Metrics  []MetricCheck `json:"metrics,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	scalableObject, status, err := s.getScalableObject(r, MetricsPathPrefix, true)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	scalersCache, err := s.metricsHandler.GetScalersCache(r.Context(), scalableObject)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, getMetrics(r.Context(), scalableObject, scalersCache))
+}
+
+// getMetrics uses the cached scalers of the object, so the values are the ones the metrics server serves
+func getMetrics(ctx context.Context, scalableObject client.Object, scalersCache *cache.ScalersCache) *ScalableObjectMetrics {
+	result := &ScalableObjectMetrics{
+		Namespace: scalableObject.GetNamespace(),
+		Name:      scalableObject.GetName(),
+	}
+
+	active := make([]bool, len(scalersCache.Scalers))
+	names := make([]string, len(scalersCache.Scalers))
+	for i, sb := range scalersCache.Scalers {
+		trigger := TriggerMetrics{
+			Index: i,
+			Name:  sb.TriggerName,
+			Type:  strings.TrimPrefix(fmt.Sprintf("%T", sb.Scaler), "*scalers."),
+		}
+
+		isActive, err := sb.Scaler.IsActive(ctx)
+		if err != nil {
+			trigger.Error = err.Error()
+		}
+		trigger.IsActive = isActive
+		active[i], names[i] = isActive, sb.TriggerName
+
+		for _, spec := range scalersCache.GetMetricSpecForScaler(ctx, i) {
+			if spec.External == nil {
+				continue
+			}
+			metric := MetricCheck{Name: spec.External.Metric.Name}
+			switch {
+			case spec.External.Target.AverageValue != nil:
+				metric.Target = spec.External.Target.AverageValue.String() + " (AverageValue)"
+			case spec.External.Target.Value != nil:
+				metric.Target = spec.External.Target.Value.String() + " (Value)"
+			}
+
+			values, err := scalersCache.GetMetricsForScaler(ctx, i, metric.Name, nil)
+			if err != nil {
+				metric.Error = err.Error()
+			} else {
+				formatted := make([]string, 0, len(values))
+				for _, value := range values {
+					formatted = append(formatted, value.Value.String())
+				}
+				metric.Value = strings.Join(formatted, ",")
+			}
+			trigger.Metrics = append(trigger.Metrics, metric)
+		}
+		result.Triggers = append(result.Triggers, trigger)
+	}
+
+	switch obj := scalableObject.(type) {
+	case *kedav1alpha1.ScaledObject:
+		result.Kind = "ScaledObject"
+		strategy, err := activation.Parse(obj.GetActivationStrategy())
+		if err != nil {
+			strategy, _ = activation.Parse(activation.StrategyAny)
+		}
+		result.IsActive = strategy.IsActive(active, names, len(obj.Spec.Triggers))
+	case *kedav1alpha1.ScaledJob:
+		result.Kind = "ScaledJob"
+		for _, a := range active {
+			result.IsActive = result.IsActive || a
+		}
+	}
+	return result
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling"
+)
+
+// CheckPathPrefix is the prefix of the check endpoint,
+// the full path is CheckPathPrefix + "namespaces/<namespace>/<scaledobjects|scaledjobs>/<name>"
+const CheckPathPrefix = "/api/v1/check/"
+
+// MetricsPathPrefix is the prefix of the metrics endpoint,
+// the full path is MetricsPathPrefix + "namespaces/<namespace>/<scaledobjects|scaledjobs>/<name>"
+const MetricsPathPrefix = "/api/v1/metrics/"
+
+// PprofPathPrefix is the prefix of the pprof endpoints of the Go runtime profiles
+const PprofPathPrefix = "/debug/pprof/"
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// Server exposes HTTP endpoints meant for troubleshooting only. Every endpoint authenticates the bearer
+// token of its caller with a TokenReview and checks the permission below with a SubjectAccessReview, see reviewRequest:
+//   - check, executes the scalers of a ScaledObject or ScaledJob once and reports their current state: get the object
+//   - metrics, if metricsHandler is set, the metric values computed by its scalers: get the object
+//   - summary, the health, the replica range and the current metric values of all the ScaledObjects: list the ScaledObjects
+//   - pprof, if profiling is set: get the non resource URL of the profile
+type Server struct {
+	addr              string
+	client            client.Client
+	scheme            *runtime.Scheme
+	globalHTTPTimeout time.Duration
+	metricsHandler    scaling.ScaleHandler
+	profiling         bool
+	logger            logr.Logger
+}
+
+// NewServer creates a new debug Server listening on the passed address, metricsHandler can be nil
+func NewServer(addr string, client client.Client, scheme *runtime.Scheme, globalHTTPTimeout time.Duration, metricsHandler scaling.ScaleHandler, profiling bool) *Server {
+	return &Server{
+		addr:              addr,
+		client:            client,
+		scheme:            scheme,
+		globalHTTPTimeout: globalHTTPTimeout,
+		metricsHandler:    metricsHandler,
+		profiling:         profiling,
+		logger:            logf.Log.WithName("debug_server"),
+	}
+}
+
+// Start serves the debug endpoint until the context is done, it implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(CheckPathPrefix, s.handleCheck)
+	mux.HandleFunc(SummaryPath, s.handleSummary)
+	if s.metricsHandler != nil {
+		mux.HandleFunc(MetricsPathPrefix, s.handleMetrics)
+	}
+	if s.profiling {
+		mux.HandleFunc(PprofPathPrefix, s.authorized(pprof.Index))
+		mux.HandleFunc(PprofPathPrefix+"cmdline", s.authorized(pprof.Cmdline))
+		mux.HandleFunc(PprofPathPrefix+"profile", s.authorized(pprof.Profile))
+		mux.HandleFunc(PprofPathPrefix+"symbol", s.authorized(pprof.Symbol))
+		mux.HandleFunc(PprofPathPrefix+"trace", s.authorized(pprof.Trace))
+	}
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("Starting debug server", "address", s.addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the debug server runs on every replica
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// authorized serves the request with handler once its caller is allowed to get the path of the request
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if status, err := authorizeNonResourceRequest(r.Context(), s.client, r); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	scalableObject, status, err := s.getScalableObject(r, CheckPathPrefix, true)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	result, err := s.check(r.Context(), scalableObject)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, result)
+}
+
+// getScalableObject returns the ScaledObject or ScaledJob addressed by the request path after prefix,
+// the caller has to be allowed to get the object if authorize is true. The HTTP status is returned with the error.
+func (s *Server) getScalableObject(r *http.Request, prefix string, authorize bool) (client.Object, int, error) {
+	ctx := r.Context()
+	// namespaces/<namespace>/<resource>/<name>
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+	if len(parts) != 4 || parts[0] != "namespaces" {
+		return nil, http.StatusNotFound, fmt.Errorf("expected path %snamespaces/<namespace>/<scaledobjects|scaledjobs>/<name>", prefix)
+	}
+	namespace, resource, name := parts[1], parts[2], parts[3]
+
+	var scalableObject client.Object
+	switch resource {
+	case "scaledobjects":
+		scalableObject = &kedav1alpha1.ScaledObject{}
+	case "scaledjobs":
+		scalableObject = &kedav1alpha1.ScaledJob{}
+	default:
+		return nil, http.StatusNotFound, fmt.Errorf("unknown resource %s", resource)
+	}
+
+	if authorize {
+		if status, err := authorizeRequest(ctx, s.client, r, namespace, resource, name); err != nil {
+			return nil, status, err
+		}
+	}
+
+	err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, scalableObject)
+	if errors.IsNotFound(err) {
+		return nil, http.StatusNotFound, err
+	} else if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return scalableObject, http.StatusOK, nil
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error(err, "Failed to write debug response")
+	}
+}
+
+// check builds fresh scalers for the object, so the result doesn't depend on the state of the scale loop
+func (s *Server) check(ctx context.Context, scalableObject client.Object) (*ScalableObjectCheck, error) {
+	result := &ScalableObjectCheck{
+		Namespace: scalableObject.GetNamespace(),
+		Name:      scalableObject.GetName(),
+	}
+	switch obj := scalableObject.(type) {
+	case *kedav1alpha1.ScaledObject:
+		result.Kind = "ScaledObject"
+		result.Triggers = len(obj.Spec.Triggers)
+	case *kedav1alpha1.ScaledJob:
+		result.Kind = "ScaledJob"
+		result.Triggers = len(obj.Spec.Triggers)
+	}
+
+	// the recorder collects errors of triggers which couldn't be built instead of publishing them as Events
+	recorder := record.NewFakeRecorder(result.Triggers)
+	handler := scaling.NewScaleHandler(s.client, nil, s.scheme, s.globalHTTPTimeout, recorder, nil)
+	cache, err := handler.GetScalersCache(ctx, scalableObject)
+	if err != nil {
+		return nil, err
+	}
+	defer cache.Close(ctx)
+
+	for i, scaler := range cache.GetScalers() {
+		result.Scalers = append(result.Scalers, checkScaler(ctx, i, scaler))
+	}
+
+	for len(recorder.Events) > 0 {
+		result.Errors = append(result.Errors, <-recorder.Events)
+	}
+
+	return result, nil
+}
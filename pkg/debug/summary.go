@@ -0,0 +1,247 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// SummaryPath is the path of the summary endpoint, the ScaledObjects of a single namespace are returned
+// with the query parameter namespace=<namespace>
+const SummaryPath = "/api/v1/summary"
+
+const (
+	// HealthHealthy means the ScaledObject is ready and all its triggers answer
+	HealthHealthy = "Healthy"
+	// HealthDegraded means the ScaledObject is ready but some triggers fail or the fallback is used
+	HealthDegraded = "Degraded"
+	// HealthUnhealthy means the ScaledObject isn't ready
+	HealthUnhealthy = "Unhealthy"
+	// HealthUnknown means the ScaledObject wasn't reconciled yet
+	HealthUnknown = "Unknown"
+)
+
+// the defaults of the ScaledObject replica range, as they are applied by the ScaledObject controller
+const (
+	defaultMinReplicaCount int32 = 0
+	defaultMaxReplicaCount int32 = 100
+)
+
+// ScalingSummary contains the scaling state of all the ScaledObjects, it is built from the status of the ScaledObjects
+// and of their HPAs so that developer portals can render it without calling the API server for every object
+type ScalingSummary struct {
+	Total         int                   `json:"total"`
+	Healthy       int                   `json:"healthy"`
+	Active        int                   `json:"active"`
+	Paused        int                   `json:"paused"`
+	ScaledObjects []ScaledObjectSummary `json:"scaledObjects"`
+}
+
+// ScaledObjectSummary contains the health, the replica range and the current metric values of a ScaledObject
+type ScaledObjectSummary struct {
+	Namespace       string          `json:"namespace"`
+	Name            string          `json:"name"`
+	ScaleTargetKind string          `json:"scaleTargetKind,omitempty"`
+	ScaleTargetName string          `json:"scaleTargetName"`
+	Health          string          `json:"health"`
+	Message         string          `json:"message,omitempty"`
+	IsActive        bool            `json:"isActive"`
+	IsPaused        bool            `json:"isPaused"`
+	FailingTriggers []string        `json:"failingTriggers,omitempty"`
+	MinReplicas     int32           `json:"minReplicas"`
+	MaxReplicas     int32           `json:"maxReplicas"`
+	CurrentReplicas *int32          `json:"currentReplicas,omitempty"`
+	DesiredReplicas *int32          `json:"desiredReplicas,omitempty"`
+	LastActiveTime  *metav1.Time    `json:"lastActiveTime,omitempty"`
+	Metrics         []MetricSummary `json:"metrics,omitempty"`
+}
+
+// MetricSummary contains the current value of a metric of the HPA of a ScaledObject
+type MetricSummary struct {
+	Name   string `json:"name"`
+	Target string `json:"target,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace := r.URL.Query().Get("namespace")
+	if status, err := authorizeListRequest(r.Context(), s.client, r, namespace, "scaledobjects"); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	summary, err := getSummary(r.Context(), s.client, namespace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.writeJSON(w, summary)
+}
+
+// getSummary lists the ScaledObjects and the HPAs of the namespace, or of all the namespaces if namespace is empty
+func getSummary(ctx context.Context, kubeClient client.Client, namespace string) (*ScalingSummary, error) {
+	scaledObjects := &kedav1alpha1.ScaledObjectList{}
+	if err := kubeClient.List(ctx, scaledObjects, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	hpas := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+	if err := kubeClient.List(ctx, hpas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	hpasByName := make(map[string]*autoscalingv2beta2.HorizontalPodAutoscaler, len(hpas.Items))
+	for i := range hpas.Items {
+		hpasByName[hpas.Items[i].Namespace+"/"+hpas.Items[i].Name] = &hpas.Items[i]
+	}
+
+	summary := &ScalingSummary{ScaledObjects: []ScaledObjectSummary{}}
+	for i := range scaledObjects.Items {
+		scaledObject := &scaledObjects.Items[i]
+		objectSummary := summarizeScaledObject(scaledObject, hpasByName[scaledObject.GetScaleTargetNamespace()+"/"+scaledObject.GetHPAName()])
+		summary.Total++
+		if objectSummary.Health == HealthHealthy {
+			summary.Healthy++
+		}
+		if objectSummary.IsActive {
+			summary.Active++
+		}
+		if objectSummary.IsPaused {
+			summary.Paused++
+		}
+		summary.ScaledObjects = append(summary.ScaledObjects, objectSummary)
+	}
+	sort.Slice(summary.ScaledObjects, func(i, j int) bool {
+		a, b := summary.ScaledObjects[i], summary.ScaledObjects[j]
+		return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Name < b.Name)
+	})
+	return summary, nil
+}
+
+// summarizeScaledObject returns the summary of the ScaledObject, hpa is nil if the HPA doesn't exist yet
+func summarizeScaledObject(scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) ScaledObjectSummary {
+	summary := ScaledObjectSummary{
+		Namespace:       scaledObject.Namespace,
+		Name:            scaledObject.Name,
+		ScaleTargetKind: scaledObject.Status.ScaleTargetKind,
+		MinReplicas:     defaultMinReplicaCount,
+		MaxReplicas:     defaultMaxReplicaCount,
+		LastActiveTime:  scaledObject.Status.LastActiveTime,
+	}
+	if scaledObject.Spec.ScaleTargetRef != nil {
+		summary.ScaleTargetName = scaledObject.Spec.ScaleTargetRef.Name
+	}
+	if scaledObject.Spec.MinReplicaCount != nil {
+		summary.MinReplicas = *scaledObject.Spec.MinReplicaCount
+	}
+	if scaledObject.Spec.MaxReplicaCount != nil {
+		summary.MaxReplicas = *scaledObject.Spec.MaxReplicaCount
+	}
+
+	for name, health := range scaledObject.Status.Health {
+		if health.Status != kedav1alpha1.HealthStatusHappy {
+			summary.FailingTriggers = append(summary.FailingTriggers, name)
+		}
+	}
+	sort.Strings(summary.FailingTriggers)
+
+	conditions := scaledObject.Status.Conditions
+	ready, active, paused, fallback := conditions.GetReadyCondition(), conditions.GetActiveCondition(), conditions.GetPausedCondition(), conditions.GetFallbackCondition()
+	summary.IsActive, summary.IsPaused = active.IsTrue(), paused.IsTrue()
+	switch {
+	case !conditions.AreInitialized() || ready.IsUnknown():
+		summary.Health = HealthUnknown
+	case ready.IsFalse():
+		summary.Health, summary.Message = HealthUnhealthy, ready.Message
+	case len(summary.FailingTriggers) > 0 || fallback.IsTrue():
+		summary.Health = HealthDegraded
+	default:
+		summary.Health = HealthHealthy
+	}
+
+	if hpa != nil {
+		currentReplicas, desiredReplicas := hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas
+		summary.CurrentReplicas, summary.DesiredReplicas = &currentReplicas, &desiredReplicas
+		summary.Metrics = summarizeHPAMetrics(hpa)
+	}
+	return summary
+}
+
+// summarizeHPAMetrics returns the targets of the HPA with the current values computed by the HPA controller
+func summarizeHPAMetrics(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) []MetricSummary {
+	values := make(map[string]string, len(hpa.Status.CurrentMetrics))
+	for _, current := range hpa.Status.CurrentMetrics {
+		switch {
+		case current.External != nil:
+			values[current.External.Metric.Name] = formatMetricValue(current.External.Current)
+		case current.Resource != nil:
+			values[string(current.Resource.Name)] = formatMetricValue(current.Resource.Current)
+		}
+	}
+
+	var metrics []MetricSummary
+	for _, spec := range hpa.Spec.Metrics {
+		var metric MetricSummary
+		switch {
+		case spec.External != nil:
+			metric = MetricSummary{Name: spec.External.Metric.Name, Target: formatMetricTarget(spec.External.Target)}
+		case spec.Resource != nil:
+			metric = MetricSummary{Name: string(spec.Resource.Name), Target: formatMetricTarget(spec.Resource.Target)}
+		default:
+			continue
+		}
+		metric.Value = values[metric.Name]
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+func formatMetricTarget(target autoscalingv2beta2.MetricTarget) string {
+	switch {
+	case target.AverageValue != nil:
+		return target.AverageValue.String() + " (AverageValue)"
+	case target.Value != nil:
+		return target.Value.String() + " (Value)"
+	case target.AverageUtilization != nil:
+		return fmt.Sprintf("%d%% (Utilization)", *target.AverageUtilization)
+	}
+	return ""
+}
+
+func formatMetricValue(value autoscalingv2beta2.MetricValueStatus) string {
+	switch {
+	case value.AverageValue != nil:
+		return value.AverageValue.String()
+	case value.Value != nil:
+		return value.Value.String()
+	case value.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *value.AverageUtilization)
+	}
+	return ""
+}
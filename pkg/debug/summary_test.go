@@ -0,0 +1,123 @@
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestGetSummary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+	assert.NoError(t, autoscalingv2beta2.AddToScheme(scheme))
+
+	scaledObject := func(namespace, name string, ready, active metav1.ConditionStatus, health map[string]kedav1alpha1.HealthStatus) *kedav1alpha1.ScaledObject {
+		conditions := kedav1alpha1.GetInitializedConditions()
+		conditions.SetReadyCondition(ready, "Reason", "message of "+name)
+		conditions.SetActiveCondition(active, "Reason", "")
+		return &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       kedav1alpha1.ScaledObjectSpec{ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: name}},
+			Status:     kedav1alpha1.ScaledObjectStatus{ScaleTargetKind: "apps/v1.Deployment", Conditions: *conditions, Health: health},
+		}
+	}
+	minReplicas, maxReplicas := int32(1), int32(20)
+	worker := scaledObject("shop", "worker", metav1.ConditionTrue, metav1.ConditionTrue, nil)
+	worker.Spec.MinReplicaCount, worker.Spec.MaxReplicaCount = &minReplicas, &maxReplicas
+	failing := map[string]kedav1alpha1.HealthStatus{
+		"s1-queue": {Status: kedav1alpha1.HealthStatusFailing},
+		"s0-cron":  {Status: kedav1alpha1.HealthStatusHappy},
+	}
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "keda-hpa-worker"},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{Metrics: []autoscalingv2beta2.MetricSpec{{
+			Type: autoscalingv2beta2.ExternalMetricSourceType,
+			External: &autoscalingv2beta2.ExternalMetricSource{
+				Metric: autoscalingv2beta2.MetricIdentifier{Name: "s0-queue"},
+				Target: autoscalingv2beta2.MetricTarget{AverageValue: resource.NewQuantity(5, resource.DecimalSI)},
+			},
+		}}},
+		Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: 3,
+			DesiredReplicas: 4,
+			CurrentMetrics: []autoscalingv2beta2.MetricStatus{{
+				Type: autoscalingv2beta2.ExternalMetricSourceType,
+				External: &autoscalingv2beta2.ExternalMetricStatus{
+					Metric:  autoscalingv2beta2.MetricIdentifier{Name: "s0-queue"},
+					Current: autoscalingv2beta2.MetricValueStatus{AverageValue: resource.NewQuantity(7, resource.DecimalSI)},
+				},
+			}},
+		},
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		worker, hpa,
+		scaledObject("shop", "api", metav1.ConditionTrue, metav1.ConditionFalse, failing),
+		scaledObject("shop", "broken", metav1.ConditionFalse, metav1.ConditionFalse, nil),
+		scaledObject("billing", "invoices", metav1.ConditionUnknown, metav1.ConditionUnknown, nil),
+	).Build()
+
+	summary, err := getSummary(context.Background(), kubeClient, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, summary.Total)
+	assert.Equal(t, 1, summary.Healthy)
+	assert.Equal(t, 1, summary.Active)
+	assert.Equal(t, 0, summary.Paused)
+
+	byName := map[string]ScaledObjectSummary{}
+	for _, s := range summary.ScaledObjects {
+		byName[s.Name] = s
+	}
+	assert.Equal(t, "invoices", summary.ScaledObjects[0].Name)
+
+	current, desired := int32(3), int32(4)
+	assert.Equal(t, ScaledObjectSummary{
+		Namespace:       "shop",
+		Name:            "worker",
+		ScaleTargetKind: "apps/v1.Deployment",
+		ScaleTargetName: "worker",
+		Health:          HealthHealthy,
+		IsActive:        true,
+		MinReplicas:     1,
+		MaxReplicas:     20,
+		CurrentReplicas: &current,
+		DesiredReplicas: &desired,
+		Metrics:         []MetricSummary{{Name: "s0-queue", Target: "5 (AverageValue)", Value: "7"}},
+	}, byName["worker"])
+
+	assert.Equal(t, HealthDegraded, byName["api"].Health)
+	assert.Equal(t, []string{"s1-queue"}, byName["api"].FailingTriggers)
+	assert.Equal(t, int32(0), byName["api"].MinReplicas)
+	assert.Equal(t, int32(100), byName["api"].MaxReplicas)
+	assert.Nil(t, byName["api"].CurrentReplicas)
+	assert.Equal(t, HealthUnhealthy, byName["broken"].Health)
+	assert.Equal(t, "message of broken", byName["broken"].Message)
+	assert.Equal(t, HealthUnknown, byName["invoices"].Health)
+
+	summary, err = getSummary(context.Background(), kubeClient, "billing")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Total)
+}
+
+func TestHandleSummaryUnauthenticated(t *testing.T) {
+	s := NewServer("", nil, nil, 0, nil, false)
+
+	rec := httptest.NewRecorder()
+	s.handleSummary(rec, httptest.NewRequest(http.MethodGet, SummaryPath, nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.handleSummary(rec, httptest.NewRequest(http.MethodPost, SummaryPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventemitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsSource      = "/keda"
+	cloudEventsTypePrefix  = "sh.keda."
+	cloudEventsContentType = "application/cloudevents+json"
+
+	// size of the buffer between the recorder and the sink, events are dropped when it is full
+	cloudEventsQueueSize = 1024
+)
+
+// CloudEvent is the structured JSON representation of a CloudEvent (v1.0) sent to the sink
+type CloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Subject         string         `json:"subject,omitempty"`
+	Time            time.Time      `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            CloudEventData `json:"data"`
+}
+
+// CloudEventData is the payload of every CloudEvent emitted by KEDA
+type CloudEventData struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	EventType string `json:"eventType"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// CloudEventsRecorder is a record.EventRecorder which, in addition to recording the Kubernetes Event
+// through the wrapped recorder, emits it as a CloudEvent to the configured HTTP sink
+type CloudEventsRecorder struct {
+	record.EventRecorder
+
+	sinkURL    string
+	httpClient *http.Client
+	queue      chan CloudEvent
+	logger     logr.Logger
+}
+
+// NewCloudEventsRecorder creates a CloudEventsRecorder wrapping the passed recorder.
+// Events are sent asynchronously once the returned recorder is started.
+func NewCloudEventsRecorder(recorder record.EventRecorder, sinkURL string, timeout time.Duration) *CloudEventsRecorder {
+	return &CloudEventsRecorder{
+		EventRecorder: recorder,
+		sinkURL:       sinkURL,
+		httpClient:    kedautil.CreateHTTPClient(timeout, false),
+		queue:         make(chan CloudEvent, cloudEventsQueueSize),
+		logger:        logf.Log.WithName("cloudevents"),
+	}
+}
+
+// Event records the Event and emits the matching CloudEvent
+func (r *CloudEventsRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message)
+	r.enqueue(object, eventtype, reason, message)
+}
+
+// Eventf is just like Event, but with Sprintf for the message field
+func (r *CloudEventsRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	r.enqueue(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf is just like Eventf, but with annotations attached
+func (r *CloudEventsRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	r.enqueue(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Start sends the queued CloudEvents to the sink until the context is done, it implements manager.Runnable
+func (r *CloudEventsRecorder) Start(ctx context.Context) error {
+	r.logger.Info("Starting CloudEvents emitter", "sink", r.sinkURL)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-r.queue:
+			if err := r.send(ctx, event); err != nil {
+				r.logger.Error(err, "Failed to send CloudEvent", "type", event.Type, "subject", event.Subject)
+			}
+		}
+	}
+}
+
+func (r *CloudEventsRecorder) enqueue(object runtime.Object, eventtype, reason, message string) {
+	event := newCloudEvent(object, eventtype, reason, message)
+	select {
+	case r.queue <- event:
+	default:
+		r.logger.V(1).Info("CloudEvents queue is full, dropping event", "type", event.Type, "subject", event.Subject)
+	}
+}
+
+func (r *CloudEventsRecorder) send(ctx context.Context, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.sinkURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", cloudEventsContentType)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("CloudEvents sink returned unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newCloudEvent(object runtime.Object, eventtype, reason, message string) CloudEvent {
+	data := CloudEventData{
+		Kind:      getKind(object),
+		EventType: eventtype,
+		Reason:    reason,
+		Message:   message,
+	}
+	if accessor, err := meta.Accessor(object); err == nil {
+		data.Namespace = accessor.GetNamespace()
+		data.Name = accessor.GetName()
+	}
+
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              string(uuid.NewUUID()),
+		Source:          cloudEventsSource,
+		Type:            cloudEventsTypePrefix + reason,
+		Subject:         fmt.Sprintf("/namespaces/%s/%s/%s", data.Namespace, data.Kind, data.Name),
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// getKind returns the Kind of the object, objects returned from the client cache don't always have TypeMeta set
+func getKind(object runtime.Object) string {
+	switch object.(type) {
+	case *kedav1alpha1.ScaledObject:
+		return "ScaledObject"
+	case *kedav1alpha1.ScaledJob:
+		return "ScaledJob"
+	case *kedav1alpha1.TriggerAuthentication:
+		return "TriggerAuthentication"
+	case *kedav1alpha1.ClusterTriggerAuthentication:
+		return "ClusterTriggerAuthentication"
+	default:
+		return object.GetObjectKind().GroupVersionKind().Kind
+	}
+}
@@ -0,0 +1,81 @@
+package eventemitter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+func TestCloudEventsRecorderEmitsEvent(t *testing.T) {
+	received := make(chan CloudEvent, 1)
+	contentTypes := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event CloudEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		contentTypes <- r.Header.Get("Content-Type")
+		received <- event
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	fakeRecorder := record.NewFakeRecorder(1)
+	recorder := NewCloudEventsRecorder(fakeRecorder, server.URL, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = recorder.Start(ctx)
+	}()
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"},
+	}
+	recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetActivated, "Scaled from %d to %d", 0, 1)
+
+	assert.Equal(t, "Normal KEDAScaleTargetActivated Scaled from 0 to 1", <-fakeRecorder.Events)
+
+	select {
+	case event := <-received:
+		assert.Equal(t, cloudEventsContentType, <-contentTypes)
+		assert.Equal(t, cloudEventsSpecVersion, event.SpecVersion)
+		assert.Equal(t, "sh.keda.KEDAScaleTargetActivated", event.Type)
+		assert.Equal(t, "/namespaces/namespace/ScaledObject/name", event.Subject)
+		assert.NotEmpty(t, event.ID)
+		assert.Equal(t, CloudEventData{
+			Kind:      "ScaledObject",
+			Namespace: "namespace",
+			Name:      "name",
+			EventType: corev1.EventTypeNormal,
+			Reason:    eventreason.KEDAScaleTargetActivated,
+			Message:   "Scaled from 0 to 1",
+		}, event.Data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("CloudEvent was not received by the sink")
+	}
+}
+
+func TestCloudEventsRecorderSendFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	recorder := NewCloudEventsRecorder(record.NewFakeRecorder(1), server.URL, time.Second)
+	event := newCloudEvent(&kedav1alpha1.ScaledJob{}, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "failed")
+
+	assert.Error(t, recorder.send(context.Background(), event))
+}
@@ -56,9 +56,45 @@ const (
 	// KEDAScaleTargetDeactivationFailed is for event when the deactivation of the scale target for ScaledObject fails
 	KEDAScaleTargetDeactivationFailed = "KEDAScaleTargetDeactivationFailed"
 
+	// KEDAScaleTargetFallback is for event when the scale target of ScaledObject was scaled to the fallback replicas
+	KEDAScaleTargetFallback = "KEDAScaleTargetFallback"
+
+	// KEDAScaleTargetDryRun is for event when the desired replica count of a ScaledObject in dry-run mode changed
+	KEDAScaleTargetDryRun = "KEDAScaleTargetDryRun"
+
+	// KEDAScalingHookFailed is for event when a scaling hook of ScaledObject fails
+	KEDAScalingHookFailed = "KEDAScalingHookFailed"
+
+	// KEDABudgetClamped is for event when the budget of ScaledObject starts capping the replicas of the scale target
+	KEDABudgetClamped = "KEDABudgetClamped"
+
+	// KEDABudgetReleased is for event when the budget of ScaledObject stops capping the replicas of the scale target
+	KEDABudgetReleased = "KEDABudgetReleased"
+
+	// KEDAShadowCompleted is for event when the shadow triggers of ScaledObject were evaluated for their duration
+	KEDAShadowCompleted = "KEDAShadowCompleted"
+
+	// KEDAPreProvisioningFailed is for event when the pre-provisioning signal of ScaledObject could not be updated
+	KEDAPreProvisioningFailed = "KEDAPreProvisioningFailed"
+
+	// KEDAHPAOwnershipTransferred is for event when an existing HPA was adopted by ScaledObject
+	KEDAHPAOwnershipTransferred = "KEDAHPAOwnershipTransferred"
+
+	// KEDAHPADeleted is for event when the HPA of a ScaledObject was deleted, eg. after the dry-run mode was enabled
+	KEDAHPADeleted = "KEDAHPADeleted"
+
 	// KEDAJobsCreated is for event when jobs for ScaledJob are created
 	KEDAJobsCreated = "KEDAJobsCreated"
 
+	// KEDAOrphanAdopted is for event when an HPA or a Job left behind was adopted by its recreated owner
+	KEDAOrphanAdopted = "KEDAOrphanAdopted"
+
+	// KEDAOrphanDetected is for event when an HPA or a Job was left behind by its owner
+	KEDAOrphanDetected = "KEDAOrphanDetected"
+
+	// KEDAOrphanDeleted is for event when an HPA or a Job left behind by its owner was deleted
+	KEDAOrphanDeleted = "KEDAOrphanDeleted"
+
 	// TriggerAuthenticationDeleted is for event when a TriggerAuthentication is deleted
 	TriggerAuthenticationDeleted = "TriggerAuthenticationDeleted"
 
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RemoteWriteMetricName is the name of the series of the external metric values sent by the RemoteWriter
+const RemoteWriteMetricName = "keda_scaler_metrics_value"
+
+// maxRemoteWriteSamples bounds the samples buffered while the endpoint is unavailable, the oldest are dropped
+const maxRemoteWriteSamples = 10000
+
+type remoteWriteLabel struct {
+	name, value string
+}
+
+type remoteWriteSample struct {
+	labels    []remoteWriteLabel
+	value     float64
+	timestamp time.Time
+}
+
+// RemoteWriter sends the external metric values computed for the HPAs to a Prometheus remote write endpoint, so
+// their history is kept even when the source systems purge their data. The values are buffered and sent in batches.
+type RemoteWriter struct {
+	url             string
+	interval        time.Duration
+	bearerTokenFile string
+	client          *http.Client
+	logger          logr.Logger
+
+	lock    sync.Mutex
+	samples []remoteWriteSample
+	dropped int
+}
+
+// remoteWriter is set at startup, the values aren't sent if it is nil
+var remoteWriter *RemoteWriter
+
+// SetRemoteWriter sets the RemoteWriter the values recorded with RemoteWriteHPAScalerMetric are sent with
+func SetRemoteWriter(w *RemoteWriter) {
+	remoteWriter = w
+}
+
+// NewRemoteWriter creates a RemoteWriter sending the buffered values to url every interval, the requests are
+// authenticated with the bearer token read from bearerTokenFile at each request if it is set
+func NewRemoteWriter(url string, interval time.Duration, bearerTokenFile string) (*RemoteWriter, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid remote write url %s", url)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("remote write interval must be greater than 0")
+	}
+	return &RemoteWriter{
+		url:             url,
+		interval:        interval,
+		bearerTokenFile: bearerTokenFile,
+		client:          &http.Client{Timeout: interval},
+		logger:          logf.Log.WithName("remote_write"),
+	}, nil
+}
+
+// RemoteWriteHPAScalerMetric buffers the external metric value used by the HPA to be sent by the RemoteWriter
+func (metricsServer PrometheusMetricServer) RemoteWriteHPAScalerMetric(namespace string, scaledObject string, scaler string, scalerIndex int, metric string, value float64, timestamp time.Time) {
+	if remoteWriter == nil {
+		return
+	}
+	remoteWriter.append(remoteWriteSample{
+		// the labels of a series must be sorted by name
+		labels: []remoteWriteLabel{
+			{"__name__", RemoteWriteMetricName},
+			{"metric", metric},
+			{"namespace", namespace},
+			{"scaledObject", scaledObject},
+			{"scaler", scaler},
+			{"scalerIndex", strconv.Itoa(scalerIndex)},
+		},
+		value:     value,
+		timestamp: timestamp,
+	})
+}
+
+func (w *RemoteWriter) append(sample remoteWriteSample) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.samples) >= maxRemoteWriteSamples {
+		w.samples = w.samples[1:]
+		w.dropped++
+	}
+	w.samples = append(w.samples, sample)
+}
+
+// Start sends the buffered values every interval until the context is done, it implements manager.Runnable
+func (w *RemoteWriter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.flush(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the values are computed by every replica serving
+// the Metrics Service so each of them sends its own
+func (w *RemoteWriter) NeedLeaderElection() bool {
+	return false
+}
+
+// flush sends the buffered values, they are buffered again if the endpoint can't be reached or fails with a
+// 5xx so they are retried at the next interval
+func (w *RemoteWriter) flush(ctx context.Context) {
+	w.lock.Lock()
+	samples, dropped := w.samples, w.dropped
+	w.samples, w.dropped = nil, 0
+	w.lock.Unlock()
+
+	if dropped > 0 {
+		w.logger.Info("Dropped the oldest values, the remote write endpoint isn't keeping up", "dropped", dropped)
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	retry, err := w.send(ctx, samples)
+	if err == nil {
+		return
+	}
+	w.logger.Error(err, "Failed to send the metric values", "url", w.url, "samples", len(samples), "retry", retry)
+	if retry {
+		w.lock.Lock()
+		w.samples = append(samples, w.samples...)
+		if len(w.samples) > maxRemoteWriteSamples {
+			w.dropped += len(w.samples) - maxRemoteWriteSamples
+			w.samples = w.samples[len(w.samples)-maxRemoteWriteSamples:]
+		}
+		w.lock.Unlock()
+	}
+}
+
+// send posts the samples as a snappy compressed WriteRequest, it returns whether a failed request can be retried
+func (w *RemoteWriter) send(ctx context.Context, samples []remoteWriteSample) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(snappy.Encode(nil, encodeWriteRequest(samples))))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.bearerTokenFile != "" {
+		token, err := ioutil.ReadFile(w.bearerTokenFile)
+		if err != nil {
+			return true, fmt.Errorf("error reading the remote write bearer token: %s", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests,
+		fmt.Errorf("remote write endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// encodeWriteRequest encodes the samples as a prometheus.WriteRequest protobuf message, the samples of the same
+// series are sent in one TimeSeries, see https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+func encodeWriteRequest(samples []remoteWriteSample) []byte {
+	var keys []string
+	series := map[string][]remoteWriteSample{}
+	for _, sample := range samples {
+		var key strings.Builder
+		for _, label := range sample.labels {
+			key.WriteString(label.name + "\xff" + label.value + "\xff")
+		}
+		if _, ok := series[key.String()]; !ok {
+			keys = append(keys, key.String())
+		}
+		series[key.String()] = append(series[key.String()], sample)
+	}
+
+	var request []byte
+	for _, key := range keys {
+		var timeSeries []byte
+		for _, label := range series[key][0].labels {
+			var l []byte
+			l = protowire.AppendTag(l, 1, protowire.BytesType)
+			l = protowire.AppendString(l, label.name)
+			l = protowire.AppendTag(l, 2, protowire.BytesType)
+			l = protowire.AppendString(l, label.value)
+			timeSeries = protowire.AppendTag(timeSeries, 1, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, l)
+		}
+		// the samples of a series must be in timestamp order
+		sort.SliceStable(series[key], func(i, j int) bool { return series[key][i].timestamp.Before(series[key][j].timestamp) })
+		for _, sample := range series[key] {
+			var s []byte
+			s = protowire.AppendTag(s, 1, protowire.Fixed64Type)
+			s = protowire.AppendFixed64(s, math.Float64bits(sample.value))
+			s = protowire.AppendTag(s, 2, protowire.VarintType)
+			s = protowire.AppendVarint(s, uint64(sample.timestamp.UnixNano()/int64(time.Millisecond)))
+			timeSeries = protowire.AppendTag(timeSeries, 2, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, s)
+		}
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, timeSeries)
+	}
+	return request
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodedSeries is a TimeSeries of a decoded WriteRequest
+type decodedSeries struct {
+	labels     map[string]string
+	values     []float64
+	timestamps []int64
+}
+
+// consumeMessages returns the length delimited fields of a message by number
+func consumeMessages(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	fields := map[protowire.Number][][]byte{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		assert.True(t, n > 0)
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			fields[num] = append(fields[num], protowire.AppendFixed64(nil, v))
+			b = b[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			fields[num] = append(fields[num], protowire.AppendVarint(nil, v))
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+	}
+	return fields
+}
+
+func decodeWriteRequest(t *testing.T, b []byte) []decodedSeries {
+	var decoded []decodedSeries
+	for _, ts := range consumeMessages(t, b)[1] {
+		series := decodedSeries{labels: map[string]string{}}
+		fields := consumeMessages(t, ts)
+		for _, l := range fields[1] {
+			label := consumeMessages(t, l)
+			series.labels[string(label[1][0])] = string(label[2][0])
+		}
+		for _, s := range fields[2] {
+			sample := consumeMessages(t, s)
+			v, _ := protowire.ConsumeFixed64(sample[1][0])
+			ts, _ := protowire.ConsumeVarint(sample[2][0])
+			series.values = append(series.values, math.Float64frombits(v))
+			series.timestamps = append(series.timestamps, int64(ts))
+		}
+		decoded = append(decoded, series)
+	}
+	return decoded
+}
+
+func TestRemoteWriteHPAScalerMetric(t *testing.T) {
+	var requests [][]byte
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		body, _ := ioutil.ReadAll(r.Body)
+		decoded, err := snappy.Decode(nil, body)
+		assert.NoError(t, err)
+		requests = append(requests, decoded)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	tokenFile, err := ioutil.TempFile("", "token")
+	assert.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	_, _ = tokenFile.WriteString("secret\n")
+	tokenFile.Close()
+
+	writer, err := NewRemoteWriter(server.URL, time.Minute, tokenFile.Name())
+	assert.NoError(t, err)
+	SetRemoteWriter(writer)
+	defer SetRemoteWriter(nil)
+
+	now := time.Unix(1638000000, 0)
+	var metricsServer PrometheusMetricServer
+	metricsServer.RemoteWriteHPAScalerMetric("shop", "orders", "prometheusScaler", 0, "s0-jobs", 12.5, now)
+	metricsServer.RemoteWriteHPAScalerMetric("shop", "orders", "prometheusScaler", 0, "s0-jobs", 2, now.Add(time.Second))
+	metricsServer.RemoteWriteHPAScalerMetric("shop", "orders", "redisScaler", 1, "s1-redis", 3, now)
+
+	// the values are retried after a 5xx
+	writer.flush(context.Background())
+	status = http.StatusNoContent
+	writer.flush(context.Background())
+	writer.flush(context.Background())
+	assert.Len(t, requests, 2)
+	assert.Equal(t, requests[0], requests[1])
+
+	series := decodeWriteRequest(t, requests[1])
+	assert.Len(t, series, 2)
+	assert.Equal(t, map[string]string{
+		"__name__": RemoteWriteMetricName, "metric": "s0-jobs", "namespace": "shop", "scaledObject": "orders", "scaler": "prometheusScaler", "scalerIndex": "0",
+	}, series[0].labels)
+	assert.Equal(t, []float64{12.5, 2}, series[0].values)
+	assert.Equal(t, []int64{1638000000000, 1638000001000}, series[0].timestamps)
+	assert.Equal(t, []float64{3}, series[1].values)
+
+	// the values rejected with a 4xx are dropped
+	status = http.StatusBadRequest
+	metricsServer.RemoteWriteHPAScalerMetric("shop", "orders", "redisScaler", 1, "s1-redis", 4, now)
+	writer.flush(context.Background())
+	assert.Empty(t, writer.samples)
+}
+
+func TestNewRemoteWriter(t *testing.T) {
+	_, err := NewRemoteWriter("prometheus:9090/api/v1/write", time.Minute, "")
+	assert.Error(t, err)
+	_, err = NewRemoteWriter("http://prometheus:9090/api/v1/write", 0, "")
+	assert.Error(t, err)
+}
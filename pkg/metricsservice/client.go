@@ -0,0 +1,284 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsservice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/certificates"
+	"github.com/kedacore/keda/v2/pkg/metricsservice/api"
+)
+
+const scaledObjectNameLabel = "scaledobject.keda.sh/name"
+
+// backgroundRefreshConcurrency is the number of metrics refreshed in parallel by Run
+const backgroundRefreshConcurrency = 16
+
+// GrpcClient fetches external metrics from the KEDA Operator, the last successfully fetched values are kept
+// for staleTTL and they are served if the operator is unavailable (eg. during a rolling restart).
+// With a refreshInterval the known metrics are served from the local cache and refreshed in the background,
+// so the HPA requests don't wait on the operator and are still served while it fails over.
+type GrpcClient struct {
+	client          api.MetricsServiceClient
+	conn            *grpc.ClientConn
+	timeout         time.Duration
+	staleTTL        time.Duration
+	refreshInterval time.Duration
+
+	lock       sync.RWMutex
+	lastValues map[string]*cachedMetrics
+}
+
+type cachedMetrics struct {
+	values    []external_metrics.ExternalMetricValue
+	fetchedAt time.Time
+
+	// the request of the metrics, to refresh them in the background
+	namespace      string
+	metricSelector labels.Selector
+	metricName     string
+	// requestedAt is the last time the metrics were requested, they are dropped once it is older than staleTTL
+	requestedAt time.Time
+	refreshing  bool
+}
+
+// NewGrpcClient creates a new GrpcClient connected to the KEDA Operator Metrics Service on the address, over TLS
+// verified with the CA of caFile if it isn't empty, a refreshInterval of 0 queries the operator on every request
+func NewGrpcClient(address string, caFile string, timeout time.Duration, staleTTL time.Duration, refreshInterval time.Duration) (*GrpcClient, error) {
+	transport := grpc.WithInsecure()
+	if caFile != "" {
+		tlsConfig, err := certificates.NewClientTLSConfig(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA of KEDA Operator Metrics Service: %s", err)
+		}
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	conn, err := grpc.Dial(address, transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to KEDA Operator Metrics Service %s: %s", address, err)
+	}
+	return newGrpcClient(conn, timeout, staleTTL, refreshInterval), nil
+}
+
+func newGrpcClient(conn *grpc.ClientConn, timeout time.Duration, staleTTL time.Duration, refreshInterval time.Duration) *GrpcClient {
+	return &GrpcClient{
+		client:          api.NewMetricsServiceClient(conn),
+		conn:            conn,
+		timeout:         timeout,
+		staleTTL:        staleTTL,
+		refreshInterval: refreshInterval,
+		lastValues:      map[string]*cachedMetrics{},
+	}
+}
+
+// GetMetrics returns the values of the external metric of the ScaledObject matching the selector in the namespace
+func (c *GrpcClient) GetMetrics(ctx context.Context, namespace string, metricSelector labels.Selector, metricName string) ([]external_metrics.ExternalMetricValue, error) {
+	key := fmt.Sprintf("%s/%s/%s", namespace, metricSelector.String(), metricName)
+
+	if c.refreshInterval > 0 {
+		now := time.Now()
+		c.lock.Lock()
+		cached, ok := c.lastValues[key]
+		if ok && now.Sub(cached.fetchedAt) < c.staleTTL {
+			cached.requestedAt = now
+			// the value is served as is, the refresh is only awaited by the next requests
+			if now.Sub(cached.fetchedAt) >= c.refreshInterval && !cached.refreshing {
+				cached.refreshing = true
+				go c.refresh(key, cached)
+			}
+			values := cached.values
+			c.lock.Unlock()
+			return values, nil
+		}
+		c.lock.Unlock()
+	}
+
+	values, err := c.getMetrics(ctx, namespace, metricSelector, metricName)
+	if err != nil {
+		c.lock.RLock()
+		cached, ok := c.lastValues[key]
+		var values []external_metrics.ExternalMetricValue
+		if ok && time.Since(cached.fetchedAt) < c.staleTTL {
+			values = cached.values
+		}
+		c.lock.RUnlock()
+		if values != nil {
+			log.V(1).Info("KEDA Operator Metrics Service unavailable, serving last known metric values", "namespace", namespace, "metricName", metricName, "error", err)
+			return values, nil
+		}
+		return nil, err
+	}
+
+	// without staleTTL the values are never served from the cache
+	if c.staleTTL <= 0 {
+		return values, nil
+	}
+	now := time.Now()
+	c.lock.Lock()
+	c.lastValues[key] = &cachedMetrics{
+		values:         values,
+		fetchedAt:      now,
+		namespace:      namespace,
+		metricSelector: metricSelector,
+		metricName:     metricName,
+		requestedAt:    now,
+	}
+	c.lock.Unlock()
+	return values, nil
+}
+
+// Run refreshes the cached metrics every refreshInterval until the context is done, the metrics which haven't
+// been requested for staleTTL are dropped. Without refreshInterval it drops the values older than staleTTL every
+// staleTTL, they can't be served anymore, so the values of the metrics which aren't requested anymore (eg. of the
+// deleted ScaledObjects) don't pile up.
+func (c *GrpcClient) Run(ctx context.Context) {
+	interval := c.refreshInterval
+	if interval <= 0 {
+		interval = c.staleTTL
+	}
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if c.refreshInterval > 0 {
+				c.refreshAll(now)
+			} else {
+				c.dropStale(now)
+			}
+		}
+	}
+}
+
+// dropStale drops the cached metrics fetched staleTTL or more before now
+func (c *GrpcClient) dropStale(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, cached := range c.lastValues {
+		if now.Sub(cached.fetchedAt) >= c.staleTTL {
+			delete(c.lastValues, key)
+		}
+	}
+}
+
+// refreshAll refreshes the cached metrics older than refreshInterval and drops the ones which are not requested
+// anymore, it returns once the refreshes are done
+func (c *GrpcClient) refreshAll(now time.Time) {
+	type refresh struct {
+		key    string
+		cached *cachedMetrics
+	}
+	var refreshes []refresh
+	c.lock.Lock()
+	for key, cached := range c.lastValues {
+		if now.Sub(cached.requestedAt) >= c.staleTTL {
+			delete(c.lastValues, key)
+			continue
+		}
+		if now.Sub(cached.fetchedAt) >= c.refreshInterval && !cached.refreshing {
+			cached.refreshing = true
+			refreshes = append(refreshes, refresh{key: key, cached: cached})
+		}
+	}
+	c.lock.Unlock()
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, backgroundRefreshConcurrency)
+	for _, r := range refreshes {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(r refresh) {
+			defer wg.Done()
+			c.refresh(r.key, r.cached)
+			<-semaphore
+		}(r)
+	}
+	wg.Wait()
+}
+
+// refresh fetches the metrics of the cache entry from the operator, the entry keeps its values on errors
+func (c *GrpcClient) refresh(key string, cached *cachedMetrics) {
+	values, err := c.getMetrics(context.Background(), cached.namespace, cached.metricSelector, cached.metricName)
+	if err != nil {
+		log.V(1).Info("Error refreshing metric values, serving last known metric values", "namespace", cached.namespace, "metricName", cached.metricName, "error", err)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cached.refreshing = false
+	if err == nil && c.lastValues[key] == cached {
+		cached.values, cached.fetchedAt = values, time.Now()
+	}
+}
+
+func (c *GrpcClient) getMetrics(ctx context.Context, namespace string, metricSelector labels.Selector, metricName string) ([]external_metrics.ExternalMetricValue, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// the HPA created by KEDA always selects metrics by the name of the ScaledObject
+	var name string
+	if selector, err := labels.ConvertSelectorToLabelsMap(metricSelector.String()); err == nil {
+		name = selector[scaledObjectNameLabel]
+	}
+
+	response, err := c.client.GetMetrics(ctx, &api.ScaledObjectRef{
+		Name:           name,
+		Namespace:      namespace,
+		MetricName:     metricName,
+		MetricSelector: metricSelector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]external_metrics.ExternalMetricValue, 0, len(response.MetricValues))
+	for _, m := range response.MetricValues {
+		quantity, err := resource.ParseQuantity(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q of metric %s: %s", m.Value, m.MetricName, err)
+		}
+		values = append(values, external_metrics.ExternalMetricValue{
+			MetricName: m.MetricName,
+			Value:      quantity,
+			Timestamp:  metav1.NewTime(time.Unix(0, m.Timestamp*1e6)),
+		})
+	}
+	return values, nil
+}
+
+// Close closes the connection to the KEDA Operator
+func (c *GrpcClient) Close() error {
+	return c.conn.Close()
+}
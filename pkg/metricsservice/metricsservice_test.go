@@ -0,0 +1,201 @@
+package metricsservice
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+)
+
+type fakeExternalMetricsProvider struct {
+	err      error
+	selector string
+	calls    int32
+}
+
+func (p *fakeExternalMetricsProvider) GetExternalMetric(ctx context.Context, namespace string, metricSelector labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) {
+	atomic.AddInt32(&p.calls, 1)
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.selector = metricSelector.String()
+	return &external_metrics.ExternalMetricValueList{
+		Items: []external_metrics.ExternalMetricValue{
+			{
+				MetricName: info.Metric,
+				Value:      *resource.NewMilliQuantity(1500, resource.DecimalSI),
+				Timestamp:  metav1.NewTime(time.Unix(1600000000, 0)),
+			},
+		},
+	}, nil
+}
+
+func (p *fakeExternalMetricsProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
+	return nil
+}
+
+func startTestServer(t *testing.T, metricsProvider provider.ExternalMetricsProvider, refreshInterval time.Duration) (*GrpcClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewGrpcServer(metricsProvider, "", "")
+	go func() {
+		_ = server.server.Serve(listener)
+	}()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newGrpcClient(conn, time.Second, time.Minute, refreshInterval), server.server.Stop
+}
+
+func TestGetMetrics(t *testing.T) {
+	metricsProvider := &fakeExternalMetricsProvider{}
+	client, stop := startTestServer(t, metricsProvider, 0)
+	defer stop()
+	defer client.Close()
+
+	selector := labels.SelectorFromSet(labels.Set{scaledObjectNameLabel: "so"})
+	values, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 {
+		t.Fatalf("expected 1 metric value, got %d", len(values))
+	}
+	if values[0].MetricName != "s0-metric" {
+		t.Errorf("expected metric name s0-metric, got %s", values[0].MetricName)
+	}
+	if values[0].Value.MilliValue() != 1500 {
+		t.Errorf("expected value 1500m, got %s", values[0].Value.String())
+	}
+	if !values[0].Timestamp.Time.Equal(time.Unix(1600000000, 0)) {
+		t.Errorf("unexpected timestamp %s", values[0].Timestamp)
+	}
+	if metricsProvider.selector != selector.String() {
+		t.Errorf("expected selector %s, got %s", selector.String(), metricsProvider.selector)
+	}
+}
+
+func TestGetMetricsServesStaleValues(t *testing.T) {
+	metricsProvider := &fakeExternalMetricsProvider{}
+	client, stop := startTestServer(t, metricsProvider, 0)
+	defer stop()
+	defer client.Close()
+
+	selector := labels.SelectorFromSet(labels.Set{scaledObjectNameLabel: "so"})
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err != nil {
+		t.Fatal(err)
+	}
+
+	metricsProvider.err = errors.New("scaler unavailable")
+	values, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric")
+	if err != nil {
+		t.Fatalf("expected last known value, got error %s", err)
+	}
+	if len(values) != 1 || values[0].Value.MilliValue() != 1500 {
+		t.Errorf("expected last known value, got %v", values)
+	}
+
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s1-metric"); err == nil {
+		t.Error("expected error for a metric without known value")
+	}
+
+	client.staleTTL = 0
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err == nil {
+		t.Error("expected error once the last known value is stale")
+	}
+}
+
+func TestGetMetricsDropsStaleValues(t *testing.T) {
+	metricsProvider := &fakeExternalMetricsProvider{}
+	client, stop := startTestServer(t, metricsProvider, 0)
+	defer stop()
+	defer client.Close()
+
+	selector := labels.SelectorFromSet(labels.Set{scaledObjectNameLabel: "so"})
+	key := "default/" + selector.String() + "/s0-metric"
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err != nil {
+		t.Fatal(err)
+	}
+
+	client.dropStale(time.Now())
+	if _, ok := client.lastValues[key]; !ok {
+		t.Fatal("expected the last known value to be kept while it can be served")
+	}
+	client.dropStale(time.Now().Add(2 * time.Minute))
+	if _, ok := client.lastValues[key]; ok {
+		t.Error("expected the last known value to be dropped once it is stale")
+	}
+
+	// the values aren't kept when they can't be served
+	client.staleTTL = 0
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.lastValues) != 0 {
+		t.Errorf("expected no cached values without staleTTL, got %d", len(client.lastValues))
+	}
+}
+
+func TestGetMetricsRefreshesInBackground(t *testing.T) {
+	metricsProvider := &fakeExternalMetricsProvider{}
+	client, stop := startTestServer(t, metricsProvider, 10*time.Second)
+	defer stop()
+	defer client.Close()
+
+	selector := labels.SelectorFromSet(labels.Set{scaledObjectNameLabel: "so"})
+	key := "default/" + selector.String() + "/s0-metric"
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fresh value is served without querying the operator
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&metricsProvider.calls); calls != 1 {
+		t.Fatalf("expected 1 call to the operator, got %d", calls)
+	}
+
+	// an older value is served while it is refreshed in the background
+	client.lock.Lock()
+	client.lastValues[key].fetchedAt = time.Now().Add(-20 * time.Second)
+	client.lock.Unlock()
+	if _, err := client.GetMetrics(context.TODO(), "default", selector, "s0-metric"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool {
+		client.lock.RLock()
+		defer client.lock.RUnlock()
+		return time.Since(client.lastValues[key].fetchedAt) < 10*time.Second
+	}, 5*time.Second, 10*time.Millisecond)
+	if calls := atomic.LoadInt32(&metricsProvider.calls); calls != 2 {
+		t.Errorf("expected 2 calls to the operator, got %d", calls)
+	}
+
+	// the refresh loop refreshes the old values and drops the ones which aren't requested anymore
+	client.lock.Lock()
+	client.lastValues[key].fetchedAt = time.Now().Add(-20 * time.Second)
+	client.lock.Unlock()
+	client.refreshAll(time.Now())
+	if calls := atomic.LoadInt32(&metricsProvider.calls); calls != 3 {
+		t.Errorf("expected 3 calls to the operator, got %d", calls)
+	}
+	client.refreshAll(time.Now().Add(2 * time.Minute))
+	if _, ok := client.lastValues[key]; ok {
+		t.Error("expected the metric to be dropped once it isn't requested anymore")
+	}
+}
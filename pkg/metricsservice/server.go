@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsservice
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/kedacore/keda/v2/pkg/certificates"
+	"github.com/kedacore/keda/v2/pkg/metricsservice/api"
+)
+
+var log = logf.Log.WithName("metricsservice")
+
+// GrpcServer serves the external metrics of the KEDA Operator to the KEDA Metrics Server
+type GrpcServer struct {
+	server   *grpc.Server
+	address  string
+	provider provider.ExternalMetricsProvider
+	// certDir is the directory of the serving certificate, the requests are served without TLS if it is empty
+	certDir     string
+	certWatcher *certwatcher.CertWatcher
+}
+
+// NewGrpcServer creates a new GrpcServer serving the metrics of the passed provider on the address, with the
+// certificates.CertName and certificates.KeyName serving certificate of certDir if it isn't empty. The certificate
+// is reloaded when its files change.
+func NewGrpcServer(metricsProvider provider.ExternalMetricsProvider, address string, certDir string) *GrpcServer {
+	s := &GrpcServer{
+		address:  address,
+		provider: metricsProvider,
+		certDir:  certDir,
+	}
+	var opts []grpc.ServerOption
+	if certDir != "" {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion: tls.VersionTLS12,
+			GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				return s.certWatcher.GetCertificate(hello)
+			},
+		})))
+	}
+	s.server = grpc.NewServer(opts...)
+	api.RegisterMetricsServiceServer(s.server, s)
+	return s
+}
+
+// GetMetrics returns the values of the requested external metric
+func (s *GrpcServer) GetMetrics(ctx context.Context, in *api.ScaledObjectRef) (*api.MetricValueList, error) {
+	selector, err := labels.Parse(in.MetricSelector)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metric selector %q: %s", in.MetricSelector, err)
+	}
+
+	metrics, err := s.provider.GetExternalMetric(ctx, in.Namespace, selector, provider.ExternalMetricInfo{Metric: in.MetricName})
+	if err != nil {
+		log.V(1).Info("Error getting external metric", "scaledObject.Namespace", in.Namespace, "scaledObject.Name", in.Name, "metricName", in.MetricName, "error", err)
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	result := &api.MetricValueList{}
+	for _, m := range metrics.Items {
+		result.MetricValues = append(result.MetricValues, &api.MetricValue{
+			MetricName: m.MetricName,
+			Value:      m.Value.String(),
+			Timestamp:  m.Timestamp.UnixNano() / 1e6,
+		})
+	}
+	return result, nil
+}
+
+// Start serves the gRPC requests until the context is done, it implements manager.Runnable
+func (s *GrpcServer) Start(ctx context.Context) error {
+	if s.certDir != "" {
+		watcher, err := certwatcher.New(filepath.Join(s.certDir, certificates.CertName), filepath.Join(s.certDir, certificates.KeyName))
+		if err != nil {
+			return fmt.Errorf("failed to load the serving certificate of %s: %s", s.certDir, err)
+		}
+		s.certWatcher = watcher
+		go func() {
+			if err := watcher.Start(ctx); err != nil {
+				log.Error(err, "Error watching the serving certificate", "certDir", s.certDir)
+			}
+		}()
+	}
+
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %s", s.address, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("Starting Metrics Service gRPC Server", "address", s.address)
+		errCh <- s.server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		s.server.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica of the operator serves metrics
+func (s *GrpcServer) NeedLeaderElection() bool {
+	return false
+}
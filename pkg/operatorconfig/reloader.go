@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kedacore/keda/v2/pkg/scaling"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	"github.com/kedacore/keda/v2/pkg/scaling/state"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+var reloaderLog = logf.Log.WithName("operator_config")
+
+// Reloader watches the ConfigMap of the operator and applies its Settings on every change, the settings removed
+// from the ConfigMap, or all of them when it is deleted, get back the values of the flags. The scalers are rebuilt
+// on their next check when a setting they are built with changes, the metrics served to the HPAs are kept
+type Reloader struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	// Defaults are the settings given by the flags of the operator
+	Defaults Settings
+	// LogLevel is the level of the logger of the operator, see NewLogLevel
+	LogLevel zap.AtomicLevel
+
+	lock    sync.Mutex
+	current Settings
+}
+
+// NewLogLevel returns the level of the logs set by the flags of opts and makes opts use it, so the level of the
+// logger built from opts can be changed at runtime
+func NewLogLevel(opts *crzap.Options) zap.AtomicLevel {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	switch l := opts.Level.(type) {
+	case zap.AtomicLevel:
+		level = l
+	case zapcore.Level:
+		level.SetLevel(l)
+	case nil:
+		if opts.Development {
+			level.SetLevel(zapcore.DebugLevel)
+		}
+	}
+	opts.Level = level
+	return level
+}
+
+// Start watches the ConfigMap until ctx is done, it implements manager.Runnable
+func (r *Reloader) Start(ctx context.Context) error {
+	r.lock.Lock()
+	r.current = r.Defaults
+	r.lock.Unlock()
+
+	factory := informers.NewSharedInformerFactoryWithOptions(r.Client, 0,
+		informers.WithNamespace(r.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", r.Name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    r.reload,
+		UpdateFunc: func(_, obj interface{}) { r.reload(obj) },
+		DeleteFunc: func(interface{}) {
+			reloaderLog.Info("The operator ConfigMap was deleted, restoring the settings of the flags", "namespace", r.Namespace, "name", r.Name)
+			r.apply(r.Defaults)
+		},
+	})
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the settings are reloaded on every replica
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Reloader) reload(obj interface{}) {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	settings, err := ParseSettings(configMap.Data, r.Defaults)
+	if err != nil {
+		reloaderLog.Error(err, "Invalid operator ConfigMap, keeping the current settings", "namespace", r.Namespace, "name", r.Name)
+		return
+	}
+	r.apply(settings)
+}
+
+// apply sets the settings that changed since the last reload
+func (r *Reloader) apply(settings Settings) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	current := r.current
+
+	if settings.LogLevel != current.LogLevel {
+		r.LogLevel.SetLevel(settings.LogLevel)
+		reloaderLog.Info("Reloaded the log level", "level", settings.LogLevel)
+	}
+	if settings.HTTPTimeout != current.HTTPTimeout {
+		if err := scaling.SetHTTPTimeout(settings.HTTPTimeout); err != nil {
+			reloaderLog.Error(err, "Error reloading the HTTP timeout")
+			settings.HTTPTimeout = current.HTTPTimeout
+		} else {
+			reloaderLog.Info("Reloaded the HTTP timeout", "timeout", settings.HTTPTimeout)
+		}
+	}
+	if !equalTransportOptions(settings.HTTPTransport, current.HTTPTransport) {
+		if err := kedautil.SetHTTPTransportOptions(settings.HTTPTransport); err != nil {
+			reloaderLog.Error(err, "Error reloading the HTTP transport options")
+			settings.HTTPTransport = current.HTTPTransport
+		} else {
+			scaling.RebuildScalers()
+			reloaderLog.Info("Reloaded the HTTP transport options", "maxIdleConns", settings.HTTPTransport.MaxIdleConns, "idleConnTimeout", settings.HTTPTransport.IdleConnTimeout)
+		}
+	}
+	if settings.CredentialsCacheTTL != current.CredentialsCacheTTL {
+		if err := resolver.SetCredentialsTTL(settings.CredentialsCacheTTL); err != nil {
+			reloaderLog.Error(err, "Error reloading the credentials cache TTL")
+			settings.CredentialsCacheTTL = current.CredentialsCacheTTL
+		} else {
+			reloaderLog.Info("Reloaded the credentials cache TTL", "ttl", settings.CredentialsCacheTTL)
+		}
+	}
+	if settings.PollingJitter != current.PollingJitter {
+		if err := scaling.SetPollingJitter(settings.PollingJitter); err != nil {
+			reloaderLog.Error(err, "Error reloading the polling jitter")
+			settings.PollingJitter = current.PollingJitter
+		} else {
+			reloaderLog.Info("Reloaded the polling jitter", "jitter", settings.PollingJitter)
+		}
+	}
+	if settings.StateSyncInterval != current.StateSyncInterval {
+		if err := state.SetSyncInterval(settings.StateSyncInterval); err != nil {
+			reloaderLog.Error(err, "Error reloading the state sync interval")
+			settings.StateSyncInterval = current.StateSyncInterval
+		} else {
+			reloaderLog.Info("Reloaded the state sync interval", "interval", settings.StateSyncInterval)
+		}
+	}
+	r.current = settings
+}
+
+func equalTransportOptions(a, b kedautil.HTTPTransportOptions) bool {
+	if a.MaxIdleConns != b.MaxIdleConns || a.IdleConnTimeout != b.IdleConnTimeout {
+		return false
+	}
+	if a.HTTP2 == nil || b.HTTP2 == nil {
+		return a.HTTP2 == b.HTTP2
+	}
+	return *a.HTTP2 == *b.HTTP2
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package operatorconfig reloads the settings of the operator from a ConfigMap at runtime, eg. the log level or the
+// global HTTP timeout of the scalers, so tuning them doesn't need a restart of the operator.
+package operatorconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// the keys of the settings in the ConfigMap, the settings not given keep the values of the flags of the operator
+const (
+	keyLogLevel            = "logLevel"
+	keyHTTPTimeout         = "httpTimeout"
+	keyCredentialsCacheTTL = "credentialsCacheTTL"
+	keyPollingJitter       = "pollingJitter"
+	keyStateSyncInterval   = "stateSyncInterval"
+	// the options of the HTTP transports use the keys of the trigger metadata, see kedautil.ParseHTTPTransportOptions
+)
+
+// Settings are the settings of the operator that can be changed at runtime
+type Settings struct {
+	// LogLevel is the level of the logs, like --zap-log-level
+	LogLevel zapcore.Level
+	// HTTPTimeout is the global HTTP timeout of the scalers, 0 keeps KEDA_HTTP_DEFAULT_TIMEOUT
+	HTTPTimeout time.Duration
+	// HTTPTransport are the options of the transports of the HTTP clients of the scalers
+	HTTPTransport kedautil.HTTPTransportOptions
+	// CredentialsCacheTTL is how long the credentials read from the external secret stores are cached
+	CredentialsCacheTTL time.Duration
+	// PollingJitter is the fraction of the pollingInterval the first checks of the scale loops are spread over
+	PollingJitter float64
+	// StateSyncInterval is the minimal interval between two saves of the state of the scalers
+	StateSyncInterval time.Duration
+}
+
+// ParseSettings parses the data of the ConfigMap of the operator, the settings it doesn't give keep their value in
+// defaults
+func ParseSettings(data map[string]string, defaults Settings) (Settings, error) {
+	settings := defaults
+	if val, ok := data[keyLogLevel]; ok && val != "" {
+		level, err := parseLogLevel(val)
+		if err != nil {
+			return defaults, err
+		}
+		settings.LogLevel = level
+	}
+	if val, ok := data[keyHTTPTimeout]; ok && val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil || timeout <= 0 {
+			return defaults, fmt.Errorf("%s must be a positive duration, got %q", keyHTTPTimeout, val)
+		}
+		settings.HTTPTimeout = timeout
+	}
+	if val, ok := data[keyCredentialsCacheTTL]; ok && val != "" {
+		ttl, err := time.ParseDuration(val)
+		if err != nil || ttl < 0 {
+			return defaults, fmt.Errorf("%s must be a duration, got %q", keyCredentialsCacheTTL, val)
+		}
+		settings.CredentialsCacheTTL = ttl
+	}
+	if val, ok := data[keyPollingJitter]; ok && val != "" {
+		jitter, err := strconv.ParseFloat(val, 64)
+		if err != nil || jitter < 0 || jitter > 1 {
+			return defaults, fmt.Errorf("%s must be a number between 0 and 1, got %q", keyPollingJitter, val)
+		}
+		settings.PollingJitter = jitter
+	}
+	if val, ok := data[keyStateSyncInterval]; ok && val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil || interval <= 0 {
+			return defaults, fmt.Errorf("%s must be a positive duration, got %q", keyStateSyncInterval, val)
+		}
+		settings.StateSyncInterval = interval
+	}
+
+	transport, err := kedautil.ParseHTTPTransportOptions(data)
+	if err != nil {
+		return defaults, err
+	}
+	if transport.MaxIdleConns > 0 {
+		settings.HTTPTransport.MaxIdleConns = transport.MaxIdleConns
+	}
+	if transport.IdleConnTimeout > 0 {
+		settings.HTTPTransport.IdleConnTimeout = transport.IdleConnTimeout
+	}
+	if transport.HTTP2 != nil {
+		settings.HTTPTransport.HTTP2 = transport.HTTP2
+	}
+	return settings, nil
+}
+
+// parseLogLevel parses a level like --zap-log-level, debug, info, error or an integer verbosity greater than 0
+func parseLogLevel(val string) (zapcore.Level, error) {
+	switch strings.ToLower(val) {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	}
+	verbosity, err := strconv.Atoi(val)
+	if err != nil || verbosity <= 0 || verbosity > 127 {
+		return 0, fmt.Errorf("%s must be debug, info, error or an integer greater than 0, got %q", keyLogLevel, val)
+	}
+	return zapcore.Level(-verbosity), nil
+}
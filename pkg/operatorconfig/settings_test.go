@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+func TestParseSettings(t *testing.T) {
+	defaults := Settings{LogLevel: zapcore.InfoLevel, PollingJitter: 0.1, StateSyncInterval: time.Minute, HTTPTransport: kedautil.HTTPTransportOptions{MaxIdleConns: 10}}
+
+	settings, err := ParseSettings(map[string]string{}, defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, defaults, settings)
+
+	settings, err = ParseSettings(map[string]string{
+		"logLevel":            "2",
+		"httpTimeout":         "10s",
+		"httpIdleConnTimeout": "90s",
+		"credentialsCacheTTL": "5m",
+		"pollingJitter":       "0.5",
+	}, defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, zapcore.Level(-2), settings.LogLevel)
+	assert.Equal(t, 10*time.Second, settings.HTTPTimeout)
+	assert.Equal(t, 10, settings.HTTPTransport.MaxIdleConns)
+	assert.Equal(t, 90*time.Second, settings.HTTPTransport.IdleConnTimeout)
+	assert.Equal(t, 5*time.Minute, settings.CredentialsCacheTTL)
+	assert.Equal(t, 0.5, settings.PollingJitter)
+	assert.Equal(t, time.Minute, settings.StateSyncInterval)
+
+	for _, data := range []map[string]string{
+		{"logLevel": "verbose"},
+		{"logLevel": "0"},
+		{"httpTimeout": "10"},
+		{"credentialsCacheTTL": "-1m"},
+		{"pollingJitter": "2"},
+		{"stateSyncInterval": "0s"},
+		{"httpMaxIdleConns": "many"},
+	} {
+		_, err := ParseSettings(data, defaults)
+		assert.Error(t, err, "%v", data)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	defer func() { _ = resolver.SetCredentialsCache(0, "") }()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda-operator", Namespace: "keda"},
+		Data:       map[string]string{"logLevel": "debug", "credentialsCacheTTL": "1m"},
+	}
+	client := fake.NewSimpleClientset(configMap)
+	reloader := &Reloader{
+		Client:    client,
+		Namespace: "keda",
+		Name:      "keda-operator",
+		Defaults:  Settings{LogLevel: zapcore.InfoLevel, StateSyncInterval: time.Minute},
+		LogLevel:  zap.NewAtomicLevelAt(zapcore.InfoLevel),
+	}
+	go func() { _ = reloader.Start(ctx) }()
+
+	assert.Eventually(t, func() bool { return reloader.LogLevel.Level() == zapcore.DebugLevel }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, time.Minute, resolver.CredentialsTTL())
+
+	// an invalid ConfigMap keeps the settings
+	configMap.Data = map[string]string{"logLevel": "verbose"}
+	_, err := client.CoreV1().ConfigMaps("keda").Update(ctx, configMap, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, zapcore.DebugLevel, reloader.LogLevel.Level())
+
+	// the deletion restores the flags
+	assert.NoError(t, client.CoreV1().ConfigMaps("keda").Delete(ctx, "keda-operator", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool { return reloader.LogLevel.Level() == zapcore.InfoLevel }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, time.Duration(0), resolver.CredentialsTTL())
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -33,12 +34,23 @@ func isFallbackEnabled(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2bet
 	return scaledObject.Spec.Fallback != nil && metricSpec.External.Target.Type == v2beta2.AverageValueMetricType
 }
 
-func (p *KedaProvider) getMetricsWithFallback(ctx context.Context, metrics []external_metrics.ExternalMetricValue, suppressedError error, metricName string, scaledObject *kedav1alpha1.ScaledObject, metricSpec v2beta2.MetricSpec) ([]external_metrics.ExternalMetricValue, error) {
+// getMetricsWithFallback records the health of the metric and falls back on error, stale metrics are the last
+// values of a trigger that didn't answer within the latency budget
+func (p *KedaProvider) getMetricsWithFallback(ctx context.Context, metrics []external_metrics.ExternalMetricValue, suppressedError error, stale bool, metricName string, scaledObject *kedav1alpha1.ScaledObject, metricSpec v2beta2.MetricSpec) ([]external_metrics.ExternalMetricValue, error) {
 	status := scaledObject.Status.DeepCopy()
 
 	initHealthStatus(status)
 	healthStatus := getHealthStatus(status, metricName)
 
+	if suppressedError == nil && stale {
+		// the trigger is slow, not failing
+		healthStatus.Status = kedav1alpha1.HealthStatusStale
+		status.Health[metricName] = *healthStatus
+
+		p.updateStatus(ctx, scaledObject, status, metricSpec)
+		return metrics, nil
+	}
+
 	if suppressedError == nil {
 		zero := int32(0)
 		healthStatus.NumberOfFailures = &zero
@@ -62,7 +74,16 @@ func (p *KedaProvider) getMetricsWithFallback(ctx context.Context, metrics []ext
 		logger.Info("Failed to validate ScaledObject Spec. Please check that parameters are positive integers")
 		return nil, suppressedError
 	case *healthStatus.NumberOfFailures > scaledObject.Spec.Fallback.FailureThreshold:
-		return doFallback(scaledObject, metricSpec, metricName, suppressedError), nil
+		replicas := scaledObject.Spec.Fallback.Replicas
+		if scaledObject.Spec.Fallback.IsCurrentReplicasIfError() {
+			currentReplicas, err := p.getCurrentReplicas(ctx, scaledObject)
+			if err != nil {
+				logger.Error(err, "Failed to get the current replicas count of the scale target")
+				return nil, suppressedError
+			}
+			replicas = currentReplicas
+		}
+		return doFallback(replicas, metricSpec, metricName, suppressedError), nil
 	default:
 		return nil, suppressedError
 	}
@@ -87,12 +108,11 @@ func validateFallback(scaledObject *kedav1alpha1.ScaledObject) bool {
 		scaledObject.Spec.Fallback.Replicas >= 0
 }
 
-func doFallback(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2beta2.MetricSpec, metricName string, suppressedError error) []external_metrics.ExternalMetricValue {
-	replicas := int64(scaledObject.Spec.Fallback.Replicas)
+func doFallback(replicas int32, metricSpec v2beta2.MetricSpec, metricName string, suppressedError error) []external_metrics.ExternalMetricValue {
 	normalisationValue, _ := metricSpec.External.Target.AverageValue.AsInt64()
 	metric := external_metrics.ExternalMetricValue{
 		MetricName: metricName,
-		Value:      *resource.NewQuantity(normalisationValue*replicas, resource.DecimalSI),
+		Value:      *resource.NewQuantity(normalisationValue*int64(replicas), resource.DecimalSI),
 		Timestamp:  metav1.Now(),
 	}
 	fallbackMetrics := []external_metrics.ExternalMetricValue{metric}
@@ -101,6 +121,29 @@ func doFallback(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2beta2.Metr
 	return fallbackMetrics
 }
 
+// getCurrentReplicas reads spec.replicas of the scale target, the scale target kind is resolved by the controller
+func (p *KedaProvider) getCurrentReplicas(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (int32, error) {
+	gvkr := scaledObject.Status.ScaleTargetGVKR
+	if gvkr == nil {
+		return 0, fmt.Errorf("scale target of ScaledObject %s/%s is not resolved yet", scaledObject.Namespace, scaledObject.Name)
+	}
+
+	target := &unstructured.Unstructured{}
+	target.SetGroupVersionKind(gvkr.GroupVersionKind())
+	if err := p.client.Get(ctx, runtimeclient.ObjectKey{Namespace: scaledObject.GetScaleTargetNamespace(), Name: scaledObject.Spec.ScaleTargetRef.Name}, target); err != nil {
+		return 0, err
+	}
+	replicas, found, err := unstructured.NestedInt64(target.Object, "spec", "replicas")
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		// the API server defaults a missing replicas count to 1
+		return 1, nil
+	}
+	return int32(replicas), nil
+}
+
 func (p *KedaProvider) updateStatus(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, status *kedav1alpha1.ScaledObjectStatus, metricSpec v2beta2.MetricSpec) {
 	patch := runtimeclient.MergeFrom(scaledObject.DeepCopy())
 
@@ -30,7 +30,9 @@ import (
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/metrics/pkg/apis/external_metrics"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
 
@@ -88,7 +90,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ToNot(HaveOccurred())
 		value, _ := metrics[0].Value.AsInt64()
@@ -119,7 +121,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ToNot(HaveOccurred())
 		value, _ := metrics[0].Value.AsInt64()
@@ -127,6 +129,38 @@ var _ = Describe("fallback", func() {
 		Expect(so.Status.Health[metricName]).To(haveFailureAndStatus(0, kedav1alpha1.HealthStatusHappy))
 	})
 
+	It("should keep the number of failures of stale metrics", func() {
+		expectedMetricValue := int64(6)
+		startingNumberOfFailures := int32(2)
+		primeGetMetrics(scaler, expectedMetricValue)
+
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+			},
+			&kedav1alpha1.ScaledObjectStatus{
+				Health: map[string]kedav1alpha1.HealthStatus{
+					metricName: {
+						NumberOfFailures: &startingNumberOfFailures,
+						Status:           kedav1alpha1.HealthStatusFailing,
+					},
+				},
+			},
+		)
+
+		metricSpec := createMetricSpec(3)
+		expectStatusPatch(ctrl, client)
+
+		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
+		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, true, metricName, so, metricSpec)
+
+		Expect(err).ToNot(HaveOccurred())
+		value, _ := metrics[0].Value.AsInt64()
+		Expect(value).Should(Equal(expectedMetricValue))
+		Expect(so.Status.Health[metricName]).To(haveFailureAndStatus(2, kedav1alpha1.HealthStatusStale))
+	})
+
 	It("should propagate the error when fallback is disabled", func() {
 		scaler.EXPECT().GetMetrics(gomock.Any(), gomock.Eq(metricName), gomock.Any()).Return(nil, errors.New("Some error"))
 
@@ -135,7 +169,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ShouldNot(BeNil())
 		Expect(err.Error()).Should(Equal("Some error"))
@@ -164,7 +198,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ShouldNot(BeNil())
 		Expect(err.Error()).Should(Equal("Some error"))
@@ -194,7 +228,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ToNot(HaveOccurred())
 		value, _ := metrics[0].Value.AsInt64()
@@ -202,6 +236,41 @@ var _ = Describe("fallback", func() {
 		Expect(so.Status.Health[metricName]).To(haveFailureAndStatus(4, kedav1alpha1.HealthStatusFailing))
 	})
 
+	It("should return a metric normalised with the current replicas when behavior is currentReplicasIfError", func() {
+		scaler.EXPECT().GetMetrics(gomock.Any(), gomock.Eq(metricName), gomock.Any()).Return(nil, errors.New("Some error"))
+		startingNumberOfFailures := int32(3)
+		expectedMetricValue := int64(40)
+
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Behavior:         kedav1alpha1.FallbackBehaviorCurrentReplicasIfError,
+			},
+			&kedav1alpha1.ScaledObjectStatus{
+				ScaleTargetGVKR: &kedav1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"},
+				Health: map[string]kedav1alpha1.HealthStatus{
+					metricName: {
+						NumberOfFailures: &startingNumberOfFailures,
+						Status:           kedav1alpha1.HealthStatusFailing,
+					},
+				},
+			},
+		)
+		metricSpec := createMetricSpec(10)
+		expectStatusPatch(ctrl, client)
+		client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ runtimeclient.ObjectKey, obj runtimeclient.Object) error {
+				return unstructured.SetNestedField(obj.(*unstructured.Unstructured).Object, int64(4), "spec", "replicas")
+			})
+
+		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
+		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
+
+		Expect(err).ToNot(HaveOccurred())
+		value, _ := metrics[0].Value.AsInt64()
+		Expect(value).Should(Equal(expectedMetricValue))
+	})
+
 	It("should behave as if fallback is disabled when the metrics spec target type is not average value metric", func() {
 		so := buildScaledObject(
 			&kedav1alpha1.Fallback{
@@ -250,7 +319,7 @@ var _ = Describe("fallback", func() {
 		client.EXPECT().Status().Return(statusWriter)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		metrics, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ToNot(HaveOccurred())
 		value, _ := metrics[0].Value.AsInt64()
@@ -280,7 +349,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 
 		Expect(err).ShouldNot(BeNil())
 		Expect(err.Error()).Should(Equal("Some error"))
@@ -314,7 +383,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 		Expect(err).ToNot(HaveOccurred())
 		condition := so.Status.Conditions.GetFallbackCondition()
 		Expect(condition.IsTrue()).Should(BeTrue())
@@ -348,7 +417,7 @@ var _ = Describe("fallback", func() {
 		expectStatusPatch(ctrl, client)
 
 		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
-		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, metricName, so, metricSpec)
+		_, err = providerUnderTest.getMetricsWithFallback(context.Background(), metrics, err, false, metricName, so, metricSpec)
 		Expect(err).ShouldNot(BeNil())
 		Expect(err.Error()).Should(Equal("Some error"))
 		condition := so.Status.Conditions.GetFallbackCondition()
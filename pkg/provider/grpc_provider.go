@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/metrics/pkg/apis/custom_metrics"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/kedacore/keda/v2/pkg/metricsservice"
+)
+
+// GrpcProvider implements External Metrics Provider, the metric values are fetched from the KEDA Operator.
+// It doesn't keep any state apart of the last known metric values, so multiple replicas can serve the requests.
+type GrpcProvider struct {
+	client           client.Client
+	grpcClient       *metricsservice.GrpcClient
+	watchedNamespace string
+	shardSelector    labels.Selector
+	ctx              context.Context
+}
+
+// NewGrpcProvider returns an instance of GrpcProvider
+func NewGrpcProvider(ctx context.Context, adapterLogger logr.Logger, client client.Client, grpcClient *metricsservice.GrpcClient, watchedNamespace string, shardSelector labels.Selector) provider.MetricsProvider {
+	provider := &GrpcProvider{
+		client:           client,
+		grpcClient:       grpcClient,
+		watchedNamespace: watchedNamespace,
+		shardSelector:    shardSelector,
+		ctx:              ctx,
+	}
+	logger = adapterLogger.WithName("provider")
+	logger.Info("starting", "mode", "grpc")
+	return provider
+}
+
+// GetExternalMetric retrieves metrics from the KEDA Operator
+func (p *GrpcProvider) GetExternalMetric(ctx context.Context, namespace string, metricSelector labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) {
+	logger.V(1).Info("KEDA provider received request for external metrics", "namespace", namespace, "metric name", info.Metric, "metricSelector", metricSelector.String())
+	metrics, err := p.grpcClient.GetMetrics(ctx, namespace, metricSelector, info.Metric)
+	if err != nil {
+		logger.Error(err, "error getting metric from KEDA Operator", "namespace", namespace, "metric name", info.Metric)
+		return nil, err
+	}
+
+	return &external_metrics.ExternalMetricValueList{
+		Items: metrics,
+	}, nil
+}
+
+// ListAllExternalMetrics returns the supported external metrics for this provider
+func (p *GrpcProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
+	return listExternalMetrics(p.ctx, p.client, p.watchedNamespace, p.shardSelector)
+}
+
+// GetMetricByName fetches a particular metric for a particular object.
+func (p *GrpcProvider) GetMetricByName(ctx context.Context, name types.NamespacedName, info provider.CustomMetricInfo, metricSelector labels.Selector) (*custom_metrics.MetricValue, error) {
+	// not implemented yet
+	return nil, apiErrors.NewServiceUnavailable("not implemented yet")
+}
+
+// GetMetricBySelector fetches a particular metric for a set of objects matching the given label selector.
+func (p *GrpcProvider) GetMetricBySelector(ctx context.Context, namespace string, selector labels.Selector, info provider.CustomMetricInfo, metricSelector labels.Selector) (*custom_metrics.MetricValueList, error) {
+	// not implemented yet
+	return nil, apiErrors.NewServiceUnavailable("not implemented yet")
+}
+
+// ListAllMetrics provides a list of all available custom metrics.
+func (p *GrpcProvider) ListAllMetrics() []provider.CustomMetricInfo {
+	// not implemented yet
+	return []provider.CustomMetricInfo{}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+// budgetedQueryTimeout bounds the queries that outlive the request of the HPA
+const budgetedQueryTimeout = time.Minute
+
+// latencyBudget serves the last value of a metric when its query takes longer than the budget of the ScaledObject,
+// the query keeps running to refresh the value for the next requests
+type latencyBudget struct {
+	lock     sync.Mutex
+	values   map[string][]external_metrics.ExternalMetricValue
+	inFlight map[string]*budgetedQuery
+}
+
+type budgetedQuery struct {
+	done    chan struct{}
+	metrics []external_metrics.ExternalMetricValue
+	err     error
+}
+
+func newLatencyBudget() *latencyBudget {
+	return &latencyBudget{
+		values:   map[string][]external_metrics.ExternalMetricValue{},
+		inFlight: map[string]*budgetedQuery{},
+	}
+}
+
+// get returns the metrics of the query with the key, stale is true when they are the last value because the query
+// didn't answer within the budget, the query runs with baseCtx as it can outlive ctx
+func (b *latencyBudget) get(ctx, baseCtx context.Context, key string, budget time.Duration, query func(context.Context) ([]external_metrics.ExternalMetricValue, error)) ([]external_metrics.ExternalMetricValue, bool, error) {
+	b.lock.Lock()
+	call, ok := b.inFlight[key]
+	if !ok {
+		// a slow query isn't started again while it is running
+		call = &budgetedQuery{done: make(chan struct{})}
+		b.inFlight[key] = call
+		go b.run(baseCtx, key, call, query)
+	}
+	b.lock.Unlock()
+
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+	select {
+	case <-call.done:
+		return call.metrics, false, call.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	case <-timer.C:
+	}
+
+	b.lock.Lock()
+	last, ok := b.values[key]
+	b.lock.Unlock()
+	if ok {
+		return append([]external_metrics.ExternalMetricValue{}, last...), true, nil
+	}
+
+	// there is no value to degrade to yet
+	select {
+	case <-call.done:
+		return call.metrics, false, call.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+func (b *latencyBudget) run(baseCtx context.Context, key string, call *budgetedQuery, query func(context.Context) ([]external_metrics.ExternalMetricValue, error)) {
+	ctx, cancel := context.WithTimeout(baseCtx, budgetedQueryTimeout)
+	defer cancel()
+	call.metrics, call.err = query(ctx)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if call.err == nil {
+		b.values[key] = append([]external_metrics.ExternalMetricValue{}, call.metrics...)
+	}
+	delete(b.inFlight, key)
+	close(call.done)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+func TestLatencyBudget(t *testing.T) {
+	budget := newLatencyBudget()
+	ctx := context.Background()
+	release := make(chan struct{})
+	value := func(name string) []external_metrics.ExternalMetricValue {
+		return []external_metrics.ExternalMetricValue{{MetricName: name}}
+	}
+
+	// without a last value the slow query is waited for
+	metrics, stale, err := budget.get(ctx, ctx, "key", time.Millisecond, func(context.Context) ([]external_metrics.ExternalMetricValue, error) {
+		time.Sleep(20 * time.Millisecond)
+		return value("first"), nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, value("first"), metrics)
+
+	// the last value is returned while the slow query runs
+	metrics, stale, err = budget.get(ctx, ctx, "key", time.Millisecond, func(context.Context) ([]external_metrics.ExternalMetricValue, error) {
+		<-release
+		return value("second"), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, value("first"), metrics)
+
+	// the running query isn't started again
+	metrics, stale, err = budget.get(ctx, ctx, "key", time.Millisecond, func(context.Context) ([]external_metrics.ExternalMetricValue, error) {
+		t.Error("the query shouldn't run")
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, value("first"), metrics)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		budget.lock.Lock()
+		defer budget.lock.Unlock()
+		return len(budget.inFlight) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, value("second"), budget.values["key"])
+
+	metrics, stale, err = budget.get(ctx, ctx, "key", time.Second, func(context.Context) ([]external_metrics.ExternalMetricValue, error) {
+		return value("third"), nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, value("third"), metrics)
+
+	// errors within the budget are returned and don't replace the last value
+	_, stale, err = budget.get(ctx, ctx, "key", time.Second, func(context.Context) ([]external_metrics.ExternalMetricValue, error) {
+		return nil, errors.New("backend unavailable")
+	})
+	assert.Error(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, value("third"), budget.values["key"])
+}
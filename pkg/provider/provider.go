@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -33,6 +34,8 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	prommetrics "github.com/kedacore/keda/v2/pkg/metrics"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	scalingcache "github.com/kedacore/keda/v2/pkg/scaling/cache"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 // KedaProvider implements External Metrics Provider
@@ -42,22 +45,33 @@ type KedaProvider struct {
 	externalMetrics  []externalMetric
 	scaleHandler     scaling.ScaleHandler
 	watchedNamespace string
+	shardSelector    labels.Selector
+	rateLimiters     *rateLimiters
+	latencyBudget    *latencyBudget
 	ctx              context.Context
 }
 
 type externalMetric struct{}
 
+// scaledObjectNameLabel is the label of the metric selectors of the HPAs identifying their ScaledObject,
+// along with it the other labels are passed to the scalers to slice the metric
+const scaledObjectNameLabel = "scaledobject.keda.sh/name"
+
 var logger logr.Logger
 var metricsServer prommetrics.PrometheusMetricServer
 
-// NewProvider returns an instance of KedaProvider
-func NewProvider(ctx context.Context, adapterLogger logr.Logger, scaleHandler scaling.ScaleHandler, client client.Client, watchedNamespace string) provider.MetricsProvider {
+// NewProvider returns an instance of KedaProvider, watchedNamespace can be a comma separated list of namespaces,
+// shardSelector restricts the served ScaledObjects to the ones matching it and rateLimits bounds the metric queries
+func NewProvider(ctx context.Context, adapterLogger logr.Logger, scaleHandler scaling.ScaleHandler, client client.Client, watchedNamespace string, shardSelector labels.Selector, rateLimits RateLimits) provider.MetricsProvider {
 	provider := &KedaProvider{
 		values:           make(map[provider.CustomMetricInfo]int64),
 		externalMetrics:  make([]externalMetric, 2, 10),
 		client:           client,
 		scaleHandler:     scaleHandler,
 		watchedNamespace: watchedNamespace,
+		shardSelector:    shardSelector,
+		rateLimiters:     newRateLimiters(rateLimits),
+		latencyBudget:    newLatencyBudget(),
 		ctx:              ctx,
 	}
 	logger = adapterLogger.WithName("provider")
@@ -81,10 +95,21 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 	}
 
 	// get the scaled objects matching namespace and labels
+	labelSelector, scalerSelector := splitMetricSelector(selector)
+	if p.shardSelector != nil {
+		if requirements, selectable := p.shardSelector.Requirements(); selectable {
+			labelSelector = labelSelector.Add(requirements...)
+		}
+	}
+	// the HPA of a ScaledObject scaling a workload of another namespace is in the namespace of the workload
+	scaledObjectNamespace := namespace
+	if ownerNamespace, ok := selector[kedav1alpha1.ScaledObjectNamespaceLabel]; ok {
+		scaledObjectNamespace = ownerNamespace
+	}
 	scaledObjects := &kedav1alpha1.ScaledObjectList{}
 	opts := []client.ListOption{
-		client.InNamespace(namespace),
-		client.MatchingLabels(selector),
+		client.InNamespace(scaledObjectNamespace),
+		client.MatchingLabelsSelector{Selector: labelSelector},
 	}
 	err = p.client.List(ctx, scaledObjects, opts...)
 	if err != nil {
@@ -94,6 +119,10 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 	}
 
 	scaledObject := &scaledObjects.Items[0]
+	// the metrics are only served to the namespace of the scale target, which consented to the ScaledObject
+	if scaledObject.GetScaleTargetNamespace() != namespace {
+		return nil, fmt.Errorf("ScaledObject %s/%s doesn't scale a workload of namespace %s", scaledObject.Namespace, scaledObject.Name, namespace)
+	}
 	var matchingMetrics []external_metrics.ExternalMetricValue
 	cache, err := p.scaleHandler.GetScalersCache(ctx, scaledObject)
 	if err != nil {
@@ -105,8 +134,19 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 		return nil, fmt.Errorf("error when getting scalers %s", err)
 	}
 
+	if info.Metric == kedav1alpha1.ReplicaCalculatorMetricName && scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.ReplicaCalculatorWebhook != nil {
+		metric, err := p.getReplicaCalculatorMetric(ctx, cache, scaledObject, scalerSelector)
+		if err != nil {
+			logger.Error(err, "error getting the replica count of the replica calculator", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+			return nil, err
+		}
+		return &external_metrics.ExternalMetricValueList{
+			Items: []external_metrics.ExternalMetricValue{metric},
+		}, nil
+	}
+
 	for scalerIndex, scaler := range cache.GetScalers() {
-		metricSpecs := scaler.GetMetricSpecForScaling(ctx)
+		metricSpecs := cache.GetMetricSpecForScaler(ctx, scalerIndex)
 		scalerName := strings.Replace(fmt.Sprintf("%T", scaler), "*scalers.", "", 1)
 
 		for _, metricSpec := range metricSpecs {
@@ -116,8 +156,11 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 			}
 			// Filter only the desired metric
 			if strings.EqualFold(metricSpec.External.Metric.Name, info.Metric) {
-				metrics, err := cache.GetMetricsForScaler(ctx, scalerIndex, info.Metric, metricSelector)
-				metrics, err = p.getMetricsWithFallback(ctx, metrics, err, info.Metric, scaledObject, metricSpec)
+				metrics, stale, err := p.getBudgetedMetrics(ctx, cache, scalerIndex, scaledObject, info.Metric, scalerSelector)
+				metrics, err = p.getMetricsWithFallback(ctx, metrics, err, stale, info.Metric, scaledObject, metricSpec)
+				if err == nil {
+					metrics = p.getMetricsWithTolerance(ctx, metrics, scaledObject, metricSpec)
+				}
 
 				if err != nil {
 					logger.Error(err, "error getting metric for scaler", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "scaler", scaler)
@@ -125,6 +168,7 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 					for _, metric := range metrics {
 						metricValue, _ := metric.Value.AsInt64()
 						metricsServer.RecordHPAScalerMetric(namespace, scaledObject.Name, scalerName, scalerIndex, metric.MetricName, metricValue)
+						metricsServer.RemoteWriteHPAScalerMetric(namespace, scaledObject.Name, scalerName, scalerIndex, metric.MetricName, metric.Value.AsApproximateFloat64(), metric.Timestamp.Time)
 					}
 					matchingMetrics = append(matchingMetrics, metrics...)
 				}
@@ -142,25 +186,82 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 	}, nil
 }
 
+// splitMetricSelector returns the selector of the ScaledObject and the one of the scalers, when the metric selector
+// has the scaledObjectNameLabel the ScaledObject is only selected by it and the other labels parameterize the scalers,
+// except the namespace label of the ScaledObjects scaling a workload of another namespace
+func splitMetricSelector(selector labels.Set) (labels.Selector, labels.Selector) {
+	name, ok := selector[scaledObjectNameLabel]
+	if !ok {
+		return labels.SelectorFromSet(selector), labels.Everything()
+	}
+
+	parameters := labels.Set{}
+	for label, value := range selector {
+		if label != scaledObjectNameLabel && label != kedav1alpha1.ScaledObjectNamespaceLabel {
+			parameters[label] = value
+		}
+	}
+	return labels.SelectorFromSet(labels.Set{scaledObjectNameLabel: name}), labels.SelectorFromSet(parameters)
+}
+
+// getBudgetedMetrics queries the metrics of the scaler within the latency budget of the ScaledObject,
+// stale is true when the last value is returned instead
+func (p *KedaProvider) getBudgetedMetrics(ctx context.Context, cache *scalingcache.ScalersCache, scalerIndex int, scaledObject *kedav1alpha1.ScaledObject, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, bool, error) {
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.LatencyBudgetMilliseconds == nil || *scaledObject.Spec.Advanced.LatencyBudgetMilliseconds <= 0 {
+		metrics, err := p.getRateLimitedMetrics(ctx, cache, scalerIndex, scaledObject.Namespace, metricName, metricSelector)
+		return metrics, false, err
+	}
+
+	budget := time.Duration(*scaledObject.Spec.Advanced.LatencyBudgetMilliseconds) * time.Millisecond
+	key := fmt.Sprintf("%s/%s/%s/%s", scaledObject.Namespace, scaledObject.Name, metricName, metricSelector)
+	return p.latencyBudget.get(ctx, p.ctx, key, budget, func(ctx context.Context) ([]external_metrics.ExternalMetricValue, error) {
+		return p.getRateLimitedMetrics(ctx, cache, scalerIndex, scaledObject.Namespace, metricName, metricSelector)
+	})
+}
+
+// getRateLimitedMetrics queries the metrics of the scaler once the rate limits of the namespace and the backend allow it
+func (p *KedaProvider) getRateLimitedMetrics(ctx context.Context, cache *scalingcache.ScalersCache, scalerIndex int, namespace, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if err := p.rateLimiters.wait(ctx, namespace, cache.Scalers[scalerIndex].BackendHost); err != nil {
+		return nil, err
+	}
+	return cache.GetMetricsForScaler(ctx, scalerIndex, metricName, metricSelector)
+}
+
 // ListAllExternalMetrics returns the supported external metrics for this provider
 func (p *KedaProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
+	return listExternalMetrics(p.ctx, p.client, p.watchedNamespace, p.shardSelector)
+}
+
+// listExternalMetrics returns the external metrics of all ScaledObjects in the watched namespace(s)
+func listExternalMetrics(ctx context.Context, kubeClient client.Client, watchedNamespace string, shardSelector labels.Selector) []provider.ExternalMetricInfo {
 	externalMetricsInfo := []provider.ExternalMetricInfo{}
 
-	// get all ScaledObjects in namespace(s) watched by the operator
-	scaledObjects := &kedav1alpha1.ScaledObjectList{}
-	opts := []client.ListOption{
-		client.InNamespace(p.watchedNamespace),
-	}
-	err := p.client.List(p.ctx, scaledObjects, opts...)
-	if err != nil {
-		logger.Error(err, "Cannot get list of ScaledObjects", "WatchedNamespace", p.watchedNamespace)
-		return nil
+	namespaces := kedautil.ParseWatchNamespaces(watchedNamespace)
+	if len(namespaces) == 0 {
+		// all namespaces
+		namespaces = []string{""}
 	}
 
-	// get metrics from all watched ScaledObjects
-	for _, scaledObject := range scaledObjects.Items {
-		for _, metric := range scaledObject.Status.ExternalMetricNames {
-			externalMetricsInfo = append(externalMetricsInfo, provider.ExternalMetricInfo{Metric: metric})
+	// get all ScaledObjects in namespace(s) watched by the operator
+	for _, namespace := range namespaces {
+		scaledObjects := &kedav1alpha1.ScaledObjectList{}
+		opts := []client.ListOption{
+			client.InNamespace(namespace),
+		}
+		if shardSelector != nil {
+			opts = append(opts, client.MatchingLabelsSelector{Selector: shardSelector})
+		}
+		err := kubeClient.List(ctx, scaledObjects, opts...)
+		if err != nil {
+			logger.Error(err, "Cannot get list of ScaledObjects", "WatchedNamespace", watchedNamespace)
+			return nil
+		}
+
+		// get metrics from all watched ScaledObjects
+		for _, scaledObject := range scaledObjects.Items {
+			for _, metric := range scaledObject.Status.ExternalMetricNames {
+				externalMetricsInfo = append(externalMetricsInfo, provider.ExternalMetricInfo{Metric: metric})
+			}
 		}
 	}
 	return externalMetricsInfo
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSplitMetricSelector(t *testing.T) {
+	scaledObjectSelector, scalerSelector := splitMetricSelector(labels.Set{scaledObjectNameLabel: "orders", "queueName": "invoices"})
+	assert.Equal(t, "scaledobject.keda.sh/name=orders", scaledObjectSelector.String())
+	assert.Equal(t, "queueName=invoices", scalerSelector.String())
+
+	scaledObjectSelector, scalerSelector = splitMetricSelector(labels.Set{scaledObjectNameLabel: "orders"})
+	assert.Equal(t, "scaledobject.keda.sh/name=orders", scaledObjectSelector.String())
+	assert.True(t, scalerSelector.Empty())
+
+	// the namespace label of a ScaledObject of another namespace doesn't parameterize the scalers
+	scaledObjectSelector, scalerSelector = splitMetricSelector(labels.Set{scaledObjectNameLabel: "orders", "scaledobject.keda.sh/namespace": "platform"})
+	assert.Equal(t, "scaledobject.keda.sh/name=orders", scaledObjectSelector.String())
+	assert.True(t, scalerSelector.Empty())
+
+	// without the name label all the labels select the ScaledObject
+	scaledObjectSelector, scalerSelector = splitMetricSelector(labels.Set{"app": "orders"})
+	assert.Equal(t, "app=orders", scaledObjectSelector.String())
+	assert.True(t, scalerSelector.Empty())
+}
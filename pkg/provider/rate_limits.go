@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RateLimits bounds the metric queries of the provider per namespace of the ScaledObjects and per backend host
+// of the scalers, so the ScaledObjects of a namespace can't overload a backend shared with other teams.
+// A QPS of 0 disables the limit.
+type RateLimits struct {
+	NamespaceQPS   float32
+	NamespaceBurst int
+	HostQPS        float32
+	HostBurst      int
+}
+
+// rateLimiters holds the token buckets of the namespaces and the hosts, they are created on first use
+type rateLimiters struct {
+	limits RateLimits
+
+	lock       sync.Mutex
+	namespaces map[string]flowcontrol.RateLimiter
+	hosts      map[string]flowcontrol.RateLimiter
+}
+
+func newRateLimiters(limits RateLimits) *rateLimiters {
+	return &rateLimiters{
+		limits:     limits,
+		namespaces: map[string]flowcontrol.RateLimiter{},
+		hosts:      map[string]flowcontrol.RateLimiter{},
+	}
+}
+
+// wait blocks until a query of the namespace to the host is allowed, it fails if the context
+// is done before, an empty host is only limited by the namespace limit
+func (l *rateLimiters) wait(ctx context.Context, namespace, host string) error {
+	if limiter := l.get(l.namespaces, namespace, l.limits.NamespaceQPS, l.limits.NamespaceBurst); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("metric queries of namespace %s are rate limited: %s", namespace, err)
+		}
+	}
+	if host == "" {
+		return nil
+	}
+	if limiter := l.get(l.hosts, host, l.limits.HostQPS, l.limits.HostBurst); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("metric queries of the scaler backend are rate limited: %s", err)
+		}
+	}
+	return nil
+}
+
+func (l *rateLimiters) get(limiters map[string]flowcontrol.RateLimiter, key string, qps float32, burst int) flowcontrol.RateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter, ok := limiters[key]
+	if !ok {
+		limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+		limiters[key] = limiter
+	}
+	return limiter
+}
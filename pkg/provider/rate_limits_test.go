@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitersDisabled(t *testing.T) {
+	limiters := newRateLimiters(RateLimits{})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, limiters.wait(ctx, "team-a", "prometheus:9090"))
+	}
+}
+
+func TestRateLimitersPerNamespace(t *testing.T) {
+	limiters := newRateLimiters(RateLimits{NamespaceQPS: 0.001, NamespaceBurst: 2})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, limiters.wait(ctx, "team-a", ""))
+	assert.NoError(t, limiters.wait(ctx, "team-a", ""))
+	// the other namespaces have their own limit
+	assert.NoError(t, limiters.wait(ctx, "team-b", ""))
+	assert.Error(t, limiters.wait(ctx, "team-a", ""))
+}
+
+func TestRateLimitersPerHost(t *testing.T) {
+	limiters := newRateLimiters(RateLimits{HostQPS: 0.001, HostBurst: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, limiters.wait(ctx, "team-a", "prometheus:9090"))
+	assert.NoError(t, limiters.wait(ctx, "team-b", "elasticsearch:9200"))
+	// triggers not naming their backend aren't limited by host
+	assert.NoError(t, limiters.wait(ctx, "team-b", ""))
+	assert.Error(t, limiters.wait(ctx, "team-b", "prometheus:9090"))
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	scalingcache "github.com/kedacore/keda/v2/pkg/scaling/cache"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// defaultReplicaCalculatorTimeout is the timeout of the requests to a ReplicaCalculatorWebhook
+const defaultReplicaCalculatorTimeout = 10 * time.Second
+
+// getReplicaCalculatorMetric returns the replica count decided by the ReplicaCalculatorWebhook of the ScaledObject
+// from the values of all its triggers, the HPA targets an average value of 1 so it scales to this replica count
+func (p *KedaProvider) getReplicaCalculatorMetric(ctx context.Context, cache *scalingcache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject, scalerSelector labels.Selector) (external_metrics.ExternalMetricValue, error) {
+	webhook := scaledObject.Spec.Advanced.ReplicaCalculatorWebhook
+
+	var triggers []kedav1alpha1.ReplicaCalculatorTrigger
+	for scalerIndex := range cache.GetScalers() {
+		for _, metricSpec := range cache.GetMetricSpecForScaler(ctx, scalerIndex) {
+			if metricSpec.External == nil {
+				continue
+			}
+			trigger := kedav1alpha1.ReplicaCalculatorTrigger{
+				Name:       cache.Scalers[scalerIndex].TriggerName,
+				MetricName: metricSpec.External.Metric.Name,
+				MetricType: metricSpec.External.Target.Type,
+			}
+			switch target := metricSpec.External.Target; {
+			case target.Type == v2beta2.AverageValueMetricType && target.AverageValue != nil:
+				trigger.Target = target.AverageValue.AsApproximateFloat64()
+			case target.Value != nil:
+				trigger.Target = target.Value.AsApproximateFloat64()
+			}
+
+			metricName := metricSpec.External.Metric.Name
+			metrics, stale, err := p.getBudgetedMetrics(ctx, cache, scalerIndex, scaledObject, metricName, scalerSelector)
+			metrics, err = p.getMetricsWithFallback(ctx, metrics, err, stale, metricName, scaledObject, metricSpec)
+			if err != nil {
+				trigger.Error = err.Error()
+			}
+			for _, metric := range metrics {
+				trigger.Value += metric.Value.AsApproximateFloat64()
+			}
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	currentReplicas, err := p.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		return external_metrics.ExternalMetricValue{}, fmt.Errorf("error reading the current replicas: %s", err)
+	}
+	request := kedav1alpha1.ReplicaCalculatorRequest{
+		Namespace:        scaledObject.Namespace,
+		Name:             scaledObject.Name,
+		CurrentReplicas:  currentReplicas,
+		ProposedReplicas: proposeReplicas(triggers, currentReplicas),
+		Triggers:         triggers,
+	}
+
+	replicas, err := callReplicaCalculator(ctx, webhook, request)
+	if err != nil {
+		if webhook.FailurePolicy != kedav1alpha1.ScalingHookIgnore {
+			return external_metrics.ExternalMetricValue{}, fmt.Errorf("error calling the replica calculator webhook: %s", err)
+		}
+		logger.Error(err, "Ignoring the failure of the replica calculator webhook", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+		replicas = request.ProposedReplicas
+	}
+
+	return external_metrics.ExternalMetricValue{
+		MetricName: kedav1alpha1.ReplicaCalculatorMetricName,
+		Value:      *resource.NewQuantity(int64(replicas), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}, nil
+}
+
+// proposeReplicas returns the replica count the HPA would compute from the triggers, the highest replica count
+// required by the triggers which could be read
+func proposeReplicas(triggers []kedav1alpha1.ReplicaCalculatorTrigger, currentReplicas int32) int32 {
+	var proposed int32
+	for _, trigger := range triggers {
+		if trigger.Error != "" || trigger.Target <= 0 {
+			continue
+		}
+		var replicas float64
+		if trigger.MetricType == v2beta2.AverageValueMetricType {
+			replicas = math.Ceil(trigger.Value / trigger.Target)
+		} else {
+			replicas = math.Ceil(float64(currentReplicas) * trigger.Value / trigger.Target)
+		}
+		if int32(replicas) > proposed {
+			proposed = int32(replicas)
+		}
+	}
+	return proposed
+}
+
+// callReplicaCalculator posts the request to the webhook and returns the replica count it decided
+func callReplicaCalculator(ctx context.Context, webhook *kedav1alpha1.ReplicaCalculatorWebhook, request kedav1alpha1.ReplicaCalculatorRequest) (int32, error) {
+	timeout := defaultReplicaCalculatorTimeout
+	if webhook.TimeoutSeconds != nil {
+		timeout = time.Duration(*webhook.TimeoutSeconds) * time.Second
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := kedautil.CreateHTTPClient(timeout, false).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var response kedav1alpha1.ReplicaCalculatorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("error decoding the webhook response: %s", err)
+	}
+	if response.Replicas == nil {
+		return request.ProposedReplicas, nil
+	}
+	if *response.Replicas < 0 {
+		return 0, fmt.Errorf("webhook returned a negative replica count %d", *response.Replicas)
+	}
+	return *response.Replicas, nil
+}
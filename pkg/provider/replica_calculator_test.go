@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestProposeReplicas(t *testing.T) {
+	averageValue := func(value, target float64) kedav1alpha1.ReplicaCalculatorTrigger {
+		return kedav1alpha1.ReplicaCalculatorTrigger{MetricType: v2beta2.AverageValueMetricType, Value: value, Target: target}
+	}
+	value := func(value, target float64) kedav1alpha1.ReplicaCalculatorTrigger {
+		return kedav1alpha1.ReplicaCalculatorTrigger{MetricType: v2beta2.ValueMetricType, Value: value, Target: target}
+	}
+	failed := averageValue(1000, 1)
+	failed.Error = "timeout"
+
+	tests := []struct {
+		name            string
+		triggers        []kedav1alpha1.ReplicaCalculatorTrigger
+		currentReplicas int32
+		expected        int32
+	}{
+		{"average value", []kedav1alpha1.ReplicaCalculatorTrigger{averageValue(45, 10)}, 2, 5},
+		{"value", []kedav1alpha1.ReplicaCalculatorTrigger{value(150, 100)}, 3, 5},
+		{"highest trigger", []kedav1alpha1.ReplicaCalculatorTrigger{averageValue(45, 10), value(300, 100)}, 3, 9},
+		{"failed trigger", []kedav1alpha1.ReplicaCalculatorTrigger{averageValue(20, 10), failed}, 3, 2},
+		{"no triggers", nil, 3, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, proposeReplicas(test.triggers, test.currentReplicas))
+		})
+	}
+}
+
+func TestCallReplicaCalculator(t *testing.T) {
+	var received kedav1alpha1.ReplicaCalculatorRequest
+	response := `{"replicas": 7}`
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	webhook := &kedav1alpha1.ReplicaCalculatorWebhook{URL: server.URL}
+	request := kedav1alpha1.ReplicaCalculatorRequest{
+		Namespace:        "shop",
+		Name:             "orders",
+		CurrentReplicas:  2,
+		ProposedReplicas: 4,
+		Triggers:         []kedav1alpha1.ReplicaCalculatorTrigger{{Name: "queue", MetricName: "s0-queue", MetricType: v2beta2.AverageValueMetricType, Value: 40, Target: 10}},
+	}
+
+	replicas, err := callReplicaCalculator(context.Background(), webhook, request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), replicas)
+	assert.Equal(t, request, received)
+
+	// the proposed replica count is kept when the webhook doesn't set one
+	response = `{}`
+	replicas, err = callReplicaCalculator(context.Background(), webhook, request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), replicas)
+
+	response = `{"replicas": -1}`
+	_, err = callReplicaCalculator(context.Background(), webhook, request)
+	assert.Error(t, err)
+
+	status = http.StatusInternalServerError
+	response = "unavailable"
+	_, err = callReplicaCalculator(context.Background(), webhook, request)
+	assert.EqualError(t, err, "webhook returned 500: unavailable")
+}
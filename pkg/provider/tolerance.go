@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"math"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// hpaTolerance is the default tolerance of the HPA controller, a usage ratio within it doesn't change the replica count
+const hpaTolerance = 0.1
+
+// getMetricsWithTolerance applies the tolerance of the ScaledObject to the metrics of an external metric spec
+func (p *KedaProvider) getMetricsWithTolerance(ctx context.Context, metrics []external_metrics.ExternalMetricValue, scaledObject *kedav1alpha1.ScaledObject, metricSpec v2beta2.MetricSpec) []external_metrics.ExternalMetricValue {
+	tolerance := scaledObject.GetTolerance()
+	if tolerance == nil || len(metrics) == 0 {
+		return metrics
+	}
+
+	currentReplicas, err := p.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		logger.V(1).Info("Skipping the tolerance, unable to read the current replicas", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "error", err.Error())
+		return metrics
+	}
+	return applyTolerance(metrics, metricSpec, currentReplicas, tolerance.AsApproximateFloat64())
+}
+
+// applyTolerance adjusts the metrics so the HPA honors the tolerance instead of its own:
+// a usage ratio within the tolerance is reported on target, so the replica count is kept,
+// a usage ratio beyond the tolerance but within the HPA tolerance is reported as the ratio of the replicas it requires
+func applyTolerance(metrics []external_metrics.ExternalMetricValue, metricSpec v2beta2.MetricSpec, currentReplicas int32, tolerance float64) []external_metrics.ExternalMetricValue {
+	if metricSpec.External == nil || currentReplicas <= 0 {
+		return metrics
+	}
+
+	var total float64
+	switch target := metricSpec.External.Target; {
+	case target.Type == v2beta2.AverageValueMetricType && target.AverageValue != nil:
+		total = target.AverageValue.AsApproximateFloat64() * float64(currentReplicas)
+	case target.Type == v2beta2.ValueMetricType && target.Value != nil:
+		total = target.Value.AsApproximateFloat64()
+	}
+	if total <= 0 {
+		return metrics
+	}
+
+	var value float64
+	for _, metric := range metrics {
+		value += metric.Value.AsApproximateFloat64()
+	}
+	ratio := value / total
+
+	var adjustedRatio float64
+	switch {
+	case math.Abs(ratio-1) <= tolerance:
+		adjustedRatio = 1
+	case math.Abs(ratio-1) <= hpaTolerance:
+		adjustedRatio = math.Ceil(ratio*float64(currentReplicas)) / float64(currentReplicas)
+	default:
+		return metrics
+	}
+
+	adjusted := make([]external_metrics.ExternalMetricValue, 0, len(metrics))
+	for _, metric := range metrics {
+		metric.Value = *resource.NewMilliQuantity(int64(math.Round(float64(metric.Value.MilliValue())*adjustedRatio/ratio)), resource.DecimalSI)
+		adjusted = append(adjusted, metric)
+	}
+	return adjusted
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+func TestApplyTolerance(t *testing.T) {
+	averageValueSpec := v2beta2.MetricSpec{External: &v2beta2.ExternalMetricSource{
+		Target: v2beta2.MetricTarget{Type: v2beta2.AverageValueMetricType, AverageValue: resource.NewQuantity(10, resource.DecimalSI)},
+	}}
+	valueSpec := v2beta2.MetricSpec{External: &v2beta2.ExternalMetricSource{
+		Target: v2beta2.MetricTarget{Type: v2beta2.ValueMetricType, Value: resource.NewQuantity(100, resource.DecimalSI)},
+	}}
+	metrics := func(milliValues ...int64) []external_metrics.ExternalMetricValue {
+		values := make([]external_metrics.ExternalMetricValue, 0, len(milliValues))
+		for _, value := range milliValues {
+			values = append(values, external_metrics.ExternalMetricValue{MetricName: metricName, Value: *resource.NewMilliQuantity(value, resource.DecimalSI)})
+		}
+		return values
+	}
+
+	tests := []struct {
+		name            string
+		metrics         []external_metrics.ExternalMetricValue
+		metricSpec      v2beta2.MetricSpec
+		currentReplicas int32
+		tolerance       float64
+		expected        []external_metrics.ExternalMetricValue
+	}{
+		{"within the tolerance above the target", metrics(48000), averageValueSpec, 4, 0.2, metrics(40000)},
+		{"within the tolerance below the target", metrics(33000), averageValueSpec, 4, 0.2, metrics(40000)},
+		{"beyond the tolerance", metrics(50000), averageValueSpec, 4, 0.2, metrics(50000)},
+		{"within the tolerance summed metrics", metrics(20000, 28000), averageValueSpec, 4, 0.2, metrics(16667, 23333)},
+		{"value target within the tolerance", metrics(115000), valueSpec, 3, 0.2, metrics(100000)},
+		{"below the HPA tolerance", metrics(42000), averageValueSpec, 4, 0.02, metrics(50000)},
+		{"below the HPA tolerance scale down", metrics(37000), averageValueSpec, 4, 0.02, metrics(40000)},
+		{"below the HPA tolerance beyond it", metrics(50000), averageValueSpec, 4, 0.02, metrics(50000)},
+		{"scaled to zero", metrics(5000), averageValueSpec, 0, 0.2, metrics(5000)},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, applyTolerance(test.metrics, test.metricSpec, test.currentReplicas, test.tolerance), test.name)
+	}
+}
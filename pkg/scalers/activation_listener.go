@@ -0,0 +1,59 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+)
+
+// activationNotificationHub is the hub the notifications pushed to the operator are dispatched by
+var activationNotificationHub = notification.Default
+
+// notificationListener listens to the notifications pushed to the notification endpoint of the operator, eg. by a
+// GCP Pub/Sub push subscription or an Azure Event Grid webhook subscription, at
+// notification.PathPrefix + "namespaces/<namespace>/<activationNotificationKey>?token=<activationNotificationToken>"
+// or with the token in an `Authorization: Bearer <activationNotificationToken>` header
+type notificationListener struct {
+	namespace string
+	key       string
+	token     string
+}
+
+// parseNotificationListener returns the listener of the activationNotificationKey metadata, nil if it isn't set
+func parseNotificationListener(config *ScalerConfig) (*notificationListener, error) {
+	key := config.TriggerMetadata["activationNotificationKey"]
+	if key == "" {
+		return nil, nil
+	}
+	if strings.Contains(key, "/") {
+		return nil, fmt.Errorf("activationNotificationKey must not contain '/'")
+	}
+	token := config.AuthParams["activationNotificationToken"]
+	if token == "" {
+		return nil, fmt.Errorf("activationNotificationToken is required in the TriggerAuthentication with activationNotificationKey")
+	}
+	return &notificationListener{namespace: config.Namespace, key: key, token: token}, nil
+}
+
+// listen relays the notifications to notify until ctx is done, a nil listener returns right away
+func (l *notificationListener) listen(ctx context.Context, notify chan<- struct{}) error {
+	if l == nil {
+		return nil
+	}
+	notifications, unsubscribe := activationNotificationHub.Subscribe(l.namespace, l.key, l.token)
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notifications:
+			select {
+			case notify <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
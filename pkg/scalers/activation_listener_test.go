@@ -0,0 +1,70 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+)
+
+type parseNotificationListenerTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	listener   bool
+	isError    bool
+}
+
+var testNotificationListenerMetadata = []parseNotificationListenerTestData{
+	// no listener
+	{map[string]string{}, map[string]string{}, false, false},
+	// listener
+	{map[string]string{"activationNotificationKey": "orders"}, map[string]string{"activationNotificationToken": "secret"}, true, false},
+	// no token
+	{map[string]string{"activationNotificationKey": "orders"}, map[string]string{}, false, true},
+	// key with a slash
+	{map[string]string{"activationNotificationKey": "shop/orders"}, map[string]string{"activationNotificationToken": "secret"}, false, true},
+}
+
+func TestParseNotificationListener(t *testing.T) {
+	for i, testData := range testNotificationListenerMetadata {
+		listener, err := parseNotificationListener(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, Namespace: "shop"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+		assert.Equal(t, testData.listener, listener != nil, "unit test #%v", i)
+	}
+}
+
+func TestNotificationListenerListen(t *testing.T) {
+	hub := notification.NewHub()
+	activationNotificationHub = hub
+	defer func() { activationNotificationHub = notification.Default }()
+
+	// a nil listener returns right away
+	var disabled *notificationListener
+	assert.NoError(t, disabled.listen(context.Background(), make(chan struct{})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	listener := &notificationListener{namespace: "shop", key: "orders", token: "secret"}
+	notify := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- listener.listen(ctx, notify) }()
+
+	assert.Eventually(t, func() bool { return hub.Subscribed("shop", "orders", "secret") }, time.Second, time.Millisecond)
+	assert.True(t, hub.Notify("shop", "orders", "secret"))
+	select {
+	case <-notify:
+	case <-time.After(time.Second):
+		t.Error("the notification wasn't relayed")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.False(t, hub.Subscribed("shop", "orders", "secret"), "the listener must unsubscribe once done")
+}
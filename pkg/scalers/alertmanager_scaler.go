@@ -0,0 +1,164 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// alertmanagerSignals are the signals the Alertmanager webhooks received by the notification server set
+var alertmanagerSignals = notification.DefaultSignals
+
+type alertmanagerScaler struct {
+	metadata *alertmanagerMetadata
+}
+
+type alertmanagerMetadata struct {
+	// Signal is the name of the signal in the webhook path, namespaces/<namespace>/<signal>
+	Signal string `keda:"name=signal"`
+	// HMACSecret is the key of the HMAC-SHA256 signatures of the webhooks
+	HMACSecret string `keda:"name=hmacSecret, order=authParams"`
+	// ValueAnnotation is the annotation of the alerts holding their value, the value is the number of firing
+	// alerts if it isn't set
+	ValueAnnotation string  `keda:"name=valueAnnotation, optional"`
+	TargetValue     float64 `keda:"name=targetValue, default=1"`
+
+	namespace   string
+	scalerIndex int
+}
+
+// Validate checks the signal can be set from the webhook path
+func (m *alertmanagerMetadata) Validate() error {
+	if strings.Contains(m.Signal, "/") {
+		return fmt.Errorf("signal must not contain a /")
+	}
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	return nil
+}
+
+var alertmanagerLog = logf.Log.WithName("alertmanager_scaler")
+
+// NewAlertmanagerScaler creates a new push scaler for the signals set by Alertmanager webhooks, the webhooks are
+// received by the notification server of the operator and activate the scale target as soon as an alert fires
+func NewAlertmanagerScaler(config *ScalerConfig) (PushScaler, error) {
+	meta, err := parseAlertmanagerMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing alertmanager metadata: %s", err)
+	}
+
+	return &alertmanagerScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseAlertmanagerMetadata(config *ScalerConfig) (*alertmanagerMetadata, error) {
+	meta := &alertmanagerMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+	meta.namespace = config.Namespace
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// getValue returns the value of the firing alerts of the signal
+func (s *alertmanagerScaler) getValue(ctx context.Context) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	firing := alertmanagerSignals.Firing(s.metadata.namespace, s.metadata.Signal, time.Now())
+	if s.metadata.ValueAnnotation == "" {
+		return float64(len(firing)), nil
+	}
+	var value float64
+	for _, alert := range firing {
+		v, err := strconv.ParseFloat(alert.Annotations[s.metadata.ValueAnnotation], 64)
+		if err != nil {
+			alertmanagerLog.V(1).Info("Skipping alert without a valid value annotation", "annotation", s.metadata.ValueAnnotation, "labels", alert.Labels)
+			continue
+		}
+		value += v
+	}
+	return value, nil
+}
+
+// IsActive returns true if an alert of the signal is firing
+func (s *alertmanagerScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		return false, err
+	}
+	return value > 0, nil
+}
+
+// Run notifies the changes of the activity of the signal until ctx is done
+func (s *alertmanagerScaler) Run(ctx context.Context, active chan<- bool) {
+	defer close(active)
+	notify, unsubscribe := alertmanagerSignals.Subscribe(s.metadata.namespace, s.metadata.Signal, s.metadata.HMACSecret)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-notify:
+			isActive, err := s.IsActive(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case active <- isActive:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Close no need for alertmanager scaler
+func (s *alertmanagerScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *alertmanagerScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("alertmanager-%s", s.metadata.Signal))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value of the firing alerts of the signal
+func (s *alertmanagerScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *newMilliQuantity(value),
+		Timestamp:  metav1.Now(),
+	}
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,85 @@
+package scalers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+)
+
+type parseAlertmanagerMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+var testAlertmanagerMetadata = []parseAlertmanagerMetadataTestData{
+	// properly formed
+	{map[string]string{"signal": "orders-backlog"}, map[string]string{"hmacSecret": "secret"}, false},
+	// value annotation and target value
+	{map[string]string{"signal": "orders-backlog", "valueAnnotation": "queue", "targetValue": "5"}, map[string]string{"hmacSecret": "secret"}, false},
+	// missing signal
+	{map[string]string{}, map[string]string{"hmacSecret": "secret"}, true},
+	// missing secret
+	{map[string]string{"signal": "orders-backlog"}, map[string]string{}, true},
+	// signal with a slash
+	{map[string]string{"signal": "shop/orders"}, map[string]string{"hmacSecret": "secret"}, true},
+	// invalid target value
+	{map[string]string{"signal": "orders-backlog", "targetValue": "0"}, map[string]string{"hmacSecret": "secret"}, true},
+}
+
+func TestParseAlertmanagerMetadata(t *testing.T) {
+	for i, testData := range testAlertmanagerMetadata {
+		_, err := parseAlertmanagerMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, Namespace: "shop"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestAlertmanagerScalerRun(t *testing.T) {
+	signals := notification.NewSignals()
+	alertmanagerSignals = signals
+	defer func() { alertmanagerSignals = notification.DefaultSignals }()
+
+	scaler, err := NewAlertmanagerScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"signal": "orders-backlog", "valueAnnotation": "queue"},
+		AuthParams:      map[string]string{"hmacSecret": "secret"},
+		Namespace:       "shop",
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	active := make(chan bool)
+	go scaler.Run(ctx, active)
+
+	update := func(alerts ...notification.Alert) bool {
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte("body"))
+		return signals.Update("shop", "orders-backlog", []byte("body"), "sha256="+hex.EncodeToString(h.Sum(nil)), alerts)
+	}
+	firing := notification.Alert{Status: "firing", Fingerprint: "a", Annotations: map[string]string{"queue": "12"}}
+	assert.Eventually(t, func() bool { return update(firing) }, time.Second, time.Millisecond)
+	assert.True(t, <-active)
+
+	metrics, err := scaler.GetMetrics(ctx, "s0-alertmanager-orders-backlog", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), metrics[0].Value.Value())
+
+	firing.Status = "resolved"
+	assert.True(t, update(firing))
+	assert.False(t, <-active)
+
+	cancel()
+	_, open := <-active
+	assert.False(t, open, "the active channel must be closed once done")
+}
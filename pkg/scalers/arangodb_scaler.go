@@ -0,0 +1,247 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type arangoDBScaler struct {
+	metadata   *arangoDBMetadata
+	httpClient *http.Client
+}
+
+type arangoDBMetadata struct {
+	// The address of the ArangoDB server, eg. http://arangodb:8529
+	serverAddress string
+	// The database the query is executed in
+	dbName string
+	// The AQL query returning a single numeric value
+	query string
+	// A threshold that is used as targetAverageValue in HPA
+	queryValue float64
+	// The name of the metric to use in the Horizontal Pod Autoscaler
+	metricName string
+
+	// basic auth
+	enableBasicAuth bool
+	username        string
+	password        string
+
+	// bearer auth with a JWT
+	enableBearerAuth bool
+	bearerToken      string
+
+	scalerIndex int
+}
+
+type arangoDBCursorResult struct {
+	Result       []interface{} `json:"result"`
+	Error        bool          `json:"error"`
+	ErrorMessage string        `json:"errorMessage"`
+}
+
+var arangoDBLog = logf.Log.WithName("arangodb_scaler")
+
+// NewArangoDBScaler creates a new arangoDBScaler
+func NewArangoDBScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseArangoDBMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing arangodb metadata: %s", err)
+	}
+
+	unsafeSsl := false
+	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok && val != "" {
+		unsafeSsl, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing unsafeSsl: %s", err)
+		}
+	}
+
+	return &arangoDBScaler{
+		metadata:   meta,
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, unsafeSsl),
+	}, nil
+}
+
+func parseArangoDBMetadata(config *ScalerConfig) (*arangoDBMetadata, error) {
+	meta := arangoDBMetadata{}
+
+	if val, ok := config.TriggerMetadata["serverAddress"]; ok && val != "" {
+		meta.serverAddress = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no serverAddress given")
+	}
+
+	if val, ok := config.TriggerMetadata["dbName"]; ok && val != "" {
+		meta.dbName = val
+	} else {
+		return nil, fmt.Errorf("no dbName given")
+	}
+
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	queryValue, err := getFloatMetadataValue(config.TriggerMetadata, "queryValue", true, 0)
+	if err != nil {
+		return nil, err
+	}
+	meta.queryValue = queryValue
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("arangodb-%s", val))
+	} else {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("arangodb-%s", meta.dbName))
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	authModes, ok := config.TriggerMetadata["authModes"]
+	// no authMode specified
+	if !ok {
+		return &meta, nil
+	}
+
+	for _, t := range strings.Split(authModes, ",") {
+		authType := authentication.Type(strings.TrimSpace(t))
+		switch authType {
+		case authentication.BasicAuthType:
+			if len(config.AuthParams["username"]) == 0 {
+				return nil, errors.New("no username given")
+			}
+			meta.username = config.AuthParams["username"]
+			meta.password = config.AuthParams["password"]
+			meta.enableBasicAuth = true
+		case authentication.BearerAuthType:
+			if len(config.AuthParams["bearerToken"]) == 0 {
+				return nil, errors.New("no bearer token provided")
+			}
+			meta.bearerToken = config.AuthParams["bearerToken"]
+			meta.enableBearerAuth = true
+		default:
+			return nil, fmt.Errorf("err incorrect value for authMode is given: %s", t)
+		}
+	}
+
+	if meta.enableBasicAuth && meta.enableBearerAuth {
+		return nil, errors.New("bearer and basic authentication can not be set both")
+	}
+
+	return &meta, nil
+}
+
+// getQueryResult executes the AQL query with the cursor API and returns its first result
+func (s *arangoDBScaler) getQueryResult(ctx context.Context) (float64, error) {
+	body, err := json.Marshal(map[string]interface{}{"query": s.metadata.query, "batchSize": 1})
+	if err != nil {
+		return -1, err
+	}
+
+	url := fmt.Sprintf("%s/_db/%s/_api/cursor", s.metadata.serverAddress, url_pkg.PathEscape(s.metadata.dbName))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.metadata.enableBearerAuth {
+		req.Header.Add("Authorization", fmt.Sprintf("bearer %s", s.metadata.bearerToken))
+	} else if s.metadata.enableBasicAuth {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	var result arangoDBCursorResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return -1, fmt.Errorf("arangodb cursor api returned status %d: %s", r.StatusCode, string(b))
+	}
+	if result.Error || !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("arangodb query failed with status %d: %s", r.StatusCode, result.ErrorMessage)
+	}
+
+	// allow for an empty result
+	if len(result.Result) == 0 {
+		return 0, nil
+	}
+
+	switch value := result.Result[0].(type) {
+	case float64:
+		return value, nil
+	case nil:
+		return 0, nil
+	default:
+		return -1, fmt.Errorf("arangodb query %s returned %v, a single numeric value is expected", s.metadata.query, result.Result[0])
+	}
+}
+
+// IsActive returns true if the query result is greater than 0
+func (s *arangoDBScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		arangoDBLog.Error(err, "error executing arangodb query")
+		return false, err
+	}
+
+	return value > 0, nil
+}
+
+// Close does nothing, the scaler only uses the HTTP API
+func (s *arangoDBScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *arangoDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.queryValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value of the metric from the query result
+func (s *arangoDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		arangoDBLog.Error(err, "error executing arangodb query")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, value)
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,138 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseArangoDBMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type arangoDBMetricIdentifier struct {
+	metadataTestData *parseArangoDBMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testArangoDBMetadata = []parseArangoDBMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10"}, map[string]string{}, false},
+	// missing serverAddress
+	{map[string]string{"dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10"}, map[string]string{}, true},
+	// missing dbName
+	{map[string]string{"serverAddress": "http://localhost:8529", "query": "RETURN LENGTH(queue)", "queryValue": "10"}, map[string]string{}, true},
+	// missing query
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "queryValue": "10"}, map[string]string{}, true},
+	// malformed queryValue
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "ten"}, map[string]string{}, true},
+	// basic auth
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10", "authModes": "basic"}, map[string]string{"username": "root", "password": "secret"}, false},
+	// basic auth without username
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10", "authModes": "basic"}, map[string]string{}, true},
+	// jwt auth
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10", "authModes": "bearer"}, map[string]string{"bearerToken": "jwt"}, false},
+	// both auth modes
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10", "authModes": "basic,bearer"}, map[string]string{"username": "root", "bearerToken": "jwt"}, true},
+	// unknown auth mode
+	{map[string]string{"serverAddress": "http://localhost:8529", "dbName": "jobs", "query": "RETURN LENGTH(queue)", "queryValue": "10", "authModes": "tls"}, map[string]string{}, true},
+}
+
+var arangoDBMetricIdentifiers = []arangoDBMetricIdentifier{
+	{&testArangoDBMetadata[1], 0, "s0-arangodb-jobs"},
+	{&testArangoDBMetadata[1], 1, "s1-arangodb-jobs"},
+}
+
+func TestArangoDBParseMetadata(t *testing.T) {
+	for _, testData := range testArangoDBMetadata {
+		_, err := parseArangoDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestArangoDBGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range arangoDBMetricIdentifiers {
+		meta, err := parseArangoDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockArangoDBScaler := arangoDBScaler{metadata: meta}
+
+		metricSpec := mockArangoDBScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+type arangoDBQueryResultTestData struct {
+	name           string
+	responseStatus int
+	bodyStr        string
+	expectedValue  float64
+	isError        bool
+}
+
+var testArangoDBQueryResult = []arangoDBQueryResultTestData{
+	{"numeric result", http.StatusCreated, `{"result":[42],"hasMore":false,"error":false,"code":201}`, 42, false},
+	{"empty result", http.StatusCreated, `{"result":[],"hasMore":false,"error":false,"code":201}`, 0, false},
+	{"null result", http.StatusCreated, `{"result":[null],"hasMore":false,"error":false,"code":201}`, 0, false},
+	{"not numeric result", http.StatusCreated, `{"result":[{"count":1}],"hasMore":false,"error":false,"code":201}`, -1, true},
+	{"query error", http.StatusBadRequest, `{"error":true,"errorMessage":"syntax error","code":400,"errorNum":1501}`, -1, true},
+	{"not json", http.StatusBadGateway, `bad gateway`, -1, true},
+}
+
+func TestArangoDBGetQueryResult(t *testing.T) {
+	for _, testData := range testArangoDBQueryResult {
+		t.Run(testData.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/_db/jobs/_api/cursor", request.URL.Path)
+				assert.Equal(t, "bearer jwt", request.Header.Get("Authorization"))
+				var body map[string]interface{}
+				assert.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+				assert.Equal(t, "RETURN LENGTH(queue)", body["query"])
+
+				writer.WriteHeader(testData.responseStatus)
+				if _, err := writer.Write([]byte(testData.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			scaler := arangoDBScaler{
+				metadata: &arangoDBMetadata{
+					serverAddress:    server.URL,
+					dbName:           "jobs",
+					query:            "RETURN LENGTH(queue)",
+					enableBearerAuth: true,
+					bearerToken:      "jwt",
+				},
+				httpClient: http.DefaultClient,
+			}
+
+			value, err := scaler.getQueryResult(context.TODO())
+
+			assert.Equal(t, testData.expectedValue, value)
+			if testData.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+var argoWorkflowListGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "WorkflowList"}
+
+type argoWorkflowsScaler struct {
+	metadata   *argoWorkflowsMetadata
+	kubeClient client.Client
+}
+
+type argoWorkflowsMetadata struct {
+	LabelSelector string `keda:"name=labelSelector, optional"`
+	// Phases are the phases of the counted workflows, the workflows not reconciled by the Argo controller yet are
+	// counted as Pending, both phases are counted by default
+	Phases []string `keda:"name=phases, optional, enum=Pending;Running"`
+	// ActivationThreshold is the number of workflows the scale target is activated above
+	ActivationThreshold int64   `keda:"name=activationThreshold, default=0"`
+	TargetValue         float64 `keda:"name=targetValue, default=1"`
+
+	selector    labels.Selector
+	namespace   string
+	scalerIndex int
+}
+
+// Validate checks the thresholds
+func (m *argoWorkflowsMetadata) Validate() error {
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	if m.ActivationThreshold < 0 {
+		return fmt.Errorf("activationThreshold must not be negative")
+	}
+	return nil
+}
+
+// NewArgoWorkflowsScaler creates a new scaler counting the pending and running Argo Workflows of the namespace, so
+// the services supporting the workflows scale with their load
+func NewArgoWorkflowsScaler(kubeClient client.Client, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseArgoWorkflowsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing argo-workflows metadata: %s", err)
+	}
+
+	return &argoWorkflowsScaler{
+		metadata:   meta,
+		kubeClient: kubeClient,
+	}, nil
+}
+
+func parseArgoWorkflowsMetadata(config *ScalerConfig) (*argoWorkflowsMetadata, error) {
+	meta := &argoWorkflowsMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+	if len(meta.Phases) == 0 {
+		meta.Phases = []string{"Pending", "Running"}
+	}
+
+	selector, err := labels.Parse(meta.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labelSelector: %s", err)
+	}
+	meta.selector = selector
+	meta.namespace = config.Namespace
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// IsActive determines if the number of workflows exceeds the activation threshold
+func (s *argoWorkflowsScaler) IsActive(ctx context.Context) (bool, error) {
+	workflows, err := s.getWorkflows(ctx)
+	if err != nil {
+		return false, err
+	}
+	return int64(workflows) > s.metadata.ActivationThreshold, nil
+}
+
+// Close no need for argo workflows scaler
+func (s *argoWorkflowsScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *argoWorkflowsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("argo-workflows-%s", s.metadata.namespace))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the number of workflows in the phases
+func (s *argoWorkflowsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	workflows, err := s.getWorkflows(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error listing argo workflows: %s", err)
+	}
+	return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, float64(workflows))), nil
+}
+
+// getWorkflows lists the workflows matching the label selector, they are read as unstructured objects so KEDA
+// doesn't depend on the Argo API
+func (s *argoWorkflowsScaler) getWorkflows(ctx context.Context) (int, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(argoWorkflowListGVK)
+	err := s.kubeClient.List(ctx, list, client.InNamespace(s.metadata.namespace), client.MatchingLabelsSelector{Selector: s.metadata.selector})
+	if err != nil {
+		return 0, err
+	}
+
+	workflows := 0
+	for i := range list.Items {
+		phase, _, _ := unstructured.NestedString(list.Items[i].Object, "status", "phase")
+		if phase == "" {
+			phase = "Pending"
+		}
+		if contains(s.metadata.Phases, phase) {
+			workflows++
+		}
+	}
+	return workflows, nil
+}
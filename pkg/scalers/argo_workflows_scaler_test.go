@@ -0,0 +1,91 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type parseArgoWorkflowsMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+var testArgoWorkflowsMetadata = []parseArgoWorkflowsMetadataTestData{
+	{map[string]string{}, false},
+	{map[string]string{"labelSelector": "workflows.argoproj.io/workflow-template=etl", "phases": "Pending", "activationThreshold": "3", "targetValue": "10"}, false},
+	{map[string]string{"phases": "Pending,Succeeded"}, true},
+	{map[string]string{"labelSelector": "team in etl"}, true},
+	{map[string]string{"activationThreshold": "-1"}, true},
+	{map[string]string{"targetValue": "0"}, true},
+}
+
+func TestArgoWorkflowsParseMetadata(t *testing.T) {
+	for i, testData := range testArgoWorkflowsMetadata {
+		_, err := parseArgoWorkflowsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "argo"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func newArgoWorkflow(name, team, phase string) *unstructured.Unstructured {
+	workflow := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	workflow.SetGroupVersionKind(argoWorkflowListGVK.GroupVersion().WithKind("Workflow"))
+	workflow.SetNamespace("argo")
+	workflow.SetName(name)
+	workflow.SetLabels(map[string]string{"team": team})
+	if phase != "" {
+		workflow.Object["status"] = map[string]interface{}{"phase": phase}
+	}
+	return workflow
+}
+
+func TestArgoWorkflowsGetWorkflows(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(
+		newArgoWorkflow("not-reconciled", "etl", ""),
+		newArgoWorkflow("pending", "etl", "Pending"),
+		newArgoWorkflow("running", "etl", "Running"),
+		newArgoWorkflow("succeeded", "etl", "Succeeded"),
+		newArgoWorkflow("error", "etl", "Error"),
+		newArgoWorkflow("other-team", "ml", "Running"),
+	).Build()
+
+	tests := []struct {
+		metadata  map[string]string
+		workflows int
+		active    bool
+	}{
+		{map[string]string{}, 4, true},
+		{map[string]string{"labelSelector": "team=etl"}, 3, true},
+		{map[string]string{"labelSelector": "team=etl", "phases": "Pending"}, 2, true},
+		{map[string]string{"labelSelector": "team=etl", "activationThreshold": "3"}, 3, false},
+	}
+	for _, test := range tests {
+		scaler, err := NewArgoWorkflowsScaler(kubeClient, &ScalerConfig{TriggerMetadata: test.metadata, Namespace: "argo"})
+		assert.NoError(t, err)
+		workflows, err := scaler.(*argoWorkflowsScaler).getWorkflows(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, test.workflows, workflows, "metadata %v", test.metadata)
+
+		active, err := scaler.IsActive(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, test.active, active, "metadata %v", test.metadata)
+	}
+}
+
+func TestArgoWorkflowsGetMetricSpecForScaling(t *testing.T) {
+	scaler, err := NewArgoWorkflowsScaler(nil, &ScalerConfig{TriggerMetadata: map[string]string{"targetValue": "4"}, Namespace: "argo", ScalerIndex: 2})
+	assert.NoError(t, err)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, "s2-argo-workflows-argo", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(4), metricSpec[0].External.Target.AverageValue.Value())
+}
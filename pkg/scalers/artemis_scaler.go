@@ -10,12 +10,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-logr/logr"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -23,6 +23,7 @@ import (
 type artemisScaler struct {
 	metadata   *artemisMetadata
 	httpClient *http.Client
+	logger     logr.Logger
 }
 
 //revive:disable:var-naming breaking change on restApiTemplate, wouldn't bring any benefit to users
@@ -54,14 +55,12 @@ const (
 	defaultCorsHeader         = "http://%s"
 )
 
-var artemisLog = logf.Log.WithName("artemis_queue_scaler")
-
 // NewArtemisQueueScaler creates a new artemis queue Scaler
 func NewArtemisQueueScaler(config *ScalerConfig) (Scaler, error) {
 	// do we need to guarantee this timeout for a specific
 	// reason? if not, we can have buildScaler pass in
 	// the global client
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
 
 	artemisMetadata, err := parseArtemisMetadata(config)
 	if err != nil {
@@ -71,6 +70,7 @@ func NewArtemisQueueScaler(config *ScalerConfig) (Scaler, error) {
 	return &artemisScaler{
 		metadata:   artemisMetadata,
 		httpClient: httpClient,
+		logger:     InitializeLogger(config, "artemis_queue_scaler"),
 	}, nil
 }
 
@@ -164,7 +164,7 @@ func parseArtemisMetadata(config *ScalerConfig) (*artemisMetadata, error) {
 func (s *artemisScaler) IsActive(ctx context.Context) (bool, error) {
 	messages, err := s.getQueueMessageCount(ctx)
 	if err != nil {
-		artemisLog.Error(err, "Unable to access the artemis management endpoint", "managementEndpoint", s.metadata.managementEndpoint)
+		s.logger.Error(err, "Unable to access the artemis management endpoint", "managementEndpoint", s.metadata.managementEndpoint)
 		return false, err
 	}
 
@@ -245,7 +245,7 @@ func (s *artemisScaler) getQueueMessageCount(ctx context.Context) (int, error) {
 		return -1, fmt.Errorf("artemis management endpoint response error code : %d %d", resp.StatusCode, monitoringInfo.Status)
 	}
 
-	artemisLog.V(1).Info(fmt.Sprintf("Artemis scaler: Providing metrics based on current queue length %d queue length limit %d", messageCount, s.metadata.queueLength))
+	s.logger.V(1).Info("Providing metrics based on the current queue length", "queueLength", messageCount, "queueLengthLimit", s.metadata.queueLength)
 
 	return messageCount, nil
 }
@@ -270,7 +270,7 @@ func (s *artemisScaler) GetMetrics(ctx context.Context, metricName string, metri
 	messages, err := s.getQueueMessageCount(ctx)
 
 	if err != nil {
-		artemisLog.Error(err, "Unable to access the artemis management endpoint", "managementEndpoint", s.metadata.managementEndpoint)
+		s.logger.Error(err, "Unable to access the artemis management endpoint", "managementEndpoint", s.metadata.managementEndpoint)
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
@@ -285,5 +285,6 @@ func (s *artemisScaler) GetMetrics(ctx context.Context, metricName string, metri
 
 // Nothing to close here.
 func (s *artemisScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
@@ -12,4 +12,6 @@ const (
 	TLSAuthType Type = "tls"
 	// BearerAuthType is a auth type using a bearer token
 	BearerAuthType Type = "bearer"
+	// OAuth2AuthType is a auth type using the bearer tokens of an OAuth2 client credentials grant
+	OAuth2AuthType Type = "oauth2"
 )
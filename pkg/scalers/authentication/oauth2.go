@@ -0,0 +1,75 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauth2TokenTimeout bounds the requests to the token URL
+const oauth2TokenTimeout = 30 * time.Second
+
+// OAuth2ClientCredentials is the OAuth2 client credentials grant of a scaler, its bearer tokens are added to
+// the requests of the scaler
+type OAuth2ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Audience     string
+}
+
+// ParseOAuth2ClientCredentials reads the tokenURL, clientID and clientSecret auth params, and the optional
+// comma separated scopes and audience
+func ParseOAuth2ClientCredentials(authParams map[string]string) (*OAuth2ClientCredentials, error) {
+	credentials := &OAuth2ClientCredentials{
+		TokenURL:     authParams["tokenURL"],
+		ClientID:     authParams["clientID"],
+		ClientSecret: authParams["clientSecret"],
+		Audience:     authParams["audience"],
+	}
+	switch {
+	case credentials.TokenURL == "":
+		return nil, errors.New("no tokenURL given")
+	case credentials.ClientID == "":
+		return nil, errors.New("no clientID given")
+	case credentials.ClientSecret == "":
+		return nil, errors.New("no clientSecret given")
+	}
+	if _, err := url.ParseRequestURI(credentials.TokenURL); err != nil {
+		return nil, errors.New("tokenURL is not a valid URL")
+	}
+
+	for _, scope := range strings.Split(authParams["scopes"], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			credentials.Scopes = append(credentials.Scopes, scope)
+		}
+	}
+	return credentials, nil
+}
+
+// Transport returns base adding the bearer token of the credentials to the requests, the token is reused
+// until it expires. The token URL is requested with base too, http.DefaultTransport when nil.
+func (c *OAuth2ClientCredentials) Transport(base http.RoundTripper) http.RoundTripper {
+	config := clientcredentials.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		TokenURL:     c.TokenURL,
+		Scopes:       c.Scopes,
+	}
+	if c.Audience != "" {
+		config.EndpointParams = url.Values{"audience": {c.Audience}}
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: base, Timeout: oauth2TokenTimeout})
+	return &oauth2.Transport{
+		Source: config.TokenSource(ctx),
+		Base:   base,
+	}
+}
@@ -0,0 +1,69 @@
+package authentication
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseOAuth2TestData struct {
+	authParams map[string]string
+	expected   *OAuth2ClientCredentials
+	isError    bool
+}
+
+var testOAuth2AuthParams = []parseOAuth2TestData{
+	{map[string]string{"tokenURL": "https://idp/token", "clientID": "keda", "clientSecret": "secret"}, &OAuth2ClientCredentials{TokenURL: "https://idp/token", ClientID: "keda", ClientSecret: "secret"}, false},
+	{map[string]string{"tokenURL": "https://idp/token", "clientID": "keda", "clientSecret": "secret", "scopes": "read, write,", "audience": "metrics"}, &OAuth2ClientCredentials{TokenURL: "https://idp/token", ClientID: "keda", ClientSecret: "secret", Scopes: []string{"read", "write"}, Audience: "metrics"}, false},
+	{map[string]string{"clientID": "keda", "clientSecret": "secret"}, nil, true},
+	{map[string]string{"tokenURL": "https://idp/token", "clientSecret": "secret"}, nil, true},
+	{map[string]string{"tokenURL": "https://idp/token", "clientID": "keda"}, nil, true},
+	{map[string]string{"tokenURL": "idp", "clientID": "keda", "clientSecret": "secret"}, nil, true},
+}
+
+func TestParseOAuth2ClientCredentials(t *testing.T) {
+	for _, testData := range testOAuth2AuthParams {
+		credentials, err := ParseOAuth2ClientCredentials(testData.authParams)
+		if testData.isError {
+			assert.Error(t, err, testData.authParams)
+			continue
+		}
+		assert.NoError(t, err, testData.authParams)
+		assert.Equal(t, testData.expected, credentials)
+	}
+}
+
+func TestOAuth2Transport(t *testing.T) {
+	tokens := 0
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+		clientID, clientSecret, _ := r.BasicAuth()
+		assert.Equal(t, "keda", clientID)
+		assert.Equal(t, "secret", clientSecret)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		assert.Equal(t, "metrics", r.PostForm.Get("audience"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": 3600}`, tokens)
+	}))
+	defer idp.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	credentials := &OAuth2ClientCredentials{TokenURL: idp.URL, ClientID: "keda", ClientSecret: "secret", Scopes: []string{"read", "write"}, Audience: "metrics"}
+	client := &http.Client{Transport: credentials.Transport(nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, 1, tokens)
+}
@@ -3,18 +3,13 @@ package scalers
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
@@ -51,7 +46,8 @@ type awsCloudwatchMetadata struct {
 	metricStatPeriod     int64
 	metricEndTimeOffset  int64
 
-	awsRegion string
+	awsRegion   string
+	awsEndpoint awsEndpointMetadata
 
 	awsAuthorization awsAuthorizationMetadata
 
@@ -73,62 +69,9 @@ func NewAwsCloudwatchScaler(config *ScalerConfig) (Scaler, error) {
 	}, nil
 }
 
-func getIntMetadataValue(metadata map[string]string, key string, required bool, defaultValue int64) (int64, error) {
-	if val, ok := metadata[key]; ok && val != "" {
-		value, err := strconv.Atoi(val)
-		if err != nil {
-			return 0, fmt.Errorf("error parsing %s metadata: %v", key, err)
-		}
-		return int64(value), nil
-	}
-
-	if required {
-		return 0, fmt.Errorf("metadata %s not given", key)
-	}
-
-	return defaultValue, nil
-}
-
-func getFloatMetadataValue(metadata map[string]string, key string, required bool, defaultValue float64) (float64, error) {
-	if val, ok := metadata[key]; ok && val != "" {
-		value, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			return 0, fmt.Errorf("error parsing %s metadata: %v", key, err)
-		}
-		return value, nil
-	}
-
-	if required {
-		return 0, fmt.Errorf("metadata %s not given", key)
-	}
-
-	return defaultValue, nil
-}
-
 func createCloudwatchClient(metadata *awsCloudwatchMetadata) *cloudwatch.CloudWatch {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(metadata.awsRegion),
-	}))
-
-	var cloudwatchClient *cloudwatch.CloudWatch
-	if metadata.awsAuthorization.podIdentityOwner {
-		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
-
-		if metadata.awsAuthorization.awsRoleArn != "" {
-			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
-		}
-
-		cloudwatchClient = cloudwatch.New(sess, &aws.Config{
-			Region:      aws.String(metadata.awsRegion),
-			Credentials: creds,
-		})
-	} else {
-		cloudwatchClient = cloudwatch.New(sess, &aws.Config{
-			Region: aws.String(metadata.awsRegion),
-		})
-	}
-
-	return cloudwatchClient
+	sess, config := getAwsConfig(metadata.awsRegion, metadata.awsEndpoint, metadata.awsAuthorization)
+	return cloudwatch.New(sess, config)
 }
 
 func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, error) {
@@ -163,12 +106,19 @@ func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, e
 		return nil, fmt.Errorf("dimensionName and dimensionValue are not matching in size")
 	}
 
-	meta.targetMetricValue, err = getFloatMetadataValue(config.TriggerMetadata, "targetMetricValue", true, 0)
+	meta.metricUnit = config.TriggerMetadata["metricUnit"]
+	if err = checkMetricUnit(meta.metricUnit); err != nil {
+		return nil, err
+	}
+
+	// the values can be given in another unit of the kind of the metricUnit, eg. 1Gi for Bytes
+	valueUnit := cloudwatchValueUnit(meta.metricUnit)
+	meta.targetMetricValue, err = getUnitMetadataValue(config.TriggerMetadata, "targetMetricValue", valueUnit, true, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	meta.minMetricValue, err = getFloatMetadataValue(config.TriggerMetadata, "minMetricValue", true, 0)
+	meta.minMetricValue, err = getUnitMetadataValue(config.TriggerMetadata, "minMetricValue", valueUnit, true, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +131,7 @@ func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, e
 		return nil, err
 	}
 
-	meta.metricStatPeriod, err = getIntMetadataValue(config.TriggerMetadata, "metricStatPeriod", false, defaultMetricStatPeriod)
+	meta.metricStatPeriod, err = getIntUnitMetadataValue(config.TriggerMetadata, "metricStatPeriod", unitSeconds, false, defaultMetricStatPeriod)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +140,7 @@ func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, e
 		return nil, err
 	}
 
-	meta.metricCollectionTime, err = getIntMetadataValue(config.TriggerMetadata, "metricCollectionTime", false, defaultMetricCollectionTime)
+	meta.metricCollectionTime, err = getIntUnitMetadataValue(config.TriggerMetadata, "metricCollectionTime", unitSeconds, false, defaultMetricCollectionTime)
 	if err != nil {
 		return nil, err
 	}
@@ -199,22 +149,23 @@ func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, e
 		return nil, fmt.Errorf("metricCollectionTime must be greater than 0 and a multiple of metricStatPeriod(%d), %d is given", meta.metricStatPeriod, meta.metricCollectionTime)
 	}
 
-	meta.metricEndTimeOffset, err = getIntMetadataValue(config.TriggerMetadata, "metricEndTimeOffset", false, defaultMetricEndTimeOffset)
+	meta.metricEndTimeOffset, err = getIntUnitMetadataValue(config.TriggerMetadata, "metricEndTimeOffset", unitSeconds, false, defaultMetricEndTimeOffset)
 	if err != nil {
 		return nil, err
 	}
 
-	meta.metricUnit = config.TriggerMetadata["metricUnit"]
-	if err = checkMetricUnit(meta.metricUnit); err != nil {
-		return nil, err
-	}
-
 	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
 		meta.awsRegion = val
 	} else {
 		return nil, fmt.Errorf("no awsRegion given")
 	}
 
+	awsEndpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = awsEndpoint
+
 	meta.awsAuthorization, err = getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
 	if err != nil {
 		return nil, err
@@ -246,6 +197,27 @@ func checkMetricUnit(unit string) error {
 	return fmt.Errorf("metricUnit '%s' is not one of %v", unit, cloudwatch.StandardUnit_Values())
 }
 
+// cloudwatchValueUnits are the units of the values of the metricUnits with a size or a duration
+var cloudwatchValueUnits = map[string]string{
+	cloudwatch.StandardUnitMicroseconds: unitMicroseconds,
+	cloudwatch.StandardUnitMilliseconds: unitMilliseconds,
+	cloudwatch.StandardUnitSeconds:      unitSeconds,
+	cloudwatch.StandardUnitBytes:        unitBytes,
+	cloudwatch.StandardUnitKilobytes:    unitKilobytes,
+	cloudwatch.StandardUnitMegabytes:    unitMegabytes,
+	cloudwatch.StandardUnitGigabytes:    unitGigabytes,
+	cloudwatch.StandardUnitTerabytes:    unitTerabytes,
+}
+
+// cloudwatchValueUnit returns the unit the target values of a metricUnit are parsed in, the values of the other
+// metricUnits are counts
+func cloudwatchValueUnit(metricUnit string) string {
+	if unit, ok := cloudwatchValueUnits[metricUnit]; ok {
+		return unit
+	}
+	return unitCount
+}
+
 func checkMetricStatPeriod(period int64) error {
 	if period < 1 {
 		return fmt.Errorf("metricStatPeriod can not be smaller than 1, however, %d is provided", period)
@@ -272,7 +244,7 @@ func computeQueryWindow(current time.Time, metricPeriodSec, metricEndTimeOffsetS
 }
 
 func (c *awsCloudwatchScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	metricValue, err := c.GetCloudwatchMetrics()
+	metricValue, timestamp, err := c.GetCloudwatchMetrics(ctx)
 
 	if err != nil {
 		cloudwatchLog.Error(err, "Error getting metric value")
@@ -281,30 +253,26 @@ func (c *awsCloudwatchScaler) GetMetrics(ctx context.Context, metricName string,
 
 	metric := external_metrics.ExternalMetricValue{
 		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(metricValue), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
+		Value:      *newMilliQuantity(metricValue),
+		Timestamp:  metav1.NewTime(timestamp),
 	}
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
 func (c *awsCloudwatchScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricValue := resource.NewQuantity(int64(c.metadata.targetMetricValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(c.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("aws-cloudwatch-%s", c.metadata.dimensionName[0]))),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricValue,
-		},
+		Target: GetMetricTargetMili(c.metadata.targetMetricValue),
 	}
 	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
 	return []v2beta2.MetricSpec{metricSpec}
 }
 
 func (c *awsCloudwatchScaler) IsActive(ctx context.Context) (bool, error) {
-	val, err := c.GetCloudwatchMetrics()
+	val, _, err := c.GetCloudwatchMetrics(ctx)
 
 	if err != nil {
 		return false, err
@@ -317,7 +285,8 @@ func (c *awsCloudwatchScaler) Close(context.Context) error {
 	return nil
 }
 
-func (c *awsCloudwatchScaler) GetCloudwatchMetrics() (float64, error) {
+// GetCloudwatchMetrics returns the latest datapoint of the metric and its timestamp, now when CloudWatch doesn't return it
+func (c *awsCloudwatchScaler) GetCloudwatchMetrics(ctx context.Context) (float64, time.Time, error) {
 	dimensions := []*cloudwatch.Dimension{}
 	for i := range c.metadata.dimensionName {
 		dimensions = append(dimensions, &cloudwatch.Dimension{
@@ -355,20 +324,25 @@ func (c *awsCloudwatchScaler) GetCloudwatchMetrics() (float64, error) {
 		},
 	}
 
-	output, err := c.cwClient.GetMetricData(&input)
+	output, err := c.cwClient.GetMetricDataWithContext(ctx, &input)
 
 	if err != nil {
 		cloudwatchLog.Error(err, "Failed to get output")
-		return -1, err
+		return -1, time.Time{}, err
 	}
 
 	cloudwatchLog.V(1).Info("Received Metric Data", "data", output)
 	var metricValue float64
+	timestamp := time.Now()
 	if len(output.MetricDataResults) > 0 && len(output.MetricDataResults[0].Values) > 0 {
 		metricValue = *output.MetricDataResults[0].Values[0]
+		// the datapoints are sorted by descending timestamp
+		if len(output.MetricDataResults[0].Timestamps) > 0 && output.MetricDataResults[0].Timestamps[0] != nil {
+			timestamp = *output.MetricDataResults[0].Timestamps[0]
+		}
 	} else {
-		return -1, fmt.Errorf("metric data not received")
+		return -1, time.Time{}, fmt.Errorf("metric data not received")
 	}
 
-	return metricValue, nil
+	return metricValue, timestamp, nil
 }
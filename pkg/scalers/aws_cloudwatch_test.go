@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +22,8 @@ const (
 	testAWSCloudwatchNoValueMetric   = "NoValue"
 )
 
+var testAWSCloudwatchTimestamp = time.Date(2021, 12, 20, 10, 0, 0, 0, time.UTC)
+
 var testAWSCloudwatchResolvedEnv = map[string]string{
 	"AWS_ACCESS_KEY":        "none",
 	"AWS_SECRET_ACCESS_KEY": "none",
@@ -380,7 +383,7 @@ type mockCloudwatch struct {
 	cloudwatchiface.CloudWatchAPI
 }
 
-func (m *mockCloudwatch) GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+func (m *mockCloudwatch) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
 	switch *input.MetricDataQueries[0].MetricStat.Metric.MetricName {
 	case testAWSCloudwatchErrorMetric:
 		return nil, errors.New("error")
@@ -392,7 +395,8 @@ func (m *mockCloudwatch) GetMetricData(input *cloudwatch.GetMetricDataInput) (*c
 	return &cloudwatch.GetMetricDataOutput{
 		MetricDataResults: []*cloudwatch.MetricDataResult{
 			{
-				Values: []*float64{aws.Float64(10)},
+				Values:     []*float64{aws.Float64(10)},
+				Timestamps: []*time.Time{aws.Time(testAWSCloudwatchTimestamp)},
 			},
 		},
 	}, nil
@@ -410,6 +414,27 @@ func TestCloudwatchParseMetadata(t *testing.T) {
 	}
 }
 
+func TestCloudwatchParseMetadataUnits(t *testing.T) {
+	meta, err := parseAwsCloudwatchMetadata(&ScalerConfig{TriggerMetadata: map[string]string{
+		"namespace":            "AWS/EBS",
+		"dimensionName":        "VolumeId",
+		"dimensionValue":       "vol-keda",
+		"metricName":           "VolumeReadBytes",
+		"targetMetricValue":    "1Gi",
+		"minMetricValue":       "512Mi",
+		"metricUnit":           "Megabytes",
+		"metricStatPeriod":     "1m",
+		"metricCollectionTime": "5m",
+		"awsRegion":            "eu-west-1"},
+		AuthParams: testAWSAuthentication})
+	assert.NoError(t, err)
+	// the sizes are converted to the metricUnit and the durations to seconds
+	assert.InDelta(t, 1073.741824, meta.targetMetricValue, 1e-9)
+	assert.InDelta(t, 536.870912, meta.minMetricValue, 1e-9)
+	assert.Equal(t, int64(60), meta.metricStatPeriod)
+	assert.Equal(t, int64(300), meta.metricCollectionTime)
+}
+
 func TestAWSCloudwatchGetMetricSpecForScaling(t *testing.T) {
 	for _, testData := range awsCloudwatchMetricIdentifiers {
 		ctx := context.Background()
@@ -439,6 +464,7 @@ func TestAWSCloudwatchScalerGetMetrics(t *testing.T) {
 			assert.Error(t, err, "expect error because of no data return from cloudwatch")
 		default:
 			assert.EqualValues(t, int64(10.0), value[0].Value.Value())
+			assert.True(t, testAWSCloudwatchTimestamp.Equal(value[0].Timestamp.Time))
 		}
 	}
 }
@@ -1,16 +1,42 @@
 package scalers
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
 
 type awsAuthorizationMetadata struct {
 	awsRoleArn string
+	// awsExternalID is passed when assuming awsRoleArn
+	awsExternalID string
 
 	awsAccessKeyID     string
 	awsSecretAccessKey string
 
+	// awsRoleChain are the roles assumed in order from the credentials of the pod or of the operator
+	awsRoleChain []awsAssumedRole
+
 	podIdentityOwner bool
 }
 
+// awsAssumedRole is a role of the awsRoleChain, eg.
+// [{"roleArn": "arn:aws:iam::111111111111:role/hub", "externalId": "keda"}, {"roleArn": "arn:aws:iam::222222222222:role/queues", "sessionTags": {"team": "orders"}}]
+type awsAssumedRole struct {
+	RoleArn    string `json:"roleArn"`
+	ExternalID string `json:"externalId,omitempty"`
+	// SessionTags are passed as transitive tags so they are kept by the next roles of the chain
+	SessionTags map[string]string `json:"sessionTags,omitempty"`
+}
+
 func getAwsAuthorization(authParams, metadata, resolvedEnv map[string]string) (awsAuthorizationMetadata, error) {
 	meta := awsAuthorizationMetadata{}
 
@@ -21,6 +47,7 @@ func getAwsAuthorization(authParams, metadata, resolvedEnv map[string]string) (a
 		switch {
 		case authParams["awsRoleArn"] != "":
 			meta.awsRoleArn = authParams["awsRoleArn"]
+			meta.awsExternalID = authParams["awsExternalID"]
 		case (authParams["awsAccessKeyID"] != "" || authParams["awsAccessKeyId"] != "") && authParams["awsSecretAccessKey"] != "":
 			meta.awsAccessKeyID = authParams["awsAccessKeyID"]
 			if meta.awsAccessKeyID == "" {
@@ -48,5 +75,96 @@ func getAwsAuthorization(authParams, metadata, resolvedEnv map[string]string) (a
 		}
 	}
 
+	roleChain := authParams["awsRoleChain"]
+	if roleChain == "" {
+		roleChain = metadata["awsRoleChain"]
+	}
+	if roleChain != "" {
+		if err := json.Unmarshal([]byte(roleChain), &meta.awsRoleChain); err != nil {
+			return meta, fmt.Errorf("awsRoleChain must be a json list of roles: %s", err)
+		}
+		for i, role := range meta.awsRoleChain {
+			if role.RoleArn == "" {
+				return meta, fmt.Errorf("no roleArn given for the role %d of awsRoleChain", i)
+			}
+		}
+	}
+
 	return meta, nil
 }
+
+// awsEndpointMetadata overrides the endpoints of the AWS clients, the endpoints of the other partitions like
+// GovCloud or China are resolved from the region
+type awsEndpointMetadata struct {
+	// endpointURL replaces the endpoint of the service, eg. a VPC endpoint or LocalStack, the roles are still
+	// assumed with the STS endpoint of the region
+	endpointURL     string
+	useFIPSEndpoint bool
+}
+
+func getAwsEndpoint(metadata map[string]string) (awsEndpointMetadata, error) {
+	meta := awsEndpointMetadata{}
+
+	if val := metadata["endpointURL"]; val != "" {
+		endpointURL, err := url.Parse(val)
+		if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+			return meta, fmt.Errorf("endpointURL must be an http or https url")
+		}
+		meta.endpointURL = val
+	}
+
+	if val := metadata["useFIPSEndpoint"]; val != "" {
+		useFIPSEndpoint, err := strconv.ParseBool(val)
+		if err != nil {
+			return meta, fmt.Errorf("error parsing useFIPSEndpoint: %s", err)
+		}
+		meta.useFIPSEndpoint = useFIPSEndpoint
+	}
+
+	return meta, nil
+}
+
+// getAwsConfig returns the session and the config the clients of the AWS scalers are created with, the roles of
+// the awsRoleChain are assumed with the credentials of the previous one
+func getAwsConfig(region string, endpoint awsEndpointMetadata, auth awsAuthorizationMetadata) (*session.Session, *aws.Config) {
+	sessionConfig := &aws.Config{
+		Region: aws.String(region),
+		// the global STS endpoint only serves the aws partition
+		STSRegionalEndpoint: endpoints.RegionalSTSEndpoint,
+	}
+	if endpoint.useFIPSEndpoint {
+		sessionConfig.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	sess := session.Must(session.NewSession(sessionConfig))
+
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if auth.podIdentityOwner {
+		config.Credentials = credentials.NewStaticCredentials(auth.awsAccessKeyID, auth.awsSecretAccessKey, "")
+
+		if auth.awsRoleArn != "" {
+			config.Credentials = stscreds.NewCredentials(sess, auth.awsRoleArn, awsAssumedRole{ExternalID: auth.awsExternalID}.options)
+		}
+	}
+
+	for _, role := range auth.awsRoleChain {
+		config.Credentials = stscreds.NewCredentials(sess.Copy(config), role.RoleArn, role.options)
+	}
+
+	if endpoint.endpointURL != "" {
+		config.Endpoint = aws.String(endpoint.endpointURL)
+	}
+	return sess, config
+}
+
+// options sets the external ID and the session tags of the AssumeRole requests
+func (r awsAssumedRole) options(provider *stscreds.AssumeRoleProvider) {
+	if r.ExternalID != "" {
+		provider.ExternalID = aws.String(r.ExternalID)
+	}
+	for key, value := range r.SessionTags {
+		provider.Tags = append(provider.Tags, &sts.Tag{Key: aws.String(key), Value: aws.String(value)})
+		provider.TransitiveTagKeys = append(provider.TransitiveTagKeys, aws.String(key))
+	}
+}
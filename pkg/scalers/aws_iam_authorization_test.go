@@ -0,0 +1,85 @@
+package scalers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+)
+
+type parseAwsAuthorizationTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	roleChain  []awsAssumedRole
+	isError    bool
+}
+
+var testAwsRoleChain = `[{"roleArn": "arn:aws:iam::111111111111:role/hub", "externalId": "keda"}, {"roleArn": "arn:aws:iam::222222222222:role/queues", "sessionTags": {"team": "orders"}}]`
+
+var testAwsAuthorizationMetadata = []parseAwsAuthorizationTestData{
+	// role chain from the operator identity
+	{map[string]string{}, map[string]string{"identityOwner": "operator", "awsRoleChain": testAwsRoleChain}, []awsAssumedRole{
+		{RoleArn: "arn:aws:iam::111111111111:role/hub", ExternalID: "keda"},
+		{RoleArn: "arn:aws:iam::222222222222:role/queues", SessionTags: map[string]string{"team": "orders"}},
+	}, false},
+	// role chain of the TriggerAuthentication from the pod role
+	{map[string]string{"awsRoleArn": "arn:aws:iam::000000000000:role/pod", "awsRoleChain": `[{"roleArn": "arn:aws:iam::111111111111:role/hub"}]`}, map[string]string{}, []awsAssumedRole{
+		{RoleArn: "arn:aws:iam::111111111111:role/hub"},
+	}, false},
+	// invalid role chain
+	{map[string]string{}, map[string]string{"identityOwner": "operator", "awsRoleChain": "arn:aws:iam::111111111111:role/hub"}, nil, true},
+	// role without arn
+	{map[string]string{}, map[string]string{"identityOwner": "operator", "awsRoleChain": `[{"externalId": "keda"}]`}, nil, true},
+}
+
+func TestAwsGetAuthorization(t *testing.T) {
+	for _, testData := range testAwsAuthorizationMetadata {
+		meta, err := getAwsAuthorization(testData.authParams, testData.metadata, map[string]string{})
+		if testData.isError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, testData.roleChain, meta.awsRoleChain)
+	}
+}
+
+func TestAwsAssumedRoleOptions(t *testing.T) {
+	provider := &stscreds.AssumeRoleProvider{}
+	awsAssumedRole{RoleArn: "arn:aws:iam::222222222222:role/queues", ExternalID: "keda", SessionTags: map[string]string{"team": "orders"}}.options(provider)
+	assert.Equal(t, aws.String("keda"), provider.ExternalID)
+	assert.Equal(t, []*sts.Tag{{Key: aws.String("team"), Value: aws.String("orders")}}, provider.Tags)
+	assert.Equal(t, []*string{aws.String("team")}, provider.TransitiveTagKeys)
+
+	// the roles of the chain are assumed with the credentials of the previous one
+	_, config := getAwsConfig("eu-west-1", awsEndpointMetadata{}, awsAuthorizationMetadata{awsRoleChain: []awsAssumedRole{{RoleArn: "arn:aws:iam::111111111111:role/hub"}}})
+	assert.NotNil(t, config.Credentials)
+}
+
+func TestAwsGetEndpoint(t *testing.T) {
+	_, err := getAwsEndpoint(map[string]string{"endpointURL": "localstack:4566"})
+	assert.Error(t, err)
+	_, err = getAwsEndpoint(map[string]string{"useFIPSEndpoint": "yes please"})
+	assert.Error(t, err)
+
+	tests := []struct {
+		region   string
+		metadata map[string]string
+		endpoint string
+	}{
+		{"eu-west-1", map[string]string{}, "https://sqs.eu-west-1.amazonaws.com"},
+		{"eu-west-1", map[string]string{"endpointURL": "http://localstack:4566"}, "http://localstack:4566"},
+		{"us-gov-west-1", map[string]string{"useFIPSEndpoint": "true"}, "https://sqs-fips.us-gov-west-1.amazonaws.com"},
+		{"us-east-1", map[string]string{"useFIPSEndpoint": "true"}, "https://sqs-fips.us-east-1.amazonaws.com"},
+		{"cn-north-1", map[string]string{}, "https://sqs.cn-north-1.amazonaws.com.cn"},
+	}
+	for _, test := range tests {
+		endpoint, err := getAwsEndpoint(test.metadata)
+		assert.NoError(t, err)
+		sess, config := getAwsConfig(test.region, endpoint, awsAuthorizationMetadata{})
+		assert.Equal(t, test.endpoint, sqs.New(sess, config).Endpoint, test.region)
+	}
+}
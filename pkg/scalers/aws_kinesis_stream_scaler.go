@@ -5,11 +5,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kinesis"
 	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -35,6 +30,7 @@ type awsKinesisStreamMetadata struct {
 	targetShardCount int
 	streamName       string
 	awsRegion        string
+	awsEndpoint      awsEndpointMetadata
 	awsAuthorization awsAuthorizationMetadata
 	scalerIndex      int
 }
@@ -80,6 +76,12 @@ func parseAwsKinesisStreamMetadata(config *ScalerConfig) (*awsKinesisStreamMetad
 		return nil, fmt.Errorf("no awsRegion given")
 	}
 
+	awsEndpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = awsEndpoint
+
 	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
 	if err != nil {
 		return nil, err
@@ -93,33 +95,13 @@ func parseAwsKinesisStreamMetadata(config *ScalerConfig) (*awsKinesisStreamMetad
 }
 
 func createKinesisClient(metadata *awsKinesisStreamMetadata) *kinesis.Kinesis {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(metadata.awsRegion),
-	}))
-
-	var kinesisClinent *kinesis.Kinesis
-	if metadata.awsAuthorization.podIdentityOwner {
-		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
-
-		if metadata.awsAuthorization.awsRoleArn != "" {
-			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
-		}
-
-		kinesisClinent = kinesis.New(sess, &aws.Config{
-			Region:      aws.String(metadata.awsRegion),
-			Credentials: creds,
-		})
-	} else {
-		kinesisClinent = kinesis.New(sess, &aws.Config{
-			Region: aws.String(metadata.awsRegion),
-		})
-	}
-	return kinesisClinent
+	sess, config := getAwsConfig(metadata.awsRegion, metadata.awsEndpoint, metadata.awsAuthorization)
+	return kinesis.New(sess, config)
 }
 
 // IsActive determines if we need to scale from zero
 func (s *awsKinesisStreamScaler) IsActive(ctx context.Context) (bool, error) {
-	count, err := s.GetAwsKinesisOpenShardCount()
+	count, err := s.GetAwsKinesisOpenShardCount(ctx)
 
 	if err != nil {
 		return false, err
@@ -149,7 +131,7 @@ func (s *awsKinesisStreamScaler) GetMetricSpecForScaling(context.Context) []v2be
 
 // GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
 func (s *awsKinesisStreamScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	shardCount, err := s.GetAwsKinesisOpenShardCount()
+	shardCount, err := s.GetAwsKinesisOpenShardCount(ctx)
 
 	if err != nil {
 		kinesisStreamLog.Error(err, "Error getting shard count")
@@ -166,12 +148,12 @@ func (s *awsKinesisStreamScaler) GetMetrics(ctx context.Context, metricName stri
 }
 
 // Get Kinesis open shard count
-func (s *awsKinesisStreamScaler) GetAwsKinesisOpenShardCount() (int64, error) {
+func (s *awsKinesisStreamScaler) GetAwsKinesisOpenShardCount(ctx context.Context) (int64, error) {
 	input := &kinesis.DescribeStreamSummaryInput{
 		StreamName: &s.metadata.streamName,
 	}
 
-	output, err := s.kinesisClient.DescribeStreamSummary(input)
+	output, err := s.kinesisClient.DescribeStreamSummaryWithContext(ctx, input)
 	if err != nil {
 		return -1, err
 	}
@@ -6,12 +6,12 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -26,6 +26,12 @@ import (
 
 const (
 	targetQueueLengthDefault = 5
+
+	sqsModeQueueLength = "QueueLength"
+	// sqsOldestItemAgeMetricName is the CloudWatch metric of the age of the oldest message, SQS publishes it every
+	// minute so the age is read from the datapoints of the last sqsOldestItemAgeWindow
+	sqsOldestItemAgeMetricName = "ApproximateAgeOfOldestMessage"
+	sqsOldestItemAgeWindow     = 5 * time.Minute
 )
 
 var (
@@ -39,15 +45,35 @@ var (
 type awsSqsQueueScaler struct {
 	metadata  *awsSqsQueueMetadata
 	sqsClient sqsiface.SQSAPI
+	// cwClient reads the age of the oldest message in OldestItemAge mode, nil otherwise
+	cwClient cloudwatchiface.CloudWatchAPI
+
+	deadLetterLock sync.Mutex
+	deadLetter     *sqsDeadLetterState
 }
 
 type awsSqsQueueMetadata struct {
+	// mode is QueueLength or OldestItemAge
+	mode              string
 	targetQueueLength int
-	queueURL          string
-	queueName         string
-	awsRegion         string
-	awsAuthorization  awsAuthorizationMetadata
-	scalerIndex       int
+	// targetOldestItemAge is the target age in seconds of the oldest message in OldestItemAge mode
+	targetOldestItemAge int
+	queueURL            string
+	queueName           string
+	// deadLetterQueueURL is the DLQ of the queue, the queue length stops growing while the DLQ
+	// grows faster than the queue drains
+	deadLetterQueueURL string
+	awsRegion          string
+	awsEndpoint        awsEndpointMetadata
+	awsAuthorization   awsAuthorizationMetadata
+	scalerIndex        int
+}
+
+// sqsDeadLetterState is the last read of the queue and its DLQ
+type sqsDeadLetterState struct {
+	queueLength      int32
+	deadLetterLength int32
+	reportedLength   int32
 }
 
 // NewAwsSqsQueueScaler creates a new awsSqsQueueScaler
@@ -57,10 +83,15 @@ func NewAwsSqsQueueScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing SQS queue metadata: %s", err)
 	}
 
-	return &awsSqsQueueScaler{
+	scaler := &awsSqsQueueScaler{
 		metadata:  meta,
 		sqsClient: createSqsClient(meta),
-	}, nil
+	}
+	if meta.mode == modeOldestItemAge {
+		sess, cwConfig := getAwsConfig(meta.awsRegion, meta.awsEndpoint, meta.awsAuthorization)
+		scaler.cwClient = cloudwatch.New(sess, cwConfig)
+	}
+	return scaler, nil
 }
 
 func parseAwsSqsQueueMetadata(config *ScalerConfig) (*awsSqsQueueMetadata, error) {
@@ -77,6 +108,28 @@ func parseAwsSqsQueueMetadata(config *ScalerConfig) (*awsSqsQueueMetadata, error
 		}
 	}
 
+	meta.mode = sqsModeQueueLength
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		if val != sqsModeQueueLength && val != modeOldestItemAge {
+			return nil, fmt.Errorf("mode must be %s or %s", sqsModeQueueLength, modeOldestItemAge)
+		}
+		meta.mode = val
+	}
+	if meta.mode == modeOldestItemAge {
+		if _, ok := config.TriggerMetadata["queueLength"]; ok {
+			return nil, fmt.Errorf("queueLength can only be used with mode %s, use value instead", sqsModeQueueLength)
+		}
+		val, ok := config.TriggerMetadata["value"]
+		if !ok || val == "" {
+			return nil, fmt.Errorf("no value given for mode %s", modeOldestItemAge)
+		}
+		age, err := strconv.Atoi(val)
+		if err != nil || age <= 0 {
+			return nil, fmt.Errorf("value must be a number of seconds greater than 0")
+		}
+		meta.targetOldestItemAge = age
+	}
+
 	if val, ok := config.TriggerMetadata["queueURL"]; ok && val != "" {
 		meta.queueURL = val
 	} else {
@@ -96,12 +149,28 @@ func parseAwsSqsQueueMetadata(config *ScalerConfig) (*awsSqsQueueMetadata, error
 
 	meta.queueName = queueURLPathParts[2]
 
+	if val, ok := config.TriggerMetadata["deadLetterQueueURL"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("deadLetterQueueURL is not a valid URL")
+		}
+		if val == meta.queueURL {
+			return nil, fmt.Errorf("deadLetterQueueURL must be different from queueURL")
+		}
+		meta.deadLetterQueueURL = val
+	}
+
 	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
 		meta.awsRegion = val
 	} else {
 		return nil, fmt.Errorf("no awsRegion given")
 	}
 
+	awsEndpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = awsEndpoint
+
 	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
 	if err != nil {
 		return nil, err
@@ -115,33 +184,13 @@ func parseAwsSqsQueueMetadata(config *ScalerConfig) (*awsSqsQueueMetadata, error
 }
 
 func createSqsClient(metadata *awsSqsQueueMetadata) *sqs.SQS {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(metadata.awsRegion),
-	}))
-
-	var sqsClient *sqs.SQS
-	if metadata.awsAuthorization.podIdentityOwner {
-		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
-
-		if metadata.awsAuthorization.awsRoleArn != "" {
-			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
-		}
-
-		sqsClient = sqs.New(sess, &aws.Config{
-			Region:      aws.String(metadata.awsRegion),
-			Credentials: creds,
-		})
-	} else {
-		sqsClient = sqs.New(sess, &aws.Config{
-			Region: aws.String(metadata.awsRegion),
-		})
-	}
-	return sqsClient
+	sess, config := getAwsConfig(metadata.awsRegion, metadata.awsEndpoint, metadata.awsAuthorization)
+	return sqs.New(sess, config)
 }
 
 // IsActive determines if we need to scale from zero
 func (s *awsSqsQueueScaler) IsActive(ctx context.Context) (bool, error) {
-	length, err := s.GetAwsSqsQueueLength()
+	length, err := s.GetAwsSqsQueueLength(ctx)
 
 	if err != nil {
 		return false, err
@@ -156,6 +205,9 @@ func (s *awsSqsQueueScaler) Close(context.Context) error {
 
 func (s *awsSqsQueueScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
 	targetQueueLengthQty := resource.NewQuantity(int64(s.metadata.targetQueueLength), resource.DecimalSI)
+	if s.metadata.mode == modeOldestItemAge {
+		targetQueueLengthQty = resource.NewQuantity(int64(s.metadata.targetOldestItemAge), resource.DecimalSI)
+	}
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("aws-sqs-%s", s.metadata.queueName))),
@@ -171,7 +223,19 @@ func (s *awsSqsQueueScaler) GetMetricSpecForScaling(context.Context) []v2beta2.M
 
 // GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
 func (s *awsSqsQueueScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	queuelen, err := s.GetAwsSqsQueueLength()
+	if s.metadata.mode == modeOldestItemAge {
+		age, err := s.getOldestMessageAge(ctx)
+		if err != nil {
+			sqsQueueLog.Error(err, "Error getting the age of the oldest message")
+			return []external_metrics.ExternalMetricValue{}, err
+		}
+		return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, age)), nil
+	}
+
+	queuelen, err := s.GetAwsSqsQueueLength(ctx)
+	if err == nil && s.metadata.deadLetterQueueURL != "" {
+		queuelen, err = s.holdForDeadLetterQueue(ctx, queuelen)
+	}
 
 	if err != nil {
 		sqsQueueLog.Error(err, "Error getting queue length")
@@ -187,20 +251,92 @@ func (s *awsSqsQueueScaler) GetMetrics(ctx context.Context, metricName string, m
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
+// holdForDeadLetterQueue returns the length to report for the queue, it doesn't grow above the last one
+// while the messages go to the DLQ faster than the queue drains: the consumers fail and more of them
+// would fail the same way
+func (s *awsSqsQueueScaler) holdForDeadLetterQueue(ctx context.Context, queueLength int32) (int32, error) {
+	deadLetterLength, err := s.getQueueLength(ctx, s.metadata.deadLetterQueueURL, awsSqsQueueMetricNames[:1])
+	if err != nil {
+		return -1, fmt.Errorf("error getting dead letter queue length: %s", err)
+	}
+
+	s.deadLetterLock.Lock()
+	defer s.deadLetterLock.Unlock()
+
+	reportedLength := queueLength
+	if last := s.deadLetter; last != nil {
+		// both lengths are read at the same interval, their deltas compare as their rates
+		deadLetterGrowth := deadLetterLength - last.deadLetterLength
+		drained := last.queueLength - queueLength
+		if deadLetterGrowth > 0 && deadLetterGrowth > drained && reportedLength > last.reportedLength {
+			sqsQueueLog.V(1).Info("dead letter queue grows faster than the queue drains, holding the queue length",
+				"queueName", s.metadata.queueName, "deadLetterGrowth", deadLetterGrowth, "drained", drained)
+			reportedLength = last.reportedLength
+		}
+	}
+	s.deadLetter = &sqsDeadLetterState{
+		queueLength:      queueLength,
+		deadLetterLength: deadLetterLength,
+		reportedLength:   reportedLength,
+	}
+	return reportedLength, nil
+}
+
+// getOldestMessageAge returns the latest age in seconds of the oldest message of the queue published to CloudWatch,
+// 0 without datapoints: SQS doesn't publish the metrics of the queues inactive for a while
+func (s *awsSqsQueueScaler) getOldestMessageAge(ctx context.Context) (float64, error) {
+	now := time.Now()
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(now.Add(-sqsOldestItemAgeWindow)),
+		EndTime:   aws.Time(now),
+		ScanBy:    aws.String(cloudwatch.ScanByTimestampDescending),
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{
+			{
+				Id: aws.String("age"),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String("AWS/SQS"),
+						MetricName: aws.String(sqsOldestItemAgeMetricName),
+						Dimensions: []*cloudwatch.Dimension{{Name: aws.String("QueueName"), Value: aws.String(s.metadata.queueName)}},
+					},
+					Period: aws.Int64(60),
+					Stat:   aws.String(cloudwatch.StatisticMaximum),
+				},
+				ReturnData: aws.Bool(true),
+			},
+		},
+	}
+
+	output, err := s.cwClient.GetMetricDataWithContext(ctx, input)
+	if err != nil {
+		return -1, err
+	}
+	// the datapoints are sorted by descending timestamp
+	if len(output.MetricDataResults) == 0 || len(output.MetricDataResults[0].Values) == 0 {
+		return 0, nil
+	}
+	return *output.MetricDataResults[0].Values[0], nil
+}
+
 // Get SQS Queue Length
-func (s *awsSqsQueueScaler) GetAwsSqsQueueLength() (int32, error) {
+func (s *awsSqsQueueScaler) GetAwsSqsQueueLength(ctx context.Context) (int32, error) {
+	return s.getQueueLength(ctx, s.metadata.queueURL, awsSqsQueueMetricNames)
+}
+
+// getQueueLength returns the sum of the attributes of the queue
+func (s *awsSqsQueueScaler) getQueueLength(ctx context.Context, queueURL string, attributes []string) (int32, error) {
 	input := &sqs.GetQueueAttributesInput{
-		AttributeNames: aws.StringSlice(awsSqsQueueMetricNames),
-		QueueUrl:       aws.String(s.metadata.queueURL),
+		AttributeNames: aws.StringSlice(attributes),
+		QueueUrl:       aws.String(queueURL),
 	}
 
-	output, err := s.sqsClient.GetQueueAttributes(input)
+	output, err := s.sqsClient.GetQueueAttributesWithContext(ctx, input)
 	if err != nil {
 		return -1, err
 	}
 
 	var approximateNumberOfMessages int64
-	for _, awsSqsQueueMetric := range awsSqsQueueMetricNames {
+	for _, awsSqsQueueMetric := range attributes {
 		metricValue, err := strconv.ParseInt(*output.Attributes[awsSqsQueueMetric], 10, 32)
 		if err != nil {
 			return -1, err
@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +24,8 @@ const (
 	testAWSSQSImproperQueueURL1 = "https://sqs.eu-west-1.amazonaws.com/account_id"
 	testAWSSQSImproperQueueURL2 = "https://sqs.eu-west-1.amazonaws.com"
 
+	testAWSSQSDeadLetterQueueURL = "https://sqs.eu-west-1.amazonaws.com/account_id/DeleteArtifactDLQ"
+
 	testAWSSQSErrorQueueURL   = "https://sqs.eu-west-1.amazonaws.com/account_id/Error"
 	testAWSSQSBadDataQueueURL = "https://sqs.eu-west-1.amazonaws.com/account_id/BadData"
 )
@@ -47,7 +52,7 @@ type mockSqs struct {
 	sqsiface.SQSAPI
 }
 
-func (m *mockSqs) GetQueueAttributes(input *sqs.GetQueueAttributesInput) (*sqs.GetQueueAttributesOutput, error) {
+func (m *mockSqs) GetQueueAttributesWithContext(ctx aws.Context, input *sqs.GetQueueAttributesInput, opts ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
 	switch *input.QueueUrl {
 	case testAWSSQSErrorQueueURL:
 		return nil, errors.New("some error")
@@ -165,6 +170,58 @@ var testAWSSQSMetadata = []parseAWSSQSMetadataTestData{
 		},
 		false,
 		"with AWS Role assigned on KEDA operator itself"},
+	{map[string]string{
+		"queueURL":           testAWSSQSProperQueueURL,
+		"deadLetterQueueURL": testAWSSQSDeadLetterQueueURL,
+		"awsRegion":          "eu-west-1"},
+		testAWSSQSAuthentication,
+		false,
+		"with dead letter queue"},
+	{map[string]string{
+		"queueURL":           testAWSSQSProperQueueURL,
+		"deadLetterQueueURL": "DeleteArtifactDLQ",
+		"awsRegion":          "eu-west-1"},
+		testAWSSQSAuthentication,
+		true,
+		"with invalid dead letter queue URL"},
+	{map[string]string{
+		"queueURL":           testAWSSQSProperQueueURL,
+		"deadLetterQueueURL": testAWSSQSProperQueueURL,
+		"awsRegion":          "eu-west-1"},
+		testAWSSQSAuthentication,
+		true,
+		"with the queue as dead letter queue"},
+	{map[string]string{
+		"queueURL":  testAWSSQSProperQueueURL,
+		"mode":      "OldestItemAge",
+		"value":     "120",
+		"awsRegion": "eu-west-1"},
+		testAWSSQSAuthentication,
+		false,
+		"with the age of the oldest message"},
+	{map[string]string{
+		"queueURL":    testAWSSQSProperQueueURL,
+		"mode":        "OldestItemAge",
+		"queueLength": "5",
+		"awsRegion":   "eu-west-1"},
+		testAWSSQSAuthentication,
+		true,
+		"with the age of the oldest message and queueLength"},
+	{map[string]string{
+		"queueURL":  testAWSSQSProperQueueURL,
+		"mode":      "OldestItemAge",
+		"value":     "two minutes",
+		"awsRegion": "eu-west-1"},
+		testAWSSQSAuthentication,
+		true,
+		"with the age of the oldest message and an invalid value"},
+	{map[string]string{
+		"queueURL":  testAWSSQSProperQueueURL,
+		"mode":      "NewestItemAge",
+		"awsRegion": "eu-west-1"},
+		testAWSSQSAuthentication,
+		true,
+		"with an unknown mode"},
 }
 
 var awsSQSMetricIdentifiers = []awsSQSMetricIdentifier{
@@ -197,7 +254,7 @@ func TestAWSSQSGetMetricSpecForScaling(t *testing.T) {
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockAWSSQSScaler := awsSqsQueueScaler{meta, &mockSqs{}}
+		mockAWSSQSScaler := awsSqsQueueScaler{metadata: meta, sqsClient: &mockSqs{}}
 
 		metricSpec := mockAWSSQSScaler.GetMetricSpecForScaling(ctx)
 		metricName := metricSpec[0].External.Metric.Name
@@ -210,7 +267,7 @@ func TestAWSSQSGetMetricSpecForScaling(t *testing.T) {
 func TestAWSSQSScalerGetMetrics(t *testing.T) {
 	var selector labels.Selector
 	for _, meta := range awsSQSGetMetricTestData {
-		scaler := awsSqsQueueScaler{meta, &mockSqs{}}
+		scaler := awsSqsQueueScaler{metadata: meta, sqsClient: &mockSqs{}}
 		value, err := scaler.GetMetrics(context.Background(), "MetricName", selector)
 		switch meta.queueURL {
 		case testAWSSQSErrorQueueURL:
@@ -222,3 +279,83 @@ func TestAWSSQSScalerGetMetrics(t *testing.T) {
 		}
 	}
 }
+
+type mockSqsCloudwatch struct {
+	cloudwatchiface.CloudWatchAPI
+	ages []float64
+}
+
+func (m *mockSqsCloudwatch) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
+	stat := input.MetricDataQueries[0].MetricStat
+	if *stat.Metric.MetricName != "ApproximateAgeOfOldestMessage" || *stat.Metric.Dimensions[0].Value != "DeleteArtifactQ" {
+		return nil, errors.New("unexpected metric")
+	}
+	return &cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []*cloudwatch.MetricDataResult{{Values: aws.Float64Slice(m.ages)}},
+	}, nil
+}
+
+func TestAWSSQSScalerGetOldestItemAge(t *testing.T) {
+	meta, err := parseAwsSqsQueueMetadata(&ScalerConfig{TriggerMetadata: testAWSSQSMetadata[15].metadata, AuthParams: testAWSSQSAuthentication})
+	assert.NoError(t, err)
+
+	scaler := awsSqsQueueScaler{metadata: meta, sqsClient: &mockSqs{}, cwClient: &mockSqsCloudwatch{ages: []float64{95, 40}}}
+	value, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 95, value[0].Value.Value())
+	assert.EqualValues(t, 120, scaler.GetMetricSpecForScaling(context.Background())[0].External.Target.AverageValue.Value())
+
+	// the inactive queues have no datapoints
+	scaler.cwClient = &mockSqsCloudwatch{}
+	value, err = scaler.GetMetrics(context.Background(), "MetricName", nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, value[0].Value.Value())
+
+	// the activation stays on the length of the queue
+	active, err := scaler.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+}
+
+type mockSqsLengths struct {
+	sqsiface.SQSAPI
+	lengths map[string]string
+}
+
+func (m *mockSqsLengths) GetQueueAttributesWithContext(ctx aws.Context, input *sqs.GetQueueAttributesInput, opts ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
+	attributes := map[string]*string{}
+	for _, name := range input.AttributeNames {
+		attributes[*name] = aws.String("0")
+	}
+	attributes["ApproximateNumberOfMessages"] = aws.String(m.lengths[*input.QueueUrl])
+	return &sqs.GetQueueAttributesOutput{Attributes: attributes}, nil
+}
+
+func TestAWSSQSScalerGetMetricsWithDeadLetterQueue(t *testing.T) {
+	client := &mockSqsLengths{lengths: map[string]string{}}
+	scaler := awsSqsQueueScaler{
+		metadata:  &awsSqsQueueMetadata{queueURL: testAWSSQSProperQueueURL, deadLetterQueueURL: testAWSSQSDeadLetterQueueURL},
+		sqsClient: client,
+	}
+	getLength := func(queueLength, deadLetterLength string) int64 {
+		client.lengths[testAWSSQSProperQueueURL] = queueLength
+		client.lengths[testAWSSQSDeadLetterQueueURL] = deadLetterLength
+		metrics, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+		assert.NoError(t, err)
+		return metrics[0].Value.Value()
+	}
+
+	assert.Equal(t, int64(100), getLength("100", "0"))
+	assert.Equal(t, int64(150), getLength("150", "0"))
+	// the messages go to the DLQ, the length is held
+	assert.Equal(t, int64(150), getLength("200", "40"))
+	assert.Equal(t, int64(150), getLength("250", "90"))
+	// the queue drains faster than the DLQ grows
+	assert.Equal(t, int64(120), getLength("120", "100"))
+	// the DLQ stops growing
+	assert.Equal(t, int64(200), getLength("200", "100"))
+
+	client.lengths[testAWSSQSDeadLetterQueueURL] = "NotInt"
+	_, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+	assert.Error(t, err)
+}
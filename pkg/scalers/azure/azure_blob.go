@@ -18,6 +18,7 @@ package azure
 
 import (
 	"context"
+	"time"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
 
@@ -25,8 +26,9 @@ import (
 	"github.com/kedacore/keda/v2/pkg/util"
 )
 
-// GetAzureBlobListLength returns the count of the blobs in blob container in int
-func GetAzureBlobListLength(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.PodIdentityProvider, connectionString, blobContainerName string, accountName string, blobDelimiter string, blobPrefix string, endpointSuffix string) (int, error) {
+// GetAzureBlobListLength returns the count of the blobs in blob container in int, only the blobs
+// last modified more than minBlobAge ago are counted. All the pages of the listing are traversed.
+func GetAzureBlobListLength(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.PodIdentityProvider, connectionString, blobContainerName string, accountName string, blobDelimiter string, blobPrefix string, endpointSuffix string, minBlobAge time.Duration) (int, error) {
 	credential, endpoint, err := ParseAzureStorageBlobConnection(ctx, httpClient, podIdentity, connectionString, accountName, endpointSuffix)
 	if err != nil {
 		return -1, err
@@ -34,15 +36,32 @@ func GetAzureBlobListLength(ctx context.Context, httpClient util.HTTPDoer, podId
 
 	listBlobsSegmentOptions := azblob.ListBlobsSegmentOptions{
 		Prefix: blobPrefix,
+		// the maximum page size of the service, to traverse large containers with few requests
+		MaxResults: 5000,
 	}
 	p := azblob.NewPipeline(credential, azblob.PipelineOptions{})
 	serviceURL := azblob.NewServiceURL(*endpoint, p)
 	containerURL := serviceURL.NewContainerURL(blobContainerName)
 
-	props, err := containerURL.ListBlobsHierarchySegment(ctx, azblob.Marker{}, blobDelimiter, listBlobsSegmentOptions)
-	if err != nil {
-		return -1, err
+	modifiedBefore := time.Now().Add(-minBlobAge)
+	count := 0
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		props, err := containerURL.ListBlobsHierarchySegment(ctx, marker, blobDelimiter, listBlobsSegmentOptions)
+		if err != nil {
+			return -1, err
+		}
+
+		if minBlobAge <= 0 {
+			count += len(props.Segment.BlobItems)
+		} else {
+			for _, blob := range props.Segment.BlobItems {
+				if blob.Properties.LastModified.Before(modifiedBefore) {
+					count++
+				}
+			}
+		}
+		marker = props.NextMarker
 	}
 
-	return len(props.Segment.BlobItems), nil
+	return count, nil
 }
@@ -32,6 +32,28 @@ import (
 	"github.com/kedacore/keda/v2/pkg/util"
 )
 
+// Checkpoint strategies, each one reads the checkpoints of a consumer library
+const (
+	// CheckpointStrategyDefault reads the JSON checkpoints of the Event Processor Host SDKs in BlobContainer
+	CheckpointStrategyDefault = ""
+	// CheckpointStrategyAzureFunction reads the JSON checkpoints of Azure Functions with the Event Hubs extension v1-v4
+	CheckpointStrategyAzureFunction = "azureFunction"
+	// CheckpointStrategyAzureFunctionV1 is an alias of CheckpointStrategyAzureFunction
+	CheckpointStrategyAzureFunctionV1 = "azureFunctionV1"
+	// CheckpointStrategyAzureFunctionV2 reads the checkpoints of Azure Functions with the Event Hubs extension v5+,
+	// they are stored in the blob metadata like the checkpoints of the azure-sdk blob checkpoint store
+	CheckpointStrategyAzureFunctionV2 = "azureFunctionV2"
+	// CheckpointStrategyBlobMetadata reads the checkpoints of the azure-sdk blob checkpoint store in BlobContainer
+	CheckpointStrategyBlobMetadata = "blobMetadata"
+	// CheckpointStrategyGoSdk reads the checkpoints of the Go SDK in BlobContainer
+	CheckpointStrategyGoSdk = "goSdk"
+
+	azureFunctionContainerName = "azure-webjobs-eventhub"
+)
+
+// CheckpointStrategies lists the supported strategies
+var CheckpointStrategies = []string{CheckpointStrategyDefault, CheckpointStrategyAzureFunction, CheckpointStrategyAzureFunctionV1, CheckpointStrategyAzureFunctionV2, CheckpointStrategyBlobMetadata, CheckpointStrategyGoSdk}
+
 // goCheckpoint struct to adapt goSdk Checkpoint
 type goCheckpoint struct {
 	Checkpoint struct {
@@ -95,19 +117,28 @@ func GetCheckpointFromBlobStorage(ctx context.Context, httpClient util.HTTPDoer,
 
 func newCheckpointer(info EventHubInfo, partitionID string) checkpointer {
 	switch {
-	case (info.CheckpointStrategy == "goSdk"):
+	case (info.CheckpointStrategy == CheckpointStrategyGoSdk):
 		return &goSdkCheckpointer{
 			containerName: info.BlobContainer,
 			partitionID:   partitionID,
 		}
-	case (info.CheckpointStrategy == "blobMetadata"):
+	case (info.CheckpointStrategy == CheckpointStrategyBlobMetadata):
 		return &blobMetadataCheckpointer{
 			containerName: info.BlobContainer,
 			partitionID:   partitionID,
 		}
-	case (info.CheckpointStrategy == "azureFunction" || info.BlobContainer == ""):
+	case (info.CheckpointStrategy == CheckpointStrategyAzureFunctionV2):
+		containerName := info.BlobContainer
+		if containerName == "" {
+			containerName = azureFunctionContainerName
+		}
+		return &blobMetadataCheckpointer{
+			containerName: containerName,
+			partitionID:   partitionID,
+		}
+	case (info.CheckpointStrategy == CheckpointStrategyAzureFunction || info.CheckpointStrategy == CheckpointStrategyAzureFunctionV1 || info.BlobContainer == ""):
 		return &azureFunctionCheckpointer{
-			containerName: "azure-webjobs-eventhub",
+			containerName: azureFunctionContainerName,
 			partitionID:   partitionID,
 		}
 	default:
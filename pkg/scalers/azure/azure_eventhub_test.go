@@ -326,3 +326,16 @@ func createNewCheckpointInStorage(urlPath string, containerName string, partitio
 	}
 	return ctx, nil
 }
+
+func TestShouldParseCheckpointForFunctionV2(t *testing.T) {
+	eventHubInfo := EventHubInfo{
+		EventHubConnection:    "Endpoint=sb://eventhubnamespace.servicebus.windows.net/;EntityPath=hub-test",
+		EventHubConsumerGroup: "$Default",
+		CheckpointStrategy:    "azureFunctionV2",
+	}
+
+	cp := newCheckpointer(eventHubInfo, "0")
+	url, _ := cp.resolvePath(eventHubInfo)
+
+	assert.Equal(t, url.Path, "/azure-webjobs-eventhub/eventhubnamespace.servicebus.windows.net/hub-test/$default/checkpoint/0")
+}
@@ -19,7 +19,6 @@ package azure
 import (
 	"context"
 	"fmt"
-	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -64,8 +63,8 @@ type MonitorInfo struct {
 
 var azureMonitorLog = logf.Log.WithName("azure_monitor_scaler")
 
-// GetAzureMetricValue returns the value of an Azure Monitor metric, rounded to the nearest int
-func GetAzureMetricValue(ctx context.Context, info MonitorInfo, podIdentity kedav1alpha1.PodIdentityProvider) (int32, error) {
+// GetAzureMetricValue returns the value of an Azure Monitor metric
+func GetAzureMetricValue(ctx context.Context, info MonitorInfo, podIdentity kedav1alpha1.PodIdentityProvider) (float64, error) {
 	var podIdentityEnabled = true
 
 	if podIdentity == "" || podIdentity == kedav1alpha1.PodIdentityProviderNone {
@@ -121,16 +120,13 @@ func createMetricsRequest(info MonitorInfo) (*azureExternalMetricRequest, error)
 	return &metricRequest, nil
 }
 
-func executeRequest(ctx context.Context, client insights.MetricsClient, request *azureExternalMetricRequest) (int32, error) {
+func executeRequest(ctx context.Context, client insights.MetricsClient, request *azureExternalMetricRequest) (float64, error) {
 	metricResponse, err := getAzureMetric(ctx, client, *request)
 	if err != nil {
 		return -1, fmt.Errorf("error getting azure monitor metric %s: %w", request.MetricName, err)
 	}
 
-	// casting drops everything after decimal, so round first
-	metricValue := int32(math.Round(metricResponse))
-
-	return metricValue, nil
+	return metricResponse, nil
 }
 
 func getAzureMetric(ctx context.Context, client insights.MetricsClient, azMetricRequest azureExternalMetricRequest) (float64, error) {
@@ -25,8 +25,9 @@ import (
 	"github.com/kedacore/keda/v2/pkg/util"
 )
 
-// GetAzureQueueLength returns the length of a queue in int
-func GetAzureQueueLength(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.PodIdentityProvider, connectionString, queueName, accountName, endpointSuffix string) (int32, error) {
+// GetAzureQueueLength returns the length of a queue in int, the poison messages dequeued more than
+// maxDequeueCount times are left out of it when maxDequeueCount is above 0
+func GetAzureQueueLength(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.PodIdentityProvider, connectionString, queueName, accountName, endpointSuffix string, maxDequeueCount int64) (int32, error) {
 	credential, endpoint, err := ParseAzureStorageQueueConnection(ctx, httpClient, podIdentity, connectionString, accountName, endpointSuffix)
 	if err != nil {
 		return -1, err
@@ -40,25 +41,35 @@ func GetAzureQueueLength(ctx context.Context, httpClient util.HTTPDoer, podIdent
 		return -1, err
 	}
 
-	visibleMessageCount, err := getVisibleCount(ctx, &queueURL, 32)
+	visibleMessageCount, poisonMessageCount, err := getVisibleCount(ctx, &queueURL, 32, maxDequeueCount)
 	if err != nil {
 		return -1, err
 	}
 	approximateMessageCount := props.ApproximateMessagesCount()
 
+	// only the peeked messages are checked for poison ones, the rest of a long queue is counted
 	if visibleMessageCount == 32 {
-		return approximateMessageCount, nil
+		return approximateMessageCount - poisonMessageCount, nil
 	}
 
-	return visibleMessageCount, nil
+	return visibleMessageCount - poisonMessageCount, nil
 }
 
-func getVisibleCount(ctx context.Context, queueURL *azqueue.QueueURL, maxCount int32) (int32, error) {
+func getVisibleCount(ctx context.Context, queueURL *azqueue.QueueURL, maxCount int32, maxDequeueCount int64) (int32, int32, error) {
 	messagesURL := queueURL.NewMessagesURL()
 	queue, err := messagesURL.Peek(ctx, maxCount)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	num := queue.NumMessages()
-	return num, nil
+
+	var poison int32
+	if maxDequeueCount > 0 {
+		for i := int32(0); i < num; i++ {
+			if queue.Message(i).DequeueCount > maxDequeueCount {
+				poison++
+			}
+		}
+	}
+	return num, poison, nil
 }
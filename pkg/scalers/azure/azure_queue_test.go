@@ -2,13 +2,17 @@ package azure
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetQueueLength(t *testing.T) {
-	length, err := GetAzureQueueLength(context.TODO(), http.DefaultClient, "", "", "queueName", "", "")
+	length, err := GetAzureQueueLength(context.TODO(), http.DefaultClient, "", "", "queueName", "", "", 0)
 	if length != -1 {
 		t.Error("Expected length to be -1, but got", length)
 	}
@@ -21,7 +25,7 @@ func TestGetQueueLength(t *testing.T) {
 		t.Error("Expected error to contain parsing error message, but got", err.Error())
 	}
 
-	length, err = GetAzureQueueLength(context.TODO(), http.DefaultClient, "", "DefaultEndpointsProtocol=https;AccountName=name;AccountKey=key==;EndpointSuffix=core.windows.net", "queueName", "", "")
+	length, err = GetAzureQueueLength(context.TODO(), http.DefaultClient, "", "DefaultEndpointsProtocol=https;AccountName=name;AccountKey=key==;EndpointSuffix=core.windows.net", "queueName", "", "", 0)
 
 	if length != -1 {
 		t.Error("Expected length to be -1, but got", length)
@@ -35,3 +39,28 @@ func TestGetQueueLength(t *testing.T) {
 		t.Error("Expected error to contain base64 error message, but got", err.Error())
 	}
 }
+
+func TestGetQueueLengthWithoutPoisonMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("peekonly") {
+		case "true":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><QueueMessagesList>`+
+				`<QueueMessage><MessageId>1</MessageId><DequeueCount>1</DequeueCount><MessageText>a</MessageText></QueueMessage>`+
+				`<QueueMessage><MessageId>2</MessageId><DequeueCount>9</DequeueCount><MessageText>b</MessageText></QueueMessage>`+
+				`<QueueMessage><MessageId>3</MessageId><DequeueCount>6</DequeueCount><MessageText>c</MessageText></QueueMessage>`+
+				`</QueueMessagesList>`)
+		default:
+			w.Header().Set("x-ms-approximate-messages-count", "3")
+		}
+	}))
+	defer server.Close()
+
+	connection := fmt.Sprintf("QueueEndpoint=%s/name;AccountName=name;AccountKey=a2V5", server.URL)
+	length, err := GetAzureQueueLength(context.TODO(), http.DefaultClient, "", connection, "queueName", "", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), length)
+
+	length, err = GetAzureQueueLength(context.TODO(), http.DefaultClient, "", connection, "queueName", "", "", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), length)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -56,7 +57,9 @@ type azureBlobMetadata struct {
 	accountName       string
 	metricName        string
 	endpointSuffix    string
-	scalerIndex       int
+	// minBlobAge excludes the blobs modified more recently, eg. blobs still being uploaded
+	minBlobAge  time.Duration
+	scalerIndex int
 }
 
 var azureBlobLog = logf.Log.WithName("azure_blob_scaler")
@@ -71,7 +74,7 @@ func NewAzureBlobScaler(config *ScalerConfig) (Scaler, error) {
 	return &azureBlobScaler{
 		metadata:    meta,
 		podIdentity: podIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  createHTTPClient(config, config.GlobalHTTPTimeout, false),
 	}, nil
 }
 
@@ -105,6 +108,14 @@ func parseAzureBlobMetadata(config *ScalerConfig) (*azureBlobMetadata, kedav1alp
 		meta.blobPrefix = val + meta.blobDelimiter
 	}
 
+	if val, ok := config.TriggerMetadata["minBlobAge"]; ok && val != "" {
+		minBlobAge, err := parseUnitValue(val, unitSeconds)
+		if err != nil || minBlobAge < 0 {
+			return nil, "", fmt.Errorf("error parsing azure blob metadata minBlobAge: %s must be a positive number of seconds or a duration", val)
+		}
+		meta.minBlobAge = time.Duration(minBlobAge * float64(time.Second))
+	}
+
 	endpointSuffix, err := azure.ParseAzureStorageEndpointSuffix(config.TriggerMetadata, azure.BlobEndpoint)
 	if err != nil {
 		return nil, "", err
@@ -166,6 +177,7 @@ func (s *azureBlobScaler) IsActive(ctx context.Context) (bool, error) {
 		s.metadata.blobDelimiter,
 		s.metadata.blobPrefix,
 		s.metadata.endpointSuffix,
+		s.metadata.minBlobAge,
 	)
 
 	if err != nil {
@@ -177,6 +189,7 @@ func (s *azureBlobScaler) IsActive(ctx context.Context) (bool, error) {
 }
 
 func (s *azureBlobScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
@@ -207,6 +220,7 @@ func (s *azureBlobScaler) GetMetrics(ctx context.Context, metricName string, met
 		s.metadata.blobDelimiter,
 		s.metadata.blobPrefix,
 		s.metadata.endpointSuffix,
+		s.metadata.minBlobAge,
 	)
 
 	if err != nil {
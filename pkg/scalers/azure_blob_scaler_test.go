@@ -71,6 +71,14 @@ var testAzBlobMetadata = []parseAzBlobMetadataTestData{
 	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "", "endpointSuffix": "ignored"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// connection from authParams
 	{map[string]string{"blobContainerName": "sample_container", "blobCount": "5"}, false, testAzBlobResolvedEnv, map[string]string{"connection": "value"}, kedav1alpha1.PodIdentityProviderNone},
+	// minBlobAge
+	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "minBlobAge": "300"}, false, testAzBlobResolvedEnv, map[string]string{}, ""},
+	// minBlobAge duration
+	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "minBlobAge": "5m"}, false, testAzBlobResolvedEnv, map[string]string{}, ""},
+	// improperly formed minBlobAge
+	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "minBlobAge": "5 minutes"}, true, testAzBlobResolvedEnv, map[string]string{}, ""},
+	// negative minBlobAge
+	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "minBlobAge": "-5m"}, true, testAzBlobResolvedEnv, map[string]string{}, ""},
 }
 
 var azBlobMetricIdentifiers = []azBlobMetricIdentifier{
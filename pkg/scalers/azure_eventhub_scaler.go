@@ -26,12 +26,12 @@ import (
 
 	eventhub "github.com/Azure/azure-event-hubs-go/v3"
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/go-logr/logr"
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/scalers/azure"
@@ -45,20 +45,25 @@ const (
 	defaultEventHubConsumerGroup    = "$Default"
 	defaultBlobContainer            = ""
 	defaultCheckpointStrategy       = ""
-)
 
-var eventhubLog = logf.Log.WithName("azure_eventhub_scaler")
+	// the consumer starts from the first retained event of a partition without checkpoint
+	noCheckpointEarliest = "earliest"
+	// the consumer starts from the next enqueued event of a partition without checkpoint
+	noCheckpointLatest = "latest"
+)
 
 type azureEventHubScaler struct {
 	metadata   *eventHubMetadata
 	client     *eventhub.Hub
 	httpClient *http.Client
+	logger     logr.Logger
 }
 
 type eventHubMetadata struct {
-	eventHubInfo azure.EventHubInfo
-	threshold    int64
-	scalerIndex  int
+	eventHubInfo       azure.EventHubInfo
+	threshold          int64
+	noCheckpointPolicy string
+	scalerIndex        int
 }
 
 // NewAzureEventHubScaler creates a new scaler for eventHub
@@ -76,7 +81,8 @@ func NewAzureEventHubScaler(config *ScalerConfig) (Scaler, error) {
 	return &azureEventHubScaler{
 		metadata:   parsedMetadata,
 		client:     hub,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, false),
+		logger:     InitializeLogger(config, "azure_eventhub_scaler"),
 	}, nil
 }
 
@@ -113,9 +119,25 @@ func parseAzureEventHubMetadata(config *ScalerConfig) (*eventHubMetadata, error)
 
 	meta.eventHubInfo.CheckpointStrategy = defaultCheckpointStrategy
 	if val, ok := config.TriggerMetadata["checkpointStrategy"]; ok {
+		// an unknown checkpoint format would be read as no checkpoint and report a wrong lag
+		supported := false
+		for _, strategy := range azure.CheckpointStrategies {
+			supported = supported || val == strategy
+		}
+		if !supported {
+			return nil, fmt.Errorf("checkpointStrategy %s is not supported", val)
+		}
 		meta.eventHubInfo.CheckpointStrategy = val
 	}
 
+	meta.noCheckpointPolicy = noCheckpointEarliest
+	if val, ok := config.TriggerMetadata["noCheckpointPolicy"]; ok && val != "" {
+		if val != noCheckpointEarliest && val != noCheckpointLatest {
+			return nil, fmt.Errorf("noCheckpointPolicy must be %s or %s", noCheckpointEarliest, noCheckpointLatest)
+		}
+		meta.noCheckpointPolicy = val
+	}
+
 	meta.eventHubInfo.BlobContainer = defaultBlobContainer
 	if val, ok := config.TriggerMetadata["blobContainer"]; ok {
 		meta.eventHubInfo.BlobContainer = val
@@ -171,6 +193,9 @@ func (scaler *azureEventHubScaler) GetUnprocessedEventCountInPartition(ctx conte
 		err = errors.Unwrap(err)
 		if stErr, ok := err.(azblob.StorageError); ok {
 			if stErr.ServiceCode() == azblob.ServiceCodeBlobNotFound || stErr.ServiceCode() == azblob.ServiceCodeContainerNotFound {
+				if scaler.metadata.noCheckpointPolicy == noCheckpointLatest {
+					return 0, azure.Checkpoint{}, nil
+				}
 				return GetUnprocessedEventCountWithoutCheckpoint(partitionInfo), azure.Checkpoint{}, nil
 			}
 		}
@@ -222,7 +247,7 @@ func GetUnprocessedEventCountWithoutCheckpoint(partitionInfo *eventhub.HubPartit
 func (scaler *azureEventHubScaler) IsActive(ctx context.Context) (bool, error) {
 	runtimeInfo, err := scaler.client.GetRuntimeInformation(ctx)
 	if err != nil {
-		eventhubLog.Error(err, "unable to get runtimeInfo for isActive")
+		scaler.logger.Error(err, "unable to get runtimeInfo for isActive")
 		return false, fmt.Errorf("unable to get runtimeInfo for isActive: %s", err)
 	}
 
@@ -292,14 +317,14 @@ func (scaler *azureEventHubScaler) GetMetrics(ctx context.Context, metricName st
 
 		totalUnprocessedEventCount += unprocessedEventCount
 
-		eventhubLog.V(1).Info(fmt.Sprintf("Partition ID: %s, Last Enqueued Offset: %s, Checkpoint Offset: %s, Total new events in partition: %d",
-			partitionRuntimeInfo.PartitionID, partitionRuntimeInfo.LastEnqueuedOffset, checkpoint.Offset, unprocessedEventCount))
+		scaler.logger.V(1).Info("Found the unprocessed events of a partition", "partitionID", partitionRuntimeInfo.PartitionID,
+			"lastEnqueuedOffset", partitionRuntimeInfo.LastEnqueuedOffset, "checkpointOffset", checkpoint.Offset, "unprocessedEvents", unprocessedEventCount)
 	}
 
 	// don't scale out beyond the number of partitions
 	lagRelatedToPartitionCount := getTotalLagRelatedToPartitionAmount(totalUnprocessedEventCount, int64(len(partitionIDs)), scaler.metadata.threshold)
 
-	eventhubLog.V(1).Info(fmt.Sprintf("Unprocessed events in event hub total: %d, scaling for a lag of %d related to %d partitions", totalUnprocessedEventCount, lagRelatedToPartitionCount, len(partitionIDs)))
+	scaler.logger.V(1).Info("Found the unprocessed events of the event hub", "unprocessedEvents", totalUnprocessedEventCount, "lag", lagRelatedToPartitionCount, "partitions", len(partitionIDs))
 
 	metric := external_metrics.ExternalMetricValue{
 		MetricName: metricName,
@@ -310,6 +335,16 @@ func (scaler *azureEventHubScaler) GetMetrics(ctx context.Context, metricName st
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
+// GetPartitionCount returns the number of partitions of the event hub
+func (scaler *azureEventHubScaler) GetPartitionCount(ctx context.Context) (int64, error) {
+	runtimeInfo, err := scaler.client.GetRuntimeInformation(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get runtimeInfo for partition count: %s", err)
+	}
+
+	return int64(len(runtimeInfo.PartitionIDs)), nil
+}
+
 func getTotalLagRelatedToPartitionAmount(unprocessedEventsCount int64, partitionCount int64, threshold int64) int64 {
 	if (unprocessedEventsCount / threshold) > partitionCount {
 		return partitionCount * threshold
@@ -323,10 +358,11 @@ func (scaler *azureEventHubScaler) Close(ctx context.Context) error {
 	if scaler.client != nil {
 		err := scaler.client.Close(ctx)
 		if err != nil {
-			eventhubLog.Error(err, "error closing azure event hub client")
+			scaler.logger.Error(err, "error closing azure event hub client")
 			return err
 		}
 	}
+	closeIdleConnections(scaler.httpClient)
 
 	return nil
 }
@@ -26,6 +26,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -46,8 +48,19 @@ const (
 	miEndpoint       = "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https%3A%2F%2Fapi.loganalytics.io%2F"
 	aadTokenEndpoint = "https://login.microsoftonline.com/%s/oauth2/token"
 	laQueryEndpoint  = "https://api.loganalytics.io/v1/workspaces/%s/query"
+
+	// the environment variables injected by the Azure AD workload identity webhook
+	azureClientIDEnv           = "AZURE_CLIENT_ID"
+	azureTenantIDEnv           = "AZURE_TENANT_ID"
+	azureFederatedTokenFileEnv = "AZURE_FEDERATED_TOKEN_FILE"
+	azureAuthorityHostEnv      = "AZURE_AUTHORITY_HOST"
+	defaultAzureAuthorityHost  = "https://login.microsoftonline.com/"
+	laScope                    = "https://api.loganalytics.io/.default"
 )
 
+// laTimespanRegex matches the ISO 8601 durations accepted as query timespan, eg. PT1H or P1DT12H
+var laTimespanRegex = regexp.MustCompile(`^P(\d+W|(\d+D)?(T(\d+H)?(\d+M)?(\d+S)?)?)$`)
+
 type azureLogAnalyticsScaler struct {
 	metadata   *azureLogAnalyticsMetadata
 	cache      *sessionCache
@@ -66,6 +79,10 @@ type azureLogAnalyticsMetadata struct {
 	threshold    int64
 	metricName   string // Custom metric name for trigger
 	scalerIndex  int
+	// additionalWorkspaces are queried with workspaceID, eg. to count the alerts of several regions
+	additionalWorkspaces []string
+	// timespan is an ISO 8601 duration applied by the API on top of the time filters of the query
+	timespan string
 }
 
 type sessionCache struct {
@@ -118,7 +135,7 @@ func NewAzureLogAnalyticsScaler(config *ScalerConfig) (Scaler, error) {
 		cache:      &sessionCache{metricValue: -1, metricThreshold: -1},
 		name:       config.Name,
 		namespace:  config.Namespace,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, false),
 	}, nil
 }
 
@@ -148,7 +165,7 @@ func parseAzureLogAnalyticsMetadata(config *ScalerConfig) (*azureLogAnalyticsMet
 		meta.clientSecret = clientSecret
 
 		meta.podIdentity = ""
-	case kedav1alpha1.PodIdentityProviderAzure:
+	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
 		meta.podIdentity = string(config.PodIdentity)
 	default:
 		return nil, fmt.Errorf("error parsing metadata. Details: Log Analytics Scaler doesn't support pod identity %s", config.PodIdentity)
@@ -161,6 +178,14 @@ func parseAzureLogAnalyticsMetadata(config *ScalerConfig) (*azureLogAnalyticsMet
 	}
 	meta.workspaceID = workspaceID
 
+	if val, ok := config.TriggerMetadata["additionalWorkspaces"]; ok && val != "" {
+		for _, workspace := range strings.Split(val, ",") {
+			if workspace = strings.TrimSpace(workspace); workspace != "" {
+				meta.additionalWorkspaces = append(meta.additionalWorkspaces, workspace)
+			}
+		}
+	}
+
 	// Getting query, observe that we dont check AuthParams for query
 	query, err := getParameterFromConfig(config, "query", false)
 	if err != nil {
@@ -179,6 +204,13 @@ func parseAzureLogAnalyticsMetadata(config *ScalerConfig) (*azureLogAnalyticsMet
 	}
 	meta.threshold = threshold
 
+	if val, ok := config.TriggerMetadata["timespan"]; ok && val != "" {
+		if val == "P" || strings.HasSuffix(val, "T") || !laTimespanRegex.MatchString(val) {
+			return nil, fmt.Errorf("error parsing metadata. Details: timespan %s is not an ISO 8601 duration, eg. PT1H", val)
+		}
+		meta.timespan = val
+	}
+
 	// Resolve metricName
 	if val, ok := config.TriggerMetadata["metricName"]; ok {
 		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("%s-%s", "azure-log-analytics", val))
@@ -254,6 +286,7 @@ func (s *azureLogAnalyticsScaler) GetMetrics(ctx context.Context, metricName str
 }
 
 func (s *azureLogAnalyticsScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
@@ -459,9 +492,12 @@ func (s *azureLogAnalyticsScaler) getAuthorizationToken(ctx context.Context) (to
 	var err error
 	var tokenInfo tokenData
 
-	if s.metadata.podIdentity == "" {
+	switch s.metadata.podIdentity {
+	case "":
 		body, statusCode, err = s.executeAADApicall(ctx)
-	} else {
+	case string(kedav1alpha1.PodIdentityProviderAzureWorkload):
+		return s.getWorkloadIdentityToken(ctx)
+	default:
 		body, statusCode, err = s.executeIMDSApicall(ctx)
 	}
 
@@ -483,8 +519,73 @@ func (s *azureLogAnalyticsScaler) getAuthorizationToken(ctx context.Context) (to
 	return tokenData{}, fmt.Errorf("error getting access token. Details: unknown error. HTTP code: %d. Body: %s", statusCode, string(body))
 }
 
+// getWorkloadIdentityToken exchanges the service account token projected by the workload identity webhook
+// for an AAD token, the v2 endpoint returns expires_in as a number instead of the string of the v1 endpoint
+func (s *azureLogAnalyticsScaler) getWorkloadIdentityToken(ctx context.Context) (tokenData, error) {
+	tokenFile := os.Getenv(azureFederatedTokenFileEnv)
+	if tokenFile == "" {
+		return tokenData{}, fmt.Errorf("error getting access token. Details: %s is not set, check the workload identity of KEDA", azureFederatedTokenFileEnv)
+	}
+	assertion, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return tokenData{}, fmt.Errorf("error getting access token. Details: can't read the federated token. Inner Error: %v", err)
+	}
+
+	authorityHost := os.Getenv(azureAuthorityHostEnv)
+	if authorityHost == "" {
+		authorityHost = defaultAzureAuthorityHost
+	}
+
+	data := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {os.Getenv(azureClientIDEnv)},
+		"scope":                 {laScope},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(authorityHost, "/"), os.Getenv(azureTenantIDEnv))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return tokenData{}, fmt.Errorf("can't construct HTTP request to Azure Active Directory. Inner Error: %v", err)
+	}
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	body, statusCode, err := s.runHTTP(request, "AAD")
+	if err != nil {
+		return tokenData{}, fmt.Errorf("error getting access token. HTTP code: %d. Inner Error: %v. Body: %s", statusCode, err, string(body))
+	}
+	if statusCode != 200 {
+		return tokenData{}, fmt.Errorf("error getting access token. Details: unknown error. HTTP code: %d. Body: %s", statusCode, string(body))
+	}
+
+	var token struct {
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return tokenData{}, fmt.Errorf("error getting access token. Details: can't decode response body to JSON after getting access token. HTTP code: %d. Inner Error: %v. Body: %s", statusCode, err, string(body))
+	}
+
+	now := time.Now().Unix()
+	return tokenData{
+		TokenType:   token.TokenType,
+		ExpiresIn:   int(token.ExpiresIn),
+		ExpiresOn:   now + token.ExpiresIn,
+		NotBefore:   now,
+		Resource:    laScope,
+		AccessToken: token.AccessToken,
+	}, nil
+}
+
 func (s *azureLogAnalyticsScaler) executeLogAnalyticsREST(ctx context.Context, query string, tokenInfo tokenData) ([]byte, int, error) {
 	m := map[string]interface{}{"query": query}
+	if len(s.metadata.additionalWorkspaces) > 0 {
+		m["workspaces"] = s.metadata.additionalWorkspaces
+	}
+	if s.metadata.timespan != "" {
+		m["timespan"] = s.metadata.timespan
+	}
 
 	jsonBytes, err := json.Marshal(m)
 	if err != nil {
@@ -18,7 +18,12 @@ package scalers
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
@@ -89,6 +94,12 @@ var testLogAnalyticsMetadata = []parseLogAnalyticsMetadataTestData{
 	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000"}, false},
 	// All parameters set, should succeed
 	{map[string]string{"tenantIdFromEnv": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientIdFromEnv": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecretFromEnv": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceIdFromEnv": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000"}, false},
+	// Cross-workspace query with timespan, should succeed
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "additionalWorkspaces": "ws-westeurope, ws-eastus", "query": query, "threshold": "1900000000", "timespan": "P1DT12H"}, false},
+	// Malformed timespan, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "timespan": "1h"}, true},
+	// Empty timespan duration, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "timespan": "PT"}, true},
 }
 
 var LogAnalyticsMetricIdentifiers = []LogAnalyticsMetricIdentifier{
@@ -158,6 +169,17 @@ func TestLogAnalyticsParseMetadata(t *testing.T) {
 			t.Error("Expected error but got success")
 		}
 	}
+
+	// test with workload identity params should not fail
+	for _, testData := range testLogAnalyticsMetadataWithPodIdentity {
+		_, err := parseAzureLogAnalyticsMetadata(&ScalerConfig{ResolvedEnv: sampleLogAnalyticsResolvedEnv, TriggerMetadata: testData.metadata, AuthParams: LogAnalyticsAuthParams, PodIdentity: kedav1alpha1.PodIdentityProviderAzureWorkload})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
 }
 
 func TestLogAnalyticsGetMetricSpecForScaling(t *testing.T) {
@@ -207,3 +229,49 @@ func TestLogAnalyticsParseMetadataMetricName(t *testing.T) {
 		}
 	}
 }
+
+func TestLogAnalyticsWorkloadIdentityToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("federated-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+tenantID+"/oauth2/v2.0/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("client_assertion") != "federated-token" || r.PostForm.Get("client_id") != clientID {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token_type":"Bearer","expires_in":3599,"access_token":"token"}`)
+	}))
+	defer server.Close()
+
+	env := map[string]string{
+		azureClientIDEnv:           clientID,
+		azureTenantIDEnv:           tenantID,
+		azureFederatedTokenFileEnv: tokenFile,
+		azureAuthorityHostEnv:      server.URL + "/",
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	scaler := azureLogAnalyticsScaler{
+		metadata:   &azureLogAnalyticsMetadata{podIdentity: string(kedav1alpha1.PodIdentityProviderAzureWorkload)},
+		httpClient: http.DefaultClient,
+	}
+	token, err := scaler.getAuthorizationToken(context.TODO())
+	if err != nil {
+		t.Fatal("Expected success but got error", err)
+	}
+	if token.AccessToken != "token" {
+		t.Errorf("Expected token but got %s", token.AccessToken)
+	}
+	if token.ExpiresOn <= token.NotBefore {
+		t.Errorf("Expected the token to expire after %d but got %d", token.NotBefore, token.ExpiresOn)
+	}
+}
@@ -19,12 +19,9 @@ package scalers
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -46,7 +43,7 @@ type azureMonitorScaler struct {
 
 type azureMonitorMetadata struct {
 	azureMonitorInfo azure.MonitorInfo
-	targetValue      int
+	targetValue      float64
 	scalerIndex      int
 }
 
@@ -70,16 +67,11 @@ func parseAzureMonitorMetadata(config *ScalerConfig) (*azureMonitorMetadata, err
 		azureMonitorInfo: azure.MonitorInfo{},
 	}
 
-	if val, ok := config.TriggerMetadata[targetValueName]; ok && val != "" {
-		targetValue, err := strconv.Atoi(val)
-		if err != nil {
-			azureMonitorLog.Error(err, "Error parsing azure monitor metadata", "targetValue", targetValueName)
-			return nil, fmt.Errorf("error parsing azure monitor metadata %s: %s", targetValueName, err.Error())
-		}
-		meta.targetValue = targetValue
-	} else {
-		return nil, fmt.Errorf("no targetValue given")
+	targetValue, err := getFloatMetadataValue(config.TriggerMetadata, targetValueName, true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.targetValue = targetValue
 
 	if val, ok := config.TriggerMetadata["resourceURI"]; ok && val != "" {
 		resourceURI := strings.Split(val, "/")
@@ -191,15 +183,11 @@ func (s *azureMonitorScaler) Close(context.Context) error {
 }
 
 func (s *azureMonitorScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricVal := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-monitor-%s", s.metadata.azureMonitorInfo.Name))),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricVal,
-		},
+		Target: GetMetricTargetMili(s.metadata.targetValue),
 	}
 	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
 	return []v2beta2.MetricSpec{metricSpec}
@@ -213,11 +201,7 @@ func (s *azureMonitorScaler) GetMetrics(ctx context.Context, metricName string,
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, val)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
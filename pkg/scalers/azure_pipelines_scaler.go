@@ -45,7 +45,7 @@ func NewAzurePipelinesScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing azure Pipelines metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
 
 	return &azurePipelinesScaler{
 		metadata:   meta,
@@ -192,5 +192,6 @@ func (s *azurePipelinesScaler) IsActive(ctx context.Context) (bool, error) {
 }
 
 func (s *azurePipelinesScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
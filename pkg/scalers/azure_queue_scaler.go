@@ -53,6 +53,7 @@ type azureQueueMetadata struct {
 	connection        string
 	accountName       string
 	endpointSuffix    string
+	maxDequeueCount   int64
 	scalerIndex       int
 }
 
@@ -68,7 +69,7 @@ func NewAzureQueueScaler(config *ScalerConfig) (Scaler, error) {
 	return &azureQueueScaler{
 		metadata:    meta,
 		podIdentity: podIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  createHTTPClient(config, config.GlobalHTTPTimeout, false),
 	}, nil
 }
 
@@ -86,6 +87,14 @@ func parseAzureQueueMetadata(config *ScalerConfig) (*azureQueueMetadata, kedav1a
 		meta.targetQueueLength = queueLength
 	}
 
+	if val, ok := config.TriggerMetadata["maxDequeueCount"]; ok && val != "" {
+		maxDequeueCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || maxDequeueCount < 1 {
+			return nil, "", fmt.Errorf("maxDequeueCount must be a positive integer: %s", val)
+		}
+		meta.maxDequeueCount = maxDequeueCount
+	}
+
 	endpointSuffix, err := azure.ParseAzureStorageEndpointSuffix(config.TriggerMetadata, azure.QueueEndpoint)
 	if err != nil {
 		return nil, "", err
@@ -148,6 +157,7 @@ func (s *azureQueueScaler) IsActive(ctx context.Context) (bool, error) {
 		s.metadata.queueName,
 		s.metadata.accountName,
 		s.metadata.endpointSuffix,
+		s.metadata.maxDequeueCount,
 	)
 
 	if err != nil {
@@ -159,6 +169,7 @@ func (s *azureQueueScaler) IsActive(ctx context.Context) (bool, error) {
 }
 
 func (s *azureQueueScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
@@ -187,6 +198,7 @@ func (s *azureQueueScaler) GetMetrics(ctx context.Context, metricName string, me
 		s.metadata.queueName,
 		s.metadata.accountName,
 		s.metadata.endpointSuffix,
+		s.metadata.maxDequeueCount,
 	)
 
 	if err != nil {
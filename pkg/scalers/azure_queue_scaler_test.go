@@ -75,6 +75,12 @@ var testAzQueueMetadata = []parseAzQueueMetadataTestData{
 	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "", "endpointSuffix": "ignored"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// connection from authParams
 	{map[string]string{"queueName": "sample", "queueLength": "5"}, false, testAzQueueResolvedEnv, map[string]string{"connection": "value"}, kedav1alpha1.PodIdentityProviderNone},
+	// maxDequeueCount
+	{map[string]string{"connectionFromEnv": "CONNECTION", "queueName": "sample", "maxDequeueCount": "5"}, false, testAzQueueResolvedEnv, map[string]string{}, ""},
+	// improperly formed maxDequeueCount
+	{map[string]string{"connectionFromEnv": "CONNECTION", "queueName": "sample", "maxDequeueCount": "AA"}, true, testAzQueueResolvedEnv, map[string]string{}, ""},
+	// maxDequeueCount below 1
+	{map[string]string{"connectionFromEnv": "CONNECTION", "queueName": "sample", "maxDequeueCount": "0"}, true, testAzQueueResolvedEnv, map[string]string{}, ""},
 }
 
 var azQueueMetricIdentifiers = []azQueueMetricIdentifier{
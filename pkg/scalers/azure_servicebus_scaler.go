@@ -18,9 +18,11 @@ limitations under the License.
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-amqp-common-go/v3/auth"
 	servicebus "github.com/Azure/azure-service-bus-go"
@@ -40,11 +42,12 @@ import (
 type entityType int
 
 const (
-	none                      entityType = 0
-	queue                     entityType = 1
-	subscription              entityType = 2
-	messageCountMetricName               = "messageCount"
-	defaultTargetMessageCount            = 5
+	none                       entityType = 0
+	queue                      entityType = 1
+	subscription               entityType = 2
+	messageCountMetricName                = "messageCount"
+	defaultTargetMessageCount             = 5
+	defaultServiceBusPeekLimit            = 1000
 )
 
 var azureServiceBusLog = logf.Log.WithName("azure_servicebus_scaler")
@@ -65,7 +68,16 @@ type azureServiceBusMetadata struct {
 	entityType       entityType
 	namespace        string
 	endpointSuffix   string
-	scalerIndex      int
+	// correlationFilter counts only the messages of the subscription matching it, it is given in the metadata or
+	// read from the ruleName rule of the subscription, the messages are peeked up to peekLimit
+	correlationFilter *servicebus.CorrelationFilter
+	ruleName          string
+	peekLimit         int
+	// deadLetterTargetLength is the target of the metric of the dead-letter messages, 0 doesn't report it
+	deadLetterTargetLength int
+	// activationListener is notified by an Event Grid subscription of the namespace, nil if activationNotificationKey isn't set
+	activationListener *notificationListener
+	scalerIndex        int
 }
 
 // NewAzureServiceBusScaler creates a new AzureServiceBusScaler
@@ -79,7 +91,7 @@ func NewAzureServiceBusScaler(ctx context.Context, config *ScalerConfig) (Scaler
 		ctx:         ctx,
 		metadata:    meta,
 		podIdentity: config.PodIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  createHTTPClient(config, config.GlobalHTTPTimeout, false),
 	}, nil
 }
 
@@ -123,6 +135,10 @@ func parseAzureServiceBusMetadata(config *ScalerConfig) (*azureServiceBusMetadat
 		}
 	}
 
+	if err := parseServiceBusFilterMetadata(config, &meta); err != nil {
+		return nil, err
+	}
+
 	envSuffixProvider := func(env az.Environment) (string, error) {
 		return env.ServiceBusEndpointSuffix, nil
 	}
@@ -158,24 +174,122 @@ func parseAzureServiceBusMetadata(config *ScalerConfig) (*azureServiceBusMetadat
 		return nil, fmt.Errorf("azure service bus doesn't support pod identity %s", config.PodIdentity)
 	}
 
+	if meta.activationListener, err = parseNotificationListener(config); err != nil {
+		return nil, err
+	}
 	meta.scalerIndex = config.ScalerIndex
 
 	return &meta, nil
 }
 
-// Returns true if the scaler's queue has messages in it, false otherwise
+// parseServiceBusFilterMetadata parses the correlationFilter, ruleName, peekLimit and deadLetterMessageCount metadata
+func parseServiceBusFilterMetadata(config *ScalerConfig, meta *azureServiceBusMetadata) error {
+	if val, ok := config.TriggerMetadata["correlationFilter"]; ok && val != "" {
+		filter, err := parseCorrelationFilter(val)
+		if err != nil {
+			return fmt.Errorf("error parsing correlationFilter: %s", err)
+		}
+		meta.correlationFilter = filter
+	}
+	meta.ruleName = config.TriggerMetadata["ruleName"]
+	if meta.correlationFilter != nil || meta.ruleName != "" {
+		if meta.entityType != subscription {
+			return fmt.Errorf("correlationFilter and ruleName require a topic subscription")
+		}
+		if meta.correlationFilter != nil && meta.ruleName != "" {
+			return fmt.Errorf("correlationFilter and ruleName can't be given together")
+		}
+	}
+
+	peekLimit, err := getIntMetadataValue(config.TriggerMetadata, "peekLimit", false, defaultServiceBusPeekLimit)
+	if err != nil {
+		return err
+	}
+	if peekLimit <= 0 {
+		return fmt.Errorf("peekLimit must be greater than 0")
+	}
+	meta.peekLimit = int(peekLimit)
+
+	deadLetterTargetLength, err := getIntMetadataValue(config.TriggerMetadata, "deadLetterMessageCount", false, 0)
+	if err != nil {
+		return err
+	}
+	if deadLetterTargetLength < 0 {
+		return fmt.Errorf("deadLetterMessageCount can't be negative")
+	}
+	meta.deadLetterTargetLength = int(deadLetterTargetLength)
+	return nil
+}
+
+// parseCorrelationFilter parses a `key=value` list into a correlation filter, the keys are the system properties
+// correlationId, messageId, to, replyTo, label, sessionId, replyToSessionId and contentType, the other keys are
+// user properties
+func parseCorrelationFilter(val string) (*servicebus.CorrelationFilter, error) {
+	filter := &servicebus.CorrelationFilter{}
+	for _, item := range strings.Split(val, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%q isn't a key=value pair", item)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "correlationId":
+			filter.CorrelationID = &value
+		case "messageId":
+			filter.MessageID = &value
+		case "to":
+			filter.To = &value
+		case "replyTo":
+			filter.ReplyTo = &value
+		case "label":
+			filter.Label = &value
+		case "sessionId":
+			filter.SessionID = &value
+		case "replyToSessionId":
+			filter.ReplyToSessionID = &value
+		case "contentType":
+			filter.ContentType = &value
+		default:
+			if filter.Properties == nil {
+				filter.Properties = map[string]interface{}{}
+			}
+			filter.Properties[key] = value
+		}
+	}
+	return filter, nil
+}
+
+// ListenActivation relays the events of the Event Grid webhook subscription pointed to the notification endpoint of
+// the operator, eg. the Microsoft.ServiceBus.ActiveMessagesAvailableWithNoListeners events of the namespace
+func (s *azureServiceBusScaler) ListenActivation(ctx context.Context, notify chan<- struct{}) error {
+	return s.metadata.activationListener.listen(ctx, notify)
+}
+
+// Returns true if the scaler's queue has messages in it, or dead-letter messages if they are reported, false otherwise
 func (s *azureServiceBusScaler) IsActive(ctx context.Context) (bool, error) {
 	length, err := s.GetAzureServiceBusLength(ctx)
 	if err != nil {
 		azureServiceBusLog.Error(err, "error")
 		return false, err
 	}
+	if length > 0 || s.metadata.deadLetterTargetLength == 0 {
+		return length > 0, nil
+	}
 
-	return length > 0, nil
+	deadLetterLength, err := s.getDeadLetterLength(ctx)
+	if err != nil {
+		azureServiceBusLog.Error(err, "error getting service bus dead-letter length")
+		return false, err
+	}
+	return deadLetterLength > 0, nil
 }
 
 // Close - nothing to close for SB
 func (s *azureServiceBusScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
@@ -199,13 +313,37 @@ func (s *azureServiceBusScaler) GetMetricSpecForScaling(context.Context) []v2bet
 			AverageValue: targetLengthQty,
 		},
 	}
-	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
-	return []v2beta2.MetricSpec{metricSpec}
+	metricSpecs := []v2beta2.MetricSpec{{External: externalMetric, Type: externalMetricType}}
+
+	if s.metadata.deadLetterTargetLength > 0 {
+		deadLetterMetric := &v2beta2.ExternalMetricSource{
+			Metric: v2beta2.MetricIdentifier{
+				Name: s.deadLetterMetricName(metricName),
+			},
+			Target: v2beta2.MetricTarget{
+				Type:         v2beta2.AverageValueMetricType,
+				AverageValue: resource.NewQuantity(int64(s.metadata.deadLetterTargetLength), resource.DecimalSI),
+			},
+		}
+		metricSpecs = append(metricSpecs, v2beta2.MetricSpec{External: deadLetterMetric, Type: externalMetricType})
+	}
+	return metricSpecs
+}
+
+// deadLetterMetricName returns the name of the metric of the dead-letter messages of the entity
+func (s *azureServiceBusScaler) deadLetterMetricName(entityName string) string {
+	return GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-servicebus-%s-deadletter", entityName)))
 }
 
 // Returns the current metrics to be served to the HPA
 func (s *azureServiceBusScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	queuelen, err := s.GetAzureServiceBusLength(ctx)
+	var queuelen int32
+	var err error
+	if s.metadata.deadLetterTargetLength > 0 && (metricName == s.deadLetterMetricName(s.metadata.queueName) || metricName == s.deadLetterMetricName(s.metadata.topicName)) {
+		queuelen, err = s.getDeadLetterLength(ctx)
+	} else {
+		queuelen, err = s.GetAzureServiceBusLength(ctx)
+	}
 
 	if err != nil {
 		azureServiceBusLog.Error(err, "error getting service bus entity length")
@@ -242,13 +380,40 @@ func (a azureTokenProvider) GetToken(uri string) (*auth.Token, error) {
 	}, nil
 }
 
-// Returns the length of the queue or subscription
+// Returns the length of the queue or subscription, only the messages matching the correlation filter are counted
+// if it is set
 func (s *azureServiceBusScaler) GetAzureServiceBusLength(ctx context.Context) (int32, error) {
 	// get namespace
 	namespace, err := s.getServiceBusNamespace(ctx)
 	if err != nil {
 		return -1, err
 	}
+	if s.metadata.entityType == subscription && (s.metadata.correlationFilter != nil || s.metadata.ruleName != "") {
+		return s.getFilteredSubscriptionLength(ctx, namespace)
+	}
+
+	countDetails, err := s.getCountDetails(ctx, namespace)
+	if err != nil {
+		return -1, err
+	}
+	return *countDetails.ActiveMessageCount, nil
+}
+
+// getDeadLetterLength returns the number of dead-letter messages of the queue or subscription
+func (s *azureServiceBusScaler) getDeadLetterLength(ctx context.Context) (int32, error) {
+	namespace, err := s.getServiceBusNamespace(ctx)
+	if err != nil {
+		return -1, err
+	}
+	countDetails, err := s.getCountDetails(ctx, namespace)
+	if err != nil {
+		return -1, err
+	}
+	return *countDetails.DeadLetterMessageCount, nil
+}
+
+// getCountDetails returns the message counts of the queue or subscription
+func (s *azureServiceBusScaler) getCountDetails(ctx context.Context, namespace *servicebus.Namespace) (*servicebus.CountDetails, error) {
 	// switch case for queue vs topic here
 	switch s.metadata.entityType {
 	case queue:
@@ -256,8 +421,106 @@ func (s *azureServiceBusScaler) GetAzureServiceBusLength(ctx context.Context) (i
 	case subscription:
 		return getSubscriptionEntityFromNamespace(ctx, namespace, s.metadata.topicName, s.metadata.subscriptionName)
 	default:
-		return -1, fmt.Errorf("no entity type")
+		return nil, fmt.Errorf("no entity type")
+	}
+}
+
+// getFilteredSubscriptionLength peeks the active messages of the subscription and counts the ones matching the
+// correlation filter, at most peekLimit messages are peeked
+func (s *azureServiceBusScaler) getFilteredSubscriptionLength(ctx context.Context, namespace *servicebus.Namespace) (int32, error) {
+	filter := s.metadata.correlationFilter
+	if s.metadata.ruleName != "" {
+		var err error
+		if filter, err = getSubscriptionRuleFilter(ctx, namespace, s.metadata.topicName, s.metadata.subscriptionName, s.metadata.ruleName); err != nil {
+			return -1, err
+		}
+	}
+
+	topic, err := namespace.NewTopic(s.metadata.topicName)
+	if err != nil {
+		return -1, err
+	}
+	defer topic.Close(ctx)
+	sub, err := topic.NewSubscription(s.metadata.subscriptionName)
+	if err != nil {
+		return -1, err
+	}
+	defer sub.Close(ctx)
+
+	pageSize := s.metadata.peekLimit
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	messages, err := sub.Peek(ctx, servicebus.PeekWithPageSize(pageSize))
+	if err != nil {
+		return -1, err
+	}
+	return countMatchingMessages(ctx, messages, filter, s.metadata.peekLimit)
+}
+
+// getSubscriptionRuleFilter returns the correlation filter of the rule of the subscription
+func getSubscriptionRuleFilter(ctx context.Context, ns *servicebus.Namespace, topicName, subscriptionName, ruleName string) (*servicebus.CorrelationFilter, error) {
+	subscriptionManager, err := ns.NewSubscriptionManager(topicName)
+	if err != nil {
+		return nil, err
 	}
+	rules, err := subscriptionManager.ListRules(ctx, subscriptionName)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Entity == nil || rule.Name != ruleName || rule.RuleDescription == nil {
+			continue
+		}
+		if rule.Filter.Type != "CorrelationFilter" {
+			return nil, fmt.Errorf("rule %s of subscription %s is a %s, only the correlation filters can be counted", ruleName, subscriptionName, rule.Filter.Type)
+		}
+		filter := rule.Filter.CorrelationFilter
+		return &filter, nil
+	}
+	return nil, fmt.Errorf("subscription %s has no rule %s", subscriptionName, ruleName)
+}
+
+// countMatchingMessages counts the messages of the iterator matching the filter among the first limit ones
+func countMatchingMessages(ctx context.Context, messages servicebus.MessageIterator, filter *servicebus.CorrelationFilter, limit int) (int32, error) {
+	var count int32
+	for i := 0; i < limit && !messages.Done(); i++ {
+		message, err := messages.Next(ctx)
+		if errors.As(err, &servicebus.ErrNoMessages{}) {
+			break
+		}
+		if err != nil {
+			return -1, err
+		}
+		if matchesCorrelationFilter(filter, message) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// matchesCorrelationFilter returns whether all the properties of the filter are equal to the ones of the message
+func matchesCorrelationFilter(filter *servicebus.CorrelationFilter, message *servicebus.Message) bool {
+	matches := func(expected *string, actual string) bool {
+		return expected == nil || *expected == actual
+	}
+	sessionID := ""
+	if message.SessionID != nil {
+		sessionID = *message.SessionID
+	}
+	if !matches(filter.CorrelationID, message.CorrelationID) || !matches(filter.MessageID, message.ID) ||
+		!matches(filter.To, message.To) || !matches(filter.ReplyTo, message.ReplyTo) ||
+		!matches(filter.Label, message.Label) || !matches(filter.SessionID, sessionID) ||
+		!matches(filter.ReplyToSessionID, message.ReplyToGroupID) || !matches(filter.ContentType, message.ContentType) {
+		return false
+	}
+	for key, expected := range filter.Properties {
+		actual, ok := message.UserProperties[key]
+		if !ok || fmt.Sprint(actual) != fmt.Sprint(expected) {
+			return false
+		}
+	}
+	return true
 }
 
 // Returns service bus namespace object
@@ -286,31 +549,31 @@ func (s *azureServiceBusScaler) getServiceBusNamespace(ctx context.Context) (*se
 	return namespace, nil
 }
 
-func getQueueEntityFromNamespace(ctx context.Context, ns *servicebus.Namespace, queueName string) (int32, error) {
+func getQueueEntityFromNamespace(ctx context.Context, ns *servicebus.Namespace, queueName string) (*servicebus.CountDetails, error) {
 	// get queue manager from namespace
 	queueManager := ns.NewQueueManager()
 
 	// queue manager.get(ctx, queueName) -> QueueEntitity
 	queueEntity, err := queueManager.Get(ctx, queueName)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
-	return *queueEntity.CountDetails.ActiveMessageCount, nil
+	return queueEntity.CountDetails, nil
 }
 
-func getSubscriptionEntityFromNamespace(ctx context.Context, ns *servicebus.Namespace, topicName, subscriptionName string) (int32, error) {
+func getSubscriptionEntityFromNamespace(ctx context.Context, ns *servicebus.Namespace, topicName, subscriptionName string) (*servicebus.CountDetails, error) {
 	// get subscription manager from namespace
 	subscriptionManager, err := ns.NewSubscriptionManager(topicName)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
 	// subscription manager.get(ctx, subName) -> SubscriptionEntity
 	subscriptionEntity, err := subscriptionManager.Get(ctx, subscriptionName)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
-	return *subscriptionEntity.CountDetails.ActiveMessageCount, nil
+	return subscriptionEntity.CountDetails, nil
 }
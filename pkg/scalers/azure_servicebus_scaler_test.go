@@ -23,6 +23,9 @@ import (
 	"testing"
 	"time"
 
+	servicebus "github.com/Azure/azure-service-bus-go"
+	"github.com/stretchr/testify/assert"
+
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 )
 
@@ -97,6 +100,22 @@ var parseServiceBusMetadataDataset = []parseServiceBusMetadataTestData{
 	{map[string]string{"queueName": queueName}, true, queue, "", map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// correct pod identity
 	{map[string]string{"queueName": queueName, "namespace": namespaceName}, false, queue, defaultSuffix, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// subscription with correlation filter
+	{map[string]string{"topicName": topicName, "subscriptionName": subscriptionName, "connectionFromEnv": connectionSetting, "correlationFilter": "label=orders, tenant=contoso", "peekLimit": "500"}, false, subscription, defaultSuffix, map[string]string{}, ""},
+	// subscription with rule
+	{map[string]string{"topicName": topicName, "subscriptionName": subscriptionName, "connectionFromEnv": connectionSetting, "ruleName": "orders"}, false, subscription, defaultSuffix, map[string]string{}, ""},
+	// correlation filter and rule
+	{map[string]string{"topicName": topicName, "subscriptionName": subscriptionName, "connectionFromEnv": connectionSetting, "correlationFilter": "label=orders", "ruleName": "orders"}, true, none, "", map[string]string{}, ""},
+	// correlation filter on a queue
+	{map[string]string{"queueName": queueName, "connectionFromEnv": connectionSetting, "correlationFilter": "label=orders"}, true, none, "", map[string]string{}, ""},
+	// invalid correlation filter
+	{map[string]string{"topicName": topicName, "subscriptionName": subscriptionName, "connectionFromEnv": connectionSetting, "correlationFilter": "orders"}, true, none, "", map[string]string{}, ""},
+	// invalid peek limit
+	{map[string]string{"topicName": topicName, "subscriptionName": subscriptionName, "connectionFromEnv": connectionSetting, "ruleName": "orders", "peekLimit": "0"}, true, none, "", map[string]string{}, ""},
+	// queue with dead-letter message count
+	{map[string]string{"queueName": queueName, "connectionFromEnv": connectionSetting, "deadLetterMessageCount": "10"}, false, queue, defaultSuffix, map[string]string{}, ""},
+	// negative dead-letter message count
+	{map[string]string{"queueName": queueName, "connectionFromEnv": connectionSetting, "deadLetterMessageCount": "-1"}, true, none, "", map[string]string{}, ""},
 }
 
 var azServiceBusMetricIdentifiers = []azServiceBusMetricIdentifier{
@@ -207,3 +226,45 @@ func TestAzServiceBusGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestAzServiceBusGetMetricSpecForScalingWithDeadLetter(t *testing.T) {
+	meta, err := parseAzureServiceBusMetadata(&ScalerConfig{ResolvedEnv: connectionResolvedEnv, TriggerMetadata: map[string]string{"queueName": queueName, "connectionFromEnv": connectionSetting, "deadLetterMessageCount": "10"}, ScalerIndex: 2})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := azureServiceBusScaler{metadata: meta}
+
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+	assert.Len(t, metricSpec, 2)
+	assert.Equal(t, "s2-azure-servicebus-testqueue", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, "s2-azure-servicebus-testqueue-deadletter", metricSpec[1].External.Metric.Name)
+	assert.Equal(t, int64(10), metricSpec[1].External.Target.AverageValue.Value())
+}
+
+func TestAzServiceBusCountMatchingMessages(t *testing.T) {
+	filter, err := parseCorrelationFilter("label=orders, tenant=contoso")
+	assert.NoError(t, err)
+
+	sessionID := "session"
+	messages := []*servicebus.Message{
+		{Label: "orders", UserProperties: map[string]interface{}{"tenant": "contoso"}},
+		{Label: "orders", UserProperties: map[string]interface{}{"tenant": "fabrikam"}},
+		{Label: "invoices", UserProperties: map[string]interface{}{"tenant": "contoso"}},
+		{Label: "orders", SessionID: &sessionID, UserProperties: map[string]interface{}{"tenant": "contoso", "priority": 1}},
+		{Label: "orders"},
+	}
+	count, err := countMatchingMessages(context.Background(), servicebus.AsMessageSliceIterator(messages), filter, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), count)
+
+	// only the first messages are peeked
+	count, err = countMatchingMessages(context.Background(), servicebus.AsMessageSliceIterator(messages), filter, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), count)
+
+	// the properties of the filter aren't strings when it is read from a rule
+	filter = &servicebus.CorrelationFilter{SessionID: &sessionID, Properties: map[string]interface{}{"priority": int64(1)}}
+	count, err = countMatchingMessages(context.Background(), servicebus.AsMessageSliceIterator(messages), filter, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), count)
+}
@@ -8,8 +8,6 @@ import (
 
 	"github.com/gocql/gocql"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -33,7 +31,7 @@ type CassandraMetadata struct {
 	protocolVersion  int
 	keyspace         string
 	query            string
-	targetQueryValue int
+	targetQueryValue float64
 	metricName       string
 	scalerIndex      int
 }
@@ -68,15 +66,11 @@ func ParseCassandraMetadata(config *ScalerConfig) (*CassandraMetadata, error) {
 		return nil, fmt.Errorf("no query given")
 	}
 
-	if val, ok := config.TriggerMetadata["targetQueryValue"]; ok {
-		targetQueryValue, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("targetQueryValue parsing error %s", err.Error())
-		}
-		meta.targetQueryValue = targetQueryValue
-	} else {
-		return nil, fmt.Errorf("no targetQueryValue given")
+	targetQueryValue, err := getFloatMetadataValue(config.TriggerMetadata, "targetQueryValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.targetQueryValue = targetQueryValue
 
 	if val, ok := config.TriggerMetadata["username"]; ok {
 		meta.username = val
@@ -175,15 +169,11 @@ func (s *cassandraScaler) IsActive(ctx context.Context) (bool, error) {
 
 // GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler.
 func (s *cassandraScaler) GetMetricSpecForScaling(ctx context.Context) []v2beta2.MetricSpec {
-	targetQueryValue := resource.NewQuantity(int64(s.metadata.targetQueryValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetQueryValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.targetQueryValue),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -199,11 +189,7 @@ func (s *cassandraScaler) GetMetrics(ctx context.Context, metricName string, met
 		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting cassandra: %s", err)
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(num), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, float64(num))
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
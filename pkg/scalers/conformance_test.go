@@ -0,0 +1,97 @@
+package scalers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scalers/scalertest"
+)
+
+func TestScalerConformance(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/query":
+			fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1638000000, "12.5"]}]}}`)
+		case "/metrics":
+			fmt.Fprint(w, `{"components": {"worker": {"tasks": 3}}}`)
+		case "/stats":
+			fmt.Fprint(w, `{"stats": [{"name": "cluster.outbound|8080||checkout.shop.svc.cluster.local.upstream_rq_active", "value": 0}]}`)
+		case "/druid/v2/sql":
+			fmt.Fprint(w, `[[7]]`)
+		case "/1/queries/checkout":
+			fmt.Fprint(w, `{"id": "query-1"}`)
+		case "/1/query_results/checkout":
+			fmt.Fprint(w, `{"id": "result-1", "complete": true, "data": {"results": [{"data": {"COUNT": 42}}]}}`)
+		case "/api/v1/metricsQueries":
+			fmt.Fprint(w, `{"queryResult": [{"rowId": "A", "timeSeriesList": {"timeSeries": [{"points": {"values": [4]}}]}}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer backend.Close()
+
+	cases := []scalertest.Case{
+		{
+			Name:      "prometheus",
+			NewScaler: scalers.NewPrometheusScaler,
+			Config: scalers.ScalerConfig{TriggerMetadata: map[string]string{
+				"serverAddress": backend.URL, "metricName": "jobs", "query": "sum(jobs)", "threshold": "10",
+			}},
+			Active: true,
+		},
+		{
+			Name:      "metrics-api",
+			NewScaler: scalers.NewMetricsAPIScaler,
+			Config: scalers.ScalerConfig{TriggerMetadata: map[string]string{
+				"url": backend.URL + "/metrics", "valueLocation": "components.worker.tasks", "targetValue": "2",
+			}},
+			Active: true,
+		},
+		{
+			Name:      "http-requests",
+			NewScaler: scalers.NewHTTPRequestsScaler,
+			Config: scalers.ScalerConfig{Namespace: "shop", TriggerMetadata: map[string]string{
+				"serverAddress": backend.URL, "ingressName": "checkout", "targetRequestsPerSecond": "50",
+			}},
+			Active: true,
+		},
+		{
+			Name:      "envoy-concurrency",
+			NewScaler: scalers.NewEnvoyConcurrencyScaler,
+			Config: scalers.ScalerConfig{Namespace: "shop", TriggerMetadata: map[string]string{
+				"service": "checkout", "port": "8080", "envoyAdminAddress": backend.URL, "targetConcurrency": "10",
+			}},
+			Active: false,
+		},
+		{
+			Name:      "druid",
+			NewScaler: scalers.NewDruidScaler,
+			Config: scalers.ScalerConfig{TriggerMetadata: map[string]string{
+				"brokerURL": backend.URL, "query": "SELECT COUNT(*) FROM jobs", "targetValue": "5",
+			}},
+			Active: true,
+		},
+		{
+			Name:      "honeycomb",
+			NewScaler: scalers.NewHoneycombScaler,
+			Config: scalers.ScalerConfig{TriggerMetadata: map[string]string{
+				"host": backend.URL, "dataset": "checkout", "calculation": "COUNT", "targetValue": "10",
+			}, AuthParams: map[string]string{"apiKey": "key"}},
+			Active: true,
+		},
+		{
+			Name:      "sumologic",
+			NewScaler: scalers.NewSumoLogicScaler,
+			Config: scalers.ScalerConfig{TriggerMetadata: map[string]string{
+				"host": backend.URL, "queryType": "metrics", "query": "metric=queue_depth", "targetValue": "2",
+			}, AuthParams: map[string]string{"accessID": "id", "accessKey": "key"}},
+			Active: true,
+		},
+	}
+	for _, c := range cases {
+		scalertest.Run(t, c)
+	}
+}
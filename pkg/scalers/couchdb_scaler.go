@@ -0,0 +1,383 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	couchDBCookieAuth = "cookie"
+	couchDBIAMAuth    = "iam"
+
+	defaultCouchDBIAMEndpoint = "https://iam.cloud.ibm.com/identity/token"
+	defaultCouchDBQueryLimit  = 10000
+	couchDBSessionCookie      = "AuthSession"
+)
+
+type couchDBScaler struct {
+	metadata   *couchDBMetadata
+	httpClient *http.Client
+
+	// credential is the session cookie or the IAM access token, renewed when it expires
+	credentialLock   sync.Mutex
+	credential       string
+	credentialExpiry time.Time
+}
+
+type couchDBMetadata struct {
+	// The address of the CouchDB server, eg. http://couchdb:5984
+	serverAddress string
+	dbName        string
+	// view is <design document>/<view name>, the reduced value of the view is the metric
+	view string
+	// query is a Mango selector, the number of matching documents is the metric
+	query      string
+	queryLimit int
+	// A threshold that is used as targetAverageValue in HPA
+	queryValue float64
+	metricName string
+
+	authMode string
+	// cookie auth
+	username string
+	password string
+	// IAM auth of Cloudant
+	apiKey      string
+	iamEndpoint string
+
+	scalerIndex int
+}
+
+type couchDBViewResult struct {
+	Rows []struct {
+		Value interface{} `json:"value"`
+	} `json:"rows"`
+}
+
+type couchDBFindResult struct {
+	Docs []json.RawMessage `json:"docs"`
+}
+
+type couchDBIAMToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+var couchDBLog = logf.Log.WithName("couchdb_scaler")
+
+// NewCouchDBScaler creates a new couchDBScaler
+func NewCouchDBScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseCouchDBMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing couchdb metadata: %s", err)
+	}
+
+	return &couchDBScaler{
+		metadata:   meta,
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, false),
+	}, nil
+}
+
+func parseCouchDBMetadata(config *ScalerConfig) (*couchDBMetadata, error) {
+	meta := couchDBMetadata{queryLimit: defaultCouchDBQueryLimit}
+
+	if val, ok := config.TriggerMetadata["serverAddress"]; ok && val != "" {
+		meta.serverAddress = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no serverAddress given")
+	}
+
+	if val, ok := config.TriggerMetadata["dbName"]; ok && val != "" {
+		meta.dbName = val
+	} else {
+		return nil, fmt.Errorf("no dbName given")
+	}
+
+	meta.view = config.TriggerMetadata["view"]
+	meta.query = config.TriggerMetadata["query"]
+	switch {
+	case meta.view == "" && meta.query == "":
+		return nil, fmt.Errorf("no view or query given")
+	case meta.view != "" && meta.query != "":
+		return nil, fmt.Errorf("view and query can not be set both")
+	case meta.view != "" && len(strings.Split(meta.view, "/")) != 2:
+		return nil, fmt.Errorf("view must be <design document>/<view name>, got %s", meta.view)
+	case meta.query != "" && !json.Valid([]byte(meta.query)):
+		return nil, fmt.Errorf("query must be a JSON Mango selector")
+	}
+
+	if val, ok := config.TriggerMetadata["queryLimit"]; ok && val != "" {
+		queryLimit, err := strconv.Atoi(val)
+		if err != nil || queryLimit < 1 {
+			return nil, fmt.Errorf("queryLimit must be a positive integer, got %s", val)
+		}
+		meta.queryLimit = queryLimit
+	}
+
+	queryValue, err := getFloatMetadataValue(config.TriggerMetadata, "queryValue", true, 0)
+	if err != nil {
+		return nil, err
+	}
+	meta.queryValue = queryValue
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("couchdb-%s", val))
+	} else {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("couchdb-%s", meta.dbName))
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	meta.authMode = config.TriggerMetadata["authMode"]
+	switch meta.authMode {
+	case "":
+	case couchDBCookieAuth:
+		if len(config.AuthParams["username"]) == 0 {
+			return nil, fmt.Errorf("no username given")
+		}
+		meta.username = config.AuthParams["username"]
+		meta.password = config.AuthParams["password"]
+	case couchDBIAMAuth:
+		if len(config.AuthParams["apiKey"]) == 0 {
+			return nil, fmt.Errorf("no apiKey given")
+		}
+		meta.apiKey = config.AuthParams["apiKey"]
+		meta.iamEndpoint = defaultCouchDBIAMEndpoint
+		if val, ok := config.TriggerMetadata["iamEndpoint"]; ok && val != "" {
+			meta.iamEndpoint = val
+		}
+	default:
+		return nil, fmt.Errorf("authMode must be %s or %s", couchDBCookieAuth, couchDBIAMAuth)
+	}
+
+	return &meta, nil
+}
+
+// getCredential returns the session cookie or the IAM access token, they are requested again once expired
+func (s *couchDBScaler) getCredential(ctx context.Context) (string, error) {
+	s.credentialLock.Lock()
+	defer s.credentialLock.Unlock()
+	if s.credential != "" && time.Now().Before(s.credentialExpiry) {
+		return s.credential, nil
+	}
+
+	var err error
+	switch s.metadata.authMode {
+	case couchDBCookieAuth:
+		s.credential, s.credentialExpiry, err = s.getSessionCookie(ctx)
+	case couchDBIAMAuth:
+		s.credential, s.credentialExpiry, err = s.getIAMToken(ctx)
+	}
+	return s.credential, err
+}
+
+func (s *couchDBScaler) resetCredential() {
+	s.credentialLock.Lock()
+	defer s.credentialLock.Unlock()
+	s.credential = ""
+}
+
+func (s *couchDBScaler) getSessionCookie(ctx context.Context) (string, time.Time, error) {
+	body, err := json.Marshal(map[string]string{"name": s.metadata.username, "password": s.metadata.password})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.serverAddress+"/_session", bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("couchdb session api returned status %d", r.StatusCode)
+	}
+
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == couchDBSessionCookie {
+			// the session is renewed before the default timeout of CouchDB (10 minutes)
+			return cookie.Value, time.Now().Add(5 * time.Minute), nil
+		}
+	}
+	return "", time.Time{}, fmt.Errorf("couchdb session api didn't return the %s cookie", couchDBSessionCookie)
+}
+
+func (s *couchDBScaler) getIAMToken(ctx context.Context) (string, time.Time, error) {
+	form := url_pkg.Values{}
+	form.Set("grant_type", "urn:ibm:params:oauth:grant-type:apikey")
+	form.Set("apikey", s.metadata.apiKey)
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.iamEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("iam token api returned status %d", r.StatusCode)
+	}
+
+	var token couchDBIAMToken
+	if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
+		return "", time.Time{}, err
+	}
+	// the token is renewed a minute before it expires
+	return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute), nil
+}
+
+func (s *couchDBScaler) newRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.metadata.authMode == "" {
+		return req, nil
+	}
+	credential, err := s.getCredential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error authenticating to couchdb: %s", err)
+	}
+	if s.metadata.authMode == couchDBCookieAuth {
+		req.AddCookie(&http.Cookie{Name: couchDBSessionCookie, Value: credential})
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", credential))
+	}
+	return req, nil
+}
+
+func (s *couchDBScaler) doRequest(ctx context.Context, method, url string, body []byte, result interface{}) error {
+	req, err := s.newRequest(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode == http.StatusUnauthorized {
+		// the session or the token is requested again in the next polling interval
+		s.resetCredential()
+	}
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return fmt.Errorf("couchdb api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+	return json.Unmarshal(b, result)
+}
+
+// getQueryResult returns the reduced value of the view or the number of documents matching the query
+func (s *couchDBScaler) getQueryResult(ctx context.Context) (float64, error) {
+	dbURL := fmt.Sprintf("%s/%s", s.metadata.serverAddress, url_pkg.PathEscape(s.metadata.dbName))
+
+	if s.metadata.view != "" {
+		parts := strings.Split(s.metadata.view, "/")
+		url := fmt.Sprintf("%s/_design/%s/_view/%s?reduce=true", dbURL, url_pkg.PathEscape(parts[0]), url_pkg.PathEscape(parts[1]))
+		var result couchDBViewResult
+		if err := s.doRequest(ctx, "GET", url, nil, &result); err != nil {
+			return -1, err
+		}
+		if len(result.Rows) == 0 {
+			return 0, nil
+		}
+		value, ok := result.Rows[0].Value.(float64)
+		if !ok {
+			return -1, fmt.Errorf("couchdb view %s returned %v, a numeric reduced value is expected", s.metadata.view, result.Rows[0].Value)
+		}
+		return value, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"selector": json.RawMessage(s.metadata.query),
+		"fields":   []string{"_id"},
+		"limit":    s.metadata.queryLimit,
+	})
+	if err != nil {
+		return -1, err
+	}
+	var result couchDBFindResult
+	if err := s.doRequest(ctx, "POST", dbURL+"/_find", body, &result); err != nil {
+		return -1, err
+	}
+	return float64(len(result.Docs)), nil
+}
+
+// IsActive returns true if the query result is greater than 0
+func (s *couchDBScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		couchDBLog.Error(err, "error executing couchdb query")
+		return false, err
+	}
+
+	return value > 0, nil
+}
+
+// Close does nothing, the scaler only uses the HTTP API
+func (s *couchDBScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *couchDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.queryValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value of the metric from the query result
+func (s *couchDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		couchDBLog.Error(err, "error executing couchdb query")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, value)
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,156 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseCouchDBMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type couchDBMetricIdentifier struct {
+	metadataTestData *parseCouchDBMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testCouchDBMetadata = []parseCouchDBMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// view
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "queue/pending", "queryValue": "10"}, map[string]string{}, false},
+	// mango query
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "query": `{"status": "pending"}`, "queryValue": "10", "queryLimit": "500"}, map[string]string{}, false},
+	// missing view and query
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "queryValue": "10"}, map[string]string{}, true},
+	// both view and query
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "queue/pending", "query": `{"status": "pending"}`, "queryValue": "10"}, map[string]string{}, true},
+	// malformed view
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "pending", "queryValue": "10"}, map[string]string{}, true},
+	// malformed query
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "query": `{"status": `, "queryValue": "10"}, map[string]string{}, true},
+	// missing queryValue
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "queue/pending"}, map[string]string{}, true},
+	// cookie auth
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "queue/pending", "queryValue": "10", "authMode": "cookie"}, map[string]string{"username": "admin", "password": "secret"}, false},
+	// cookie auth without username
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "queue/pending", "queryValue": "10", "authMode": "cookie"}, map[string]string{}, true},
+	// iam auth
+	{map[string]string{"serverAddress": "https://account.cloudantnosqldb.appdomain.cloud", "dbName": "jobs", "view": "queue/pending", "queryValue": "10", "authMode": "iam"}, map[string]string{"apiKey": "key"}, false},
+	// iam auth without apiKey
+	{map[string]string{"serverAddress": "https://account.cloudantnosqldb.appdomain.cloud", "dbName": "jobs", "view": "queue/pending", "queryValue": "10", "authMode": "iam"}, map[string]string{}, true},
+	// unknown auth mode
+	{map[string]string{"serverAddress": "http://localhost:5984", "dbName": "jobs", "view": "queue/pending", "queryValue": "10", "authMode": "basic"}, map[string]string{}, true},
+}
+
+var couchDBMetricIdentifiers = []couchDBMetricIdentifier{
+	{&testCouchDBMetadata[1], 0, "s0-couchdb-jobs"},
+	{&testCouchDBMetadata[1], 1, "s1-couchdb-jobs"},
+}
+
+func TestCouchDBParseMetadata(t *testing.T) {
+	for _, testData := range testCouchDBMetadata {
+		_, err := parseCouchDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestCouchDBGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range couchDBMetricIdentifiers {
+		meta, err := parseCouchDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockCouchDBScaler := couchDBScaler{metadata: meta}
+
+		metricSpec := mockCouchDBScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestCouchDBViewWithCookieAuth(t *testing.T) {
+	var sessions int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_session":
+			sessions++
+			http.SetCookie(w, &http.Cookie{Name: couchDBSessionCookie, Value: "session"})
+		case "/jobs/_design/queue/_view/pending":
+			cookie, err := r.Cookie(couchDBSessionCookie)
+			if err != nil || cookie.Value != "session" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"rows":[{"key":null,"value":12}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	meta, err := parseCouchDBMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"serverAddress": server.URL, "dbName": "jobs", "view": "queue/pending", "queryValue": "10", "authMode": "cookie"},
+		AuthParams:      map[string]string{"username": "admin", "password": "secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scaler := couchDBScaler{metadata: meta, httpClient: http.DefaultClient}
+
+	for i := 0; i < 2; i++ {
+		value, err := scaler.getQueryResult(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, float64(12), value)
+	}
+	assert.Equal(t, 1, sessions)
+}
+
+func TestCouchDBQueryWithIAMAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/identity/token":
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "key", r.PostForm.Get("apikey"))
+			fmt.Fprint(w, `{"access_token":"token","expires_in":3600}`)
+		case "/jobs/_find":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{"status": "pending"}, body["selector"])
+			fmt.Fprint(w, `{"docs":[{"_id":"1"},{"_id":"2"},{"_id":"3"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	meta, err := parseCouchDBMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"serverAddress": server.URL, "dbName": "jobs", "query": `{"status": "pending"}`, "queryValue": "10", "authMode": "iam", "iamEndpoint": server.URL + "/identity/token"},
+		AuthParams:      map[string]string{"apiKey": "key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scaler := couchDBScaler{metadata: meta, httpClient: http.DefaultClient}
+
+	value, err := scaler.getQueryResult(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), value)
+}
@@ -21,6 +21,7 @@ type cpuMemoryMetadata struct {
 	Type               v2beta2.MetricTargetType
 	AverageValue       *resource.Quantity
 	AverageUtilization *int32
+	ContainerName      string
 }
 
 // NewCPUMemoryScaler creates a new cpuMemoryScaler
@@ -40,6 +41,8 @@ func parseResourceMetadata(config *ScalerConfig) (*cpuMemoryMetadata, error) {
 	meta := &cpuMemoryMetadata{}
 	if val, ok := config.TriggerMetadata["type"]; ok && val != "" {
 		meta.Type = v2beta2.MetricTargetType(val)
+	} else if config.MetricType != "" {
+		meta.Type = config.MetricType
 	} else {
 		return nil, fmt.Errorf("no type given")
 	}
@@ -63,6 +66,10 @@ func parseResourceMetadata(config *ScalerConfig) (*cpuMemoryMetadata, error) {
 	default:
 		return nil, fmt.Errorf("unsupported metric type, allowed values are 'Utilization' or 'AverageValue'")
 	}
+
+	if value, ok := config.TriggerMetadata["containerName"]; ok && value != "" {
+		meta.ContainerName = value
+	}
 	return meta, nil
 }
 
@@ -76,15 +83,28 @@ func (s *cpuMemoryScaler) Close(context.Context) error {
 	return nil
 }
 
-// GetMetricSpecForScaling returns the metric spec for the HPA
+// GetMetricSpecForScaling returns the metric spec for the HPA,
+// the utilization of a single container is used if containerName is set
 func (s *cpuMemoryScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	target := v2beta2.MetricTarget{
+		Type:               s.metadata.Type,
+		AverageUtilization: s.metadata.AverageUtilization,
+		AverageValue:       s.metadata.AverageValue,
+	}
+
+	if s.metadata.ContainerName != "" {
+		containerMetric := &v2beta2.ContainerResourceMetricSource{
+			Name:      s.resourceName,
+			Container: s.metadata.ContainerName,
+			Target:    target,
+		}
+		metricSpec := v2beta2.MetricSpec{ContainerResource: containerMetric, Type: v2beta2.ContainerResourceMetricSourceType}
+		return []v2beta2.MetricSpec{metricSpec}
+	}
+
 	cpuMemoryMetric := &v2beta2.ResourceMetricSource{
-		Name: s.resourceName,
-		Target: v2beta2.MetricTarget{
-			Type:               s.metadata.Type,
-			AverageUtilization: s.metadata.AverageUtilization,
-			AverageValue:       s.metadata.AverageValue,
-		},
+		Name:   s.resourceName,
+		Target: target,
 	}
 	metricSpec := v2beta2.MetricSpec{Resource: cpuMemoryMetric, Type: v2beta2.ResourceMetricSourceType}
 	return []v2beta2.MetricSpec{metricSpec}
@@ -56,3 +56,30 @@ func TestGetMetricSpecForScaling(t *testing.T) {
 	assert.Equal(t, metricSpec[0].Resource.Name, v1.ResourceCPU)
 	assert.Equal(t, metricSpec[0].Resource.Target.Type, v2beta2.UtilizationMetricType)
 }
+
+func TestCPUMemoryParseMetadataWithMetricType(t *testing.T) {
+	config := &ScalerConfig{
+		TriggerMetadata: map[string]string{"value": "50"},
+		MetricType:      v2beta2.UtilizationMetricType,
+	}
+	meta, err := parseResourceMetadata(config)
+	if err != nil {
+		t.Fatal("Expected success but got error", err)
+	}
+	assert.Equal(t, v2beta2.UtilizationMetricType, meta.Type)
+	assert.Equal(t, int32(50), *meta.AverageUtilization)
+}
+
+func TestGetContainerMetricSpecForScaling(t *testing.T) {
+	config := &ScalerConfig{
+		TriggerMetadata: map[string]string{"type": "Utilization", "value": "50", "containerName": "app"},
+	}
+	scaler, _ := NewCPUMemoryScaler(v1.ResourceMemory, config)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, metricSpec[0].Type, v2beta2.ContainerResourceMetricSourceType)
+	assert.Nil(t, metricSpec[0].Resource)
+	assert.Equal(t, metricSpec[0].ContainerResource.Name, v1.ResourceMemory)
+	assert.Equal(t, metricSpec[0].ContainerResource.Container, "app")
+	assert.Equal(t, *metricSpec[0].ContainerResource.Target.AverageUtilization, int32(50))
+}
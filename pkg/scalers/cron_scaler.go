@@ -1,10 +1,15 @@
 package scalers
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -21,10 +26,20 @@ import (
 const (
 	defaultDesiredReplicas = 1
 	cronMetricType         = "External"
+	// holidaysRefreshInterval is how often the holidays of holidaysURL are downloaded
+	holidaysRefreshInterval = time.Hour
+	holidayDateFormat       = "2006-01-02"
 )
 
 type cronScaler struct {
-	metadata *cronMetadata
+	metadata   *cronMetadata
+	httpClient *http.Client
+	now        func() time.Time
+
+	// holidays downloaded from holidaysURL
+	holidaysLock    sync.Mutex
+	holidays        map[string]bool
+	holidaysFetched time.Time
 }
 
 type cronMetadata struct {
@@ -32,7 +47,19 @@ type cronMetadata struct {
 	end             string
 	timezone        string
 	desiredReplicas int64
-	scalerIndex     int
+	// windows holds start, end and desiredReplicas first when they are set
+	windows []cronWindow
+	// holidays are the dates suppressing the windows, in the timezone
+	holidays    map[string]bool
+	holidaysURL string
+	scalerIndex int
+}
+
+// cronWindow is an element of the windows metadata, a JSON list
+type cronWindow struct {
+	Start           string `json:"start"`
+	End             string `json:"end"`
+	DesiredReplicas int64  `json:"desiredReplicas"`
 }
 
 var cronLog = logf.Log.WithName("cron_scaler")
@@ -45,22 +72,18 @@ func NewCronScaler(config *ScalerConfig) (Scaler, error) {
 	}
 
 	return &cronScaler{
-		metadata: meta,
+		metadata:   meta,
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, false),
+		now:        time.Now,
 	}, nil
 }
 
-func getCronTime(location *time.Location, spec string) (int64, error) {
-	c := cron.New(cron.WithLocation(location))
-	_, err := c.AddFunc(spec, func() { _ = fmt.Sprintf("Cron initialized for location %s", location.String()) })
+func getCronTime(location *time.Location, spec string, now time.Time) (int64, error) {
+	schedule, err := cron.ParseStandard(spec)
 	if err != nil {
 		return 0, err
 	}
-
-	c.Start()
-	cronTime := c.Entries()[0].Next.Unix()
-	c.Stop()
-
-	return cronTime, nil
+	return schedule.Next(now.In(location)).Unix(), nil
 }
 
 func parseCronMetadata(config *ScalerConfig) (*cronMetadata, error) {
@@ -75,60 +98,133 @@ func parseCronMetadata(config *ScalerConfig) (*cronMetadata, error) {
 		return nil, fmt.Errorf("no timezone specified. %s", config.TriggerMetadata)
 	}
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	if val, ok := config.TriggerMetadata["start"]; ok && val != "" {
-		_, err := parser.Parse(val)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing start schedule: %s", err)
+	// start, end and desiredReplicas are optional with windows
+	_, hasStart := config.TriggerMetadata["start"]
+	_, hasEnd := config.TriggerMetadata["end"]
+	windows, hasWindows := config.TriggerMetadata["windows"]
+	if hasStart || hasEnd || !hasWindows {
+		if val, ok := config.TriggerMetadata["start"]; ok && val != "" {
+			_, err := parser.Parse(val)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing start schedule: %s", err)
+			}
+			meta.start = val
+		} else {
+			return nil, fmt.Errorf("no start schedule specified. %s", config.TriggerMetadata)
 		}
-		meta.start = val
-	} else {
-		return nil, fmt.Errorf("no start schedule specified. %s", config.TriggerMetadata)
-	}
-	if val, ok := config.TriggerMetadata["end"]; ok && val != "" {
-		_, err := parser.Parse(val)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing end schedule: %s", err)
+		if val, ok := config.TriggerMetadata["end"]; ok && val != "" {
+			_, err := parser.Parse(val)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing end schedule: %s", err)
+			}
+			meta.end = val
+		} else {
+			return nil, fmt.Errorf("no end schedule specified. %s", config.TriggerMetadata)
 		}
-		meta.end = val
-	} else {
-		return nil, fmt.Errorf("no end schedule specified. %s", config.TriggerMetadata)
-	}
-	if meta.start == meta.end {
-		return nil, fmt.Errorf("error parsing schedule. %s: start and end can not have exactly same time input", config.TriggerMetadata)
+		if meta.start == meta.end {
+			return nil, fmt.Errorf("error parsing schedule. %s: start and end can not have exactly same time input", config.TriggerMetadata)
+		}
+		if val, ok := config.TriggerMetadata["desiredReplicas"]; ok && val != "" {
+			metadataDesiredReplicas, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing desiredReplicas metadata. %s", config.TriggerMetadata)
+			}
+
+			meta.desiredReplicas = int64(metadataDesiredReplicas)
+		} else {
+			return nil, fmt.Errorf("no DesiredReplicas specified. %s", config.TriggerMetadata)
+		}
+		meta.windows = append(meta.windows, cronWindow{Start: meta.start, End: meta.end, DesiredReplicas: meta.desiredReplicas})
 	}
-	if val, ok := config.TriggerMetadata["desiredReplicas"]; ok && val != "" {
-		metadataDesiredReplicas, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing desiredReplicas metadata. %s", config.TriggerMetadata)
+
+	if hasWindows {
+		var parsed []cronWindow
+		if err := json.Unmarshal([]byte(windows), &parsed); err != nil {
+			return nil, fmt.Errorf("error parsing windows: %s", err)
 		}
+		if len(parsed) == 0 {
+			return nil, fmt.Errorf("no window given in windows")
+		}
+		for i, window := range parsed {
+			if _, err := parser.Parse(window.Start); err != nil {
+				return nil, fmt.Errorf("error parsing start schedule of window %d: %s", i, err)
+			}
+			if _, err := parser.Parse(window.End); err != nil {
+				return nil, fmt.Errorf("error parsing end schedule of window %d: %s", i, err)
+			}
+			if window.Start == window.End {
+				return nil, fmt.Errorf("start and end of window %d can not have exactly same time input", i)
+			}
+			if window.DesiredReplicas < 1 {
+				return nil, fmt.Errorf("desiredReplicas of window %d must be greater than 0", i)
+			}
+		}
+		meta.windows = append(meta.windows, parsed...)
+	}
 
-		meta.desiredReplicas = int64(metadataDesiredReplicas)
-	} else {
-		return nil, fmt.Errorf("no DesiredReplicas specified. %s", config.TriggerMetadata)
+	if val, ok := config.TriggerMetadata["holidays"]; ok && val != "" {
+		meta.holidays = map[string]bool{}
+		for _, date := range splitAndTrim(val) {
+			if _, err := time.Parse(holidayDateFormat, date); err != nil {
+				return nil, fmt.Errorf("error parsing holidays: %s", err)
+			}
+			meta.holidays[date] = true
+		}
 	}
+	meta.holidaysURL = config.TriggerMetadata["holidaysURL"]
 	meta.scalerIndex = config.ScalerIndex
 	return &meta, nil
 }
 
-// IsActive checks if the startTime or endTime has reached
+// IsActive checks if the startTime or endTime of a window has reached
 func (s *cronScaler) IsActive(ctx context.Context) (bool, error) {
+	_, active, err := s.getDesiredReplicas(ctx)
+	return active, err
+}
+
+// getDesiredReplicas returns the highest desiredReplicas of the active windows, no window is active on holidays
+func (s *cronScaler) getDesiredReplicas(ctx context.Context) (int64, bool, error) {
 	location, err := time.LoadLocation(s.metadata.timezone)
 	if err != nil {
-		return false, fmt.Errorf("unable to load timezone. Error: %s", err)
+		return 0, false, fmt.Errorf("unable to load timezone. Error: %s", err)
+	}
+	now := s.now()
+
+	holiday, err := s.isHoliday(ctx, now.In(location).Format(holidayDateFormat))
+	if err != nil {
+		return 0, false, err
+	}
+	if holiday {
+		return 0, false, nil
+	}
+
+	desiredReplicas, active := int64(0), false
+	for _, window := range s.metadata.windows {
+		windowActive, err := isCronWindowActive(location, window, now)
+		if err != nil {
+			return 0, false, err
+		}
+		if windowActive && window.DesiredReplicas > desiredReplicas {
+			desiredReplicas = window.DesiredReplicas
+		}
+		active = active || windowActive
 	}
+	return desiredReplicas, active, nil
+}
 
-	nextStartTime, startTimecronErr := getCronTime(location, s.metadata.start)
+func isCronWindowActive(location *time.Location, window cronWindow, now time.Time) (bool, error) {
+	nextStartTime, startTimecronErr := getCronTime(location, window.Start, now)
 	if startTimecronErr != nil {
 		return false, fmt.Errorf("error initializing start cron: %s", startTimecronErr)
 	}
 
-	nextEndTime, endTimecronErr := getCronTime(location, s.metadata.end)
+	nextEndTime, endTimecronErr := getCronTime(location, window.End, now)
 	if endTimecronErr != nil {
 		return false, fmt.Errorf("error intializing end cron: %s", endTimecronErr)
 	}
 
 	// Since we are considering the timestamp here and not the exact time, timezone does matter.
-	currentTime := time.Now().Unix()
+	currentTime := now.Unix()
 	switch {
 	case nextStartTime < nextEndTime && currentTime < nextStartTime:
 		return false, nil
@@ -139,7 +235,111 @@ func (s *cronScaler) IsActive(ctx context.Context) (bool, error) {
 	}
 }
 
+// isHoliday checks the date against the holidays of the metadata and of holidaysURL, the holidays of holidaysURL
+// are refreshed every holidaysRefreshInterval and kept when the download fails
+func (s *cronScaler) isHoliday(ctx context.Context, date string) (bool, error) {
+	if s.metadata.holidays[date] {
+		return true, nil
+	}
+	if s.metadata.holidaysURL == "" {
+		return false, nil
+	}
+
+	s.holidaysLock.Lock()
+	defer s.holidaysLock.Unlock()
+	if s.holidays == nil || s.now().Sub(s.holidaysFetched) > holidaysRefreshInterval {
+		holidays, err := s.fetchHolidays(ctx)
+		switch {
+		case err == nil:
+			s.holidays = holidays
+			s.holidaysFetched = s.now()
+		case s.holidays == nil:
+			return false, err
+		default:
+			cronLog.Error(err, "error refreshing holidays, keeping the previous ones")
+		}
+	}
+	return s.holidays[date], nil
+}
+
+// fetchHolidays downloads the iCalendar or the list of YYYY-MM-DD dates of holidaysURL
+func (s *cronScaler) fetchHolidays(ctx context.Context) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.metadata.holidaysURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting holidays: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error getting holidays: status %d", resp.StatusCode)
+	}
+	return parseHolidays(resp.Body)
+}
+
+// parseHolidays reads the dates of the events of an iCalendar, the days of all-day events spanning several days,
+// or a list of YYYY-MM-DD dates, one per line
+func parseHolidays(r io.Reader) (map[string]bool, error) {
+	holidays := map[string]bool{}
+	var start, end time.Time
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		name, value := line, ""
+		if i := strings.LastIndex(line, ":"); i >= 0 {
+			name, value = line[:i], line[i+1:]
+		}
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case line == "BEGIN:VEVENT":
+			start, end = time.Time{}, time.Time{}
+		case line == "END:VEVENT":
+			if start.IsZero() {
+				continue
+			}
+			// DTEND of all-day events is exclusive, events are capped to a year
+			for day := 0; day == 0 || (start.AddDate(0, 0, day).Before(end) && day < 366); day++ {
+				holidays[start.AddDate(0, 0, day).Format(holidayDateFormat)] = true
+			}
+		case strings.HasPrefix(name, "DTSTART"):
+			date, err := parseICalendarDate(value)
+			if err != nil {
+				return nil, err
+			}
+			start = date
+		case strings.HasPrefix(name, "DTEND") && strings.Contains(name, "VALUE=DATE") && !strings.Contains(name, "VALUE=DATE-TIME"):
+			date, err := parseICalendarDate(value)
+			if err != nil {
+				return nil, err
+			}
+			end = date
+		case strings.Contains(line, ":"):
+			// the other iCalendar properties
+		default:
+			if _, err := time.Parse(holidayDateFormat, line); err != nil {
+				return nil, fmt.Errorf("error parsing holiday: %s", err)
+			}
+			holidays[line] = true
+		}
+	}
+	return holidays, scanner.Err()
+}
+
+func parseICalendarDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("error parsing iCalendar date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error parsing iCalendar date %q: %s", value, err)
+	}
+	return date, nil
+}
+
 func (s *cronScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
@@ -151,13 +351,20 @@ func parseCronTimeFormat(s string) string {
 	return s
 }
 
+func (s *cronScaler) metricName() string {
+	if s.metadata.start == "" {
+		return kedautil.NormalizeString(fmt.Sprintf("cron-%s-windows", s.metadata.timezone))
+	}
+	return kedautil.NormalizeString(fmt.Sprintf("cron-%s-%s-%s", s.metadata.timezone, parseCronTimeFormat(s.metadata.start), parseCronTimeFormat(s.metadata.end)))
+}
+
 // GetMetricSpecForScaling returns the metric spec for the HPA
 func (s *cronScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
 	specReplicas := 1
 	targetMetricValue := resource.NewQuantity(int64(specReplicas), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
-			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("cron-%s-%s-%s", s.metadata.timezone, parseCronTimeFormat(s.metadata.start), parseCronTimeFormat(s.metadata.end)))),
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metricName()),
 		},
 		Target: v2beta2.MetricTarget{
 			Type:         v2beta2.AverageValueMetricType,
@@ -171,13 +378,13 @@ func (s *cronScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSp
 // GetMetrics finds the current value of the metric
 func (s *cronScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
 	var currentReplicas = int64(defaultDesiredReplicas)
-	isActive, err := s.IsActive(ctx)
+	desiredReplicas, isActive, err := s.getDesiredReplicas(ctx)
 	if err != nil {
 		cronLog.Error(err, "error")
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 	if isActive {
-		currentReplicas = s.metadata.desiredReplicas
+		currentReplicas = desiredReplicas
 	}
 
 	/*******************************************************************************/
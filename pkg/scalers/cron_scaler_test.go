@@ -2,6 +2,10 @@ package scalers
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,11 +51,20 @@ var testCronMetadata = []parseCronMetadataTestData{
 	{map[string]string{"timezone": "Asia/Kolkata", "start": "30 * * * *", "end": "-50 * * * *", "desiredReplicas": "10"}, true},
 	{map[string]string{"timezone": "Asia/Kolkata", "start": "30 * * * *", "end": "50 * * -3 *", "desiredReplicas": "10"}, true},
 	{map[string]string{"timezone": "Asia/Kolkata", "start": "30 * * * *", "end": "30 * * * *", "desiredReplicas": "10"}, true},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[{"start": "0 6 * * *", "end": "0 14 * * *", "desiredReplicas": 5}]`}, false},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[{"start": "0 6 * * *", "end": "0 14 * * *", "desiredReplicas": 5}]`, "start": "30 * * * *"}, true},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[]`}, true},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[{"start": "0 6 * * *", "end": "-1 14 * * *", "desiredReplicas": 5}]`}, true},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[{"start": "0 6 * * *", "end": "0 14 * * *"}]`}, true},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `{"start": "0 6 * * *"}`}, true},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[{"start": "0 6 * * *", "end": "0 14 * * *", "desiredReplicas": 5}]`, "holidays": "2021-12-25, 2022-01-01"}, false},
+	{map[string]string{"timezone": "Etc/UTC", "windows": `[{"start": "0 6 * * *", "end": "0 14 * * *", "desiredReplicas": 5}]`, "holidays": "25/12/2021"}, true},
 }
 
 var cronMetricIdentifiers = []cronMetricIdentifier{
 	{&testCronMetadata[1], 0, "s0-cron-Etc-UTC-00xxThu-5923xxThu"},
 	{&testCronMetadata[2], 1, "s1-cron-Etc-UTC-0xSl2xxx-01-23Sl2xxx"},
+	{&testCronMetadata[11], 2, "s2-cron-Etc-UTC-windows"},
 }
 
 var tz, _ = time.LoadLocation(validCronMetadata2["timezone"])
@@ -118,7 +131,7 @@ func TestCronGetMetricSpecForScaling(t *testing.T) {
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockCronScaler := cronScaler{meta}
+		mockCronScaler := cronScaler{metadata: meta}
 
 		metricSpec := mockCronScaler.GetMetricSpecForScaling(context.Background())
 		metricName := metricSpec[0].External.Metric.Name
@@ -127,3 +140,93 @@ func TestCronGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestCronWindows(t *testing.T) {
+	meta, err := parseCronMetadata(&ScalerConfig{TriggerMetadata: map[string]string{
+		"timezone":        "Etc/UTC",
+		"start":           "0 8 * * *",
+		"end":             "0 18 * * *",
+		"desiredReplicas": "4",
+		"windows":         `[{"start": "0 6 * * *", "end": "0 14 * * *", "desiredReplicas": 5}, {"start": "0 14 * * *", "end": "0 22 * * *", "desiredReplicas": 3}]`,
+		"holidays":        "2021-12-25",
+	}})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+
+	for date, expected := range map[string]int64{
+		"2021-12-20T07:00:00Z": 5,
+		"2021-12-20T10:00:00Z": 5,
+		"2021-12-20T16:00:00Z": 4,
+		"2021-12-20T20:00:00Z": 3,
+		"2021-12-20T23:00:00Z": 1,
+		"2021-12-25T10:00:00Z": 1,
+	} {
+		now, _ := time.Parse(time.RFC3339, date)
+		scaler := &cronScaler{metadata: meta, now: func() time.Time { return now }}
+		metrics, err := scaler.GetMetrics(context.Background(), "s0-cron", nil)
+		assert.NoError(t, err, date)
+		assert.Equal(t, expected, metrics[0].Value.Value(), date)
+	}
+}
+
+func TestCronHolidaysURL(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"BEGIN:VEVENT",
+			"DTSTART;VALUE=DATE:20211224",
+			"DTEND;VALUE=DATE:20211227",
+			"SUMMARY:Christmas",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"DTSTART:20211231T120000Z",
+			"DTEND:20211231T180000Z",
+			"END:VEVENT",
+			"END:VCALENDAR",
+		}, "\r\n"))
+	}))
+	defer server.Close()
+
+	meta, err := parseCronMetadata(&ScalerConfig{TriggerMetadata: map[string]string{
+		"timezone":    "Etc/UTC",
+		"windows":     `[{"start": "0 6 * * *", "end": "0 14 * * *", "desiredReplicas": 5}]`,
+		"holidaysURL": server.URL,
+	}})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+
+	for date, expected := range map[string]bool{
+		"2021-12-23T10:00:00Z": true,
+		"2021-12-24T10:00:00Z": false,
+		"2021-12-26T10:00:00Z": false,
+		"2021-12-27T10:00:00Z": true,
+		"2021-12-31T10:00:00Z": false,
+	} {
+		now, _ := time.Parse(time.RFC3339, date)
+		scaler := &cronScaler{metadata: meta, httpClient: server.Client(), now: func() time.Time { return now }}
+		active, err := scaler.IsActive(context.Background())
+		assert.NoError(t, err, date)
+		assert.Equal(t, expected, active, date)
+	}
+
+	// the holidays are downloaded once per refresh interval
+	now := time.Date(2021, 12, 23, 10, 0, 0, 0, time.UTC)
+	scaler := &cronScaler{metadata: meta, httpClient: server.Client(), now: func() time.Time { return now }}
+	requests = 0
+	_, _ = scaler.IsActive(context.Background())
+	_, _ = scaler.IsActive(context.Background())
+	assert.Equal(t, 1, requests)
+}
+
+func TestParseHolidaysList(t *testing.T) {
+	holidays, err := parseHolidays(strings.NewReader("# public holidays\n2021-12-25\n\n2022-01-01\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"2021-12-25": true, "2022-01-01": true}, holidays)
+
+	_, err = parseHolidays(strings.NewReader("christmas\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,9 @@
+//go:build db2
+// +build db2
+
+package scalers
+
+import (
+	// Db2 driver required for the db2 scaler, it needs cgo and the IBM Db2 CLI driver
+	_ "github.com/ibmdb/go_ibm_db"
+)
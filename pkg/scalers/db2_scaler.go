@@ -0,0 +1,237 @@
+package scalers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// db2DriverName is the name of the database/sql driver of github.com/ibmdb/go_ibm_db, the driver needs cgo and the
+// IBM Db2 CLI driver so it is only registered in the builds with the db2 tag, see db2_driver.go
+const db2DriverName = "go_ibm_db"
+
+type db2Scaler struct {
+	metadata   *db2Metadata
+	connection *sql.DB
+	logger     logr.Logger
+}
+
+type db2Metadata struct {
+	Query            string  `keda:"name=query"`
+	TargetQueryValue float64 `keda:"name=targetQueryValue"`
+	MetricName       string  `keda:"name=metricName, optional"`
+
+	// Connection is a full connection string, eg. HOSTNAME=host;PORT=50000;DATABASE=db;UID=user;PWD=password,
+	// the other connection parameters are used when it is not set
+	Connection string `keda:"name=connection, order=authParams;resolvedEnv, optional"`
+	Host       string `keda:"name=host, order=triggerMetadata;authParams, optional"`
+	Port       int    `keda:"name=port, order=triggerMetadata;authParams, default=50000"`
+	Database   string `keda:"name=database, order=triggerMetadata;authParams, optional"`
+	Username   string `keda:"name=username, order=triggerMetadata;authParams, optional"`
+	Password   string `keda:"name=password, order=authParams;resolvedEnv, optional"`
+	// APIKey authenticates with IBM Cloud IAM to Db2 on Cloud instead of the username and password
+	APIKey string `keda:"name=apiKey, order=authParams;resolvedEnv, optional"`
+
+	// SSL enables the TLS connection, which Db2 on Cloud requires, SSLServerCertificate is the path of the
+	// certificate of the server in the KEDA pod if it isn't signed by a known authority
+	SSL                  bool   `keda:"name=ssl, default=false"`
+	SSLServerCertificate string `keda:"name=sslServerCertificate, optional"`
+
+	missingValue *missingValuePolicy
+	scalerIndex  int
+}
+
+// Validate checks that the connection to Db2 is configured
+func (m *db2Metadata) Validate() error {
+	if m.Connection != "" {
+		return nil
+	}
+	if m.Host == "" || m.Database == "" {
+		return fmt.Errorf("connection or host and database must be given")
+	}
+	switch {
+	case m.APIKey != "" && (m.Username != "" || m.Password != ""):
+		return fmt.Errorf("apiKey can't be given with username and password")
+	case m.APIKey == "" && m.Username == "":
+		return fmt.Errorf("username or apiKey must be given")
+	}
+	if m.SSLServerCertificate != "" && !m.SSL {
+		return fmt.Errorf("sslServerCertificate requires ssl")
+	}
+	return nil
+}
+
+// NewDb2Scaler creates a new Db2 scaler
+func NewDb2Scaler(config *ScalerConfig) (Scaler, error) {
+	logger := InitializeLogger(config, "db2_scaler")
+	meta, err := parseDb2Metadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing db2 metadata: %s", err)
+	}
+
+	conn, err := newDb2Connection(meta, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error establishing db2 connection: %s", err)
+	}
+	return &db2Scaler{
+		metadata:   meta,
+		connection: conn,
+		logger:     logger,
+	}, nil
+}
+
+func parseDb2Metadata(config *ScalerConfig) (*db2Metadata, error) {
+	meta := &db2Metadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+
+	if meta.MetricName != "" {
+		meta.MetricName = kedautil.NormalizeString(fmt.Sprintf("db2-%s", meta.MetricName))
+	} else {
+		meta.MetricName = kedautil.NormalizeString("db2")
+	}
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.missingValue = missingValue
+
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// connectionString returns the CLI connection string of the metadata
+func (m *db2Metadata) connectionString() string {
+	if m.Connection != "" {
+		return m.Connection
+	}
+
+	params := []string{
+		"HOSTNAME=" + m.Host,
+		fmt.Sprintf("PORT=%d", m.Port),
+		"DATABASE=" + m.Database,
+	}
+	if m.APIKey != "" {
+		// the CLI driver exchanges the API key for an IAM token
+		params = append(params, "Authentication=GSSPLUGIN", "APIKEY="+m.APIKey)
+	} else {
+		params = append(params, "UID="+m.Username, "PWD="+m.Password)
+	}
+	if m.SSL {
+		params = append(params, "Security=SSL")
+		if m.SSLServerCertificate != "" {
+			params = append(params, "SSLServerCertificate="+m.SSLServerCertificate)
+		}
+	}
+	return strings.Join(params, ";")
+}
+
+func newDb2Connection(meta *db2Metadata, logger logr.Logger) (*sql.DB, error) {
+	if !isDb2DriverRegistered() {
+		return nil, fmt.Errorf("the db2 driver isn't available in this build of KEDA, it has to be built with the db2 tag")
+	}
+	db, err := sql.Open(db2DriverName, meta.connectionString())
+	if err != nil {
+		logger.Error(err, "Found error opening db2")
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		logger.Error(err, "Found error pinging db2")
+		return nil, err
+	}
+	return db, nil
+}
+
+func isDb2DriverRegistered() bool {
+	for _, driver := range sql.Drivers() {
+		if driver == db2DriverName {
+			return true
+		}
+	}
+	return false
+}
+
+// Close disposes of db2 connections
+func (s *db2Scaler) Close(context.Context) error {
+	err := s.connection.Close()
+	if err != nil {
+		s.logger.Error(err, "Error closing db2 connection")
+		return err
+	}
+	return nil
+}
+
+// State returns the last value of the query kept for valueIfNull: lastValue
+func (s *db2Scaler) State() ([]byte, error) {
+	return missingValueState(s.metadata.missingValue)
+}
+
+// RestoreState restores the last value of the query
+func (s *db2Scaler) RestoreState(state []byte) error {
+	return restoreMissingValueState(state, s.metadata.missingValue)
+}
+
+// IsActive returns true if the query returned a value greater than 0
+func (s *db2Scaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error inspecting db2: %s", err)
+	}
+
+	return value > 0, nil
+}
+
+func (s *db2Scaler) getQueryResult(ctx context.Context) (float64, error) {
+	var value sql.NullFloat64
+	err := s.connection.QueryRowContext(ctx, s.metadata.Query).Scan(&value)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		s.logger.Error(err, "Could not query db2")
+		return 0, fmt.Errorf("could not query db2: %s", err)
+	default:
+		err = errSQLNullResult
+	}
+
+	result, err := s.metadata.missingValue.resolve(value.Float64, value.Valid, err)
+	if err != nil {
+		return 0, fmt.Errorf("could not query db2: %s", err)
+	}
+	return result, nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *db2Scaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.MetricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetQueryValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *db2Scaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	num, err := s.getQueryResult(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting db2: %s", err)
+	}
+
+	metric := GenerateMetricInMili(metricName, num)
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,94 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseDb2MetadataTestData struct {
+	metadata    map[string]string
+	authParams  map[string]string
+	resolvedEnv map[string]string
+	isError     bool
+}
+
+var testDb2Metadata = []parseDb2MetadataTestData{
+	// no metadata
+	{map[string]string{}, nil, nil, true},
+	// connection string
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5"}, map[string]string{"connection": "HOSTNAME=db2;PORT=50000;DATABASE=jobs;UID=keda;PWD=secret"}, nil, false},
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "connectionFromEnv": "DB2_CONNECTION"}, nil, map[string]string{"DB2_CONNECTION": "HOSTNAME=db2;DATABASE=jobs"}, false},
+	// username and password
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "database": "jobs", "username": "keda"}, map[string]string{"password": "secret"}, nil, false},
+	// api key with ssl
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "port": "30376", "database": "bludb", "ssl": "true"}, map[string]string{"apiKey": "key"}, nil, false},
+	// no query
+	{map[string]string{"targetQueryValue": "5", "host": "db2", "database": "jobs", "username": "keda"}, nil, nil, true},
+	// no targetQueryValue
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "host": "db2", "database": "jobs", "username": "keda"}, nil, nil, true},
+	// no database
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "username": "keda"}, nil, nil, true},
+	// no credentials
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "database": "jobs"}, nil, nil, true},
+	// api key and username
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "database": "jobs", "username": "keda"}, map[string]string{"apiKey": "key"}, nil, true},
+	// certificate without ssl
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "database": "jobs", "username": "keda", "sslServerCertificate": "/certs/db2.arm"}, nil, nil, true},
+	// invalid port
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "port": "db2", "database": "jobs", "username": "keda"}, nil, nil, true},
+}
+
+func TestDb2ParseMetadata(t *testing.T) {
+	for i, testData := range testDb2Metadata {
+		_, err := parseDb2Metadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, ResolvedEnv: testData.resolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestDb2ConnectionString(t *testing.T) {
+	meta, err := parseDb2Metadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2", "database": "jobs", "username": "keda"},
+		AuthParams:      map[string]string{"password": "secret"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "HOSTNAME=db2;PORT=50000;DATABASE=jobs;UID=keda;PWD=secret", meta.connectionString())
+
+	meta, err = parseDb2Metadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5", "host": "db2.cloud", "port": "30376", "database": "bludb", "ssl": "true", "sslServerCertificate": "/certs/db2.arm"},
+		AuthParams:      map[string]string{"apiKey": "key"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "HOSTNAME=db2.cloud;PORT=30376;DATABASE=bludb;Authentication=GSSPLUGIN;APIKEY=key;Security=SSL;SSLServerCertificate=/certs/db2.arm", meta.connectionString())
+}
+
+func TestDb2GetMetricSpecForScaling(t *testing.T) {
+	meta, err := parseDb2Metadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "2.5", "metricName": "jobs"},
+		AuthParams:      map[string]string{"connection": "HOSTNAME=db2;DATABASE=jobs"},
+		ScalerIndex:     1,
+	})
+	assert.NoError(t, err)
+	scaler := &db2Scaler{metadata: meta}
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, "s1-db2-jobs", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(2500), metricSpec[0].External.Target.AverageValue.MilliValue())
+}
+
+func TestDb2ScalerWithoutDriver(t *testing.T) {
+	if isDb2DriverRegistered() {
+		t.Skip("the db2 driver is built in")
+	}
+	_, err := NewDb2Scaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetQueryValue": "5"},
+		AuthParams:      map[string]string{"connection": "HOSTNAME=db2;DATABASE=jobs"},
+	})
+	assert.Error(t, err)
+}
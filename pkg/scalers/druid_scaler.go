@@ -0,0 +1,401 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type druidScaler struct {
+	metadata   *druidMetadata
+	httpClient *http.Client
+}
+
+type druidMetadata struct {
+	brokerURL string
+	// query is a Druid SQL query, nativeQuery a native json query whose value is read at valueLocation
+	query         string
+	nativeQuery   string
+	valueLocation string
+	// targetValues has the target of each metric, the SQL queries expose one metric per column of their first row
+	targetValues []float64
+	metricName   string
+	unsafeSsl    bool
+	// missingValues is how the queries without rows or with a null result are handled for each metric
+	missingValues []*missingValuePolicy
+
+	// basic auth
+	enableBasicAuth bool
+	username        string
+	password        string
+
+	// oauth2, the token of the client credentials is sent as bearer token
+	oauth2 *authentication.OAuth2ClientCredentials
+
+	// client certification
+	enableTLS bool
+	cert      string
+	key       string
+	ca        string
+
+	scalerIndex int
+}
+
+var druidLog = logf.Log.WithName("druid_scaler")
+
+// NewDruidScaler creates a new scaler running a query against a Druid broker
+func NewDruidScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseDruidMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing druid metadata: %s", err)
+	}
+
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, meta.unsafeSsl)
+	if meta.enableTLS || meta.ca != "" {
+		tlsConfig, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.InsecureSkipVerify = meta.unsafeSsl
+		httpClient = createTLSHTTPClient(config, config.GlobalHTTPTimeout, tlsConfig)
+	}
+	if meta.oauth2 != nil {
+		httpClient.Transport = meta.oauth2.Transport(httpClient.Transport)
+	}
+
+	return &druidScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseDruidMetadata(config *ScalerConfig) (*druidMetadata, error) {
+	meta := druidMetadata{}
+
+	switch {
+	case config.TriggerMetadata["brokerURL"] != "":
+		meta.brokerURL = config.TriggerMetadata["brokerURL"]
+	case config.AuthParams["brokerURL"] != "":
+		meta.brokerURL = config.AuthParams["brokerURL"]
+	default:
+		return nil, fmt.Errorf("no brokerURL given")
+	}
+	meta.brokerURL = strings.TrimSuffix(meta.brokerURL, "/")
+
+	meta.query = config.TriggerMetadata["query"]
+	meta.nativeQuery = config.TriggerMetadata["nativeQuery"]
+	switch {
+	case meta.query == "" && meta.nativeQuery == "":
+		return nil, fmt.Errorf("no query or nativeQuery given")
+	case meta.query != "" && meta.nativeQuery != "":
+		return nil, fmt.Errorf("only one of query and nativeQuery can be given")
+	case meta.nativeQuery != "":
+		if !json.Valid([]byte(meta.nativeQuery)) {
+			return nil, fmt.Errorf("nativeQuery must be a json query")
+		}
+		meta.valueLocation = config.TriggerMetadata["valueLocation"]
+		if meta.valueLocation == "" {
+			return nil, fmt.Errorf("no valueLocation given for the nativeQuery")
+		}
+		if err := validateValueLocation(meta.valueLocation, config.TriggerMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case config.TriggerMetadata["targetValue"] != "" && config.TriggerMetadata["targetValues"] != "":
+		return nil, fmt.Errorf("only one of targetValue and targetValues can be given")
+	case config.TriggerMetadata["targetValue"] != "":
+		targetValue, err := strconv.ParseFloat(config.TriggerMetadata["targetValue"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValues = []float64{targetValue}
+	case config.TriggerMetadata["targetValues"] != "":
+		if meta.query == "" {
+			return nil, fmt.Errorf("targetValues can only be given with a query")
+		}
+		for _, val := range strings.Split(config.TriggerMetadata["targetValues"], ",") {
+			targetValue, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing targetValues: %s", err)
+			}
+			meta.targetValues = append(meta.targetValues, targetValue)
+		}
+	default:
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("druid-%s", val))
+	} else {
+		meta.metricName = "druid"
+	}
+
+	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok && val != "" {
+		unsafeSsl, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing unsafeSsl: %s", err)
+		}
+		meta.unsafeSsl = unsafeSsl
+	}
+
+	// each metric keeps its own last value
+	for range meta.targetValues {
+		missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+		if err != nil {
+			return nil, err
+		}
+		meta.missingValues = append(meta.missingValues, missingValue)
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	authMode, ok := config.TriggerMetadata["authMode"]
+	// no authMode specified
+	if !ok {
+		return &meta, nil
+	}
+
+	switch authentication.Type(strings.TrimSpace(authMode)) {
+	case authentication.BasicAuthType:
+		if len(config.AuthParams["username"]) == 0 {
+			return nil, errors.New("no username given")
+		}
+		meta.username = config.AuthParams["username"]
+		meta.password = config.AuthParams["password"]
+		meta.enableBasicAuth = true
+	case authentication.TLSAuthType:
+		if len(config.AuthParams["cert"]) == 0 {
+			return nil, errors.New("no cert given")
+		}
+		meta.cert = config.AuthParams["cert"]
+
+		if len(config.AuthParams["key"]) == 0 {
+			return nil, errors.New("no key given")
+		}
+		meta.key = config.AuthParams["key"]
+		meta.enableTLS = true
+	case authentication.OAuth2AuthType:
+		oauth2, err := authentication.ParseOAuth2ClientCredentials(config.AuthParams)
+		if err != nil {
+			return nil, err
+		}
+		meta.oauth2 = oauth2
+	default:
+		return nil, fmt.Errorf("err incorrect value for authMode is given: %s", authMode)
+	}
+
+	meta.ca = config.AuthParams["ca"]
+	return &meta, nil
+}
+
+// IsActive returns true if a value of the query result is greater than 0
+func (s *druidScaler) IsActive(ctx context.Context) (bool, error) {
+	values, err := s.getQueryResult(ctx)
+	if err != nil {
+		druidLog.Error(err, "error querying druid")
+		return false, err
+	}
+
+	for _, value := range values {
+		if value > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Close does nothing in case of druidScaler
+func (s *druidScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+// State returns the last values of the queries kept for valueIfNull: lastValue
+func (s *druidScaler) State() ([]byte, error) {
+	return missingValueState(s.metadata.missingValues...)
+}
+
+// RestoreState restores the last values of the queries
+func (s *druidScaler) RestoreState(state []byte) error {
+	return restoreMissingValueState(state, s.metadata.missingValues...)
+}
+
+// getQueryResult posts the SQL query to /druid/v2/sql or the native query to /druid/v2 and returns the value of each metric
+func (s *druidScaler) getQueryResult(ctx context.Context) ([]float64, error) {
+	url := s.metadata.brokerURL + "/druid/v2"
+	body := []byte(s.metadata.nativeQuery)
+	if s.metadata.query != "" {
+		url += "/sql"
+		// the array format keeps the order of the columns
+		body, _ = json.Marshal(map[string]string{"query": s.metadata.query, "resultFormat": "array"})
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.metadata.enableBasicAuth {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("druid returned %d: %s", resp.StatusCode, getDruidError(result))
+	}
+
+	if s.metadata.nativeQuery != "" {
+		value, err := GetValueFromResponse(result, s.metadata.valueLocation)
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := s.metadata.missingValues[0].resolve(value.AsApproximateFloat64(), true, nil)
+		return []float64{resolved}, err
+	}
+
+	values, found, queryErr := parseDruidSQLResult(result, len(s.metadata.targetValues))
+	if queryErr != nil && !errors.Is(queryErr, errNoQueryResult) {
+		return nil, queryErr
+	}
+	for i, missingValue := range s.metadata.missingValues {
+		if values[i], err = missingValue.resolve(values[i], found[i], queryErr); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// parseDruidSQLResult returns the first columns of the first row of an array result, a value is not found when null.
+// The values are allocated even when the query has no rows.
+func parseDruidSQLResult(result []byte, columns int) ([]float64, []bool, error) {
+	values := make([]float64, columns)
+	found := make([]bool, columns)
+	var rows [][]interface{}
+	if err := json.Unmarshal(result, &rows); err != nil {
+		return values, found, fmt.Errorf("error parsing the query result: %s", err)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return values, found, errNoQueryResult
+	}
+	if len(rows[0]) < columns {
+		return values, found, fmt.Errorf("the query returned %d columns, %d targetValues are given", len(rows[0]), columns)
+	}
+
+	for i := range values {
+		switch value := rows[0][i].(type) {
+		case float64:
+			values[i] = value
+			found[i] = true
+		case nil:
+			// aggregations over no rows return null, it is read as 0 by default
+		default:
+			return values, found, fmt.Errorf("value of type %T could not be converted into a float", value)
+		}
+	}
+	return values, found, nil
+}
+
+// getDruidError returns the message of a Druid error response
+func getDruidError(response []byte) string {
+	var druidError struct {
+		Error        string `json:"error"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if err := json.Unmarshal(response, &druidError); err == nil && druidError.ErrorMessage != "" {
+		return fmt.Sprintf("%s: %s", druidError.Error, druidError.ErrorMessage)
+	}
+	return strings.TrimSpace(string(response))
+}
+
+// getMetricNames returns the name of each metric, the metrics after the first one are suffixed with their column
+func (s *druidScaler) getMetricNames() []string {
+	names := make([]string, len(s.metadata.targetValues))
+	for i := range names {
+		name := s.metadata.metricName
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		names[i] = GenerateMetricNameWithIndex(s.metadata.scalerIndex, name)
+	}
+	return names
+}
+
+// GetMetricSpecForScaling returns the MetricSpec of each metric for the Horizontal Pod Autoscaler
+func (s *druidScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricSpecs := make([]v2beta2.MetricSpec, 0, len(s.metadata.targetValues))
+	for i, name := range s.getMetricNames() {
+		externalMetric := &v2beta2.ExternalMetricSource{
+			Metric: v2beta2.MetricIdentifier{
+				Name: name,
+			},
+			Target: GetMetricTargetMili(s.metadata.targetValues[i]),
+		}
+		metricSpecs = append(metricSpecs, v2beta2.MetricSpec{
+			External: externalMetric, Type: externalMetricType,
+		})
+	}
+	return metricSpecs
+}
+
+// GetAllMetrics returns the value of each metric from one query
+func (s *druidScaler) GetAllMetrics(ctx context.Context, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	values, err := s.getQueryResult(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error querying druid: %s", err)
+	}
+
+	metrics := make([]external_metrics.ExternalMetricValue, 0, len(values))
+	for i, name := range s.getMetricNames() {
+		metrics = append(metrics, GenerateMetricInMili(name, values[i]))
+	}
+	return metrics, nil
+}
+
+// GetMetrics returns the value of metricName from the result of the query
+func (s *druidScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	metrics, err := s.GetAllMetrics(ctx, metricSelector)
+	if err != nil {
+		return metrics, err
+	}
+
+	// a single metric is returned whatever its requested name
+	if len(metrics) == 1 {
+		metrics[0].MetricName = metricName
+		return metrics, nil
+	}
+	for _, metric := range metrics {
+		if strings.EqualFold(metric.MetricName, metricName) {
+			return append([]external_metrics.ExternalMetricValue{}, metric), nil
+		}
+	}
+	return []external_metrics.ExternalMetricValue{}, fmt.Errorf("no druid metric %s", metricName)
+}
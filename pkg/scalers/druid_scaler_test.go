@@ -0,0 +1,225 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseDruidMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type druidMetricIdentifier struct {
+	metadataTestData *parseDruidMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testDruidMetadata = []parseDruidMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// sql query
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "metricName": "jobs"}, map[string]string{}, false},
+	// native query
+	{map[string]string{"brokerURL": "http://druid:8082", "nativeQuery": `{"queryType": "timeseries"}`, "valueLocation": "0.result.count", "targetValue": "2.5"}, map[string]string{}, false},
+	// brokerURL in authParams
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetValue": "10"}, map[string]string{"brokerURL": "http://druid:8082"}, false},
+	// no brokerURL
+	{map[string]string{"query": "SELECT COUNT(*) FROM jobs", "targetValue": "10"}, map[string]string{}, true},
+	// no query
+	{map[string]string{"brokerURL": "http://druid:8082", "targetValue": "10"}, map[string]string{}, true},
+	// both queries
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "nativeQuery": `{}`, "valueLocation": "0", "targetValue": "10"}, map[string]string{}, true},
+	// invalid native query
+	{map[string]string{"brokerURL": "http://druid:8082", "nativeQuery": `{"queryType"`, "valueLocation": "0", "targetValue": "10"}, map[string]string{}, true},
+	// native query with invalid valueLocation
+	{map[string]string{"brokerURL": "http://druid:8082", "nativeQuery": `{"queryType": "timeseries"}`, "valueLocation": "0.#(result.count>1", "targetValue": "10"}, map[string]string{}, true},
+	// native query without valueLocation
+	{map[string]string{"brokerURL": "http://druid:8082", "nativeQuery": `{"queryType": "timeseries"}`, "targetValue": "10"}, map[string]string{}, true},
+	// no targetValue
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs"}, map[string]string{}, true},
+	// invalid targetValue
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "ten"}, map[string]string{}, true},
+	// invalid unsafeSsl
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "unsafeSsl": "yes please"}, map[string]string{}, true},
+	// basic auth
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "authMode": "basic"}, map[string]string{"username": "user", "password": "secret"}, false},
+	// basic auth without username
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "authMode": "basic"}, map[string]string{"password": "secret"}, true},
+	// tls auth
+	{map[string]string{"brokerURL": "https://druid:8282", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "authMode": "tls"}, map[string]string{"cert": "cert", "key": "key", "ca": "ca"}, false},
+	// tls auth without key
+	{map[string]string{"brokerURL": "https://druid:8282", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "authMode": "tls"}, map[string]string{"cert": "cert"}, true},
+	// oauth2 auth
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "authMode": "oauth2"}, map[string]string{"tokenURL": "http://idp/token", "clientID": "keda", "clientSecret": "secret"}, false},
+	// unknown authMode
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*) FROM jobs", "targetValue": "10", "authMode": "bearer"}, map[string]string{"token": "token"}, true},
+	// a target per column
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*), MAX(age) FROM jobs", "targetValues": "10, 300", "metricName": "jobs"}, map[string]string{}, false},
+	// invalid targetValues
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*), MAX(age) FROM jobs", "targetValues": "10,old"}, map[string]string{}, true},
+	// both targetValue and targetValues
+	{map[string]string{"brokerURL": "http://druid:8082", "query": "SELECT COUNT(*), MAX(age) FROM jobs", "targetValue": "10", "targetValues": "10,300"}, map[string]string{}, true},
+	// targetValues with a native query
+	{map[string]string{"brokerURL": "http://druid:8082", "nativeQuery": `{"queryType": "timeseries"}`, "valueLocation": "0.result.count", "targetValues": "10,300"}, map[string]string{}, true},
+}
+
+var druidMetricIdentifiers = []druidMetricIdentifier{
+	{&testDruidMetadata[1], 0, "s0-druid-jobs"},
+	{&testDruidMetadata[2], 1, "s1-druid"},
+	{&testDruidMetadata[19], 2, "s2-druid-jobs"},
+}
+
+func TestDruidParseMetadata(t *testing.T) {
+	for i, testData := range testDruidMetadata {
+		_, err := parseDruidMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestDruidGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range druidMetricIdentifiers {
+		meta, err := parseDruidMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockDruidScaler := druidScaler{metadata: meta}
+
+		metricSpec := mockDruidScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestDruidGetMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "secret", password)
+
+		var query map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		switch r.URL.Path {
+		case "/druid/v2/sql":
+			assert.Equal(t, "array", query["resultFormat"])
+			switch query["query"] {
+			case "SELECT COUNT(*), MAX(__time) FROM jobs":
+				fmt.Fprint(w, `[[7, "2021-12-20T10:00:00.000Z"]]`)
+			case "SELECT SUM(lag) FROM jobs":
+				fmt.Fprint(w, `[[null]]`)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "SQL parse failed", "errorMessage": "Object 'missing' not found"}`)
+			}
+		case "/druid/v2":
+			assert.Equal(t, "timeseries", query["queryType"])
+			fmt.Fprint(w, `[{"timestamp": "2021-12-20T00:00:00.000Z", "result": {"count": 3.5}}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	getMetric := func(metadata map[string]string) (int64, error) {
+		metadata["brokerURL"] = server.URL + "/"
+		metadata["targetValue"] = "10"
+		metadata["authMode"] = "basic"
+		scaler, err := NewDruidScaler(&ScalerConfig{TriggerMetadata: metadata, AuthParams: map[string]string{"username": "user", "password": "secret"}})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		metrics, err := scaler.GetMetrics(context.Background(), "s0-druid", nil)
+		if err != nil {
+			return 0, err
+		}
+		return metrics[0].Value.MilliValue(), nil
+	}
+
+	value, err := getMetric(map[string]string{"query": "SELECT COUNT(*), MAX(__time) FROM jobs"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7000), value)
+
+	value, err = getMetric(map[string]string{"query": "SELECT SUM(lag) FROM jobs"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), value)
+
+	value, err = getMetric(map[string]string{"nativeQuery": `{"queryType": "timeseries", "dataSource": "jobs"}`, "valueLocation": "0.result.count"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3500), value)
+
+	_, err = getMetric(map[string]string{"query": "SELECT COUNT(*) FROM missing"})
+	assert.EqualError(t, err, "error querying druid: druid returned 400: SQL parse failed: Object 'missing' not found")
+}
+
+func TestParseDruidSQLResult(t *testing.T) {
+	values, found, err := parseDruidSQLResult([]byte(`[[12.5, "a"], [1, "b"]]`), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true}, found)
+	assert.Equal(t, []float64{12.5}, values)
+
+	values, found, err = parseDruidSQLResult([]byte(`[[3, null, 40]]`), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, found)
+	assert.Equal(t, []float64{3, 0, 40}, values)
+
+	_, found, err = parseDruidSQLResult([]byte(`[[null]]`), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false}, found)
+
+	for _, result := range []string{`[]`, `[[]]`, `[["a"]]`, `{"count": 1}`, `[[1]]`} {
+		_, _, err := parseDruidSQLResult([]byte(result), 2)
+		assert.Error(t, err, result)
+	}
+}
+
+func TestDruidGetAllMetrics(t *testing.T) {
+	queries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		fmt.Fprint(w, `[[7, 120.5]]`)
+	}))
+	defer server.Close()
+
+	scaler, err := NewDruidScaler(&ScalerConfig{TriggerMetadata: map[string]string{"brokerURL": server.URL, "query": "SELECT COUNT(*), MAX(age) FROM jobs", "targetValues": "10,300", "metricName": "jobs"}})
+	if err != nil {
+		t.Fatal("Could not create scaler:", err)
+	}
+
+	metricSpecs := scaler.GetMetricSpecForScaling(context.Background())
+	assert.Len(t, metricSpecs, 2)
+	assert.Equal(t, "s0-druid-jobs", metricSpecs[0].External.Metric.Name)
+	assert.Equal(t, "s0-druid-jobs-1", metricSpecs[1].External.Metric.Name)
+	assert.Equal(t, int64(300), metricSpecs[1].External.Target.AverageValue.Value())
+
+	metrics, err := scaler.(MultiMetricScaler).GetAllMetrics(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queries)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, "s0-druid-jobs", metrics[0].MetricName)
+	assert.Equal(t, int64(7000), metrics[0].Value.MilliValue())
+	assert.Equal(t, "s0-druid-jobs-1", metrics[1].MetricName)
+	assert.Equal(t, int64(120500), metrics[1].Value.MilliValue())
+
+	metrics, err = scaler.GetMetrics(context.Background(), "s0-druid-jobs-1", nil)
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, int64(120500), metrics[0].Value.MilliValue())
+
+	_, err = scaler.GetMetrics(context.Background(), "s0-druid-jobs-2", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// envoyConcurrencyStats are the gauges of the in-flight requests of an Envoy cluster,
+// the active requests and the requests queued by the circuit breaker
+var envoyConcurrencyStats = []string{"upstream_rq_active", "upstream_rq_pending_active"}
+
+type envoyConcurrencyScaler struct {
+	metadata   *envoyConcurrencyMetadata
+	prometheus *prometheusScaler
+	httpClient *http.Client
+}
+
+type envoyConcurrencyMetadata struct {
+	Service           string  `keda:"name=service"`
+	Namespace         string  `keda:"name=namespace, optional"`
+	Port              *uint16 `keda:"name=port, optional"`
+	ClusterDomain     string  `keda:"name=clusterDomain, default=cluster.local"`
+	ServerAddress     string  `keda:"name=serverAddress, optional"`
+	TargetConcurrency float64 `keda:"name=targetConcurrency"`
+
+	// EnvoyAdminAddress is the admin interface of an Envoy the stats are read from, eg. of the ingress gateway,
+	// the Istio telemetry in Prometheus at ServerAddress is queried instead when it isn't set
+	EnvoyAdminAddress string `keda:"name=envoyAdminAddress, optional"`
+
+	// clusterPattern matches the Istio outbound clusters of the service, outbound|<port>|<subset>|<host>
+	clusterPattern string
+	scalerIndex    int
+}
+
+// Validate checks the stats are read from Prometheus or from an Envoy
+func (m *envoyConcurrencyMetadata) Validate() error {
+	if m.EnvoyAdminAddress == "" && m.ServerAddress == "" {
+		return fmt.Errorf("no %s or envoyAdminAddress given", promServerAddress)
+	}
+	if m.TargetConcurrency <= 0 {
+		return fmt.Errorf("targetConcurrency must be greater than 0")
+	}
+	return nil
+}
+
+var envoyConcurrencyLog = logf.Log.WithName("envoy_concurrency_scaler")
+
+// NewEnvoyConcurrencyScaler creates a new scaler reporting the in-flight requests to a service from the stats of
+// the Envoy proxies calling it, the replicas are scaled on the concurrency like the Knative Pod Autoscaler does
+func NewEnvoyConcurrencyScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseEnvoyConcurrencyMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing envoy-concurrency metadata: %s", err)
+	}
+
+	scaler := &envoyConcurrencyScaler{metadata: meta}
+	if meta.EnvoyAdminAddress != "" {
+		scaler.httpClient = createHTTPClient(config, config.GlobalHTTPTimeout, false)
+		return scaler, nil
+	}
+
+	// the label matcher is a double-quoted PromQL string, its backslashes are escaped
+	matcher := strings.ReplaceAll(meta.clusterPattern, `\`, `\\`)
+	queries := make([]string, 0, len(envoyConcurrencyStats))
+	for _, stat := range envoyConcurrencyStats {
+		queries = append(queries, fmt.Sprintf(`sum(envoy_cluster_%s{cluster_name=~"%s"} or vector(0))`, stat, matcher))
+	}
+	scaler.prometheus, err = newPrometheusQuery(config, strings.Join(queries, " + "), meta.Service)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing envoy-concurrency metadata: %s", err)
+	}
+	return scaler, nil
+}
+
+func parseEnvoyConcurrencyMetadata(config *ScalerConfig) (*envoyConcurrencyMetadata, error) {
+	meta := envoyConcurrencyMetadata{}
+	if err := config.TypedConfig(&meta); err != nil {
+		return nil, err
+	}
+	if meta.Namespace == "" {
+		meta.Namespace = config.Namespace
+	}
+	meta.EnvoyAdminAddress = strings.TrimSuffix(meta.EnvoyAdminAddress, "/")
+
+	port := "[0-9]+"
+	if meta.Port != nil {
+		port = strconv.Itoa(int(*meta.Port))
+	}
+	host := fmt.Sprintf("%s.%s.svc.%s", meta.Service, meta.Namespace, meta.ClusterDomain)
+	meta.clusterPattern = fmt.Sprintf(`outbound\|%s\|[^|]*\|%s`, port, regexp.QuoteMeta(host))
+
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// envoyStats is the JSON format of the stats of the Envoy admin interface
+type envoyStats struct {
+	Stats []struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	} `json:"stats"`
+}
+
+// getConcurrency returns the in-flight requests to the service
+func (s *envoyConcurrencyScaler) getConcurrency(ctx context.Context) (float64, error) {
+	if s.prometheus != nil {
+		return s.prometheus.ExecutePromQuery(ctx)
+	}
+
+	filter := fmt.Sprintf(`^cluster\.%s\.(%s)$`, s.metadata.clusterPattern, strings.Join(envoyConcurrencyStats, "|"))
+	statsURL := fmt.Sprintf("%s/stats?format=json&filter=%s", s.metadata.EnvoyAdminAddress, url.QueryEscape(filter))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statsURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("envoy admin returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var stats envoyStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return 0, fmt.Errorf("error parsing the envoy stats: %s", err)
+	}
+	var concurrency float64
+	for _, stat := range stats.Stats {
+		// the histograms of the stats don't have a value
+		value, err := strconv.ParseFloat(string(stat.Value), 64)
+		if err != nil {
+			continue
+		}
+		concurrency += value
+	}
+	return concurrency, nil
+}
+
+// IsActive returns true if the service has requests in flight
+func (s *envoyConcurrencyScaler) IsActive(ctx context.Context) (bool, error) {
+	concurrency, err := s.getConcurrency(ctx)
+	if err != nil {
+		envoyConcurrencyLog.Error(err, "error getting the concurrency")
+		return false, err
+	}
+	return concurrency > 0, nil
+}
+
+func (s *envoyConcurrencyScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+func (s *envoyConcurrencyScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("envoy-concurrency-%s", s.metadata.Service))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetConcurrency),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the in-flight requests to the service
+func (s *envoyConcurrencyScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	concurrency, err := s.getConcurrency(ctx)
+	if err != nil {
+		envoyConcurrencyLog.Error(err, "error getting the concurrency")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, concurrency)
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,99 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseEnvoyConcurrencyMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+var testEnvoyConcurrencyMetadata = []parseEnvoyConcurrencyMetadataTestData{
+	{map[string]string{}, true},
+	// Istio telemetry in Prometheus
+	{map[string]string{"serverAddress": "http://localhost:9090", "service": "checkout", "targetConcurrency": "10"}, false},
+	// Envoy admin with port and namespace
+	{map[string]string{"envoyAdminAddress": "http://istio-ingressgateway-admin:15000", "service": "checkout", "namespace": "shop", "port": "8080", "targetConcurrency": "2.5"}, false},
+	// no service
+	{map[string]string{"serverAddress": "http://localhost:9090", "targetConcurrency": "10"}, true},
+	// no source
+	{map[string]string{"service": "checkout", "targetConcurrency": "10"}, true},
+	// invalid port
+	{map[string]string{"serverAddress": "http://localhost:9090", "service": "checkout", "port": "http", "targetConcurrency": "10"}, true},
+	// no targetConcurrency
+	{map[string]string{"serverAddress": "http://localhost:9090", "service": "checkout"}, true},
+	// invalid targetConcurrency
+	{map[string]string{"serverAddress": "http://localhost:9090", "service": "checkout", "targetConcurrency": "-1"}, true},
+}
+
+func TestEnvoyConcurrencyParseMetadata(t *testing.T) {
+	for i, testData := range testEnvoyConcurrencyMetadata {
+		_, err := NewEnvoyConcurrencyScaler(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "default"})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", i)
+		}
+	}
+}
+
+func TestEnvoyConcurrencyClusterPattern(t *testing.T) {
+	meta, err := parseEnvoyConcurrencyMetadata(&ScalerConfig{TriggerMetadata: map[string]string{"serverAddress": "http://localhost:9090", "service": "checkout", "targetConcurrency": "10"}, Namespace: "shop"})
+	assert.NoError(t, err)
+
+	pattern := regexp.MustCompile("^" + meta.clusterPattern + "$")
+	assert.True(t, pattern.MatchString("outbound|8080||checkout.shop.svc.cluster.local"))
+	assert.True(t, pattern.MatchString("outbound|80|v2|checkout.shop.svc.cluster.local"))
+	assert.False(t, pattern.MatchString("inbound|8080||"))
+	assert.False(t, pattern.MatchString("outbound|8080||checkout.shop-canary.svc.cluster.local"))
+	assert.False(t, pattern.MatchString("outbound|8080||checkoutXshop.svc.cluster.local"))
+}
+
+func TestEnvoyConcurrencyGetMetrics(t *testing.T) {
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stats", r.URL.Path)
+		assert.Equal(t, `^cluster\.outbound\|8080\|[^|]*\|checkout\.shop\.svc\.cluster\.local\.(upstream_rq_active|upstream_rq_pending_active)$`, r.URL.Query().Get("filter"))
+		fmt.Fprint(w, `{"stats": [`+
+			`{"name": "cluster.outbound|8080||checkout.shop.svc.cluster.local.upstream_rq_active", "value": 7},`+
+			`{"name": "cluster.outbound|8080||checkout.shop.svc.cluster.local.upstream_rq_pending_active", "value": 2},`+
+			`{"histograms": {}}]}`)
+	}))
+	defer admin.Close()
+
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `sum(envoy_cluster_upstream_rq_active{cluster_name=~"outbound\\|[0-9]+\\|[^|]*\\|checkout\\.shop\\.svc\\.cluster\\.local"} or vector(0)) + `+
+			`sum(envoy_cluster_upstream_rq_pending_active{cluster_name=~"outbound\\|[0-9]+\\|[^|]*\\|checkout\\.shop\\.svc\\.cluster\\.local"} or vector(0))`, r.URL.Query().Get("query"))
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"12"]}]}}`)
+	}))
+	defer prometheus.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		expected int64
+	}{
+		{map[string]string{"envoyAdminAddress": admin.URL, "service": "checkout", "port": "8080", "targetConcurrency": "5"}, 9000},
+		{map[string]string{"serverAddress": prometheus.URL, "service": "checkout", "targetConcurrency": "5"}, 12000},
+	}
+
+	for _, test := range tests {
+		scaler, err := NewEnvoyConcurrencyScaler(&ScalerConfig{TriggerMetadata: test.metadata, Namespace: "shop"})
+		assert.NoError(t, err)
+
+		spec := scaler.GetMetricSpecForScaling(context.Background())
+		assert.Equal(t, "s0-envoy-concurrency-checkout", spec[0].External.Metric.Name)
+		assert.Equal(t, int64(5000), spec[0].External.Target.AverageValue.MilliValue())
+
+		metrics, err := scaler.GetMetrics(context.Background(), "s0-envoy-concurrency-checkout", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, metrics[0].Value.MilliValue())
+	}
+}
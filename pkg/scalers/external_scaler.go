@@ -2,6 +2,7 @@ package scalers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -9,9 +10,15 @@ import (
 
 	"github.com/mitchellh/hashstructure"
 
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
 	pb "github.com/kedacore/keda/v2/pkg/scalers/externalscaler"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,13 +27,37 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	defaultExternalScalerTimeout = 10 * time.Second
+	// the default keepalive time matches the minimum ping interval enforced by gRPC servers by default,
+	// faster pings are rejected with too_many_pings unless the external scaler permits them
+	defaultExternalScalerKeepAliveTime = 5 * time.Minute
+	externalScalerKeepAliveTimeout     = 20 * time.Second
+	// the streams of the push scalers are reopened after a backoff doubling from the base delay up to the max delay,
+	// it is reset once a stream received a message
+	defaultExternalPushReconnectBaseDelay = 2 * time.Second
+	defaultExternalPushReconnectMaxDelay  = time.Minute
+)
+
 type externalScaler struct {
 	metadata        externalScalerMetadata
 	scaledObjectRef pb.ScaledObjectRef
+	grpcConnection  *grpc.ClientConn
+	grpcClient      pb.ExternalScalerClient
+	// done releases the connection of the pool
+	done    func()
+	timeout time.Duration
 }
 
 type externalPushScaler struct {
 	externalScaler
+
+	lock sync.Mutex
+	// lastPush is the time of the last message of the stream, or of the start of Run, lastActivePush is the time
+	// of the last active message
+	lastPush       time.Time
+	lastActivePush time.Time
+	now            func() time.Time
 }
 
 type externalScalerMetadata struct {
@@ -34,26 +65,105 @@ type externalScalerMetadata struct {
 	tlsCertFile      string
 	originalMetadata map[string]string
 	scalerIndex      int
+	keepAliveTime    time.Duration
+
+	// push, the trigger stays active quietPeriod after the last active message of the stream, a stream without
+	// message for livenessDeadline is reopened and the trigger fails meanwhile so the fallback applies,
+	// both are disabled when 0
+	quietPeriod        time.Duration
+	livenessDeadline   time.Duration
+	reconnectBaseDelay time.Duration
+	reconnectMaxDelay  time.Duration
+
+	// TLS
+	enableTLS bool
+	cert      string
+	key       string
+	ca        string
+	// serverName overrides the name verified in the certificate of the external scaler,
+	// eg. when it is reached through a load balancer of another cluster
+	serverName string
+
+	// bearer, sent with every call
+	enableBearerAuth bool
+	bearerToken      string
+
+	// SPIFFE, the SVID of the operator authenticates to the external scaler
+	enableSPIFFE   bool
+	spiffeServerID string
+}
+
+// bearerCredentials adds the bearer token to the metadata of every gRPC call
+type bearerCredentials struct {
+	token string
+}
+
+func (c bearerCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+// RequireTransportSecurity prevents sending the token in plaintext
+func (c bearerCredentials) RequireTransportSecurity() bool {
+	return true
 }
 
 type connectionGroup struct {
 	grpcConnection *grpc.ClientConn
-	waitGroup      *sync.WaitGroup
+	references     int
 }
 
-// a pool of connectionGroup per metadata hash
-var connectionPool sync.Map
+// connectionKey holds the connection properties of the metadata, hashstructure only hashes exported fields
+type connectionKey struct {
+	ScalerAddress string
+	TLSCertFile   string
+	KeepAliveTime time.Duration
+	Cert          string
+	Key           string
+	CA            string
+	ServerName    string
+	BearerToken   string
+	SPIFFE        bool
+	SPIFFEServer  string
+}
+
+// a pool of connectionGroup per connectionKey hash
+var connectionPool = map[uint64]*connectionGroup{}
 
 var externalLog = logf.Log.WithName("external_scaler")
 
 // NewExternalScaler creates a new external scaler - calls the GRPC interface
 // to create a new scaler
 func NewExternalScaler(config *ScalerConfig) (Scaler, error) {
+	return newExternalScaler(config)
+}
+
+// NewExternalPushScaler creates a new externalPushScaler push scaler
+func NewExternalPushScaler(config *ScalerConfig) (PushScaler, error) {
+	scaler, err := newExternalScaler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalPushScaler{externalScaler: *scaler, now: time.Now}, nil
+}
+
+// newExternalScaler takes a persistent connection of the pool, it is released on Close()
+func newExternalScaler(config *ScalerConfig) (*externalScaler, error) {
 	meta, err := parseExternalScalerMetadata(config)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing external scaler metadata: %s", err)
 	}
 
+	conn, done, err := getClientForConnectionPool(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error building grpc connection: %s", err)
+	}
+
+	timeout := config.GlobalHTTPTimeout
+	if timeout <= 0 {
+		timeout = defaultExternalScalerTimeout
+	}
+
 	return &externalScaler{
 		metadata: meta,
 		scaledObjectRef: pb.ScaledObjectRef{
@@ -61,25 +171,10 @@ func NewExternalScaler(config *ScalerConfig) (Scaler, error) {
 			Namespace:      config.Namespace,
 			ScalerMetadata: meta.originalMetadata,
 		},
-	}, nil
-}
-
-// NewExternalPushScaler creates a new externalPushScaler push scaler
-func NewExternalPushScaler(config *ScalerConfig) (PushScaler, error) {
-	meta, err := parseExternalScalerMetadata(config)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing external scaler metadata: %s", err)
-	}
-
-	return &externalPushScaler{
-		externalScaler{
-			metadata: meta,
-			scaledObjectRef: pb.ScaledObjectRef{
-				Name:           config.Name,
-				Namespace:      config.Namespace,
-				ScalerMetadata: meta.originalMetadata,
-			},
-		},
+		grpcConnection: conn,
+		grpcClient:     pb.NewExternalScalerClient(conn),
+		done:           done,
+		timeout:        timeout,
 	}, nil
 }
 
@@ -99,6 +194,26 @@ func parseExternalScalerMetadata(config *ScalerConfig) (externalScalerMetadata,
 		meta.tlsCertFile = val
 	}
 
+	meta.keepAliveTime = defaultExternalScalerKeepAliveTime
+	if val, ok := config.TriggerMetadata["keepAliveTime"]; ok && val != "" {
+		keepAliveTime, err := time.ParseDuration(val)
+		if err != nil {
+			return meta, fmt.Errorf("error parsing keepAliveTime: %s", err)
+		}
+		if keepAliveTime < 10*time.Second {
+			return meta, fmt.Errorf("keepAliveTime must be at least 10s")
+		}
+		meta.keepAliveTime = keepAliveTime
+	}
+
+	if err := parseExternalPushMetadata(config, &meta); err != nil {
+		return meta, err
+	}
+
+	if err := parseExternalScalerAuth(config, &meta); err != nil {
+		return meta, err
+	}
+
 	meta.originalMetadata = make(map[string]string)
 
 	// Add elements to metadata
@@ -116,15 +231,157 @@ func parseExternalScalerMetadata(config *ScalerConfig) (externalScalerMetadata,
 	return meta, nil
 }
 
+// parseExternalPushMetadata parses the quiet period, the liveness deadline and the reconnect backoff of the stream
+// of the push scalers
+func parseExternalPushMetadata(config *ScalerConfig, meta *externalScalerMetadata) error {
+	durations := []struct {
+		key          string
+		value        *time.Duration
+		defaultValue time.Duration
+	}{
+		{"quietPeriod", &meta.quietPeriod, 0},
+		{"livenessDeadline", &meta.livenessDeadline, 0},
+		{"reconnectBaseDelay", &meta.reconnectBaseDelay, defaultExternalPushReconnectBaseDelay},
+		{"reconnectMaxDelay", &meta.reconnectMaxDelay, defaultExternalPushReconnectMaxDelay},
+	}
+	for _, d := range durations {
+		*d.value = d.defaultValue
+		val, ok := config.TriggerMetadata[d.key]
+		if !ok || val == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %s", d.key, err)
+		}
+		if duration < 0 {
+			return fmt.Errorf("%s can't be negative", d.key)
+		}
+		*d.value = duration
+	}
+	if meta.reconnectBaseDelay <= 0 {
+		return fmt.Errorf("reconnectBaseDelay must be greater than 0")
+	}
+	if meta.reconnectMaxDelay < meta.reconnectBaseDelay {
+		return fmt.Errorf("reconnectMaxDelay must be at least reconnectBaseDelay")
+	}
+	return nil
+}
+
+func parseExternalScalerAuth(config *ScalerConfig, meta *externalScalerMetadata) error {
+	meta.serverName = config.TriggerMetadata["serverName"]
+	if len(config.AuthParams["ca"]) > 0 {
+		meta.ca = config.AuthParams["ca"]
+	}
+
+	authModes, ok := config.TriggerMetadata["authModes"]
+	// no authMode specified
+	if !ok {
+		return nil
+	}
+
+	authTypes := strings.Split(authModes, ",")
+	for _, t := range authTypes {
+		authType := authentication.Type(strings.TrimSpace(t))
+		switch authType {
+		case authentication.TLSAuthType:
+			if len(config.AuthParams["cert"]) == 0 {
+				return errors.New("no cert given")
+			}
+			meta.cert = config.AuthParams["cert"]
+
+			if len(config.AuthParams["key"]) == 0 {
+				return errors.New("no key given")
+			}
+
+			meta.key = config.AuthParams["key"]
+			meta.enableTLS = true
+		case authentication.BearerAuthType:
+			if len(config.AuthParams["bearerToken"]) == 0 {
+				return errors.New("no bearer token provided")
+			}
+
+			meta.bearerToken = config.AuthParams["bearerToken"]
+			meta.enableBearerAuth = true
+		default:
+			return fmt.Errorf("err incorrect value for authMode is given: %s", t)
+		}
+	}
+
+	if config.PodIdentity == kedav1alpha1.PodIdentityProviderSpiffe {
+		if meta.enableTLS || meta.ca != "" || meta.tlsCertFile != "" {
+			return errors.New("spiffe pod identity can't be used with tlsCertFile, ca or the tls authMode")
+		}
+		meta.enableSPIFFE = true
+		meta.spiffeServerID = config.TriggerMetadata["spiffeServerID"]
+	}
+
+	if meta.enableBearerAuth && !meta.enableTLS && !meta.enableSPIFFE && meta.ca == "" && meta.tlsCertFile == "" {
+		return errors.New("bearer authentication requires a TLS connection, set tlsCertFile, ca or the tls authMode")
+	}
+	return nil
+}
+
+// buildGRPCDialOptions returns the credentials of the connection to the external scaler
+func buildGRPCDialOptions(metadata externalScalerMetadata) ([]grpc.DialOption, error) {
+	opts := []grpc.DialOption{
+		// keepalive pings detect an external scaler restarted behind a connection that looks open
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    metadata.keepAliveTime,
+			Timeout: externalScalerKeepAliveTimeout,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  time.Second,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   time.Minute,
+			},
+			MinConnectTimeout: defaultExternalScalerTimeout,
+		}),
+	}
+
+	switch {
+	case metadata.enableSPIFFE:
+		tlsConfig, err := kedautil.NewSPIFFETLSConfig(metadata.spiffeServerID)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	case metadata.enableTLS || metadata.ca != "":
+		tlsConfig, err := kedautil.NewTLSConfig(metadata.cert, metadata.key, metadata.ca)
+		if err != nil {
+			return nil, err
+		}
+		// the external scaler is verified against the CA, or the system roots without CA
+		tlsConfig.InsecureSkipVerify = false
+		tlsConfig.ServerName = metadata.serverName
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	case metadata.tlsCertFile != "":
+		creds, err := credentials.NewClientTLSFromFile(metadata.tlsCertFile, metadata.serverName)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	default:
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	if metadata.enableBearerAuth {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerCredentials{token: metadata.bearerToken}))
+	}
+	return opts, nil
+}
+
 // IsActive checks if there are any messages in the subscription
 func (s *externalScaler) IsActive(ctx context.Context) (bool, error) {
-	grpcClient, done, err := getClientForConnectionPool(s.metadata)
-	if err != nil {
+	if err := s.checkConnection(); err != nil {
 		return false, err
 	}
-	defer done()
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
 
-	response, err := grpcClient.IsActive(ctx, &s.scaledObjectRef)
+	response, err := s.grpcClient.IsActive(ctx, &s.scaledObjectRef)
 	if err != nil {
 		externalLog.Error(err, "error calling IsActive on external scaler")
 		return false, err
@@ -134,6 +391,21 @@ func (s *externalScaler) IsActive(ctx context.Context) (bool, error) {
 }
 
 func (s *externalScaler) Close(context.Context) error {
+	if s.done != nil {
+		s.done()
+	}
+	return nil
+}
+
+// checkConnection fails fast while the connection to the external scaler is broken, so the failure is
+// reported in the health of the ScaledObject instead of every call waiting for its timeout
+func (s *externalScaler) checkConnection() error {
+	if s.grpcConnection == nil {
+		return fmt.Errorf("no grpc connection to external scaler %s", s.metadata.scalerAddress)
+	}
+	if state := s.grpcConnection.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+		return fmt.Errorf("external scaler %s is unavailable, connection is %s", s.metadata.scalerAddress, state)
+	}
 	return nil
 }
 
@@ -141,14 +413,14 @@ func (s *externalScaler) Close(context.Context) error {
 func (s *externalScaler) GetMetricSpecForScaling(ctx context.Context) []v2beta2.MetricSpec {
 	var result []v2beta2.MetricSpec
 
-	grpcClient, done, err := getClientForConnectionPool(s.metadata)
-	if err != nil {
-		externalLog.Error(err, "error building grpc connection")
+	if err := s.checkConnection(); err != nil {
+		externalLog.Error(err, "error checking grpc connection")
 		return result
 	}
-	defer done()
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
 
-	response, err := grpcClient.GetMetricSpec(ctx, &s.scaledObjectRef)
+	response, err := s.grpcClient.GetMetricSpec(ctx, &s.scaledObjectRef)
 	if err != nil {
 		externalLog.Error(err, "error")
 		return nil
@@ -183,18 +455,18 @@ func (s *externalScaler) GetMetricSpecForScaling(ctx context.Context) []v2beta2.
 // GetMetrics connects calls the gRPC interface to get the metrics with a specific name
 func (s *externalScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
 	var metrics []external_metrics.ExternalMetricValue
-	grpcClient, done, err := getClientForConnectionPool(s.metadata)
-	if err != nil {
+	if err := s.checkConnection(); err != nil {
 		return metrics, err
 	}
-	defer done()
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
 
 	request := &pb.GetMetricsRequest{
 		MetricName:      metricName,
 		ScaledObjectRef: &s.scaledObjectRef,
 	}
 
-	response, err := grpcClient.GetMetrics(ctx, request)
+	response, err := s.grpcClient.GetMetrics(ctx, request)
 	if err != nil {
 		externalLog.Error(err, "error")
 		return []external_metrics.ExternalMetricValue{}, err
@@ -213,130 +485,240 @@ func (s *externalScaler) GetMetrics(ctx context.Context, metricName string, metr
 	return metrics, nil
 }
 
-// handleIsActiveStream is the only writer to the active channel and will close it on return.
+// IsActive is true during the quiet period after the last active message of the stream, otherwise the external
+// scaler is queried. It fails while the stream is past its liveness deadline
+func (s *externalPushScaler) IsActive(ctx context.Context) (bool, error) {
+	if err := s.checkLiveness(); err != nil {
+		return false, err
+	}
+	if s.isQuiet() {
+		return true, nil
+	}
+	return s.externalScaler.IsActive(ctx)
+}
+
+// GetMetrics fails while the stream is past its liveness deadline so the fallback of the trigger applies
+func (s *externalPushScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if err := s.checkLiveness(); err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+	return s.externalScaler.GetMetrics(ctx, metricName, metricSelector)
+}
+
+// checkLiveness fails when the stream didn't receive a message for the liveness deadline, eg. after a silent
+// disconnect, the external scaler has to send messages more often than the deadline
+func (s *externalPushScaler) checkLiveness() error {
+	if s.metadata.livenessDeadline <= 0 {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.lastPush.IsZero() {
+		return nil
+	}
+	if since := s.now().Sub(s.lastPush); since > s.metadata.livenessDeadline {
+		return fmt.Errorf("no message from external push scaler %s for %s, the liveness deadline is %s", s.metadata.scalerAddress, since.Round(time.Second), s.metadata.livenessDeadline)
+	}
+	return nil
+}
+
+// isQuiet returns whether the quiet period after the last active message is running
+func (s *externalPushScaler) isQuiet() bool {
+	if s.metadata.quietPeriod <= 0 {
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return !s.lastActivePush.IsZero() && s.now().Sub(s.lastActivePush) < s.metadata.quietPeriod
+}
+
+// recordPush records a message of the stream, or the start of Run when active is nil
+func (s *externalPushScaler) recordPush(active *bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastPush = s.now()
+	if active != nil && *active {
+		s.lastActivePush = s.lastPush
+	}
+}
+
+// Run is the only writer to the active channel and will close it on return.
 func (s *externalPushScaler) Run(ctx context.Context, active chan<- bool) {
 	defer close(active)
+	s.recordPush(nil)
+
 	// It's possible for the connection to get terminated anytime, we need to run this in a retry loop
-	runWithLog := func() {
-		grpcClient, done, err := getClientForConnectionPool(s.metadata)
-		if err != nil {
-			externalLog.Error(err, "error running internalRun")
+	retryDuration := s.metadata.reconnectBaseDelay
+	for {
+		received, err := s.handleIsActiveStream(ctx, active)
+		if ctx.Err() != nil {
 			return
 		}
-		if err := handleIsActiveStream(ctx, s.scaledObjectRef, grpcClient, active); err != nil {
+		if err != nil {
 			externalLog.Error(err, "error running internalRun")
-			return
 		}
-		done()
-	}
-
-	// retry on error from runWithLog() starting by 2 sec backing off * 2 with a max of 1 minute
-	retryDuration := time.Second * 2
-	// the caller of this function needs to ensure that they call Stop() on the resulting
-	// timer, to release background resources.
-	retryBackoff := func() *time.Timer {
-		tmr := time.NewTimer(retryDuration)
-		retryDuration *= time.Second * 2
-		if retryDuration > time.Minute*1 {
-			retryDuration = time.Minute * 1
+		if received {
+			retryDuration = s.metadata.reconnectBaseDelay
 		}
-		return tmr
-	}
-
-	// start the first run without delay
-	runWithLog()
 
-	for {
-		backoffTimer := retryBackoff()
+		backoffTimer := time.NewTimer(retryDuration)
 		select {
 		case <-ctx.Done():
 			backoffTimer.Stop()
 			return
 		case <-backoffTimer.C:
-			backoffTimer.Stop()
-			runWithLog()
+		}
+		retryDuration *= 2
+		if retryDuration > s.metadata.reconnectMaxDelay {
+			retryDuration = s.metadata.reconnectMaxDelay
 		}
 	}
 }
 
-// handleIsActiveStream calls blocks on a stream call from the GRPC server. It'll only terminate on error, stream completion, or ctx cancellation.
-func handleIsActiveStream(ctx context.Context, scaledObjectRef pb.ScaledObjectRef, grpcClient pb.ExternalScalerClient, active chan<- bool) error {
-	stream, err := grpcClient.StreamIsActive(ctx, &scaledObjectRef)
+// handleIsActiveStream blocks on a stream call from the GRPC server. It'll only terminate on error, stream completion,
+// ctx cancellation or when the liveness deadline elapses without message, received is whether a message was received.
+// With a quiet period the inactive messages are sent once the quiet period after the last active one elapsed, and
+// the trigger is inactive once it elapsed without message
+func (s *externalPushScaler) handleIsActiveStream(ctx context.Context, active chan<- bool) (received bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := s.grpcClient.StreamIsActive(ctx, &s.scaledObjectRef)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	for {
-		resp, err := stream.Recv()
-		if err != nil {
-			return err
+	messages := make(chan bool)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case messages <- resp.Result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var liveness, quiet <-chan time.Time
+	var livenessTimer, quietTimer *time.Timer
+	resetTimer := func(timer **time.Timer, ch *<-chan time.Time, d time.Duration) {
+		if *timer != nil {
+			(*timer).Stop()
+		}
+		*timer = time.NewTimer(d)
+		*ch = (*timer).C
+	}
+	defer func() {
+		for _, timer := range []*time.Timer{livenessTimer, quietTimer} {
+			if timer != nil {
+				timer.Stop()
+			}
 		}
+	}()
+	if s.metadata.livenessDeadline > 0 {
+		resetTimer(&livenessTimer, &liveness, s.metadata.livenessDeadline)
+	}
 
-		active <- resp.Result
+	send := func(result bool) bool {
+		select {
+		case active <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return received, ctx.Err()
+		case err := <-errs:
+			return received, err
+		case <-liveness:
+			return received, fmt.Errorf("no message from external push scaler %s within the liveness deadline %s, reconnecting", s.metadata.scalerAddress, s.metadata.livenessDeadline)
+		case <-quiet:
+			quiet = nil
+			if !send(false) {
+				return received, ctx.Err()
+			}
+		case result := <-messages:
+			received = true
+			s.recordPush(&result)
+			if s.metadata.livenessDeadline > 0 {
+				resetTimer(&livenessTimer, &liveness, s.metadata.livenessDeadline)
+			}
+			if s.metadata.quietPeriod > 0 {
+				if !result && quiet != nil {
+					// sent once the quiet period elapsed
+					continue
+				}
+				if result {
+					resetTimer(&quietTimer, &quiet, s.metadata.quietPeriod)
+				}
+			}
+			if !send(result) {
+				return received, ctx.Err()
+			}
+		}
 	}
 }
 
 var connectionPoolMutex sync.Mutex
 
-// getClientForConnectionPool returns a grpcClient and a done() Func. The done() function must be called once the client is no longer
-// in use to clean up the shared grpc.ClientConn
-func getClientForConnectionPool(metadata externalScalerMetadata) (pb.ExternalScalerClient, func(), error) {
+// getClientForConnectionPool returns a persistent grpc.ClientConn and a done() Func. The done() function must be called once
+// the connection is no longer in use, the shared grpc.ClientConn is closed once every user is done
+func getClientForConnectionPool(metadata externalScalerMetadata) (*grpc.ClientConn, func(), error) {
 	connectionPoolMutex.Lock()
 	defer connectionPoolMutex.Unlock()
 
-	buildGRPCConnection := func(metadata externalScalerMetadata) (*grpc.ClientConn, error) {
-		if metadata.tlsCertFile != "" {
-			creds, err := credentials.NewClientTLSFromFile(metadata.tlsCertFile, "")
-			if err != nil {
-				return nil, err
-			}
-			return grpc.Dial(metadata.scalerAddress, grpc.WithTransportCredentials(creds))
-		}
-
-		return grpc.Dial(metadata.scalerAddress, grpc.WithInsecure())
-	}
-
-	// create a unique key per-metadata. If scaledObjects share the same connection properties
+	// create a unique key per connection properties. If scaledObjects share the same connection properties
 	// in the metadata, they will share the same grpc.ClientConn
-	key, err := hashstructure.Hash(metadata, nil)
+	key, err := hashstructure.Hash(connectionKey{
+		ScalerAddress: metadata.scalerAddress,
+		TLSCertFile:   metadata.tlsCertFile,
+		KeepAliveTime: metadata.keepAliveTime,
+		Cert:          metadata.cert,
+		Key:           metadata.key,
+		CA:            metadata.ca,
+		ServerName:    metadata.serverName,
+		BearerToken:   metadata.bearerToken,
+		SPIFFE:        metadata.enableSPIFFE,
+		SPIFFEServer:  metadata.spiffeServerID,
+	}, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if i, ok := connectionPool.Load(key); ok {
-		if connGroup, ok := i.(*connectionGroup); ok {
-			connGroup.waitGroup.Add(1)
-			return pb.NewExternalScalerClient(connGroup.grpcConnection), func() {
-				connGroup.waitGroup.Done()
-			}, nil
+	connGroup, ok := connectionPool[key]
+	if !ok {
+		opts, err := buildGRPCDialOptions(metadata)
+		if err != nil {
+			return nil, nil, err
 		}
+		// the connection is established in the background and reconnects with backoff
+		conn, err := grpc.Dial(metadata.scalerAddress, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		connGroup = &connectionGroup{grpcConnection: conn}
+		connectionPool[key] = connGroup
 	}
-
-	conn, err := buildGRPCConnection(metadata)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	waitGroup := &sync.WaitGroup{}
-	waitGroup.Add(1)
-	connGroup := connectionGroup{
-		grpcConnection: conn,
-		waitGroup:      waitGroup,
-	}
-
-	connectionPool.Store(key, connGroup)
-
-	go func() {
-		// clean up goroutine.
-		// once all waitGroup is done, remove the connection from the pool and Close() grpc.ClientConn
-		connGroup.waitGroup.Wait()
-		connectionPoolMutex.Lock()
-		defer connectionPoolMutex.Unlock()
-		connectionPool.Delete(key)
-		connGroup.grpcConnection.Close()
-	}()
-
-	return pb.NewExternalScalerClient(connGroup.grpcConnection), func() {
-		connGroup.waitGroup.Done()
+	connGroup.references++
+
+	var once sync.Once
+	return connGroup.grpcConnection, func() {
+		once.Do(func() {
+			connectionPoolMutex.Lock()
+			defer connectionPoolMutex.Unlock()
+			connGroup.references--
+			if connGroup.references == 0 {
+				delete(connectionPool, key)
+				connGroup.grpcConnection.Close()
+			}
+		})
 	}, nil
 }
@@ -8,28 +8,58 @@ import (
 	"testing"
 	"time"
 
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	pb "github.com/kedacore/keda/v2/pkg/scalers/externalscaler"
+	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/status"
 )
 
 type parseExternalScalerMetadataTestData struct {
-	metadata map[string]string
-	isError  bool
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
 }
 
 var testExternalScalerMetadata = []parseExternalScalerMetadataTestData{
-	{map[string]string{}, true},
+	{map[string]string{}, map[string]string{}, true},
 	// all properly formed
-	{map[string]string{"scalerAddress": "myservice", "test1": "7", "test2": "SAMPLE_CREDS"}, false},
+	{map[string]string{"scalerAddress": "myservice", "test1": "7", "test2": "SAMPLE_CREDS"}, map[string]string{}, false},
 	// missing scalerAddress
-	{map[string]string{"test1": "1", "test2": "SAMPLE_CREDS"}, true},
+	{map[string]string{"test1": "1", "test2": "SAMPLE_CREDS"}, map[string]string{}, true},
+	// mTLS
+	{map[string]string{"scalerAddress": "myservice", "authModes": "tls", "serverName": "scaler.example.com"}, map[string]string{"cert": "ceert", "key": "keey", "ca": "caaa"}, false},
+	// mTLS without key
+	{map[string]string{"scalerAddress": "myservice", "authModes": "tls"}, map[string]string{"cert": "ceert"}, true},
+	// mTLS and bearer
+	{map[string]string{"scalerAddress": "myservice", "authModes": "tls, bearer"}, map[string]string{"cert": "ceert", "key": "keey", "bearerToken": "token"}, false},
+	// bearer with server CA
+	{map[string]string{"scalerAddress": "myservice", "authModes": "bearer"}, map[string]string{"ca": "caaa", "bearerToken": "token"}, false},
+	// bearer without token
+	{map[string]string{"scalerAddress": "myservice", "authModes": "bearer"}, map[string]string{"ca": "caaa"}, true},
+	// bearer over plaintext
+	{map[string]string{"scalerAddress": "myservice", "authModes": "bearer"}, map[string]string{"bearerToken": "token"}, true},
+	// keepAliveTime
+	{map[string]string{"scalerAddress": "myservice", "keepAliveTime": "1m"}, map[string]string{}, false},
+	// keepAliveTime too short
+	{map[string]string{"scalerAddress": "myservice", "keepAliveTime": "1s"}, map[string]string{}, true},
+	// malformed keepAliveTime
+	{map[string]string{"scalerAddress": "myservice", "keepAliveTime": "often"}, map[string]string{}, true},
+	// unknown authModes
+	{map[string]string{"scalerAddress": "myservice", "authModes": "basic"}, map[string]string{}, true},
+	// push quiet period, liveness deadline and reconnect backoff
+	{map[string]string{"scalerAddress": "myservice", "quietPeriod": "30s", "livenessDeadline": "2m", "reconnectBaseDelay": "1s", "reconnectMaxDelay": "30s"}, map[string]string{}, false},
+	{map[string]string{"scalerAddress": "myservice", "quietPeriod": "-30s"}, map[string]string{}, true},
+	{map[string]string{"scalerAddress": "myservice", "livenessDeadline": "soon"}, map[string]string{}, true},
+	{map[string]string{"scalerAddress": "myservice", "reconnectBaseDelay": "0s"}, map[string]string{}, true},
+	{map[string]string{"scalerAddress": "myservice", "reconnectBaseDelay": "1m", "reconnectMaxDelay": "30s"}, map[string]string{}, true},
 }
 
 func TestExternalScalerParseMetadata(t *testing.T) {
 	for _, testData := range testExternalScalerMetadata {
-		_, err := parseExternalScalerMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: map[string]string{}})
+		_, err := parseExternalScalerMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, ResolvedEnv: map[string]string{}})
 		if err != nil && !testData.isError {
 			t.Error("Expected success but got error", err)
 		}
@@ -39,11 +69,118 @@ func TestExternalScalerParseMetadata(t *testing.T) {
 	}
 }
 
+func TestExternalScalerParseSPIFFE(t *testing.T) {
+	config := &ScalerConfig{
+		TriggerMetadata: map[string]string{"scalerAddress": "myservice", "authModes": "bearer", "spiffeServerID": "spiffe://example.org/scaler"},
+		AuthParams:      map[string]string{"bearerToken": "token"},
+		PodIdentity:     kedav1alpha1.PodIdentityProviderSpiffe,
+	}
+	meta, err := parseExternalScalerMetadata(config)
+	if err != nil {
+		t.Fatal("Expected success but got error", err)
+	}
+	if !meta.enableSPIFFE || meta.spiffeServerID != "spiffe://example.org/scaler" {
+		t.Errorf("Expected SPIFFE with spiffe://example.org/scaler, got %v with %s", meta.enableSPIFFE, meta.spiffeServerID)
+	}
+
+	// the SVID replaces the certificates
+	config.AuthParams["ca"] = "caaa"
+	if _, err := parseExternalScalerMetadata(config); err == nil {
+		t.Error("Expected error but got success")
+	}
+}
+
+func TestExternalScalerBearerCredentials(t *testing.T) {
+	creds := bearerCredentials{token: "token"}
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md["authorization"] != "Bearer token" {
+		t.Error("Wrong authorization metadata:", md["authorization"])
+	}
+	if !creds.RequireTransportSecurity() {
+		t.Error("Expected the bearer token to require transport security")
+	}
+}
+
+func TestExternalScalerDialOptions(t *testing.T) {
+	// plaintext
+	opts, err := buildGRPCDialOptions(externalScalerMetadata{})
+	if err != nil || len(opts) != 3 {
+		t.Error("Expected keepalive, backoff and plaintext dial options but got", len(opts), err)
+	}
+
+	// invalid client certificate
+	if _, err := buildGRPCDialOptions(externalScalerMetadata{enableTLS: true, cert: "ceert", key: "keey"}); err == nil {
+		t.Error("Expected error for an invalid client certificate but got success")
+	}
+}
+
+func TestExternalScalerConnectionPool(t *testing.T) {
+	first, err := newExternalScaler(&ScalerConfig{Name: "app", Namespace: "namespace", TriggerMetadata: map[string]string{"scalerAddress": "pool-test:6000", "test": "1"}, ResolvedEnv: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newExternalScaler(&ScalerConfig{Name: "other", Namespace: "namespace", TriggerMetadata: map[string]string{"scalerAddress": "pool-test:6000", "test": "2"}, ResolvedEnv: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newExternalScaler(&ScalerConfig{Name: "app", Namespace: "namespace", TriggerMetadata: map[string]string{"scalerAddress": "pool-test:6001"}, ResolvedEnv: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close(context.Background())
+
+	if first.grpcConnection != second.grpcConnection {
+		t.Error("Expected scalers with the same connection properties to share the connection")
+	}
+	if first.grpcConnection == other.grpcConnection {
+		t.Error("Expected scalers with different addresses to use different connections")
+	}
+
+	// closing twice must not release the connection of the second scaler
+	first.Close(context.Background())
+	first.Close(context.Background())
+	if state := second.grpcConnection.GetState(); state == connectivity.Shutdown {
+		t.Error("Expected the connection to stay open while in use")
+	}
+
+	second.Close(context.Background())
+	if state := second.grpcConnection.GetState(); state != connectivity.Shutdown {
+		t.Error("Expected the connection to be closed once released, got", state)
+	}
+}
+
+func TestExternalScalerUnavailable(t *testing.T) {
+	// nothing listens on the port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	scaler, err := newExternalScaler(&ScalerConfig{Name: "app", Namespace: "namespace", TriggerMetadata: map[string]string{"scalerAddress": address}, ResolvedEnv: map[string]string{}, GlobalHTTPTimeout: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scaler.Close(context.Background())
+
+	start := time.Now()
+	if _, err := scaler.IsActive(context.Background()); err == nil {
+		t.Error("Expected error but got success")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Expected the call to time out, it took", time.Since(start))
+	}
+}
+
 func TestExternalPushScaler_Run(t *testing.T) {
 	const serverCount = 5
 	const iterationCount = 500
 
-	servers := createGRPCServers(serverCount, t)
+	servers := createGRPCServers(serverCount, 5050, t)
 	replyCh := createIsActiveChannels(serverCount * iterationCount)
 
 	// we will send serverCount * iterationCount 'isActiveResponse' and expect resultCount == serverCount * iterationCount
@@ -93,18 +230,65 @@ func TestExternalPushScaler_Run(t *testing.T) {
 	}
 }
 
+func TestExternalPushScalerQuietPeriod(t *testing.T) {
+	server := createGRPCServers(1, 5060, t)[0]
+	defer server.grpcServer.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pushScaler, err := NewExternalPushScaler(&ScalerConfig{Name: "app", Namespace: "namespace", TriggerMetadata: map[string]string{"scalerAddress": server.address, "quietPeriod": "300ms"}, ResolvedEnv: map[string]string{}})
+	assert.NoError(t, err)
+	activeCh := make(chan bool)
+	go pushScaler.Run(ctx, activeCh)
+
+	server.publish <- true
+	assert.True(t, <-activeCh)
+	start := time.Now()
+	assert.True(t, pushScaler.(*externalPushScaler).isQuiet())
+
+	// the inactive message is held back until the quiet period elapsed
+	server.publish <- false
+	assert.False(t, <-activeCh)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(250*time.Millisecond))
+	assert.False(t, pushScaler.(*externalPushScaler).isQuiet())
+}
+
+func TestExternalPushScalerLivenessDeadline(t *testing.T) {
+	server := createGRPCServers(1, 5061, t)[0]
+	defer server.grpcServer.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pushScaler, err := NewExternalPushScaler(&ScalerConfig{Name: "app", Namespace: "namespace", TriggerMetadata: map[string]string{"scalerAddress": server.address, "livenessDeadline": "200ms", "reconnectBaseDelay": "10ms"}, ResolvedEnv: map[string]string{}})
+	assert.NoError(t, err)
+	scaler := pushScaler.(*externalPushScaler)
+	activeCh := make(chan bool)
+	go pushScaler.Run(ctx, activeCh)
+
+	assert.NoError(t, scaler.checkLiveness())
+	time.Sleep(300 * time.Millisecond)
+	assert.Error(t, scaler.checkLiveness())
+	_, err = pushScaler.GetMetrics(ctx, "metric", nil)
+	assert.Error(t, err)
+
+	// the stream is reopened and the trigger is healthy again once a message is received
+	server.publish <- true
+	assert.True(t, <-activeCh)
+	assert.NoError(t, scaler.checkLiveness())
+}
+
 type testServer struct {
 	grpcServer *grpc.Server
 	address    string
 	publish    chan bool
 }
 
-func createGRPCServers(count int, t *testing.T) []testServer {
+func createGRPCServers(count int, port int, t *testing.T) []testServer {
 	result := make([]testServer, 0, count)
 
 	for i := 0; i < count; i++ {
 		grpcServer := grpc.NewServer()
-		address := fmt.Sprintf("127.0.0.1:%d", 5050+i)
+		address := fmt.Sprintf("127.0.0.1:%d", port+i)
 		lis, _ := net.Listen("tcp", address)
 		activeCh := make(chan bool)
 		pb.RegisterExternalScalerServer(grpcServer, &testExternalScaler{
@@ -19,6 +19,11 @@ import (
 const (
 	defaultTargetSubscriptionSize = 5
 	pubSubStackDriverMetricName   = "pubsub.googleapis.com/subscription/num_undelivered_messages"
+	// the age in seconds of the oldest message not acknowledged by a subscriber
+	pubSubStackDriverOldestUnackedMessageAgeMetricName = "pubsub.googleapis.com/subscription/oldest_unacked_message_age"
+
+	pubSubModeSubscriptionSize        = "SubscriptionSize"
+	pubSubModeOldestUnackedMessageAge = "OldestUnackedMessageAge"
 )
 
 type gcpAuthorizationMetadata struct {
@@ -33,10 +38,14 @@ type pubsubScaler struct {
 }
 
 type pubsubMetadata struct {
+	mode string
+	// targetSubscriptionSize is the number of undelivered messages or the age in seconds of the oldest one, depending on mode
 	targetSubscriptionSize int
 	subscriptionName       string
 	gcpAuthorization       gcpAuthorizationMetadata
-	scalerIndex            int
+	// activationListener is notified by a push subscription of the topic, nil if activationNotificationKey isn't set
+	activationListener *notificationListener
+	scalerIndex        int
 }
 
 var gcpPubSubLog = logf.Log.WithName("gcp_pub_sub_scaler")
@@ -57,7 +66,22 @@ func parsePubSubMetadata(config *ScalerConfig) (*pubsubMetadata, error) {
 	meta := pubsubMetadata{}
 	meta.targetSubscriptionSize = defaultTargetSubscriptionSize
 
+	meta.mode = pubSubModeSubscriptionSize
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		// OldestItemAge is the mode shared by the queue scalers
+		if val == modeOldestItemAge {
+			val = pubSubModeOldestUnackedMessageAge
+		}
+		if val != pubSubModeSubscriptionSize && val != pubSubModeOldestUnackedMessageAge {
+			return nil, fmt.Errorf("mode must be %s, %s or %s", pubSubModeSubscriptionSize, pubSubModeOldestUnackedMessageAge, modeOldestItemAge)
+		}
+		meta.mode = val
+	}
+
 	if val, ok := config.TriggerMetadata["subscriptionSize"]; ok {
+		if meta.mode != pubSubModeSubscriptionSize {
+			return nil, fmt.Errorf("subscriptionSize can only be used with mode %s, use value instead", pubSubModeSubscriptionSize)
+		}
 		subscriptionSize, err := strconv.Atoi(val)
 		if err != nil {
 			return nil, fmt.Errorf("subscription Size parsing error %s", err.Error())
@@ -66,6 +90,18 @@ func parsePubSubMetadata(config *ScalerConfig) (*pubsubMetadata, error) {
 		meta.targetSubscriptionSize = subscriptionSize
 	}
 
+	if val, ok := config.TriggerMetadata["value"]; ok {
+		if _, ok := config.TriggerMetadata["subscriptionSize"]; ok {
+			return nil, fmt.Errorf("value and subscriptionSize can not be set both")
+		}
+		value, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("value parsing error %s", err.Error())
+		}
+
+		meta.targetSubscriptionSize = value
+	}
+
 	if val, ok := config.TriggerMetadata["subscriptionName"]; ok {
 		if val == "" {
 			return nil, fmt.Errorf("no subscription name given")
@@ -81,13 +117,17 @@ func parsePubSubMetadata(config *ScalerConfig) (*pubsubMetadata, error) {
 		return nil, err
 	}
 	meta.gcpAuthorization = *auth
+
+	if meta.activationListener, err = parseNotificationListener(config); err != nil {
+		return nil, err
+	}
 	meta.scalerIndex = config.ScalerIndex
 	return &meta, nil
 }
 
 // IsActive checks if there are any messages in the subscription
 func (s *pubsubScaler) IsActive(ctx context.Context) (bool, error) {
-	size, err := s.GetSubscriptionSize(ctx)
+	size, err := s.getMetrics(ctx)
 
 	if err != nil {
 		gcpPubSubLog.Error(err, "error getting Active Status")
@@ -97,6 +137,11 @@ func (s *pubsubScaler) IsActive(ctx context.Context) (bool, error) {
 	return size > 0, nil
 }
 
+// ListenActivation relays the messages of the push subscription pointed to the notification endpoint of the operator
+func (s *pubsubScaler) ListenActivation(ctx context.Context, notify chan<- struct{}) error {
+	return s.metadata.activationListener.listen(ctx, notify)
+}
+
 func (s *pubsubScaler) Close(context.Context) error {
 	if s.client != nil {
 		err := s.client.metricsClient.Close()
@@ -134,11 +179,12 @@ func (s *pubsubScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Metric
 }
 
 // GetMetrics connects to Stack Driver and finds the size of the pub sub subscription
+// or the age of its oldest unacked message
 func (s *pubsubScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	size, err := s.GetSubscriptionSize(ctx)
+	size, err := s.getMetrics(ctx)
 
 	if err != nil {
-		gcpPubSubLog.Error(err, "error getting subscription size")
+		gcpPubSubLog.Error(err, "error getting subscription metric", "mode", s.metadata.mode)
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
@@ -151,9 +197,9 @@ func (s *pubsubScaler) GetMetrics(ctx context.Context, metricName string, metric
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
-// GetSubscriptionSize gets the number of messages in a subscription by calling the
-// Stackdriver api
-func (s *pubsubScaler) GetSubscriptionSize(ctx context.Context) (int64, error) {
+// getMetrics gets the number of messages in a subscription or the age of the oldest
+// unacked one by calling the Stackdriver api
+func (s *pubsubScaler) getMetrics(ctx context.Context) (int64, error) {
 	if s.client == nil {
 		var client *StackDriverClient
 		var err error
@@ -168,9 +214,18 @@ func (s *pubsubScaler) GetSubscriptionSize(ctx context.Context) (int64, error) {
 		s.client = client
 	}
 
-	filter := `metric.type="` + pubSubStackDriverMetricName + `" AND resource.labels.subscription_id="` + s.metadata.subscriptionName + `"`
+	return s.client.GetMetrics(ctx, s.metadata.stackDriverFilter())
+}
+
+// stackDriverFilter selects the time series of the subscription only, the metric type
+// and the subscription ID are shared by the subscriptions of other resource types
+func (m *pubsubMetadata) stackDriverFilter() string {
+	metricType := pubSubStackDriverMetricName
+	if m.mode == pubSubModeOldestUnackedMessageAge {
+		metricType = pubSubStackDriverOldestUnackedMessageAgeMetricName
+	}
 
-	return s.client.GetMetrics(ctx, filter)
+	return `metric.type="` + metricType + `" AND resource.type="pubsub_subscription" AND resource.labels.subscription_id="` + m.subscriptionName + `"`
 }
 
 func getGcpAuthorization(config *ScalerConfig, resolvedEnv map[string]string) (*gcpAuthorizationMetadata, error) {
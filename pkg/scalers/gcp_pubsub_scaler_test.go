@@ -2,7 +2,11 @@ package scalers
 
 import (
 	"context"
+	"encoding/json"
+	"os"
 	"testing"
+
+	"golang.org/x/oauth2/google"
 )
 
 var testPubSubResolvedEnv = map[string]string{
@@ -35,6 +39,24 @@ var testPubSubMetadata = []parsePubSubMetadataTestData{
 	{map[string]string{"GoogleApplicationCredentials": "Creds", "podIdentityOwner": ""}, map[string]string{"subscriptionName": "mysubscription", "subscriptionSize": "7"}, false},
 	// Credentials from AuthParams with empty creds
 	{map[string]string{"GoogleApplicationCredentials": "", "podIdentityOwner": ""}, map[string]string{"subscriptionName": "mysubscription", "subscriptionSize": "7"}, true},
+	// oldest unacked message age
+	{nil, map[string]string{"subscriptionName": "mysubscription", "mode": "OldestUnackedMessageAge", "value": "60", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// subscription size with value
+	{nil, map[string]string{"subscriptionName": "mysubscription", "mode": "SubscriptionSize", "value": "7", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// unknown mode
+	{nil, map[string]string{"subscriptionName": "mysubscription", "mode": "MessageCount", "value": "7", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// subscriptionSize with oldest unacked message age
+	{nil, map[string]string{"subscriptionName": "mysubscription", "mode": "OldestUnackedMessageAge", "subscriptionSize": "7", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// both subscriptionSize and value
+	{nil, map[string]string{"subscriptionName": "mysubscription", "subscriptionSize": "7", "value": "7", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed value
+	{nil, map[string]string{"subscriptionName": "mysubscription", "mode": "OldestUnackedMessageAge", "value": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// the mode shared by the queue scalers
+	{nil, map[string]string{"subscriptionName": "mysubscription", "mode": "OldestItemAge", "value": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// activation notifications
+	{map[string]string{"activationNotificationToken": "secret"}, map[string]string{"subscriptionName": "mysubscription", "credentialsFromEnv": "SAMPLE_CREDS", "activationNotificationKey": "orders"}, false},
+	// activation notifications without token
+	{nil, map[string]string{"subscriptionName": "mysubscription", "credentialsFromEnv": "SAMPLE_CREDS", "activationNotificationKey": "orders"}, true},
 }
 
 var gcpPubSubMetricIdentifiers = []gcpPubSubMetricIdentifier{
@@ -69,3 +91,71 @@ func TestGcpPubSubGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestPubSubStackDriverFilter(t *testing.T) {
+	testData := []struct {
+		metadata map[string]string
+		filter   string
+	}{
+		{
+			map[string]string{"subscriptionName": "mysubscription", "credentialsFromEnv": "SAMPLE_CREDS"},
+			`metric.type="pubsub.googleapis.com/subscription/num_undelivered_messages" AND resource.type="pubsub_subscription" AND resource.labels.subscription_id="mysubscription"`,
+		},
+		{
+			map[string]string{"subscriptionName": "mysubscription", "mode": "OldestUnackedMessageAge", "credentialsFromEnv": "SAMPLE_CREDS"},
+			`metric.type="pubsub.googleapis.com/subscription/oldest_unacked_message_age" AND resource.type="pubsub_subscription" AND resource.labels.subscription_id="mysubscription"`,
+		},
+	}
+
+	for _, test := range testData {
+		meta, err := parsePubSubMetadata(&ScalerConfig{TriggerMetadata: test.metadata, ResolvedEnv: testPubSubResolvedEnv})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		if filter := meta.stackDriverFilter(); filter != test.filter {
+			t.Errorf("Expected filter %s but got %s", test.filter, filter)
+		}
+	}
+}
+
+func TestGcpWorkloadIdentityFederationCredentials(t *testing.T) {
+	provider := "//iam.googleapis.com/projects/123456/locations/global/workloadIdentityPools/keda/providers/eks"
+	os.Setenv(gcpWorkloadIdentityProviderEnv, provider)
+	defer os.Unsetenv(gcpWorkloadIdentityProviderEnv)
+
+	if _, err := gcpWorkloadIdentityFederationCredentials(); err == nil {
+		t.Error("Expected an error without the project ID")
+	}
+
+	os.Setenv(gcpProjectIDEnv, "shop")
+	defer os.Unsetenv(gcpProjectIDEnv)
+	os.Setenv(gcpServiceAccountEnv, "keda@shop.iam.gserviceaccount.com")
+	defer os.Unsetenv(gcpServiceAccountEnv)
+
+	credentials, err := gcpWorkloadIdentityFederationCredentials()
+	if err != nil {
+		t.Fatal("Could not build the credentials:", err)
+	}
+	var parsed struct {
+		Audience         string            `json:"audience"`
+		CredentialSource map[string]string `json:"credential_source"`
+		ImpersonationURL string            `json:"service_account_impersonation_url"`
+	}
+	if err := json.Unmarshal(credentials, &parsed); err != nil {
+		t.Fatal("Could not decode the credentials:", err)
+	}
+	if parsed.Audience != provider {
+		t.Errorf("Expected audience %s but got %s", provider, parsed.Audience)
+	}
+	if parsed.CredentialSource["file"] != defaultGcpWorkloadIdentityTokenFile {
+		t.Errorf("Expected the token file %s but got %s", defaultGcpWorkloadIdentityTokenFile, parsed.CredentialSource["file"])
+	}
+	if expected := "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/keda@shop.iam.gserviceaccount.com:generateAccessToken"; parsed.ImpersonationURL != expected {
+		t.Errorf("Expected impersonation url %s but got %s", expected, parsed.ImpersonationURL)
+	}
+
+	// the token is exchanged at the first request
+	if _, err := google.CredentialsFromJSON(context.Background(), credentials, "https://www.googleapis.com/auth/monitoring.read"); err != nil {
+		t.Error("The credentials were rejected:", err)
+	}
+}
@@ -7,15 +7,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	url_pkg "net/url"
-	"strconv"
 
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
@@ -36,14 +34,18 @@ type graphiteMetadata struct {
 	serverAddress string
 	metricName    string
 	query         string
-	threshold     int
+	threshold     float64
 	from          string
 
 	// basic auth
 	enableBasicAuth bool
 	username        string
 	password        string // +optional
-	scalerIndex     int
+
+	// oauth2 auth, the token of the client credentials is sent as bearer token
+	oauth2 *authentication.OAuth2ClientCredentials
+
+	scalerIndex int
 }
 
 type grapQueryResult []struct {
@@ -61,7 +63,10 @@ func NewGraphiteScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing graphite metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
+	if meta.oauth2 != nil {
+		httpClient.Transport = meta.oauth2.Transport(httpClient.Transport)
+	}
 
 	return &graphiteScaler{
 		metadata:   meta,
@@ -96,14 +101,11 @@ func parseGraphiteMetadata(config *ScalerConfig) (*graphiteMetadata, error) {
 		return nil, fmt.Errorf("no %s given", grapQueryTime)
 	}
 
-	if val, ok := config.TriggerMetadata[grapThreshold]; ok && val != "" {
-		t, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing %s: %s", grapThreshold, err)
-		}
-
-		meta.threshold = t
+	threshold, err := getFloatMetadataValue(config.TriggerMetadata, grapThreshold, false, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.threshold = threshold
 
 	meta.scalerIndex = config.ScalerIndex
 
@@ -112,20 +114,27 @@ func parseGraphiteMetadata(config *ScalerConfig) (*graphiteMetadata, error) {
 	if !ok {
 		return &meta, nil
 	}
-	if val != "basic" {
-		return nil, fmt.Errorf("authMode must be 'basic'")
-	}
+	switch authentication.Type(val) {
+	case authentication.BasicAuthType:
+		if len(config.AuthParams["username"]) == 0 {
+			return nil, fmt.Errorf("no username given")
+		}
 
-	if len(config.AuthParams["username"]) == 0 {
-		return nil, fmt.Errorf("no username given")
+		meta.username = config.AuthParams["username"]
+		// password is optional. For convenience, many application implement basic auth with
+		// username as apikey and password as empty
+		meta.password = config.AuthParams["password"]
+		meta.enableBasicAuth = true
+	case authentication.OAuth2AuthType:
+		oauth2, err := authentication.ParseOAuth2ClientCredentials(config.AuthParams)
+		if err != nil {
+			return nil, err
+		}
+		meta.oauth2 = oauth2
+	default:
+		return nil, fmt.Errorf("authMode must be 'basic' or 'oauth2'")
 	}
 
-	meta.username = config.AuthParams["username"]
-	// password is optional. For convenience, many application implement basic auth with
-	// username as apikey and password as empty
-	meta.password = config.AuthParams["password"]
-	meta.enableBasicAuth = true
-
 	return &meta, nil
 }
 
@@ -140,19 +149,16 @@ func (s *graphiteScaler) IsActive(ctx context.Context) (bool, error) {
 }
 
 func (s *graphiteScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
 func (s *graphiteScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricValue := resource.NewQuantity(int64(s.metadata.threshold), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("graphite-%s", s.metadata.metricName))),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.threshold),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -206,11 +212,7 @@ func (s *graphiteScaler) GetMetrics(ctx context.Context, metricName string, metr
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, val)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
@@ -0,0 +1,298 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// honeycombPollInterval is the interval the query results are polled at until they are complete
+var honeycombPollInterval = time.Second
+
+type honeycombScaler struct {
+	metadata   *honeycombMetadata
+	httpClient *http.Client
+
+	// queryID is the id of the query spec, it is created once since Honeycomb returns the same id for the same spec
+	lock    sync.Mutex
+	queryID string
+}
+
+type honeycombMetadata struct {
+	APIKey  string `keda:"name=apiKey, order=authParams"`
+	Host    string `keda:"name=host, default=https://api.honeycomb.io"`
+	Dataset string `keda:"name=dataset"`
+	// Query is a query spec in JSON, the query is built from Calculation, Column and Filter otherwise,
+	// see https://docs.honeycomb.io/api/query-specification/
+	Query       string `keda:"name=query, optional"`
+	Calculation string `keda:"name=calculation, optional"`
+	Column      string `keda:"name=column, optional"`
+	// Filter is a JSON list of the filters of the built query, eg. [{"column": "status_code", "op": ">=", "value": 500}]
+	Filter    string        `keda:"name=filter, optional"`
+	TimeRange time.Duration `keda:"name=timeRange, default=10m"`
+	// ResultField is the field of the first result row holding the value, by default the name of the first
+	// calculation, eg. COUNT or P99(duration_ms)
+	ResultField string `keda:"name=resultField, optional"`
+	// QueryTimeout bounds the wait for the results of a query
+	QueryTimeout time.Duration `keda:"name=queryTimeout, default=30s"`
+	TargetValue  float64       `keda:"name=targetValue"`
+
+	// querySpec is the query posted to the Query Data API
+	querySpec   []byte
+	scalerIndex int
+}
+
+type honeycombCalculation struct {
+	Op     string `json:"op"`
+	Column string `json:"column,omitempty"`
+}
+
+type honeycombQuerySpec struct {
+	Calculations []honeycombCalculation `json:"calculations"`
+	Filters      json.RawMessage        `json:"filters,omitempty"`
+	TimeRange    int64                  `json:"time_range,omitempty"`
+}
+
+// Validate checks the query is given either as a query spec or as a calculation
+func (m *honeycombMetadata) Validate() error {
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	if m.TimeRange < time.Second {
+		return fmt.Errorf("timeRange must be at least 1s")
+	}
+	switch {
+	case m.Query != "" && (m.Calculation != "" || m.Column != "" || m.Filter != ""):
+		return fmt.Errorf("only one of query and calculation can be given")
+	case m.Query == "" && m.Calculation == "":
+		return fmt.Errorf("no query or calculation given")
+	}
+	return nil
+}
+
+// buildQuerySpec sets the query spec posted to the Query Data API and the result field read from its results
+func (m *honeycombMetadata) buildQuerySpec() error {
+	if m.Query != "" {
+		var spec map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(m.Query), &spec); err != nil {
+			return fmt.Errorf("query must be a json query spec: %s", err)
+		}
+		if _, ok := spec["time_range"]; !ok {
+			spec["time_range"], _ = json.Marshal(int64(m.TimeRange.Seconds()))
+		}
+		var calculations []honeycombCalculation
+		if err := json.Unmarshal(spec["calculations"], &calculations); err != nil || len(calculations) == 0 {
+			return fmt.Errorf("the query spec has no calculations")
+		}
+		if m.ResultField == "" {
+			m.ResultField = honeycombResultField(calculations[0])
+		}
+		m.querySpec, _ = json.Marshal(spec)
+		return nil
+	}
+
+	calculation := honeycombCalculation{Op: strings.ToUpper(m.Calculation), Column: m.Column}
+	if calculation.Op != "COUNT" && calculation.Op != "CONCURRENCY" && calculation.Column == "" {
+		return fmt.Errorf("no column given for the calculation %s", calculation.Op)
+	}
+	query := honeycombQuerySpec{Calculations: []honeycombCalculation{calculation}, TimeRange: int64(m.TimeRange.Seconds())}
+	if m.Filter != "" {
+		if !json.Valid([]byte(m.Filter)) || !strings.HasPrefix(strings.TrimSpace(m.Filter), "[") {
+			return fmt.Errorf("filter must be a json list of filters")
+		}
+		query.Filters = json.RawMessage(m.Filter)
+	}
+	if m.ResultField == "" {
+		m.ResultField = honeycombResultField(calculation)
+	}
+	m.querySpec, _ = json.Marshal(query)
+	return nil
+}
+
+// honeycombResultField returns the name of the field of a calculation in the query results
+func honeycombResultField(calculation honeycombCalculation) string {
+	if calculation.Column == "" {
+		return calculation.Op
+	}
+	return fmt.Sprintf("%s(%s)", calculation.Op, calculation.Column)
+}
+
+var honeycombLog = logf.Log.WithName("honeycomb_scaler")
+
+// NewHoneycombScaler creates a new scaler running a query of a Honeycomb dataset
+func NewHoneycombScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseHoneycombMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing honeycomb metadata: %s", err)
+	}
+
+	return &honeycombScaler{
+		metadata:   meta,
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, false),
+	}, nil
+}
+
+func parseHoneycombMetadata(config *ScalerConfig) (*honeycombMetadata, error) {
+	meta := honeycombMetadata{}
+	if err := config.TypedConfig(&meta); err != nil {
+		return nil, err
+	}
+	if err := meta.buildQuerySpec(); err != nil {
+		return nil, err
+	}
+	meta.Host = strings.TrimSuffix(meta.Host, "/")
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive returns true if the result of the query is greater than 0
+func (s *honeycombScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		honeycombLog.Error(err, "error querying honeycomb")
+		return false, err
+	}
+	return value > 0, nil
+}
+
+// Close does nothing in case of honeycombScaler
+func (s *honeycombScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+// do sends a request to the API and decodes its JSON response into result
+func (s *honeycombScaler) do(ctx context.Context, method, path string, body []byte, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.metadata.Host+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Honeycomb-Team", s.metadata.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var honeycombError struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &honeycombError); err == nil && honeycombError.Error != "" {
+			return fmt.Errorf("honeycomb returned %d: %s", resp.StatusCode, honeycombError.Error)
+		}
+		return fmt.Errorf("honeycomb returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// getQueryID creates the query once and returns its id
+func (s *honeycombScaler) getQueryID(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.queryID != "" {
+		return s.queryID, nil
+	}
+
+	var query struct {
+		ID string `json:"id"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/1/queries/"+url.PathEscape(s.metadata.Dataset), s.metadata.querySpec, &query); err != nil {
+		return "", fmt.Errorf("error creating the query: %s", err)
+	}
+	s.queryID = query.ID
+	return s.queryID, nil
+}
+
+type honeycombQueryResult struct {
+	ID       string `json:"id"`
+	Complete bool   `json:"complete"`
+	Data     struct {
+		Results []struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"results"`
+	} `json:"data"`
+}
+
+// getQueryResult runs the query and returns the result field of its first result row, 0 if it has no rows
+func (s *honeycombScaler) getQueryResult(ctx context.Context) (float64, error) {
+	queryID, err := s.getQueryID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	dataset := url.PathEscape(s.metadata.Dataset)
+	body, _ := json.Marshal(map[string]interface{}{"query_id": queryID, "disable_series": true, "limit": 1000})
+	var result honeycombQueryResult
+	if err := s.do(ctx, http.MethodPost, "/1/query_results/"+dataset, body, &result); err != nil {
+		return 0, fmt.Errorf("error running the query: %s", err)
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, s.metadata.QueryTimeout)
+	defer cancel()
+	for !result.Complete {
+		select {
+		case <-queryCtx.Done():
+			return 0, fmt.Errorf("the query didn't complete: %s", queryCtx.Err())
+		case <-time.After(honeycombPollInterval):
+		}
+		if err := s.do(queryCtx, http.MethodGet, "/1/query_results/"+dataset+"/"+url.PathEscape(result.ID), nil, &result); err != nil {
+			return 0, fmt.Errorf("error getting the query results: %s", err)
+		}
+	}
+
+	if len(result.Data.Results) == 0 {
+		return 0, nil
+	}
+	switch value := result.Data.Results[0].Data[s.metadata.ResultField].(type) {
+	case float64:
+		return value, nil
+	case nil:
+		return 0, fmt.Errorf("the query results have no field %s", s.metadata.ResultField)
+	default:
+		return 0, fmt.Errorf("value of type %T could not be converted into a float", value)
+	}
+}
+
+func (s *honeycombScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("honeycomb-%s", s.metadata.Dataset))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the result of the query
+func (s *honeycombScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error querying honeycomb: %s", err)
+	}
+	return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, value)), nil
+}
@@ -0,0 +1,125 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseHoneycombMetadataTestData struct {
+	metadata    map[string]string
+	authParams  map[string]string
+	querySpec   string
+	resultField string
+	isError     bool
+}
+
+var testHoneycombAuthParams = map[string]string{"apiKey": "key"}
+
+var testHoneycombMetadata = []parseHoneycombMetadataTestData{
+	// count
+	{map[string]string{"dataset": "checkout", "calculation": "count", "targetValue": "100"}, testHoneycombAuthParams,
+		`{"calculations": [{"op": "COUNT"}], "time_range": 600}`, "COUNT", false},
+	// calculation on a column with a filter
+	{map[string]string{"dataset": "checkout", "calculation": "P99", "column": "duration_ms", "filter": `[{"column": "status_code", "op": ">=", "value": 500}]`, "timeRange": "5m", "targetValue": "100"}, testHoneycombAuthParams,
+		`{"calculations": [{"op": "P99", "column": "duration_ms"}], "filters": [{"column": "status_code", "op": ">=", "value": 500}], "time_range": 300}`, "P99(duration_ms)", false},
+	// query spec
+	{map[string]string{"dataset": "checkout", "query": `{"calculations": [{"op": "CONCURRENCY"}], "breakdowns": ["service.name"]}`, "targetValue": "100"}, testHoneycombAuthParams,
+		`{"calculations": [{"op": "CONCURRENCY"}], "breakdowns": ["service.name"], "time_range": 600}`, "CONCURRENCY", false},
+	// query spec with a result field
+	{map[string]string{"dataset": "checkout", "query": `{"calculations": [{"op": "COUNT"}], "time_range": 60}`, "resultField": "COUNT", "targetValue": "100"}, testHoneycombAuthParams,
+		`{"calculations": [{"op": "COUNT"}], "time_range": 60}`, "COUNT", false},
+	// missing apiKey
+	{map[string]string{"dataset": "checkout", "calculation": "count", "targetValue": "100"}, map[string]string{}, "", "", true},
+	// missing dataset
+	{map[string]string{"calculation": "count", "targetValue": "100"}, testHoneycombAuthParams, "", "", true},
+	// no query
+	{map[string]string{"dataset": "checkout", "targetValue": "100"}, testHoneycombAuthParams, "", "", true},
+	// query and calculation
+	{map[string]string{"dataset": "checkout", "query": `{"calculations": [{"op": "COUNT"}]}`, "calculation": "count", "targetValue": "100"}, testHoneycombAuthParams, "", "", true},
+	// calculation without column
+	{map[string]string{"dataset": "checkout", "calculation": "avg", "targetValue": "100"}, testHoneycombAuthParams, "", "", true},
+	// query spec without calculations
+	{map[string]string{"dataset": "checkout", "query": `{"breakdowns": ["service.name"]}`, "targetValue": "100"}, testHoneycombAuthParams, "", "", true},
+	// invalid filter
+	{map[string]string{"dataset": "checkout", "calculation": "count", "filter": `{"column": "status_code"}`, "targetValue": "100"}, testHoneycombAuthParams, "", "", true},
+}
+
+func TestParseHoneycombMetadata(t *testing.T) {
+	for i, testData := range testHoneycombMetadata {
+		meta, err := parseHoneycombMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+		if err == nil {
+			assert.JSONEq(t, testData.querySpec, string(meta.querySpec), "unit test #%v", i)
+			assert.Equal(t, testData.resultField, meta.ResultField, "unit test #%v", i)
+		}
+	}
+}
+
+func TestHoneycombGetQueryResult(t *testing.T) {
+	honeycombPollInterval = time.Millisecond
+	defer func() { honeycombPollInterval = time.Second }()
+
+	queries, polls := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Honeycomb-Team") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "unknown API key - check your credentials"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/queries/checkout":
+			queries++
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"calculations": [{"op": "COUNT"}], "time_range": 600}`, string(body))
+			fmt.Fprint(w, `{"id": "query-1"}`)
+		case "/1/query_results/checkout":
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "query-1", body["query_id"])
+			fmt.Fprint(w, `{"id": "result-1", "complete": false}`)
+		case "/1/query_results/checkout/result-1":
+			polls++
+			if polls%2 == 1 {
+				fmt.Fprint(w, `{"id": "result-1", "complete": false}`)
+				return
+			}
+			fmt.Fprint(w, `{"id": "result-1", "complete": true, "data": {"results": [{"data": {"COUNT": 42}}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	scaler, err := NewHoneycombScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"host": server.URL, "dataset": "checkout", "calculation": "COUNT", "targetValue": "10"},
+		AuthParams:      testHoneycombAuthParams,
+	})
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		value, err := scaler.(*honeycombScaler).getQueryResult(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, float64(42), value)
+	}
+	assert.Equal(t, 1, queries, "the query must be created once")
+
+	scaler, err = NewHoneycombScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"host": server.URL, "dataset": "checkout", "calculation": "COUNT", "targetValue": "10"},
+		AuthParams:      map[string]string{"apiKey": "guess"},
+	})
+	assert.NoError(t, err)
+	_, err = scaler.(*honeycombScaler).getQueryResult(context.Background())
+	assert.EqualError(t, err, "error creating the query: honeycomb returned 401: unknown API key - check your credentials")
+}
@@ -0,0 +1,149 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	httpRequestsControllerNginx        = "nginx"
+	httpRequestsControllerEnvoyGateway = "envoy-gateway"
+)
+
+// httpRequestsQueries are the queries of the requests per second of a route, by controller, from the metrics the
+// controllers expose to Prometheus: the `namespace`, the route name and the rate window are filled in
+var httpRequestsQueries = map[string]string{
+	// ingress-nginx counts the requests by Ingress
+	httpRequestsControllerNginx: `sum(rate(nginx_ingress_controller_requests{namespace="%s",ingress="%s"}[%s]))`,
+	// Envoy Gateway names the clusters of the HTTPRoute rules httproute/<namespace>/<name>/rule/<index>
+	httpRequestsControllerEnvoyGateway: `sum(rate(envoy_cluster_upstream_rq_total{envoy_cluster_name=~"httproute/%s/%s/rule/.*"}[%s]))`,
+}
+
+type httpRequestsScaler struct {
+	metadata *httpRequestsMetadata
+	query    *prometheusScaler
+}
+
+type httpRequestsMetadata struct {
+	IngressName             string  `keda:"name=ingressName, optional"`
+	HTTPRouteName           string  `keda:"name=httpRouteName, optional"`
+	Controller              string  `keda:"name=controller, optional, enum=nginx;envoy-gateway"`
+	Namespace               string  `keda:"name=namespace, optional"`
+	Window                  string  `keda:"name=window, default=1m"`
+	TargetRequestsPerSecond float64 `keda:"name=targetRequestsPerSecond"`
+
+	// route is the Ingress or the HTTPRoute the requests are counted for
+	route       string
+	scalerIndex int
+}
+
+// Validate checks one route is given, and that it is an Ingress for ingress-nginx and an HTTPRoute for Envoy Gateway
+func (m *httpRequestsMetadata) Validate() error {
+	switch {
+	case m.IngressName != "" && m.HTTPRouteName != "":
+		return fmt.Errorf("only one of ingressName or httpRouteName can be given")
+	case m.IngressName == "" && m.HTTPRouteName == "":
+		return fmt.Errorf("no ingressName or httpRouteName given")
+	case m.Controller != "" && (m.Controller == httpRequestsControllerNginx) != (m.IngressName != ""):
+		return fmt.Errorf("the %s controller requires ingressName and the %s controller requires httpRouteName", httpRequestsControllerNginx, httpRequestsControllerEnvoyGateway)
+	case !sloWindowRegexp.MatchString(m.Window):
+		return fmt.Errorf("invalid window %q", m.Window)
+	case m.TargetRequestsPerSecond <= 0:
+		return fmt.Errorf("targetRequestsPerSecond must be greater than 0")
+	}
+	return nil
+}
+
+var httpRequestsLog = logf.Log.WithName("http_requests_scaler")
+
+// NewHTTPRequestsScaler creates a new scaler reporting the requests per second of an Ingress or an HTTPRoute
+// from the Prometheus metrics of its controller
+func NewHTTPRequestsScaler(config *ScalerConfig) (Scaler, error) {
+	meta, query, err := parseHTTPRequestsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing http-requests metadata: %s", err)
+	}
+
+	prometheus, err := newPrometheusQuery(config, query, meta.route)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing http-requests metadata: %s", err)
+	}
+
+	return &httpRequestsScaler{
+		metadata: meta,
+		query:    prometheus,
+	}, nil
+}
+
+// parseHTTPRequestsMetadata parses the metadata and returns the query of the requests per second of the route
+func parseHTTPRequestsMetadata(config *ScalerConfig) (*httpRequestsMetadata, string, error) {
+	meta := httpRequestsMetadata{}
+	if err := config.TypedConfig(&meta); err != nil {
+		return nil, "", err
+	}
+
+	if meta.IngressName != "" {
+		meta.route = meta.IngressName
+		if meta.Controller == "" {
+			meta.Controller = httpRequestsControllerNginx
+		}
+	} else {
+		meta.route = meta.HTTPRouteName
+		if meta.Controller == "" {
+			meta.Controller = httpRequestsControllerEnvoyGateway
+		}
+	}
+	if meta.Namespace == "" {
+		meta.Namespace = config.Namespace
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, fmt.Sprintf(httpRequestsQueries[meta.Controller], meta.Namespace, meta.route, meta.Window), nil
+}
+
+// IsActive returns true if the route receives requests
+func (s *httpRequestsScaler) IsActive(ctx context.Context) (bool, error) {
+	rps, err := s.query.ExecutePromQuery(ctx)
+	if err != nil {
+		httpRequestsLog.Error(err, "error executing the requests per second query")
+		return false, err
+	}
+	return rps > 0, nil
+}
+
+func (s *httpRequestsScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *httpRequestsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("http-requests-%s", s.metadata.route))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetRequestsPerSecond),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the requests per second of the route
+func (s *httpRequestsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	rps, err := s.query.ExecutePromQuery(ctx)
+	if err != nil {
+		httpRequestsLog.Error(err, "error executing the requests per second query")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, rps)
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
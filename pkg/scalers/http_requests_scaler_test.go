@@ -0,0 +1,79 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseHTTPRequestsMetadataTestData struct {
+	metadata map[string]string
+	query    string
+	isError  bool
+}
+
+var testHTTPRequestsMetadata = []parseHTTPRequestsMetadataTestData{
+	{map[string]string{}, "", true},
+	// ingress-nginx
+	{map[string]string{"serverAddress": "http://localhost:9090", "ingressName": "shop", "targetRequestsPerSecond": "50"},
+		`sum(rate(nginx_ingress_controller_requests{namespace="default",ingress="shop"}[1m]))`, false},
+	// Envoy Gateway with namespace and window
+	{map[string]string{"serverAddress": "http://localhost:9090", "httpRouteName": "shop", "namespace": "gateway", "window": "5m", "targetRequestsPerSecond": "12.5"},
+		`sum(rate(envoy_cluster_upstream_rq_total{envoy_cluster_name=~"httproute/gateway/shop/rule/.*"}[5m]))`, false},
+	// both routes
+	{map[string]string{"serverAddress": "http://localhost:9090", "ingressName": "shop", "httpRouteName": "shop", "targetRequestsPerSecond": "50"}, "", true},
+	// unknown controller
+	{map[string]string{"serverAddress": "http://localhost:9090", "ingressName": "shop", "controller": "traefik", "targetRequestsPerSecond": "50"}, "", true},
+	// HTTPRoute with the nginx controller
+	{map[string]string{"serverAddress": "http://localhost:9090", "httpRouteName": "shop", "controller": "nginx", "targetRequestsPerSecond": "50"}, "", true},
+	// invalid window
+	{map[string]string{"serverAddress": "http://localhost:9090", "ingressName": "shop", "window": "a minute", "targetRequestsPerSecond": "50"}, "", true},
+	// missing targetRequestsPerSecond
+	{map[string]string{"serverAddress": "http://localhost:9090", "ingressName": "shop"}, "", true},
+	// invalid targetRequestsPerSecond
+	{map[string]string{"serverAddress": "http://localhost:9090", "ingressName": "shop", "targetRequestsPerSecond": "0"}, "", true},
+}
+
+func TestHTTPRequestsParseMetadata(t *testing.T) {
+	for i, testData := range testHTTPRequestsMetadata {
+		_, query, err := parseHTTPRequestsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "default"})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", i)
+		}
+		assert.Equal(t, testData.query, query, i)
+	}
+}
+
+func TestHTTPRequestsGetMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `sum(rate(nginx_ingress_controller_requests{namespace="default",ingress="shop"}[1m]))`, r.URL.Query().Get("query"))
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"42.25"]}]}}`)
+	}))
+	defer server.Close()
+
+	scaler, err := NewHTTPRequestsScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"serverAddress": server.URL, "ingressName": "shop", "targetRequestsPerSecond": "20"},
+		Namespace:       "default",
+		ScalerIndex:     2,
+	})
+	assert.NoError(t, err)
+
+	spec := scaler.GetMetricSpecForScaling(context.Background())
+	assert.Equal(t, "s2-http-requests-shop", spec[0].External.Metric.Name)
+	assert.Equal(t, int64(20000), spec[0].External.Target.AverageValue.MilliValue())
+
+	metrics, err := scaler.GetMetrics(context.Background(), "s2-http-requests-shop", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42250), metrics[0].Value.MilliValue())
+
+	active, err := scaler.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+}
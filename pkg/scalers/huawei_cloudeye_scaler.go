@@ -11,8 +11,6 @@ import (
 	"github.com/Huawei/gophercloud/openstack"
 	"github.com/Huawei/gophercloud/openstack/ces/v1/metricdata"
 	"k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -116,34 +114,24 @@ func parseHuaweiCloudeyeMetadata(config *ScalerConfig) (*huaweiCloudeyeMetadata,
 		return nil, fmt.Errorf("dimension Value not given")
 	}
 
-	if val, ok := config.TriggerMetadata["targetMetricValue"]; ok && val != "" {
-		targetMetricValue, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			cloudeyeLog.Error(err, "Error parsing targetMetricValue metadata")
-		} else {
-			meta.targetMetricValue = targetMetricValue
-		}
-	} else {
-		return nil, fmt.Errorf("target Metric Value not given")
+	targetMetricValue, err := getFloatMetadataValue(config.TriggerMetadata, "targetMetricValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.targetMetricValue = targetMetricValue
 
-	if val, ok := config.TriggerMetadata["minMetricValue"]; ok && val != "" {
-		minMetricValue, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			cloudeyeLog.Error(err, "Error parsing minMetricValue metadata")
-		} else {
-			meta.minMetricValue = minMetricValue
-		}
-	} else {
-		return nil, fmt.Errorf("min Metric Value not given")
+	minMetricValue, err := getFloatMetadataValue(config.TriggerMetadata, "minMetricValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.minMetricValue = minMetricValue
 
 	if val, ok := config.TriggerMetadata["metricCollectionTime"]; ok && val != "" {
-		metricCollectionTime, err := strconv.Atoi(val)
+		metricCollectionTime, err := getIntUnitMetadataValue(config.TriggerMetadata, "metricCollectionTime", unitSeconds, false, 0)
 		if err != nil {
 			cloudeyeLog.Error(err, "Error parsing metricCollectionTime metadata")
 		} else {
-			meta.metricCollectionTime = int64(metricCollectionTime)
+			meta.metricCollectionTime = metricCollectionTime
 		}
 	}
 
@@ -225,39 +213,31 @@ func gethuaweiAuthorization(authParams map[string]string) (huaweiAuthorizationMe
 }
 
 func (h *huaweiCloudeyeScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	metricValue, err := h.GetCloudeyeMetrics()
+	metricValue, err := h.GetCloudeyeMetrics(ctx)
 
 	if err != nil {
 		cloudeyeLog.Error(err, "Error getting metric value")
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(metricValue), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, metricValue)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
 func (h *huaweiCloudeyeScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricValue := resource.NewQuantity(int64(h.metadata.targetMetricValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(h.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("huawei-cloudeye-%s", h.metadata.metricsName))),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricValue,
-		},
+		Target: GetMetricTargetMili(h.metadata.targetMetricValue),
 	}
 	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
 	return []v2beta2.MetricSpec{metricSpec}
 }
 
 func (h *huaweiCloudeyeScaler) IsActive(ctx context.Context) (bool, error) {
-	val, err := h.GetCloudeyeMetrics()
+	val, err := h.GetCloudeyeMetrics(ctx)
 
 	if err != nil {
 		return false, err
@@ -270,7 +250,13 @@ func (h *huaweiCloudeyeScaler) Close(context.Context) error {
 	return nil
 }
 
-func (h *huaweiCloudeyeScaler) GetCloudeyeMetrics() (float64, error) {
+// GetCloudeyeMetrics returns the value of the metric, the cloudeye client has no context so a canceled ctx
+// only fails the call before it reaches the API
+func (h *huaweiCloudeyeScaler) GetCloudeyeMetrics(ctx context.Context) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
 	options := aksk.AKSKOptions{
 		IdentityEndpoint: h.metadata.huaweiAuthorization.IdentityEndpoint,
 		ProjectID:        h.metadata.huaweiAuthorization.ProjectID,
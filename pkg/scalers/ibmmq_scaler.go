@@ -3,7 +3,6 @@ package scalers
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -32,6 +31,8 @@ const (
 type IBMMQScaler struct {
 	metadata           *IBMMQMetadata
 	defaultHTTPTimeout time.Duration
+	namespace          string
+	retryPolicy        kedautil.RetryPolicy
 }
 
 // IBMMQMetadata Metadata used by KEDA to query IBM MQ queue depth and scale
@@ -71,6 +72,8 @@ func NewIBMMQScaler(config *ScalerConfig) (Scaler, error) {
 	return &IBMMQScaler{
 		metadata:           meta,
 		defaultHTTPTimeout: config.GlobalHTTPTimeout,
+		namespace:          config.Namespace,
+		retryPolicy:        config.RetryPolicy,
 	}, nil
 }
 
@@ -171,11 +174,10 @@ func (s *IBMMQScaler) getQueueDepthViaHTTP(ctx context.Context) (int, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(s.metadata.username, s.metadata.password)
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: s.metadata.tlsDisabled},
-	}
-	client := kedautil.CreateHTTPClient(s.defaultHTTPTimeout, false)
-	client.Transport = tr
+	client := kedautil.CreateHTTPClientForNamespace(s.namespace, s.defaultHTTPTimeout, s.metadata.tlsDisabled)
+	client.Transport = kedautil.NewRetryTransport(client.Transport, s.retryPolicy)
+	// the client is built per request, its connection isn't reused
+	defer client.CloseIdleConnections()
 
 	resp, err := client.Do(req)
 	if err != nil {
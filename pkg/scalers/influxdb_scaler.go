@@ -1,25 +1,39 @@
 package scalers
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	api "github.com/influxdata/influxdb-client-go/v2/api"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	influxDBQueryLanguageFlux = "flux"
+	influxDBQueryLanguageSQL  = "sql"
+)
+
 type influxDBScaler struct {
-	client   influxdb2.Client
-	metadata *influxDBMetadata
+	client     influxdb2.Client
+	metadata   *influxDBMetadata
+	httpClient *http.Client
+
+	// databaseLock guards the database discovered for the sql queries
+	databaseLock sync.Mutex
+	database     string
 }
 
 type influxDBMetadata struct {
@@ -27,10 +41,14 @@ type influxDBMetadata struct {
 	metricName       string
 	organizationName string
 	query            string
-	serverURL        string
-	unsafeSsl        bool
-	thresholdValue   float64
-	scalerIndex      int
+	queryLanguage    string
+	// database is the database (bucket) of the InfluxDB 3 sql queries, it is discovered when not given
+	database       string
+	serverURL      string
+	unsafeSsl      bool
+	thresholdValue float64
+	missingValue   *missingValuePolicy
+	scalerIndex    int
 }
 
 var influxDBLog = logf.Log.WithName("influxdb_scaler")
@@ -42,6 +60,14 @@ func NewInfluxDBScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing influxdb metadata: %s", err)
 	}
 
+	if meta.queryLanguage == influxDBQueryLanguageSQL {
+		return &influxDBScaler{
+			metadata:   meta,
+			httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, meta.unsafeSsl),
+			database:   meta.database,
+		}, nil
+	}
+
 	influxDBLog.Info("starting up influxdb client")
 	// In case unsafeSsl is enabled.
 	if meta.unsafeSsl {
@@ -62,6 +88,8 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 	var metricName string
 	var organizationName string
 	var query string
+	var queryLanguage string
+	var database string
 	var serverURL string
 	var unsafeSsl bool
 	var thresholdValue float64
@@ -82,6 +110,14 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		return nil, fmt.Errorf("no auth token given")
 	}
 
+	queryLanguage = influxDBQueryLanguageFlux
+	if val, ok := config.TriggerMetadata["queryLanguage"]; ok && val != "" {
+		queryLanguage = strings.ToLower(val)
+		if queryLanguage != influxDBQueryLanguageFlux && queryLanguage != influxDBQueryLanguageSQL {
+			return nil, fmt.Errorf("queryLanguage must be %s or %s, got %s", influxDBQueryLanguageFlux, influxDBQueryLanguageSQL, val)
+		}
+	}
+
 	val, ok = config.TriggerMetadata["organizationName"]
 	switch {
 	case ok && val != "":
@@ -94,10 +130,21 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		}
 	case config.AuthParams["organizationName"] != "":
 		organizationName = config.AuthParams["organizationName"]
+	case queryLanguage == influxDBQueryLanguageSQL:
+		// InfluxDB 3 has no organizations
 	default:
 		return nil, fmt.Errorf("no organization name given")
 	}
 
+	if val, ok := config.TriggerMetadata["database"]; ok && val != "" {
+		if queryLanguage != influxDBQueryLanguageSQL {
+			return nil, fmt.Errorf("database is only supported with the sql queryLanguage")
+		}
+		database = val
+	} else if val, ok := config.AuthParams["database"]; ok && val != "" && queryLanguage == influxDBQueryLanguageSQL {
+		database = val
+	}
+
 	if val, ok := config.TriggerMetadata["query"]; ok {
 		query = val
 	} else {
@@ -112,20 +159,20 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		return nil, fmt.Errorf("no server url given")
 	}
 
-	if val, ok := config.TriggerMetadata["metricName"]; ok {
+	switch val, ok := config.TriggerMetadata["metricName"]; {
+	case ok:
 		metricName = kedautil.NormalizeString(fmt.Sprintf("influxdb-%s", val))
-	} else {
+	case organizationName != "":
 		metricName = kedautil.NormalizeString(fmt.Sprintf("influxdb-%s", organizationName))
+	case database != "":
+		metricName = kedautil.NormalizeString(fmt.Sprintf("influxdb-%s", database))
+	default:
+		metricName = "influxdb-sql"
 	}
 
-	if val, ok := config.TriggerMetadata["thresholdValue"]; ok {
-		value, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			return nil, fmt.Errorf("thresholdValue: failed to parse thresholdValue length %s", err.Error())
-		}
-		thresholdValue = value
-	} else {
-		return nil, fmt.Errorf("no threshold value given")
+	thresholdValue, err := getFloatMetadataValue(config.TriggerMetadata, "thresholdValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
 	unsafeSsl = false
 	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok {
@@ -136,23 +183,29 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		unsafeSsl = parsedVal
 	}
 
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+
 	return &influxDBMetadata{
 		authToken:        authToken,
 		metricName:       metricName,
 		organizationName: organizationName,
 		query:            query,
+		queryLanguage:    queryLanguage,
+		database:         database,
 		serverURL:        serverURL,
 		thresholdValue:   thresholdValue,
 		unsafeSsl:        unsafeSsl,
+		missingValue:     missingValue,
 		scalerIndex:      config.ScalerIndex,
 	}, nil
 }
 
 // IsActive returns true if queried value is above the minimum value
 func (s *influxDBScaler) IsActive(ctx context.Context) (bool, error) {
-	queryAPI := s.client.QueryAPI(s.metadata.organizationName)
-
-	value, err := queryInfluxDB(ctx, queryAPI, s.metadata.query)
+	value, err := s.getQueryResult(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -162,10 +215,31 @@ func (s *influxDBScaler) IsActive(ctx context.Context) (bool, error) {
 
 // Close closes the connection of the client to the server
 func (s *influxDBScaler) Close(context.Context) error {
-	s.client.Close()
+	if s.client != nil {
+		s.client.Close()
+	}
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
+// State returns the last value of the query kept for valueIfNull: lastValue
+func (s *influxDBScaler) State() ([]byte, error) {
+	return missingValueState(s.metadata.missingValue)
+}
+
+// RestoreState restores the last value of the query
+func (s *influxDBScaler) RestoreState(state []byte) error {
+	return restoreMissingValueState(state, s.metadata.missingValue)
+}
+
+// getQueryResult runs the Flux query through the client or the sql query through the InfluxDB 3 api
+func (s *influxDBScaler) getQueryResult(ctx context.Context) (float64, error) {
+	if s.metadata.queryLanguage == influxDBQueryLanguageSQL {
+		return s.metadata.missingValue.resolveQueryResult(s.querySQL(ctx))
+	}
+	return s.metadata.missingValue.resolveQueryResult(queryInfluxDB(ctx, s.client.QueryAPI(s.metadata.organizationName), s.metadata.query))
+}
+
 // queryInfluxDB runs the query against the associated influxdb database
 // there is an implicit assumption here that the first value returned from the iterator
 // will be the value of interest
@@ -177,7 +251,7 @@ func queryInfluxDB(ctx context.Context, queryAPI api.QueryAPI, query string) (fl
 
 	valueExists := result.Next()
 	if !valueExists {
-		return 0, fmt.Errorf("no results found from query")
+		return 0, errNoQueryResult
 	}
 
 	switch valRaw := result.Record().Value().(type) {
@@ -185,41 +259,147 @@ func queryInfluxDB(ctx context.Context, queryAPI api.QueryAPI, query string) (fl
 		return valRaw, nil
 	case int64:
 		return float64(valRaw), nil
+	case nil:
+		return 0, errNoQueryResult
 	default:
 		return 0, fmt.Errorf("value of type %T could not be converted into a float", valRaw)
 	}
 }
 
+// querySQL runs the sql query with the /api/v3/query_sql endpoint of InfluxDB 3,
+// like with Flux the first value of the first row is the value of interest
+func (s *influxDBScaler) querySQL(ctx context.Context) (float64, error) {
+	database, err := s.getDatabase(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	body, _ := json.Marshal(map[string]string{"db": database, "q": s.metadata.query, "format": "json"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.metadata.serverURL, "/")+"/api/v3/query_sql", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var value float64
+	err = s.doRequest(req, func(r io.Reader) error {
+		v, err := parseInfluxDBSQLResult(r)
+		value = v
+		return err
+	})
+	return value, err
+}
+
+// getDatabase returns the database of the sql queries, when not given it is the only database the token can read
+func (s *influxDBScaler) getDatabase(ctx context.Context) (string, error) {
+	s.databaseLock.Lock()
+	defer s.databaseLock.Unlock()
+	if s.database != "" {
+		return s.database, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.metadata.serverURL, "/")+"/api/v3/configure/database?format=json", nil)
+	if err != nil {
+		return "", err
+	}
+	var databases []map[string]string
+	err = s.doRequest(req, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&databases)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error discovering the database: %s", err)
+	}
+
+	var names []string
+	for _, database := range databases {
+		if name := database["iox::database"]; name != "" && !strings.HasPrefix(name, "_") {
+			names = append(names, name)
+		}
+	}
+	if len(names) != 1 {
+		return "", fmt.Errorf("the database must be given, the token can read %d databases", len(names))
+	}
+	s.database = names[0]
+	return s.database, nil
+}
+
+func (s *influxDBScaler) doRequest(req *http.Request, read func(io.Reader) error) error {
+	req.Header.Set("Authorization", "Bearer "+s.metadata.authToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influxdb returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return read(resp.Body)
+}
+
+// parseInfluxDBSQLResult reads the first value of the first row of a json result, the tokens are read
+// in order since the columns of a row are an object
+func parseInfluxDBSQLResult(r io.Reader) (float64, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for _, expected := range []json.Delim{'[', '{'} {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, fmt.Errorf("error parsing the query result: %s", err)
+		}
+		if token != expected {
+			if token == json.Delim(']') {
+				return 0, errNoQueryResult
+			}
+			return 0, fmt.Errorf("unexpected query result %v", token)
+		}
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, fmt.Errorf("error parsing the query result: %s", err)
+	}
+	if token == json.Delim('}') {
+		return 0, errNoQueryResult
+	}
+	column := token
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return 0, fmt.Errorf("error parsing the query result: %s", err)
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case nil:
+		return 0, errNoQueryResult
+	default:
+		return 0, fmt.Errorf("value %v of the column %v could not be converted into a float", value, column)
+	}
+}
+
 // GetMetrics connects to influxdb via the client and returns a value based on the query
 func (s *influxDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	// Grab QueryAPI to make queries to influxdb instance
-	queryAPI := s.client.QueryAPI(s.metadata.organizationName)
-
-	value, err := queryInfluxDB(ctx, queryAPI, s.metadata.query)
+	value, err := s.getQueryResult(ctx)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(value), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, value)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
 // GetMetricSpecForScaling returns the metric spec for the Horizontal Pod Autoscaler
 func (s *influxDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricValue := resource.NewQuantity(int64(s.metadata.thresholdValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.thresholdValue),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -2,9 +2,15 @@ package scalers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/stretchr/testify/assert"
 )
 
 var testInfluxDBResolvedEnv = map[string]string{
@@ -45,11 +51,21 @@ var testInfluxDBMetadata = []parseInfluxDBMetadataTestData{
 	{map[string]string{"query": "from(bucket: hello)", "thresholdValue": "10", "unsafeSsl": "false"}, false, map[string]string{"serverURL": "https://influxdata.com", "organizationName": "influx_org", "authToken": "myToken"}},
 	// no sunsafeSsl value passed
 	{map[string]string{"serverURL": "https://influxdata.com", "metricName": "influx_metric", "organizationName": "influx_org", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// sql without organization name
+	{map[string]string{"serverURL": "https://influxdata.com", "queryLanguage": "sql", "database": "sensors", "query": "SELECT count(*) FROM jobs", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// sql with the database discovered
+	{map[string]string{"serverURL": "https://influxdata.com", "queryLanguage": "SQL", "query": "SELECT count(*) FROM jobs", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// unknown query language
+	{map[string]string{"serverURL": "https://influxdata.com", "queryLanguage": "influxql", "organizationName": "influx_org", "query": "SELECT count(*) FROM jobs", "thresholdValue": "10", "authToken": "myToken"}, true, map[string]string{}},
+	// database with flux
+	{map[string]string{"serverURL": "https://influxdata.com", "organizationName": "influx_org", "database": "sensors", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, true, map[string]string{}},
 }
 
 var influxDBMetricIdentifiers = []influxDBMetricIdentifier{
 	{&testInfluxDBMetadata[1], 0, "s0-influxdb-influx_metric"},
 	{&testInfluxDBMetadata[2], 1, "s1-influxdb-influx_org"},
+	{&testInfluxDBMetadata[10], 2, "s2-influxdb-sensors"},
+	{&testInfluxDBMetadata[11], 3, "s3-influxdb-sql"},
 }
 
 func TestInfluxDBParseMetadata(t *testing.T) {
@@ -72,7 +88,7 @@ func TestInfluxDBGetMetricSpecForScaling(t *testing.T) {
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockInfluxDBScaler := influxDBScaler{influxdb2.NewClient("https://influxdata.com", "myToken"), meta}
+		mockInfluxDBScaler := influxDBScaler{client: influxdb2.NewClient("https://influxdata.com", "myToken"), metadata: meta}
 
 		metricSpec := mockInfluxDBScaler.GetMetricSpecForScaling(context.Background())
 		metricName := metricSpec[0].External.Metric.Name
@@ -81,3 +97,72 @@ func TestInfluxDBGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestInfluxDBSQLQuery(t *testing.T) {
+	var databases string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer myToken", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/api/v3/configure/database":
+			fmt.Fprint(w, databases)
+		case "/api/v3/query_sql":
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "SELECT count(*) AS jobs, max(time) FROM jobs", body["q"])
+			if body["db"] != "sensors" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"error": "database not found"}`)
+				return
+			}
+			fmt.Fprint(w, `[{"jobs": 12, "max(time)": "2021-12-20T10:00:00"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newScaler := func(metadata map[string]string) *influxDBScaler {
+		metadata["serverURL"] = server.URL
+		metadata["queryLanguage"] = "sql"
+		metadata["query"] = "SELECT count(*) AS jobs, max(time) FROM jobs"
+		metadata["thresholdValue"] = "10"
+		metadata["authToken"] = "myToken"
+		scaler, err := NewInfluxDBScaler(&ScalerConfig{TriggerMetadata: metadata})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		return scaler.(*influxDBScaler)
+	}
+
+	metrics, err := newScaler(map[string]string{"database": "sensors"}).GetMetrics(context.Background(), "s0-influxdb-sensors", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), metrics[0].Value.Value())
+
+	databases = `[{"iox::database": "_internal"}, {"iox::database": "sensors"}]`
+	active, err := newScaler(map[string]string{}).IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	databases = `[{"iox::database": "sensors"}, {"iox::database": "events"}]`
+	_, err = newScaler(map[string]string{}).IsActive(context.Background())
+	assert.Error(t, err)
+
+	_, err = newScaler(map[string]string{"database": "events"}).IsActive(context.Background())
+	assert.Error(t, err)
+}
+
+func TestParseInfluxDBSQLResult(t *testing.T) {
+	for result, expected := range map[string]float64{
+		`[{"count": 3}]`:                 3,
+		`[{"avg": 2.5, "count": 3}, {}]`: 2.5,
+	} {
+		value, err := parseInfluxDBSQLResult(strings.NewReader(result))
+		assert.NoError(t, err, result)
+		assert.Equal(t, expected, value, result)
+	}
+
+	for _, result := range []string{`[]`, `[{}]`, `[{"host": "a"}]`, `{"error": "failed"}`, `[{"count": `} {
+		_, err := parseInfluxDBSQLResult(strings.NewReader(result))
+		assert.Error(t, err, result)
+	}
+}
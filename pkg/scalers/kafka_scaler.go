@@ -8,13 +8,14 @@ import (
 	"strings"
 
 	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
@@ -22,6 +23,7 @@ type kafkaScaler struct {
 	metadata kafkaMetadata
 	client   sarama.Client
 	admin    sarama.ClusterAdmin
+	logger   logr.Logger
 }
 
 type kafkaMetadata struct {
@@ -32,17 +34,30 @@ type kafkaMetadata struct {
 	offsetResetPolicy  offsetResetPolicy
 	allowIdleConsumers bool
 	version            sarama.KafkaVersion
+	// excludeUnassignedPartitions ignores the lag of the partitions without consumer, eg. during a rebalance
+	excludeUnassignedPartitions bool
+	// partitionLagCap caps the lag of each partition, 0 doesn't cap it
+	partitionLagCap int64
 
 	// SASL
 	saslType kafkaSaslType
 	username string
 	password string
 
+	// GSSAPI, the keytab authenticates the username when given, the password otherwise
+	realm               string
+	keytabPath          string
+	kerberosConfigPath  string
+	kerberosServiceName string
+
 	// TLS
 	enableTLS bool
 	cert      string
 	key       string
 	ca        string
+	// enableSPIFFE authenticates with the SVID of the operator, the brokers are authorized with spiffeServerID
+	enableSPIFFE   bool
+	spiffeServerID string
 
 	scalerIndex int
 }
@@ -62,6 +77,7 @@ const (
 	KafkaSASLTypePlaintext   kafkaSaslType = "plaintext"
 	KafkaSASLTypeSCRAMSHA256 kafkaSaslType = "scram_sha256"
 	KafkaSASLTypeSCRAMSHA512 kafkaSaslType = "scram_sha512"
+	KafkaSASLTypeGSSAPI      kafkaSaslType = "gssapi"
 )
 
 const (
@@ -69,11 +85,12 @@ const (
 	kafkaMetricType          = "External"
 	defaultKafkaLagThreshold = 10
 	defaultOffsetResetPolicy = latest
-	invalidOffset            = -1
+	// defaultKerberosConfigPath is where the krb5.conf of the operator is usually mounted
+	defaultKerberosConfigPath       = "/etc/krb5.conf"
+	defaultKafkaKerberosServiceName = "kafka"
+	invalidOffset                   = -1
 )
 
-var kafkaLog = logf.Log.WithName("kafka_scaler")
-
 // NewKafkaScaler creates a new kafkaScaler
 func NewKafkaScaler(config *ScalerConfig) (Scaler, error) {
 	kafkaMetadata, err := parseKafkaMetadata(config)
@@ -90,6 +107,7 @@ func NewKafkaScaler(config *ScalerConfig) (Scaler, error) {
 		client:   client,
 		admin:    admin,
 		metadata: kafkaMetadata,
+		logger:   InitializeLogger(config, "kafka_scaler"),
 	}, nil
 }
 
@@ -158,6 +176,11 @@ func parseKafkaMetadata(config *ScalerConfig) (kafkaMetadata, error) {
 			}
 			meta.password = strings.TrimSpace(config.AuthParams["password"])
 			meta.saslType = mode
+		} else if mode == KafkaSASLTypeGSSAPI {
+			if err := parseKafkaKerberosAuthParams(config, &meta); err != nil {
+				return meta, err
+			}
+			meta.saslType = mode
 		} else {
 			return meta, fmt.Errorf("err SASL mode %s given", mode)
 		}
@@ -185,6 +208,14 @@ func parseKafkaMetadata(config *ScalerConfig) (kafkaMetadata, error) {
 		}
 	}
 
+	if config.PodIdentity == kedav1alpha1.PodIdentityProviderSpiffe {
+		if meta.enableTLS {
+			return meta, errors.New("spiffe pod identity can't be used with tls")
+		}
+		meta.enableSPIFFE = true
+		meta.spiffeServerID = config.TriggerMetadata["spiffeServerID"]
+	}
+
 	meta.allowIdleConsumers = false
 	if val, ok := config.TriggerMetadata["allowIdleConsumers"]; ok {
 		t, err := strconv.ParseBool(val)
@@ -194,6 +225,25 @@ func parseKafkaMetadata(config *ScalerConfig) (kafkaMetadata, error) {
 		meta.allowIdleConsumers = t
 	}
 
+	if val, ok := config.TriggerMetadata["excludeUnassignedPartitions"]; ok {
+		t, err := strconv.ParseBool(val)
+		if err != nil {
+			return meta, fmt.Errorf("error parsing excludeUnassignedPartitions: %s", err)
+		}
+		meta.excludeUnassignedPartitions = t
+	}
+
+	if val, ok := config.TriggerMetadata["partitionLagCap"]; ok {
+		t, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return meta, fmt.Errorf("error parsing partitionLagCap: %s", err)
+		}
+		if t <= 0 {
+			return meta, fmt.Errorf("partitionLagCap must be greater than 0")
+		}
+		meta.partitionLagCap = t
+	}
+
 	meta.version = sarama.V1_0_0_0
 	if val, ok := config.TriggerMetadata["version"]; ok {
 		val = strings.TrimSpace(val)
@@ -209,7 +259,7 @@ func parseKafkaMetadata(config *ScalerConfig) (kafkaMetadata, error) {
 
 // IsActive determines if we need to scale from zero
 func (s *kafkaScaler) IsActive(ctx context.Context) (bool, error) {
-	partitions, err := s.getPartitions()
+	partitions, err := s.getPartitions(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -224,12 +274,20 @@ func (s *kafkaScaler) IsActive(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
+	assigned, err := s.getAssignedPartitions()
+	if err != nil {
+		return false, err
+	}
+
 	for _, partition := range partitions {
+		if assigned != nil && !assigned[partition] {
+			continue
+		}
 		lag, err := s.getLagForPartition(partition, offsets, topicOffsets)
 		if err != nil && lag == invalidOffset {
 			return true, nil
 		}
-		kafkaLog.V(1).Info(fmt.Sprintf("Group %s has a lag of %d for topic %s and partition %d\n", s.metadata.group, lag, s.metadata.topic, partition))
+		s.logger.V(1).Info("Found the lag of a partition", "group", s.metadata.group, "topic", s.metadata.topic, "partition", partition, "lag", lag)
 
 		// Return as soon as a lag was detected for any partition
 		if lag > 0 {
@@ -240,6 +298,37 @@ func (s *kafkaScaler) IsActive(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
+// parseKafkaKerberosAuthParams parses the GSSAPI auth params, the keytab and krb5.conf are files mounted in the operator
+func parseKafkaKerberosAuthParams(config *ScalerConfig, meta *kafkaMetadata) error {
+	meta.username = strings.TrimSpace(config.AuthParams["username"])
+	if meta.username == "" {
+		return errors.New("no username given")
+	}
+	meta.realm = strings.TrimSpace(config.AuthParams["realm"])
+	if meta.realm == "" {
+		return errors.New("no realm given")
+	}
+
+	meta.keytabPath = strings.TrimSpace(config.AuthParams["keytab"])
+	meta.password = strings.TrimSpace(config.AuthParams["password"])
+	switch {
+	case meta.keytabPath == "" && meta.password == "":
+		return errors.New("no keytab or password given")
+	case meta.keytabPath != "" && meta.password != "":
+		return errors.New("keytab and password can't be given together")
+	}
+
+	meta.kerberosConfigPath = defaultKerberosConfigPath
+	if val := strings.TrimSpace(config.AuthParams["kerberosConfig"]); val != "" {
+		meta.kerberosConfigPath = val
+	}
+	meta.kerberosServiceName = defaultKafkaKerberosServiceName
+	if val := strings.TrimSpace(config.AuthParams["kerberosServiceName"]); val != "" {
+		meta.kerberosServiceName = val
+	}
+	return nil
+}
+
 func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin, error) {
 	config := sarama.NewConfig()
 	config.Version = metadata.version
@@ -259,6 +348,15 @@ func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin
 		config.Net.TLS.Config = tlsConfig
 	}
 
+	if metadata.enableSPIFFE {
+		tlsConfig, err := kedautil.NewSPIFFETLSConfig(metadata.spiffeServerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
 	if metadata.saslType == KafkaSASLTypePlaintext {
 		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
 	}
@@ -273,6 +371,22 @@ func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin
 		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
 	}
 
+	if metadata.saslType == KafkaSASLTypeGSSAPI {
+		config.Net.SASL.Mechanism = sarama.SASLTypeGSSAPI
+		config.Net.SASL.GSSAPI = sarama.GSSAPIConfig{
+			AuthType:           sarama.KRB5_USER_AUTH,
+			Username:           metadata.username,
+			Password:           metadata.password,
+			Realm:              metadata.realm,
+			KerberosConfigPath: metadata.kerberosConfigPath,
+			ServiceName:        metadata.kerberosServiceName,
+		}
+		if metadata.keytabPath != "" {
+			config.Net.SASL.GSSAPI.AuthType = sarama.KRB5_KEYTAB_AUTH
+			config.Net.SASL.GSSAPI.KeyTabPath = metadata.keytabPath
+		}
+	}
+
 	client, err := sarama.NewClient(metadata.bootstrapServers, config)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error creating kafka client: %s", err)
@@ -289,7 +403,12 @@ func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin
 	return client, admin, nil
 }
 
-func (s *kafkaScaler) getPartitions() ([]int32, error) {
+// getPartitions returns the partitions of the topic, sarama has no context so a canceled ctx only
+// fails the calls before they reach the brokers
+func (s *kafkaScaler) getPartitions(ctx context.Context) ([]int32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	topicsMetadata, err := s.admin.DescribeTopics([]string{s.metadata.topic})
 	if err != nil {
 		return nil, fmt.Errorf("error describing topics: %s", err)
@@ -307,6 +426,16 @@ func (s *kafkaScaler) getPartitions() ([]int32, error) {
 	return partitions, nil
 }
 
+// GetPartitionCount returns the number of partitions of the topic
+func (s *kafkaScaler) GetPartitionCount(ctx context.Context) (int64, error) {
+	partitions, err := s.getPartitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(partitions)), nil
+}
+
 func (s *kafkaScaler) getOffsets(partitions []int32) (*sarama.OffsetFetchResponse, error) {
 	offsets, err := s.admin.ListConsumerGroupOffsets(s.metadata.group, map[string][]int32{
 		s.metadata.topic: partitions,
@@ -319,15 +448,50 @@ func (s *kafkaScaler) getOffsets(partitions []int32) (*sarama.OffsetFetchRespons
 	return offsets, nil
 }
 
+// getAssignedPartitions returns the partitions of the topic assigned to a member of the consumer group,
+// it returns nil when excludeUnassignedPartitions isn't set or when the group has no member, eg. scaled to zero
+func (s *kafkaScaler) getAssignedPartitions() (map[int32]bool, error) {
+	if !s.metadata.excludeUnassignedPartitions {
+		return nil, nil
+	}
+
+	groups, err := s.admin.DescribeConsumerGroups([]string{s.metadata.group})
+	if err != nil {
+		return nil, fmt.Errorf("error describing consumer group: %s", err)
+	}
+	if len(groups) != 1 {
+		return nil, fmt.Errorf("expected only 1 consumer group description, got %d", len(groups))
+	}
+	if len(groups[0].Members) == 0 {
+		return nil, nil
+	}
+
+	assigned := map[int32]bool{}
+	for _, member := range groups[0].Members {
+		assignment, err := member.GetMemberAssignment()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding assignment of consumer group member: %s", err)
+		}
+		if assignment == nil {
+			continue
+		}
+		for _, partition := range assignment.Topics[s.metadata.topic] {
+			assigned[partition] = true
+		}
+	}
+	return assigned, nil
+}
+
 func (s *kafkaScaler) getLagForPartition(partition int32, offsets *sarama.OffsetFetchResponse, topicOffsets map[int32]int64) (int64, error) {
 	block := offsets.GetBlock(s.metadata.topic, partition)
 	if block == nil {
-		kafkaLog.Error(fmt.Errorf("error finding offset block for topic %s and partition %d", s.metadata.topic, partition), "")
-		return 0, fmt.Errorf("error finding offset block for topic %s and partition %d", s.metadata.topic, partition)
+		err := fmt.Errorf("error finding offset block for topic %s and partition %d", s.metadata.topic, partition)
+		s.logger.Error(err, "Error finding the offset block", "topic", s.metadata.topic, "partition", partition)
+		return 0, err
 	}
 	consumerOffset := block.Offset
 	if consumerOffset == invalidOffset && s.metadata.offsetResetPolicy == latest {
-		kafkaLog.V(0).Info(fmt.Sprintf("invalid offset found for topic %s in group %s and partition %d, probably no offset is committed yet", s.metadata.topic, s.metadata.group, partition))
+		s.logger.V(0).Info("Invalid offset found, probably no offset is committed yet", "group", s.metadata.group, "topic", s.metadata.topic, "partition", partition)
 		return invalidOffset, fmt.Errorf("invalid offset found for topic %s in group %s and partition %d, probably no offset is committed yet", s.metadata.topic, s.metadata.group, partition)
 	}
 
@@ -366,7 +530,7 @@ func (s *kafkaScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricS
 
 // GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
 func (s *kafkaScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	partitions, err := s.getPartitions()
+	partitions, err := s.getPartitions(ctx)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, err
 	}
@@ -381,14 +545,26 @@ func (s *kafkaScaler) GetMetrics(ctx context.Context, metricName string, metricS
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
+	assigned, err := s.getAssignedPartitions()
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
 	totalLag := int64(0)
 	for _, partition := range partitions {
+		if assigned != nil && !assigned[partition] {
+			continue
+		}
 		lag, _ := s.getLagForPartition(partition, offsets, topicOffsets)
 
+		// a poison partition doesn't scale out the consumers of the other partitions
+		if s.metadata.partitionLagCap > 0 && lag > s.metadata.partitionLagCap {
+			lag = s.metadata.partitionLagCap
+		}
 		totalLag += lag
 	}
 
-	kafkaLog.V(1).Info(fmt.Sprintf("Kafka scaler: Providing metrics based on totalLag %v, partitions %v, threshold %v", totalLag, len(partitions), s.metadata.lagThreshold))
+	s.logger.V(1).Info("Providing metrics based on the total lag", "totalLag", totalLag, "partitions", len(partitions), "threshold", s.metadata.lagThreshold)
 
 	if !s.metadata.allowIdleConsumers {
 		// don't scale out beyond the number of partitions
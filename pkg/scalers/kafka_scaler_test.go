@@ -4,6 +4,8 @@ import (
 	"context"
 	"reflect"
 	"testing"
+
+	"github.com/go-logr/logr"
 )
 
 type parseKafkaMetadataTestData struct {
@@ -113,6 +115,16 @@ var parseKafkaAuthParamsTestDataset = []parseKafkaAuthParamsTestData{
 	{map[string]string{"sasl": "plaintext", "username": "admin", "password": "admin", "tls": "enable", "ca": "caaa", "key": "keey"}, true, false},
 	// failure, SASL + TLS, missing key
 	{map[string]string{"sasl": "plaintext", "username": "admin", "password": "admin", "tls": "enable", "ca": "caaa", "cert": "ceert"}, true, false},
+	// success, GSSAPI with keytab
+	{map[string]string{"sasl": "gssapi", "username": "keda", "realm": "EXAMPLE.COM", "keytab": "/etc/keda/keda.keytab"}, false, false},
+	// success, GSSAPI with password and krb5.conf
+	{map[string]string{"sasl": "gssapi", "username": "keda", "realm": "EXAMPLE.COM", "password": "admin", "kerberosConfig": "/etc/keda/krb5.conf"}, false, false},
+	// failure, GSSAPI missing realm
+	{map[string]string{"sasl": "gssapi", "username": "keda", "keytab": "/etc/keda/keda.keytab"}, true, false},
+	// failure, GSSAPI missing keytab and password
+	{map[string]string{"sasl": "gssapi", "username": "keda", "realm": "EXAMPLE.COM"}, true, false},
+	// failure, GSSAPI with keytab and password
+	{map[string]string{"sasl": "gssapi", "username": "keda", "realm": "EXAMPLE.COM", "keytab": "/etc/keda/keda.keytab", "password": "admin"}, true, false},
 }
 
 var kafkaMetricIdentifiers = []kafkaMetricIdentifier{
@@ -196,7 +208,7 @@ func TestKafkaGetMetricSpecForScaling(t *testing.T) {
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockKafkaScaler := kafkaScaler{meta, nil, nil}
+		mockKafkaScaler := kafkaScaler{meta, nil, nil, logr.DiscardLogger{}}
 
 		metricSpec := mockKafkaScaler.GetMetricSpecForScaling(context.Background())
 		metricName := metricSpec[0].External.Metric.Name
@@ -205,3 +217,42 @@ func TestKafkaGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestKafkaLagOptions(t *testing.T) {
+	metadata := func(options map[string]string) map[string]string {
+		result := map[string]string{}
+		for key, value := range validKafkaMetadata {
+			result[key] = value
+		}
+		for key, value := range options {
+			result[key] = value
+		}
+		return result
+	}
+
+	meta, err := parseKafkaMetadata(&ScalerConfig{TriggerMetadata: metadata(map[string]string{"excludeUnassignedPartitions": "true", "partitionLagCap": "500"}), AuthParams: validWithoutAuthParams})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	if !meta.excludeUnassignedPartitions || meta.partitionLagCap != 500 {
+		t.Errorf("Expected excludeUnassignedPartitions true and partitionLagCap 500 but got %t and %d", meta.excludeUnassignedPartitions, meta.partitionLagCap)
+	}
+
+	meta, err = parseKafkaMetadata(&ScalerConfig{TriggerMetadata: validKafkaMetadata, AuthParams: validWithoutAuthParams})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	if meta.excludeUnassignedPartitions || meta.partitionLagCap != 0 {
+		t.Errorf("Expected the lag options to be disabled by default")
+	}
+
+	for _, invalid := range []map[string]string{
+		{"excludeUnassignedPartitions": "maybe"},
+		{"partitionLagCap": "0"},
+		{"partitionLagCap": "lots"},
+	} {
+		if _, err := parseKafkaMetadata(&ScalerConfig{TriggerMetadata: metadata(invalid), AuthParams: validWithoutAuthParams}); err == nil {
+			t.Errorf("Expected error for %v but got success", invalid)
+		}
+	}
+}
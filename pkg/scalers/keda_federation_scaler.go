@@ -0,0 +1,183 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// federationScaledObjectLabel selects the ScaledObject of the metric on the remote KEDA metrics server
+const federationScaledObjectLabel = "scaledobject.keda.sh/name"
+
+type kedaFederationScaler struct {
+	metadata   *kedaFederationMetadata
+	httpClient *http.Client
+}
+
+type kedaFederationMetadata struct {
+	// Address is the remote KEDA metrics server, the metric of the ScaledObject is read through its external metrics API
+	Address          string  `keda:"name=address, order=triggerMetadata;authParams"`
+	Namespace        string  `keda:"name=namespace, optional"`
+	ScaledObjectName string  `keda:"name=scaledObjectName"`
+	MetricName       string  `keda:"name=metricName"`
+	TargetValue      float64 `keda:"name=targetValue"`
+
+	// client certification, required since the remote metrics server is queried over mTLS
+	Cert string `keda:"name=cert, order=authParams"`
+	Key  string `keda:"name=key, order=authParams"`
+	CA   string `keda:"name=ca, order=authParams, optional"`
+
+	// optional bearer token, e.g. of a service account allowed to read external metrics of the remote cluster
+	BearerToken string `keda:"name=bearerToken, order=authParams, optional"`
+
+	scalerIndex int
+}
+
+// Validate checks the address is an https URL, the remote metrics server is queried over mTLS, and the target is positive
+func (m *kedaFederationMetadata) Validate() error {
+	address, err := url.Parse(m.Address)
+	if err != nil || address.Scheme != "https" || address.Host == "" {
+		return errors.New("address must be an https URL")
+	}
+	if m.TargetValue <= 0 {
+		return errors.New("targetValue must be greater than 0")
+	}
+	return nil
+}
+
+var kedaFederationLog = logf.Log.WithName("keda_federation_scaler")
+
+// NewKedaFederationScaler creates a new scaler reading the metric of a ScaledObject from the KEDA of another cluster
+func NewKedaFederationScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseKedaFederationMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing keda-federation metadata: %s", err)
+	}
+
+	tlsConfig, err := kedautil.NewTLSConfig(meta.Cert, meta.Key, meta.CA)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the TLS config: %s", err)
+	}
+	httpClient := createTLSHTTPClient(config, config.GlobalHTTPTimeout, tlsConfig)
+
+	return &kedaFederationScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseKedaFederationMetadata(config *ScalerConfig) (*kedaFederationMetadata, error) {
+	meta := kedaFederationMetadata{}
+	if err := config.TypedConfig(&meta); err != nil {
+		return nil, err
+	}
+	meta.Address = strings.TrimSuffix(meta.Address, "/")
+	if meta.Namespace == "" {
+		meta.Namespace = config.Namespace
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive returns true if the remote metric is greater than 0
+func (s *kedaFederationScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getRemoteMetric(ctx)
+	if err != nil {
+		kedaFederationLog.Error(err, "error reading the remote metric")
+		return false, err
+	}
+	return value.Sign() > 0, nil
+}
+
+// Close does nothing in case of kedaFederationScaler
+func (s *kedaFederationScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *kedaFederationScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("keda-federation-%s", s.metadata.ScaledObjectName))),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the metric computed by the remote KEDA for the ScaledObject
+func (s *kedaFederationScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getRemoteMetric(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error reading the remote metric: %s", err)
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      value,
+		Timestamp:  metav1.Now(),
+	}
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getRemoteMetric reads the metric of the ScaledObject from the external metrics API of the remote metrics server,
+// the values of a metric exposed by several scalers are summed
+func (s *kedaFederationScaler) getRemoteMetric(ctx context.Context) (resource.Quantity, error) {
+	query := url.Values{"labelSelector": {fmt.Sprintf("%s=%s", federationScaledObjectLabel, s.metadata.ScaledObjectName)}}
+	metricURL := fmt.Sprintf("%s/apis/external.metrics.k8s.io/v1beta1/namespaces/%s/%s?%s",
+		s.metadata.Address, url.PathEscape(s.metadata.Namespace), url.PathEscape(s.metadata.MetricName), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricURL, nil)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.metadata.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.metadata.BearerToken)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resource.Quantity{}, fmt.Errorf("remote metrics server returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var metrics external_metrics.ExternalMetricValueList
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return resource.Quantity{}, fmt.Errorf("error parsing the remote metrics: %s", err)
+	}
+	if len(metrics.Items) == 0 {
+		return resource.Quantity{}, fmt.Errorf("no metric %s for the ScaledObject %s/%s", s.metadata.MetricName, s.metadata.Namespace, s.metadata.ScaledObjectName)
+	}
+
+	value := resource.Quantity{Format: resource.DecimalSI}
+	for _, item := range metrics.Items {
+		value.Add(item.Value)
+	}
+	return value, nil
+}
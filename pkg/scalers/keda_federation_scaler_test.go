@@ -0,0 +1,107 @@
+package scalers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseKedaFederationMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+var testKedaFederationAuthParams = map[string]string{"cert": "cert", "key": "key", "ca": "ca"}
+
+var testKedaFederationMetadata = []parseKedaFederationMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"address": "https://keda-metrics.primary:6443", "scaledObjectName": "orders", "metricName": "s0-rabbitmq-orders", "targetValue": "20"}, testKedaFederationAuthParams, false},
+	// address in authParams
+	{map[string]string{"scaledObjectName": "orders", "metricName": "s0-rabbitmq-orders", "targetValue": "2.5"}, map[string]string{"address": "https://keda-metrics.primary:6443", "cert": "cert", "key": "key"}, false},
+	// plain http address
+	{map[string]string{"address": "http://keda-metrics.primary:8080", "scaledObjectName": "orders", "metricName": "s0-rabbitmq-orders", "targetValue": "20"}, testKedaFederationAuthParams, true},
+	// no scaledObjectName
+	{map[string]string{"address": "https://keda-metrics.primary:6443", "metricName": "s0-rabbitmq-orders", "targetValue": "20"}, testKedaFederationAuthParams, true},
+	// no metricName
+	{map[string]string{"address": "https://keda-metrics.primary:6443", "scaledObjectName": "orders", "targetValue": "20"}, testKedaFederationAuthParams, true},
+	// no targetValue
+	{map[string]string{"address": "https://keda-metrics.primary:6443", "scaledObjectName": "orders", "metricName": "s0-rabbitmq-orders"}, testKedaFederationAuthParams, true},
+	// invalid targetValue
+	{map[string]string{"address": "https://keda-metrics.primary:6443", "scaledObjectName": "orders", "metricName": "s0-rabbitmq-orders", "targetValue": "0"}, testKedaFederationAuthParams, true},
+	// no client certificate
+	{map[string]string{"address": "https://keda-metrics.primary:6443", "scaledObjectName": "orders", "metricName": "s0-rabbitmq-orders", "targetValue": "20"}, map[string]string{"ca": "ca"}, true},
+}
+
+func TestKedaFederationParseMetadata(t *testing.T) {
+	for i, testData := range testKedaFederationMetadata {
+		_, err := parseKedaFederationMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, Namespace: "standby"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestKedaFederationGetMetrics(t *testing.T) {
+	cert, key := testRedisCA(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Len(t, r.TLS.PeerCertificates, 1)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "scaledobject.keda.sh/name=orders", r.URL.Query().Get("labelSelector"))
+		switch r.URL.Path {
+		case "/apis/external.metrics.k8s.io/v1beta1/namespaces/shop/s0-rabbitmq-orders":
+			fmt.Fprint(w, `{"kind": "ExternalMetricValueList", "apiVersion": "external.metrics.k8s.io/v1beta1", "items": [`+
+				`{"metricName": "s0-rabbitmq-orders", "timestamp": "2021-12-20T10:00:00Z", "value": "1500m"},`+
+				`{"metricName": "s0-rabbitmq-orders", "timestamp": "2021-12-20T10:00:00Z", "value": "2"}]}`)
+		case "/apis/external.metrics.k8s.io/v1beta1/namespaces/shop/s1-missing":
+			fmt.Fprint(w, `{"kind": "ExternalMetricValueList", "apiVersion": "external.metrics.k8s.io/v1beta1", "items": []}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "no ScaledObject")
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	newScaler := func(metricName string) Scaler {
+		scaler, err := NewKedaFederationScaler(&ScalerConfig{
+			TriggerMetadata: map[string]string{"address": server.URL, "namespace": "shop", "scaledObjectName": "orders", "metricName": metricName, "targetValue": "10"},
+			AuthParams:      map[string]string{"cert": cert, "key": key, "ca": cert, "bearerToken": "token"},
+			Namespace:       "standby",
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		return scaler
+	}
+
+	scaler := newScaler("s0-rabbitmq-orders")
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+	assert.Equal(t, "s0-keda-federation-orders", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(10), metricSpec[0].External.Target.AverageValue.Value())
+
+	metrics, err := scaler.GetMetrics(context.Background(), "s0-keda-federation-orders", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3500), metrics[0].Value.MilliValue())
+
+	active, err := scaler.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	_, err = newScaler("s1-missing").GetMetrics(context.Background(), "s0-keda-federation-orders", nil)
+	assert.EqualError(t, err, "error reading the remote metric: no metric s1-missing for the ScaledObject shop/orders")
+
+	_, err = newScaler("s2-unknown").GetMetrics(context.Background(), "s0-keda-federation-orders", nil)
+	assert.EqualError(t, err, "error reading the remote metric: remote metrics server returned 404: no ScaledObject")
+}
@@ -0,0 +1,256 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultMemcachedTimeout = 5 * time.Second
+)
+
+type memcachedScaler struct {
+	metadata *memcachedMetadata
+	timeout  time.Duration
+	// namespace of the trigger, its egress policy applies to the connections
+	namespace string
+}
+
+type memcachedMetadata struct {
+	// The address of the memcached server, eg. memcached:11211
+	address string
+	// stat is the name of a general-purpose statistic returned by the stats command, eg. curr_items or evictions
+	stat string
+	// key is a counter set by the application, eg. a namespace-prefixed counter incremented with incr
+	key         string
+	targetValue int
+	metricName  string
+	scalerIndex int
+}
+
+var memcachedLog = logf.Log.WithName("memcached_scaler")
+
+// NewMemcachedScaler creates a new memcachedScaler
+func NewMemcachedScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseMemcachedMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing memcached metadata: %s", err)
+	}
+
+	timeout := config.GlobalHTTPTimeout
+	if timeout <= 0 {
+		timeout = defaultMemcachedTimeout
+	}
+
+	return &memcachedScaler{
+		metadata:  meta,
+		timeout:   timeout,
+		namespace: config.Namespace,
+	}, nil
+}
+
+func parseMemcachedMetadata(config *ScalerConfig) (*memcachedMetadata, error) {
+	meta := memcachedMetadata{}
+
+	switch {
+	case config.TriggerMetadata["address"] != "":
+		meta.address = config.TriggerMetadata["address"]
+	case config.TriggerMetadata["host"] != "":
+		port := config.TriggerMetadata["port"]
+		if port == "" {
+			port = "11211"
+		}
+		meta.address = net.JoinHostPort(config.TriggerMetadata["host"], port)
+	default:
+		return nil, fmt.Errorf("no address or host given")
+	}
+
+	meta.stat = config.TriggerMetadata["stat"]
+	meta.key = config.TriggerMetadata["key"]
+	switch {
+	case meta.stat == "" && meta.key == "":
+		return nil, fmt.Errorf("no stat or key given")
+	case meta.stat != "" && meta.key != "":
+		return nil, fmt.Errorf("stat and key can not be set both")
+	case strings.ContainsAny(meta.key, " \t\r\n"):
+		return nil, fmt.Errorf("key must not contain whitespaces")
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	name := meta.stat
+	if meta.key != "" {
+		name = meta.key
+	}
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		name = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("memcached-%s", name))
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// getValue reads the stat or the counter with the text protocol of memcached,
+// rates like evictions per second are reported with the rate metricMode of the trigger
+func (s *memcachedScaler) getValue(ctx context.Context) (int64, error) {
+	conn, err := kedautil.NewEgressDialer(s.namespace, s.timeout).DialContext(ctx, "tcp", s.metadata.address)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return -1, err
+	}
+
+	if s.metadata.key != "" {
+		return getMemcachedCounter(conn, s.metadata.key)
+	}
+	return getMemcachedStat(conn, s.metadata.stat)
+}
+
+func getMemcachedStat(conn net.Conn, stat string) (int64, error) {
+	if _, err := fmt.Fprint(conn, "stats\r\n"); err != nil {
+		return -1, err
+	}
+
+	var value string
+	found := false
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "END" {
+			break
+		}
+		// STAT <name> <value>
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "STAT" && fields[1] == stat {
+			value, found = fields[2], true
+		}
+		if strings.HasPrefix(line, "ERROR") || strings.HasPrefix(line, "SERVER_ERROR") {
+			return -1, fmt.Errorf("memcached returned %s", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return -1, err
+	}
+	if !found {
+		return -1, fmt.Errorf("memcached stat %s not found", stat)
+	}
+
+	// some stats like rusage_user are decimal
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return -1, fmt.Errorf("memcached stat %s is not numeric: %s", stat, value)
+	}
+	return int64(parsed), nil
+}
+
+func getMemcachedCounter(conn net.Conn, key string) (int64, error) {
+	if _, err := fmt.Fprintf(conn, "get %s\r\n", key); err != nil {
+		return -1, err
+	}
+
+	var value string
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "END":
+			if value == "" {
+				// a missing counter has not been incremented yet
+				return 0, nil
+			}
+			parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return -1, fmt.Errorf("memcached key %s is not numeric: %s", key, value)
+			}
+			return parsed, nil
+		case strings.HasPrefix(line, "VALUE "):
+			// VALUE <key> <flags> <bytes>, the data is on the next line
+			if !scanner.Scan() {
+				return -1, fmt.Errorf("memcached didn't return the value of key %s", key)
+			}
+			value = scanner.Text()
+		case strings.HasPrefix(line, "ERROR"), strings.HasPrefix(line, "CLIENT_ERROR"), strings.HasPrefix(line, "SERVER_ERROR"):
+			return -1, fmt.Errorf("memcached returned %s", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return -1, err
+	}
+	return -1, fmt.Errorf("memcached closed the connection")
+}
+
+// IsActive returns true if the value is greater than 0
+func (s *memcachedScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		memcachedLog.Error(err, "error getting memcached value")
+		return false, err
+	}
+
+	return value > 0, nil
+}
+
+// Close does nothing, a connection is opened for each request
+func (s *memcachedScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *memcachedScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValue := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value of the stat or of the counter
+func (s *memcachedScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		memcachedLog.Error(err, "error getting memcached value")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,139 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseMemcachedMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type memcachedMetricIdentifier struct {
+	metadataTestData *parseMemcachedMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testMemcachedMetadata = []parseMemcachedMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true},
+	// stat
+	{map[string]string{"address": "localhost:11211", "stat": "curr_items", "targetValue": "1000"}, false},
+	// key with host and default port
+	{map[string]string{"host": "localhost", "key": "warmer:pending", "targetValue": "10"}, false},
+	// missing address
+	{map[string]string{"stat": "curr_items", "targetValue": "1000"}, true},
+	// missing stat and key
+	{map[string]string{"address": "localhost:11211", "targetValue": "1000"}, true},
+	// both stat and key
+	{map[string]string{"address": "localhost:11211", "stat": "curr_items", "key": "warmer:pending", "targetValue": "1000"}, true},
+	// key with whitespace
+	{map[string]string{"address": "localhost:11211", "key": "warmer pending", "targetValue": "1000"}, true},
+	// malformed targetValue
+	{map[string]string{"address": "localhost:11211", "stat": "curr_items", "targetValue": "many"}, true},
+}
+
+var memcachedMetricIdentifiers = []memcachedMetricIdentifier{
+	{&testMemcachedMetadata[1], 0, "s0-memcached-curr_items"},
+	{&testMemcachedMetadata[2], 1, "s1-memcached-warmer-pending"},
+}
+
+func TestMemcachedParseMetadata(t *testing.T) {
+	for _, testData := range testMemcachedMetadata {
+		_, err := parseMemcachedMetadata(&ScalerConfig{TriggerMetadata: testData.metadata})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestMemcachedGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range memcachedMetricIdentifiers {
+		meta, err := parseMemcachedMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockMemcachedScaler := memcachedScaler{metadata: meta}
+
+		metricSpec := mockMemcachedScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+// startMemcachedServer answers the stats and get commands of the text protocol
+func startMemcachedServer(t *testing.T, counters map[string]string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			command, _ := bufio.NewReader(conn).ReadString('\n')
+			fields := strings.Fields(command)
+			switch {
+			case len(fields) == 1 && fields[0] == "stats":
+				fmt.Fprint(conn, "STAT pid 1\r\nSTAT curr_items 42\r\nSTAT rusage_user 0.5\r\nEND\r\n")
+			case len(fields) == 2 && fields[0] == "get":
+				if value, ok := counters[fields[1]]; ok {
+					fmt.Fprintf(conn, "VALUE %s 0 %d\r\n%s\r\n", fields[1], len(value), value)
+				}
+				fmt.Fprint(conn, "END\r\n")
+			default:
+				fmt.Fprint(conn, "ERROR\r\n")
+			}
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestMemcachedGetValue(t *testing.T) {
+	address := startMemcachedServer(t, map[string]string{"warmer:pending": "7", "warmer:name": "text"})
+
+	testData := []struct {
+		metadata memcachedMetadata
+		expected int64
+		isError  bool
+	}{
+		{memcachedMetadata{stat: "curr_items"}, 42, false},
+		{memcachedMetadata{stat: "rusage_user"}, 0, false},
+		{memcachedMetadata{stat: "unknown"}, -1, true},
+		{memcachedMetadata{key: "warmer:pending"}, 7, false},
+		{memcachedMetadata{key: "warmer:missing"}, 0, false},
+		{memcachedMetadata{key: "warmer:name"}, -1, true},
+	}
+	for _, test := range testData {
+		meta := test.metadata
+		meta.address = address
+		scaler := memcachedScaler{metadata: &meta, timeout: time.Second}
+
+		value, err := scaler.getValue(context.TODO())
+		assert.Equal(t, test.expected, value, "%+v", test.metadata)
+		if test.isError {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package scalers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// getSelectorParameters returns the values of the labels of an external metric selector, they parameterize
+// the query of the scaler so one metric can be sliced by several HPAs. Only the given label names with an
+// equality requirement are supported, the parameters are empty without selector.
+func getSelectorParameters(metricSelector labels.Selector, names ...string) (map[string]string, error) {
+	parameters := map[string]string{}
+	if metricSelector == nil || metricSelector.Empty() {
+		return parameters, nil
+	}
+
+	requirements, _ := metricSelector.Requirements()
+	for _, requirement := range requirements {
+		if !contains(names, requirement.Key()) {
+			return nil, fmt.Errorf("metric label %s is not supported, supported labels are: %s", requirement.Key(), strings.Join(names, ", "))
+		}
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			if requirement.Values().Len() == 1 {
+				parameters[requirement.Key()] = requirement.Values().List()[0]
+				continue
+			}
+		}
+		return nil, fmt.Errorf("metric label %s must select a single value", requirement.Key())
+	}
+	return parameters, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
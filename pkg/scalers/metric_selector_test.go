@@ -0,0 +1,45 @@
+package scalers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type getSelectorParametersTestData struct {
+	selector string
+	expected map[string]string
+	isError  bool
+}
+
+var testSelectorParameters = []getSelectorParametersTestData{
+	{"", map[string]string{}, false},
+	{"queueName=orders", map[string]string{"queueName": "orders"}, false},
+	{"queueName==orders,vhost=sales", map[string]string{"queueName": "orders", "vhost": "sales"}, false},
+	{"queueName in (orders)", map[string]string{"queueName": "orders"}, false},
+	{"queueName in (orders,invoices)", nil, true},
+	{"queueName!=orders", nil, true},
+	{"queueName", nil, true},
+	{"listName=orders", nil, true},
+}
+
+func TestGetSelectorParameters(t *testing.T) {
+	for _, test := range testSelectorParameters {
+		selector, err := labels.Parse(test.selector)
+		if err != nil {
+			t.Fatal("Could not parse selector:", err)
+		}
+		parameters, err := getSelectorParameters(selector, "queueName", "vhost")
+		if test.isError {
+			assert.Error(t, err, test.selector)
+			continue
+		}
+		assert.NoError(t, err, test.selector)
+		assert.Equal(t, test.expected, parameters, test.selector)
+	}
+
+	parameters, err := getSelectorParameters(nil, "queueName")
+	assert.NoError(t, err)
+	assert.Empty(t, parameters)
+}
@@ -6,18 +6,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strconv"
 	"strings"
 
 	neturl "net/url"
 
+	"github.com/go-logr/logr"
 	"github.com/tidwall/gjson"
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
@@ -26,10 +25,11 @@ import (
 type metricsAPIScaler struct {
 	metadata *metricsAPIScalerMetadata
 	client   *http.Client
+	logger   logr.Logger
 }
 
 type metricsAPIScalerMetadata struct {
-	targetValue   int
+	targetValue   float64
 	url           string
 	valueLocation string
 
@@ -56,6 +56,12 @@ type metricsAPIScalerMetadata struct {
 	enableBearerAuth bool
 	bearerToken      string
 
+	// oauth2, the token of the client credentials is sent as bearer token
+	oauth2 *authentication.OAuth2ClientCredentials
+
+	// missingValue is how a missing or null valueLocation is handled, by default it is an error
+	missingValue *missingValuePolicy
+
 	scalerIndex int
 }
 
@@ -63,8 +69,6 @@ const (
 	methodValueQuery = "query"
 )
 
-var httpLog = logf.Log.WithName("metrics_api_scaler")
-
 // NewMetricsAPIScaler creates a new HTTP scaler
 func NewMetricsAPIScaler(config *ScalerConfig) (Scaler, error) {
 	meta, err := parseMetricsAPIMetadata(config)
@@ -72,20 +76,24 @@ func NewMetricsAPIScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing metric API metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
 
 	if meta.enableTLS || len(meta.ca) > 0 {
-		config, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
+		tlsConfig, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
 		if err != nil {
 			return nil, err
 		}
 
-		httpClient.Transport = &http.Transport{TLSClientConfig: config}
+		httpClient = createTLSHTTPClient(config, config.GlobalHTTPTimeout, tlsConfig)
+	}
+	if meta.oauth2 != nil {
+		httpClient.Transport = meta.oauth2.Transport(httpClient.Transport)
 	}
 
 	return &metricsAPIScaler{
 		metadata: meta,
 		client:   httpClient,
+		logger:   InitializeLogger(config, "metrics_api_scaler"),
 	}, nil
 }
 
@@ -93,15 +101,11 @@ func parseMetricsAPIMetadata(config *ScalerConfig) (*metricsAPIScalerMetadata, e
 	meta := metricsAPIScalerMetadata{}
 	meta.scalerIndex = config.ScalerIndex
 
-	if val, ok := config.TriggerMetadata["targetValue"]; ok {
-		targetValue, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
-		}
-		meta.targetValue = targetValue
-	} else {
-		return nil, fmt.Errorf("no targetValue given in metadata")
+	targetValue, err := getFloatMetadataValue(config.TriggerMetadata, "targetValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.targetValue = targetValue
 
 	if val, ok := config.TriggerMetadata["url"]; ok {
 		meta.url = val
@@ -114,6 +118,15 @@ func parseMetricsAPIMetadata(config *ScalerConfig) (*metricsAPIScalerMetadata, e
 	} else {
 		return nil, fmt.Errorf("no valueLocation given in metadata")
 	}
+	if err := validateValueLocation(meta.valueLocation, config.TriggerMetadata); err != nil {
+		return nil, err
+	}
+
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.missingValue = missingValue
 
 	authMode, ok := config.TriggerMetadata["authMode"]
 	// no authMode specified
@@ -173,6 +186,12 @@ func parseMetricsAPIMetadata(config *ScalerConfig) (*metricsAPIScalerMetadata, e
 
 		meta.bearerToken = config.AuthParams["token"]
 		meta.enableBearerAuth = true
+	case authentication.OAuth2AuthType:
+		oauth2, err := authentication.ParseOAuth2ClientCredentials(config.AuthParams)
+		if err != nil {
+			return nil, err
+		}
+		meta.oauth2 = oauth2
 	default:
 		return nil, fmt.Errorf("err incorrect value for authMode is given: %s", authMode)
 	}
@@ -197,7 +216,7 @@ func GetValueFromResponse(body []byte, valueLocation string) (*resource.Quantity
 	if r.Type != gjson.Number {
 		return nil, fmt.Errorf(errorMsg, r.Type.String())
 	}
-	return resource.NewQuantity(int64(r.Num), resource.DecimalSI), nil
+	return newMilliQuantity(r.Num), nil
 }
 
 func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (*resource.Quantity, error) {
@@ -222,6 +241,17 @@ func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (*resource.Quanti
 		return nil, err
 	}
 	v, err := GetValueFromResponse(b, s.metadata.valueLocation)
+	if s.metadata.missingValue != nil && (err == nil || gjson.GetBytes(b, s.metadata.valueLocation).Type == gjson.Null) {
+		var value float64
+		if v != nil {
+			value = v.AsApproximateFloat64()
+		}
+		resolved, err := s.metadata.missingValue.resolve(value, err == nil, err)
+		if err != nil {
+			return nil, err
+		}
+		return newMilliQuantity(resolved), nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -230,14 +260,25 @@ func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (*resource.Quanti
 
 // Close does nothing in case of metricsAPIScaler
 func (s *metricsAPIScaler) Close(context.Context) error {
+	closeIdleConnections(s.client)
 	return nil
 }
 
+// State returns the last value of the query kept for valueIfNull: lastValue
+func (s *metricsAPIScaler) State() ([]byte, error) {
+	return missingValueState(s.metadata.missingValue)
+}
+
+// RestoreState restores the last value of the query
+func (s *metricsAPIScaler) RestoreState(state []byte) error {
+	return restoreMissingValueState(state, s.metadata.missingValue)
+}
+
 // IsActive returns true if there are pending messages to be processed
 func (s *metricsAPIScaler) IsActive(ctx context.Context) (bool, error) {
 	v, err := s.getMetricValue(ctx)
 	if err != nil {
-		httpLog.Error(err, fmt.Sprintf("Error when checking metric value: %s", err))
+		s.logger.Error(err, "Error when checking metric value")
 		return false, err
 	}
 
@@ -246,15 +287,11 @@ func (s *metricsAPIScaler) IsActive(ctx context.Context) (bool, error) {
 
 // GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
 func (s *metricsAPIScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetValue := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("metric-api-%s", s.metadata.valueLocation))),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.targetValue),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -27,6 +27,12 @@ var testMetricsAPIMetadata = []metricsAPIMetadataTestData{
 	{metadata: map[string]string{"valueLocation": "metric", "targetValue": "aa"}, raisesError: true},
 	// Missing targetValue
 	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric"}, raisesError: true},
+	// Invalid valueLocation
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric..test", "targetValue": "42"}, raisesError: true},
+	// valueLocation read from sampleResponse
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric.test", "targetValue": "42", "sampleResponse": `{"metric": {"test": 3}}`}, raisesError: false},
+	// valueLocation not pointing to a number of sampleResponse
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric", "targetValue": "42", "sampleResponse": `{"metric": {"test": 3}}`}, raisesError: true},
 }
 
 type metricAPIAuthMetadataTestData struct {
@@ -62,6 +68,10 @@ var testMetricsAPIAuthMetadata = []metricAPIAuthMetadataTestData{
 	{map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric", "targetValue": "42", "authMode": "bearer"}, map[string]string{"token": "bearerTokenValue"}, false},
 	// fail bearerAuth without token
 	{map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric", "targetValue": "42", "authMode": "bearer"}, map[string]string{}, true},
+	// success oauth2
+	{map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric", "targetValue": "42", "authMode": "oauth2"}, map[string]string{"tokenURL": "http://idp/token", "clientID": "keda", "clientSecret": "secret", "scopes": "metrics.read"}, false},
+	// fail oauth2 with invalid tokenURL
+	{map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric", "targetValue": "42", "authMode": "oauth2"}, map[string]string{"tokenURL": "idp", "clientID": "keda", "clientSecret": "secret"}, true},
 }
 
 func TestParseMetricsAPIMetadata(t *testing.T) {
@@ -123,8 +133,8 @@ func TestGetValueFromResponse(t *testing.T) {
 	if err != nil {
 		t.Error("Expected success but got error", err)
 	}
-	if v.CmpInt64(2) != 0 {
-		t.Errorf("Expected %d got %d", 2, v.AsDec())
+	if v.MilliValue() != 2430 {
+		t.Errorf("Expected %d got %d", 2430, v.MilliValue())
 	}
 
 	v, err = GetValueFromResponse(d, "components.0.str")
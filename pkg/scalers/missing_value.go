@@ -0,0 +1,154 @@
+package scalers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+const (
+	valueIfNullLastValue = "lastValue"
+)
+
+var (
+	// errNoQueryResult is returned by the queries without result, they are missing values
+	errNoQueryResult = errors.New("no results found from query")
+	// errSQLNullResult is the error of the sql scalers for the NULL results
+	errSQLNullResult = errors.New("the query returned NULL")
+)
+
+// missingValuePolicy is how a scaler treats a query returning no result or a null one, without
+// the valueIfNull and errorWhenNoResult metadata each scaler keeps its own behavior
+type missingValuePolicy struct {
+	// errorWhenNoResult fails the query
+	errorWhenNoResult bool
+	// valueIfNull is returned instead of the missing value, useLastValue returns the last value of the query
+	valueIfNull  float64
+	useLastValue bool
+
+	lock      sync.Mutex
+	lastValue *float64
+}
+
+// parseMissingValuePolicy parses the valueIfNull and errorWhenNoResult metadata, the policy is nil when they are not set
+func parseMissingValuePolicy(metadata map[string]string) (*missingValuePolicy, error) {
+	valueIfNull := metadata["valueIfNull"]
+	policy := &missingValuePolicy{}
+	if val := metadata["errorWhenNoResult"]; val != "" {
+		errorWhenNoResult, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing errorWhenNoResult: %s", err)
+		}
+		policy.errorWhenNoResult = errorWhenNoResult
+	}
+
+	switch {
+	case valueIfNull == "":
+		if !policy.errorWhenNoResult {
+			// errorWhenNoResult: false keeps the behavior of the scaler
+			return nil, nil
+		}
+	case policy.errorWhenNoResult:
+		return nil, fmt.Errorf("valueIfNull can't be given with errorWhenNoResult")
+	case valueIfNull == valueIfNullLastValue:
+		policy.useLastValue = true
+	default:
+		value, err := strconv.ParseFloat(valueIfNull, 64)
+		if err != nil {
+			return nil, fmt.Errorf("valueIfNull must be a number or %s: %s", valueIfNullLastValue, err)
+		}
+		policy.valueIfNull = value
+	}
+	return policy, nil
+}
+
+// resolve returns the value of a query, found is false when the query returned no value or a null one,
+// noResultErr is the error of the scaler for missing values, when nil they are read as 0.
+// It is safe to call with a nil policy.
+func (p *missingValuePolicy) resolve(value float64, found bool, noResultErr error) (float64, error) {
+	if p == nil {
+		if !found {
+			return 0, noResultErr
+		}
+		return value, nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if found {
+		p.lastValue = &value
+		return value, nil
+	}
+
+	switch {
+	case p.errorWhenNoResult:
+		if noResultErr == nil {
+			noResultErr = fmt.Errorf("the query returned no result")
+		}
+		return 0, noResultErr
+	case p.useLastValue:
+		if p.lastValue == nil {
+			// nothing has been read yet
+			return 0, noResultErr
+		}
+		return *p.lastValue, nil
+	default:
+		return p.valueIfNull, nil
+	}
+}
+
+// resolveQueryResult applies the policy to the result of a query failing with errNoQueryResult when it has no result
+func (p *missingValuePolicy) resolveQueryResult(value float64, err error) (float64, error) {
+	switch {
+	case errors.Is(err, errNoQueryResult):
+		return p.resolve(0, false, err)
+	case err != nil:
+		return value, err
+	default:
+		return p.resolve(value, true, nil)
+	}
+}
+
+// missingValueState returns the last values of the policies, nil when none has been read yet. The policies can be nil
+func missingValueState(policies ...*missingValuePolicy) ([]byte, error) {
+	lastValues := make([]*float64, len(policies))
+	found := false
+	for i, p := range policies {
+		if p == nil {
+			continue
+		}
+		p.lock.Lock()
+		lastValues[i] = p.lastValue
+		p.lock.Unlock()
+		found = found || lastValues[i] != nil
+	}
+	if !found {
+		return nil, nil
+	}
+	return json.Marshal(lastValues)
+}
+
+// restoreMissingValueState restores the last values returned by missingValueState, the values of the policies that
+// are not there anymore are dropped
+func restoreMissingValueState(state []byte, policies ...*missingValuePolicy) error {
+	if len(state) == 0 {
+		return nil
+	}
+	var lastValues []*float64
+	if err := json.Unmarshal(state, &lastValues); err != nil {
+		return fmt.Errorf("error parsing the last values: %s", err)
+	}
+	for i, p := range policies {
+		if p == nil || i >= len(lastValues) || lastValues[i] == nil {
+			continue
+		}
+		p.lock.Lock()
+		value := *lastValues[i]
+		p.lastValue = &value
+		p.lock.Unlock()
+	}
+	return nil
+}
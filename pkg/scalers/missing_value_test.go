@@ -0,0 +1,139 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseMissingValuePolicyTestData struct {
+	metadata map[string]string
+	expected *missingValuePolicy
+	isError  bool
+}
+
+var testMissingValuePolicies = []parseMissingValuePolicyTestData{
+	{map[string]string{}, nil, false},
+	{map[string]string{"errorWhenNoResult": "false"}, nil, false},
+	{map[string]string{"errorWhenNoResult": "true"}, &missingValuePolicy{errorWhenNoResult: true}, false},
+	{map[string]string{"valueIfNull": "2.5"}, &missingValuePolicy{valueIfNull: 2.5}, false},
+	{map[string]string{"valueIfNull": "0", "errorWhenNoResult": "false"}, &missingValuePolicy{}, false},
+	{map[string]string{"valueIfNull": "lastValue"}, &missingValuePolicy{useLastValue: true}, false},
+	{map[string]string{"valueIfNull": "zero"}, nil, true},
+	{map[string]string{"errorWhenNoResult": "sometimes"}, nil, true},
+	{map[string]string{"valueIfNull": "0", "errorWhenNoResult": "true"}, nil, true},
+}
+
+func TestParseMissingValuePolicy(t *testing.T) {
+	for _, test := range testMissingValuePolicies {
+		policy, err := parseMissingValuePolicy(test.metadata)
+		if test.isError {
+			assert.Error(t, err, test.metadata)
+			continue
+		}
+		assert.NoError(t, err, test.metadata)
+		assert.Equal(t, test.expected, policy, test.metadata)
+	}
+}
+
+func TestMissingValuePolicyResolve(t *testing.T) {
+	errScaler := errors.New("scaler error")
+
+	// without policy each scaler keeps its behavior
+	var policy *missingValuePolicy
+	value, err := policy.resolve(3, true, errScaler)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, value)
+	_, err = policy.resolve(0, false, errScaler)
+	assert.Equal(t, errScaler, err)
+	value, err = policy.resolve(0, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, value)
+
+	policy = &missingValuePolicy{valueIfNull: 7}
+	value, err = policy.resolve(0, false, errScaler)
+	assert.NoError(t, err)
+	assert.Equal(t, 7.0, value)
+
+	policy = &missingValuePolicy{errorWhenNoResult: true}
+	_, err = policy.resolve(0, false, nil)
+	assert.Error(t, err)
+
+	policy = &missingValuePolicy{useLastValue: true}
+	_, err = policy.resolve(0, false, errScaler)
+	assert.Equal(t, errScaler, err)
+	_, _ = policy.resolve(4, true, nil)
+	value, err = policy.resolve(0, false, errScaler)
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, value)
+
+	value, err = policy.resolveQueryResult(0, fmt.Errorf("influxdb: %w", errNoQueryResult))
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, value)
+	_, err = policy.resolveQueryResult(0, errScaler)
+	assert.Equal(t, errScaler, err)
+}
+
+func TestPrometheusMissingValue(t *testing.T) {
+	response := `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1638000000, "12"]}]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	newScaler := func(metadata map[string]string) *prometheusScaler {
+		metadata["serverAddress"] = server.URL
+		metadata["metricName"] = "jobs"
+		metadata["query"] = "sum(jobs)"
+		metadata["threshold"] = "10"
+		scaler, err := NewPrometheusScaler(&ScalerConfig{TriggerMetadata: metadata})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		return scaler.(*prometheusScaler)
+	}
+	defaultScaler := newScaler(map[string]string{})
+	errorScaler := newScaler(map[string]string{"errorWhenNoResult": "true"})
+	lastValueScaler := newScaler(map[string]string{"valueIfNull": "lastValue"})
+
+	value, timestamp, err := lastValueScaler.executePromQuery(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 12.0, value)
+	assert.Equal(t, int64(1638000000), timestamp.Unix())
+
+	response = `{"status": "success", "data": {"resultType": "vector", "result": []}}`
+	value, err = defaultScaler.ExecutePromQuery(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, value)
+
+	_, err = errorScaler.ExecutePromQuery(context.Background())
+	assert.Error(t, err)
+
+	value, err = lastValueScaler.ExecutePromQuery(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 12.0, value)
+}
+
+func TestMissingValueState(t *testing.T) {
+	policy, _ := parseMissingValuePolicy(map[string]string{"valueIfNull": "lastValue"})
+	state, err := missingValueState(policy, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	_, _ = policy.resolve(12, true, nil)
+	state, err = missingValueState(policy, nil)
+	assert.NoError(t, err)
+
+	restored, _ := parseMissingValuePolicy(map[string]string{"valueIfNull": "lastValue"})
+	assert.NoError(t, restoreMissingValueState(state, restored, nil))
+	value, err := restored.resolve(0, false, errNoQueryResult)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.0, value)
+
+	assert.Error(t, restoreMissingValueState([]byte("12"), restored))
+}
@@ -4,20 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
+	"github.com/go-logr/logr"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 	"k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -26,6 +23,7 @@ import (
 type mongoDBScaler struct {
 	metadata *mongoDBMetadata
 	client   *mongo.Client
+	logger   logr.Logger
 }
 
 // mongoDBMetadata specify mongoDB scaler params.
@@ -57,7 +55,7 @@ type mongoDBMetadata struct {
 	query string
 	// A threshold that is used as targetAverageValue in HPA
 	// +required
-	queryValue int
+	queryValue float64
 	// The name of the metric to use in the Horizontal Pod Autoscaler. This value will be prefixed with "mongodb-".
 	// +optional
 	metricName string
@@ -72,8 +70,6 @@ const (
 	mongoDBDefaultTimeOut = 10 * time.Second
 )
 
-var mongoDBLog = logf.Log.WithName("mongodb_scaler")
-
 // NewMongoDBScaler creates a new mongoDB scaler
 func NewMongoDBScaler(ctx context.Context, config *ScalerConfig) (Scaler, error) {
 	ctx, cancel := context.WithTimeout(ctx, mongoDBDefaultTimeOut)
@@ -97,6 +93,7 @@ func NewMongoDBScaler(ctx context.Context, config *ScalerConfig) (Scaler, error)
 	return &mongoDBScaler{
 		metadata: meta,
 		client:   client,
+		logger:   InitializeLogger(config, "mongodb_scaler"),
 	}, nil
 }
 
@@ -119,15 +116,11 @@ func parseMongoDBMetadata(config *ScalerConfig) (*mongoDBMetadata, string, error
 		return nil, "", fmt.Errorf("no query given")
 	}
 
-	if val, ok := config.TriggerMetadata["queryValue"]; ok {
-		queryValue, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to convert %v to int, because of %v", queryValue, err.Error())
-		}
-		meta.queryValue = queryValue
-	} else {
-		return nil, "", fmt.Errorf("no queryValue given")
+	queryValue, err := getFloatMetadataValue(config.TriggerMetadata, "queryValue", true, 0)
+	if err != nil {
+		return nil, "", err
 	}
+	meta.queryValue = queryValue
 
 	meta.dbName, err = GetFromAuthOrMeta(config, "dbName")
 	if err != nil {
@@ -188,7 +181,7 @@ func parseMongoDBMetadata(config *ScalerConfig) (*mongoDBMetadata, string, error
 func (s *mongoDBScaler) IsActive(ctx context.Context) (bool, error) {
 	result, err := s.getQueryResult(ctx)
 	if err != nil {
-		mongoDBLog.Error(err, fmt.Sprintf("failed to get query result by mongoDB, because of %v", err))
+		s.logger.Error(err, "Failed to get query result by mongoDB")
 		return false, err
 	}
 	return result > 0, nil
@@ -199,7 +192,7 @@ func (s *mongoDBScaler) Close(ctx context.Context) error {
 	if s.client != nil {
 		err := s.client.Disconnect(ctx)
 		if err != nil {
-			mongoDBLog.Error(err, fmt.Sprintf("failed to close mongoDB connection, because of %v", err))
+			s.logger.Error(err, "Failed to close mongoDB connection")
 			return err
 		}
 	}
@@ -214,13 +207,13 @@ func (s *mongoDBScaler) getQueryResult(ctx context.Context) (int, error) {
 
 	filter, err := json2BsonDoc(s.metadata.query)
 	if err != nil {
-		mongoDBLog.Error(err, fmt.Sprintf("failed to convert query param to bson.Doc, because of %v", err))
+		s.logger.Error(err, "Failed to convert query param to bson.Doc")
 		return 0, err
 	}
 
 	docsNum, err := s.client.Database(s.metadata.dbName).Collection(s.metadata.collection).CountDocuments(ctx, filter)
 	if err != nil {
-		mongoDBLog.Error(err, fmt.Sprintf("failed to query %v in %v, because of %v", s.metadata.dbName, s.metadata.collection, err))
+		s.logger.Error(err, "Failed to query the collection", "dbName", s.metadata.dbName, "collection", s.metadata.collection)
 		return 0, err
 	}
 
@@ -234,27 +227,18 @@ func (s *mongoDBScaler) GetMetrics(ctx context.Context, metricName string, metri
 		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("failed to inspect momgoDB, because of %v", err)
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(num), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, float64(num))
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
 // GetMetricSpecForScaling get the query value for scaling
 func (s *mongoDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetQueryValue := resource.NewQuantity(int64(s.metadata.queryValue), resource.DecimalSI)
-
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetQueryValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.queryValue),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
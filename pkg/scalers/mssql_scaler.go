@@ -7,22 +7,21 @@ import (
 	"net/url"
 	"strconv"
 
-	// mssql driver required for this scaler
-	_ "github.com/denisenkom/go-mssqldb"
+	"github.com/go-logr/logr"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
+	// mssql driver required for this scaler, with its Kerberos authentication
+	_ "github.com/microsoft/go-mssqldb"
+	_ "github.com/microsoft/go-mssqldb/integratedauth/krb5"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // mssqlScaler exposes a data pointer to mssqlMetadata and sql.DB connection
 type mssqlScaler struct {
 	metadata   *mssqlMetadata
 	connection *sql.DB
+	logger     logr.Logger
 }
 
 // mssqlMetadata defines metadata used by KEDA to query a Microsoft SQL database
@@ -46,30 +45,59 @@ type mssqlMetadata struct {
 	// The name of the database to query, if not specified in the connection string.
 	// +optional
 	database string
+	// The Kerberos authentication of the username, if not specified in the connection string.
+	// +optional
+	kerberos *mssqlKerberosMetadata
 	// The T-SQL query to run against the target database - e.g. SELECT COUNT(*) FROM table.
 	// +required
 	query string
 	// The threshold that is used as targetAverageValue in the Horizontal Pod Autoscaler.
 	// +required
-	targetValue int
+	targetValue float64
 	// The name of the metric to use in the Horizontal Pod Autoscaler. This value will be prefixed with "mssql-".
 	// +optional
 	metricName string
+	// How the NULL results and the queries without rows are handled, they are read as 0 by default.
+	// +optional
+	missingValue *missingValuePolicy
 	// The index of the scaler inside the ScaledObject
 	// +internal
 	scalerIndex int
 }
 
-var mssqlLog = logf.Log.WithName("mssql_scaler")
+// mssqlKerberosMetadata defines the Kerberos authentication of the username, the files are mounted in the operator
+type mssqlKerberosMetadata struct {
+	// The realm of the username.
+	// +required
+	realm string
+	// The keytab of the username, it requires the realm.
+	// +optional
+	keytabPath string
+	// The credential cache of the username, eg. kept up to date by a kinit sidecar.
+	// +optional
+	credentialCachePath string
+	// The krb5.conf, defaults to /etc/krb5.conf.
+	// +optional
+	configPath string
+	// The SPN of the MSSQL instance, defaults to MSSQLSvc/host:port@realm.
+	// +optional
+	serverSPN string
+}
+
+const (
+	mssqlAuthModeKerberos = "kerberos"
+	mssqlDefaultPort      = 1433
+)
 
 // NewMSSQLScaler creates a new mssql scaler
 func NewMSSQLScaler(config *ScalerConfig) (Scaler, error) {
+	logger := InitializeLogger(config, "mssql_scaler")
 	meta, err := parseMSSQLMetadata(config)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing mssql metadata: %s", err)
 	}
 
-	conn, err := newMSSQLConnection(meta)
+	conn, err := newMSSQLConnection(meta, logger)
 	if err != nil {
 		return nil, fmt.Errorf("error establishing mssql connection: %s", err)
 	}
@@ -77,6 +105,7 @@ func NewMSSQLScaler(config *ScalerConfig) (Scaler, error) {
 	return &mssqlScaler{
 		metadata:   meta,
 		connection: conn,
+		logger:     logger,
 	}, nil
 }
 
@@ -92,15 +121,11 @@ func parseMSSQLMetadata(config *ScalerConfig) (*mssqlMetadata, error) {
 	}
 
 	// Target query value
-	if val, ok := config.TriggerMetadata["targetValue"]; ok {
-		targetValue, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
-		}
-		meta.targetValue = targetValue
-	} else {
-		return nil, fmt.Errorf("no targetValue given")
+	targetValue, err := getFloatMetadataValue(config.TriggerMetadata, "targetValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.targetValue = targetValue
 
 	// Connection string, which can either be provided explicitly or via the helper fields
 	switch {
@@ -137,6 +162,19 @@ func parseMSSQLMetadata(config *ScalerConfig) (*mssqlMetadata, error) {
 		} else if config.TriggerMetadata["passwordFromEnv"] != "" {
 			meta.password = config.ResolvedEnv[config.TriggerMetadata["passwordFromEnv"]]
 		}
+
+		authMode, _ := GetFromAuthOrMeta(config, "authMode")
+		switch authMode {
+		case "":
+		case mssqlAuthModeKerberos:
+			kerberos, err := parseMSSQLKerberosMetadata(config)
+			if err != nil {
+				return nil, err
+			}
+			meta.kerberos = kerberos
+		default:
+			return nil, fmt.Errorf("authMode %s is not supported", authMode)
+		}
 	}
 
 	// get the metricName, which can be explicit or from the (masked) connection string
@@ -152,23 +190,50 @@ func parseMSSQLMetadata(config *ScalerConfig) (*mssqlMetadata, error) {
 			meta.metricName = "mssql"
 		}
 	}
+
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.missingValue = missingValue
+
 	meta.scalerIndex = config.ScalerIndex
 	return &meta, nil
 }
 
+// parseMSSQLKerberosMetadata parses the Kerberos settings, a keytab or a credential cache authenticates the username
+func parseMSSQLKerberosMetadata(config *ScalerConfig) (*mssqlKerberosMetadata, error) {
+	kerberos := mssqlKerberosMetadata{configPath: defaultKerberosConfigPath}
+
+	var err error
+	if kerberos.realm, err = GetFromAuthOrMeta(config, "realm"); err != nil {
+		return nil, err
+	}
+	kerberos.keytabPath = config.AuthParams["keytab"]
+	kerberos.credentialCachePath = config.AuthParams["credentialCache"]
+	if kerberos.keytabPath == "" && kerberos.credentialCachePath == "" {
+		return nil, fmt.Errorf("no keytab or credentialCache given")
+	}
+	if val := config.AuthParams["kerberosConfig"]; val != "" {
+		kerberos.configPath = val
+	}
+	kerberos.serverSPN, _ = GetFromAuthOrMeta(config, "serverSPN")
+	return &kerberos, nil
+}
+
 // newMSSQLConnection returns a new, opened SQL connection for the provided mssqlMetadata
-func newMSSQLConnection(meta *mssqlMetadata) (*sql.DB, error) {
+func newMSSQLConnection(meta *mssqlMetadata, logger logr.Logger) (*sql.DB, error) {
 	connStr := getMSSQLConnectionString(meta)
 
 	db, err := sql.Open("sqlserver", connStr)
 	if err != nil {
-		mssqlLog.Error(err, fmt.Sprintf("Found error opening mssql: %s", err))
+		logger.Error(err, "Found error opening mssql")
 		return nil, err
 	}
 
 	err = db.Ping()
 	if err != nil {
-		mssqlLog.Error(err, fmt.Sprintf("Found error pinging mssql: %s", err))
+		logger.Error(err, "Found error pinging mssql")
 		return nil, err
 	}
 
@@ -186,6 +251,25 @@ func getMSSQLConnectionString(meta *mssqlMetadata) string {
 		if meta.database != "" {
 			query.Add("database", meta.database)
 		}
+		if meta.kerberos != nil {
+			port := meta.port
+			if port == 0 {
+				port = mssqlDefaultPort
+			}
+			query.Add("authenticator", "krb5")
+			query.Add("krb5conffile", meta.kerberos.configPath)
+			query.Add("realm", meta.kerberos.realm)
+			if meta.kerberos.keytabPath != "" {
+				query.Add("keytabfile", meta.kerberos.keytabPath)
+			} else {
+				query.Add("krbcache", meta.kerberos.credentialCachePath)
+			}
+			serverSPN := meta.kerberos.serverSPN
+			if serverSPN == "" {
+				serverSPN = fmt.Sprintf("MSSQLSvc/%s:%d@%s", meta.host, port, meta.kerberos.realm)
+			}
+			query.Add("serverspn", serverSPN)
+		}
 
 		connectionURL := &url.URL{Scheme: "sqlserver", RawQuery: query.Encode()}
 		if meta.username != "" {
@@ -210,15 +294,11 @@ func getMSSQLConnectionString(meta *mssqlMetadata) string {
 
 // GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
 func (s *mssqlScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetQueryValue := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetQueryValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.targetValue),
 	}
 
 	metricSpec := v2beta2.MetricSpec{
@@ -235,28 +315,27 @@ func (s *mssqlScaler) GetMetrics(ctx context.Context, metricName string, metricS
 		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting mssql: %s", err)
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(num), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, num)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
 // getQueryResult returns the result of the scaler query
-func (s *mssqlScaler) getQueryResult(ctx context.Context) (int, error) {
-	var value int
+func (s *mssqlScaler) getQueryResult(ctx context.Context) (float64, error) {
+	var value sql.NullFloat64
 	err := s.connection.QueryRowContext(ctx, s.metadata.query).Scan(&value)
 	switch {
 	case err == sql.ErrNoRows:
-		value = 0
+		// no rows is read as 0 by default
+		err = nil
 	case err != nil:
-		mssqlLog.Error(err, fmt.Sprintf("Could not query mssql database: %s", err))
+		s.logger.Error(err, "Could not query mssql database")
 		return 0, err
+	default:
+		err = errSQLNullResult
 	}
 
-	return value, nil
+	return s.metadata.missingValue.resolve(value.Float64, value.Valid, err)
 }
 
 // IsActive returns true if there are pending events to be processed
@@ -273,7 +352,7 @@ func (s *mssqlScaler) IsActive(ctx context.Context) (bool, error) {
 func (s *mssqlScaler) Close(context.Context) error {
 	err := s.connection.Close()
 	if err != nil {
-		mssqlLog.Error(err, "Error closing mssql connection")
+		s.logger.Error(err, "Error closing mssql connection")
 		return err
 	}
 
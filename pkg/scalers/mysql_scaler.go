@@ -4,16 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
 	"strings"
 
+	"github.com/go-logr/logr"
 	"github.com/go-sql-driver/mysql"
 	"k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -21,101 +18,70 @@ import (
 type mySQLScaler struct {
 	metadata   *mySQLMetadata
 	connection *sql.DB
+	logger     logr.Logger
 }
 
 type mySQLMetadata struct {
-	connectionString string // Database connection string
-	username         string
-	password         string
-	host             string
-	port             string
-	dbName           string
-	query            string
-	queryValue       int
+	ConnectionString string  `keda:"name=connectionString, order=authParams;resolvedEnv, optional"` // Database connection string
+	Username         string  `keda:"name=username, order=authParams;triggerMetadata, optional"`
+	Password         string  `keda:"name=password, order=authParams;resolvedEnv, optional"`
+	Host             string  `keda:"name=host, order=authParams;triggerMetadata, optional"`
+	Port             string  `keda:"name=port, order=authParams;triggerMetadata, optional"`
+	DBName           string  `keda:"name=dbName, order=authParams;triggerMetadata, optional"`
+	Query            string  `keda:"name=query"`
+	QueryValue       float64 `keda:"name=queryValue"`
 	metricName       string
+	missingValue     *missingValuePolicy
 }
 
-var mySQLLog = logf.Log.WithName("mysql_scaler")
+// Validate checks the connection parameters are given when the connection string isn't
+func (m *mySQLMetadata) Validate() error {
+	if m.ConnectionString != "" {
+		return nil
+	}
+	for _, param := range []struct{ name, value string }{{"host", m.Host}, {"port", m.Port}, {"username", m.Username}, {"dbName", m.DBName}, {"password", m.Password}} {
+		if param.value == "" {
+			return fmt.Errorf("no %s given", param.name)
+		}
+	}
+	return nil
+}
 
 // NewMySQLScaler creates a new MySQL scaler
 func NewMySQLScaler(config *ScalerConfig) (Scaler, error) {
+	logger := InitializeLogger(config, "mysql_scaler")
 	meta, err := parseMySQLMetadata(config)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing MySQL metadata: %s", err)
 	}
 
-	conn, err := newMySQLConnection(meta)
+	conn, err := newMySQLConnection(meta, logger)
 	if err != nil {
 		return nil, fmt.Errorf("error establishing MySQL connection: %s", err)
 	}
 	return &mySQLScaler{
 		metadata:   meta,
 		connection: conn,
+		logger:     logger,
 	}, nil
 }
 
 func parseMySQLMetadata(config *ScalerConfig) (*mySQLMetadata, error) {
 	meta := mySQLMetadata{}
-
-	if val, ok := config.TriggerMetadata["query"]; ok {
-		meta.query = val
-	} else {
-		return nil, fmt.Errorf("no query given")
-	}
-
-	if val, ok := config.TriggerMetadata["queryValue"]; ok {
-		queryValue, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("queryValue parsing error %s", err.Error())
-		}
-		meta.queryValue = queryValue
-	} else {
-		return nil, fmt.Errorf("no queryValue given")
+	if err := config.TypedConfig(&meta); err != nil {
+		return nil, err
 	}
 
-	switch {
-	case config.AuthParams["connectionString"] != "":
-		meta.connectionString = config.AuthParams["connectionString"]
-	case config.TriggerMetadata["connectionStringFromEnv"] != "":
-		meta.connectionString = config.ResolvedEnv[config.TriggerMetadata["connectionStringFromEnv"]]
-	default:
-		meta.connectionString = ""
-		var err error
-		meta.host, err = GetFromAuthOrMeta(config, "host")
-		if err != nil {
-			return nil, err
-		}
-
-		meta.port, err = GetFromAuthOrMeta(config, "port")
-		if err != nil {
-			return nil, err
-		}
-
-		meta.username, err = GetFromAuthOrMeta(config, "username")
-		if err != nil {
-			return nil, err
-		}
-
-		meta.dbName, err = GetFromAuthOrMeta(config, "dbName")
-		if err != nil {
-			return nil, err
-		}
-
-		if config.AuthParams["password"] != "" {
-			meta.password = config.AuthParams["password"]
-		} else if config.TriggerMetadata["passwordFromEnv"] != "" {
-			meta.password = config.ResolvedEnv[config.TriggerMetadata["passwordFromEnv"]]
-		}
-
-		if len(meta.password) == 0 {
-			return nil, fmt.Errorf("no password given")
-		}
+	if meta.ConnectionString != "" {
+		meta.DBName = parseMySQLDbNameFromConnectionStr(meta.ConnectionString)
 	}
+	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("mysql-%s", meta.DBName)))
 
-	if meta.connectionString != "" {
-		meta.dbName = parseMySQLDbNameFromConnectionStr(meta.connectionString)
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
 	}
-	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("mysql-%s", meta.dbName)))
+	meta.missingValue = missingValue
 
 	return &meta, nil
 }
@@ -124,15 +90,15 @@ func parseMySQLMetadata(config *ScalerConfig) (*mySQLMetadata, error) {
 func metadataToConnectionStr(meta *mySQLMetadata) string {
 	var connStr string
 
-	if meta.connectionString != "" {
-		connStr = meta.connectionString
+	if meta.ConnectionString != "" {
+		connStr = meta.ConnectionString
 	} else {
 		// Build connection str
 		config := mysql.NewConfig()
-		config.Addr = fmt.Sprintf("%s:%s", meta.host, meta.port)
-		config.DBName = meta.dbName
-		config.Passwd = meta.password
-		config.User = meta.username
+		config.Addr = fmt.Sprintf("%s:%s", meta.Host, meta.Port)
+		config.DBName = meta.DBName
+		config.Passwd = meta.Password
+		config.User = meta.Username
 		config.Net = "tcp"
 		connStr = config.FormatDSN()
 	}
@@ -140,16 +106,16 @@ func metadataToConnectionStr(meta *mySQLMetadata) string {
 }
 
 // newMySQLConnection creates MySQL db connection
-func newMySQLConnection(meta *mySQLMetadata) (*sql.DB, error) {
+func newMySQLConnection(meta *mySQLMetadata, logger logr.Logger) (*sql.DB, error) {
 	connStr := metadataToConnectionStr(meta)
 	db, err := sql.Open("mysql", connStr)
 	if err != nil {
-		mySQLLog.Error(err, fmt.Sprintf("Found error when opening connection: %s", err))
+		logger.Error(err, "Found error when opening connection")
 		return nil, err
 	}
 	err = db.Ping()
 	if err != nil {
-		mySQLLog.Error(err, fmt.Sprintf("Found error when pinging database: %s", err))
+		logger.Error(err, "Found error when pinging database")
 		return nil, err
 	}
 	return db, nil
@@ -170,7 +136,7 @@ func parseMySQLDbNameFromConnectionStr(connectionString string) string {
 func (s *mySQLScaler) Close(context.Context) error {
 	err := s.connection.Close()
 	if err != nil {
-		mySQLLog.Error(err, "Error closing MySQL connection")
+		s.logger.Error(err, "Error closing MySQL connection")
 		return err
 	}
 	return nil
@@ -180,35 +146,35 @@ func (s *mySQLScaler) Close(context.Context) error {
 func (s *mySQLScaler) IsActive(ctx context.Context) (bool, error) {
 	messages, err := s.getQueryResult(ctx)
 	if err != nil {
-		mySQLLog.Error(err, fmt.Sprintf("Error inspecting MySQL: %s", err))
+		s.logger.Error(err, "Error inspecting MySQL")
 		return false, err
 	}
 	return messages > 0, nil
 }
 
 // getQueryResult returns result of the scaler query
-func (s *mySQLScaler) getQueryResult(ctx context.Context) (int, error) {
-	var value int
-	err := s.connection.QueryRowContext(ctx, s.metadata.query).Scan(&value)
-	if err != nil {
-		mySQLLog.Error(err, fmt.Sprintf("Could not query MySQL database: %s", err))
+func (s *mySQLScaler) getQueryResult(ctx context.Context) (float64, error) {
+	var value sql.NullFloat64
+	err := s.connection.QueryRowContext(ctx, s.metadata.Query).Scan(&value)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		s.logger.Error(err, "Could not query MySQL database")
 		return 0, err
+	default:
+		err = errSQLNullResult
 	}
-	return value, nil
+
+	return s.metadata.missingValue.resolve(value.Float64, value.Valid, err)
 }
 
 // GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
 func (s *mySQLScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetQueryValue := resource.NewQuantity(int64(s.metadata.queryValue), resource.DecimalSI)
-
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: s.metadata.metricName,
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetQueryValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.QueryValue),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -223,11 +189,7 @@ func (s *mySQLScaler) GetMetrics(ctx context.Context, metricName string, metricS
 		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting MySQL: %s", err)
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(num), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, num)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
@@ -0,0 +1,86 @@
+// Package notification relays the notifications pushed by brokers, eg. by a GCP Pub/Sub push subscription or an
+// Azure Event Grid webhook, to the scalers listening for them, a notification activates the scale target right
+// away instead of waiting for the polling interval. It also holds the signals set by Alertmanager webhooks.
+package notification
+
+import (
+	"crypto/subtle"
+	"path"
+	"sync"
+)
+
+// Hub dispatches the notifications to their subscribers, the subscribers are identified by a namespace and a key
+// and the notifications are authenticated with the token of the subscriber
+type Hub struct {
+	lock        sync.RWMutex
+	subscribers map[string]map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	token  string
+	notify chan struct{}
+}
+
+// Default is the Hub of the scalers, it is served by the notification server of the operator
+var Default = NewHub()
+
+// NewHub creates an empty Hub
+func NewHub() *Hub {
+	return &Hub{subscribers: map[string]map[*subscriber]struct{}{}}
+}
+
+// Subscribe returns the channel receiving the notifications of key in namespace authenticated with token, the
+// notifications are coalesced while the channel isn't read. The returned func unsubscribes the channel.
+func (h *Hub) Subscribe(namespace, key, token string) (<-chan struct{}, func()) {
+	s := &subscriber{token: token, notify: make(chan struct{}, 1)}
+	id := path.Join(namespace, key)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.subscribers[id] == nil {
+		h.subscribers[id] = map[*subscriber]struct{}{}
+	}
+	h.subscribers[id][s] = struct{}{}
+
+	return s.notify, func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		delete(h.subscribers[id], s)
+		if len(h.subscribers[id]) == 0 {
+			delete(h.subscribers, id)
+		}
+	}
+}
+
+// Notify notifies the subscribers of key in namespace whose token matches, it returns false if there is none
+func (h *Hub) Notify(namespace, key, token string) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	subscribers := h.matching(namespace, key, token)
+	for _, s := range subscribers {
+		select {
+		case s.notify <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+	return len(subscribers) > 0
+}
+
+// Subscribed returns true if a subscriber of key in namespace has the token
+func (h *Hub) Subscribed(namespace, key, token string) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return len(h.matching(namespace, key, token)) > 0
+}
+
+func (h *Hub) matching(namespace, key, token string) []*subscriber {
+	var result []*subscriber
+	for s := range h.subscribers[path.Join(namespace, key)] {
+		if subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1 {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,331 @@
+package notification
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PathPrefix is the prefix of the notification endpoint,
+// the full path is PathPrefix + "namespaces/<namespace>/<key>?token=<token>", the token can be passed in an
+// `Authorization: Bearer <token>` header instead so it isn't logged with the URL
+const PathPrefix = "/api/v1/notifications/"
+
+// AlertmanagerPathPrefix is the prefix of the Alertmanager webhook endpoint,
+// the full path is AlertmanagerPathPrefix + "namespaces/<namespace>/<signal>"
+const AlertmanagerPathPrefix = "/api/v1/alertmanager/"
+
+// WebhookPathPrefix is the prefix of the generic webhook endpoint,
+// the full path is WebhookPathPrefix + "namespaces/<namespace>/<trigger>", with a `?token=<secret>` query or an
+// `Authorization: Bearer <secret>` header for the senders which can't sign their requests
+const WebhookPathPrefix = "/api/v1/webhooks/"
+
+// SignatureHeader is the header of the Alertmanager and generic webhooks holding the `sha256=` prefixed hex encoded
+// HMAC-SHA256 of their body
+const SignatureHeader = "X-KEDA-Signature"
+
+// maxBodySize bounds the size of the notifications which are read
+const maxBodySize = 1 << 20
+
+// Event Grid webhook handshake, see https://docs.microsoft.com/en-us/azure/event-grid/webhook-event-delivery
+const (
+	eventGridEventTypeHeader = "aeg-event-type"
+	eventGridValidation      = "SubscriptionValidation"
+)
+
+// CloudEvents HTTP binding, see https://github.com/cloudevents/spec/blob/v1.0.1/http-protocol-binding.md, and
+// webhook abuse protection, see https://github.com/cloudevents/spec/blob/v1.0.1/http-webhook.md#4-abuse-protection
+const (
+	cloudEventsSpecVersion       = "1.0"
+	cloudEventsStructuredType    = "application/cloudevents+json"
+	cloudEventsSpecVersionHeader = "ce-specversion"
+	cloudEventsIDHeader          = "ce-id"
+	cloudEventsSourceHeader      = "ce-source"
+	cloudEventsTypeHeader        = "ce-type"
+	webhookRequestOriginHeader   = "WebHook-Request-Origin"
+	webhookAllowedOriginHeader   = "WebHook-Allowed-Origin"
+	webhookAllowedRateHeader     = "WebHook-Allowed-Rate"
+	webhookAllowedRateUnlimited  = "*"
+)
+
+var errCloudEventAttributes = errors.New("the cloudevent must have its specversion 1.0, id, source and type set")
+
+type cloudEvent struct {
+	SpecVersion string          `json:"specversion"`
+	ID          string          `json:"id"`
+	Source      string          `json:"source"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+	DataBase64  string          `json:"data_base64"`
+}
+
+type alertmanagerWebhook struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+type eventGridValidationEvent struct {
+	Data struct {
+		ValidationCode string `json:"validationCode"`
+	} `json:"data"`
+}
+
+// Server exposes the HTTP endpoint the brokers push their notifications to, eg. the endpoint of a GCP Pub/Sub push
+// subscription or of an Azure Event Grid webhook subscription. The notifications are authenticated with the token
+// of the subscribers, the notifications of unknown keys or tokens are rejected with a 404.
+// It also receives the Alertmanager webhooks setting the alerts of the signals and the generic webhooks, plain JSON
+// or CloudEvents, setting the last events of the webhook triggers.
+type Server struct {
+	addr     string
+	hub      *Hub
+	signals  *Signals
+	webhooks *Webhooks
+	logger   logr.Logger
+}
+
+// NewServer creates a new notification Server listening on the passed address, notifying the subscribers of hub
+// and updating signals and webhooks
+func NewServer(addr string, hub *Hub, signals *Signals, webhooks *Webhooks) *Server {
+	return &Server{
+		addr:     addr,
+		hub:      hub,
+		signals:  signals,
+		webhooks: webhooks,
+		logger:   logf.Log.WithName("notification_server"),
+	}
+}
+
+// Start serves the notification endpoint until the context is done, it implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPrefix, s.handleNotification)
+	mux.HandleFunc(AlertmanagerPathPrefix, s.handleAlertmanager)
+	mux.HandleFunc(WebhookPathPrefix, s.handleWebhook)
+	srv := &http.Server{Addr: s.addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("Starting notification server", "address", s.addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the server runs on every replica, only the leader
+// running the scale loops has subscribers so the other replicas reject the notifications and the brokers retry them
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) handleNotification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace, key, ok := parsePath(r.URL.Path, PathPrefix)
+	if !ok {
+		http.Error(w, fmt.Sprintf("expected path %snamespaces/<namespace>/<key>", PathPrefix), http.StatusNotFound)
+		return
+	}
+	token := requestToken(r)
+
+	if r.Header.Get(eventGridEventTypeHeader) == eventGridValidation {
+		s.handleEventGridValidation(w, r, namespace, key, token)
+		return
+	}
+
+	// the body of the notification isn't needed, it is drained so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(r.Body, maxBodySize))
+	if !s.hub.Notify(namespace, key, token) {
+		http.Error(w, "no subscriber", http.StatusNotFound)
+		return
+	}
+	s.logger.V(1).Info("Notified subscribers", "namespace", namespace, "key", key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAlertmanager(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace, signal, ok := parsePath(r.URL.Path, AlertmanagerPathPrefix)
+	if !ok {
+		http.Error(w, fmt.Sprintf("expected path %snamespaces/<namespace>/<signal>", AlertmanagerPathPrefix), http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "error reading the webhook", http.StatusBadRequest)
+		return
+	}
+	var webhook alertmanagerWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		http.Error(w, "invalid alertmanager webhook", http.StatusBadRequest)
+		return
+	}
+
+	// the signature is checked before anything is done with the alerts
+	if !s.signals.Update(namespace, signal, body, r.Header.Get(SignatureHeader), webhook.Alerts) {
+		http.Error(w, "no subscriber", http.StatusNotFound)
+		return
+	}
+	s.logger.V(1).Info("Updated signal", "namespace", namespace, "signal", signal, "alerts", len(webhook.Alerts))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodOptions {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace, trigger, ok := parsePath(r.URL.Path, WebhookPathPrefix)
+	if !ok {
+		http.Error(w, fmt.Sprintf("expected path %snamespaces/<namespace>/<trigger>", WebhookPathPrefix), http.StatusNotFound)
+		return
+	}
+	token := requestToken(r)
+
+	if r.Method == http.MethodOptions {
+		s.handleWebhookValidation(w, r, namespace, trigger, token)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "error reading the webhook", http.StatusBadRequest)
+		return
+	}
+	event, err := parseWebhookEvent(r.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event.ReceivedAt = time.Now()
+
+	// the signature or the token is checked before the event is kept
+	if !s.webhooks.Update(namespace, trigger, body, r.Header.Get(SignatureHeader), token, event) {
+		http.Error(w, "no subscriber", http.StatusNotFound)
+		return
+	}
+	s.logger.V(1).Info("Received webhook", "namespace", namespace, "trigger", trigger, "type", event.Type, "id", event.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWebhookValidation answers the abuse protection handshake of the CloudEvents webhooks, the origin is
+// allowed once the token of the request matches the secret of a subscriber
+func (s *Server) handleWebhookValidation(w http.ResponseWriter, r *http.Request, namespace, trigger, token string) {
+	origin := r.Header.Get(webhookRequestOriginHeader)
+	if origin == "" {
+		http.Error(w, fmt.Sprintf("missing %s header", webhookRequestOriginHeader), http.StatusBadRequest)
+		return
+	}
+	if !s.webhooks.Authorized(namespace, trigger, nil, "", token) {
+		http.Error(w, "no subscriber", http.StatusNotFound)
+		return
+	}
+	w.Header().Set(webhookAllowedOriginHeader, origin)
+	w.Header().Set(webhookAllowedRateHeader, webhookAllowedRateUnlimited)
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseWebhookEvent parses the body of a generic webhook, either a CloudEvent in the binary mode, with its
+// attributes in the `ce-` headers, or in the structured mode, or a plain JSON document
+func parseWebhookEvent(header http.Header, body []byte) (Event, error) {
+	if header.Get(cloudEventsSpecVersionHeader) != "" {
+		event := Event{
+			Type:   header.Get(cloudEventsTypeHeader),
+			Source: header.Get(cloudEventsSourceHeader),
+			ID:     header.Get(cloudEventsIDHeader),
+			Data:   body,
+		}
+		if header.Get(cloudEventsSpecVersionHeader) != cloudEventsSpecVersion || event.Type == "" || event.Source == "" || event.ID == "" {
+			return Event{}, errCloudEventAttributes
+		}
+		if len(body) > 0 && !json.Valid(body) {
+			return Event{}, fmt.Errorf("the data of the cloudevent must be a json document")
+		}
+		return event, nil
+	}
+
+	if strings.HasPrefix(header.Get("Content-Type"), cloudEventsStructuredType) {
+		var ce cloudEvent
+		if err := json.Unmarshal(body, &ce); err != nil {
+			return Event{}, fmt.Errorf("invalid cloudevent")
+		}
+		if ce.SpecVersion != cloudEventsSpecVersion || ce.Type == "" || ce.Source == "" || ce.ID == "" {
+			return Event{}, errCloudEventAttributes
+		}
+		event := Event{Type: ce.Type, Source: ce.Source, ID: ce.ID, Data: ce.Data}
+		if ce.DataBase64 != "" {
+			data, err := base64.StdEncoding.DecodeString(ce.DataBase64)
+			if err != nil || !json.Valid(data) {
+				return Event{}, fmt.Errorf("the data_base64 of the cloudevent must be a base64 encoded json document")
+			}
+			event.Data = data
+		}
+		return event, nil
+	}
+
+	if !json.Valid(body) {
+		return Event{}, fmt.Errorf("the webhook must be a json document")
+	}
+	return Event{Data: body}, nil
+}
+
+// requestToken returns the bearer token of the Authorization header of the request, or its `token` query
+func requestToken(r *http.Request) string {
+	if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != r.Header.Get("Authorization") {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// parsePath returns the namespace and the name of the `<prefix>namespaces/<namespace>/<name>` paths
+func parsePath(urlPath, prefix string) (string, string, bool) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(urlPath, prefix), "/"), "/")
+	if len(parts) != 3 || parts[0] != "namespaces" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// handleEventGridValidation answers the validation handshake of an Event Grid webhook subscription with its code
+func (s *Server) handleEventGridValidation(w http.ResponseWriter, r *http.Request, namespace, key, token string) {
+	if !s.hub.Subscribed(namespace, key, token) {
+		http.Error(w, "no subscriber", http.StatusNotFound)
+		return
+	}
+
+	var events []eventGridValidationEvent
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&events); err != nil || len(events) == 0 {
+		http.Error(w, "invalid subscription validation event", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"validationResponse": events[0].Data.ValidationCode}); err != nil {
+		s.logger.Error(err, "Failed to write the subscription validation response")
+	}
+}
@@ -0,0 +1,295 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type notificationTestData struct {
+	name     string
+	method   string
+	path     string
+	header   string
+	body     string
+	status   int
+	notified bool
+	response string
+}
+
+var notificationTestDataset = []notificationTestData{
+	{"notification", http.MethodPost, PathPrefix + "namespaces/shop/orders?token=secret", "", `{"message": {"data": "e30="}}`, http.StatusNoContent, true, ""},
+	{"wrong token", http.MethodPost, PathPrefix + "namespaces/shop/orders?token=guess", "", "", http.StatusNotFound, false, ""},
+	{"no token", http.MethodPost, PathPrefix + "namespaces/shop/orders", "", "", http.StatusNotFound, false, ""},
+	{"other namespace", http.MethodPost, PathPrefix + "namespaces/dev/orders?token=secret", "", "", http.StatusNotFound, false, ""},
+	{"invalid path", http.MethodPost, PathPrefix + "shop/orders?token=secret", "", "", http.StatusNotFound, false, ""},
+	{"get", http.MethodGet, PathPrefix + "namespaces/shop/orders?token=secret", "", "", http.StatusMethodNotAllowed, false, ""},
+	{"event grid validation", http.MethodPost, PathPrefix + "namespaces/shop/orders?token=secret", eventGridValidation,
+		`[{"eventType": "Microsoft.EventGrid.SubscriptionValidationEvent", "data": {"validationCode": "512d38b6"}}]`, http.StatusOK, false, `{"validationResponse":"512d38b6"}`},
+	{"event grid validation wrong token", http.MethodPost, PathPrefix + "namespaces/shop/orders?token=guess", eventGridValidation,
+		`[{"data": {"validationCode": "512d38b6"}}]`, http.StatusNotFound, false, ""},
+	{"invalid event grid validation", http.MethodPost, PathPrefix + "namespaces/shop/orders?token=secret", eventGridValidation, `{}`, http.StatusBadRequest, false, ""},
+}
+
+func TestHandleNotification(t *testing.T) {
+	for _, testData := range notificationTestDataset {
+		hub := NewHub()
+		notify, unsubscribe := hub.Subscribe("shop", "orders", "secret")
+		s := NewServer("", hub, NewSignals(), NewWebhooks())
+
+		r := httptest.NewRequest(testData.method, testData.path, strings.NewReader(testData.body))
+		if testData.header != "" {
+			r.Header.Set(eventGridEventTypeHeader, testData.header)
+		}
+		w := httptest.NewRecorder()
+		s.handleNotification(w, r)
+
+		assert.Equal(t, testData.status, w.Code, testData.name)
+		if testData.response != "" {
+			assert.JSONEq(t, testData.response, w.Body.String(), testData.name)
+		}
+		select {
+		case <-notify:
+			assert.True(t, testData.notified, testData.name)
+		default:
+			assert.False(t, testData.notified, testData.name)
+		}
+		unsubscribe()
+	}
+}
+
+func TestRequestToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, PathPrefix+"namespaces/shop/orders?token=query", nil)
+	assert.Equal(t, "query", requestToken(r))
+
+	// the header takes precedence over the query
+	r.Header.Set("Authorization", "Bearer header")
+	assert.Equal(t, "header", requestToken(r))
+
+	r.Header.Set("Authorization", "Basic c2VjcmV0")
+	assert.Equal(t, "query", requestToken(r))
+}
+
+func TestHubCoalescesNotifications(t *testing.T) {
+	hub := NewHub()
+	notify, unsubscribe := hub.Subscribe("shop", "orders", "secret")
+	other, unsubscribeOther := hub.Subscribe("shop", "orders", "other")
+	defer unsubscribeOther()
+
+	assert.True(t, hub.Notify("shop", "orders", "secret"))
+	assert.True(t, hub.Notify("shop", "orders", "secret"))
+	assert.Len(t, notify, 1)
+	assert.Len(t, other, 0)
+
+	unsubscribe()
+	assert.False(t, hub.Notify("shop", "orders", "secret"))
+	assert.True(t, hub.Subscribed("shop", "orders", "other"))
+}
+
+func sign(body, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
+
+const testAlertmanagerWebhook = `{"alerts": [
+	{"status": "firing", "fingerprint": "a", "annotations": {"queue": "3"}},
+	{"status": "firing", "fingerprint": "b", "annotations": {"queue": "2"}}
+]}`
+
+type alertmanagerTestData struct {
+	name      string
+	method    string
+	path      string
+	signature string
+	body      string
+	status    int
+	firing    int
+}
+
+var alertmanagerTestDataset = []alertmanagerTestData{
+	{"webhook", http.MethodPost, AlertmanagerPathPrefix + "namespaces/shop/orders", sign(testAlertmanagerWebhook, "secret"), testAlertmanagerWebhook, http.StatusNoContent, 2},
+	{"wrong secret", http.MethodPost, AlertmanagerPathPrefix + "namespaces/shop/orders", sign(testAlertmanagerWebhook, "guess"), testAlertmanagerWebhook, http.StatusNotFound, 0},
+	{"no signature", http.MethodPost, AlertmanagerPathPrefix + "namespaces/shop/orders", "", testAlertmanagerWebhook, http.StatusNotFound, 0},
+	{"other namespace", http.MethodPost, AlertmanagerPathPrefix + "namespaces/dev/orders", sign(testAlertmanagerWebhook, "secret"), testAlertmanagerWebhook, http.StatusNotFound, 0},
+	{"invalid path", http.MethodPost, AlertmanagerPathPrefix + "shop/orders", sign(testAlertmanagerWebhook, "secret"), testAlertmanagerWebhook, http.StatusNotFound, 0},
+	{"invalid webhook", http.MethodPost, AlertmanagerPathPrefix + "namespaces/shop/orders", sign("{", "secret"), "{", http.StatusBadRequest, 0},
+	{"get", http.MethodGet, AlertmanagerPathPrefix + "namespaces/shop/orders", "", "", http.StatusMethodNotAllowed, 0},
+}
+
+func TestHandleAlertmanager(t *testing.T) {
+	for _, testData := range alertmanagerTestDataset {
+		signals := NewSignals()
+		notify, unsubscribe := signals.Subscribe("shop", "orders", "secret")
+		s := NewServer("", NewHub(), signals, NewWebhooks())
+
+		r := httptest.NewRequest(testData.method, testData.path, strings.NewReader(testData.body))
+		if testData.signature != "" {
+			r.Header.Set(SignatureHeader, testData.signature)
+		}
+		w := httptest.NewRecorder()
+		s.handleAlertmanager(w, r)
+
+		assert.Equal(t, testData.status, w.Code, testData.name)
+		assert.Len(t, signals.Firing("shop", "orders", time.Now()), testData.firing, testData.name)
+		select {
+		case <-notify:
+			assert.NotZero(t, testData.firing, testData.name)
+		default:
+			assert.Zero(t, testData.firing, testData.name)
+		}
+		unsubscribe()
+	}
+}
+
+func TestSignalsMergeAlerts(t *testing.T) {
+	signals := NewSignals()
+	_, unsubscribe := signals.Subscribe("shop", "orders", "secret")
+	now := time.Now()
+
+	update := func(alerts ...Alert) bool {
+		return signals.Update("shop", "orders", []byte("body"), sign("body", "secret"), alerts)
+	}
+	assert.True(t, update(Alert{Status: "firing", Fingerprint: "a"}, Alert{Status: "firing", Fingerprint: "b", EndsAt: now.Add(time.Minute)}))
+	assert.Len(t, signals.Firing("shop", "orders", now), 2)
+
+	// the alerts of another group are added, the resolved alerts are removed
+	assert.True(t, update(Alert{Status: "firing", Fingerprint: "c"}, Alert{Status: "resolved", Fingerprint: "a"}))
+	assert.Len(t, signals.Firing("shop", "orders", now), 2)
+
+	// the alerts which aren't sent again before their end expire
+	assert.Len(t, signals.Firing("shop", "orders", now.Add(2*time.Minute)), 1)
+
+	// the alerts outlive the subscribers, but no webhook is accepted without one
+	unsubscribe()
+	assert.Len(t, signals.Firing("shop", "orders", now), 2)
+	assert.False(t, update(Alert{Status: "resolved", Fingerprint: "c"}))
+}
+
+const testWebhook = `{"backlog": {"size": 7}}`
+
+const testStructuredCloudEvent = `{"specversion": "1.0", "id": "1", "source": "/shop", "type": "shop.order.created", "data": {"backlog": {"size": 3}}}`
+
+type webhookTestData struct {
+	name        string
+	method      string
+	path        string
+	header      map[string]string
+	body        string
+	status      int
+	eventType   string
+	data        string
+	allowOrigin string
+}
+
+var webhookTestDataset = []webhookTestData{
+	{"signed webhook", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders", map[string]string{SignatureHeader: sign(testWebhook, "secret")}, testWebhook, http.StatusNoContent, "", testWebhook, ""},
+	{"webhook with token", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret", nil, testWebhook, http.StatusNoContent, "", testWebhook, ""},
+	{"wrong secret", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders", map[string]string{SignatureHeader: sign(testWebhook, "guess")}, testWebhook, http.StatusNotFound, "", "", ""},
+	{"wrong token", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=guess", nil, testWebhook, http.StatusNotFound, "", "", ""},
+	{"webhook with bearer token", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders", map[string]string{"Authorization": "Bearer secret"}, testWebhook, http.StatusNoContent, "", testWebhook, ""},
+	{"wrong bearer token", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret", map[string]string{"Authorization": "Bearer guess"}, testWebhook, http.StatusNotFound, "", "", ""},
+	// the signature is checked even with a valid token
+	{"wrong signature with token", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret", map[string]string{SignatureHeader: sign(testWebhook, "guess")}, testWebhook, http.StatusNotFound, "", "", ""},
+	{"unauthenticated", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders", nil, testWebhook, http.StatusNotFound, "", "", ""},
+	{"other namespace", http.MethodPost, WebhookPathPrefix + "namespaces/dev/orders?token=secret", nil, testWebhook, http.StatusNotFound, "", "", ""},
+	{"invalid path", http.MethodPost, WebhookPathPrefix + "shop/orders?token=secret", nil, testWebhook, http.StatusNotFound, "", "", ""},
+	{"invalid json", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret", nil, "orders", http.StatusBadRequest, "", "", ""},
+	{"get", http.MethodGet, WebhookPathPrefix + "namespaces/shop/orders?token=secret", nil, "", http.StatusMethodNotAllowed, "", "", ""},
+	{"binary cloudevent", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret",
+		map[string]string{"ce-specversion": "1.0", "ce-id": "1", "ce-source": "/shop", "ce-type": "shop.order.created"}, testWebhook, http.StatusNoContent, "shop.order.created", testWebhook, ""},
+	{"binary cloudevent without type", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret",
+		map[string]string{"ce-specversion": "1.0", "ce-id": "1", "ce-source": "/shop"}, testWebhook, http.StatusBadRequest, "", "", ""},
+	{"binary cloudevent of another version", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret",
+		map[string]string{"ce-specversion": "0.3", "ce-id": "1", "ce-source": "/shop", "ce-type": "shop.order.created"}, testWebhook, http.StatusBadRequest, "", "", ""},
+	{"structured cloudevent", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders",
+		map[string]string{"Content-Type": "application/cloudevents+json; charset=UTF-8", SignatureHeader: sign(testStructuredCloudEvent, "secret")}, testStructuredCloudEvent, http.StatusNoContent, "shop.order.created", `{"backlog": {"size": 3}}`, ""},
+	{"structured cloudevent with base64 data", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret",
+		map[string]string{"Content-Type": "application/cloudevents+json"}, `{"specversion": "1.0", "id": "1", "source": "/shop", "type": "shop.order.created", "data_base64": "eyJzaXplIjogMn0="}`, http.StatusNoContent, "shop.order.created", `{"size": 2}`, ""},
+	{"structured cloudevent without id", http.MethodPost, WebhookPathPrefix + "namespaces/shop/orders?token=secret",
+		map[string]string{"Content-Type": "application/cloudevents+json"}, `{"specversion": "1.0", "source": "/shop", "type": "shop.order.created"}`, http.StatusBadRequest, "", "", ""},
+	{"abuse protection", http.MethodOptions, WebhookPathPrefix + "namespaces/shop/orders?token=secret", map[string]string{"WebHook-Request-Origin": "eventgrid.azure.net"}, "", http.StatusOK, "", "", "eventgrid.azure.net"},
+	{"abuse protection wrong token", http.MethodOptions, WebhookPathPrefix + "namespaces/shop/orders?token=guess", map[string]string{"WebHook-Request-Origin": "eventgrid.azure.net"}, "", http.StatusNotFound, "", "", ""},
+	{"abuse protection without origin", http.MethodOptions, WebhookPathPrefix + "namespaces/shop/orders?token=secret", nil, "", http.StatusBadRequest, "", "", ""},
+}
+
+func TestHandleWebhook(t *testing.T) {
+	for _, testData := range webhookTestDataset {
+		webhooks := NewWebhooks()
+		subscription := webhooks.Subscribe("shop", "orders", "secret", "", time.Minute)
+		s := NewServer("", NewHub(), NewSignals(), webhooks)
+
+		r := httptest.NewRequest(testData.method, testData.path, strings.NewReader(testData.body))
+		for k, v := range testData.header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		s.handleWebhook(w, r)
+
+		assert.Equal(t, testData.status, w.Code, testData.name)
+		assert.Equal(t, testData.allowOrigin, w.Header().Get(webhookAllowedOriginHeader), testData.name)
+		event, received := subscription.Last(time.Now())
+		if testData.data != "" {
+			assert.True(t, received, testData.name)
+			assert.Equal(t, testData.eventType, event.Type, testData.name)
+			assert.JSONEq(t, testData.data, string(event.Data), testData.name)
+		} else {
+			assert.False(t, received, testData.name)
+		}
+		select {
+		case <-subscription.Notify():
+			assert.True(t, received, testData.name)
+		default:
+			assert.False(t, received, testData.name)
+		}
+		subscription.Unsubscribe()
+	}
+}
+
+func TestWebhooksKeepEventsBySubscription(t *testing.T) {
+	webhooks := NewWebhooks()
+	orders := webhooks.Subscribe("shop", "orders", "secret", "", time.Minute)
+	cancelled := webhooks.Subscribe("shop", "orders", "secret", "cancelled", time.Minute)
+	other := webhooks.Subscribe("shop", "orders", "other", "", time.Minute)
+	now := time.Now()
+
+	update := func(event Event, secret string) bool {
+		return webhooks.Update("shop", "orders", []byte("body"), sign("body", secret), "", event)
+	}
+	assert.True(t, update(Event{Type: "created", ID: "1", ReceivedAt: now}, "secret"))
+	assert.True(t, update(Event{Type: "cancelled", ID: "2", ReceivedAt: now.Add(time.Second)}, "secret"))
+	assert.True(t, update(Event{Type: "created", ID: "3", ReceivedAt: now.Add(2 * time.Second)}, "secret"))
+
+	event, ok := orders.Last(now)
+	assert.True(t, ok)
+	assert.Equal(t, "3", event.ID)
+	event, ok = cancelled.Last(now)
+	assert.True(t, ok)
+	assert.Equal(t, "2", event.ID)
+	// the events sent with the secret of another trigger aren't read
+	_, ok = other.Last(now)
+	assert.False(t, ok)
+
+	// the events are dropped once they expire
+	_, ok = cancelled.Last(now.Add(time.Minute + time.Second))
+	assert.False(t, ok)
+	_, ok = cancelled.Last(now)
+	assert.False(t, ok)
+
+	// the events are dropped with their subscription, and no webhook is accepted without one
+	orders.Unsubscribe()
+	_, ok = orders.Last(now)
+	assert.False(t, ok)
+	cancelled.Unsubscribe()
+	assert.False(t, update(Event{Type: "created", ID: "4", ReceivedAt: now}, "secret"))
+	assert.True(t, update(Event{Type: "created", ID: "5", ReceivedAt: now}, "other"))
+	other.Unsubscribe()
+	assert.Empty(t, webhooks.subscribers)
+}
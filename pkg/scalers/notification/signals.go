@@ -0,0 +1,131 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert is an alert of an Alertmanager webhook, see https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type Alert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+const alertStatusFiring = "firing"
+
+// Signals holds the firing alerts of the named signals set by the Alertmanager webhooks, the webhooks are
+// authenticated with the HMAC-SHA256 of their body keyed with the secret of a subscriber. The alerts of a signal
+// are kept once its subscribers are gone, so the scalers rebuilt after a change of their ScaledObject read them.
+type Signals struct {
+	lock        sync.RWMutex
+	alerts      map[string]map[string]Alert
+	subscribers map[string]map[*signalSubscriber]struct{}
+}
+
+type signalSubscriber struct {
+	secret []byte
+	notify chan struct{}
+}
+
+// DefaultSignals are the Signals of the scalers, they are set by the notification server of the operator
+var DefaultSignals = NewSignals()
+
+// NewSignals creates empty Signals
+func NewSignals() *Signals {
+	return &Signals{
+		alerts:      map[string]map[string]Alert{},
+		subscribers: map[string]map[*signalSubscriber]struct{}{},
+	}
+}
+
+// Subscribe returns the channel notified when the alerts of the signal in namespace change, the notifications are
+// coalesced while the channel isn't read. The returned func unsubscribes the channel.
+func (s *Signals) Subscribe(namespace, name, secret string) (<-chan struct{}, func()) {
+	subscriber := &signalSubscriber{secret: []byte(secret), notify: make(chan struct{}, 1)}
+	id := path.Join(namespace, name)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.subscribers[id] == nil {
+		s.subscribers[id] = map[*signalSubscriber]struct{}{}
+	}
+	s.subscribers[id][subscriber] = struct{}{}
+
+	return subscriber.notify, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		delete(s.subscribers[id], subscriber)
+		if len(s.subscribers[id]) == 0 {
+			delete(s.subscribers, id)
+		}
+	}
+}
+
+// Firing returns the alerts of the signal in namespace which are firing at now
+func (s *Signals) Firing(namespace, name string, now time.Time) []Alert {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var firing []Alert
+	for _, alert := range s.alerts[path.Join(namespace, name)] {
+		// the alerts which haven't been sent again before their end are resolved
+		if alert.EndsAt.IsZero() || alert.EndsAt.After(now) {
+			firing = append(firing, alert)
+		}
+	}
+	return firing
+}
+
+// Update updates the alerts of the signal in namespace with the alerts of a webhook, signature is the hex encoded
+// HMAC-SHA256 of body, prefixed with `sha256=`. It returns false if no subscriber has the secret of the signature.
+func (s *Signals) Update(namespace, name string, body []byte, signature string, alerts []Alert) bool {
+	mac, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(mac) == 0 {
+		return false
+	}
+	id := path.Join(namespace, name)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var subscribers []*signalSubscriber
+	for subscriber := range s.subscribers[id] {
+		h := hmac.New(sha256.New, subscriber.secret)
+		h.Write(body)
+		if hmac.Equal(mac, h.Sum(nil)) {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	if len(subscribers) == 0 {
+		return false
+	}
+
+	// the alerts of all the groups sent to the signal are merged by fingerprint
+	if s.alerts[id] == nil {
+		s.alerts[id] = map[string]Alert{}
+	}
+	for _, alert := range alerts {
+		if alert.Status == alertStatusFiring {
+			s.alerts[id][alert.Fingerprint] = alert
+		} else {
+			delete(s.alerts[id], alert.Fingerprint)
+		}
+	}
+
+	for _, subscriber := range subscribers {
+		select {
+		case subscriber.notify <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+	return true
+}
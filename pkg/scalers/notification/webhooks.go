@@ -0,0 +1,173 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is the last event a generic webhook received for a trigger, the CloudEvents have their type, source and id
+// set and Data holds their data, the other webhooks have none and Data holds their JSON body
+type Event struct {
+	Type       string
+	Source     string
+	ID         string
+	Data       []byte
+	ReceivedAt time.Time
+}
+
+// Webhooks holds the last events of the named triggers set by the generic webhooks, the webhooks are authenticated
+// either with the HMAC-SHA256 of their body keyed with the secret of a subscriber or with the secret as their token,
+// for the senders which can't sign their requests. Each subscription keeps the last event authenticated with its own
+// secret, so a trigger never reads the events sent with the secret of another one, and the event is dropped once
+// it expires or the subscription is gone.
+type Webhooks struct {
+	lock        sync.RWMutex
+	subscribers map[string]map[*WebhookSubscription]struct{}
+}
+
+// WebhookSubscription is the subscription of a trigger to its webhooks, see Webhooks.Subscribe
+type WebhookSubscription struct {
+	webhooks  *Webhooks
+	id        string
+	secret    []byte
+	eventType string
+	window    time.Duration
+	notify    chan struct{}
+	// last is guarded by the lock of webhooks
+	last *Event
+}
+
+// DefaultWebhooks are the Webhooks of the scalers, they are set by the notification server of the operator
+var DefaultWebhooks = NewWebhooks()
+
+// NewWebhooks creates empty Webhooks
+func NewWebhooks() *Webhooks {
+	return &Webhooks{
+		subscribers: map[string]map[*WebhookSubscription]struct{}{},
+	}
+}
+
+// Subscribe subscribes to the webhooks of the trigger in namespace authenticated with secret, the subscription
+// keeps the last event with the passed type, any type if eventType is empty, for window after it is received
+func (w *Webhooks) Subscribe(namespace, name, secret, eventType string, window time.Duration) *WebhookSubscription {
+	subscription := &WebhookSubscription{
+		webhooks:  w,
+		id:        path.Join(namespace, name),
+		secret:    []byte(secret),
+		eventType: eventType,
+		window:    window,
+		notify:    make(chan struct{}, 1),
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.subscribers[subscription.id] == nil {
+		w.subscribers[subscription.id] = map[*WebhookSubscription]struct{}{}
+	}
+	w.subscribers[subscription.id][subscription] = struct{}{}
+	return subscription
+}
+
+// Notify returns the channel notified when the subscription receives an event, the notifications are coalesced
+// while the channel isn't read
+func (s *WebhookSubscription) Notify() <-chan struct{} {
+	return s.notify
+}
+
+// Last returns the last event of the subscription if it hasn't expired at now, the expired event is dropped
+func (s *WebhookSubscription) Last(now time.Time) (Event, bool) {
+	s.webhooks.lock.Lock()
+	defer s.webhooks.lock.Unlock()
+
+	if s.last == nil {
+		return Event{}, false
+	}
+	if !s.last.ReceivedAt.Add(s.window).After(now) {
+		s.last = nil
+		return Event{}, false
+	}
+	return *s.last, true
+}
+
+// Unsubscribe removes the subscription and drops its event, the webhooks with its secret aren't accepted anymore
+// unless another subscription has it
+func (s *WebhookSubscription) Unsubscribe() {
+	w := s.webhooks
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.subscribers[s.id], s)
+	if len(w.subscribers[s.id]) == 0 {
+		delete(w.subscribers, s.id)
+	}
+	s.last = nil
+}
+
+// Authorized returns true if a subscriber of the trigger in namespace has the secret of the signature or of the
+// token, see Update
+func (w *Webhooks) Authorized(namespace, name string, body []byte, signature, token string) bool {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return len(w.matching(path.Join(namespace, name), body, signature, token)) > 0
+}
+
+// Update sets the last event of the subscriptions of the trigger in namespace which have the secret of the
+// signature or of the token and accept the type of the event, signature is the hex encoded HMAC-SHA256 of body,
+// prefixed with `sha256=`, and token is used when there is no signature. It returns false if no subscriber has
+// the secret of the signature or of the token.
+func (w *Webhooks) Update(namespace, name string, body []byte, signature, token string, event Event) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	subscriptions := w.matching(path.Join(namespace, name), body, signature, token)
+	if len(subscriptions) == 0 {
+		return false
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.eventType != "" && subscription.eventType != event.Type {
+			continue
+		}
+		last := event
+		subscription.last = &last
+		select {
+		case subscription.notify <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+	return true
+}
+
+func (w *Webhooks) matching(id string, body []byte, signature, token string) []*WebhookSubscription {
+	var mac []byte
+	if signature != "" {
+		var err error
+		if mac, err = hex.DecodeString(strings.TrimPrefix(signature, "sha256=")); err != nil || len(mac) == 0 {
+			return nil
+		}
+	} else if token == "" {
+		return nil
+	}
+
+	var result []*WebhookSubscription
+	for subscription := range w.subscribers[id] {
+		if mac == nil {
+			if subtle.ConstantTimeCompare(subscription.secret, []byte(token)) == 1 {
+				result = append(result, subscription)
+			}
+			continue
+		}
+		h := hmac.New(sha256.New, subscription.secret)
+		h.Write(body)
+		if hmac.Equal(mac, h.Sum(nil)) {
+			result = append(result, subscription)
+		}
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+type objectScaler struct {
+	metadata *objectMetadata
+}
+
+type objectMetadata struct {
+	// the described object, in the namespace of the ScaledObject
+	APIVersion string `keda:"name=apiVersion"`
+	Kind       string `keda:"name=kind"`
+	Name       string `keda:"name=name"`
+
+	MetricName     string                   `keda:"name=metricName"`
+	MetricSelector string                   `keda:"name=metricSelector, optional"`
+	MetricType     v2beta2.MetricTargetType `keda:"name=type, optional, enum=Value;AverageValue"`
+	Value          resource.Quantity        `keda:"name=value"`
+
+	metricSelector *metav1.LabelSelector
+}
+
+// Validate checks the target value is positive
+func (m *objectMetadata) Validate() error {
+	if m.Value.Sign() <= 0 {
+		return fmt.Errorf("value must be greater than 0")
+	}
+	return nil
+}
+
+// NewObjectScaler creates a new scaler for a metric describing another Kubernetes object, eg. the requests per second
+// of an Ingress, the metric is read by the HPA from the custom metrics API
+func NewObjectScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseObjectMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing object metadata: %s", err)
+	}
+
+	return &objectScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseObjectMetadata(config *ScalerConfig) (*objectMetadata, error) {
+	meta := &objectMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+
+	if meta.MetricSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(meta.MetricSelector)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing metricSelector: %s", err)
+		}
+		meta.metricSelector = selector
+	}
+
+	if meta.MetricType == "" {
+		meta.MetricType = config.MetricType
+	}
+	switch meta.MetricType {
+	case "":
+		meta.MetricType = v2beta2.ValueMetricType
+	case v2beta2.ValueMetricType, v2beta2.AverageValueMetricType:
+	default:
+		return nil, fmt.Errorf("unsupported metric type, allowed values are 'Value' or 'AverageValue'")
+	}
+
+	return meta, nil
+}
+
+// IsActive always return true for the object scaler, its metric is only read by the HPA
+func (s *objectScaler) IsActive(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// Close no need for object scaler
+func (s *objectScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the Object metric spec for the HPA
+func (s *objectScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	value := s.metadata.Value.DeepCopy()
+	target := v2beta2.MetricTarget{Type: s.metadata.MetricType}
+	if s.metadata.MetricType == v2beta2.AverageValueMetricType {
+		target.AverageValue = &value
+	} else {
+		target.Value = &value
+	}
+
+	objectMetric := &v2beta2.ObjectMetricSource{
+		DescribedObject: v2beta2.CrossVersionObjectReference{
+			APIVersion: s.metadata.APIVersion,
+			Kind:       s.metadata.Kind,
+			Name:       s.metadata.Name,
+		},
+		Metric: v2beta2.MetricIdentifier{
+			Name:     s.metadata.MetricName,
+			Selector: s.metadata.metricSelector,
+		},
+		Target: target,
+	}
+	metricSpec := v2beta2.MetricSpec{Object: objectMetric, Type: v2beta2.ObjectMetricSourceType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics no need for object scaler, the HPA reads the metric from the custom metrics API
+func (s *objectScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	return nil, nil
+}
@@ -0,0 +1,64 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+)
+
+type parseObjectMetadataTestData struct {
+	metadata   map[string]string
+	metricType v2beta2.MetricTargetType
+	isError    bool
+}
+
+var validObjectMetadata = map[string]string{
+	"apiVersion": "networking.k8s.io/v1",
+	"kind":       "Ingress",
+	"name":       "main-route",
+	"metricName": "requests-per-second",
+	"value":      "2k",
+}
+
+var testObjectMetadata = []parseObjectMetadataTestData{
+	{map[string]string{}, "", true},
+	{validObjectMetadata, "", false},
+	{validObjectMetadata, v2beta2.AverageValueMetricType, false},
+	{validObjectMetadata, v2beta2.UtilizationMetricType, true},
+	{map[string]string{"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "name": "main-route", "metricName": "requests-per-second", "value": "100", "type": "AverageValue", "metricSelector": "route=checkout"}, "", false},
+	// no name
+	{map[string]string{"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "metricName": "requests-per-second", "value": "100"}, "", true},
+	// no metricName
+	{map[string]string{"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "name": "main-route", "value": "100"}, "", true},
+	// invalid value
+	{map[string]string{"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "name": "main-route", "metricName": "requests-per-second", "value": "many"}, "", true},
+	// invalid metricSelector
+	{map[string]string{"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "name": "main-route", "metricName": "requests-per-second", "value": "100", "metricSelector": "route in checkout"}, "", true},
+}
+
+func TestObjectParseMetadata(t *testing.T) {
+	for i, testData := range testObjectMetadata {
+		_, err := parseObjectMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, MetricType: testData.metricType})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestObjectGetMetricSpecForScaling(t *testing.T) {
+	scaler, err := NewObjectScaler(&ScalerConfig{TriggerMetadata: validObjectMetadata})
+	assert.NoError(t, err)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, v2beta2.ObjectMetricSourceType, metricSpec[0].Type)
+	assert.Equal(t, v2beta2.CrossVersionObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "main-route"}, metricSpec[0].Object.DescribedObject)
+	assert.Equal(t, "requests-per-second", metricSpec[0].Object.Metric.Name)
+	assert.Equal(t, v2beta2.ValueMetricType, metricSpec[0].Object.Target.Type)
+	assert.Equal(t, int64(2000), metricSpec[0].Object.Target.Value.Value())
+	assert.Nil(t, metricSpec[0].Object.Target.AverageValue)
+}
@@ -0,0 +1,17 @@
+package scalers
+
+import (
+	"math"
+	"time"
+)
+
+// modeOldestItemAge is the mode of the queue scalers reporting the age in seconds of the oldest item of the queue
+// instead of its length, the replicas are scaled to keep the latency of the items under the target value in seconds.
+// The scalers stay active while the queue has items
+const modeOldestItemAge = "OldestItemAge"
+
+// oldestItemAge returns the age in seconds of the oldest item enqueued at enqueuedAt, 0 for the items from the future
+// with a skewed clock
+func oldestItemAge(enqueuedAt, now time.Time) float64 {
+	return math.Max(now.Sub(enqueuedAt).Seconds(), 0)
+}
@@ -3,12 +3,15 @@ package openstack
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
+	"sync"
 	"time"
 
 	openstackutil "github.com/kedacore/keda/v2/pkg/scalers/openstack/utils"
@@ -19,6 +22,20 @@ import (
 const tokensEndpoint = "/v3/auth/tokens"
 const catalogEndpoint = "/v3/auth/catalog"
 
+// tokenRenewalMargin is the time before its expiration when a token is renewed
+const tokenRenewalMargin = time.Minute
+
+// tokenPool shares the tokens of identical authentication requests between the scalers and across the polls
+var tokenPool = struct {
+	sync.Mutex
+	tokens map[string]pooledToken
+}{tokens: make(map[string]pooledToken)}
+
+type pooledToken struct {
+	token     string
+	expiresAt time.Time
+}
+
 // Client is a struct containing an authentication token and an HTTP client for HTTP requests.
 // It can also have a public URL for an specific OpenStack project or service.
 // "authMetadata" is an unexported attribute used to validate the current token or to renew it against Keystone when it is expired.
@@ -32,6 +49,9 @@ type Client struct {
 	// HTTPClient is the client used for launching HTTP requests.
 	HTTPClient *http.Client
 
+	// expiresAt is the expiration time of Token returned by Keystone, a zero value means that it is unknown.
+	expiresAt time.Time
+
 	// authMetadata contains the properties needed for retrieving an authentication token, renew it, and dinamically discover services public URLs from Keystone.
 	authMetadata *KeystoneAuthRequest
 }
@@ -69,16 +89,42 @@ type appCredentialProps struct {
 }
 
 type scopeProps struct {
-	Project *projectProps `json:"project"`
+	Project *projectProps `json:"project,omitempty"`
+	System  *systemProps  `json:"system,omitempty"`
 }
 
 type userProps struct {
-	ID       string `json:"id"`
-	Password string `json:"password"`
+	ID       string  `json:"id,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Domain   *Domain `json:"domain,omitempty"`
+	Password string  `json:"password"`
 }
 
 type projectProps struct {
-	ID string `json:"id"`
+	ID     string  `json:"id,omitempty"`
+	Name   string  `json:"name,omitempty"`
+	Domain *Domain `json:"domain,omitempty"`
+}
+
+type systemProps struct {
+	All bool `json:"all"`
+}
+
+// Domain identifies a Keystone v3 domain either by its ID or by its name.
+// It is required when a user or a project is identified by its name, as names are only unique within a domain.
+type Domain struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+func (domain Domain) isEmpty() bool {
+	return domain.ID == "" && domain.Name == ""
+}
+
+type tokenResponse struct {
+	Token struct {
+		ExpiresAt time.Time `json:"expires_at"`
+	} `json:"token"`
 }
 
 type keystoneCatalog struct {
@@ -141,17 +187,49 @@ func (client *Client) IsTokenValid(ctx context.Context) (bool, error) {
 
 // RenewToken retrives another token from Keystone
 func (client *Client) RenewToken(ctx context.Context) error {
-	token, err := client.authMetadata.getToken(ctx)
+	token, expiresAt, err := client.authMetadata.requestToken(ctx)
 
 	if err != nil {
 		return err
 	}
 
+	client.authMetadata.poolToken(token, expiresAt)
+
 	client.Token = token
+	client.expiresAt = expiresAt
 
 	return nil
 }
 
+// GetToken returns a valid authentication token. The token is reused until it is about to expire,
+// so Keystone is only called when the token or the pooled token of the same credentials are expired.
+// When Keystone doesn't return the expiration of the token, it falls back to checking the token validity.
+func (client *Client) GetToken(ctx context.Context) (string, error) {
+	if client.Token != "" && !client.expiresAt.IsZero() && time.Now().Add(tokenRenewalMargin).Before(client.expiresAt) {
+		return client.Token, nil
+	}
+
+	if client.Token != "" && client.expiresAt.IsZero() {
+		isValid, err := client.IsTokenValid(ctx)
+		if err != nil {
+			return "", err
+		}
+		if isValid {
+			return client.Token, nil
+		}
+	}
+
+	token, expiresAt, err := client.authMetadata.getToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	client.Token = token
+	client.expiresAt = expiresAt
+
+	return client.Token, nil
+}
+
 // NewPasswordAuth creates a struct containing metadata for authentication using the password method
 func NewPasswordAuth(authURL string, userID string, userPassword string, projectID string, httpTimeout int) (*KeystoneAuthRequest, error) {
 	passAuth := new(KeystoneAuthRequest)
@@ -184,9 +262,56 @@ func NewPasswordAuth(authURL string, userID string, userPassword string, project
 
 	passAuth.Properties.Scope.Project.ID = projectID
 
+	// An empty project ID is not a valid scope, the token is unscoped until a scope is set
+	if projectID == "" {
+		passAuth.Properties.Scope = nil
+	}
+
 	return passAuth, nil
 }
 
+// NewPasswordAuthByName creates a struct containing metadata for authentication using the password method
+// for a user identified by its name in a Keystone v3 domain
+func NewPasswordAuthByName(authURL string, userName string, userDomain Domain, userPassword string, httpTimeout int) (*KeystoneAuthRequest, error) {
+	if userDomain.isEmpty() {
+		return nil, fmt.Errorf("the domain of the user %s is required", userName)
+	}
+
+	passAuth, err := NewPasswordAuth(authURL, "", userPassword, "", httpTimeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	passAuth.Properties.Identity.Password.User.Name = userName
+	passAuth.Properties.Identity.Password.User.Domain = &userDomain
+
+	return passAuth, nil
+}
+
+// SetProjectScope scopes the token to a project identified either by its ID or by its name in a Keystone v3 domain
+func (keystone *KeystoneAuthRequest) SetProjectScope(projectID string, projectName string, projectDomain Domain) error {
+	project := &projectProps{ID: projectID}
+
+	if projectID == "" {
+		if projectName == "" || projectDomain.isEmpty() {
+			return fmt.Errorf("either the project ID or the project name and its domain are required")
+		}
+
+		project.Name = projectName
+		project.Domain = &projectDomain
+	}
+
+	keystone.Properties.Scope = &scopeProps{Project: project}
+
+	return nil
+}
+
+// SetSystemScope scopes the token to the deployment system, e.g. for the services whose API is restricted to system readers
+func (keystone *KeystoneAuthRequest) SetSystemScope() {
+	keystone.Properties.Scope = &scopeProps{System: &systemProps{All: true}}
+}
+
 // NewAppCredentialsAuth creates a struct containing metadata for authentication using the application credentials method
 func NewAppCredentialsAuth(authURL string, id string, secret string, httpTimeout int) (*KeystoneAuthRequest, error) {
 	appAuth := new(KeystoneAuthRequest)
@@ -225,13 +350,14 @@ func (keystone *KeystoneAuthRequest) RequestClient(ctx context.Context, projectP
 		authMetadata: keystone,
 	}
 
-	token, err := keystone.getToken(ctx)
+	token, expiresAt, err := keystone.getToken(ctx)
 
 	if err != nil {
 		return client, err
 	}
 
 	client.Token = token
+	client.expiresAt = expiresAt
 
 	var serviceURL string
 
@@ -253,21 +379,79 @@ func (keystone *KeystoneAuthRequest) RequestClient(ctx context.Context, projectP
 	return client, nil
 }
 
-func (keystone *KeystoneAuthRequest) getToken(ctx context.Context) (string, error) {
-	var httpClient = kedautil.CreateHTTPClient(keystone.HTTPClientTimeout, false)
+// getToken returns the pooled token of the authentication request or requests a new one when it is about to expire
+func (keystone *KeystoneAuthRequest) getToken(ctx context.Context) (string, time.Time, error) {
+	key, err := keystone.poolKey()
+
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	tokenPool.Lock()
+	pooled, ok := tokenPool.tokens[key]
+	tokenPool.Unlock()
+
+	if ok && time.Now().Add(tokenRenewalMargin).Before(pooled.expiresAt) {
+		return pooled.token, pooled.expiresAt, nil
+	}
+
+	token, expiresAt, err := keystone.requestToken(ctx)
+
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	keystone.poolToken(token, expiresAt)
+
+	return token, expiresAt, nil
+}
+
+// poolToken shares a token with the clients of the same credentials, tokens without expiration are not pooled
+func (keystone *KeystoneAuthRequest) poolToken(token string, expiresAt time.Time) {
+	if expiresAt.IsZero() {
+		return
+	}
+
+	key, err := keystone.poolKey()
 
+	if err != nil {
+		return
+	}
+
+	tokenPool.Lock()
+	tokenPool.tokens[key] = pooledToken{token: token, expiresAt: expiresAt}
+	tokenPool.Unlock()
+}
+
+// poolKey identifies the credentials and the scope of the authentication request without keeping the secrets in memory
+func (keystone *KeystoneAuthRequest) poolKey() (string, error) {
 	jsonBody, err := json.Marshal(keystone)
 
 	if err != nil {
 		return "", err
 	}
 
+	hash := sha256.Sum256(append([]byte(keystone.AuthURL+"|"), jsonBody...))
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// requestToken requests a new token from Keystone and returns it with its expiration time
+func (keystone *KeystoneAuthRequest) requestToken(ctx context.Context) (string, time.Time, error) {
+	var httpClient = kedautil.CreateHTTPClient(keystone.HTTPClientTimeout, false)
+
+	jsonBody, err := json.Marshal(keystone)
+
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
 	jsonBodyReader := bytes.NewReader(jsonBody)
 
 	tokenURL, err := url.Parse(keystone.AuthURL)
 
 	if err != nil {
-		return "", fmt.Errorf("the authURL is invalid: %s", err.Error())
+		return "", time.Time{}, fmt.Errorf("the authURL is invalid: %s", err.Error())
 	}
 
 	tokenURL.Path = path.Join(tokenURL.Path, tokensEndpoint)
@@ -275,28 +459,40 @@ func (keystone *KeystoneAuthRequest) getToken(ctx context.Context) (string, erro
 	tokenRequest, err := http.NewRequestWithContext(ctx, "POST", tokenURL.String(), jsonBodyReader)
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	resp, err := httpClient.Do(tokenRequest)
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
-		return resp.Header["X-Subject-Token"][0], nil
+		token := resp.Header.Get("X-Subject-Token")
+
+		if token == "" {
+			return "", time.Time{}, fmt.Errorf("keystone didn't return a token")
+		}
+
+		// The expiration is optional for the token validation, the token is checked against Keystone when it is unknown
+		var tokenBody tokenResponse
+		if err := json.NewDecoder(resp.Body).Decode(&tokenBody); err != nil {
+			return token, time.Time{}, nil
+		}
+
+		return token, tokenBody.Token.ExpiresAt, nil
 	}
 
 	errBody, err := ioutil.ReadAll(resp.Body)
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return "", fmt.Errorf(string(errBody))
+	return "", time.Time{}, fmt.Errorf(string(errBody))
 }
 
 // getCatalog retrives the OpenStack catalog according to the current authorization
@@ -0,0 +1,76 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordAuthByNameWithProjectScope(t *testing.T) {
+	auth, err := NewPasswordAuthByName("http://localhost:5000/", "my-user", Domain{Name: "Default"}, "my-password", 5)
+	assert.NoError(t, err)
+	assert.NoError(t, auth.SetProjectScope("", "my-project", Domain{ID: "default"}))
+
+	body, err := json.Marshal(auth)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auth":{"identity":{"methods":["password"],"password":{"user":{"name":"my-user","domain":{"name":"Default"},"password":"my-password"}}},"scope":{"project":{"name":"my-project","domain":{"id":"default"}}}}}`, string(body))
+
+	auth.SetSystemScope()
+	body, err = json.Marshal(auth)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auth":{"identity":{"methods":["password"],"password":{"user":{"name":"my-user","domain":{"name":"Default"},"password":"my-password"}}},"scope":{"system":{"all":true}}}}`, string(body))
+
+	_, err = NewPasswordAuthByName("http://localhost:5000/", "my-user", Domain{}, "my-password", 5)
+	assert.Error(t, err)
+	assert.Error(t, auth.SetProjectScope("", "my-project", Domain{}))
+}
+
+func TestTokensArePooledUntilExpiration(t *testing.T) {
+	var tokenRequests int
+	var expiresAt time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tokensEndpoint || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tokenRequests++
+		w.Header().Set("X-Subject-Token", fmt.Sprintf("token-%d", tokenRequests))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":{"expires_at":"%s"}}`, expiresAt.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	expiresAt = time.Now().Add(time.Hour)
+	auth, err := NewAppCredentialsAuth(server.URL, "my-app-credential-id", "my-app-credential-secret", 5)
+	assert.NoError(t, err)
+
+	client, err := auth.RequestClient(context.TODO())
+	assert.NoError(t, err)
+	otherClient, err := auth.RequestClient(context.TODO())
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		token, err := client.GetToken(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, "token-1", otherClient.Token)
+	assert.Equal(t, 1, tokenRequests)
+
+	// a token about to expire is renewed
+	client.expiresAt = time.Now().Add(time.Second)
+	tokenPool.Lock()
+	tokenPool.tokens = make(map[string]pooledToken)
+	tokenPool.Unlock()
+
+	token, err := client.GetToken(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, 2, tokenRequests)
+}
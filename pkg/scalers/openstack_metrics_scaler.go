@@ -14,8 +14,6 @@ import (
 	"github.com/kedacore/keda/v2/pkg/scalers/openstack"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -40,7 +38,13 @@ type openstackMetricMetadata struct {
 
 type openstackMetricAuthenticationMetadata struct {
 	userID                string
+	userName              string
+	userDomain            openstack.Domain
 	password              string
+	projectID             string
+	projectName           string
+	projectDomain         openstack.Domain
+	systemScope           bool
 	authURL               string
 	appCredentialSecret   string
 	appCredentialSecretID string
@@ -76,23 +80,40 @@ func NewOpenstackMetricScaler(ctx context.Context, config *ScalerConfig) (Scaler
 		return nil, fmt.Errorf("error parsing openstack metric authentication metadata: %s", err)
 	}
 
+	switch {
 	// User choose the "application_credentials" authentication method
-	if authMetadata.appCredentialSecretID != "" {
+	case authMetadata.appCredentialSecretID != "":
 		keystoneAuth, err = openstack.NewAppCredentialsAuth(authMetadata.authURL, authMetadata.appCredentialSecretID, authMetadata.appCredentialSecret, openstackMetricMetadata.timeout)
 
 		if err != nil {
 			return nil, fmt.Errorf("error getting openstack credentials for application credentials method: %s", err)
 		}
-	} else {
-		// User choose the "password" authentication method
-		if authMetadata.userID != "" {
-			keystoneAuth, err = openstack.NewPasswordAuth(authMetadata.authURL, authMetadata.userID, authMetadata.password, "", openstackMetricMetadata.timeout)
+	// User choose the "password" authentication method
+	case authMetadata.userID != "":
+		keystoneAuth, err = openstack.NewPasswordAuth(authMetadata.authURL, authMetadata.userID, authMetadata.password, authMetadata.projectID, openstackMetricMetadata.timeout)
 
-			if err != nil {
+		if err != nil {
+			return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
+		}
+	// User choose the "password" authentication method with a user name in a domain
+	case authMetadata.userName != "":
+		keystoneAuth, err = openstack.NewPasswordAuthByName(authMetadata.authURL, authMetadata.userName, authMetadata.userDomain, authMetadata.password, openstackMetricMetadata.timeout)
+
+		if err != nil {
+			return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("no authentication method was provided for OpenStack")
+	}
+
+	// The scope of application credentials is set when they are created, the token of a password is unscoped by default
+	if authMetadata.appCredentialSecretID == "" {
+		if authMetadata.systemScope {
+			keystoneAuth.SetSystemScope()
+		} else if authMetadata.projectID != "" || authMetadata.projectName != "" {
+			if err := keystoneAuth.SetProjectScope(authMetadata.projectID, authMetadata.projectName, authMetadata.projectDomain); err != nil {
 				return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
 			}
-		} else {
-			return nil, fmt.Errorf("no authentication method was provided for OpenStack")
 		}
 	}
 
@@ -178,35 +199,59 @@ func parseOpenstackMetricAuthenticationMetadata(config *ScalerConfig) (openstack
 		return authMeta, fmt.Errorf("authURL doesn't exist in the authParams")
 	}
 
-	if val, ok := authParams["userID"]; ok && val != "" {
-		authMeta.userID = val
+	if authParams["userID"] != "" || authParams["userName"] != "" {
+		authMeta.userID = authParams["userID"]
+		authMeta.userName = authParams["userName"]
+		authMeta.userDomain = openstack.Domain{ID: authParams["userDomainID"], Name: authParams["userDomainName"]}
+
+		if authMeta.userID == "" && authMeta.userDomain == (openstack.Domain{}) {
+			return authMeta, fmt.Errorf("userDomainID or userDomainName is required with userName")
+		}
 
 		if val, ok := authParams["password"]; ok && val != "" {
 			authMeta.password = val
 		} else {
 			return authMeta, fmt.Errorf("password doesn't exist in the authParams")
 		}
-	} else if val, ok := authParams["appCredentialSecret"]; ok && val != "" {
+
+		if val, ok := authParams["systemScope"]; ok && val != "" {
+			systemScope, err := strconv.ParseBool(val)
+			if err != nil {
+				return authMeta, fmt.Errorf("systemScope parsing error %s", err.Error())
+			}
+			authMeta.systemScope = systemScope
+		}
+
+		authMeta.projectID = authParams["projectID"]
+		authMeta.projectName = authParams["projectName"]
+		authMeta.projectDomain = openstack.Domain{ID: authParams["projectDomainID"], Name: authParams["projectDomainName"]}
+
+		if authMeta.projectID == "" && authMeta.projectName != "" && authMeta.projectDomain == (openstack.Domain{}) {
+			return authMeta, fmt.Errorf("projectDomainID or projectDomainName is required with projectName")
+		}
+	} else if val, ok := authParams["appCredentialID"]; ok && val != "" {
 		authMeta.appCredentialSecretID = val
+
+		if val, ok := authParams["appCredentialSecret"]; ok && val != "" {
+			authMeta.appCredentialSecret = val
+		} else {
+			return authMeta, fmt.Errorf("appCredentialSecret doesn't exist in the authParams")
+		}
 	} else {
-		return authMeta, fmt.Errorf("neither userID or appCredentialSecretID exist in the authParams")
+		return authMeta, fmt.Errorf("neither userID or appCredentialID exist in the authParams")
 	}
 
 	return authMeta, nil
 }
 
 func (a *openstackMetricScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricVal := resource.NewQuantity(int64(a.metadata.threshold), resource.DecimalSI)
 	metricName := kedautil.NormalizeString(fmt.Sprintf("openstack-metric-%s", a.metadata.metricID))
 
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(a.metadata.scalerIndex, metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricVal,
-		},
+		Target: GetMetricTargetMili(a.metadata.threshold),
 	}
 
 	metricSpec := v2beta2.MetricSpec{
@@ -225,11 +270,7 @@ func (a *openstackMetricScaler) GetMetrics(ctx context.Context, metricName strin
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, val)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
@@ -252,23 +293,13 @@ func (a *openstackMetricScaler) Close(context.Context) error {
 func (a *openstackMetricScaler) readOpenstackMetrics(ctx context.Context) (float64, error) {
 	var metricURL = a.metadata.metricsURL
 
-	isValid, validationError := a.metricClient.IsTokenValid(ctx)
+	token, tokenRequestError := a.metricClient.GetToken(ctx)
 
-	if validationError != nil {
-		openstackMetricLog.Error(validationError, "Unable to check token validity.")
-		return 0, validationError
+	if tokenRequestError != nil {
+		openstackMetricLog.Error(tokenRequestError, "Unable to get a valid token")
+		return defaultValueWhenError, tokenRequestError
 	}
 
-	if !isValid {
-		tokenRequestError := a.metricClient.RenewToken(ctx)
-		if tokenRequestError != nil {
-			openstackMetricLog.Error(tokenRequestError, "The token being used is invalid")
-			return defaultValueWhenError, tokenRequestError
-		}
-	}
-
-	token := a.metricClient.Token
-
 	openstackMetricsURL, err := url.Parse(metricURL)
 
 	if err != nil {
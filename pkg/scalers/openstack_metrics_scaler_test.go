@@ -34,6 +34,8 @@ var opentsackMetricMetadataTestData = []parseOpenstackMetricMetadataTestData{
 var openstackMetricAuthMetadataTestData = []parseOpenstackMetricAuthMetadataTestData{
 	{authMetadata: map[string]string{"userID": "my-id", "password": "my-password", "authURL": "http://localhost:5000/v3/"}},
 	{authMetadata: map[string]string{"appCredentialID": "my-app-credential-id", "appCredentialSecret": "my-app-credential-secret", "authURL": "http://localhost:5000/v3/"}},
+	{authMetadata: map[string]string{"userName": "my-user", "userDomainName": "Default", "password": "my-password", "projectName": "my-project", "projectDomainName": "Default", "authURL": "http://localhost:5000/v3/"}},
+	{authMetadata: map[string]string{"userID": "my-id", "password": "my-password", "systemScope": "true", "authURL": "http://localhost:5000/v3/"}},
 }
 
 var invalidOpenstackMetricMetadaTestData = []parseOpenstackMetricMetadataTestData{
@@ -85,6 +87,13 @@ var invalidOpenstackMetricAuthMetadataTestData = []parseOpenstackMetricAuthMetad
 	{authMetadata: map[string]string{"appCredentialID": "my-app-credential-id", "authURL": "http://localhost:5000/v3/"}},
 	// Missing authURL
 	{authMetadata: map[string]string{"appCredentialID": "my-app-credential-id", "appCredentialSecret": "my-app-credential-secret"}},
+
+	// Using Keystone v3 domains:
+
+	// Missing userDomainName
+	{authMetadata: map[string]string{"userName": "my-user", "password": "my-password", "authURL": "http://localhost:5000/v3/"}},
+	// Missing projectDomainName
+	{authMetadata: map[string]string{"userName": "my-user", "userDomainName": "Default", "password": "my-password", "projectName": "my-project", "authURL": "http://localhost:5000/v3/"}},
 }
 
 func TestOpenstackMetricsGetMetricsForSpecScaling(t *testing.T) {
@@ -98,6 +107,8 @@ func TestOpenstackMetricsGetMetricsForSpecScaling(t *testing.T) {
 		{nil, &opentsackMetricMetadataTestData[1], &openstackMetricAuthMetadataTestData[1], 5, "s5-openstack-metric-003bb589-166d-439d-8c31-cbf098d863de"},
 		{nil, &opentsackMetricMetadataTestData[2], &openstackMetricAuthMetadataTestData[1], 6, "s6-openstack-metric-003bb589-166d-439d-8c31-cbf098d863de"},
 		{nil, &opentsackMetricMetadataTestData[3], &openstackMetricAuthMetadataTestData[1], 7, "s7-openstack-metric-003bb589-166d-439d-8c31-cbf098d863de"},
+		{nil, &opentsackMetricMetadataTestData[0], &openstackMetricAuthMetadataTestData[2], 8, "s8-openstack-metric-003bb589-166d-439d-8c31-cbf098d863de"},
+		{nil, &opentsackMetricMetadataTestData[0], &openstackMetricAuthMetadataTestData[3], 9, "s9-openstack-metric-003bb589-166d-439d-8c31-cbf098d863de"},
 	}
 
 	for _, testData := range testCases {
@@ -154,6 +165,8 @@ func TestOpenstackMetricAuthenticationInvalidAuthMetadata(t *testing.T) {
 		{nil, &opentsackMetricMetadataTestData[0], &invalidOpenstackMetricAuthMetadataTestData[3], 3, "s3-Missing appCredentialID and appCredentialSecret"},
 		{nil, &opentsackMetricMetadataTestData[0], &invalidOpenstackMetricAuthMetadataTestData[4], 4, "s4-Missing appCredentialSecret"},
 		{nil, &opentsackMetricMetadataTestData[0], &invalidOpenstackMetricAuthMetadataTestData[5], 5, "s5-Missing authURL - application credential"},
+		{nil, &opentsackMetricMetadataTestData[0], &invalidOpenstackMetricAuthMetadataTestData[6], 6, "s6-Missing userDomainName"},
+		{nil, &opentsackMetricMetadataTestData[0], &invalidOpenstackMetricAuthMetadataTestData[7], 7, "s7-Missing projectDomainName"},
 	}
 
 	for _, testData := range testCases {
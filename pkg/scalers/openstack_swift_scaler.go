@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-logr/logr"
 	"github.com/kedacore/keda/v2/pkg/scalers/openstack"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -17,8 +18,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
@@ -44,8 +43,13 @@ type openstackSwiftMetadata struct {
 
 type openstackSwiftAuthenticationMetadata struct {
 	userID              string
+	userName            string
+	userDomain          openstack.Domain
 	password            string
 	projectID           string
+	projectName         string
+	projectDomain       openstack.Domain
+	systemScope         bool
 	authURL             string
 	appCredentialID     string
 	appCredentialSecret string
@@ -55,36 +59,24 @@ type openstackSwiftAuthenticationMetadata struct {
 type openstackSwiftScaler struct {
 	metadata    *openstackSwiftMetadata
 	swiftClient openstack.Client
+	logger      logr.Logger
 }
 
-var openstackSwiftLog = logf.Log.WithName("openstack_swift_scaler")
-
 func (s *openstackSwiftScaler) getOpenstackSwiftContainerObjectCount(ctx context.Context) (int, error) {
 	var containerName = s.metadata.containerName
 	var swiftURL = s.metadata.swiftURL
 
-	isValid, err := s.swiftClient.IsTokenValid(ctx)
+	token, err := s.swiftClient.GetToken(ctx)
 
 	if err != nil {
-		openstackSwiftLog.Error(err, "scaler could not validate the token for authentication")
+		s.logger.Error(err, "error requesting token for authentication")
 		return 0, err
 	}
 
-	if !isValid {
-		err := s.swiftClient.RenewToken(ctx)
-
-		if err != nil {
-			openstackSwiftLog.Error(err, "error requesting token for authentication")
-			return 0, err
-		}
-	}
-
-	token := s.swiftClient.Token
-
 	swiftContainerURL, err := url.Parse(swiftURL)
 
 	if err != nil {
-		openstackSwiftLog.Error(err, fmt.Sprintf("the swiftURL is invalid: %s. You might have forgotten to provide the either 'http' or 'https' in the URL. Check our documentation to see if you missed something", swiftURL))
+		s.logger.Error(err, "The swiftURL is invalid. You might have forgotten to provide the either 'http' or 'https' in the URL. Check our documentation to see if you missed something", "swiftURL", swiftURL)
 		return 0, fmt.Errorf("the swiftURL is invalid: %s", err.Error())
 	}
 
@@ -108,7 +100,7 @@ func (s *openstackSwiftScaler) getOpenstackSwiftContainerObjectCount(ctx context
 	resp, requestError := s.swiftClient.HTTPClient.Do(swiftRequest)
 
 	if requestError != nil {
-		openstackSwiftLog.Error(requestError, fmt.Sprintf("error getting metrics for container '%s'. You probably specified the wrong swift URL or the URL is not reachable", containerName))
+		s.logger.Error(requestError, "Error getting metrics for the container. You probably specified the wrong swift URL or the URL is not reachable", "container", containerName)
 		return 0, requestError
 	}
 
@@ -117,7 +109,7 @@ func (s *openstackSwiftScaler) getOpenstackSwiftContainerObjectCount(ctx context
 	body, readError := ioutil.ReadAll(resp.Body)
 
 	if readError != nil {
-		openstackSwiftLog.Error(readError, "could not read response body from Swift API")
+		s.logger.Error(readError, "could not read response body from Swift API")
 		return 0, readError
 	}
 	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
@@ -136,7 +128,7 @@ func (s *openstackSwiftScaler) getOpenstackSwiftContainerObjectCount(ctx context
 				objectLimit, conversionError := strconv.Atoi(s.metadata.objectLimit)
 
 				if conversionError != nil {
-					openstackSwiftLog.Error(err, fmt.Sprintf("the objectLimit value provided is invalid: %v", s.metadata.objectLimit))
+					s.logger.Error(conversionError, "The objectLimit value provided is invalid", "objectLimit", s.metadata.objectLimit)
 					return 0, conversionError
 				}
 
@@ -159,17 +151,17 @@ func (s *openstackSwiftScaler) getOpenstackSwiftContainerObjectCount(ctx context
 	}
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		openstackSwiftLog.Error(nil, "the retrieved token is not a valid token. Provide the correct auth credentials so the scaler can retrieve a valid access token (Unauthorized)")
+		s.logger.Error(nil, "the retrieved token is not a valid token. Provide the correct auth credentials so the scaler can retrieve a valid access token (Unauthorized)")
 		return 0, fmt.Errorf("the retrieved token is not a valid token. Provide the correct auth credentials so the scaler can retrieve a valid access token (Unauthorized)")
 	}
 
 	if resp.StatusCode == http.StatusForbidden {
-		openstackSwiftLog.Error(nil, "the retrieved token is a valid token, but it does not have sufficient permission to retrieve Swift and/or container metadata (Forbidden)")
+		s.logger.Error(nil, "the retrieved token is a valid token, but it does not have sufficient permission to retrieve Swift and/or container metadata (Forbidden)")
 		return 0, fmt.Errorf("the retrieved token is a valid token, but it does not have sufficient permission to retrieve Swift and/or container metadata (Forbidden)")
 	}
 
 	if resp.StatusCode == http.StatusNotFound {
-		openstackSwiftLog.Error(nil, fmt.Sprintf("the container '%s' does not exist (Not Found)", containerName))
+		s.logger.Error(nil, "The container does not exist (Not Found)", "container", containerName)
 		return 0, fmt.Errorf("the container '%s' does not exist (Not Found)", containerName)
 	}
 
@@ -194,21 +186,34 @@ func NewOpenstackSwiftScaler(ctx context.Context, config *ScalerConfig) (Scaler,
 		return nil, fmt.Errorf("error parsing swift authentication metadata: %s", err)
 	}
 
+	switch {
 	// User chose the "application_credentials" authentication method
-	if authMetadata.appCredentialID != "" {
+	case authMetadata.appCredentialID != "":
 		authRequest, err = openstack.NewAppCredentialsAuth(authMetadata.authURL, authMetadata.appCredentialID, authMetadata.appCredentialSecret, openstackSwiftMetadata.httpClientTimeout)
 		if err != nil {
 			return nil, fmt.Errorf("error getting openstack credentials for application credentials method: %s", err)
 		}
-	} else {
-		// User chose the "password" authentication method
-		if authMetadata.userID != "" {
-			authRequest, err = openstack.NewPasswordAuth(authMetadata.authURL, authMetadata.userID, authMetadata.password, authMetadata.projectID, openstackSwiftMetadata.httpClientTimeout)
-			if err != nil {
-				return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
-			}
-		} else {
-			return nil, fmt.Errorf("no authentication method was provided for OpenStack")
+	// User chose the "password" authentication method
+	case authMetadata.userID != "":
+		authRequest, err = openstack.NewPasswordAuth(authMetadata.authURL, authMetadata.userID, authMetadata.password, authMetadata.projectID, openstackSwiftMetadata.httpClientTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
+		}
+	// User chose the "password" authentication method with a user name in a domain
+	case authMetadata.userName != "":
+		authRequest, err = openstack.NewPasswordAuthByName(authMetadata.authURL, authMetadata.userName, authMetadata.userDomain, authMetadata.password, openstackSwiftMetadata.httpClientTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("no authentication method was provided for OpenStack")
+	}
+
+	if authMetadata.appCredentialID == "" {
+		if authMetadata.systemScope {
+			authRequest.SetSystemScope()
+		} else if err := authRequest.SetProjectScope(authMetadata.projectID, authMetadata.projectName, authMetadata.projectDomain); err != nil {
+			return nil, fmt.Errorf("error getting openstack credentials for password method: %s", err)
 		}
 	}
 
@@ -235,6 +240,7 @@ func NewOpenstackSwiftScaler(ctx context.Context, config *ScalerConfig) (Scaler,
 	return &openstackSwiftScaler{
 		metadata:    openstackSwiftMetadata,
 		swiftClient: swiftClient,
+		logger:      InitializeLogger(config, "openstack_swift_scaler"),
 	}, nil
 }
 
@@ -319,8 +325,14 @@ func parseOpenstackSwiftAuthenticationMetadata(config *ScalerConfig) (*openstack
 		authMeta.regionName = ""
 	}
 
-	if config.AuthParams["userID"] != "" {
+	if config.AuthParams["userID"] != "" || config.AuthParams["userName"] != "" {
 		authMeta.userID = config.AuthParams["userID"]
+		authMeta.userName = config.AuthParams["userName"]
+		authMeta.userDomain = openstack.Domain{ID: config.AuthParams["userDomainID"], Name: config.AuthParams["userDomainName"]}
+
+		if authMeta.userID == "" && authMeta.userDomain == (openstack.Domain{}) {
+			return nil, fmt.Errorf("userDomainID or userDomainName is required with userName")
+		}
 
 		if config.AuthParams["password"] != "" {
 			authMeta.password = config.AuthParams["password"]
@@ -328,9 +340,27 @@ func parseOpenstackSwiftAuthenticationMetadata(config *ScalerConfig) (*openstack
 			return nil, fmt.Errorf("password doesn't exist in the authParams")
 		}
 
-		if config.AuthParams["projectID"] != "" {
+		if config.AuthParams["systemScope"] != "" {
+			systemScope, err := strconv.ParseBool(config.AuthParams["systemScope"])
+			if err != nil {
+				return nil, fmt.Errorf("systemScope parsing error %s", err.Error())
+			}
+			authMeta.systemScope = systemScope
+		}
+
+		authMeta.projectDomain = openstack.Domain{ID: config.AuthParams["projectDomainID"], Name: config.AuthParams["projectDomainName"]}
+
+		switch {
+		case authMeta.systemScope:
+		case config.AuthParams["projectID"] != "":
 			authMeta.projectID = config.AuthParams["projectID"]
-		} else {
+		case config.AuthParams["projectName"] != "":
+			authMeta.projectName = config.AuthParams["projectName"]
+
+			if authMeta.projectDomain == (openstack.Domain{}) {
+				return nil, fmt.Errorf("projectDomainID or projectDomainName is required with projectName")
+			}
+		default:
 			return nil, fmt.Errorf("projectID doesn't exist in the authParams")
 		}
 	} else {
@@ -368,7 +398,7 @@ func (s *openstackSwiftScaler) GetMetrics(ctx context.Context, metricName string
 	objectCount, err := s.getOpenstackSwiftContainerObjectCount(ctx)
 
 	if err != nil {
-		openstackSwiftLog.Error(err, "error getting objectCount")
+		s.logger.Error(err, "error getting objectCount")
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"github.com/kedacore/keda/v2/pkg/scalers/openstack"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,6 +45,8 @@ var openstackSwiftMetadataTestData = []parseOpenstackSwiftMetadataTestData{
 var openstackSwiftAuthMetadataTestData = []parseOpenstackSwiftAuthMetadataTestData{
 	{authMetadata: map[string]string{"userID": "my-id", "password": "my-password", "projectID": "my-project-id", "authURL": "http://localhost:5000/v3/"}},
 	{authMetadata: map[string]string{"appCredentialID": "my-app-credential-id", "appCredentialSecret": "my-app-credential-secret", "authURL": "http://localhost:5000/v3/"}},
+	{authMetadata: map[string]string{"userName": "my-user", "userDomainName": "Default", "password": "my-password", "projectName": "my-project", "projectDomainID": "default", "authURL": "http://localhost:5000/v3/"}},
+	{authMetadata: map[string]string{"userName": "my-user", "userDomainID": "default", "password": "my-password", "systemScope": "true", "authURL": "http://localhost:5000/v3/"}},
 }
 
 var invalidOpenstackSwiftMetadataTestData = []parseOpenstackSwiftMetadataTestData{
@@ -77,6 +80,15 @@ var invalidOpenstackSwiftAuthMetadataTestData = []parseOpenstackSwiftAuthMetadat
 	{authMetadata: map[string]string{"appCredentialID": "my-app-credential-id", "authURL": "http://localhost:5000/v3/"}},
 	// Missing authURL
 	{authMetadata: map[string]string{"appCredentialID": "my-app-credential-id", "appCredentialSecret": "my-app-credential-secret"}},
+
+	// Using Keystone v3 domains:
+
+	// Missing userDomainName
+	{authMetadata: map[string]string{"userName": "my-user", "password": "my-password", "projectID": "my-project-id", "authURL": "http://localhost:5000/v3/"}},
+	// Missing projectDomainName
+	{authMetadata: map[string]string{"userName": "my-user", "userDomainName": "Default", "password": "my-password", "projectName": "my-project", "authURL": "http://localhost:5000/v3/"}},
+	// systemScope is not a boolean value
+	{authMetadata: map[string]string{"userID": "my-id", "password": "my-password", "systemScope": "all", "authURL": "http://localhost:5000/v3/"}},
 }
 
 func TestOpenstackSwiftGetMetricSpecForScaling(t *testing.T) {
@@ -96,6 +108,9 @@ func TestOpenstackSwiftGetMetricSpecForScaling(t *testing.T) {
 		{nil, &openstackSwiftMetadataTestData[4], &openstackSwiftAuthMetadataTestData[1], 4, "s4-openstack-swift-my-container"},
 		{nil, &openstackSwiftMetadataTestData[5], &openstackSwiftAuthMetadataTestData[1], 5, "s5-openstack-swift-my-container"},
 		{nil, &openstackSwiftMetadataTestData[6], &openstackSwiftAuthMetadataTestData[1], 6, "s6-openstack-swift-my-container"},
+
+		{nil, &openstackSwiftMetadataTestData[1], &openstackSwiftAuthMetadataTestData[2], 0, "s0-openstack-swift-my-container"},
+		{nil, &openstackSwiftMetadataTestData[1], &openstackSwiftAuthMetadataTestData[3], 1, "s1-openstack-swift-my-container"},
 	}
 
 	for _, testData := range testCases {
@@ -109,7 +124,7 @@ func TestOpenstackSwiftGetMetricSpecForScaling(t *testing.T) {
 			t.Fatal("Could not parse auth metadata:", err)
 		}
 
-		mockSwiftScaler := openstackSwiftScaler{meta, openstack.Client{}}
+		mockSwiftScaler := openstackSwiftScaler{meta, openstack.Client{}, logr.DiscardLogger{}}
 
 		metricSpec := mockSwiftScaler.GetMetricSpecForScaling(context.Background())
 
@@ -147,6 +162,9 @@ func TestParseOpenstackSwiftAuthenticationMetadataForInvalidCases(t *testing.T)
 		{nil, &parseOpenstackSwiftMetadataTestData{}, &invalidOpenstackSwiftAuthMetadataTestData[4], 4, "s4-missing appCredentialID"},
 		{nil, &parseOpenstackSwiftMetadataTestData{}, &invalidOpenstackSwiftAuthMetadataTestData[5], 5, "s5-missing appCredentialSecret"},
 		{nil, &parseOpenstackSwiftMetadataTestData{}, &invalidOpenstackSwiftAuthMetadataTestData[6], 6, "s6-missing authURL for application credentials method"},
+		{nil, &parseOpenstackSwiftMetadataTestData{}, &invalidOpenstackSwiftAuthMetadataTestData[7], 7, "s7-missing userDomainName"},
+		{nil, &parseOpenstackSwiftMetadataTestData{}, &invalidOpenstackSwiftAuthMetadataTestData[8], 8, "s8-missing projectDomainName"},
+		{nil, &parseOpenstackSwiftMetadataTestData{}, &invalidOpenstackSwiftAuthMetadataTestData[9], 9, "s9-systemScope is not a boolean value"},
 	}
 
 	for _, testData := range testCases {
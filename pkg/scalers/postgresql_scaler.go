@@ -4,16 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
 
+	"github.com/go-logr/logr"
 	// PostreSQL drive required for this scaler
 	_ "github.com/lib/pq"
 	"k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -21,10 +18,11 @@ import (
 type postgreSQLScaler struct {
 	metadata   *postgreSQLMetadata
 	connection *sql.DB
+	logger     logr.Logger
 }
 
 type postgreSQLMetadata struct {
-	targetQueryValue int
+	targetQueryValue float64
 	connection       string
 	userName         string
 	password         string
@@ -34,25 +32,26 @@ type postgreSQLMetadata struct {
 	dbName           string
 	sslmode          string
 	metricName       string
+	missingValue     *missingValuePolicy
 	scalerIndex      int
 }
 
-var postgreSQLLog = logf.Log.WithName("postgreSQL_scaler")
-
 // NewPostgreSQLScaler creates a new postgreSQL scaler
 func NewPostgreSQLScaler(config *ScalerConfig) (Scaler, error) {
+	logger := InitializeLogger(config, "postgreSQL_scaler")
 	meta, err := parsePostgreSQLMetadata(config)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing postgreSQL metadata: %s", err)
 	}
 
-	conn, err := getConnection(meta)
+	conn, err := getConnection(meta, logger)
 	if err != nil {
 		return nil, fmt.Errorf("error establishing postgreSQL connection: %s", err)
 	}
 	return &postgreSQLScaler{
 		metadata:   meta,
 		connection: conn,
+		logger:     logger,
 	}, nil
 }
 
@@ -65,15 +64,11 @@ func parsePostgreSQLMetadata(config *ScalerConfig) (*postgreSQLMetadata, error)
 		return nil, fmt.Errorf("no query given")
 	}
 
-	if val, ok := config.TriggerMetadata["targetQueryValue"]; ok {
-		targetQueryValue, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("queryValue parsing error %s", err.Error())
-		}
-		meta.targetQueryValue = targetQueryValue
-	} else {
-		return nil, fmt.Errorf("no targetQueryValue given")
+	targetQueryValue, err := getFloatMetadataValue(config.TriggerMetadata, "targetQueryValue", true, 0)
+	if err != nil {
+		return nil, err
 	}
+	meta.targetQueryValue = targetQueryValue
 
 	switch {
 	case config.AuthParams["connection"] != "":
@@ -120,11 +115,17 @@ func parsePostgreSQLMetadata(config *ScalerConfig) (*postgreSQLMetadata, error)
 	} else {
 		meta.metricName = kedautil.NormalizeString("postgresql")
 	}
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.missingValue = missingValue
+
 	meta.scalerIndex = config.ScalerIndex
 	return &meta, nil
 }
 
-func getConnection(meta *postgreSQLMetadata) (*sql.DB, error) {
+func getConnection(meta *postgreSQLMetadata, logger logr.Logger) (*sql.DB, error) {
 	var connStr string
 	if meta.connection != "" {
 		connStr = meta.connection
@@ -141,12 +142,12 @@ func getConnection(meta *postgreSQLMetadata) (*sql.DB, error) {
 	}
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		postgreSQLLog.Error(err, fmt.Sprintf("Found error opening postgreSQL: %s", err))
+		logger.Error(err, "Found error opening postgreSQL")
 		return nil, err
 	}
 	err = db.Ping()
 	if err != nil {
-		postgreSQLLog.Error(err, fmt.Sprintf("Found error pinging postgreSQL: %s", err))
+		logger.Error(err, "Found error pinging postgreSQL")
 		return nil, err
 	}
 	return db, nil
@@ -156,7 +157,7 @@ func getConnection(meta *postgreSQLMetadata) (*sql.DB, error) {
 func (s *postgreSQLScaler) Close(context.Context) error {
 	err := s.connection.Close()
 	if err != nil {
-		postgreSQLLog.Error(err, "Error closing postgreSQL connection")
+		s.logger.Error(err, "Error closing postgreSQL connection")
 		return err
 	}
 	return nil
@@ -172,28 +173,32 @@ func (s *postgreSQLScaler) IsActive(ctx context.Context) (bool, error) {
 	return messages > 0, nil
 }
 
-func (s *postgreSQLScaler) getActiveNumber(ctx context.Context) (int, error) {
-	var id int
+func (s *postgreSQLScaler) getActiveNumber(ctx context.Context) (float64, error) {
+	var id sql.NullFloat64
 	err := s.connection.QueryRowContext(ctx, s.metadata.query).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		s.logger.Error(err, "Could not query postgreSQL")
+		return 0, fmt.Errorf("could not query postgreSQL: %s", err)
+	default:
+		err = errSQLNullResult
+	}
+
+	result, err := s.metadata.missingValue.resolve(id.Float64, id.Valid, err)
 	if err != nil {
-		postgreSQLLog.Error(err, fmt.Sprintf("could not query postgreSQL: %s", err))
 		return 0, fmt.Errorf("could not query postgreSQL: %s", err)
 	}
-	return id, nil
+	return result, nil
 }
 
 // GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
 func (s *postgreSQLScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetQueryValue := resource.NewQuantity(int64(s.metadata.targetQueryValue), resource.DecimalSI)
-
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetQueryValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.targetQueryValue),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -208,11 +213,7 @@ func (s *postgreSQLScaler) GetMetrics(ctx context.Context, metricName string, me
 		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting postgreSQL: %s", err)
 	}
 
-	metric := external_metrics.ExternalMetricValue{
-		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(num), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
-	}
+	metric := GenerateMetricInMili(metricName, num)
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
@@ -1,6 +1,7 @@
 package scalers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,14 +11,15 @@ import (
 	url_pkg "net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 
 	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
@@ -28,18 +30,43 @@ const (
 	promMetricName    = "metricName"
 	promQuery         = "query"
 	promThreshold     = "threshold"
+	promRecordingRule = "recordingRule"
+	promRulerAddress  = "rulerAddress"
+	promRulerNS       = "rulerNamespace"
+
+	promAlertmanagerAddress = "alertmanagerAddress"
+	promAlertName           = "alertName"
+
+	defaultPromRulerNS = "keda"
 )
 
 type prometheusScaler struct {
 	metadata   *prometheusMetadata
 	httpClient *http.Client
+
+	// ruleRegistered is set once the recording rule is registered in the ruler
+	ruleLock       sync.Mutex
+	ruleRegistered bool
 }
 
 type prometheusMetadata struct {
 	serverAddress string
 	metricName    string
 	query         string
-	threshold     int
+	threshold     float64
+
+	// recordingRule is the series queried instead of query, when rulerAddress is set
+	// the rule recording query in it is registered with the Cortex/Mimir ruler API
+	recordingRule  string
+	rulerAddress   string
+	rulerNamespace string
+
+	// the activation is suppressed while alertName is silenced or inhibited in the Alertmanager of alertmanagerAddress
+	alertmanagerAddress string
+	alertName           string
+
+	// missingValue is how the empty results are handled, they are read as 0 by default
+	missingValue *missingValuePolicy
 
 	// bearer auth
 	enableBearerAuth bool
@@ -50,6 +77,9 @@ type prometheusMetadata struct {
 	username        string
 	password        string // +optional
 
+	// oauth2 auth, the token of the client credentials is sent as bearer token
+	oauth2 *authentication.OAuth2ClientCredentials
+
 	// client certification
 	enableTLS bool
 	cert      string
@@ -80,21 +110,56 @@ func NewPrometheusScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing prometheus metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient, err := newPrometheusHTTPClient(config, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prometheusScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+// newPrometheusHTTPClient creates the http client with the client certificate of the metadata
+func newPrometheusHTTPClient(config *ScalerConfig, meta *prometheusMetadata) (*http.Client, error) {
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
 
 	if meta.ca != "" || meta.enableTLS {
-		config, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
-		if err != nil || config == nil {
+		tlsConfig, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
+		if err != nil || tlsConfig == nil {
 			return nil, fmt.Errorf("error creating the TLS config: %s", err)
 		}
 
-		httpClient.Transport = &http.Transport{TLSClientConfig: config}
+		httpClient = createTLSHTTPClient(config, config.GlobalHTTPTimeout, tlsConfig)
 	}
+	if meta.oauth2 != nil {
+		httpClient.Transport = meta.oauth2.Transport(httpClient.Transport)
+	}
+	return httpClient, nil
+}
 
-	return &prometheusScaler{
-		metadata:   meta,
-		httpClient: httpClient,
-	}, nil
+// newPrometheusQuery creates the prometheus scaler executing a query built by another scaler, the server address and
+// the authentication are parsed like the prometheus trigger does
+func newPrometheusQuery(config *ScalerConfig, query, metricName string) (*prometheusScaler, error) {
+	metadata := make(map[string]string, len(config.TriggerMetadata)+2)
+	for key, value := range config.TriggerMetadata {
+		metadata[key] = value
+	}
+	metadata[promQuery] = query
+	metadata[promMetricName] = metricName
+
+	queryConfig := *config
+	queryConfig.TriggerMetadata = metadata
+	meta, err := parsePrometheusMetadata(&queryConfig)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := newPrometheusHTTPClient(&queryConfig, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &prometheusScaler{metadata: meta, httpClient: httpClient}, nil
 }
 
 func parsePrometheusMetadata(config *ScalerConfig) (*prometheusMetadata, error) {
@@ -108,7 +173,33 @@ func parsePrometheusMetadata(config *ScalerConfig) (*prometheusMetadata, error)
 
 	if val, ok := config.TriggerMetadata[promQuery]; ok && val != "" {
 		meta.query = val
-	} else {
+	}
+
+	if val, ok := config.TriggerMetadata[promRecordingRule]; ok && val != "" {
+		meta.recordingRule = val
+	}
+
+	if val, ok := config.TriggerMetadata[promRulerAddress]; ok && val != "" {
+		if meta.recordingRule == "" {
+			return nil, fmt.Errorf("no %s given for %s", promRecordingRule, promRulerAddress)
+		}
+		meta.rulerAddress = strings.TrimSuffix(val, "/")
+		meta.rulerNamespace = defaultPromRulerNS
+		if ns, ok := config.TriggerMetadata[promRulerNS]; ok && ns != "" {
+			meta.rulerNamespace = ns
+		}
+	}
+
+	if val, ok := config.TriggerMetadata[promAlertmanagerAddress]; ok && val != "" {
+		meta.alertmanagerAddress = strings.TrimSuffix(val, "/")
+		meta.alertName = config.TriggerMetadata[promAlertName]
+		if meta.alertName == "" {
+			return nil, fmt.Errorf("no %s given for %s", promAlertName, promAlertmanagerAddress)
+		}
+	}
+
+	// the query is only evaluated by the ruler when a recording rule is registered
+	if meta.query == "" && (meta.recordingRule == "" || meta.rulerAddress != "") {
 		return nil, fmt.Errorf("no %s given", promQuery)
 	}
 
@@ -118,14 +209,17 @@ func parsePrometheusMetadata(config *ScalerConfig) (*prometheusMetadata, error)
 		return nil, fmt.Errorf("no %s given", promMetricName)
 	}
 
-	if val, ok := config.TriggerMetadata[promThreshold]; ok && val != "" {
-		t, err := strconv.Atoi(val)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing %s: %s", promThreshold, err)
-		}
+	threshold, err := getFloatMetadataValue(config.TriggerMetadata, promThreshold, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	meta.threshold = threshold
 
-		meta.threshold = t
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
 	}
+	meta.missingValue = missingValue
 
 	meta.scalerIndex = config.ScalerIndex
 
@@ -174,10 +268,19 @@ func parsePrometheusMetadata(config *ScalerConfig) (*prometheusMetadata, error)
 
 			meta.key = config.AuthParams["key"]
 			meta.enableTLS = true
+		case authentication.OAuth2AuthType:
+			oauth2, err := authentication.ParseOAuth2ClientCredentials(config.AuthParams)
+			if err != nil {
+				return nil, err
+			}
+			meta.oauth2 = oauth2
 		default:
 			return nil, fmt.Errorf("err incorrect value for authMode is given: %s", t)
 		}
 	}
+	if meta.oauth2 != nil && (meta.enableBearerAuth || meta.enableBasicAuth) {
+		return nil, errors.New("oauth2 can not be set with bearer or basic authentication")
+	}
 
 	if len(config.AuthParams["ca"]) > 0 {
 		meta.ca = config.AuthParams["ca"]
@@ -193,24 +296,32 @@ func (s *prometheusScaler) IsActive(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
+	if val > 0 && s.metadata.alertmanagerAddress != "" {
+		suppressed, err := s.isAlertSuppressed(ctx)
+		if err != nil {
+			// the activation isn't suppressed if Alertmanager is unavailable
+			prometheusLog.Error(err, "error checking the silences of the alert", "alertName", s.metadata.alertName)
+		} else if suppressed {
+			prometheusLog.V(1).Info("Activation suppressed, the alert is silenced or inhibited", "alertName", s.metadata.alertName)
+			return false, nil
+		}
+	}
+
 	return val > 0, nil
 }
 
 func (s *prometheusScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
 
 func (s *prometheusScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
-	targetMetricValue := resource.NewQuantity(int64(s.metadata.threshold), resource.DecimalSI)
 	metricName := kedautil.NormalizeString(fmt.Sprintf("prometheus-%s", s.metadata.metricName))
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
 		},
-		Target: v2beta2.MetricTarget{
-			Type:         v2beta2.AverageValueMetricType,
-			AverageValue: targetMetricValue,
-		},
+		Target: GetMetricTargetMili(s.metadata.threshold),
 	}
 	metricSpec := v2beta2.MetricSpec{
 		External: externalMetric, Type: externalMetricType,
@@ -218,56 +329,193 @@ func (s *prometheusScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Me
 	return []v2beta2.MetricSpec{metricSpec}
 }
 
-func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error) {
-	t := time.Now().UTC().Format(time.RFC3339)
-	queryEscaped := url_pkg.QueryEscape(s.metadata.query)
-	url := fmt.Sprintf("%s/api/v1/query?query=%s&time=%s", s.metadata.serverAddress, queryEscaped, t)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return -1, err
+// promQL returns the expression queried in each polling interval
+func (m *prometheusMetadata) promQL() string {
+	if m.recordingRule != "" {
+		return m.recordingRule
 	}
+	return m.query
+}
 
+func (s *prometheusScaler) setAuthHeaders(req *http.Request) {
 	if s.metadata.enableBearerAuth {
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", s.metadata.bearerToken))
 	} else if s.metadata.enableBasicAuth {
 		req.SetBasicAuth(s.metadata.username, s.metadata.password)
 	}
+}
+
+type promRuleGroup struct {
+	Name  string     `json:"name"`
+	Rules []promRule `json:"rules"`
+}
+
+type promRule struct {
+	Record string `json:"record"`
+	Expr   string `json:"expr"`
+}
+
+// registerRecordingRule creates or replaces the rule group of the recording rule with the ruler API,
+// the ruler evaluates the query once for all the ScaledObjects querying the recorded series
+func (s *prometheusScaler) registerRecordingRule(ctx context.Context) error {
+	s.ruleLock.Lock()
+	defer s.ruleLock.Unlock()
+	if s.ruleRegistered {
+		return nil
+	}
+
+	body, err := yaml.Marshal(promRuleGroup{
+		Name:  fmt.Sprintf("keda-%s", s.metadata.recordingRule),
+		Rules: []promRule{{Record: s.metadata.recordingRule, Expr: s.metadata.query}},
+	})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s", s.metadata.rulerAddress, url_pkg.PathEscape(s.metadata.rulerNamespace))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+	s.setAuthHeaders(req)
 
 	r, err := s.httpClient.Do(req)
 	if err != nil {
-		return -1, err
+		return err
+	}
+	defer r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		b, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("prometheus ruler api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	s.ruleRegistered = true
+	return nil
+}
+
+type alertmanagerSilence struct {
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+type alertmanagerAlert struct {
+	Status struct {
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+// isAlertSuppressed returns true if an active silence matches the alert or if the firing alert is inhibited,
+// eg. during a planned maintenance, it uses the Alertmanager API v2
+func (s *prometheusScaler) isAlertSuppressed(ctx context.Context) (bool, error) {
+	filter := url_pkg.QueryEscape(fmt.Sprintf("alertname=%q", s.metadata.alertName))
+
+	var silences []alertmanagerSilence
+	if err := s.getAlertmanager(ctx, "/api/v2/silences?filter="+filter, &silences); err != nil {
+		return false, err
+	}
+	for _, silence := range silences {
+		if silence.Status.State == "active" {
+			return true, nil
+		}
+	}
+
+	var alerts []alertmanagerAlert
+	if err := s.getAlertmanager(ctx, "/api/v2/alerts?active=false&silenced=false&inhibited=true&filter="+filter, &alerts); err != nil {
+		return false, err
+	}
+	for _, alert := range alerts {
+		if len(alert.Status.InhibitedBy) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *prometheusScaler) getAlertmanager(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.metadata.alertmanagerAddress+path, nil)
+	if err != nil {
+		return err
 	}
+	s.setAuthHeaders(req)
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
 
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return -1, err
+		return err
+	}
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return fmt.Errorf("alertmanager api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error) {
+	value, _, err := s.executePromQuery(ctx)
+	return value, err
+}
+
+// executePromQuery returns the value of the query and the timestamp of its sample, now when the result is empty
+func (s *prometheusScaler) executePromQuery(ctx context.Context) (float64, time.Time, error) {
+	if s.metadata.rulerAddress != "" {
+		if err := s.registerRecordingRule(ctx); err != nil {
+			return -1, time.Time{}, fmt.Errorf("error registering recording rule %s: %s", s.metadata.recordingRule, err)
+		}
+	}
+
+	t := time.Now().UTC().Format(time.RFC3339)
+	queryEscaped := url_pkg.QueryEscape(s.metadata.promQL())
+	url := fmt.Sprintf("%s/api/v1/query?query=%s&time=%s", s.metadata.serverAddress, queryEscaped, t)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return -1, time.Time{}, err
+	}
+
+	s.setAuthHeaders(req)
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, time.Time{}, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, time.Time{}, err
 	}
 	r.Body.Close()
 
 	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
-		return -1, fmt.Errorf("prometheus query api returned error. status: %d response: %s", r.StatusCode, string(b))
+		return -1, time.Time{}, fmt.Errorf("prometheus query api returned error. status: %d response: %s", r.StatusCode, string(b))
 	}
 
 	var result promQueryResult
 	err = json.Unmarshal(b, &result)
 	if err != nil {
-		return -1, err
+		return -1, time.Time{}, err
 	}
 
 	var v float64 = -1
 
 	// allow for zero element or single element result sets
 	if len(result.Data.Result) == 0 {
-		return 0, nil
+		value, err := s.metadata.missingValue.resolve(0, false, nil)
+		return value, time.Now(), err
 	} else if len(result.Data.Result) > 1 {
-		return -1, fmt.Errorf("prometheus query %s returned multiple elements", s.metadata.query)
+		return -1, time.Time{}, fmt.Errorf("prometheus query %s returned multiple elements", s.metadata.promQL())
 	}
 
 	valueLen := len(result.Data.Result[0].Value)
 	if valueLen == 0 {
-		return 0, nil
+		value, err := s.metadata.missingValue.resolve(0, false, nil)
+		return value, time.Now(), err
 	} else if valueLen < 2 {
-		return -1, fmt.Errorf("prometheus query %s didn't return enough values", s.metadata.query)
+		return -1, time.Time{}, fmt.Errorf("prometheus query %s didn't return enough values", s.metadata.promQL())
 	}
 
 	val := result.Data.Result[0].Value[1]
@@ -276,15 +524,22 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 		v, err = strconv.ParseFloat(s, 64)
 		if err != nil {
 			prometheusLog.Error(err, "Error converting prometheus value", "prometheus_value", s)
-			return -1, err
+			return -1, time.Time{}, err
 		}
 	}
 
-	return v, nil
+	// the sample is [<unix time in seconds>, "<value>"]
+	timestamp := time.Now()
+	if seconds, ok := result.Data.Result[0].Value[0].(float64); ok {
+		timestamp = time.Unix(0, int64(seconds*float64(time.Second)))
+	}
+
+	v, err = s.metadata.missingValue.resolve(v, true, nil)
+	return v, timestamp, err
 }
 
 func (s *prometheusScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	val, err := s.ExecutePromQuery(ctx)
+	val, timestamp, err := s.executePromQuery(ctx)
 	if err != nil {
 		prometheusLog.Error(err, "error executing prometheus query")
 		return []external_metrics.ExternalMetricValue{}, err
@@ -292,8 +547,8 @@ func (s *prometheusScaler) GetMetrics(ctx context.Context, metricName string, me
 
 	metric := external_metrics.ExternalMetricValue{
 		MetricName: metricName,
-		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
-		Timestamp:  metav1.Now(),
+		Value:      *newMilliQuantity(val),
+		Timestamp:  metav1.NewTime(timestamp),
 	}
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
@@ -2,12 +2,17 @@ package scalers
 
 import (
 	"context"
+	"encoding/pem"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 type parsePrometheusMetadataTestData struct {
@@ -35,6 +40,18 @@ var testPromMetadata = []parsePrometheusMetadataTestData{
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": ""}, true},
 	// all properly formed, default disableScaleToZero
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up"}, false},
+	// pre-aggregated series without query
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "recordingRule": "job:http_requests:rate5m"}, false},
+	// recording rule registered in the ruler
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "sum(rate(http_requests_total[5m]))", "recordingRule": "job:http_requests:rate5m", "rulerAddress": "http://localhost:9009/api/v1/rules"}, false},
+	// ruler without query
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "recordingRule": "job:http_requests:rate5m", "rulerAddress": "http://localhost:9009/api/v1/rules"}, true},
+	// ruler without recordingRule
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "rulerAddress": "http://localhost:9009/api/v1/rules"}, true},
+	// activation suppressed while the alert is silenced
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "alertmanagerAddress": "http://localhost:9093", "alertName": "QueueBacklog"}, false},
+	// alertmanagerAddress without alertName
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "alertmanagerAddress": "http://localhost:9093"}, true},
 }
 
 var prometheusMetricIdentifiers = []prometheusMetricIdentifier{
@@ -69,6 +86,14 @@ var testPrometheusAuthMetadata = []prometheusAuthMetadataTestData{
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "tls, basic"}, map[string]string{"ca": "caaa", "cert": "ceert", "key": "keey", "username": "user", "password": "pass"}, false},
 
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "tls,basic"}, map[string]string{"username": "user", "password": "pass"}, true},
+	// success oauth2
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "oauth2"}, map[string]string{"tokenURL": "http://idp/token", "clientID": "keda", "clientSecret": "secret"}, false},
+	// success tls and oauth2
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "tls,oauth2"}, map[string]string{"cert": "ceert", "key": "keey", "tokenURL": "http://idp/token", "clientID": "keda", "clientSecret": "secret"}, false},
+	// fail oauth2 without clientSecret
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "oauth2"}, map[string]string{"tokenURL": "http://idp/token", "clientID": "keda"}, true},
+	// fail oauth2 and bearer
+	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "oauth2,bearer"}, map[string]string{"bearerToken": "tooooken", "tokenURL": "http://idp/token", "clientID": "keda", "clientSecret": "secret"}, true},
 }
 
 func TestPrometheusParseMetadata(t *testing.T) {
@@ -206,3 +231,127 @@ func TestPrometheusScalerExecutePromQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestPrometheusScalerRegistersRecordingRule(t *testing.T) {
+	var registrations int
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/api/v1/rules/keda":
+			registrations++
+			body, _ := ioutil.ReadAll(request.Body)
+			assert.Equal(t, "application/yaml", request.Header.Get("Content-Type"))
+			assert.Equal(t, "name: keda-job:http_requests:rate5m\nrules:\n- expr: sum(rate(http_requests_total[5m]))\n  record: job:http_requests:rate5m\n", string(body))
+			writer.WriteHeader(http.StatusAccepted)
+		case "/api/v1/query":
+			assert.Equal(t, "job:http_requests:rate5m", request.URL.Query().Get("query"))
+			_, _ = writer.Write([]byte(`{"data":{"result":[{"value": ["1", "7"]}]}}`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	scaler := prometheusScaler{
+		metadata: &prometheusMetadata{
+			serverAddress:  server.URL,
+			query:          "sum(rate(http_requests_total[5m]))",
+			recordingRule:  "job:http_requests:rate5m",
+			rulerAddress:   server.URL + "/api/v1/rules",
+			rulerNamespace: "keda",
+		},
+		httpClient: http.DefaultClient,
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := scaler.ExecutePromQuery(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, float64(7), value)
+	}
+	assert.Equal(t, 1, registrations)
+}
+
+type prometheusSilenceTestData struct {
+	name     string
+	silences string
+	alerts   string
+	status   int
+	isActive bool
+}
+
+var testPrometheusSilences = []prometheusSilenceTestData{
+	{"no silence", `[]`, `[]`, http.StatusOK, true},
+	{"expired silence", `[{"status": {"state": "expired"}}]`, `[]`, http.StatusOK, true},
+	{"active silence", `[{"status": {"state": "expired"}}, {"status": {"state": "active"}}]`, `[]`, http.StatusOK, false},
+	{"inhibited alert", `[]`, `[{"status": {"state": "suppressed", "inhibitedBy": ["f3c8"]}}]`, http.StatusOK, false},
+	// the activation isn't suppressed if alertmanager is unavailable
+	{"alertmanager error", `[]`, `[]`, http.StatusInternalServerError, true},
+}
+
+func TestPrometheusScalerSuppressesSilencedActivation(t *testing.T) {
+	for _, testData := range testPrometheusSilences {
+		t.Run(testData.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				switch request.URL.Path {
+				case "/api/v1/query":
+					_, _ = writer.Write([]byte(`{"data":{"result":[{"value": ["1", "7"]}]}}`))
+				case "/alertmanager/api/v2/silences":
+					assert.Equal(t, `alertname="QueueBacklog"`, request.URL.Query().Get("filter"))
+					writer.WriteHeader(testData.status)
+					_, _ = writer.Write([]byte(testData.silences))
+				case "/alertmanager/api/v2/alerts":
+					assert.Equal(t, "true", request.URL.Query().Get("inhibited"))
+					_, _ = writer.Write([]byte(testData.alerts))
+				default:
+					writer.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			scaler := prometheusScaler{
+				metadata: &prometheusMetadata{
+					serverAddress:       server.URL,
+					query:               "up",
+					alertmanagerAddress: server.URL + "/alertmanager",
+					alertName:           "QueueBacklog",
+				},
+				httpClient: http.DefaultClient,
+			}
+
+			isActive, err := scaler.IsActive(context.TODO())
+			assert.NoError(t, err)
+			assert.Equal(t, testData.isActive, isActive)
+		})
+	}
+}
+
+func TestPrometheusScalerTLSEgressPolicy(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"data":{"result":[{"value": ["1", "2"]}]}}`))
+	}))
+	defer server.Close()
+
+	policy, err := kedautil.ParseEgressPolicy([]byte("namespaces:\n  restricted: [api.example.com]\n"))
+	assert.NoError(t, err)
+	kedautil.SetEgressPolicy(policy)
+	defer kedautil.SetEgressPolicy(nil)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	for namespace, allowed := range map[string]bool{"restricted": false, "unrestricted": true} {
+		scaler, err := NewPrometheusScaler(&ScalerConfig{
+			Namespace:         namespace,
+			TriggerMetadata:   map[string]string{"serverAddress": server.URL, "metricName": "http_requests_total", "threshold": "100", "query": "up", "authModes": "bearer"},
+			AuthParams:        map[string]string{"bearerToken": "token", "ca": string(ca)},
+			GlobalHTTPTimeout: time.Second,
+		})
+		assert.NoError(t, err)
+
+		value, err := scaler.(*prometheusScaler).ExecutePromQuery(context.TODO())
+		if allowed {
+			assert.NoError(t, err, namespace)
+			assert.Equal(t, float64(2), value, namespace)
+		} else {
+			assert.Error(t, err, namespace)
+			assert.Contains(t, err.Error(), "not allowed by the egress policy", namespace)
+		}
+	}
+}
@@ -34,10 +34,23 @@ const (
 	rabbitValueTriggerConfigName = "value"
 	rabbitModeQueueLength        = "QueueLength"
 	rabbitModeMessageRate        = "MessageRate"
+	rabbitModeStreamLag          = "StreamLag"
 	defaultRabbitMQQueueLength   = 20
 	rabbitMetricType             = "External"
 )
 
+// the firehose tracer publishes a copy of the messages published to the vhost with a publish.<exchange> routing key
+const (
+	rabbitFirehoseExchange   = "amq.rabbitmq.trace"
+	rabbitFirehosePublishKey = "publish.#"
+)
+
+// the defaults of amqp.Dial
+const (
+	rabbitConnectionTimeout = 30 * time.Second
+	rabbitHeartbeat         = 10 * time.Second
+)
+
 const (
 	httpProtocol    = "http"
 	amqpProtocol    = "amqp"
@@ -61,8 +74,8 @@ type rabbitMQScaler struct {
 
 type rabbitMQMetadata struct {
 	queueName   string
-	mode        string        // QueueLength or MessageRate
-	value       int           // trigger value (queue length or publish/sec. rate)
+	mode        string        // QueueLength, MessageRate, StreamLag or OldestItemAge
+	value       int           // trigger value (queue length, publish/sec. rate, stream offset lag or age in seconds)
 	host        string        // connection string for either HTTP or AMQP protocol
 	protocol    string        // either http or amqp protocol
 	vhostName   *string       // override the vhost from the connection info
@@ -70,7 +83,9 @@ type rabbitMQMetadata struct {
 	pageSize    int           // specify the page size if useRegex is enabled
 	operation   string        // specify the operation to apply in case of multiples queues
 	metricName  string        // custom metric name for trigger
+	consumer    string        // name of the stream consumers whose offset lag is read in StreamLag mode
 	timeout     time.Duration // custom http timeout for a specific trigger
+	firehose    bool          // activate on the messages published to the queue reported by the firehose tracer
 	scalerIndex int           // scaler index
 }
 
@@ -79,6 +94,8 @@ type queueInfo struct {
 	MessagesUnacknowledged int         `json:"messages_unacknowledged"`
 	MessageStat            messageStat `json:"message_stats"`
 	Name                   string      `json:"name"`
+	// HeadMessageTimestamp is the timestamp property in seconds of the first message, nil if it isn't set
+	HeadMessageTimestamp *int64 `json:"head_message_timestamp"`
 }
 
 type regexQueueInfo struct {
@@ -94,6 +111,15 @@ type publishDetail struct {
 	Rate float64 `json:"rate"`
 }
 
+// streamConsumerInfo is a consumer of the /api/stream/consumers endpoint of the stream management plugin
+type streamConsumerInfo struct {
+	Queue struct {
+		Name string `json:"name"`
+	} `json:"queue"`
+	OffsetLag  int               `json:"offset_lag"`
+	Properties map[string]string `json:"properties"`
+}
+
 var rabbitmqLog = logf.Log.WithName("rabbitmq_scaler")
 
 // NewRabbitMQScaler creates a new rabbitMQ scaler
@@ -102,7 +128,7 @@ func NewRabbitMQScaler(config *ScalerConfig) (Scaler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing rabbitmq metadata: %s", err)
 	}
-	httpClient := kedautil.CreateHTTPClient(meta.timeout, false)
+	httpClient := createHTTPClient(config, meta.timeout, false)
 
 	if meta.protocol == httpProtocol {
 		return &rabbitMQScaler{
@@ -122,7 +148,7 @@ func NewRabbitMQScaler(config *ScalerConfig) (Scaler, error) {
 		host = hostURI.String()
 	}
 
-	conn, ch, err := getConnectionAndChannel(host)
+	conn, ch, err := getConnectionAndChannel(host, kedautil.NewEgressDialer(config.Namespace, rabbitConnectionTimeout))
 	if err != nil {
 		return nil, fmt.Errorf("error establishing rabbitmq connection: %s", err)
 	}
@@ -252,6 +278,18 @@ func parseRabbitMQMetadata(config *ScalerConfig) (*rabbitMQMetadata, error) {
 		meta.timeout = config.GlobalHTTPTimeout
 	}
 
+	// Resolve activationFirehose
+	if val, ok := config.TriggerMetadata["activationFirehose"]; ok {
+		firehose, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("activationFirehose has invalid value: %s", err)
+		}
+		if firehose && meta.protocol != amqpProtocol {
+			return nil, fmt.Errorf("activationFirehose requires the %s protocol", amqpProtocol)
+		}
+		meta.firehose = firehose
+	}
+
 	meta.scalerIndex = config.ScalerIndex
 
 	return &meta, nil
@@ -301,8 +339,12 @@ func parseTrigger(meta *rabbitMQMetadata, config *ScalerConfig) (*rabbitMQMetada
 		meta.mode = rabbitModeQueueLength
 	case rabbitModeMessageRate:
 		meta.mode = rabbitModeMessageRate
+	case rabbitModeStreamLag:
+		meta.mode = rabbitModeStreamLag
+	case modeOldestItemAge:
+		meta.mode = modeOldestItemAge
 	default:
-		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s", mode, rabbitModeQueueLength, rabbitModeMessageRate)
+		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s, %s, %s", mode, rabbitModeQueueLength, rabbitModeMessageRate, rabbitModeStreamLag, modeOldestItemAge)
 	}
 	triggerValue, err := strconv.Atoi(value)
 	if err != nil {
@@ -310,15 +352,28 @@ func parseTrigger(meta *rabbitMQMetadata, config *ScalerConfig) (*rabbitMQMetada
 	}
 	meta.value = triggerValue
 
-	if meta.mode == rabbitModeMessageRate && meta.protocol != httpProtocol {
-		return nil, fmt.Errorf("protocol %s not supported; must be http to use mode %s", meta.protocol, rabbitModeMessageRate)
+	if (meta.mode == rabbitModeMessageRate || meta.mode == rabbitModeStreamLag || meta.mode == modeOldestItemAge) && meta.protocol != httpProtocol {
+		return nil, fmt.Errorf("protocol %s not supported; must be http to use mode %s", meta.protocol, meta.mode)
+	}
+
+	if meta.mode == rabbitModeStreamLag {
+		// the offsets of the stream consumers are tracked by their name
+		meta.consumer = config.TriggerMetadata["consumerName"]
+		if meta.consumer == "" {
+			return nil, fmt.Errorf("consumerName must be specified to use mode %s", rabbitModeStreamLag)
+		}
 	}
 
 	return meta, nil
 }
 
-func getConnectionAndChannel(host string) (*amqp.Connection, *amqp.Channel, error) {
-	conn, err := amqp.Dial(host)
+// getConnectionAndChannel connects with the defaults of amqp.Dial, the connection is dialed by dialer
+func getConnectionAndChannel(host string, dialer *kedautil.EgressDialer) (*amqp.Connection, *amqp.Channel, error) {
+	conn, err := amqp.DialConfig(host, amqp.Config{
+		Heartbeat: rabbitHeartbeat,
+		Locale:    "en_US",
+		Dial:      dialer.Dial,
+	})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -331,8 +386,65 @@ func getConnectionAndChannel(host string) (*amqp.Connection, *amqp.Channel, erro
 	return conn, channel, nil
 }
 
+// ListenActivation notifies the messages published to the queue, they are read from the firehose tracer which must be
+// enabled on the vhost with `rabbitmqctl trace_on`
+func (s *rabbitMQScaler) ListenActivation(ctx context.Context, notify chan<- struct{}) error {
+	if !s.metadata.firehose {
+		return nil
+	}
+
+	// the trace messages are consumed from a dedicated channel, an exclusive queue is deleted with it
+	ch, err := s.connection.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	traceQueue, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+	if err := ch.QueueBind(traceQueue.Name, rabbitFirehosePublishKey, rabbitFirehoseExchange, false, nil); err != nil {
+		return err
+	}
+	deliveries, err := ch.Consume(traceQueue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("firehose trace consumer of queue %s closed", s.metadata.queueName)
+			}
+			if !isRoutedToQueue(delivery.Headers, s.metadata.queueName) {
+				continue
+			}
+			select {
+			case notify <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// isRoutedToQueue returns true if the routed_queues header of a firehose publish trace contains queueName
+func isRoutedToQueue(headers amqp.Table, queueName string) bool {
+	queues, _ := headers["routed_queues"].([]interface{})
+	for _, queue := range queues {
+		if name, ok := queue.(string); ok && name == queueName {
+			return true
+		}
+	}
+	return false
+}
+
 // Close disposes of RabbitMQ connections
 func (s *rabbitMQScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	if s.connection != nil {
 		err := s.connection.Close()
 		if err != nil {
@@ -345,20 +457,27 @@ func (s *rabbitMQScaler) Close(context.Context) error {
 
 // IsActive returns true if there are pending messages to be processed
 func (s *rabbitMQScaler) IsActive(ctx context.Context) (bool, error) {
-	messages, publishRate, err := s.getQueueStatus()
+	messages, publishRate, err := s.getQueueStatus(ctx, s.metadata.queueName)
 	if err != nil {
 		return false, s.anonimizeRabbitMQError(err)
 	}
 
-	if s.metadata.mode == rabbitModeQueueLength {
+	if s.metadata.mode != rabbitModeMessageRate {
 		return messages > 0, nil
 	}
 	return publishRate > 0 || messages > 0, nil
 }
 
-func (s *rabbitMQScaler) getQueueStatus() (int, float64, error) {
+// getQueueStatus returns the messages and the publish rate of queueName, the queue of the trigger
+// unless a metric selector picks another one
+func (s *rabbitMQScaler) getQueueStatus(ctx context.Context, queueName string) (int, float64, error) {
+	if s.metadata.mode == rabbitModeStreamLag {
+		lag, err := s.getStreamLagViaHTTP(ctx, queueName)
+		return lag, 0, err
+	}
+
 	if s.metadata.protocol == httpProtocol {
-		info, err := s.getQueueInfoViaHTTP()
+		info, err := s.getQueueInfoViaHTTP(ctx, queueName)
 		if err != nil {
 			return -1, -1, err
 		}
@@ -367,7 +486,11 @@ func (s *rabbitMQScaler) getQueueStatus() (int, float64, error) {
 		return info.Messages, info.MessageStat.PublishDetail.Rate, nil
 	}
 
-	items, err := s.channel.QueueInspect(s.metadata.queueName)
+	// the amqp client has no context, the canceled calls are only failed before reaching the broker
+	if err := ctx.Err(); err != nil {
+		return -1, -1, err
+	}
+	items, err := s.channel.QueueInspect(queueName)
 	if err != nil {
 		return -1, -1, err
 	}
@@ -375,9 +498,9 @@ func (s *rabbitMQScaler) getQueueStatus() (int, float64, error) {
 	return items.Messages, 0, nil
 }
 
-func getJSON(s *rabbitMQScaler, url string) (queueInfo, error) {
+func getJSON(ctx context.Context, s *rabbitMQScaler, url string) (queueInfo, error) {
 	var result queueInfo
-	r, err := s.httpClient.Get(url)
+	r, err := s.httpGet(ctx, url)
 	if err != nil {
 		return result, err
 	}
@@ -405,12 +528,21 @@ func getJSON(s *rabbitMQScaler, url string) (queueInfo, error) {
 	return result, fmt.Errorf("error requesting rabbitMQ API status: %s, response: %s, from: %s", r.Status, body, url)
 }
 
-func (s *rabbitMQScaler) getQueueInfoViaHTTP() (*queueInfo, error) {
-	parsedURL, err := url.Parse(s.metadata.host)
-
+// httpGet requests uri from the management api, the request is canceled with ctx
+func (s *rabbitMQScaler) httpGet(ctx context.Context, uri string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, err
 	}
+	return s.httpClient.Do(req)
+}
+
+// getManagementURL returns the url of the management api and the vhost path of its endpoints
+func (s *rabbitMQScaler) getManagementURL() (*url.URL, string, error) {
+	parsedURL, err := url.Parse(s.metadata.host)
+	if err != nil {
+		return nil, "", err
+	}
 
 	vhost := parsedURL.Path
 
@@ -424,15 +556,24 @@ func (s *rabbitMQScaler) getQueueInfoViaHTTP() (*queueInfo, error) {
 	}
 
 	parsedURL.Path = ""
+	return parsedURL, vhost, nil
+}
+
+func (s *rabbitMQScaler) getQueueInfoViaHTTP(ctx context.Context, queueName string) (*queueInfo, error) {
+	parsedURL, vhost, err := s.getManagementURL()
+	if err != nil {
+		return nil, err
+	}
+
 	var getQueueInfoManagementURI string
 	if s.metadata.useRegex {
-		getQueueInfoManagementURI = fmt.Sprintf("%s/api/queues?page=1&use_regex=true&pagination=false&name=%s&page_size=%d", parsedURL.String(), url.QueryEscape(s.metadata.queueName), s.metadata.pageSize)
+		getQueueInfoManagementURI = fmt.Sprintf("%s/api/queues?page=1&use_regex=true&pagination=false&name=%s&page_size=%d", parsedURL.String(), url.QueryEscape(queueName), s.metadata.pageSize)
 	} else {
-		getQueueInfoManagementURI = fmt.Sprintf("%s/api/queues%s/%s", parsedURL.String(), vhost, url.QueryEscape(s.metadata.queueName))
+		getQueueInfoManagementURI = fmt.Sprintf("%s/api/queues%s/%s", parsedURL.String(), vhost, url.QueryEscape(queueName))
 	}
 
 	var info queueInfo
-	info, err = getJSON(s, getQueueInfoManagementURI)
+	info, err = getJSON(ctx, s, getQueueInfoManagementURI)
 
 	if err != nil {
 		return nil, err
@@ -441,6 +582,78 @@ func (s *rabbitMQScaler) getQueueInfoViaHTTP() (*queueInfo, error) {
 	return &info, nil
 }
 
+// getOldestMessageAge returns the age in seconds of the first message of the queue, the oldest one of the queues
+// matching the regex, from the timestamp property set by the publishers
+func (s *rabbitMQScaler) getOldestMessageAge(ctx context.Context, queueName string) (float64, error) {
+	info, err := s.getQueueInfoViaHTTP(ctx, queueName)
+	if err != nil {
+		return -1, err
+	}
+	if info.Messages == 0 {
+		return 0, nil
+	}
+	if info.HeadMessageTimestamp == nil {
+		return -1, fmt.Errorf("the first message of %s has no timestamp property, it must be set by the publishers to use mode %s", queueName, modeOldestItemAge)
+	}
+	return oldestItemAge(time.Unix(*info.HeadMessageTimestamp, 0), time.Now()), nil
+}
+
+// getStreamLagViaHTTP returns the offset lag of the consumers named consumerName, it is the lag of the most
+// late consumer of each stream, the streams matching the regex are combined with the operation
+func (s *rabbitMQScaler) getStreamLagViaHTTP(ctx context.Context, queueName string) (int, error) {
+	parsedURL, vhost, err := s.getManagementURL()
+	if err != nil {
+		return -1, err
+	}
+
+	var streamPattern *regexp.Regexp
+	if s.metadata.useRegex {
+		if streamPattern, err = regexp.Compile(queueName); err != nil {
+			return -1, fmt.Errorf("error compiling queueName regex: %s", err)
+		}
+	}
+
+	consumersURI := fmt.Sprintf("%s/api/stream/consumers%s", parsedURL.String(), vhost)
+	r, err := s.httpGet(ctx, consumersURI)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(r.Body)
+		return -1, fmt.Errorf("error requesting rabbitMQ API status: %s, response: %s, from: %s", r.Status, body, consumersURI)
+	}
+	var consumers []streamConsumerInfo
+	if err := json.NewDecoder(r.Body).Decode(&consumers); err != nil {
+		return -1, err
+	}
+
+	streams := map[string]int{}
+	for _, consumer := range consumers {
+		if consumer.Properties["name"] != s.metadata.consumer {
+			continue
+		}
+		stream := consumer.Queue.Name
+		if (streamPattern == nil && stream != queueName) || (streamPattern != nil && !streamPattern.MatchString(stream)) {
+			continue
+		}
+		if lag, ok := streams[stream]; !ok || consumer.OffsetLag > lag {
+			streams[stream] = consumer.OffsetLag
+		}
+	}
+	if len(streams) == 0 {
+		return -1, fmt.Errorf("no consumer %s is subscribed to the stream %s", s.metadata.consumer, queueName)
+	}
+
+	queues := make([]queueInfo, 0, len(streams))
+	for stream, lag := range streams {
+		queues = append(queues, queueInfo{Name: stream, Messages: lag})
+	}
+	queue, err := getComposedQueue(s, queues)
+	return queue.Messages, err
+}
+
 // GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
 func (s *rabbitMQScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
 	metricValue := resource.NewQuantity(int64(s.metadata.value), resource.DecimalSI)
@@ -460,18 +673,36 @@ func (s *rabbitMQScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Metr
 	return []v2beta2.MetricSpec{metricSpec}
 }
 
-// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric,
+// the queueName label of the metric selector overrides the queue of the trigger
 func (s *rabbitMQScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	messages, publishRate, err := s.getQueueStatus()
+	parameters, err := getSelectorParameters(metricSelector, "queueName")
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+	queueName := s.metadata.queueName
+	if val, ok := parameters["queueName"]; ok {
+		queueName = val
+	}
+
+	if s.metadata.mode == modeOldestItemAge {
+		age, err := s.getOldestMessageAge(ctx, queueName)
+		if err != nil {
+			return []external_metrics.ExternalMetricValue{}, s.anonimizeRabbitMQError(err)
+		}
+		return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, age)), nil
+	}
+
+	messages, publishRate, err := s.getQueueStatus(ctx, queueName)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, s.anonimizeRabbitMQError(err)
 	}
 
 	var metricValue resource.Quantity
-	if s.metadata.mode == rabbitModeQueueLength {
+	if s.metadata.mode != rabbitModeMessageRate {
 		metricValue = *resource.NewQuantity(int64(messages), resource.DecimalSI)
 	} else {
-		metricValue = *resource.NewMilliQuantity(int64(publishRate*1000), resource.DecimalSI)
+		metricValue = *newMilliQuantity(publishRate)
 	}
 
 	metric := external_metrics.ExternalMetricValue{
@@ -488,6 +719,12 @@ func getComposedQueue(s *rabbitMQScaler, q []queueInfo) (queueInfo, error) {
 	queue.Name = "composed-queue"
 	queue.MessagesUnacknowledged = 0
 	if len(q) > 0 {
+		// the age of the composed queue is the one of its oldest message whatever the operation
+		for _, value := range q {
+			if value.HeadMessageTimestamp != nil && (queue.HeadMessageTimestamp == nil || *value.HeadMessageTimestamp < *queue.HeadMessageTimestamp) {
+				queue.HeadMessageTimestamp = value.HeadMessageTimestamp
+			}
+		}
 		switch s.metadata.operation {
 		case sumOperation:
 			sumMessages, sumRate := getSum(q)
@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -111,6 +113,22 @@ var testRabbitMQMetadata = []parseRabbitMQMetadataTestData{
 	{map[string]string{"mode": "MessageRate", "value": "1000", "queueName": "sample", "host": "http://", "useRegex": "true", "pageSize": "-1"}, true, map[string]string{}},
 	// invalid pageSize
 	{map[string]string{"mode": "MessageRate", "value": "1000", "queueName": "sample", "host": "http://", "useRegex": "true", "pageSize": "a"}, true, map[string]string{}},
+	// stream lag
+	{map[string]string{"mode": "StreamLag", "value": "1000", "queueName": "events", "consumerName": "billing", "host": "http://"}, false, map[string]string{}},
+	// stream lag without consumerName
+	{map[string]string{"mode": "StreamLag", "value": "1000", "queueName": "events", "host": "http://"}, true, map[string]string{}},
+	// stream lag amqp
+	{map[string]string{"mode": "StreamLag", "value": "1000", "queueName": "events", "consumerName": "billing", "host": "amqp://"}, true, map[string]string{}},
+	// firehose activation amqp
+	{map[string]string{"queueName": "sample", "host": "amqp://", "activationFirehose": "true"}, false, map[string]string{}},
+	// firehose activation http
+	{map[string]string{"queueName": "sample", "host": "http://", "activationFirehose": "true"}, true, map[string]string{}},
+	// invalid firehose activation
+	{map[string]string{"queueName": "sample", "host": "amqp://", "activationFirehose": "yes please"}, true, map[string]string{}},
+	// oldest item age
+	{map[string]string{"mode": "OldestItemAge", "value": "30", "queueName": "sample", "host": "http://"}, false, map[string]string{}},
+	// oldest item age amqp
+	{map[string]string{"mode": "OldestItemAge", "value": "30", "queueName": "sample", "host": "amqp://"}, true, map[string]string{}},
 }
 
 var rabbitMQMetricIdentifiers = []rabbitMQMetricIdentifier{
@@ -565,3 +583,142 @@ func TestRegexQueueMissingError(t *testing.T) {
 		}
 	}
 }
+
+const testStreamConsumers = `[
+	{"queue": {"name": "events", "vhost": "/"}, "offset_lag": 120, "properties": {"name": "billing"}},
+	{"queue": {"name": "events", "vhost": "/"}, "offset_lag": 300, "properties": {"name": "billing"}},
+	{"queue": {"name": "events", "vhost": "/"}, "offset_lag": 5000, "properties": {"name": "audit"}},
+	{"queue": {"name": "orders-0", "vhost": "/"}, "offset_lag": 40, "properties": {"name": "billing"}},
+	{"queue": {"name": "orders-1", "vhost": "/"}, "offset_lag": 60, "properties": {"name": "billing", "super-stream": "orders"}},
+	{"queue": {"name": "invoices", "vhost": "/"}, "offset_lag": 25}
+]`
+
+func TestGetStreamLag(t *testing.T) {
+	apiStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stream/consumers/%2F", r.RequestURI)
+		_, _ = w.Write([]byte(testStreamConsumers))
+	}))
+	defer apiStub.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		lag      int64
+		isError  bool
+	}{
+		{map[string]string{"queueName": "events"}, 300, false},
+		{map[string]string{"queueName": "orders-[0-9]+", "useRegex": "true"}, 100, false},
+		{map[string]string{"queueName": "orders-[0-9]+", "useRegex": "true", "operation": "max"}, 60, false},
+		{map[string]string{"queueName": "invoices"}, 0, true},
+		{map[string]string{"queueName": "orders-[", "useRegex": "true"}, 0, true},
+	}
+	for _, test := range tests {
+		metadata := map[string]string{"host": apiStub.URL, "mode": "StreamLag", "value": "100", "consumerName": "billing"}
+		for k, v := range test.metadata {
+			metadata[k] = v
+		}
+		s, err := NewRabbitMQScaler(&ScalerConfig{TriggerMetadata: metadata, AuthParams: map[string]string{}, GlobalHTTPTimeout: time.Second})
+		if err != nil {
+			t.Fatal("Expect success", err)
+		}
+
+		metrics, err := s.GetMetrics(context.Background(), "s0-rabbitmq-events", nil)
+		if test.isError {
+			assert.Error(t, err, test.metadata["queueName"])
+			continue
+		}
+		assert.NoError(t, err, test.metadata["queueName"])
+		assert.Equal(t, test.lag, metrics[0].Value.Value(), test.metadata["queueName"])
+
+		active, err := s.IsActive(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, active)
+	}
+}
+
+func TestGetOldestMessageAge(t *testing.T) {
+	enqueuedAt := time.Now().Add(-time.Minute).Unix()
+	apiStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/queues/%2F/orders":
+			fmt.Fprintf(w, `{"messages": 4, "name": "orders", "head_message_timestamp": %d}`, enqueuedAt)
+		case "/api/queues/%2F/empty":
+			fmt.Fprint(w, `{"messages": 0, "name": "empty", "head_message_timestamp": null}`)
+		case "/api/queues/%2F/untimed":
+			fmt.Fprint(w, `{"messages": 2, "name": "untimed"}`)
+		case "/api/queues":
+			fmt.Fprintf(w, `{"items": [{"messages": 1, "name": "orders-1", "head_message_timestamp": %d}, {"messages": 3, "name": "orders-2", "head_message_timestamp": %d}], "page_count": 1}`, enqueuedAt+30, enqueuedAt)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer apiStub.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		minAge   float64
+		isError  bool
+	}{
+		{map[string]string{"queueName": "orders"}, 60, false},
+		{map[string]string{"queueName": "empty"}, 0, false},
+		{map[string]string{"queueName": "untimed"}, 0, true},
+		// the oldest message of the queues matching the regex
+		{map[string]string{"queueName": "orders-[0-9]+", "useRegex": "true", "operation": "avg"}, 60, false},
+	}
+	for _, test := range tests {
+		metadata := map[string]string{"host": apiStub.URL, "protocol": "http", "mode": "OldestItemAge", "value": "30"}
+		for k, v := range test.metadata {
+			metadata[k] = v
+		}
+		s, err := NewRabbitMQScaler(&ScalerConfig{TriggerMetadata: metadata, AuthParams: map[string]string{}, GlobalHTTPTimeout: time.Second})
+		if err != nil {
+			t.Fatal("Expect success", err)
+		}
+
+		metrics, err := s.GetMetrics(context.Background(), "s0-rabbitmq-orders", nil)
+		if test.isError {
+			assert.Error(t, err, test.metadata["queueName"])
+			continue
+		}
+		assert.NoError(t, err, test.metadata["queueName"])
+		age := float64(metrics[0].Value.MilliValue()) / 1000
+		assert.GreaterOrEqual(t, age, test.minAge, test.metadata["queueName"])
+		assert.Less(t, age, test.minAge+5, test.metadata["queueName"])
+	}
+}
+
+func TestGetMetricsWithQueueNameSelector(t *testing.T) {
+	apiStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/queues/%2F/orders":
+			_, _ = w.Write([]byte(`{"messages": 4, "name": "orders"}`))
+		case "/api/queues/%2F/invoices":
+			_, _ = w.Write([]byte(`{"messages": 9, "name": "invoices"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer apiStub.Close()
+
+	metadata := map[string]string{"host": apiStub.URL, "protocol": "http", "queueName": "orders", "mode": "QueueLength", "value": "10"}
+	s, err := NewRabbitMQScaler(&ScalerConfig{TriggerMetadata: metadata, AuthParams: map[string]string{}, GlobalHTTPTimeout: time.Second})
+	if err != nil {
+		t.Fatal("Expect success", err)
+	}
+
+	metrics, err := s.GetMetrics(context.Background(), "s0-rabbitmq-orders", labels.Everything())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), metrics[0].Value.Value())
+
+	metrics, err = s.GetMetrics(context.Background(), "s0-rabbitmq-orders", labels.SelectorFromSet(labels.Set{"queueName": "invoices"}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), metrics[0].Value.Value())
+
+	_, err = s.GetMetrics(context.Background(), "s0-rabbitmq-orders", labels.SelectorFromSet(labels.Set{"listName": "invoices"}))
+	assert.Error(t, err)
+}
+
+func TestRabbitMQIsRoutedToQueue(t *testing.T) {
+	assert.True(t, isRoutedToQueue(amqp.Table{"routed_queues": []interface{}{"other", "sample"}}, "sample"))
+	assert.False(t, isRoutedToQueue(amqp.Table{"routed_queues": []interface{}{"other"}}, "sample"))
+	assert.False(t, isRoutedToQueue(amqp.Table{"exchange_name": "sample"}, "sample"))
+}
@@ -3,9 +3,14 @@ package scalers
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -22,6 +27,9 @@ const (
 	defaultTargetListLength = 5
 	defaultDBIdx            = 0
 	defaultEnableTLS        = false
+	defaultRedisDialTimeout = 5 * time.Second
+	defaultRedisScanCount   = 100
+	defaultRedisMaxScanKeys = 1000
 )
 
 type redisAddressParser func(metadata, resolvedEnv, authParams map[string]string) (redisConnectionInfo, error)
@@ -29,7 +37,9 @@ type redisAddressParser func(metadata, resolvedEnv, authParams map[string]string
 type redisScaler struct {
 	metadata        *redisMetadata
 	closeFn         func() error
-	getListLengthFn func(context.Context) (int64, error)
+	getListLengthFn func(context.Context, string) (int64, error)
+	// sumListLengthsFn sums the lengths of the lists matching a pattern
+	sumListLengthsFn func(context.Context, string) (int64, error)
 }
 
 type redisConnectionInfo struct {
@@ -42,18 +52,35 @@ type redisConnectionInfo struct {
 	hosts            []string
 	ports            []string
 	enableTLS        bool
+	// unsafeSsl skips the verification of the server certificate
+	unsafeSsl     bool
+	tlsServerName string
+	// namespace of the trigger, its egress policy applies to the connections
+	namespace string
+	ca        string
+	cert      string
+	key       string
 }
 
 type redisMetadata struct {
 	targetListLength int
 	listName         string
-	databaseIndex    int
-	connectionInfo   redisConnectionInfo
-	scalerIndex      int
+	// listNamePattern matches the lists whose lengths are summed instead of the single listName, eg. queue:*
+	listNamePattern string
+	// scanCount is the COUNT hint of the SCAN calls matching listNamePattern
+	scanCount int64
+	// maxScanKeys is the number of keys matching listNamePattern counted at most, per node of a cluster
+	maxScanKeys    int64
+	databaseIndex  int
+	connectionInfo redisConnectionInfo
+	scalerIndex    int
 }
 
 var redisLog = logf.Log.WithName("redis_scaler")
 
+// redisPatternCharacters are the characters of a key pattern replaced in the metric name
+var redisPatternCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.:/-]+`)
+
 // NewRedisScaler creates a new redisScaler
 func NewRedisScaler(ctx context.Context, isClustered, isSentinel bool, config *ScalerConfig) (Scaler, error) {
 	luaScript := `
@@ -104,8 +131,8 @@ func createClusteredRedisScaler(ctx context.Context, meta *redisMetadata, script
 		return nil
 	}
 
-	listLengthFn := func(ctx context.Context) (int64, error) {
-		cmd := client.Eval(ctx, script, []string{meta.listName})
+	listLengthFn := func(ctx context.Context, listName string) (int64, error) {
+		cmd := client.Eval(ctx, script, []string{listName})
 		if cmd.Err() != nil {
 			return -1, cmd.Err()
 		}
@@ -113,10 +140,18 @@ func createClusteredRedisScaler(ctx context.Context, meta *redisMetadata, script
 		return cmd.Int64()
 	}
 
+	// the keys matching a pattern are spread over the masters of the cluster, each of them is scanned
+	forEachNode := func(ctx context.Context, fn func(context.Context, redis.Cmdable) error) error {
+		return client.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return fn(ctx, master)
+		})
+	}
+
 	return &redisScaler{
-		metadata:        meta,
-		closeFn:         closeFn,
-		getListLengthFn: listLengthFn,
+		metadata:         meta,
+		closeFn:          closeFn,
+		getListLengthFn:  listLengthFn,
+		sumListLengthsFn: getRedisSumListLengthsFn(meta, script, forEachNode),
 	}, nil
 }
 
@@ -134,8 +169,8 @@ func createSentinelRedisScaler(ctx context.Context, meta *redisMetadata, script
 		return nil
 	}
 
-	listLengthFn := func(ctx context.Context) (int64, error) {
-		cmd := client.Eval(ctx, script, []string{meta.listName})
+	listLengthFn := func(ctx context.Context, listName string) (int64, error) {
+		cmd := client.Eval(ctx, script, []string{listName})
 		if cmd.Err() != nil {
 			return -1, cmd.Err()
 		}
@@ -144,9 +179,10 @@ func createSentinelRedisScaler(ctx context.Context, meta *redisMetadata, script
 	}
 
 	return &redisScaler{
-		metadata:        meta,
-		closeFn:         closeFn,
-		getListLengthFn: listLengthFn,
+		metadata:         meta,
+		closeFn:          closeFn,
+		getListLengthFn:  listLengthFn,
+		sumListLengthsFn: getRedisSumListLengthsFn(meta, script, forSingleRedisNode(client)),
 	}, nil
 }
 
@@ -164,8 +200,8 @@ func createRedisScaler(ctx context.Context, meta *redisMetadata, script string)
 		return nil
 	}
 
-	listLengthFn := func(ctx context.Context) (int64, error) {
-		cmd := client.Eval(ctx, script, []string{meta.listName})
+	listLengthFn := func(ctx context.Context, listName string) (int64, error) {
+		cmd := client.Eval(ctx, script, []string{listName})
 		if cmd.Err() != nil {
 			return -1, cmd.Err()
 		}
@@ -174,17 +210,105 @@ func createRedisScaler(ctx context.Context, meta *redisMetadata, script string)
 	}
 
 	return &redisScaler{
-		metadata:        meta,
-		closeFn:         closeFn,
-		getListLengthFn: listLengthFn,
+		metadata:         meta,
+		closeFn:          closeFn,
+		getListLengthFn:  listLengthFn,
+		sumListLengthsFn: getRedisSumListLengthsFn(meta, script, forSingleRedisNode(client)),
 	}, nil
 }
 
+// forSingleRedisNode returns the function calling fn with the client of a single node
+func forSingleRedisNode(client redis.Cmdable) func(context.Context, func(context.Context, redis.Cmdable) error) error {
+	return func(ctx context.Context, fn func(context.Context, redis.Cmdable) error) error {
+		return fn(ctx, client)
+	}
+}
+
+// getRedisSumListLengthsFn returns the function summing the lengths of the lists matching a pattern on the nodes
+// of forEachNode, the nodes can be called concurrently
+func getRedisSumListLengthsFn(meta *redisMetadata, script string, forEachNode func(context.Context, func(context.Context, redis.Cmdable) error) error) func(context.Context, string) (int64, error) {
+	return func(ctx context.Context, pattern string) (int64, error) {
+		var lock sync.Mutex
+		var sum int64
+		err := forEachNode(ctx, func(ctx context.Context, client redis.Cmdable) error {
+			length, complete, err := sumRedisListLengths(ctx, client, script, pattern, meta.scanCount, meta.maxScanKeys)
+			if err != nil {
+				return err
+			}
+			if !complete {
+				redisLog.V(1).Info("more keys match the pattern than maxScanKeys, the lengths of the others aren't counted", "pattern", pattern, "maxScanKeys", meta.maxScanKeys)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			sum += length
+			return nil
+		})
+		if err != nil {
+			return -1, err
+		}
+		return sum, nil
+	}
+}
+
+// sumRedisListLengths scans the keys matching pattern scanCount at a time and sums the lengths of the lists, up to
+// maxKeys keys are counted, it returns false when more keys match the pattern
+func sumRedisListLengths(ctx context.Context, client redis.Cmdable, script string, pattern string, scanCount int64, maxKeys int64) (int64, bool, error) {
+	var sum int64
+	var cursor uint64
+	// SCAN returns a key more than once when the keyspace is rehashed during the iteration
+	counted := map[string]bool{}
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return -1, false, err
+		}
+
+		var batch []string
+		for _, key := range keys {
+			if counted[key] {
+				continue
+			}
+			if int64(len(counted)) >= maxKeys {
+				break
+			}
+			counted[key] = true
+			batch = append(batch, key)
+		}
+		if len(batch) > 0 {
+			cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range batch {
+					pipe.Eval(ctx, script, []string{key})
+				}
+				return nil
+			})
+			if err != nil {
+				return -1, false, err
+			}
+			for _, cmd := range cmds {
+				length, err := cmd.(*redis.Cmd).Int64()
+				if err != nil {
+					return -1, false, err
+				}
+				sum += length
+			}
+		}
+
+		if next == 0 {
+			return sum, true, nil
+		}
+		if int64(len(counted)) >= maxKeys {
+			return sum, false, nil
+		}
+		cursor = next
+	}
+}
+
 func parseRedisMetadata(config *ScalerConfig, parserFn redisAddressParser) (*redisMetadata, error) {
 	connInfo, err := parserFn(config.TriggerMetadata, config.ResolvedEnv, config.AuthParams)
 	if err != nil {
 		return nil, err
 	}
+	connInfo.namespace = config.Namespace
 	meta := redisMetadata{
 		connectionInfo: connInfo,
 	}
@@ -198,9 +322,32 @@ func parseRedisMetadata(config *ScalerConfig, parserFn redisAddressParser) (*red
 		meta.targetListLength = listLength
 	}
 
-	if val, ok := config.TriggerMetadata["listName"]; ok {
+	if val, ok := config.TriggerMetadata["listName"]; ok && val != "" {
 		meta.listName = val
-	} else {
+	}
+	if val, ok := config.TriggerMetadata["listNamePattern"]; ok && val != "" {
+		if meta.listName != "" {
+			return nil, fmt.Errorf("listName and listNamePattern can't be given together")
+		}
+		meta.listNamePattern = val
+		meta.scanCount = defaultRedisScanCount
+		if val, ok := config.TriggerMetadata["scanCount"]; ok && val != "" {
+			scanCount, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || scanCount <= 0 {
+				return nil, fmt.Errorf("scanCount must be a positive integer, got %q", val)
+			}
+			meta.scanCount = scanCount
+		}
+		meta.maxScanKeys = defaultRedisMaxScanKeys
+		if val, ok := config.TriggerMetadata["maxScanKeys"]; ok && val != "" {
+			maxScanKeys, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || maxScanKeys <= 0 {
+				return nil, fmt.Errorf("maxScanKeys must be a positive integer, got %q", val)
+			}
+			meta.maxScanKeys = maxScanKeys
+		}
+	}
+	if meta.listName == "" && meta.listNamePattern == "" {
 		return nil, fmt.Errorf("no list name given")
 	}
 
@@ -218,7 +365,7 @@ func parseRedisMetadata(config *ScalerConfig, parserFn redisAddressParser) (*red
 
 // IsActive checks if there is any element in the Redis list
 func (s *redisScaler) IsActive(ctx context.Context) (bool, error) {
-	length, err := s.getListLengthFn(ctx)
+	length, err := s.getListLength(ctx, s.metadata.listName)
 
 	if err != nil {
 		redisLog.Error(err, "error")
@@ -235,7 +382,12 @@ func (s *redisScaler) Close(context.Context) error {
 // GetMetricSpecForScaling returns the metric spec for the HPA
 func (s *redisScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
 	targetListLengthQty := resource.NewQuantity(int64(s.metadata.targetListLength), resource.DecimalSI)
-	metricName := kedautil.NormalizeString(fmt.Sprintf("redis-%s", s.metadata.listName))
+	listName := s.metadata.listName
+	if listName == "" {
+		// the glob characters of the pattern aren't valid in metric names
+		listName = strings.Trim(redisPatternCharacters.ReplaceAllString(s.metadata.listNamePattern, "-"), "-_.:/")
+	}
+	metricName := kedautil.NormalizeString(fmt.Sprintf("redis-%s", listName))
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
@@ -251,9 +403,19 @@ func (s *redisScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricS
 	return []v2beta2.MetricSpec{metricSpec}
 }
 
-// GetMetrics connects to Redis and finds the length of the list
+// GetMetrics connects to Redis and finds the length of the list, the listName label of the metric selector
+// overrides the list of the trigger
 func (s *redisScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	listLen, err := s.getListLengthFn(ctx)
+	parameters, err := getSelectorParameters(metricSelector, "listName")
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+	listName := s.metadata.listName
+	if val, ok := parameters["listName"]; ok {
+		listName = val
+	}
+
+	listLen, err := s.getListLength(ctx, listName)
 
 	if err != nil {
 		redisLog.Error(err, "error getting list length")
@@ -269,6 +431,15 @@ func (s *redisScaler) GetMetrics(ctx context.Context, metricName string, metricS
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
+// getListLength returns the length of listName, or with an empty listName the sum of the lengths of the lists
+// matching the listNamePattern of the trigger
+func (s *redisScaler) getListLength(ctx context.Context, listName string) (int64, error) {
+	if listName == "" && s.metadata.listNamePattern != "" {
+		return s.sumListLengthsFn(ctx, s.metadata.listNamePattern)
+	}
+	return s.getListLengthFn(ctx, listName)
+}
+
 func parseRedisAddress(metadata, resolvedEnv, authParams map[string]string) (redisConnectionInfo, error) {
 	info := redisConnectionInfo{}
 	switch {
@@ -306,28 +477,8 @@ func parseRedisAddress(metadata, resolvedEnv, authParams map[string]string) (red
 		return info, fmt.Errorf("no address or host given. address should be in the format of host:port or you should set the host/port values")
 	}
 
-	switch {
-	case authParams["username"] != "":
-		info.username = authParams["username"]
-	case metadata["username"] != "":
-		info.username = metadata["username"]
-	case metadata["usernameFromEnv"] != "":
-		info.username = resolvedEnv[metadata["usernameFromEnv"]]
-	}
-
-	if authParams["password"] != "" {
-		info.password = authParams["password"]
-	} else if metadata["passwordFromEnv"] != "" {
-		info.password = resolvedEnv[metadata["passwordFromEnv"]]
-	}
-
-	info.enableTLS = defaultEnableTLS
-	if val, ok := metadata["enableTLS"]; ok {
-		tls, err := strconv.ParseBool(val)
-		if err != nil {
-			return info, fmt.Errorf("enableTLS parsing error %s", err.Error())
-		}
-		info.enableTLS = tls
+	if err := parseRedisAuthAndTLS(&info, metadata, resolvedEnv, authParams); err != nil {
+		return info, err
 	}
 
 	return info, nil
@@ -384,28 +535,8 @@ func parseRedisClusterAddress(metadata, resolvedEnv, authParams map[string]strin
 		return info, err
 	}
 
-	switch {
-	case authParams["username"] != "":
-		info.username = authParams["username"]
-	case metadata["username"] != "":
-		info.username = metadata["username"]
-	case metadata["usernameFromEnv"] != "":
-		info.username = resolvedEnv[metadata["usernameFromEnv"]]
-	}
-
-	if authParams["password"] != "" {
-		info.password = authParams["password"]
-	} else if metadata["passwordFromEnv"] != "" {
-		info.password = resolvedEnv[metadata["passwordFromEnv"]]
-	}
-
-	info.enableTLS = defaultEnableTLS
-	if val, ok := metadata["enableTLS"]; ok {
-		tls, err := strconv.ParseBool(val)
-		if err != nil {
-			return info, fmt.Errorf("enableTLS parsing error %s", err.Error())
-		}
-		info.enableTLS = tls
+	if err := parseRedisAuthAndTLS(&info, metadata, resolvedEnv, authParams); err != nil {
+		return info, err
 	}
 
 	return info, nil
@@ -417,19 +548,8 @@ func parseRedisSentinelAddress(metadata, resolvedEnv, authParams map[string]stri
 		return info, err
 	}
 
-	switch {
-	case authParams["username"] != "":
-		info.username = authParams["username"]
-	case metadata["username"] != "":
-		info.username = metadata["username"]
-	case metadata["usernameFromEnv"] != "":
-		info.username = resolvedEnv[metadata["usernameFromEnv"]]
-	}
-
-	if authParams["password"] != "" {
-		info.password = authParams["password"]
-	} else if metadata["passwordFromEnv"] != "" {
-		info.password = resolvedEnv[metadata["passwordFromEnv"]]
+	if err := parseRedisAuthAndTLS(&info, metadata, resolvedEnv, authParams); err != nil {
+		return info, err
 	}
 
 	switch {
@@ -456,16 +576,100 @@ func parseRedisSentinelAddress(metadata, resolvedEnv, authParams map[string]stri
 		info.sentinelMaster = resolvedEnv[metadata["sentinelMasterFromEnv"]]
 	}
 
+	return info, nil
+}
+
+// parseRedisAuthAndTLS parses the ACL username, the password and the TLS settings shared by all the redis scalers
+func parseRedisAuthAndTLS(info *redisConnectionInfo, metadata, resolvedEnv, authParams map[string]string) error {
+	switch {
+	case authParams["username"] != "":
+		info.username = authParams["username"]
+	case metadata["username"] != "":
+		info.username = metadata["username"]
+	case metadata["usernameFromEnv"] != "":
+		info.username = resolvedEnv[metadata["usernameFromEnv"]]
+	}
+
+	if authParams["password"] != "" {
+		info.password = authParams["password"]
+	} else if metadata["passwordFromEnv"] != "" {
+		info.password = resolvedEnv[metadata["passwordFromEnv"]]
+	}
+
 	info.enableTLS = defaultEnableTLS
 	if val, ok := metadata["enableTLS"]; ok {
 		tls, err := strconv.ParseBool(val)
 		if err != nil {
-			return info, fmt.Errorf("enableTLS parsing error %s", err.Error())
+			return fmt.Errorf("enableTLS parsing error %s", err.Error())
 		}
 		info.enableTLS = tls
 	}
+	if !info.enableTLS {
+		return nil
+	}
 
-	return info, nil
+	info.ca = authParams["ca"]
+	info.cert = authParams["cert"]
+	info.key = authParams["key"]
+	if (info.cert == "") != (info.key == "") {
+		return fmt.Errorf("both cert and key must be given for TLS client authentication")
+	}
+	info.tlsServerName = metadata["tlsServerName"]
+
+	// the server certificates weren't verified before ca was supported
+	info.unsafeSsl = info.ca == ""
+	if val, ok := metadata["unsafeSsl"]; ok {
+		unsafeSsl, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("unsafeSsl parsing error %s", err.Error())
+		}
+		info.unsafeSsl = unsafeSsl
+	}
+	return nil
+}
+
+// getRedisTLSConfig returns the TLS config of the connection, nil if TLS isn't enabled
+func getRedisTLSConfig(info redisConnectionInfo) (*tls.Config, error) {
+	if !info.enableTLS {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		ServerName:         info.tlsServerName,
+		InsecureSkipVerify: info.unsafeSsl,
+	}
+	if info.ca != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(info.ca)) {
+			return nil, fmt.Errorf("error parsing ca: no certificate found")
+		}
+		config.RootCAs = pool
+	}
+	if info.cert != "" {
+		cert, err := tls.X509KeyPair([]byte(info.cert), []byte(info.key))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing cert and key: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// getRedisDialer dials the connections like the default dialer of go-redis with the egress policy of the namespace
+func getRedisDialer(info redisConnectionInfo, tlsConfig *tls.Config) func(context.Context, string, string) (net.Conn, error) {
+	dialer := kedautil.NewEgressDialer(info.namespace, defaultRedisDialTimeout)
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil || tlsConfig == nil {
+			return conn, err
+		}
+		config := tlsConfig
+		if config.ServerName == "" {
+			config = tlsConfig.Clone()
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		return tls.Client(conn, config), nil
+	}
 }
 
 func getRedisClusterClient(ctx context.Context, info redisConnectionInfo) (*redis.ClusterClient, error) {
@@ -474,11 +678,12 @@ func getRedisClusterClient(ctx context.Context, info redisConnectionInfo) (*redi
 		Username: info.username,
 		Password: info.password,
 	}
-	if info.enableTLS {
-		options.TLSConfig = &tls.Config{
-			InsecureSkipVerify: info.enableTLS,
-		}
+	tlsConfig, err := getRedisTLSConfig(info)
+	if err != nil {
+		return nil, err
 	}
+	options.TLSConfig = tlsConfig
+	options.Dialer = getRedisDialer(info, tlsConfig)
 
 	// confirm if connected
 	c := redis.NewClusterClient(options)
@@ -498,11 +703,12 @@ func getRedisSentinelClient(ctx context.Context, info redisConnectionInfo, dbInd
 		SentinelPassword: info.sentinelPassword,
 		MasterName:       info.sentinelMaster,
 	}
-	if info.enableTLS {
-		options.TLSConfig = &tls.Config{
-			InsecureSkipVerify: info.enableTLS,
-		}
+	tlsConfig, err := getRedisTLSConfig(info)
+	if err != nil {
+		return nil, err
 	}
+	options.TLSConfig = tlsConfig
+	options.Dialer = getRedisDialer(info, tlsConfig)
 
 	// confirm if connected
 	c := redis.NewFailoverClient(options)
@@ -519,16 +725,16 @@ func getRedisClient(ctx context.Context, info redisConnectionInfo, dbIndex int)
 		Password: info.password,
 		DB:       dbIndex,
 	}
-	if info.enableTLS {
-		options.TLSConfig = &tls.Config{
-			InsecureSkipVerify: info.enableTLS,
-		}
+	tlsConfig, err := getRedisTLSConfig(info)
+	if err != nil {
+		return nil, err
 	}
+	options.TLSConfig = tlsConfig
+	options.Dialer = getRedisDialer(info, tlsConfig)
 
 	// confirm if connected
 	c := redis.NewClient(options)
-	err := c.Ping(ctx).Err()
-	if err != nil {
+	if err := c.Ping(ctx).Err(); err != nil {
 		return nil, err
 	}
 	return c, nil
@@ -2,10 +2,20 @@ package scalers
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 var testRedisResolvedEnv = map[string]string{
@@ -54,7 +64,15 @@ var testRedisMetadata = []parseRedisMetadataTestData{
 	// host and port is defined in the authParams
 	{map[string]string{"listName": "mylist", "listLength": "0"}, false, map[string]string{"host": "localhost", "port": "6379"}},
 	// host only is defined in the authParams
-	{map[string]string{"listName": "mylist", "listLength": "0"}, true, map[string]string{"host": "localhost"}}}
+	{map[string]string{"listName": "mylist", "listLength": "0"}, true, map[string]string{"host": "localhost"}},
+	// the lists matching a pattern
+	{map[string]string{"listNamePattern": "queue:*", "scanCount": "500", "maxScanKeys": "5000"}, false, map[string]string{"address": "localhost:6379"}},
+	// both a list and a pattern
+	{map[string]string{"listName": "mylist", "listNamePattern": "queue:*"}, true, map[string]string{"address": "localhost:6379"}},
+	// invalid scanCount
+	{map[string]string{"listNamePattern": "queue:*", "scanCount": "0"}, true, map[string]string{"address": "localhost:6379"}},
+	// invalid maxScanKeys
+	{map[string]string{"listNamePattern": "queue:*", "maxScanKeys": "many"}, true, map[string]string{"address": "localhost:6379"}}}
 
 var redisMetricIdentifiers = []redisMetricIdentifier{
 	{&testRedisMetadata[1], 0, "s0-redis-mylist"},
@@ -82,11 +100,12 @@ func TestRedisGetMetricSpecForScaling(t *testing.T) {
 			t.Fatal("Could not parse metadata:", err)
 		}
 		closeFn := func() error { return nil }
-		lengthFn := func(context.Context) (int64, error) { return -1, nil }
+		lengthFn := func(context.Context, string) (int64, error) { return -1, nil }
 		mockRedisScaler := redisScaler{
 			meta,
 			closeFn,
 			lengthFn,
+			lengthFn,
 		}
 
 		metricSpec := mockRedisScaler.GetMetricSpecForScaling(context.Background())
@@ -97,6 +116,90 @@ func TestRedisGetMetricSpecForScaling(t *testing.T) {
 	}
 }
 
+func TestRedisGetMetricsWithListNameSelector(t *testing.T) {
+	lengths := map[string]int64{"jobs": 3, "urgent-jobs": 12}
+	mockRedisScaler := redisScaler{
+		metadata: &redisMetadata{listName: "jobs", targetListLength: 5},
+		closeFn:  func() error { return nil },
+		getListLengthFn: func(_ context.Context, listName string) (int64, error) {
+			return lengths[listName], nil
+		},
+	}
+
+	metrics, err := mockRedisScaler.GetMetrics(context.Background(), "s0-redis-jobs", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), metrics[0].Value.Value())
+
+	metrics, err = mockRedisScaler.GetMetrics(context.Background(), "s0-redis-jobs", labels.SelectorFromSet(labels.Set{"listName": "urgent-jobs"}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), metrics[0].Value.Value())
+}
+
+// scanningRedisClient serves the SCAN calls two keys at a time and the lengths of the lists
+type scanningRedisClient struct {
+	redis.Cmdable
+	keys    []string
+	lengths map[string]int64
+}
+
+func (c scanningRedisClient) Scan(_ context.Context, cursor uint64, _ string, _ int64) *redis.ScanCmd {
+	end := cursor + 2
+	if end >= uint64(len(c.keys)) {
+		return redis.NewScanCmdResult(c.keys[cursor:], 0, nil)
+	}
+	return redis.NewScanCmdResult(c.keys[cursor:end], end, nil)
+}
+
+func (c scanningRedisClient) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	pipe := &evalPipeliner{lengths: c.lengths}
+	err := fn(pipe)
+	return pipe.cmds, err
+}
+
+type evalPipeliner struct {
+	redis.Pipeliner
+	lengths map[string]int64
+	cmds    []redis.Cmder
+}
+
+func (p *evalPipeliner) Eval(_ context.Context, _ string, keys []string, _ ...interface{}) *redis.Cmd {
+	cmd := redis.NewCmdResult(p.lengths[keys[0]], nil)
+	p.cmds = append(p.cmds, cmd)
+	return cmd
+}
+
+func TestSumRedisListLengths(t *testing.T) {
+	client := scanningRedisClient{
+		// a key returned twice by the SCAN is counted once
+		keys:    []string{"queue:{1}", "queue:{2}", "queue:{2}", "queue:{3}", "queue:{4}"},
+		lengths: map[string]int64{"queue:{1}": 1, "queue:{2}": 2, "queue:{3}": 3, "queue:{4}": 4},
+	}
+
+	sum, complete, err := sumRedisListLengths(context.Background(), client, "", "queue:*", 2, 1000)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, int64(10), sum)
+
+	sum, complete, err = sumRedisListLengths(context.Background(), client, "", "queue:*", 2, 3)
+	assert.NoError(t, err)
+	assert.False(t, complete)
+	assert.Equal(t, int64(6), sum)
+
+	// the lengths of the nodes of a cluster are summed
+	meta := &redisMetadata{listNamePattern: "queue:*", scanCount: 2, maxScanKeys: 1000}
+	forEachNode := func(ctx context.Context, fn func(context.Context, redis.Cmdable) error) error {
+		if err := fn(ctx, client); err != nil {
+			return err
+		}
+		return fn(ctx, client)
+	}
+	scaler := redisScaler{metadata: meta, sumListLengthsFn: getRedisSumListLengthsFn(meta, "", forEachNode)}
+	assert.Equal(t, "s0-redis-queue", scaler.GetMetricSpecForScaling(context.Background())[0].External.Metric.Name)
+	metrics, err := scaler.GetMetrics(context.Background(), "s0-redis-queue", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), metrics[0].Value.Value())
+}
+
 func TestParseRedisClusterMetadata(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -712,3 +815,72 @@ func TestParseRedisSentinelMetadata(t *testing.T) {
 		})
 	}
 }
+
+// testRedisCA returns a self-signed certificate and its key in PEM
+func testRedisCA(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.example.com"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestParseRedisTLS(t *testing.T) {
+	ca, key := testRedisCA(t)
+	parsers := map[string]redisAddressParser{
+		"redis":          parseRedisAddress,
+		"redis-cluster":  parseRedisClusterAddress,
+		"redis-sentinel": parseRedisSentinelAddress,
+	}
+	for name, parser := range parsers {
+		metadata := map[string]string{"address": "redis:6379", "addresses": "redis:6379", "username": "keda", "enableTLS": "true", "tlsServerName": "redis.example.com"}
+
+		// without ca the server certificate isn't verified, as before ca was supported
+		info, err := parser(metadata, nil, map[string]string{"password": "secret"})
+		assert.NoError(t, err, name)
+		assert.Equal(t, "keda", info.username, name)
+		assert.True(t, info.unsafeSsl, name)
+		config, err := getRedisTLSConfig(info)
+		assert.NoError(t, err, name)
+		assert.True(t, config.InsecureSkipVerify, name)
+		assert.Equal(t, "redis.example.com", config.ServerName, name)
+
+		info, err = parser(metadata, nil, map[string]string{"ca": ca, "cert": ca, "key": key})
+		assert.NoError(t, err, name)
+		assert.False(t, info.unsafeSsl, name)
+		config, err = getRedisTLSConfig(info)
+		assert.NoError(t, err, name)
+		assert.False(t, config.InsecureSkipVerify, name)
+		assert.NotNil(t, config.RootCAs, name)
+		assert.Len(t, config.Certificates, 1, name)
+
+		_, err = parser(metadata, nil, map[string]string{"cert": ca})
+		assert.Error(t, err, name)
+
+		info, err = parser(map[string]string{"address": "redis:6379", "addresses": "redis:6379"}, nil, map[string]string{"ca": ca})
+		assert.NoError(t, err, name)
+		config, err = getRedisTLSConfig(info)
+		assert.NoError(t, err, name)
+		assert.Nil(t, config, name)
+	}
+
+	_, err := getRedisTLSConfig(redisConnectionInfo{enableTLS: true, ca: "not a certificate"})
+	assert.Error(t, err)
+}
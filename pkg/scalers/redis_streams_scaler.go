@@ -161,6 +161,7 @@ func parseRedisStreamsMetadata(config *ScalerConfig, parseFn redisAddressParser)
 	if err != nil {
 		return nil, err
 	}
+	connInfo.namespace = config.Namespace
 	meta := redisStreamsMetadata{
 		connectionInfo: connInfo,
 	}
@@ -0,0 +1,227 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type requestConcurrencyScaler struct {
+	metadata   *requestConcurrencyMetadata
+	kubeClient client.Client
+	httpClient *http.Client
+	logger     logr.Logger
+}
+
+type requestConcurrencyMetadata struct {
+	// StatsURL is the stats endpoint of an interceptor in front of the scale target, eg. a buffering proxy holding
+	// the requests while the target is scaled to zero
+	StatsURL string `keda:"name=statsURL, optional"`
+	// PodSelector selects the pods of the scale target whose sidecars serve the stats at StatsPort and StatsPath,
+	// the stats of the pods are summed
+	PodSelector string  `keda:"name=podSelector, optional"`
+	StatsPort   *uint16 `keda:"name=statsPort, optional"`
+	StatsPath   string  `keda:"name=statsPath, default=/stats"`
+
+	TargetConcurrency float64 `keda:"name=targetConcurrency"`
+
+	podSelector labels.Selector
+	// activationListener is notified by the interceptor once it buffers a request, nil if activationNotificationKey
+	// isn't set
+	activationListener *notificationListener
+	namespace          string
+	scalerIndex        int
+}
+
+// Validate checks the stats are read from an interceptor or from the sidecars of the pods
+func (m *requestConcurrencyMetadata) Validate() error {
+	if (m.StatsURL == "") == (m.PodSelector == "") {
+		return fmt.Errorf("exactly one of statsURL or podSelector must be given")
+	}
+	if m.PodSelector != "" && m.StatsPort == nil {
+		return fmt.Errorf("statsPort is required with podSelector")
+	}
+	if m.TargetConcurrency <= 0 {
+		return fmt.Errorf("targetConcurrency must be greater than 0")
+	}
+	return nil
+}
+
+// requestConcurrencyStats is the JSON format of the stats served by the interceptors and the sidecars
+type requestConcurrencyStats struct {
+	// InFlightRequests are the requests being processed by the scale target
+	InFlightRequests float64 `json:"inFlightRequests"`
+	// PendingRequests are the requests buffered until the scale target can process them
+	PendingRequests float64 `json:"pendingRequests"`
+}
+
+// NewRequestConcurrencyScaler creates a new scaler reporting the in-flight requests to the scale target from the
+// stats of an interceptor or of the sidecars of its pods, the replicas are scaled on the concurrency like the Knative
+// Pod Autoscaler does. The requests buffered by the interceptor activate the target scaled to zero
+func NewRequestConcurrencyScaler(kubeClient client.Client, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseRequestConcurrencyMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request-concurrency metadata: %s", err)
+	}
+
+	return &requestConcurrencyScaler{
+		metadata:   meta,
+		kubeClient: kubeClient,
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, false),
+		logger:     InitializeLogger(config, "request_concurrency_scaler"),
+	}, nil
+}
+
+func parseRequestConcurrencyMetadata(config *ScalerConfig) (*requestConcurrencyMetadata, error) {
+	meta := &requestConcurrencyMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+
+	if meta.PodSelector != "" {
+		selector, err := labels.Parse(meta.PodSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid podSelector: %s", err)
+		}
+		meta.podSelector = selector
+	}
+	if !strings.HasPrefix(meta.StatsPath, "/") {
+		meta.StatsPath = "/" + meta.StatsPath
+	}
+
+	var err error
+	if meta.activationListener, err = parseNotificationListener(config); err != nil {
+		return nil, err
+	}
+	meta.namespace = config.Namespace
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// getStats reads the stats of an interceptor or a sidecar
+func (s *requestConcurrencyScaler) getStats(ctx context.Context, statsURL string) (*requestConcurrencyStats, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d: %s", statsURL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	stats := &requestConcurrencyStats{}
+	if err := json.Unmarshal(body, stats); err != nil {
+		return nil, fmt.Errorf("error parsing the stats of %s: %s", statsURL, err)
+	}
+	return stats, nil
+}
+
+// getConcurrency returns the in-flight and the pending requests to the scale target
+func (s *requestConcurrencyScaler) getConcurrency(ctx context.Context) (float64, error) {
+	if s.metadata.StatsURL != "" {
+		stats, err := s.getStats(ctx, s.metadata.StatsURL)
+		if err != nil {
+			return 0, err
+		}
+		return stats.InFlightRequests + stats.PendingRequests, nil
+	}
+
+	pods := &corev1.PodList{}
+	err := s.kubeClient.List(ctx, pods, client.InNamespace(s.metadata.namespace), client.MatchingLabelsSelector{Selector: s.metadata.podSelector})
+	if err != nil {
+		return 0, err
+	}
+
+	var concurrency float64
+	var lastErr error
+	reached := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		statsURL := fmt.Sprintf("http://%s%s", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(*s.metadata.StatsPort))), s.metadata.StatsPath)
+		stats, err := s.getStats(ctx, statsURL)
+		if err != nil {
+			// the sidecar of a starting pod may not serve its stats yet
+			s.logger.V(1).Info("Skipping the stats of the pod", "pod", pod.Name, "error", err.Error())
+			lastErr = err
+			continue
+		}
+		reached++
+		concurrency += stats.InFlightRequests + stats.PendingRequests
+	}
+	if reached == 0 && lastErr != nil {
+		return 0, fmt.Errorf("no pod served its stats: %s", lastErr)
+	}
+	return concurrency, nil
+}
+
+// IsActive returns true if the scale target has requests in flight or buffered
+func (s *requestConcurrencyScaler) IsActive(ctx context.Context) (bool, error) {
+	concurrency, err := s.getConcurrency(ctx)
+	if err != nil {
+		s.logger.Error(err, "error getting the concurrency")
+		return false, err
+	}
+	return concurrency > 0, nil
+}
+
+// ListenActivation relays the notifications of the interceptor pointed to the notification endpoint of the operator,
+// so a request buffered for a target scaled to zero activates it without waiting for the polling interval
+func (s *requestConcurrencyScaler) ListenActivation(ctx context.Context, notify chan<- struct{}) error {
+	return s.metadata.activationListener.listen(ctx, notify)
+}
+
+func (s *requestConcurrencyScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+func (s *requestConcurrencyScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("request-concurrency-%s", s.metadata.namespace))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetConcurrency),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the in-flight and the pending requests to the scale target
+func (s *requestConcurrencyScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	concurrency, err := s.getConcurrency(ctx)
+	if err != nil {
+		s.logger.Error(err, "error getting the concurrency")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, concurrency)
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
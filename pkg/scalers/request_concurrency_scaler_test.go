@@ -0,0 +1,121 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type parseRequestConcurrencyMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+var testRequestConcurrencyMetadata = []parseRequestConcurrencyMetadataTestData{
+	{map[string]string{}, nil, true},
+	{map[string]string{"statsURL": "http://interceptor:9090/stats", "targetConcurrency": "10"}, nil, false},
+	{map[string]string{"podSelector": "app=checkout", "statsPort": "9091", "targetConcurrency": "10"}, nil, false},
+	// both sources
+	{map[string]string{"statsURL": "http://interceptor:9090/stats", "podSelector": "app=checkout", "statsPort": "9091", "targetConcurrency": "10"}, nil, true},
+	// podSelector without statsPort
+	{map[string]string{"podSelector": "app=checkout", "targetConcurrency": "10"}, nil, true},
+	// invalid podSelector
+	{map[string]string{"podSelector": "app in checkout", "statsPort": "9091", "targetConcurrency": "10"}, nil, true},
+	// no targetConcurrency
+	{map[string]string{"statsURL": "http://interceptor:9090/stats"}, nil, true},
+	{map[string]string{"statsURL": "http://interceptor:9090/stats", "targetConcurrency": "0"}, nil, true},
+	// activation notifications
+	{map[string]string{"statsURL": "http://interceptor:9090/stats", "targetConcurrency": "10", "activationNotificationKey": "checkout"}, map[string]string{"activationNotificationToken": "secret"}, false},
+	{map[string]string{"statsURL": "http://interceptor:9090/stats", "targetConcurrency": "10", "activationNotificationKey": "checkout"}, nil, true},
+}
+
+func TestRequestConcurrencyParseMetadata(t *testing.T) {
+	for i, testData := range testRequestConcurrencyMetadata {
+		_, err := parseRequestConcurrencyMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, Namespace: "shop"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestRequestConcurrencyFromInterceptor(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stats", r.URL.Path)
+		fmt.Fprint(w, `{"inFlightRequests": 0, "pendingRequests": 3}`)
+	}))
+	defer backend.Close()
+
+	scaler, err := NewRequestConcurrencyScaler(nil, &ScalerConfig{
+		TriggerMetadata: map[string]string{"statsURL": backend.URL + "/stats", "targetConcurrency": "10"},
+		Namespace:       "shop",
+	})
+	assert.NoError(t, err)
+
+	// the requests buffered for a target scaled to zero activate it
+	active, err := scaler.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	metrics, err := scaler.GetMetrics(context.Background(), "s0-request-concurrency-shop", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), metrics[0].Value.Value())
+}
+
+func TestRequestConcurrencyFromSidecars(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/queue/stats", r.URL.Path)
+		fmt.Fprint(w, `{"inFlightRequests": 4, "pendingRequests": 1}`)
+	}))
+	defer backend.Close()
+	host, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	assert.NoError(t, err)
+
+	pod := func(name string, phase corev1.PodPhase, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "checkout"}},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+		}
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		pod("checkout-1", corev1.PodRunning, host),
+		pod("checkout-2", corev1.PodRunning, host),
+		// the pending and the unscheduled pods are skipped
+		pod("checkout-3", corev1.PodPending, host),
+		pod("checkout-4", corev1.PodRunning, ""),
+	).Build()
+
+	scaler, err := NewRequestConcurrencyScaler(kubeClient, &ScalerConfig{
+		TriggerMetadata: map[string]string{"podSelector": "app=checkout", "statsPort": port, "statsPath": "queue/stats", "targetConcurrency": "10"},
+		Namespace:       "shop",
+	})
+	assert.NoError(t, err)
+
+	concurrency, err := scaler.(*requestConcurrencyScaler).getConcurrency(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), concurrency)
+}
+
+func TestRequestConcurrencyGetMetricSpecForScaling(t *testing.T) {
+	scaler, err := NewRequestConcurrencyScaler(nil, &ScalerConfig{
+		TriggerMetadata: map[string]string{"statsURL": "http://interceptor:9090/stats", "targetConcurrency": "2.5"},
+		Namespace:       "shop",
+		ScalerIndex:     1,
+	})
+	assert.NoError(t, err)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, "s1-request-concurrency-shop", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(2500), metricSpec[0].External.Target.AverageValue.MilliValue())
+}
@@ -18,14 +18,23 @@ package scalers
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/go-logr/logr"
 	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	metrics "github.com/rcrowley/go-metrics"
 )
 
@@ -51,6 +60,25 @@ type Scaler interface {
 	Close(ctx context.Context) error
 }
 
+// PartitionedScaler is implemented by the scalers of partitioned sources,
+// a consumer group can't consume with more consumers than partitions
+type PartitionedScaler interface {
+	Scaler
+
+	// GetPartitionCount returns the number of partitions of the source discovered at runtime
+	GetPartitionCount(ctx context.Context) (int64, error)
+}
+
+// MultiMetricScaler is implemented by the scalers exposing several metrics whose values are
+// fetched in one backend call
+type MultiMetricScaler interface {
+	Scaler
+
+	// GetAllMetrics returns the values of all the metrics of GetMetricSpecForScaling, named after their specs,
+	// for the criteria matching the selector
+	GetAllMetrics(ctx context.Context, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error)
+}
+
 // PushScaler interface
 type PushScaler interface {
 	Scaler
@@ -59,6 +87,27 @@ type PushScaler interface {
 	Run(ctx context.Context, active chan<- bool)
 }
 
+// ActivationListener is implemented by the scalers of brokers able to notify the arrival of messages,
+// a notification checks the scalers right away instead of waiting for the polling interval
+type ActivationListener interface {
+	Scaler
+
+	// ListenActivation sends on notify when messages arrive until ctx is done,
+	// it returns nil right away if the listener isn't enabled in the trigger metadata
+	ListenActivation(ctx context.Context, notify chan<- struct{}) error
+}
+
+// StatefulScaler is implemented by the scalers keeping state between their queries, eg. the last value of a
+// query, the state is persisted so it survives the restarts of the operator
+type StatefulScaler interface {
+	Scaler
+
+	// State returns the state of the scaler, nil when it has none yet
+	State() ([]byte, error)
+	// RestoreState restores the state returned by State, it is called once the scaler is built
+	RestoreState(state []byte) error
+}
+
 // ScalerConfig contains config fields common for all scalers
 type ScalerConfig struct {
 	// Name used for external scalers
@@ -84,6 +133,51 @@ type ScalerConfig struct {
 
 	// ScalerIndex
 	ScalerIndex int
+
+	// MetricType
+	MetricType v2beta2.MetricTargetType
+
+	// RetryPolicy of the HTTP requests of the scaler, set from the retries and retryBackoff metadata
+	RetryPolicy kedautil.RetryPolicy
+
+	// HTTPTransport overrides the options of the transport of the HTTP client of the scaler, set from the
+	// httpMaxIdleConns, httpIdleConnTimeout and httpEnableHTTP2 metadata
+	HTTPTransport kedautil.HTTPTransportOptions
+
+	// LogVerbosity raises the verbosity of the logs of the scaler, set from the LogVerbosityAnnotation
+	LogVerbosity int
+}
+
+// InitializeLogger returns the logger of a scaler, its messages carry the ScaledObject and the index of the trigger
+// and their verbosity is raised by the LogVerbosity of the config
+func InitializeLogger(config *ScalerConfig, scalerName string) logr.Logger {
+	logger := logf.Log.WithName(scalerName).WithValues("scaledObject.Namespace", config.Namespace, "scaledObject.Name", config.Name, "scalerIndex", config.ScalerIndex)
+	return kedautil.WithVerbosityBoost(logger, config.LogVerbosity)
+}
+
+// createHTTPClient returns the HTTP client of a scaler, its connections are restricted by the egress
+// policy of the namespace, its transport is tuned with the HTTPTransport options of the trigger and its
+// requests are retried with the RetryPolicy of the trigger
+func createHTTPClient(config *ScalerConfig, timeout time.Duration, unsafeSsl bool) *http.Client {
+	return createTLSHTTPClient(config, timeout, &tls.Config{InsecureSkipVerify: unsafeSsl})
+}
+
+// createTLSHTTPClient returns the HTTP client of a scaler like createHTTPClient, its connections use tlsConfig
+func createTLSHTTPClient(config *ScalerConfig, timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	httpClient := kedautil.CreateHTTPClientForNamespace(config.Namespace, timeout, false)
+	transport := httpClient.Transport.(*http.Transport)
+	transport.TLSClientConfig = tlsConfig
+	config.HTTPTransport.Apply(transport)
+	httpClient.Transport = kedautil.NewRetryTransport(transport, config.RetryPolicy)
+	return httpClient
+}
+
+// closeIdleConnections releases the pooled connections of the HTTP client of a scaler when it is closed,
+// the client can be nil
+func closeIdleConnections(httpClient *http.Client) {
+	if httpClient != nil {
+		httpClient.CloseIdleConnections()
+	}
 }
 
 // GetFromAuthOrMeta helps getting a field from Auth or Meta sections
@@ -105,3 +199,51 @@ func GetFromAuthOrMeta(config *ScalerConfig, field string) (string, error) {
 func GenerateMetricNameWithIndex(scalerIndex int, metricName string) string {
 	return fmt.Sprintf("s%d-%s", scalerIndex, metricName)
 }
+
+// getIntMetadataValue parses the int parameter key of the trigger metadata, defaultValue is returned when the
+// parameter isn't given unless it is required
+func getIntMetadataValue(metadata map[string]string, key string, required bool, defaultValue int64) (int64, error) {
+	if val, ok := metadata[key]; ok && val != "" {
+		value, err := strconv.Atoi(val)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing %s: %s", key, err)
+		}
+		return int64(value), nil
+	}
+
+	if required {
+		return 0, fmt.Errorf("no %s given", key)
+	}
+
+	return defaultValue, nil
+}
+
+// getFloatMetadataValue parses the float64 parameter key of the trigger metadata, e.g. the target value of a metric,
+// defaultValue is returned when the parameter isn't given unless it is required. The value can have a quantity
+// suffix, eg. 2.5k
+func getFloatMetadataValue(metadata map[string]string, key string, required bool, defaultValue float64) (float64, error) {
+	return getUnitMetadataValue(metadata, key, unitCount, required, defaultValue)
+}
+
+// GenerateMetricInMili returns the external metric of a value with milli precision,
+// the fractional part of the float64 values of the scalers isn't truncated on its way to the HPA
+func GenerateMetricInMili(metricName string, value float64) external_metrics.ExternalMetricValue {
+	return external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *newMilliQuantity(value),
+		Timestamp:  metav1.Now(),
+	}
+}
+
+// GetMetricTargetMili returns the average value target of a metric with milli precision
+func GetMetricTargetMili(value float64) v2beta2.MetricTarget {
+	return v2beta2.MetricTarget{
+		Type:         v2beta2.AverageValueMetricType,
+		AverageValue: newMilliQuantity(value),
+	}
+}
+
+// newMilliQuantity returns the quantity of a value rounded to milli precision
+func newMilliQuantity(value float64) *resource.Quantity {
+	return resource.NewMilliQuantity(int64(math.Round(value*1000)), resource.DecimalSI)
+}
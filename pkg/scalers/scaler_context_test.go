@@ -0,0 +1,88 @@
+package scalers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// contextBoundMethods are called by the scale loop and the metrics adapter with a context canceled on timeout or
+// shutdown, their backend calls must be made with it
+var contextBoundMethods = map[string]bool{
+	"IsActive":          true,
+	"GetMetrics":        true,
+	"GetAllMetrics":     true,
+	"GetPartitionCount": true,
+}
+
+// contextFreeScalers have no backend, their metrics are read by the HPA from the other metrics APIs
+var contextFreeScalers = map[string]bool{
+	"cpuMemoryScaler": true,
+	"objectScaler":    true,
+}
+
+func TestScalersUseContext(t *testing.T) {
+	files, err := filepath.Glob("*_scaler.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("error parsing %s: %s", file, err)
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil || !contextBoundMethods[fn.Name.Name] {
+				continue
+			}
+			if contextFreeScalers[receiverName(fn)] {
+				continue
+			}
+			if ctx := contextParam(fn); ctx == "" || !usesIdent(fn.Body, ctx) {
+				t.Errorf("%s: %s.%s must pass its context to the backend calls", fset.Position(fn.Pos()), receiverName(fn), fn.Name.Name)
+			}
+		}
+	}
+}
+
+func receiverName(fn *ast.FuncDecl) string {
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// contextParam returns the name of the context.Context parameter of fn, empty if it is unnamed
+func contextParam(fn *ast.FuncDecl) string {
+	for _, param := range fn.Type.Params.List {
+		sel, ok := param.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" || len(param.Names) == 0 {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "context" && !strings.HasPrefix(param.Names[0].Name, "_") {
+			return param.Names[0].Name
+		}
+	}
+	return ""
+}
+
+func usesIdent(body *ast.BlockStmt, name string) bool {
+	used := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
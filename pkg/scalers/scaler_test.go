@@ -0,0 +1,48 @@
+package scalers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+)
+
+type getFloatMetadataValueTestData struct {
+	metadata     map[string]string
+	required     bool
+	defaultValue float64
+	expected     float64
+	isError      bool
+}
+
+var testGetFloatMetadataValues = []getFloatMetadataValueTestData{
+	{map[string]string{"targetValue": "10"}, true, 0, 10, false},
+	{map[string]string{"targetValue": "2.5"}, true, 0, 2.5, false},
+	{map[string]string{"targetValue": ""}, false, 5, 5, false},
+	{map[string]string{}, false, 5, 5, false},
+	{map[string]string{}, true, 0, 0, true},
+	{map[string]string{"targetValue": "ten"}, false, 5, 0, true},
+}
+
+func TestGetFloatMetadataValue(t *testing.T) {
+	for _, test := range testGetFloatMetadataValues {
+		value, err := getFloatMetadataValue(test.metadata, "targetValue", test.required, test.defaultValue)
+		if test.isError {
+			assert.Error(t, err, test.metadata)
+			continue
+		}
+		assert.NoError(t, err, test.metadata)
+		assert.Equal(t, test.expected, value, test.metadata)
+	}
+}
+
+func TestGenerateMetricInMili(t *testing.T) {
+	metric := GenerateMetricInMili("s0-metric", 2.4305)
+	assert.Equal(t, "s0-metric", metric.MetricName)
+	assert.Equal(t, int64(2431), metric.Value.MilliValue())
+	assert.False(t, metric.Timestamp.IsZero())
+
+	target := GetMetricTargetMili(0.25)
+	assert.Equal(t, v2beta2.AverageValueMetricType, target.Type)
+	assert.Equal(t, int64(250), target.AverageValue.MilliValue())
+}
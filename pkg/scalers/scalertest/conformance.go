@@ -0,0 +1,118 @@
+// Package scalertest checks the behavior shared by all the scalers, the scalers are run against a fake backend
+// and must honor the contract of the Scaler interface the scale loop and the metrics adapter rely on
+package scalertest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/autoscaling/v2beta2"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// conformanceScalerIndex is the index of the scaler under test, its metric names must start with s3-
+const conformanceScalerIndex = 3
+
+// Case is a scaler under test with the config of its fake backend
+type Case struct {
+	// Name of the test
+	Name string
+
+	// NewScaler is the constructor of the scaler
+	NewScaler func(config *scalers.ScalerConfig) (scalers.Scaler, error)
+
+	// Config of the scaler, its ScalerIndex is set by the harness
+	Config scalers.ScalerConfig
+
+	// Active is the expected activity of the scaler on the fake backend
+	Active bool
+
+	// SkipCancellation is set for the scalers without backend calls, eg. cron
+	SkipCancellation bool
+}
+
+// Run checks the scaler of the case:
+//   - its metric specs are named with the scaler index and have a positive target
+//   - its metrics are returned under the requested name
+//   - IsActive returns the expected activity
+//   - its backend calls fail once the context is canceled
+//   - Close can be called twice
+func Run(t *testing.T, c Case) {
+	t.Run(c.Name, func(t *testing.T) {
+		config := c.Config
+		config.ScalerIndex = conformanceScalerIndex
+		scaler, err := c.NewScaler(&config)
+		require.NoError(t, err, "error creating the scaler")
+		defer func() {
+			assert.NoError(t, scaler.Close(context.Background()), "first Close")
+			assert.NoError(t, scaler.Close(context.Background()), "Close must be idempotent")
+		}()
+
+		ctx := context.Background()
+		specs := scaler.GetMetricSpecForScaling(ctx)
+		require.NotEmpty(t, specs, "no metric spec")
+
+		for _, name := range checkMetricSpecs(t, specs) {
+			metrics, err := scaler.GetMetrics(ctx, name, nil)
+			if assert.NoError(t, err, "GetMetrics of %s", name) && assert.NotEmpty(t, metrics, "GetMetrics of %s", name) {
+				for _, metric := range metrics {
+					assert.Equal(t, name, metric.MetricName, "metrics must be returned under the requested name")
+				}
+			}
+		}
+
+		active, err := scaler.IsActive(ctx)
+		assert.NoError(t, err, "IsActive")
+		assert.Equal(t, c.Active, active, "IsActive")
+
+		if !c.SkipCancellation {
+			canceled, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = scaler.IsActive(canceled)
+			assert.Error(t, err, "IsActive must honor the canceled context")
+			for _, spec := range specs {
+				if spec.External == nil {
+					continue
+				}
+				_, err = scaler.GetMetrics(canceled, spec.External.Metric.Name, nil)
+				assert.Error(t, err, "GetMetrics must honor the canceled context")
+			}
+		}
+	})
+}
+
+// checkMetricSpecs checks the metric specs and returns the names of their external metrics
+func checkMetricSpecs(t *testing.T, specs []v2beta2.MetricSpec) []string {
+	var names []string
+	for _, spec := range specs {
+		if spec.External == nil {
+			// the resource and object metrics are read by the HPA from the other metrics APIs
+			continue
+		}
+		name := spec.External.Metric.Name
+		assert.True(t, strings.HasPrefix(name, scalers.GenerateMetricNameWithIndex(conformanceScalerIndex, "")),
+			"metric %s must be prefixed with the scaler index", name)
+		assert.Equal(t, kedautil.NormalizeString(name), name, "metric %s must be normalized", name)
+
+		target := spec.External.Target
+		switch target.Type {
+		case v2beta2.AverageValueMetricType:
+			if assert.NotNil(t, target.AverageValue, "metric %s has no average value target", name) {
+				assert.Positive(t, target.AverageValue.MilliValue(), "metric %s target", name)
+			}
+		case v2beta2.ValueMetricType:
+			if assert.NotNil(t, target.Value, "metric %s has no value target", name) {
+				assert.Positive(t, target.Value.MilliValue(), "metric %s target", name)
+			}
+		default:
+			t.Errorf("metric %s has an unsupported target type %q", name, target.Type)
+		}
+		names = append(names, name)
+	}
+	return names
+}
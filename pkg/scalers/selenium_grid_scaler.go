@@ -11,12 +11,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-logr/logr"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/metrics/pkg/apis/external_metrics"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -24,6 +24,7 @@ import (
 type seleniumGridScaler struct {
 	metadata *seleniumGridScalerMetadata
 	client   *http.Client
+	logger   logr.Logger
 }
 
 type seleniumGridScalerMetadata struct {
@@ -63,8 +64,6 @@ const (
 	DefaultBrowserVersion string = "latest"
 )
 
-var seleniumGridLog = logf.Log.WithName("selenium_grid_scaler")
-
 func NewSeleniumGridScaler(config *ScalerConfig) (Scaler, error) {
 	meta, err := parseSeleniumGridScalerMetadata(config)
 
@@ -72,11 +71,12 @@ func NewSeleniumGridScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing selenium grid metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, meta.unsafeSsl)
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, meta.unsafeSsl)
 
 	return &seleniumGridScaler{
 		metadata: meta,
 		client:   httpClient,
+		logger:   InitializeLogger(config, "selenium_grid_scaler"),
 	}, nil
 }
 
@@ -117,6 +117,7 @@ func parseSeleniumGridScalerMetadata(config *ScalerConfig) (*seleniumGridScalerM
 
 // No cleanup required for selenium grid scaler
 func (s *seleniumGridScaler) Close(context.Context) error {
+	closeIdleConnections(s.client)
 	return nil
 }
 
@@ -191,14 +192,14 @@ func (s *seleniumGridScaler) getSessionsCount(ctx context.Context) (*resource.Qu
 	if err != nil {
 		return nil, err
 	}
-	v, err := getCountFromSeleniumResponse(b, s.metadata.browserName, s.metadata.browserVersion)
+	v, err := getCountFromSeleniumResponse(b, s.metadata.browserName, s.metadata.browserVersion, s.logger)
 	if err != nil {
 		return nil, err
 	}
 	return v, nil
 }
 
-func getCountFromSeleniumResponse(b []byte, browserName string, browserVersion string) (*resource.Quantity, error) {
+func getCountFromSeleniumResponse(b []byte, browserName string, browserVersion string, logger logr.Logger) (*resource.Quantity, error) {
 	var count int64
 	var seleniumResponse = seleniumResponse{}
 
@@ -218,7 +219,7 @@ func getCountFromSeleniumResponse(b []byte, browserName string, browserVersion s
 				}
 			}
 		} else {
-			seleniumGridLog.Error(err, fmt.Sprintf("Error when unmarshaling session queue requests: %s", err))
+			logger.Error(err, "Error when unmarshaling session queue requests")
 		}
 	}
 
@@ -234,7 +235,7 @@ func getCountFromSeleniumResponse(b []byte, browserName string, browserVersion s
 				}
 			}
 		} else {
-			seleniumGridLog.Error(err, fmt.Sprintf("Error when unmarshaling sessions info: %s", err))
+			logger.Error(err, "Error when unmarshaling sessions info")
 		}
 	}
 
@@ -0,0 +1,191 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	simulatorConstant   = "constant"
+	simulatorStep       = "step"
+	simulatorSine       = "sine"
+	simulatorRandomWalk = "randomWalk"
+)
+
+type simulatorScaler struct {
+	metadata   *simulatorMetadata
+	kubeClient client.Client
+	now        func() time.Time
+
+	// the random walk is kept by the scaler, it restarts from value when the scaler is rebuilt
+	walkLock sync.Mutex
+	walk     float64
+	random   *rand.Rand
+}
+
+type simulatorMetadata struct {
+	// Waveform is the shape of the value over time:
+	//   - constant: value
+	//   - step: value and value + amplitude in turn, each for half of the period
+	//   - sine: value + amplitude * sin(2π t / period)
+	//   - randomWalk: moves by up to step at each read, between value - amplitude and value + amplitude
+	// the waves are aligned on the wall clock so the replicas of the operator report the same value
+	Waveform  string        `keda:"name=waveform, default=constant, enum=constant;step;sine;randomWalk"`
+	Value     float64       `keda:"name=value, default=0"`
+	Amplitude float64       `keda:"name=amplitude, default=0"`
+	Period    time.Duration `keda:"name=period, default=10m"`
+	Step      float64       `keda:"name=step, default=1"`
+
+	// ConfigMapName is a ConfigMap of the namespace whose ConfigMapKey holds the value, it is edited to drive the
+	// scaling by hand instead of the waveform
+	ConfigMapName string `keda:"name=configMapName, optional"`
+	ConfigMapKey  string `keda:"name=configMapKey, default=value"`
+
+	TargetValue float64 `keda:"name=targetValue, default=1"`
+
+	namespace   string
+	scalerIndex int
+}
+
+// Validate checks the parameters of the waveform
+func (m *simulatorMetadata) Validate() error {
+	if m.ConfigMapName != "" && m.Waveform != simulatorConstant {
+		return fmt.Errorf("configMapName can't be used with the %s waveform", m.Waveform)
+	}
+	if m.Period <= 0 {
+		return fmt.Errorf("period must be greater than 0")
+	}
+	if m.Amplitude < 0 || m.Step < 0 {
+		return fmt.Errorf("amplitude and step must not be negative")
+	}
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	return nil
+}
+
+// NewSimulatorScaler creates a new scaler reporting a simulated value, from a waveform or a ConfigMap, so the scaling
+// behavior can be load-tested and demoed without a real backend
+func NewSimulatorScaler(kubeClient client.Client, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseSimulatorMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing simulator metadata: %s", err)
+	}
+
+	return &simulatorScaler{
+		metadata:   meta,
+		kubeClient: kubeClient,
+		now:        time.Now,
+		walk:       meta.Value,
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+func parseSimulatorMetadata(config *ScalerConfig) (*simulatorMetadata, error) {
+	meta := &simulatorMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+	meta.namespace = config.Namespace
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// getValue returns the simulated value, the negative values are reported as 0
+func (s *simulatorScaler) getValue(ctx context.Context) (float64, error) {
+	if s.metadata.ConfigMapName != "" {
+		return s.getConfigMapValue(ctx)
+	}
+
+	var value float64
+	// the phase of the wave in [0, 1)
+	phase := float64(s.now().UnixNano()%int64(s.metadata.Period)) / float64(s.metadata.Period)
+	switch s.metadata.Waveform {
+	case simulatorStep:
+		value = s.metadata.Value
+		if phase >= 0.5 {
+			value += s.metadata.Amplitude
+		}
+	case simulatorSine:
+		value = s.metadata.Value + s.metadata.Amplitude*math.Sin(2*math.Pi*phase)
+	case simulatorRandomWalk:
+		s.walkLock.Lock()
+		s.walk += (s.random.Float64()*2 - 1) * s.metadata.Step
+		s.walk = math.Min(math.Max(s.walk, s.metadata.Value-s.metadata.Amplitude), s.metadata.Value+s.metadata.Amplitude)
+		value = s.walk
+		s.walkLock.Unlock()
+	default:
+		value = s.metadata.Value
+	}
+	return math.Max(value, 0), nil
+}
+
+func (s *simulatorScaler) getConfigMapValue(ctx context.Context) (float64, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := s.kubeClient.Get(ctx, types.NamespacedName{Namespace: s.metadata.namespace, Name: s.metadata.ConfigMapName}, configMap); err != nil {
+		return 0, err
+	}
+	val, ok := configMap.Data[s.metadata.ConfigMapKey]
+	if !ok {
+		return 0, fmt.Errorf("key %s not found in ConfigMap %s", s.metadata.ConfigMapKey, s.metadata.ConfigMapName)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing the value of the key %s of ConfigMap %s: %s", s.metadata.ConfigMapKey, s.metadata.ConfigMapName, err)
+	}
+	return math.Max(value, 0), nil
+}
+
+// IsActive returns true if the simulated value is positive
+func (s *simulatorScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		return false, err
+	}
+	return value > 0, nil
+}
+
+// Close no need for simulator scaler
+func (s *simulatorScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *simulatorScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("simulator-%s", s.metadata.Waveform))
+	if s.metadata.ConfigMapName != "" {
+		metricName = kedautil.NormalizeString(fmt.Sprintf("simulator-%s", s.metadata.ConfigMapName))
+	}
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the simulated value
+func (s *simulatorScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error getting the simulated value: %s", err)
+	}
+	return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, value)), nil
+}
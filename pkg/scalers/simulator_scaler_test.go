@@ -0,0 +1,123 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type parseSimulatorMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+var testSimulatorMetadata = []parseSimulatorMetadataTestData{
+	{map[string]string{}, false},
+	{map[string]string{"waveform": "sine", "value": "10", "amplitude": "5", "period": "5m", "targetValue": "2"}, false},
+	{map[string]string{"waveform": "randomWalk", "value": "10", "amplitude": "5", "step": "0.5"}, false},
+	{map[string]string{"configMapName": "load", "configMapKey": "queue"}, false},
+	// unknown waveform
+	{map[string]string{"waveform": "square"}, true},
+	// configMapName with a waveform
+	{map[string]string{"waveform": "step", "configMapName": "load"}, true},
+	{map[string]string{"waveform": "sine", "period": "0s"}, true},
+	{map[string]string{"waveform": "sine", "amplitude": "-1"}, true},
+	{map[string]string{"targetValue": "0"}, true},
+}
+
+func TestSimulatorParseMetadata(t *testing.T) {
+	for i, testData := range testSimulatorMetadata {
+		_, err := parseSimulatorMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "demo"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestSimulatorWaveforms(t *testing.T) {
+	newScaler := func(metadata map[string]string, at time.Duration) *simulatorScaler {
+		scaler, err := NewSimulatorScaler(nil, &ScalerConfig{TriggerMetadata: metadata, Namespace: "demo"})
+		assert.NoError(t, err)
+		s := scaler.(*simulatorScaler)
+		s.now = func() time.Time { return time.Unix(0, 0).Add(at) }
+		return s
+	}
+
+	value, err := newScaler(map[string]string{"value": "4"}, 0).getValue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), value)
+
+	step := map[string]string{"waveform": "step", "value": "1", "amplitude": "9", "period": "10m"}
+	value, _ = newScaler(step, 2*time.Minute).getValue(context.Background())
+	assert.Equal(t, float64(1), value)
+	value, _ = newScaler(step, 7*time.Minute).getValue(context.Background())
+	assert.Equal(t, float64(10), value)
+
+	sine := map[string]string{"waveform": "sine", "value": "2", "amplitude": "4", "period": "4m"}
+	value, _ = newScaler(sine, time.Minute).getValue(context.Background())
+	assert.InDelta(t, 6, value, 1e-9)
+	// the negative values are reported as 0
+	value, _ = newScaler(sine, 3*time.Minute).getValue(context.Background())
+	assert.Equal(t, float64(0), value)
+
+	walk := newScaler(map[string]string{"waveform": "randomWalk", "value": "10", "amplitude": "3", "step": "2"}, 0)
+	previous := float64(10)
+	for i := 0; i < 100; i++ {
+		value, err = walk.getValue(context.Background())
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, value, float64(7))
+		assert.LessOrEqual(t, value, float64(13))
+		assert.LessOrEqual(t, value-previous, float64(2))
+		assert.GreaterOrEqual(t, value-previous, float64(-2))
+		previous = value
+	}
+}
+
+func TestSimulatorFromConfigMap(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "load", Namespace: "demo"},
+		Data:       map[string]string{"queue": " 12.5\n", "broken": "many"},
+	}).Build()
+	newScaler := func(key string) Scaler {
+		scaler, err := NewSimulatorScaler(kubeClient, &ScalerConfig{
+			TriggerMetadata: map[string]string{"configMapName": "load", "configMapKey": key, "targetValue": "5"},
+			Namespace:       "demo",
+		})
+		assert.NoError(t, err)
+		return scaler
+	}
+
+	scaler := newScaler("queue")
+	active, err := scaler.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+	metrics, err := scaler.GetMetrics(context.Background(), "s0-simulator-load", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12500), metrics[0].Value.MilliValue())
+
+	_, err = newScaler("broken").IsActive(context.Background())
+	assert.Error(t, err)
+	_, err = newScaler("missing").IsActive(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSimulatorGetMetricSpecForScaling(t *testing.T) {
+	scaler, err := NewSimulatorScaler(nil, &ScalerConfig{
+		TriggerMetadata: map[string]string{"waveform": "sine", "targetValue": "2.5"},
+		Namespace:       "demo",
+		ScalerIndex:     1,
+	})
+	assert.NoError(t, err)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, "s1-simulator-sine", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(2500), metricSpec[0].External.Target.AverageValue.MilliValue())
+}
@@ -0,0 +1,231 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	sloErrorQuery = "errorQuery"
+	sloTotalQuery = "totalQuery"
+	sloObjective  = "objective"
+	sloWindows    = "windows"
+	sloThreshold  = "threshold"
+
+	// sloWindowPlaceholder is replaced with the window in the queries, eg. `sum(rate(http_requests_total{code=~"5.."}[$window]))`
+	sloWindowPlaceholder = "$window"
+
+	defaultSLOWindows    = "1h,6h"
+	defaultSLOThreshold  = 1
+	defaultSLOMetricName = "burn-rate"
+)
+
+// sloWindowRegexp matches the Prometheus durations accepted as windows
+var sloWindowRegexp = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d|w|y)$`)
+
+type sloBurnRateScaler struct {
+	metadata *sloBurnRateMetadata
+	windows  []sloBurnRateWindow
+}
+
+type sloBurnRateMetadata struct {
+	metricName string
+	// errorBudget is the allowed ratio of errors, 1 - objective
+	errorBudget float64
+	threshold   float64
+	windows     []string
+	scalerIndex int
+}
+
+// sloBurnRateWindow holds the queries of the error and the total count over a window
+type sloBurnRateWindow struct {
+	window string
+	errors *prometheusScaler
+	total  *prometheusScaler
+}
+
+var sloBurnRateLog = logf.Log.WithName("slo_burn_rate_scaler")
+
+// NewSLOBurnRateScaler creates a new scaler reporting the error budget burn rate of an SLO from Prometheus queries
+func NewSLOBurnRateScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseSLOBurnRateMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing slo-burn-rate metadata: %s", err)
+	}
+
+	scaler := &sloBurnRateScaler{metadata: meta}
+	for _, window := range meta.windows {
+		errors, err := newSLOBurnRateQuery(config, config.TriggerMetadata[sloErrorQuery], window)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing slo-burn-rate metadata: %s", err)
+		}
+		total, err := newSLOBurnRateQuery(config, config.TriggerMetadata[sloTotalQuery], window)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing slo-burn-rate metadata: %s", err)
+		}
+		// all the queries use the same server and authentication
+		total.httpClient = errors.httpClient
+		scaler.windows = append(scaler.windows, sloBurnRateWindow{window: window, errors: errors, total: total})
+	}
+	return scaler, nil
+}
+
+func parseSLOBurnRateMetadata(config *ScalerConfig) (*sloBurnRateMetadata, error) {
+	meta := sloBurnRateMetadata{
+		metricName: defaultSLOMetricName,
+		threshold:  defaultSLOThreshold,
+	}
+
+	for _, key := range []string{sloErrorQuery, sloTotalQuery} {
+		val, ok := config.TriggerMetadata[key]
+		if !ok || val == "" {
+			return nil, fmt.Errorf("no %s given", key)
+		}
+		if !strings.Contains(val, sloWindowPlaceholder) {
+			return nil, fmt.Errorf("%s must use the %s placeholder", key, sloWindowPlaceholder)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata[sloObjective]; ok && val != "" {
+		objective, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", sloObjective, err)
+		}
+		if objective <= 0 || objective >= 100 {
+			return nil, fmt.Errorf("%s must be a percentage between 0 and 100, eg. 99.9", sloObjective)
+		}
+		meta.errorBudget = 1 - objective/100
+	} else {
+		return nil, fmt.Errorf("no %s given", sloObjective)
+	}
+
+	windows := defaultSLOWindows
+	if val, ok := config.TriggerMetadata[sloWindows]; ok && val != "" {
+		windows = val
+	}
+	for _, window := range strings.Split(windows, ",") {
+		window = strings.TrimSpace(window)
+		if !sloWindowRegexp.MatchString(window) {
+			return nil, fmt.Errorf("invalid window %q in %s", window, sloWindows)
+		}
+		meta.windows = append(meta.windows, window)
+	}
+
+	if val, ok := config.TriggerMetadata[sloThreshold]; ok && val != "" {
+		threshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", sloThreshold, err)
+		}
+		if threshold <= 0 {
+			return nil, fmt.Errorf("%s must be greater than 0", sloThreshold)
+		}
+		meta.threshold = threshold
+	}
+
+	if val, ok := config.TriggerMetadata[promMetricName]; ok && val != "" {
+		meta.metricName = val
+	}
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// newSLOBurnRateQuery creates the prometheus scaler executing the query over the window, the server address and
+// the authentication are parsed like the prometheus trigger does
+func newSLOBurnRateQuery(config *ScalerConfig, query, window string) (*prometheusScaler, error) {
+	metadata := make(map[string]string, len(config.TriggerMetadata)+2)
+	for key, value := range config.TriggerMetadata {
+		metadata[key] = value
+	}
+	metadata[promQuery] = strings.ReplaceAll(query, sloWindowPlaceholder, window)
+	metadata[promMetricName] = defaultSLOMetricName
+	// the threshold of the burn rate is a decimal number, unlike the threshold of the prometheus trigger
+	delete(metadata, sloThreshold)
+
+	queryConfig := *config
+	queryConfig.TriggerMetadata = metadata
+	meta, err := parsePrometheusMetadata(&queryConfig)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := newPrometheusHTTPClient(&queryConfig, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &prometheusScaler{metadata: meta, httpClient: httpClient}, nil
+}
+
+// getBurnRate returns the lowest burn rate of the windows, like a multi-window alert all the windows
+// have to burn the error budget for the burn rate to be high
+func (s *sloBurnRateScaler) getBurnRate(ctx context.Context) (float64, error) {
+	burnRate := math.Inf(1)
+	for _, window := range s.windows {
+		errors, err := window.errors.ExecutePromQuery(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("error executing the errorQuery over %s: %s", window.window, err)
+		}
+		total, err := window.total.ExecutePromQuery(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("error executing the totalQuery over %s: %s", window.window, err)
+		}
+
+		// no traffic doesn't burn the error budget
+		var windowBurnRate float64
+		if total > 0 {
+			windowBurnRate = errors / total / s.metadata.errorBudget
+		}
+		burnRate = math.Min(burnRate, windowBurnRate)
+	}
+	return burnRate, nil
+}
+
+func (s *sloBurnRateScaler) IsActive(ctx context.Context) (bool, error) {
+	burnRate, err := s.getBurnRate(ctx)
+	if err != nil {
+		sloBurnRateLog.Error(err, "error getting the burn rate")
+		return false, err
+	}
+
+	return burnRate > 0, nil
+}
+
+func (s *sloBurnRateScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *sloBurnRateScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("slo-%s", s.metadata.metricName))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.threshold),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *sloBurnRateScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	burnRate, err := s.getBurnRate(ctx)
+	if err != nil {
+		sloBurnRateLog.Error(err, "error getting the burn rate")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, burnRate)
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
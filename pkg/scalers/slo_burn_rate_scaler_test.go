@@ -0,0 +1,100 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseSLOBurnRateMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+var testSLOBurnRateMetadata = []parseSLOBurnRateMetadataTestData{
+	{map[string]string{}, true},
+	// all properly formed
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": `sum(rate(http_requests_total{code=~"5.."}[$window]))`, "totalQuery": "sum(rate(http_requests_total[$window]))", "objective": "99.9"}, false},
+	// custom windows and threshold
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "99", "windows": "5m, 1h, 1d", "threshold": "14.4"}, false},
+	// missing serverAddress
+	{map[string]string{"errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "99"}, true},
+	// missing errorQuery
+	{map[string]string{"serverAddress": "http://localhost:9090", "totalQuery": "total[$window]", "objective": "99"}, true},
+	// totalQuery without window
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[1h]", "objective": "99"}, true},
+	// missing objective
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[$window]"}, true},
+	// objective out of range
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "100"}, true},
+	// invalid window
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "99", "windows": "1h,six hours"}, true},
+	// invalid threshold
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "99", "threshold": "0"}, true},
+	// bearer authentication without token
+	{map[string]string{"serverAddress": "http://localhost:9090", "errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "99", "authModes": "bearer"}, true},
+}
+
+func TestSLOBurnRateParseMetadata(t *testing.T) {
+	for i, testData := range testSLOBurnRateMetadata {
+		_, err := NewSLOBurnRateScaler(&ScalerConfig{TriggerMetadata: testData.metadata})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", i)
+		}
+	}
+}
+
+func TestSLOBurnRateGetMetricSpecForScaling(t *testing.T) {
+	scaler, err := NewSLOBurnRateScaler(&ScalerConfig{TriggerMetadata: testSLOBurnRateMetadata[2].metadata, ScalerIndex: 1})
+	assert.NoError(t, err)
+
+	spec := scaler.GetMetricSpecForScaling(context.Background())
+	assert.Equal(t, "s1-slo-burn-rate", spec[0].External.Metric.Name)
+	assert.Equal(t, int64(14400), spec[0].External.Target.AverageValue.MilliValue())
+}
+
+func TestSLOBurnRateGetMetrics(t *testing.T) {
+	// errors and total per window, the 6h window doesn't burn the budget as fast as the 1h window
+	values := map[string]string{
+		"errors[1h]": "3", "total[1h]": "100",
+		"errors[6h]": "1", "total[6h]": "100",
+		"errors[5m]": "0", "total[5m]": "0",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, ok := values[r.URL.Query().Get("query")]
+		assert.True(t, ok, r.URL.Query().Get("query"))
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"%s"]}]}}`, value)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		windows  string
+		expected int64
+	}{
+		{"1h", 30000},
+		{"1h,6h", 10000},
+		// no traffic in the 5m window
+		{"5m,1h", 0},
+	}
+	for _, test := range tests {
+		scaler, err := NewSLOBurnRateScaler(&ScalerConfig{TriggerMetadata: map[string]string{
+			"serverAddress": server.URL, "errorQuery": "errors[$window]", "totalQuery": "total[$window]", "objective": "99.9", "windows": test.windows,
+		}})
+		assert.NoError(t, err)
+
+		metrics, err := scaler.GetMetrics(context.Background(), "s0-slo-burn-rate", nil)
+		assert.NoError(t, err, test.windows)
+		assert.Equal(t, test.expected, metrics[0].Value.MilliValue(), test.windows)
+
+		active, err := scaler.IsActive(context.Background())
+		assert.NoError(t, err, test.windows)
+		assert.Equal(t, test.expected > 0, active, test.windows)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -43,9 +44,13 @@ const (
 	// Metric Targets
 	solaceMetaMsgCountTarget      = "messageCountTarget"
 	solaceMetaMsgSpoolUsageTarget = "messageSpoolUsageTarget"
+	solaceMetaMsgAgeTarget        = "messageAgeTarget"
 	// Trigger type identifiers
 	solaceTriggermsgcount      = "msgcount"
 	solaceTriggermsgspoolusage = "msgspoolusage"
+	solaceTriggermsgage        = "msgage"
+	// Oldest message of the queue, the messages are listed in spool order
+	solaceSempOldestMsgQuery = "/msgs?count=1&select=spooledTime"
 )
 
 // Struct for Observed Metric Values
@@ -54,6 +59,8 @@ type SolaceMetricValues struct {
 	msgCount int
 	//	Observed Message Spool Usage
 	msgSpoolUsage int
+	//	Observed Age of the Oldest Message in Seconds
+	msgAge int
 }
 
 type SolaceScaler struct {
@@ -75,6 +82,7 @@ type SolaceMetadata struct {
 	// Target Message Count
 	msgCountTarget      int
 	msgSpoolUsageTarget int // Spool Use Target in Megabytes
+	msgAgeTarget        int // Oldest Message Age Target in Seconds
 	// Scaler index
 	scalerIndex int
 }
@@ -101,6 +109,17 @@ type solaceSEMPMessages struct {
 	Count int `json:"count"`
 }
 
+// SEMP API Response Messages Root Struct
+type solaceSEMPMsgsResponse struct {
+	Data []solaceSEMPMsg    `json:"data"`
+	Meta solaceSEMPMetadata `json:"meta"`
+}
+
+// SEMP API Message Struct
+type solaceSEMPMsg struct {
+	SpooledTime int64 `json:"spooledTime"`
+}
+
 // SEMP API Metadata Struct
 type solaceSEMPMetadata struct {
 	ResponseCode int `json:"responseCode"`
@@ -112,7 +131,7 @@ var solaceLog = logf.Log.WithName(solaceScalerID + "_scaler")
 //	Constructor for SolaceScaler
 func NewSolaceScaler(config *ScalerConfig) (Scaler, error) {
 	// Create HTTP Client
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
 
 	// Parse Solace Metadata
 	solaceMetadata, err := parseSolaceMetadata(config)
@@ -167,8 +186,17 @@ func parseSolaceMetadata(config *ScalerConfig) (*SolaceMetadata, error) {
 		}
 	}
 
+	//	GET msgAgeTarget
+	if val, ok := config.TriggerMetadata[solaceMetaMsgAgeTarget]; ok && val != "" {
+		if msgAge, err := strconv.Atoi(val); err == nil {
+			meta.msgAgeTarget = msgAge
+		} else {
+			return nil, fmt.Errorf("can't parse [%s], not a valid integer: %s", solaceMetaMsgAgeTarget, err)
+		}
+	}
+
 	//	Check that we have at least one positive target value for the scaler
-	if meta.msgCountTarget < 1 && meta.msgSpoolUsageTarget < 1 {
+	if meta.msgCountTarget < 1 && meta.msgSpoolUsageTarget < 1 && meta.msgAgeTarget < 1 {
 		return nil, fmt.Errorf("no target value found in the scaler configuration")
 	}
 
@@ -236,6 +264,7 @@ func getSolaceSempCredentials(config *ScalerConfig) (u string, p string, err err
 //	CURRENT SUPPORTED METRICS ARE:
 //	- QUEUE MESSAGE COUNT (msgCount)
 //	- QUEUE SPOOL USAGE   (msgSpoolUsage in MBytes)
+//	- OLDEST MESSAGE AGE  (msgAge in Seconds)
 //	METRIC IDENTIFIER HAS THE SIGNATURE:
 //	- solace-[Queue_Name]-[metric_type]
 //	e.g. solace-QUEUE1-msgCount
@@ -273,21 +302,64 @@ func (s *SolaceScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Metric
 		metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: solaceExtMetricType}
 		metricSpecList = append(metricSpecList, metricSpec)
 	}
+	// Oldest Message Age Target Spec
+	if s.metadata.msgAgeTarget > 0 {
+		targetMetricValue := resource.NewQuantity(int64(s.metadata.msgAgeTarget), resource.DecimalSI)
+		metricName := kedautil.NormalizeString(fmt.Sprintf("solace-%s-%s", s.metadata.queueName, solaceTriggermsgage))
+		externalMetric := &v2beta2.ExternalMetricSource{
+			Metric: v2beta2.MetricIdentifier{
+				Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+			},
+			Target: v2beta2.MetricTarget{
+				Type:         v2beta2.AverageValueMetricType,
+				AverageValue: targetMetricValue,
+			},
+		}
+		metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: solaceExtMetricType}
+		metricSpecList = append(metricSpecList, metricSpec)
+	}
 	return metricSpecList
 }
 
 //	returns SolaceMetricValues struct populated from broker  SEMP endpoint
 func (s *SolaceScaler) getSolaceQueueMetricsFromSEMP(ctx context.Context) (SolaceMetricValues, error) {
-	var scaledMetricEndpointURL = s.metadata.endpointURL
-	var httpClient = s.httpClient
 	var sempResponse solaceSEMPResponse
 	var metricValues SolaceMetricValues
 
 	//	RETRIEVE METRICS FROM SOLACE SEMP API
+	if err := s.callSEMP(ctx, s.metadata.endpointURL, &sempResponse); err != nil {
+		return SolaceMetricValues{}, err
+	}
+	if sempResponse.Meta.ResponseCode < 200 || sempResponse.Meta.ResponseCode > 299 {
+		return SolaceMetricValues{}, fmt.Errorf("solace semp api returned error status: %d", sempResponse.Meta.ResponseCode)
+	}
+
+	// Set Return Values
+	metricValues.msgCount = sempResponse.Collections.Msgs.Count
+	metricValues.msgSpoolUsage = sempResponse.Data.MsgSpoolUsage
+
+	//	The age of the oldest message needs an additional request, only made when it is a target
+	if s.metadata.msgAgeTarget > 0 && metricValues.msgCount > 0 {
+		var msgsResponse solaceSEMPMsgsResponse
+		if err := s.callSEMP(ctx, s.metadata.endpointURL+solaceSempOldestMsgQuery, &msgsResponse); err != nil {
+			return SolaceMetricValues{}, err
+		}
+		if msgsResponse.Meta.ResponseCode < 200 || msgsResponse.Meta.ResponseCode > 299 {
+			return SolaceMetricValues{}, fmt.Errorf("solace semp api returned error status: %d", msgsResponse.Meta.ResponseCode)
+		}
+		if len(msgsResponse.Data) > 0 && msgsResponse.Data[0].SpooledTime > 0 {
+			metricValues.msgAge = int(time.Since(time.Unix(msgsResponse.Data[0].SpooledTime, 0)).Seconds())
+		}
+	}
+	return metricValues, nil
+}
+
+//	Calls the SEMP endpoint and decodes its JSON response
+func (s *SolaceScaler) callSEMP(ctx context.Context, endpointURL string, sempResponse interface{}) error {
 	//	Define HTTP Request
-	request, err := http.NewRequestWithContext(ctx, "GET", scaledMetricEndpointURL, nil)
+	request, err := http.NewRequestWithContext(ctx, "GET", endpointURL, nil)
 	if err != nil {
-		return SolaceMetricValues{}, fmt.Errorf("failed attempting request to solace semp api: %s", err)
+		return fmt.Errorf("failed attempting request to solace semp api: %s", err)
 	}
 
 	//	Add HTTP Auth and Headers
@@ -295,30 +367,22 @@ func (s *SolaceScaler) getSolaceQueueMetricsFromSEMP(ctx context.Context) (Solac
 	request.Header.Set("Content-Type", "application/json")
 
 	//	Call Solace SEMP API
-	response, err := httpClient.Do(request)
+	response, err := s.httpClient.Do(request)
 	if err != nil {
-		return SolaceMetricValues{}, fmt.Errorf("call to solace semp api failed: %s", err)
+		return fmt.Errorf("call to solace semp api failed: %s", err)
 	}
 	defer response.Body.Close()
 
 	// Check HTTP Status Code
 	if response.StatusCode < 200 || response.StatusCode > 299 {
-		sempError := fmt.Errorf("semp request http status code: %s - %s", strconv.Itoa(response.StatusCode), response.Status)
-		return SolaceMetricValues{}, sempError
+		return fmt.Errorf("semp request http status code: %s - %s", strconv.Itoa(response.StatusCode), response.Status)
 	}
 
-	// Decode SEMP Response and Test
-	if err := json.NewDecoder(response.Body).Decode(&sempResponse); err != nil {
-		return SolaceMetricValues{}, fmt.Errorf("failed to read semp response body: %s", err)
-	}
-	if sempResponse.Meta.ResponseCode < 200 || sempResponse.Meta.ResponseCode > 299 {
-		return SolaceMetricValues{}, fmt.Errorf("solace semp api returned error status: %d", sempResponse.Meta.ResponseCode)
+	// Decode SEMP Response
+	if err := json.NewDecoder(response.Body).Decode(sempResponse); err != nil {
+		return fmt.Errorf("failed to read semp response body: %s", err)
 	}
-
-	// Set Return Values
-	metricValues.msgCount = sempResponse.Collections.Msgs.Count
-	metricValues.msgSpoolUsage = sempResponse.Data.MsgSpoolUsage
-	return metricValues, nil
+	return nil
 }
 
 //	INTERFACE METHOD
@@ -347,6 +411,12 @@ func (s *SolaceScaler) GetMetrics(ctx context.Context, metricName string, metric
 			Value:      *resource.NewQuantity(int64(metricValues.msgSpoolUsage), resource.DecimalSI),
 			Timestamp:  metav1.Now(),
 		}
+	case strings.HasSuffix(metricName, solaceTriggermsgage):
+		metric = external_metrics.ExternalMetricValue{
+			MetricName: metricName,
+			Value:      *resource.NewQuantity(int64(metricValues.msgAge), resource.DecimalSI),
+			Timestamp:  metav1.Now(),
+		}
 	default:
 		// Should never end up here
 		err := fmt.Errorf("unidentified metric: %s", metricName)
@@ -370,5 +440,6 @@ func (s *SolaceScaler) IsActive(ctx context.Context) (bool, error) {
 
 // Do Nothing - Satisfies Interface
 func (s *SolaceScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
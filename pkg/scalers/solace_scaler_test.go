@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/api/autoscaling/v2beta2"
 )
@@ -365,11 +368,25 @@ var testSolaceGetMetricSpecData = []testSolaceMetadata{
 		1,
 		false,
 	},
+	{
+		"#407 - Get Metric Spec - AGE TARGET ONLY",
+		map[string]string{
+			solaceMetaSempBaseURL:  soltestValidBaseURL,
+			solaceMetaMsgVpn:       soltestValidVpn,
+			solaceMetaUsername:     soltestValidUsername,
+			solaceMetaPassword:     soltestValidPassword,
+			solaceMetaQueueName:    soltestValidQueueName,
+			solaceMetaMsgAgeTarget: "60",
+		},
+		1,
+		false,
+	},
 }
 
 var testSolaceExpectedMetricNames = map[string]string{
 	"s1-" + solaceScalerID + "-" + soltestValidQueueName + "-" + solaceTriggermsgcount:      "",
 	"s1-" + solaceScalerID + "-" + soltestValidQueueName + "-" + solaceTriggermsgspoolusage: "",
+	"s1-" + solaceScalerID + "-" + soltestValidQueueName + "-" + solaceTriggermsgage:        "",
 }
 
 func TestSolaceParseSolaceMetadata(t *testing.T) {
@@ -455,3 +472,37 @@ func TestSolaceGetMetricSpec(t *testing.T) {
 		}
 	}
 }
+
+func TestSolaceGetMetricsOldestMessageAge(t *testing.T) {
+	spooledTime := time.Now().Add(-2 * time.Minute).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/msgs") {
+			fmt.Fprintf(w, `{"data":[{"spooledTime":%d}],"meta":{"responseCode":200}}`, spooledTime)
+			return
+		}
+		fmt.Fprint(w, `{"collections":{"msgs":{"count":4}},"data":{"msgSpoolUsage":1024},"meta":{"responseCode":200}}`)
+	}))
+	defer server.Close()
+
+	solaceMeta, err := parseSolaceMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{
+			solaceMetaSempBaseURL:  server.URL,
+			solaceMetaMsgVpn:       soltestValidVpn,
+			solaceMetaQueueName:    soltestValidQueueName,
+			solaceMetaMsgAgeTarget: "60",
+		},
+		AuthParams: testDataSolaceAuthParamsVALID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSolaceScaler := SolaceScaler{metadata: solaceMeta, httpClient: http.DefaultClient}
+
+	metrics, err := testSolaceScaler.GetMetrics(context.Background(), "s0-solace-queue3-msgage", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if age := metrics[0].Value.Value(); age < 120 || age > 125 {
+		t.Errorf("expected an age of 120 seconds, got %d", age)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
@@ -44,8 +45,24 @@ func NewStackDriverClient(ctx context.Context, credentials string) (*StackDriver
 	}, nil
 }
 
-// NewStackDriverClient creates a new stackdriver client with the credentials underlying
+// NewStackDriverClient creates a new stackdriver client with the credentials underlying, outside of GKE the
+// credentials are obtained with workload identity federation when GCP_WORKLOAD_IDENTITY_PROVIDER is set
 func NewStackDriverClientPodIdentity(ctx context.Context) (*StackDriverClient, error) {
+	if os.Getenv(gcpWorkloadIdentityProviderEnv) != "" {
+		credentials, err := gcpWorkloadIdentityFederationCredentials()
+		if err != nil {
+			return nil, err
+		}
+		client, err := monitoring.NewMetricClient(ctx, option.WithCredentialsJSON(credentials))
+		if err != nil {
+			return nil, err
+		}
+		return &StackDriverClient{
+			metricsClient: client,
+			projectID:     os.Getenv(gcpProjectIDEnv),
+		}, nil
+	}
+
 	client, err := monitoring.NewMetricClient(ctx)
 	if err != nil {
 		return nil, err
@@ -61,6 +78,47 @@ func NewStackDriverClientPodIdentity(ctx context.Context) (*StackDriverClient, e
 	}, nil
 }
 
+const (
+	// the environment variables of KEDA configuring the workload identity federation of the gcp pod identity,
+	// the provider is the audience of the pool provider trusting the OIDC issuer of the cluster, eg.
+	// //iam.googleapis.com/projects/123456/locations/global/workloadIdentityPools/keda/providers/eks
+	gcpWorkloadIdentityProviderEnv  = "GCP_WORKLOAD_IDENTITY_PROVIDER"
+	gcpWorkloadIdentityTokenFileEnv = "GCP_WORKLOAD_IDENTITY_TOKEN_FILE"
+	gcpServiceAccountEnv            = "GCP_SERVICE_ACCOUNT"
+	gcpProjectIDEnv                 = "GCP_PROJECT_ID"
+
+	// defaultGcpWorkloadIdentityTokenFile is where the service account token projected with the audience of the
+	// pool provider is expected by default
+	defaultGcpWorkloadIdentityTokenFile = "/var/run/secrets/tokens/gcp-ksa/token"
+	gcpSTSTokenURL                      = "https://sts.googleapis.com/v1/token"
+	gcpImpersonationURL                 = "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%s:generateAccessToken"
+)
+
+// gcpWorkloadIdentityFederationCredentials returns the external account credentials exchanging the projected
+// service account token of KEDA for a Google access token, the token file is read again at each exchange so the
+// rotated tokens are used. The federated identity impersonates GCP_SERVICE_ACCOUNT if it is set.
+func gcpWorkloadIdentityFederationCredentials() ([]byte, error) {
+	if os.Getenv(gcpProjectIDEnv) == "" {
+		return nil, fmt.Errorf("%s must be set with %s, the project can't be read from the metadata server outside of GCP", gcpProjectIDEnv, gcpWorkloadIdentityProviderEnv)
+	}
+	tokenFile := os.Getenv(gcpWorkloadIdentityTokenFileEnv)
+	if tokenFile == "" {
+		tokenFile = defaultGcpWorkloadIdentityTokenFile
+	}
+
+	credentials := map[string]interface{}{
+		"type":               "external_account",
+		"audience":           os.Getenv(gcpWorkloadIdentityProviderEnv),
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          gcpSTSTokenURL,
+		"credential_source":  map[string]string{"file": tokenFile},
+	}
+	if serviceAccount := os.Getenv(gcpServiceAccountEnv); serviceAccount != "" {
+		credentials["service_account_impersonation_url"] = fmt.Sprintf(gcpImpersonationURL, serviceAccount)
+	}
+	return json.Marshal(credentials)
+}
+
 // GetMetrics fetches metrics from stackdriver for a specific filter for the last minute
 func (s StackDriverClient) GetMetrics(ctx context.Context, filter string) (int64, error) {
 	// Set the start time to 1 minute ago
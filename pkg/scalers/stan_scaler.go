@@ -70,7 +70,7 @@ func NewStanScaler(config *ScalerConfig) (Scaler, error) {
 	return &stanScaler{
 		channelInfo: &monitorChannelInfo{},
 		metadata:    stanMetadata,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  createHTTPClient(config, config.GlobalHTTPTimeout, false),
 	}, nil
 }
 
@@ -245,5 +245,6 @@ func (s *stanScaler) GetMetrics(ctx context.Context, metricName string, metricSe
 
 // Nothing to close here.
 func (s *stanScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
 	return nil
 }
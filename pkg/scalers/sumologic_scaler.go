@@ -0,0 +1,343 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	sumoLogicLogsQuery    = "logs"
+	sumoLogicMetricsQuery = "metrics"
+
+	// the states of a search job, see https://help.sumologic.com/APIs/Search-Job-API/About-the-Search-Job-API
+	sumoLogicJobDone      = "DONE GATHERING RESULTS"
+	sumoLogicJobCancelled = "CANCELLED"
+)
+
+// sumoLogicPollInterval is the interval the state of the search jobs is polled at
+var sumoLogicPollInterval = time.Second
+
+type sumoLogicScaler struct {
+	metadata   *sumoLogicMetadata
+	httpClient *http.Client
+}
+
+type sumoLogicMetadata struct {
+	// Host is the API endpoint of the deployment of the account, eg. https://api.us2.sumologic.com
+	Host      string `keda:"name=host"`
+	AccessID  string `keda:"name=accessID, order=authParams"`
+	AccessKey string `keda:"name=accessKey, order=authParams"`
+	QueryType string `keda:"name=queryType, default=logs, enum=logs;metrics"`
+	Query     string `keda:"name=query"`
+	// TimeRange is how far back from now the query runs
+	TimeRange time.Duration `keda:"name=timeRange, default=15m"`
+	// ResultField is the field of the first record of an aggregate logs query holding the value, the value is the
+	// number of messages found if it isn't set
+	ResultField string `keda:"name=resultField, optional"`
+	Timezone    string `keda:"name=timezone, default=UTC"`
+	// SearchTimeout bounds the wait for a search job to gather its results
+	SearchTimeout time.Duration `keda:"name=searchTimeout, default=1m"`
+	// Rollup and Quantization aggregate the data points of a metrics query
+	Rollup       string        `keda:"name=rollup, default=Avg, enum=Avg;Min;Max;Sum;Count"`
+	Quantization time.Duration `keda:"name=quantization, default=1m"`
+	TargetValue  float64       `keda:"name=targetValue"`
+	MetricName   string        `keda:"name=metricName, optional"`
+
+	scalerIndex int
+}
+
+// Validate checks the parameters of the query type are given
+func (m *sumoLogicMetadata) Validate() error {
+	if m.QueryType == sumoLogicMetricsQuery && m.ResultField != "" {
+		return fmt.Errorf("resultField can only be given with a logs query")
+	}
+	if m.TimeRange <= 0 {
+		return fmt.Errorf("timeRange must be greater than 0")
+	}
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	if _, err := time.LoadLocation(m.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %s", err)
+	}
+	return nil
+}
+
+var sumoLogicLog = logf.Log.WithName("sumologic_scaler")
+
+// NewSumoLogicScaler creates a new scaler running a Sumo Logic search job or metrics query
+func NewSumoLogicScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseSumoLogicMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sumologic metadata: %s", err)
+	}
+
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
+	// the requests of a search job must carry the cookies of its creation, they are routed to the node running it
+	if httpClient.Jar, err = cookiejar.New(nil); err != nil {
+		return nil, err
+	}
+
+	return &sumoLogicScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseSumoLogicMetadata(config *ScalerConfig) (*sumoLogicMetadata, error) {
+	meta := sumoLogicMetadata{}
+	if err := config.TypedConfig(&meta); err != nil {
+		return nil, err
+	}
+	meta.Host = strings.TrimSuffix(meta.Host, "/")
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive returns true if the result of the query is greater than 0
+func (s *sumoLogicScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		sumoLogicLog.Error(err, "error querying sumologic")
+		return false, err
+	}
+	return value > 0, nil
+}
+
+// Close does nothing in case of sumoLogicScaler
+func (s *sumoLogicScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+func (s *sumoLogicScaler) getQueryResult(ctx context.Context) (float64, error) {
+	to := time.Now()
+	from := to.Add(-s.metadata.TimeRange)
+	if s.metadata.QueryType == sumoLogicMetricsQuery {
+		return s.getMetricsQueryResult(ctx, from, to)
+	}
+	return s.getLogsQueryResult(ctx, from, to)
+}
+
+// do sends a request to the API and decodes its JSON response into result
+func (s *sumoLogicScaler) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		reqBody, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.metadata.Host+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.metadata.AccessID, s.metadata.AccessKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sumologic returned %d: %s", resp.StatusCode, getSumoLogicError(respBody))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// getSumoLogicError returns the message of a Sumo Logic error response
+func getSumoLogicError(response []byte) string {
+	var sumoLogicError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Errors  []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(response, &sumoLogicError); err == nil {
+		switch {
+		case sumoLogicError.Message != "":
+			return fmt.Sprintf("%s: %s", sumoLogicError.Code, sumoLogicError.Message)
+		case len(sumoLogicError.Errors) > 0:
+			return fmt.Sprintf("%s: %s", sumoLogicError.Errors[0].Code, sumoLogicError.Errors[0].Message)
+		}
+	}
+	return strings.TrimSpace(string(response))
+}
+
+// getLogsQueryResult runs a search job and returns the number of messages it found, or the resultField of its
+// first record for an aggregate query. The job is deleted once read.
+func (s *sumoLogicScaler) getLogsQueryResult(ctx context.Context, from, to time.Time) (float64, error) {
+	var job struct {
+		ID string `json:"id"`
+	}
+	err := s.do(ctx, http.MethodPost, "/api/v1/search/jobs", map[string]interface{}{
+		"query":    s.metadata.Query,
+		"from":     from.UnixNano() / int64(time.Millisecond),
+		"to":       to.UnixNano() / int64(time.Millisecond),
+		"timeZone": s.metadata.Timezone,
+	}, &job)
+	if err != nil {
+		return 0, fmt.Errorf("error creating the search job: %s", err)
+	}
+	defer func() {
+		if err := s.do(ctx, http.MethodDelete, "/api/v1/search/jobs/"+job.ID, nil, nil); err != nil {
+			sumoLogicLog.V(1).Info("Failed to delete the search job", "id", job.ID, "error", err.Error())
+		}
+	}()
+
+	searchCtx, cancel := context.WithTimeout(ctx, s.metadata.SearchTimeout)
+	defer cancel()
+	var status struct {
+		State        string `json:"state"`
+		MessageCount int64  `json:"messageCount"`
+		RecordCount  int64  `json:"recordCount"`
+	}
+	for {
+		if err := s.do(searchCtx, http.MethodGet, "/api/v1/search/jobs/"+job.ID, nil, &status); err != nil {
+			return 0, fmt.Errorf("error getting the state of the search job: %s", err)
+		}
+		if status.State == sumoLogicJobDone {
+			break
+		}
+		if status.State == sumoLogicJobCancelled {
+			return 0, fmt.Errorf("the search job was cancelled")
+		}
+		select {
+		case <-searchCtx.Done():
+			return 0, fmt.Errorf("the search job didn't gather its results: %s", searchCtx.Err())
+		case <-time.After(sumoLogicPollInterval):
+		}
+	}
+
+	if s.metadata.ResultField == "" {
+		return float64(status.MessageCount), nil
+	}
+	if status.RecordCount == 0 {
+		return 0, nil
+	}
+	var records struct {
+		Records []struct {
+			Map map[string]string `json:"map"`
+		} `json:"records"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/api/v1/search/jobs/"+job.ID+"/records?offset=0&limit=1", nil, &records); err != nil {
+		return 0, fmt.Errorf("error getting the records of the search job: %s", err)
+	}
+	if len(records.Records) == 0 {
+		return 0, nil
+	}
+	// the fields of the records are lowercase
+	val, ok := records.Records[0].Map[strings.ToLower(s.metadata.ResultField)]
+	if !ok {
+		return 0, fmt.Errorf("the records of the search job have no field %s", s.metadata.ResultField)
+	}
+	value, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing the field %s of the record: %s", s.metadata.ResultField, err)
+	}
+	return value, nil
+}
+
+// getMetricsQueryResult runs a metrics query and returns the sum of the latest data points of its time series
+func (s *sumoLogicScaler) getMetricsQueryResult(ctx context.Context, from, to time.Time) (float64, error) {
+	boundary := func(t time.Time) map[string]interface{} {
+		return map[string]interface{}{"type": "EpochTimeRangeBoundary", "epochMillis": t.UnixNano() / int64(time.Millisecond)}
+	}
+	var result struct {
+		QueryResult []struct {
+			TimeSeriesList struct {
+				TimeSeries []struct {
+					Points struct {
+						Values []float64 `json:"values"`
+					} `json:"points"`
+				} `json:"timeSeries"`
+			} `json:"timeSeriesList"`
+		} `json:"queryResult"`
+		Errors *struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"errors"`
+	}
+	err := s.do(ctx, http.MethodPost, "/api/v1/metricsQueries", map[string]interface{}{
+		"queries": []map[string]interface{}{{
+			"rowId":        "A",
+			"query":        s.metadata.Query,
+			"quantization": s.metadata.Quantization.Milliseconds(),
+			"rollup":       s.metadata.Rollup,
+		}},
+		"timeRange": map[string]interface{}{"type": "BeginBoundedTimeRange", "from": boundary(from), "to": boundary(to)},
+	}, &result)
+	if err != nil {
+		return 0, fmt.Errorf("error running the metrics query: %s", err)
+	}
+	if result.Errors != nil && len(result.Errors.Errors) > 0 {
+		return 0, fmt.Errorf("error running the metrics query: %s", result.Errors.Errors[0].Message)
+	}
+
+	var value float64
+	for _, queryResult := range result.QueryResult {
+		for _, series := range queryResult.TimeSeriesList.TimeSeries {
+			if points := series.Points.Values; len(points) > 0 {
+				value += points[len(points)-1]
+			}
+		}
+	}
+	return value, nil
+}
+
+func (s *sumoLogicScaler) getMetricName() string {
+	name := fmt.Sprintf("sumologic-%s", s.metadata.QueryType)
+	if s.metadata.MetricName != "" {
+		name = fmt.Sprintf("sumologic-%s", s.metadata.MetricName)
+	}
+	return GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name))
+}
+
+func (s *sumoLogicScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: s.getMetricName(),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the result of the query
+func (s *sumoLogicScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error querying sumologic: %s", err)
+	}
+	return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, value)), nil
+}
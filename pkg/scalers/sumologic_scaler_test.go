@@ -0,0 +1,151 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseSumoLogicMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+var testSumoLogicAuthParams = map[string]string{"accessID": "id", "accessKey": "key"}
+
+var testSumoLogicMetadata = []parseSumoLogicMetadataTestData{
+	// logs query
+	{map[string]string{"host": "https://api.sumologic.com", "query": "_sourceCategory=orders error", "targetValue": "10"}, testSumoLogicAuthParams, false},
+	// aggregate logs query
+	{map[string]string{"host": "https://api.sumologic.com", "query": "_sourceCategory=orders | count", "resultField": "_count", "timeRange": "5m", "timezone": "Europe/Paris", "targetValue": "10"}, testSumoLogicAuthParams, false},
+	// metrics query
+	{map[string]string{"host": "https://api.sumologic.com", "queryType": "metrics", "query": "metric=queue_depth | sum", "rollup": "Max", "targetValue": "10"}, testSumoLogicAuthParams, false},
+	// missing host
+	{map[string]string{"query": "_sourceCategory=orders", "targetValue": "10"}, testSumoLogicAuthParams, true},
+	// missing access key
+	{map[string]string{"host": "https://api.sumologic.com", "query": "_sourceCategory=orders", "targetValue": "10"}, map[string]string{"accessID": "id"}, true},
+	// invalid query type
+	{map[string]string{"host": "https://api.sumologic.com", "queryType": "traces", "query": "_sourceCategory=orders", "targetValue": "10"}, testSumoLogicAuthParams, true},
+	// resultField of a metrics query
+	{map[string]string{"host": "https://api.sumologic.com", "queryType": "metrics", "query": "metric=queue_depth", "resultField": "_count", "targetValue": "10"}, testSumoLogicAuthParams, true},
+	// invalid timezone
+	{map[string]string{"host": "https://api.sumologic.com", "query": "_sourceCategory=orders", "timezone": "Mars/Olympus", "targetValue": "10"}, testSumoLogicAuthParams, true},
+	// missing targetValue
+	{map[string]string{"host": "https://api.sumologic.com", "query": "_sourceCategory=orders"}, testSumoLogicAuthParams, true},
+}
+
+func TestParseSumoLogicMetadata(t *testing.T) {
+	for i, testData := range testSumoLogicMetadata {
+		_, err := parseSumoLogicMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestSumoLogicGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range []struct {
+		metadata map[string]string
+		name     string
+	}{
+		{map[string]string{"host": "https://api.sumologic.com", "query": "error", "targetValue": "10"}, "s1-sumologic-logs"},
+		{map[string]string{"host": "https://api.sumologic.com", "query": "error", "metricName": "orders.errors", "targetValue": "10"}, "s1-sumologic-orders-errors"},
+	} {
+		scaler, err := NewSumoLogicScaler(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testSumoLogicAuthParams, ScalerIndex: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, testData.name, scaler.GetMetricSpecForScaling(context.Background())[0].External.Metric.Name)
+	}
+}
+
+// newSumoLogicTestServer serves a search job gathering its results at the second poll, and a metrics query
+func newSumoLogicTestServer(t *testing.T) (*httptest.Server, *bool) {
+	polls := 0
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "id" || pass != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"status": 401, "code": "unauthorized", "message": "Credential could not be verified."}`)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/search/jobs":
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "UTC", body["timeZone"])
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "node-1"})
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"id": "job-1"}`)
+		case r.URL.Path == "/api/v1/search/jobs/job-1":
+			if cookie, err := r.Cookie("JSESSIONID"); err != nil || cookie.Value != "node-1" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				deleted = true
+				return
+			}
+			polls++
+			if polls < 2 {
+				fmt.Fprint(w, `{"state": "GATHERING RESULTS", "messageCount": 10, "recordCount": 0}`)
+				return
+			}
+			fmt.Fprint(w, `{"state": "DONE GATHERING RESULTS", "messageCount": 42, "recordCount": 1}`)
+		case r.URL.Path == "/api/v1/search/jobs/job-1/records":
+			fmt.Fprint(w, `{"fields": [{"name": "_count"}], "records": [{"map": {"_count": "7"}}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/metricsQueries":
+			fmt.Fprint(w, `{"queryResult": [{"rowId": "A", "timeSeriesList": {"timeSeries": [
+				{"points": {"timestamps": [1, 2], "values": [3, 4.5]}},
+				{"points": {"timestamps": [1, 2], "values": [1, 2]}}
+			]}}], "errors": null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, &deleted
+}
+
+func TestSumoLogicGetQueryResult(t *testing.T) {
+	sumoLogicPollInterval = time.Millisecond
+	defer func() { sumoLogicPollInterval = time.Second }()
+
+	for _, testData := range []struct {
+		metadata   map[string]string
+		authParams map[string]string
+		value      float64
+		isError    bool
+	}{
+		{map[string]string{"query": "error", "targetValue": "10"}, testSumoLogicAuthParams, 42, false},
+		{map[string]string{"query": "error | count", "resultField": "_count", "targetValue": "10"}, testSumoLogicAuthParams, 7, false},
+		{map[string]string{"query": "error | count", "resultField": "missing", "targetValue": "10"}, testSumoLogicAuthParams, 0, true},
+		{map[string]string{"queryType": "metrics", "query": "metric=queue_depth", "targetValue": "10"}, testSumoLogicAuthParams, 6.5, false},
+		{map[string]string{"query": "error", "targetValue": "10"}, map[string]string{"accessID": "id", "accessKey": "guess"}, 0, true},
+	} {
+		server, deleted := newSumoLogicTestServer(t)
+		testData.metadata["host"] = server.URL
+		scaler, err := NewSumoLogicScaler(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		assert.NoError(t, err)
+
+		value, err := scaler.(*sumoLogicScaler).getQueryResult(context.Background())
+		if testData.isError {
+			assert.Error(t, err, testData.metadata)
+		} else {
+			assert.NoError(t, err, testData.metadata)
+			assert.Equal(t, testData.value, value, testData.metadata)
+		}
+		if testData.metadata["queryType"] == "" && !testData.isError {
+			assert.True(t, *deleted, "the search job must be deleted")
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,172 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	tektonGroup = "tekton.dev"
+
+	tektonPipelineRunKind = "PipelineRun"
+	tektonTaskRunKind     = "TaskRun"
+)
+
+// tektonQueuedReasons are the reasons of the Succeeded condition of the runs waiting to be executed: pending in their
+// spec, waiting for the resources of their pods or not picked up by the Tekton controller yet
+var tektonQueuedReasons = map[string]bool{
+	"":                      true,
+	"Pending":               true,
+	"PipelineRunPending":    true,
+	"TaskRunPending":        true,
+	"Started":               true,
+	"ExceededResourceQuota": true,
+	"ExceededNodeResources": true,
+}
+
+type tektonScaler struct {
+	metadata   *tektonMetadata
+	kubeClient client.Client
+}
+
+type tektonMetadata struct {
+	Kind          string `keda:"name=kind, default=PipelineRun, enum=PipelineRun;TaskRun"`
+	APIVersion    string `keda:"name=apiVersion, default=v1beta1"`
+	LabelSelector string `keda:"name=labelSelector, optional"`
+	// IncludeRunning counts the running runs along the queued ones, eg. for the runners executing the runs
+	IncludeRunning bool    `keda:"name=includeRunning, default=false"`
+	TargetValue    float64 `keda:"name=targetValue, default=1"`
+
+	selector    labels.Selector
+	namespace   string
+	scalerIndex int
+}
+
+// Validate checks the target value is positive
+func (m *tektonMetadata) Validate() error {
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	return nil
+}
+
+// NewTektonScaler creates a new scaler counting the queued PipelineRuns or TaskRuns of the namespace, so the
+// infrastructure of the Tekton workers scales with the backlog of the CI
+func NewTektonScaler(kubeClient client.Client, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseTektonMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tekton metadata: %s", err)
+	}
+
+	return &tektonScaler{
+		metadata:   meta,
+		kubeClient: kubeClient,
+	}, nil
+}
+
+func parseTektonMetadata(config *ScalerConfig) (*tektonMetadata, error) {
+	meta := &tektonMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+
+	selector, err := labels.Parse(meta.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labelSelector: %s", err)
+	}
+	meta.selector = selector
+	meta.namespace = config.Namespace
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// IsActive determines if there are queued runs
+func (s *tektonScaler) IsActive(ctx context.Context) (bool, error) {
+	runs, err := s.getQueuedRuns(ctx)
+	if err != nil {
+		return false, err
+	}
+	return runs > 0, nil
+}
+
+// Close no need for tekton scaler
+func (s *tektonScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *tektonScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("tekton-%s-%s", s.metadata.Kind, s.metadata.namespace))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the number of queued runs
+func (s *tektonScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	runs, err := s.getQueuedRuns(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error listing tekton %ss: %s", s.metadata.Kind, err)
+	}
+	return append([]external_metrics.ExternalMetricValue{}, GenerateMetricInMili(metricName, float64(runs))), nil
+}
+
+// getQueuedRuns lists the runs matching the label selector, the Tekton types are read as unstructured objects so
+// KEDA doesn't depend on the Tekton API
+func (s *tektonScaler) getQueuedRuns(ctx context.Context) (int, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: tektonGroup, Version: s.metadata.APIVersion, Kind: s.metadata.Kind + "List"})
+	err := s.kubeClient.List(ctx, list, client.InNamespace(s.metadata.namespace), client.MatchingLabelsSelector{Selector: s.metadata.selector})
+	if err != nil {
+		return 0, err
+	}
+
+	runs := 0
+	for i := range list.Items {
+		if isTektonRunQueued(&list.Items[i], s.metadata.IncludeRunning) {
+			runs++
+		}
+	}
+	return runs, nil
+}
+
+// isTektonRunQueued returns true if the run isn't done and waits for its execution, or is running if includeRunning
+// is set
+func isTektonRunQueued(run *unstructured.Unstructured, includeRunning bool) bool {
+	if completionTime, _, _ := unstructured.NestedString(run.Object, "status", "completionTime"); completionTime != "" {
+		return false
+	}
+	// the pending runs aren't picked up by the Tekton controller until their spec.status is cleared
+	if status, _, _ := unstructured.NestedString(run.Object, "spec", "status"); status == tektonPipelineRunKind+"Pending" || status == tektonTaskRunKind+"Pending" {
+		return true
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(run.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Succeeded" {
+			continue
+		}
+		if condition["status"] != "Unknown" {
+			return false
+		}
+		reason, _ := condition["reason"].(string)
+		return includeRunning || tektonQueuedReasons[reason]
+	}
+	// the run isn't reconciled by the Tekton controller yet
+	return true
+}
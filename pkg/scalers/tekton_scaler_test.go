@@ -0,0 +1,103 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type parseTektonMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+var testTektonMetadata = []parseTektonMetadataTestData{
+	{map[string]string{}, false},
+	{map[string]string{"kind": "TaskRun", "labelSelector": "tekton.dev/pipeline=build", "includeRunning": "true", "targetValue": "5"}, false},
+	{map[string]string{"kind": "Pipeline"}, true},
+	{map[string]string{"labelSelector": "tekton.dev/pipeline in build"}, true},
+	{map[string]string{"includeRunning": "sometimes"}, true},
+	{map[string]string{"targetValue": "0"}, true},
+}
+
+func TestTektonParseMetadata(t *testing.T) {
+	for i, testData := range testTektonMetadata {
+		_, err := parseTektonMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "ci"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func newTektonRun(kind, name string, labels map[string]string, spec, status map[string]interface{}) *unstructured.Unstructured {
+	run := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	run.SetGroupVersionKind(schema.GroupVersionKind{Group: tektonGroup, Version: "v1beta1", Kind: kind})
+	run.SetNamespace("ci")
+	run.SetName(name)
+	run.SetLabels(labels)
+	if spec != nil {
+		run.Object["spec"] = spec
+	}
+	if status != nil {
+		run.Object["status"] = status
+	}
+	return run
+}
+
+func tektonSucceeded(status, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Succeeded", "status": status, "reason": reason}},
+	}
+}
+
+func TestTektonGetQueuedRuns(t *testing.T) {
+	build := map[string]string{"tekton.dev/pipeline": "build"}
+	kubeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(
+		newTektonRun(tektonPipelineRunKind, "not-reconciled", build, nil, nil),
+		newTektonRun(tektonPipelineRunKind, "pending", build, map[string]interface{}{"status": "PipelineRunPending"}, nil),
+		newTektonRun(tektonPipelineRunKind, "started", build, nil, tektonSucceeded("Unknown", "Started")),
+		newTektonRun(tektonPipelineRunKind, "running", build, nil, tektonSucceeded("Unknown", "Running")),
+		newTektonRun(tektonPipelineRunKind, "succeeded", build, nil, tektonSucceeded("True", "Succeeded")),
+		newTektonRun(tektonPipelineRunKind, "failed", build, nil, tektonSucceeded("False", "Failed")),
+		newTektonRun(tektonPipelineRunKind, "other-pipeline", map[string]string{"tekton.dev/pipeline": "deploy"}, nil, nil),
+		newTektonRun(tektonTaskRunKind, "task", build, nil, tektonSucceeded("Unknown", "ExceededNodeResources")),
+	).Build()
+
+	tests := []struct {
+		metadata map[string]string
+		runs     int
+	}{
+		{map[string]string{}, 4},
+		{map[string]string{"labelSelector": "tekton.dev/pipeline=build"}, 3},
+		{map[string]string{"labelSelector": "tekton.dev/pipeline=build", "includeRunning": "true"}, 4},
+		{map[string]string{"kind": "TaskRun"}, 1},
+	}
+	for _, test := range tests {
+		scaler, err := NewTektonScaler(kubeClient, &ScalerConfig{TriggerMetadata: test.metadata, Namespace: "ci"})
+		assert.NoError(t, err)
+		runs, err := scaler.(*tektonScaler).getQueuedRuns(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, test.runs, runs, "metadata %v", test.metadata)
+
+		active, err := scaler.IsActive(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, active)
+	}
+}
+
+func TestTektonGetMetricSpecForScaling(t *testing.T) {
+	scaler, err := NewTektonScaler(nil, &ScalerConfig{TriggerMetadata: map[string]string{"kind": "TaskRun", "targetValue": "2"}, Namespace: "ci", ScalerIndex: 1})
+	assert.NoError(t, err)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, "s1-tekton-TaskRun-ci", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(2), metricSpec[0].External.Target.AverageValue.Value())
+}
@@ -0,0 +1,322 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	trinoDefaultUser = "keda"
+	// trinoMaxPages bounds the pages followed for a query, the single value is usually on one of the first
+	trinoMaxPages = 100
+)
+
+type trinoScaler struct {
+	metadata   *trinoMetadata
+	httpClient *http.Client
+}
+
+type trinoMetadata struct {
+	serverURL   string
+	query       string
+	user        string
+	catalog     string
+	schema      string
+	targetValue float64
+	metricName  string
+	unsafeSsl   bool
+	// missingValue is how the queries without rows or with a null result are handled
+	missingValue *missingValuePolicy
+	// headerPrefix is X-Trino or X-Presto for the Presto servers
+	headerPrefix string
+
+	// basic auth
+	enableBasicAuth bool
+	username        string
+	password        string
+
+	// bearer (JWT)
+	enableBearerAuth bool
+	bearerToken      string
+
+	scalerIndex int
+}
+
+// trinoQueryResults is a page of the results of the statement protocol
+type trinoQueryResults struct {
+	ID      string          `json:"id"`
+	NextURI string          `json:"nextUri"`
+	Data    [][]interface{} `json:"data"`
+	Error   *struct {
+		Message   string `json:"message"`
+		ErrorName string `json:"errorName"`
+	} `json:"error"`
+}
+
+var trinoLog = logf.Log.WithName("trino_scaler")
+
+// NewTrinoScaler creates a new scaler running a query on Trino or Presto
+func NewTrinoScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseTrinoMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trino metadata: %s", err)
+	}
+
+	return &trinoScaler{
+		metadata:   meta,
+		httpClient: createHTTPClient(config, config.GlobalHTTPTimeout, meta.unsafeSsl),
+	}, nil
+}
+
+func parseTrinoMetadata(config *ScalerConfig) (*trinoMetadata, error) {
+	meta := trinoMetadata{}
+
+	switch {
+	case config.TriggerMetadata["serverURL"] != "":
+		meta.serverURL = config.TriggerMetadata["serverURL"]
+	case config.AuthParams["serverURL"] != "":
+		meta.serverURL = config.AuthParams["serverURL"]
+	default:
+		return nil, fmt.Errorf("no serverURL given")
+	}
+	meta.serverURL = strings.TrimSuffix(meta.serverURL, "/")
+
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	meta.catalog = config.TriggerMetadata["catalog"]
+	meta.schema = config.TriggerMetadata["schema"]
+	if meta.schema != "" && meta.catalog == "" {
+		return nil, fmt.Errorf("the catalog must be given with the schema")
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("trino-%s", val))
+	} else {
+		parts := []string{"trino"}
+		for _, part := range []string{meta.catalog, meta.schema} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		meta.metricName = kedautil.NormalizeString(strings.Join(parts, "-"))
+	}
+
+	switch engine := config.TriggerMetadata["engine"]; engine {
+	case "", "trino":
+		meta.headerPrefix = "X-Trino"
+	case "presto":
+		meta.headerPrefix = "X-Presto"
+	default:
+		return nil, fmt.Errorf("engine must be trino or presto, got %s", engine)
+	}
+
+	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok && val != "" {
+		unsafeSsl, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing unsafeSsl: %s", err)
+		}
+		meta.unsafeSsl = unsafeSsl
+	}
+
+	missingValue, err := parseMissingValuePolicy(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.missingValue = missingValue
+
+	meta.scalerIndex = config.ScalerIndex
+
+	if authMode, ok := config.TriggerMetadata["authMode"]; ok {
+		switch authentication.Type(strings.TrimSpace(authMode)) {
+		case authentication.BasicAuthType:
+			if len(config.AuthParams["username"]) == 0 {
+				return nil, errors.New("no username given")
+			}
+			meta.username = config.AuthParams["username"]
+			meta.password = config.AuthParams["password"]
+			meta.enableBasicAuth = true
+		case authentication.BearerAuthType:
+			if len(config.AuthParams["token"]) == 0 {
+				return nil, errors.New("no token provided")
+			}
+			meta.bearerToken = config.AuthParams["token"]
+			meta.enableBearerAuth = true
+		default:
+			return nil, fmt.Errorf("err incorrect value for authMode is given: %s", authMode)
+		}
+	}
+
+	// the session user defaults to the authenticated one
+	switch {
+	case config.TriggerMetadata["user"] != "":
+		meta.user = config.TriggerMetadata["user"]
+	case meta.username != "":
+		meta.user = meta.username
+	default:
+		meta.user = trinoDefaultUser
+	}
+
+	return &meta, nil
+}
+
+// IsActive returns true if the query result is greater than 0
+func (s *trinoScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		trinoLog.Error(err, "error querying trino")
+		return false, err
+	}
+
+	return value > 0, nil
+}
+
+// Close does nothing in case of trinoScaler
+func (s *trinoScaler) Close(context.Context) error {
+	closeIdleConnections(s.httpClient)
+	return nil
+}
+
+// getQueryResult submits the query to /v1/statement and follows the nextUri of the results
+// until the first row, the query is cancelled once its value is read
+func (s *trinoScaler) getQueryResult(ctx context.Context) (float64, error) {
+	results, err := s.doRequest(ctx, http.MethodPost, s.metadata.serverURL+"/v1/statement", s.metadata.query)
+	if err != nil {
+		return 0, err
+	}
+
+	for page := 0; len(results.Data) == 0 && results.NextURI != ""; page++ {
+		if page == trinoMaxPages {
+			return 0, fmt.Errorf("no results found after %d pages of query %s", trinoMaxPages, results.ID)
+		}
+		if results, err = s.doRequest(ctx, http.MethodGet, results.NextURI, ""); err != nil {
+			return 0, err
+		}
+	}
+	if results.NextURI != "" {
+		// the remaining rows are not needed
+		if _, err := s.doRequest(ctx, http.MethodDelete, results.NextURI, ""); err != nil {
+			trinoLog.V(1).Info("error cancelling trino query", "query", results.ID, "error", err.Error())
+		}
+	}
+
+	if len(results.Data) == 0 || len(results.Data[0]) == 0 {
+		return s.metadata.missingValue.resolve(0, false, errNoQueryResult)
+	}
+	switch value := results.Data[0][0].(type) {
+	case float64:
+		return s.metadata.missingValue.resolve(value, true, nil)
+	case string:
+		// decimal and bigint columns can be returned as strings
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %s could not be converted into a float", value)
+		}
+		return s.metadata.missingValue.resolve(parsed, true, nil)
+	case nil:
+		// null is read as 0 by default
+		return s.metadata.missingValue.resolve(0, false, nil)
+	default:
+		return 0, fmt.Errorf("value of type %T could not be converted into a float", value)
+	}
+}
+
+func (s *trinoScaler) doRequest(ctx context.Context, method, url, body string) (*trinoQueryResults, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(s.metadata.headerPrefix+"-User", s.metadata.user)
+	req.Header.Set(s.metadata.headerPrefix+"-Source", "keda")
+	if s.metadata.catalog != "" {
+		req.Header.Set(s.metadata.headerPrefix+"-Catalog", s.metadata.catalog)
+	}
+	if s.metadata.schema != "" {
+		req.Header.Set(s.metadata.headerPrefix+"-Schema", s.metadata.schema)
+	}
+	switch {
+	case s.metadata.enableBasicAuth:
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	case s.metadata.enableBearerAuth:
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.metadata.bearerToken))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodDelete {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("trino returned %d: %s", resp.StatusCode, strings.TrimSpace(string(content)))
+	}
+
+	results := &trinoQueryResults{}
+	if err := json.Unmarshal(content, results); err != nil {
+		return nil, fmt.Errorf("error parsing the query results: %s", err)
+	}
+	if results.Error != nil {
+		return nil, fmt.Errorf("query failed with %s: %s", results.Error.ErrorName, results.Error.Message)
+	}
+	return results, nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *trinoScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.targetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the result of the query
+func (s *trinoScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error querying trino: %s", err)
+	}
+
+	metric := GenerateMetricInMili(metricName, value)
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
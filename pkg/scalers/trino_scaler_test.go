@@ -0,0 +1,156 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseTrinoMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type trinoMetricIdentifier struct {
+	metadataTestData *parseTrinoMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testTrinoMetadata = []parseTrinoMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// everything passed
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "catalog": "hive", "schema": "queues", "user": "scaler", "targetValue": "10", "metricName": "jobs"}, map[string]string{}, false},
+	// no catalog nor schema
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM hive.queues.jobs", "targetValue": "10"}, map[string]string{}, false},
+	// serverURL in authParams
+	{map[string]string{"query": "SELECT count(*) FROM jobs", "catalog": "hive", "schema": "queues", "targetValue": "10"}, map[string]string{"serverURL": "https://trino:8443"}, false},
+	// no serverURL
+	{map[string]string{"query": "SELECT count(*) FROM jobs", "targetValue": "10"}, map[string]string{}, true},
+	// no query
+	{map[string]string{"serverURL": "https://trino:8443", "targetValue": "10"}, map[string]string{}, true},
+	// schema without catalog
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "schema": "queues", "targetValue": "10"}, map[string]string{}, true},
+	// no targetValue
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs"}, map[string]string{}, true},
+	// invalid targetValue
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "ten"}, map[string]string{}, true},
+	// presto
+	{map[string]string{"serverURL": "https://presto:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "engine": "presto"}, map[string]string{}, false},
+	// unknown engine
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "engine": "spark"}, map[string]string{}, true},
+	// invalid unsafeSsl
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "unsafeSsl": "maybe"}, map[string]string{}, true},
+	// basic auth
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "authMode": "basic"}, map[string]string{"username": "user", "password": "secret"}, false},
+	// basic auth without username
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "authMode": "basic"}, map[string]string{"password": "secret"}, true},
+	// jwt
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "authMode": "bearer"}, map[string]string{"token": "jwt"}, false},
+	// jwt without token
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "authMode": "bearer"}, map[string]string{}, true},
+	// unknown authMode
+	{map[string]string{"serverURL": "https://trino:8443", "query": "SELECT count(*) FROM jobs", "targetValue": "10", "authMode": "tls"}, map[string]string{}, true},
+}
+
+var trinoMetricIdentifiers = []trinoMetricIdentifier{
+	{&testTrinoMetadata[1], 0, "s0-trino-jobs"},
+	{&testTrinoMetadata[2], 1, "s1-trino"},
+	{&testTrinoMetadata[3], 2, "s2-trino-hive-queues"},
+}
+
+func TestTrinoParseMetadata(t *testing.T) {
+	for i, testData := range testTrinoMetadata {
+		_, err := parseTrinoMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestTrinoGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range trinoMetricIdentifiers {
+		meta, err := parseTrinoMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockTrinoScaler := trinoScaler{metadata: meta}
+
+		metricSpec := mockTrinoScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestTrinoGetMetrics(t *testing.T) {
+	var cancelled bool
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jwt", r.Header.Get("Authorization"))
+		assert.Equal(t, "keda", r.Header.Get("X-Trino-User"))
+		assert.Equal(t, "hive", r.Header.Get("X-Trino-Catalog"))
+		assert.Equal(t, "queues", r.Header.Get("X-Trino-Schema"))
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/statement":
+			query, _ := ioutil.ReadAll(r.Body)
+			switch string(query) {
+			case "SELECT count(*) FROM jobs":
+				fmt.Fprintf(w, `{"id": "q1", "nextUri": "%s/v1/statement/queued/q1/1", "stats": {"state": "QUEUED"}}`, server.URL)
+			case "SELECT sum(lag) FROM jobs":
+				fmt.Fprint(w, `{"id": "q2", "data": [[null]]}`)
+			default:
+				fmt.Fprint(w, `{"id": "q3", "error": {"errorName": "TABLE_NOT_FOUND", "message": "Table 'hive.queues.missing' does not exist"}}`)
+			}
+		case "GET /v1/statement/queued/q1/1":
+			fmt.Fprintf(w, `{"id": "q1", "nextUri": "%s/v1/statement/executing/q1/2", "columns": [{"name": "_col0"}]}`, server.URL)
+		case "GET /v1/statement/executing/q1/2":
+			fmt.Fprintf(w, `{"id": "q1", "nextUri": "%s/v1/statement/executing/q1/3", "data": [["42"]]}`, server.URL)
+		case "DELETE /v1/statement/executing/q1/3":
+			cancelled = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	getMetric := func(query string) (int64, error) {
+		scaler, err := NewTrinoScaler(&ScalerConfig{
+			TriggerMetadata: map[string]string{"serverURL": server.URL, "query": query, "catalog": "hive", "schema": "queues", "targetValue": "10", "authMode": "bearer"},
+			AuthParams:      map[string]string{"token": "jwt"},
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		metrics, err := scaler.GetMetrics(context.Background(), "s0-trino-hive-queues", nil)
+		if err != nil {
+			return 0, err
+		}
+		return metrics[0].Value.Value(), nil
+	}
+
+	value, err := getMetric("SELECT count(*) FROM jobs")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), value)
+	assert.True(t, cancelled)
+
+	value, err = getMetric("SELECT sum(lag) FROM jobs")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), value)
+
+	_, err = getMetric("SELECT count(*) FROM missing")
+	assert.EqualError(t, err, "error querying trino: query failed with TABLE_NOT_FOUND: Table 'hive.queues.missing' does not exist")
+}
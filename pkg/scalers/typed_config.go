@@ -0,0 +1,327 @@
+package scalers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// typedConfigTag is the struct tag of the fields parsed by TypedConfig, eg.
+//
+//	TargetValue float64 `keda:"name=targetValue, order=triggerMetadata, optional, default=5"`
+//
+// the options are separated by `,`:
+//   - name: the name of the parameter, by default the name of the field with a lowercase first letter
+//   - order: the sources of the parameter separated by `;`, read in turn until one has the parameter,
+//     triggerMetadata by default, resolvedEnv reads the environment variable named in the `<name>FromEnv` metadata
+//   - optional: a missing parameter isn't an error, the field keeps its value
+//   - default: the value of a missing parameter, implies optional
+//   - enum: the allowed values separated by `;`
+//   - unit: the unit of a numeric field, the value can be given in another unit of its kind, eg. `5m` for seconds,
+//     see parseUnitValue. The float fields are counts by default, eg. `2.5k`
+//   - deprecated: the parameter is rejected with this message, eg. the name of the parameter replacing it
+const typedConfigTag = "keda"
+
+const (
+	typedConfigTriggerMetadata = "triggerMetadata"
+	typedConfigAuthParams      = "authParams"
+	typedConfigResolvedEnv     = "resolvedEnv"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	quantityType = reflect.TypeOf(resource.Quantity{})
+)
+
+// typedConfigValidator is implemented by the metadata checking the parameters together once they are parsed
+type typedConfigValidator interface {
+	Validate() error
+}
+
+// typedConfigParam is a parameter described by the tag of a field
+type typedConfigParam struct {
+	name         string
+	order        []string
+	optional     bool
+	defaultValue *string
+	enum         []string
+	unit         string
+	deprecated   string
+}
+
+// TypedConfig parses the parameters of the scaler into typedConfig, a pointer to a struct whose fields are described by
+// a `keda` tag, the fields without tag are skipped.
+// The errors of all the parameters are returned together, then the Validate method of typedConfig is called if any
+func (c *ScalerConfig) TypedConfig(typedConfig interface{}) error {
+	value := reflect.ValueOf(typedConfig)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("typedConfig must be a pointer to a struct, got %T", typedConfig)
+	}
+
+	if err := c.parseTypedConfig(value.Elem()); err != nil {
+		return err
+	}
+	if validator, ok := typedConfig.(typedConfigValidator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+func (c *ScalerConfig) parseTypedConfig(value reflect.Value) error {
+	var errs []error
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup(typedConfigTag)
+		if !ok {
+			continue
+		}
+		if !value.Field(i).CanSet() {
+			errs = append(errs, fmt.Errorf("field %s of param %q must be exported", field.Name, tag))
+			continue
+		}
+
+		param, err := parseTypedConfigTag(field, tag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := c.setTypedConfigParam(value.Field(i), param); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func parseTypedConfigTag(field reflect.StructField, tag string) (typedConfigParam, error) {
+	param := typedConfigParam{
+		name:  strings.ToLower(field.Name[:1]) + field.Name[1:],
+		order: []string{typedConfigTriggerMetadata},
+	}
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		key, val := option, ""
+		if i := strings.Index(option, "="); i >= 0 {
+			key, val = strings.TrimSpace(option[:i]), strings.TrimSpace(option[i+1:])
+		}
+
+		switch key {
+		case "name":
+			param.name = val
+		case "order":
+			param.order = strings.Split(val, ";")
+			for _, source := range param.order {
+				if source != typedConfigTriggerMetadata && source != typedConfigAuthParams && source != typedConfigResolvedEnv {
+					return param, fmt.Errorf("unknown source %q of param %q", source, param.name)
+				}
+			}
+		case "optional":
+			param.optional = true
+		case "default":
+			param.defaultValue = &val
+			param.optional = true
+		case "enum":
+			param.enum = strings.Split(val, ";")
+		case "unit":
+			if !isUnit(val) {
+				return param, fmt.Errorf("unknown unit %q of param %q", val, param.name)
+			}
+			param.unit = val
+		case "deprecated":
+			param.deprecated = val
+		default:
+			return param, fmt.Errorf("unknown option %q of param %q", key, param.name)
+		}
+	}
+	return param, nil
+}
+
+// lookup returns the value of the parameter from the first of its sources having it
+func (c *ScalerConfig) lookup(param typedConfigParam) (string, bool) {
+	for _, source := range param.order {
+		var val string
+		switch source {
+		case typedConfigTriggerMetadata:
+			val = c.TriggerMetadata[param.name]
+		case typedConfigAuthParams:
+			val = c.AuthParams[param.name]
+		case typedConfigResolvedEnv:
+			if env := c.TriggerMetadata[param.name+"FromEnv"]; env != "" {
+				val = c.ResolvedEnv[env]
+			}
+		}
+		if val != "" {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+func (c *ScalerConfig) setTypedConfigParam(field reflect.Value, param typedConfigParam) error {
+	val, found := c.lookup(param)
+	if found && param.deprecated != "" {
+		return fmt.Errorf("param %q is deprecated: %s", param.name, param.deprecated)
+	}
+	if !found {
+		switch {
+		case param.defaultValue != nil:
+			val = *param.defaultValue
+		case param.optional, param.deprecated != "":
+			return nil
+		default:
+			return fmt.Errorf("missing required param %q in %v", param.name, param.order)
+		}
+	}
+
+	if len(param.enum) > 0 {
+		for _, v := range splitTypedConfigList(field, val) {
+			if !contains(param.enum, v) {
+				return fmt.Errorf("param %q value %q must be one of %v", param.name, v, param.enum)
+			}
+		}
+	}
+	if err := setTypedConfigUnitValue(field, val, param.unit); err != nil {
+		return fmt.Errorf("unable to set param %q value %q: %s", param.name, val, err)
+	}
+	return nil
+}
+
+// setTypedConfigUnitValue parses val into field, the numeric fields with a unit and the float fields are parsed by
+// parseUnitValue, the int fields take whole numbers of their unit
+func setTypedConfigUnitValue(field reflect.Value, val string, unit string) error {
+	kind := field.Kind()
+	isInt := kind >= reflect.Int && kind <= reflect.Int64
+	isFloat := kind == reflect.Float32 || kind == reflect.Float64
+	if field.Type() == durationType || (!isFloat && !(isInt && unit != "")) {
+		return setTypedConfigValue(field, val)
+	}
+	if unit == "" {
+		unit = unitCount
+	}
+
+	f, err := parseUnitValue(val, unit)
+	if err != nil {
+		return err
+	}
+	if isFloat {
+		field.SetFloat(f)
+		return nil
+	}
+	if f != math.Trunc(f) {
+		return fmt.Errorf("%v isn't a whole number of %s", f, unit)
+	}
+	field.SetInt(int64(f))
+	return nil
+}
+
+// splitTypedConfigList returns the items of the value of a list field, the value itself otherwise
+func splitTypedConfigList(field reflect.Value, val string) []string {
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Map {
+		return []string{val}
+	}
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			if field.Kind() == reflect.Map {
+				item = strings.TrimSpace(strings.SplitN(item, "=", 2)[0])
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setTypedConfigValue parses val into field, the lists are separated by `,` and the maps are `key=value` lists
+func setTypedConfigValue(field reflect.Value, val string) error {
+	switch field.Type() {
+	case durationType:
+		duration, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	case quantityType:
+		quantity, err := resource.ParseQuantity(val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(quantity))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setTypedConfigValue(elem.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Slice:
+		items := splitTypedConfigList(field, val)
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setTypedConfigValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		if field.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", field.Type().Key())
+		}
+		m := reflect.MakeMap(field.Type())
+		for _, item := range strings.Split(val, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("%q isn't a key=value pair", item)
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setTypedConfigValue(elem, strings.TrimSpace(kv[1])); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])).Convert(field.Type().Key()), elem)
+		}
+		field.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
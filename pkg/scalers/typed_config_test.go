@@ -0,0 +1,107 @@
+package scalers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type typedConfigTestMetadata struct {
+	Query       string            `keda:"name=query"`
+	TargetValue float64           `keda:"name=targetValue, default=5"`
+	Password    string            `keda:"name=password, order=authParams;resolvedEnv, optional"`
+	Mode        string            `keda:"name=mode, optional, enum=sum;max"`
+	Timeout     time.Duration     `keda:"name=timeout, default=10s"`
+	Size        resource.Quantity `keda:"name=size, optional"`
+	Port        *uint16           `keda:"name=port, optional"`
+	Queues      []string          `keda:"name=queues, optional"`
+	Weights     map[string]int    `keda:"name=weights, optional"`
+	MaxAge      int64             `keda:"name=maxAge, optional, unit=seconds"`
+	Legacy      string            `keda:"name=legacy, deprecated=use query instead"`
+}
+
+func (m *typedConfigTestMetadata) Validate() error {
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	return nil
+}
+
+type typedConfigTestData struct {
+	config  ScalerConfig
+	isError bool
+}
+
+var testTypedConfigs = []typedConfigTestData{
+	// only the required param
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q"}}, false},
+	// all params
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "targetValue": "2.5", "mode": "max", "timeout": "1m", "size": "1Gi", "port": "8080", "queues": "a, b", "weights": "a=1,b=2"}, AuthParams: map[string]string{"password": "secret"}}, false},
+	// password from env
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "passwordFromEnv": "PASSWORD"}, ResolvedEnv: map[string]string{"PASSWORD": "secret"}}, false},
+	// missing required param
+	{ScalerConfig{TriggerMetadata: map[string]string{}}, true},
+	// invalid float
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "targetValue": "ten"}}, true},
+	// value not in enum
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "mode": "min"}}, true},
+	// port out of range
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "port": "70000"}}, true},
+	// target value with a suffix
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "targetValue": "2.5k"}}, false},
+	// target value with the milli suffix
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "targetValue": "5m"}}, true},
+	// fraction of the unit of an int
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "maxAge": "1500ms"}}, true},
+	// invalid map
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "weights": "a"}}, true},
+	// deprecated param
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "legacy": "q"}}, true},
+	// failed validation
+	{ScalerConfig{TriggerMetadata: map[string]string{"query": "q", "targetValue": "-1"}}, true},
+}
+
+func TestTypedConfig(t *testing.T) {
+	for i, test := range testTypedConfigs {
+		meta := typedConfigTestMetadata{}
+		err := test.config.TypedConfig(&meta)
+		if test.isError {
+			assert.Error(t, err, "test %d", i)
+		} else {
+			assert.NoError(t, err, "test %d", i)
+		}
+	}
+}
+
+func TestTypedConfigValues(t *testing.T) {
+	config := ScalerConfig{
+		TriggerMetadata: map[string]string{"query": "q", "passwordFromEnv": "PASSWORD", "mode": "max", "size": "1Gi", "port": "8080", "queues": "a, b", "weights": "a=1,b=2", "maxAge": "2m"},
+		AuthParams:      map[string]string{"password": "secret"},
+		ResolvedEnv:     map[string]string{"PASSWORD": "env"},
+	}
+	meta := typedConfigTestMetadata{}
+	assert.NoError(t, config.TypedConfig(&meta))
+
+	port := uint16(8080)
+	assert.Equal(t, typedConfigTestMetadata{
+		Query:       "q",
+		TargetValue: 5,
+		Password:    "secret",
+		Mode:        "max",
+		Timeout:     10 * time.Second,
+		Size:        resource.MustParse("1Gi"),
+		Port:        &port,
+		Queues:      []string{"a", "b"},
+		Weights:     map[string]int{"a": 1, "b": 2},
+		MaxAge:      120,
+	}, meta)
+
+	// the errors of all the params are returned
+	err := (&ScalerConfig{TriggerMetadata: map[string]string{"mode": "min"}}).TypedConfig(&typedConfigTestMetadata{})
+	assert.EqualError(t, err, `[missing required param "query" in [triggerMetadata], param "mode" value "min" must be one of [sum max]]`)
+
+	assert.Error(t, config.TypedConfig(meta))
+}
@@ -0,0 +1,120 @@
+package scalers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// the units of the numeric parameters of the scalers, a value is given either as a plain number in the unit or with
+// a unit of its kind, eg. `5m` for seconds or `1Gi` for bytes, and converted to the unit
+const (
+	// unitCount values are plain numbers or quantities with a decimal or binary suffix, eg. `2.5k` or `1Mi`
+	unitCount = "count"
+
+	unitMicroseconds = "microseconds"
+	unitMilliseconds = "milliseconds"
+	unitSeconds      = "seconds"
+	unitMinutes      = "minutes"
+
+	unitBytes     = "bytes"
+	unitKilobytes = "kilobytes"
+	unitMegabytes = "megabytes"
+	unitGigabytes = "gigabytes"
+	unitTerabytes = "terabytes"
+)
+
+// durationUnits are the durations of the duration units, the values are time.ParseDuration strings, eg. `1m30s`
+var durationUnits = map[string]time.Duration{
+	unitMicroseconds: time.Microsecond,
+	unitMilliseconds: time.Millisecond,
+	unitSeconds:      time.Second,
+	unitMinutes:      time.Minute,
+}
+
+// sizeUnits are the bytes of the size units, the values are quantities, eg. `1Gi` or `500M`
+var sizeUnits = map[string]float64{
+	unitBytes:     1,
+	unitKilobytes: 1e3,
+	unitMegabytes: 1e6,
+	unitGigabytes: 1e9,
+	unitTerabytes: 1e12,
+}
+
+// isUnit returns whether unit is one of the units parsed by parseUnitValue
+func isUnit(unit string) bool {
+	_, isDuration := durationUnits[unit]
+	_, isSize := sizeUnits[unit]
+	return unit == unitCount || isDuration || isSize
+}
+
+// parseUnitValue parses val into a number of unit, a plain number is already in the unit
+func parseUnitValue(val string, unit string) (float64, error) {
+	val = strings.TrimSpace(val)
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f, nil
+	}
+
+	if scale, ok := durationUnits[unit]; ok {
+		duration, err := time.ParseDuration(val)
+		if err != nil {
+			return 0, fmt.Errorf("%q must be a number of %s or a duration, eg. 1m30s", val, unit)
+		}
+		return float64(duration) / float64(scale), nil
+	}
+
+	scale, isSize := sizeUnits[unit]
+	if !isSize && unit != unitCount {
+		return 0, fmt.Errorf("unknown unit %q", unit)
+	}
+	// the milli suffix is mostly meant as minutes, the fractions are given as decimals
+	if strings.HasSuffix(val, "m") {
+		return 0, fmt.Errorf("%q has the milli suffix, give fractions as decimals, eg. 0.5", val)
+	}
+	quantity, err := resource.ParseQuantity(val)
+	if err != nil {
+		if isSize {
+			return 0, fmt.Errorf("%q must be a number of %s or a size, eg. 1Gi", val, unit)
+		}
+		return 0, fmt.Errorf("%q must be a number, eg. 2.5k", val)
+	}
+	if isSize {
+		return quantity.AsApproximateFloat64() / scale, nil
+	}
+	return quantity.AsApproximateFloat64(), nil
+}
+
+// getUnitMetadataValue parses the parameter key of the trigger metadata into a number of unit, defaultValue is
+// returned when the parameter isn't given unless it is required
+func getUnitMetadataValue(metadata map[string]string, key string, unit string, required bool, defaultValue float64) (float64, error) {
+	if val, ok := metadata[key]; ok && val != "" {
+		value, err := parseUnitValue(val, unit)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing %s: %s", key, err)
+		}
+		return value, nil
+	}
+
+	if required {
+		return 0, fmt.Errorf("no %s given", key)
+	}
+
+	return defaultValue, nil
+}
+
+// getIntUnitMetadataValue parses the parameter key of the trigger metadata into a whole number of unit, eg. `2m` is
+// 120 seconds, defaultValue is returned when the parameter isn't given unless it is required
+func getIntUnitMetadataValue(metadata map[string]string, key string, unit string, required bool, defaultValue int64) (int64, error) {
+	value, err := getUnitMetadataValue(metadata, key, unit, required, float64(defaultValue))
+	if err != nil {
+		return 0, err
+	}
+	if value != math.Trunc(value) {
+		return 0, fmt.Errorf("error parsing %s: %v isn't a whole number of %s", key, value, unit)
+	}
+	return int64(value), nil
+}
@@ -0,0 +1,67 @@
+package scalers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseUnitValueTestData struct {
+	value    string
+	unit     string
+	expected float64
+	isError  bool
+}
+
+var testUnitValues = []parseUnitValueTestData{
+	// plain numbers are in the unit
+	{"2.5", unitCount, 2.5, false},
+	{"90", unitSeconds, 90, false},
+	{"1024", unitBytes, 1024, false},
+	// counts
+	{"2.5k", unitCount, 2500, false},
+	{"1Mi", unitCount, 1 << 20, false},
+	{"5m", unitCount, 0, true},
+	{"ten", unitCount, 0, true},
+	// durations
+	{"5m", unitSeconds, 300, false},
+	{"1m30s", unitSeconds, 90, false},
+	{"1.5s", unitMilliseconds, 1500, false},
+	{"1h", unitMinutes, 60, false},
+	{"5 minutes", unitSeconds, 0, true},
+	// sizes
+	{"1Gi", unitBytes, 1 << 30, false},
+	{"1.5G", unitMegabytes, 1500, false},
+	{"500M", unitGigabytes, 0.5, false},
+	{"5m", unitBytes, 0, true},
+	{"1GB", unitBytes, 0, true},
+	// unknown unit
+	{"5k", "hours", 0, true},
+}
+
+func TestParseUnitValue(t *testing.T) {
+	for _, test := range testUnitValues {
+		value, err := parseUnitValue(test.value, test.unit)
+		if test.isError {
+			assert.Error(t, err, "%s in %s", test.value, test.unit)
+			continue
+		}
+		assert.NoError(t, err, "%s in %s", test.value, test.unit)
+		assert.Equal(t, test.expected, value, "%s in %s", test.value, test.unit)
+	}
+}
+
+func TestGetIntUnitMetadataValue(t *testing.T) {
+	value, err := getIntUnitMetadataValue(map[string]string{"period": "2m"}, "period", unitSeconds, true, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(120), value)
+
+	value, err = getIntUnitMetadataValue(map[string]string{}, "period", unitSeconds, false, 60)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), value)
+
+	_, err = getIntUnitMetadataValue(map[string]string{"period": "1500ms"}, "period", unitSeconds, true, 0)
+	assert.Error(t, err)
+	_, err = getIntUnitMetadataValue(map[string]string{}, "period", unitSeconds, true, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package scalers
+
+import (
+	"fmt"
+)
+
+// metadataParser parses the metadata of a trigger type, it must not connect to the backend of the scaler
+type metadataParser func(config *ScalerConfig) error
+
+func parserOf(parse func(config *ScalerConfig) (interface{}, error)) metadataParser {
+	return func(config *ScalerConfig) error {
+		_, err := parse(config)
+		return err
+	}
+}
+
+// metadataParsers are the parsers used by the constructors of the scalers, keyed by trigger type
+var metadataParsers = map[string]metadataParser{
+	"alertmanager":       parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAlertmanagerMetadata(c) }),
+	"arangodb":           parserOf(func(c *ScalerConfig) (interface{}, error) { return parseArangoDBMetadata(c) }),
+	"argo-workflows":     parserOf(func(c *ScalerConfig) (interface{}, error) { return parseArgoWorkflowsMetadata(c) }),
+	"artemis-queue":      parserOf(func(c *ScalerConfig) (interface{}, error) { return parseArtemisMetadata(c) }),
+	"aws-cloudwatch":     parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAwsCloudwatchMetadata(c) }),
+	"aws-kinesis-stream": parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAwsKinesisStreamMetadata(c) }),
+	"aws-sqs-queue":      parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAwsSqsQueueMetadata(c) }),
+	"azure-blob": func(c *ScalerConfig) error {
+		_, _, err := parseAzureBlobMetadata(c)
+		return err
+	},
+	"azure-eventhub":      parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAzureEventHubMetadata(c) }),
+	"azure-log-analytics": parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAzureLogAnalyticsMetadata(c) }),
+	"azure-monitor":       parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAzureMonitorMetadata(c) }),
+	"azure-pipelines":     parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAzurePipelinesMetadata(c) }),
+	"azure-queue": func(c *ScalerConfig) error {
+		_, _, err := parseAzureQueueMetadata(c)
+		return err
+	},
+	"azure-servicebus":  parserOf(func(c *ScalerConfig) (interface{}, error) { return parseAzureServiceBusMetadata(c) }),
+	"cassandra":         parserOf(func(c *ScalerConfig) (interface{}, error) { return ParseCassandraMetadata(c) }),
+	"couchdb":           parserOf(func(c *ScalerConfig) (interface{}, error) { return parseCouchDBMetadata(c) }),
+	"cpu":               parserOf(func(c *ScalerConfig) (interface{}, error) { return parseResourceMetadata(c) }),
+	"cron":              parserOf(func(c *ScalerConfig) (interface{}, error) { return parseCronMetadata(c) }),
+	"db2":               parserOf(func(c *ScalerConfig) (interface{}, error) { return parseDb2Metadata(c) }),
+	"druid":             parserOf(func(c *ScalerConfig) (interface{}, error) { return parseDruidMetadata(c) }),
+	"envoy-concurrency": parserOf(func(c *ScalerConfig) (interface{}, error) { return parseEnvoyConcurrencyMetadata(c) }),
+	"external":          parserOf(func(c *ScalerConfig) (interface{}, error) { return parseExternalScalerMetadata(c) }),
+	"external-push":     parserOf(func(c *ScalerConfig) (interface{}, error) { return parseExternalScalerMetadata(c) }),
+	"gcp-pubsub":        parserOf(func(c *ScalerConfig) (interface{}, error) { return parsePubSubMetadata(c) }),
+	"graphite":          parserOf(func(c *ScalerConfig) (interface{}, error) { return parseGraphiteMetadata(c) }),
+	"honeycomb":         parserOf(func(c *ScalerConfig) (interface{}, error) { return parseHoneycombMetadata(c) }),
+	"http-requests": func(c *ScalerConfig) error {
+		_, _, err := parseHTTPRequestsMetadata(c)
+		return err
+	},
+	"huawei-cloudeye":     parserOf(func(c *ScalerConfig) (interface{}, error) { return parseHuaweiCloudeyeMetadata(c) }),
+	"ibmmq":               parserOf(func(c *ScalerConfig) (interface{}, error) { return parseIBMMQMetadata(c) }),
+	"influxdb":            parserOf(func(c *ScalerConfig) (interface{}, error) { return parseInfluxDBMetadata(c) }),
+	"kafka":               parserOf(func(c *ScalerConfig) (interface{}, error) { return parseKafkaMetadata(c) }),
+	"keda-federation":     parserOf(func(c *ScalerConfig) (interface{}, error) { return parseKedaFederationMetadata(c) }),
+	"kubernetes-workload": parserOf(func(c *ScalerConfig) (interface{}, error) { return parseWorkloadMetadata(c) }),
+	"liiklus":             parserOf(func(c *ScalerConfig) (interface{}, error) { return parseLiiklusMetadata(c) }),
+	"memcached":           parserOf(func(c *ScalerConfig) (interface{}, error) { return parseMemcachedMetadata(c) }),
+	"memory":              parserOf(func(c *ScalerConfig) (interface{}, error) { return parseResourceMetadata(c) }),
+	"metrics-api":         parserOf(func(c *ScalerConfig) (interface{}, error) { return parseMetricsAPIMetadata(c) }),
+	"mongodb": func(c *ScalerConfig) error {
+		_, _, err := parseMongoDBMetadata(c)
+		return err
+	},
+	"mssql":  parserOf(func(c *ScalerConfig) (interface{}, error) { return parseMSSQLMetadata(c) }),
+	"mysql":  parserOf(func(c *ScalerConfig) (interface{}, error) { return parseMySQLMetadata(c) }),
+	"object": parserOf(func(c *ScalerConfig) (interface{}, error) { return parseObjectMetadata(c) }),
+	"openstack-metric": func(c *ScalerConfig) error {
+		if _, err := parseOpenstackMetricMetadata(c); err != nil {
+			return err
+		}
+		_, err := parseOpenstackMetricAuthenticationMetadata(c)
+		return err
+	},
+	"openstack-swift": func(c *ScalerConfig) error {
+		if _, err := parseOpenstackSwiftMetadata(c); err != nil {
+			return err
+		}
+		_, err := parseOpenstackSwiftAuthenticationMetadata(c)
+		return err
+	},
+	"postgresql": parserOf(func(c *ScalerConfig) (interface{}, error) { return parsePostgreSQLMetadata(c) }),
+	"prometheus": parserOf(func(c *ScalerConfig) (interface{}, error) { return parsePrometheusMetadata(c) }),
+	"rabbitmq":   parserOf(func(c *ScalerConfig) (interface{}, error) { return parseRabbitMQMetadata(c) }),
+	"redis": parserOf(func(c *ScalerConfig) (interface{}, error) {
+		return parseRedisMetadata(c, parseRedisAddress)
+	}),
+	"redis-cluster": parserOf(func(c *ScalerConfig) (interface{}, error) {
+		return parseRedisMetadata(c, parseRedisClusterAddress)
+	}),
+	"redis-cluster-streams": parserOf(func(c *ScalerConfig) (interface{}, error) {
+		return parseRedisStreamsMetadata(c, parseRedisClusterAddress)
+	}),
+	"redis-sentinel": parserOf(func(c *ScalerConfig) (interface{}, error) {
+		return parseRedisMetadata(c, parseRedisSentinelAddress)
+	}),
+	"redis-sentinel-streams": parserOf(func(c *ScalerConfig) (interface{}, error) {
+		return parseRedisStreamsMetadata(c, parseRedisSentinelAddress)
+	}),
+	"redis-streams": parserOf(func(c *ScalerConfig) (interface{}, error) {
+		return parseRedisStreamsMetadata(c, parseRedisAddress)
+	}),
+	"request-concurrency": parserOf(func(c *ScalerConfig) (interface{}, error) { return parseRequestConcurrencyMetadata(c) }),
+	"selenium-grid":       parserOf(func(c *ScalerConfig) (interface{}, error) { return parseSeleniumGridScalerMetadata(c) }),
+	"simulator":           parserOf(func(c *ScalerConfig) (interface{}, error) { return parseSimulatorMetadata(c) }),
+	"slo-burn-rate":       parserOf(func(c *ScalerConfig) (interface{}, error) { return parseSLOBurnRateMetadata(c) }),
+	"solace-event-queue":  parserOf(func(c *ScalerConfig) (interface{}, error) { return parseSolaceMetadata(c) }),
+	"stan":                parserOf(func(c *ScalerConfig) (interface{}, error) { return parseStanMetadata(c) }),
+	"sumologic":           parserOf(func(c *ScalerConfig) (interface{}, error) { return parseSumoLogicMetadata(c) }),
+	"tekton":              parserOf(func(c *ScalerConfig) (interface{}, error) { return parseTektonMetadata(c) }),
+	"trino":               parserOf(func(c *ScalerConfig) (interface{}, error) { return parseTrinoMetadata(c) }),
+	"wasm":                parserOf(func(c *ScalerConfig) (interface{}, error) { return parseWasmMetadata(c) }),
+	"webhook":             parserOf(func(c *ScalerConfig) (interface{}, error) { return parseWebhookMetadata(c) }),
+}
+
+// ValidateTriggerMetadata parses the metadata of a trigger with the parser of its scaler without connecting to the
+// backend, so the typos and the invalid values are caught before the trigger is deployed
+func ValidateTriggerMetadata(triggerType string, config *ScalerConfig) error {
+	parse, ok := metadataParsers[triggerType]
+	if !ok {
+		return fmt.Errorf("no scaler found for type: %s", triggerType)
+	}
+	if err := parse(config); err != nil {
+		return fmt.Errorf("error parsing %s metadata: %s", triggerType, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package scalers
+
+import (
+	"testing"
+)
+
+type validateTriggerMetadataTestData struct {
+	triggerType string
+	metadata    map[string]string
+	isError     bool
+}
+
+var validateTriggerMetadataTestDataset = []validateTriggerMetadataTestData{
+	{"cron", map[string]string{"timezone": "UTC", "start": "0 8 * * *", "end": "0 18 * * *", "desiredReplicas": "5"}, false},
+	{"cron", map[string]string{"timezone": "UTC", "start": "0 8 * * *"}, true},
+	{"selenium-grid", map[string]string{"url": "https://selenium-hub:4444/graphql", "browserName": "chrome", "unsafeSsl": "true"}, false},
+	{"selenium-grid", map[string]string{"url": "https://selenium-hub:4444/graphql", "browserName": "chrome", "unsafeSsl": "ture"}, true},
+	// the address of the redis scalers is parsed by the parser of their mode
+	{"redis-cluster", map[string]string{"addresses": "redis-0:6379, redis-1:6379", "listName": "jobs"}, false},
+	{"redis-cluster", map[string]string{"address": "", "listName": "jobs"}, true},
+	{"no-such-scaler", map[string]string{}, true},
+}
+
+func TestValidateTriggerMetadata(t *testing.T) {
+	for _, testData := range validateTriggerMetadataTestDataset {
+		config := &ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: map[string]string{}, AuthParams: map[string]string{}}
+		err := ValidateTriggerMetadata(testData.triggerType, config)
+		if err != nil && !testData.isError {
+			t.Errorf("%s: expected success but got error %s", testData.triggerType, err)
+		}
+		if err == nil && testData.isError {
+			t.Errorf("%s: expected error but got success for %v", testData.triggerType, testData.metadata)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package scalers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// gjsonModifiers are the modifiers of the gjson version in use, eg. `data.@reverse.0`
+var gjsonModifiers = map[string]bool{
+	"pretty": true, "ugly": true, "reverse": true, "this": true, "flatten": true,
+	"join": true, "valid": true, "keys": true, "values": true,
+}
+
+// gjsonClosing are the closing brackets of the queries and multipaths of gjson
+var gjsonClosing = map[byte]byte{'(': ')', '[': ']', '{': '}'}
+
+// validateValueLocation rejects the valueLocation paths gjson can't select a value with, eg. empty components,
+// unbalanced brackets or unknown modifiers, when sampleResponse is set in the metadata the value is read from it
+// so a path not pointing to a number is rejected too, instead of failing every query at runtime
+func validateValueLocation(valueLocation string, metadata map[string]string) error {
+	if err := validateGJSONPath(valueLocation); err != nil {
+		return fmt.Errorf("invalid valueLocation %q: %s", valueLocation, err)
+	}
+
+	sample, ok := metadata["sampleResponse"]
+	if !ok || sample == "" {
+		return nil
+	}
+	if !json.Valid([]byte(sample)) {
+		return fmt.Errorf("sampleResponse must be a json document")
+	}
+	if _, err := GetValueFromResponse([]byte(sample), valueLocation); err != nil {
+		return fmt.Errorf("invalid valueLocation %q for the sampleResponse: %s", valueLocation, err)
+	}
+	return nil
+}
+
+// validateGJSONPath checks the syntax of a gjson path, the components are separated by `.` or `|` outside of the
+// queries `#(...)` and the multipaths `[...]`, `{...}`, and `\` escapes the next character
+func validateGJSONPath(path string) error {
+	// a leading `..` reads the path from JSON lines
+	path = strings.TrimPrefix(path, "..")
+	if path == "" {
+		return fmt.Errorf("the path is empty")
+	}
+
+	var stack []byte
+	var inString, escaped bool
+	component := 0
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case inString:
+			inString = c != '"'
+		case c == '"' && len(stack) > 0:
+			inString = true
+		case gjsonClosing[c] != 0:
+			stack = append(stack, gjsonClosing[c])
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			stack = stack[:len(stack)-1]
+		case len(stack) == 0 && (c == '.' || c == '|'):
+			if component == 0 {
+				return fmt.Errorf("empty component at %d", i)
+			}
+			component = 0
+			continue
+		case len(stack) == 0 && c == '@' && component == 0:
+			end := strings.IndexAny(path[i+1:], ".|:")
+			if end < 0 {
+				end = len(path) - i - 1
+			}
+			if name := path[i+1 : i+1+end]; !gjsonModifiers[name] {
+				return fmt.Errorf("unknown modifier @%s", name)
+			}
+		}
+		component++
+	}
+
+	switch {
+	case escaped:
+		return fmt.Errorf("the path ends with an escape")
+	case inString:
+		return fmt.Errorf("unterminated string")
+	case len(stack) > 0:
+		return fmt.Errorf("missing %q", stack[len(stack)-1])
+	case component == 0:
+		return fmt.Errorf("the path ends with a separator")
+	}
+	return nil
+}
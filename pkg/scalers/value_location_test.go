@@ -0,0 +1,51 @@
+package scalers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validateValueLocationTestData struct {
+	valueLocation string
+	metadata      map[string]string
+	isError       bool
+}
+
+var testValueLocations = []validateValueLocationTestData{
+	{"components.worker.tasks", nil, false},
+	{"components.0.tasks", nil, false},
+	{"queues.#", nil, false},
+	{`queues.#(name=="orders").depth`, nil, false},
+	{`queues.#(name=="a.b|c").depth`, nil, false},
+	{`version\.major`, nil, false},
+	{"queues.@reverse.0.depth", nil, false},
+	{"@this", nil, false},
+	{"..0.depth", nil, false},
+	{"", nil, true},
+	{"components..tasks", nil, true},
+	{".tasks", nil, true},
+	{"components.tasks.", nil, true},
+	{"queues.#(name==orders.depth", nil, true},
+	{"queues.#(name==orders)).depth", nil, true},
+	{`queues.#(name=="orders).depth`, nil, true},
+	{`tasks\`, nil, true},
+	{"queues.@sum", nil, true},
+	// sample response
+	{"components.worker.tasks", map[string]string{"sampleResponse": `{"components": {"worker": {"tasks": 12}}}`}, false},
+	{"components.worker.tasks", map[string]string{"sampleResponse": `{"components": {"worker": {"tasks": "12k"}}}`}, false},
+	{"components.worker", map[string]string{"sampleResponse": `{"components": {"worker": {"tasks": 12}}}`}, true},
+	{"components.worker.task", map[string]string{"sampleResponse": `{"components": {"worker": {"tasks": 12}}}`}, true},
+	{"components.worker.tasks", map[string]string{"sampleResponse": `{"components": `}, true},
+}
+
+func TestValidateValueLocation(t *testing.T) {
+	for _, test := range testValueLocations {
+		err := validateValueLocation(test.valueLocation, test.metadata)
+		if test.isError {
+			assert.Error(t, err, test.valueLocation)
+		} else {
+			assert.NoError(t, err, test.valueLocation)
+		}
+	}
+}
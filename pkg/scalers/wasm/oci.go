@@ -0,0 +1,329 @@
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultRegistry = "registry-1.docker.io"
+
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// maxModuleSize bounds the size of the downloaded modules and manifests
+	maxModuleSize = 64 << 20
+)
+
+// wasmLayerMediaTypes are the media types of the layers holding the module, a manifest with a single layer of
+// another media type is accepted too, eg. when the module was pushed with the default media type of oras
+var wasmLayerMediaTypes = []string{
+	"application/vnd.wasm.content.layer.v1+wasm",
+	"application/vnd.module.wasm.content.layer.v1+wasm",
+}
+
+// Reference is the reference of a module distributed as an OCI artifact, eg. ghcr.io/org/scaler:1.0 or
+// ghcr.io/org/scaler@sha256:...
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// RegistryCredentials are used to pull modules from private registries
+type RegistryCredentials struct {
+	Username string
+	Password string
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ParseReference parses an OCI reference, the registry defaults to docker.io and the tag to latest
+func ParseReference(ref string) (Reference, error) {
+	if ref == "" {
+		return Reference{}, fmt.Errorf("empty module reference")
+	}
+	result := Reference{}
+
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		result.Digest = name[i+1:]
+		name = name[:i]
+		if !strings.HasPrefix(result.Digest, "sha256:") || len(result.Digest) != len("sha256:")+64 {
+			return Reference{}, fmt.Errorf("unsupported digest %s, only sha256 is supported", result.Digest)
+		}
+	}
+	// a colon after the last slash separates the tag, a colon before is the port of the registry
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		result.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		result.Registry = parts[0]
+		result.Repository = parts[1]
+	} else {
+		result.Registry = defaultRegistry
+		result.Repository = name
+		if len(parts) == 1 {
+			result.Repository = "library/" + name
+		}
+	}
+	if result.Repository == "" || strings.ContainsAny(result.Repository, " \t") {
+		return Reference{}, fmt.Errorf("invalid module reference %s", ref)
+	}
+	if result.Tag == "" && result.Digest == "" {
+		result.Tag = "latest"
+	}
+	return result, nil
+}
+
+func (r Reference) String() string {
+	result := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		result += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		result += "@" + r.Digest
+	}
+	return result
+}
+
+// registryClient pulls from the distribution API of a registry, anonymous or basic credentials are exchanged for
+// a bearer token when the registry asks for it
+type registryClient struct {
+	httpClient  *http.Client
+	scheme      string
+	credentials *RegistryCredentials
+	token       string
+}
+
+func newRegistryClient(httpClient *http.Client, credentials *RegistryCredentials, insecure bool) *registryClient {
+	client := &registryClient{
+		httpClient:  httpClient,
+		scheme:      "https",
+		credentials: credentials,
+	}
+	if insecure {
+		client.scheme = "http"
+	}
+	return client
+}
+
+// Resolve returns the reference pinned to the digest of its manifest, the references with a digest are returned as is
+func Resolve(ctx context.Context, httpClient *http.Client, ref Reference, credentials *RegistryCredentials, insecure bool) (Reference, error) {
+	if ref.Digest != "" {
+		return ref, nil
+	}
+	body, err := newRegistryClient(httpClient, credentials, insecure).getManifest(ctx, ref)
+	if err != nil {
+		return Reference{}, err
+	}
+	sum := sha256.Sum256(body)
+	ref.Digest = "sha256:" + hex.EncodeToString(sum[:])
+	return ref, nil
+}
+
+// Pull downloads the module of the reference and verifies its digest, insecure pulls over plain http
+func Pull(ctx context.Context, httpClient *http.Client, ref Reference, credentials *RegistryCredentials, insecure bool) ([]byte, error) {
+	client := newRegistryClient(httpClient, credentials, insecure)
+	body, err := client.getManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := ociManifest{}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing the manifest of %s: %s", ref, err)
+	}
+	layer, err := findModuleLayer(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error in the manifest of %s: %s", ref, err)
+	}
+
+	module, err := client.get(ctx, ref, "blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, fmt.Errorf("error getting the module of %s: %s", ref, err)
+	}
+	if err := verifyDigest(module, layer.Digest); err != nil {
+		return nil, fmt.Errorf("error verifying the module of %s: %s", ref, err)
+	}
+	return module, nil
+}
+
+// getManifest gets the manifest of the reference by digest if it has one, by tag otherwise
+func (c *registryClient) getManifest(ctx context.Context, ref Reference) ([]byte, error) {
+	manifestRef := ref.Tag
+	if ref.Digest != "" {
+		manifestRef = ref.Digest
+	}
+	body, err := c.get(ctx, ref, "manifests/"+manifestRef, strings.Join([]string{ociManifestMediaType, dockerManifestMediaType}, ", "))
+	if err != nil {
+		return nil, fmt.Errorf("error getting the manifest of %s: %s", ref, err)
+	}
+	if ref.Digest != "" {
+		if err := verifyDigest(body, ref.Digest); err != nil {
+			return nil, fmt.Errorf("error verifying the manifest of %s: %s", ref, err)
+		}
+	}
+	return body, nil
+}
+
+func findModuleLayer(manifest ociManifest) (ociDescriptor, error) {
+	for _, layer := range manifest.Layers {
+		for _, mediaType := range wasmLayerMediaTypes {
+			if layer.MediaType == mediaType {
+				return layer, nil
+			}
+		}
+	}
+	if len(manifest.Layers) == 1 {
+		return manifest.Layers[0], nil
+	}
+	return ociDescriptor{}, fmt.Errorf("no wasm layer found in %d layers", len(manifest.Layers))
+}
+
+func verifyDigest(content []byte, digest string) error {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return fmt.Errorf("unsupported digest %s, only sha256 is supported", digest)
+	}
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != strings.TrimPrefix(digest, "sha256:") {
+		return fmt.Errorf("digest mismatch, expected %s got sha256:%s", digest, actual)
+	}
+	return nil
+}
+
+func (c *registryClient) get(ctx context.Context, ref Reference, path string, accept string) ([]byte, error) {
+	resp, err := c.do(ctx, ref, path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authenticate(ctx, ref, challenge); err != nil {
+			return nil, err
+		}
+		resp, err = c.do(ctx, ref, path, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxModuleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry returned %d: %s", resp.StatusCode, string(body))
+	}
+	if len(body) > maxModuleSize {
+		return nil, fmt.Errorf("content is bigger than %d bytes", maxModuleSize)
+	}
+	return body, nil
+}
+
+func (c *registryClient) do(ctx context.Context, ref Reference, path string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s/v2/%s/%s", c.scheme, ref.Registry, ref.Repository, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.credentials != nil:
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+	return c.httpClient.Do(req)
+}
+
+// authenticate gets a pull token from the realm of a bearer challenge
+func (c *registryClient) authenticate(ctx context.Context, ref Reference, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return fmt.Errorf("registry requires %s authentication", challenge)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return fmt.Errorf("error parsing the token realm: %s", err)
+	}
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.Repository))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.credentials != nil {
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token realm returned %d", resp.StatusCode)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("error parsing the token: %s", err)
+	}
+	c.token = token.Token
+	if c.token == "" {
+		c.token = token.AccessToken
+	}
+	if c.token == "" {
+		return fmt.Errorf("token realm returned no token")
+	}
+	return nil
+}
+
+// parseChallenge parses a WWW-Authenticate header like: Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	for _, param := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return parts[0], params
+}
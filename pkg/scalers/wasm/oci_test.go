@@ -0,0 +1,142 @@
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testDigest = "sha256:" + strings.Repeat("a", 64)
+
+type parseReferenceTestData struct {
+	ref      string
+	expected Reference
+	isError  bool
+}
+
+var testReferences = []parseReferenceTestData{
+	{"ghcr.io/org/scaler:1.0", Reference{Registry: "ghcr.io", Repository: "org/scaler", Tag: "1.0"}, false},
+	{"registry.local:5000/scalers/queue", Reference{Registry: "registry.local:5000", Repository: "scalers/queue", Tag: "latest"}, false},
+	{"localhost/scaler@" + testDigest, Reference{Registry: "localhost", Repository: "scaler", Digest: testDigest}, false},
+	{"ghcr.io/org/scaler:1.0@" + testDigest, Reference{Registry: "ghcr.io", Repository: "org/scaler", Tag: "1.0", Digest: testDigest}, false},
+	{"org/scaler", Reference{Registry: defaultRegistry, Repository: "org/scaler", Tag: "latest"}, false},
+	{"scaler:2", Reference{Registry: defaultRegistry, Repository: "library/scaler", Tag: "2"}, false},
+	{"", Reference{}, true},
+	{"ghcr.io/org/scaler@md5:abc", Reference{}, true},
+}
+
+func TestParseReference(t *testing.T) {
+	for _, test := range testReferences {
+		ref, err := ParseReference(test.ref)
+		if test.isError {
+			assert.Error(t, err, test.ref)
+			continue
+		}
+		assert.NoError(t, err, test.ref)
+		assert.Equal(t, test.expected, ref, test.ref)
+	}
+}
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// startRegistry serves a module behind a token challenge, like ghcr.io does for public images
+func startRegistry(t *testing.T, module []byte, layerDigest string) (*httptest.Server, string) {
+	manifest, _ := json.Marshal(ociManifest{
+		MediaType: ociManifestMediaType,
+		Layers: []ociDescriptor{
+			{MediaType: "application/vnd.oci.image.config.v1+json", Digest: digestOf([]byte("{}"))},
+			{MediaType: wasmLayerMediaTypes[0], Digest: layerDigest, Size: int64(len(module))},
+		},
+	})
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Regexp(t, "^repository:org/[a-z]+:pull$", r.URL.Query().Get("scope"))
+			user, password, _ := r.BasicAuth()
+			assert.Equal(t, "user", user)
+			assert.Equal(t, "secret", password)
+			fmt.Fprint(w, `{"token":"pull-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer pull-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/scaler/manifests/1.0", "/v2/org/scaler/manifests/" + digestOf(manifest):
+			assert.Contains(t, r.Header.Get("Accept"), ociManifestMediaType)
+			w.Write(manifest)
+		case "/v2/org/scaler/blobs/" + layerDigest:
+			w.Write(module)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, digestOf(manifest)
+}
+
+func TestPull(t *testing.T) {
+	module := []byte("module")
+	server, manifestDigest := startRegistry(t, module, digestOf(module))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+	credentials := &RegistryCredentials{Username: "user", Password: "secret"}
+
+	pulled, err := Pull(context.Background(), server.Client(), Reference{Registry: registry, Repository: "org/scaler", Tag: "1.0"}, credentials, true)
+	assert.NoError(t, err)
+	assert.Equal(t, module, pulled)
+
+	pulled, err = Pull(context.Background(), server.Client(), Reference{Registry: registry, Repository: "org/scaler", Digest: manifestDigest}, credentials, true)
+	assert.NoError(t, err)
+	assert.Equal(t, module, pulled)
+
+	// the manifest doesn't match the digest of the reference
+	_, err = Pull(context.Background(), server.Client(), Reference{Registry: registry, Repository: "org/scaler", Tag: "1.0", Digest: testDigest}, credentials, true)
+	assert.Error(t, err)
+
+	_, err = Pull(context.Background(), server.Client(), Reference{Registry: registry, Repository: "org/missing", Tag: "1.0"}, credentials, true)
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	module := []byte("module")
+	server, manifestDigest := startRegistry(t, module, digestOf(module))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+	credentials := &RegistryCredentials{Username: "user", Password: "secret"}
+
+	resolved, err := Resolve(context.Background(), server.Client(), Reference{Registry: registry, Repository: "org/scaler", Tag: "1.0"}, credentials, true)
+	assert.NoError(t, err)
+	assert.Equal(t, Reference{Registry: registry, Repository: "org/scaler", Tag: "1.0", Digest: manifestDigest}, resolved)
+
+	// the references with a digest don't reach the registry
+	pinned := Reference{Registry: registry, Repository: "org/missing", Digest: testDigest}
+	resolved, err = Resolve(context.Background(), server.Client(), pinned, credentials, true)
+	assert.NoError(t, err)
+	assert.Equal(t, pinned, resolved)
+
+	_, err = Resolve(context.Background(), server.Client(), Reference{Registry: registry, Repository: "org/missing", Tag: "1.0"}, credentials, true)
+	assert.Error(t, err)
+}
+
+func TestPullCorruptedModule(t *testing.T) {
+	module := []byte("module")
+	server, _ := startRegistry(t, module, digestOf([]byte("another module")))
+	defer server.Close()
+
+	_, err := Pull(context.Background(), server.Client(), Reference{Registry: strings.TrimPrefix(server.URL, "http://"), Repository: "org/scaler", Tag: "1.0"}, &RegistryCredentials{Username: "user", Password: "secret"}, true)
+	assert.Error(t, err)
+}
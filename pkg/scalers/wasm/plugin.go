@@ -0,0 +1,323 @@
+// Package wasm runs scalers implemented as WebAssembly modules.
+//
+// A module implements the scaler through the following exports, every call gets a JSON request and returns a JSON
+// response packed in an i64 as (pointer << 32 | length) in the exported memory:
+//
+//	memory
+//	keda_alloc(size i32) i32                     allocates the memory of the requests and of the host responses
+//	keda_parse_metadata(ptr i32, len i32) i64    validates the trigger, returns {"metricName", "targetValue", "error"}
+//	keda_is_active(ptr i32, len i32) i64         returns {"active", "error"}
+//	keda_get_metric(ptr i32, len i32) i64        returns {"value", "error"}
+//
+// The requests hold the metadata and the authentication parameters of the trigger, see Request. A module reaches the
+// systems it scales through the host functions of the "keda" module:
+//
+//	http_request(ptr i32, len i32) i64           performs an HTTPRequest, returns an HTTPResponse
+//	log(ptr i32, len i32)                        writes a message to the log of the operator
+//
+// A new instance of the module serves each call, modules don't keep state between the calls.
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const (
+	// ABIVersion is sent with every request so modules can detect a host they don't support
+	ABIVersion = 1
+
+	hostModuleName = "keda"
+
+	exportAlloc         = "keda_alloc"
+	exportParseMetadata = "keda_parse_metadata"
+	exportIsActive      = "keda_is_active"
+	exportGetMetric     = "keda_get_metric"
+
+	// memoryLimitPages limits the memory of an instance to 32MiB
+	memoryLimitPages = 512
+	// maxHTTPResponseSize bounds the body of the responses returned to the modules
+	maxHTTPResponseSize = 16 << 20
+)
+
+var requiredExports = []string{exportAlloc, exportParseMetadata, exportIsActive, exportGetMetric}
+
+// Request is the input of every call of a module
+type Request struct {
+	ABIVersion int               `json:"abiVersion"`
+	Metadata   map[string]string `json:"metadata"`
+	AuthParams map[string]string `json:"authParams,omitempty"`
+	MetricName string            `json:"metricName,omitempty"`
+}
+
+// ParseMetadataResponse is returned by keda_parse_metadata
+type ParseMetadataResponse struct {
+	MetricName  string  `json:"metricName"`
+	TargetValue float64 `json:"targetValue"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// IsActiveResponse is returned by keda_is_active
+type IsActiveResponse struct {
+	Active bool   `json:"active"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GetMetricResponse is returned by keda_get_metric
+type GetMetricResponse struct {
+	Value float64 `json:"value"`
+	Error string  `json:"error,omitempty"`
+}
+
+// HTTPRequest is the input of the http_request host function
+type HTTPRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// HTTPResponse is returned by the http_request host function
+type HTTPResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// Plugin is a compiled module, it is shared by the scalers using the same module
+type Plugin struct {
+	compiled wazero.CompiledModule
+}
+
+// callEnvironment is passed to the host functions through the context of a call
+type callEnvironment struct {
+	httpClient *http.Client
+	logger     logr.Logger
+}
+
+type callEnvironmentKey struct{}
+
+var (
+	wasmRuntimeOnce sync.Once
+	wasmRuntime     wazero.Runtime
+	wasmRuntimeErr  error
+)
+
+// getRuntime returns the runtime shared by the plugins, the host module is instantiated once
+func getRuntime() (wazero.Runtime, error) {
+	wasmRuntimeOnce.Do(func() {
+		ctx := context.Background()
+		config := wazero.NewRuntimeConfig().
+			WithMemoryLimitPages(memoryLimitPages).
+			// the call timeouts interrupt modules stuck in a loop
+			WithCloseOnContextDone(true)
+		wasmRuntime = wazero.NewRuntimeWithConfig(ctx, config)
+
+		_, wasmRuntimeErr = wasmRuntime.NewHostModuleBuilder(hostModuleName).
+			NewFunctionBuilder().
+			WithGoModuleFunction(api.GoModuleFunc(hostHTTPRequest), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}).
+			WithParameterNames("ptr", "len").
+			Export("http_request").
+			NewFunctionBuilder().
+			WithGoModuleFunction(api.GoModuleFunc(hostLog), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{}).
+			WithParameterNames("ptr", "len").
+			Export("log").
+			Instantiate(ctx)
+	})
+	return wasmRuntime, wasmRuntimeErr
+}
+
+// Compile validates the module and compiles it
+func Compile(ctx context.Context, module []byte) (*Plugin, error) {
+	r, err := getRuntime()
+	if err != nil {
+		return nil, fmt.Errorf("error creating the wasm runtime: %s", err)
+	}
+
+	compiled, err := r.CompileModule(ctx, module)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling the wasm module: %s", err)
+	}
+	exports := compiled.ExportedFunctions()
+	for _, name := range requiredExports {
+		if _, ok := exports[name]; !ok {
+			compiled.Close(ctx)
+			return nil, fmt.Errorf("the wasm module doesn't export %s", name)
+		}
+	}
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok {
+		compiled.Close(ctx)
+		return nil, fmt.Errorf("the wasm module doesn't export memory")
+	}
+	return &Plugin{compiled: compiled}, nil
+}
+
+// ParseMetadata calls keda_parse_metadata
+func (p *Plugin) ParseMetadata(ctx context.Context, httpClient *http.Client, logger logr.Logger, request Request) (ParseMetadataResponse, error) {
+	response := ParseMetadataResponse{}
+	err := p.call(ctx, httpClient, logger, exportParseMetadata, request, &response)
+	if err == nil && response.Error != "" {
+		err = fmt.Errorf("%s", response.Error)
+	}
+	return response, err
+}
+
+// IsActive calls keda_is_active
+func (p *Plugin) IsActive(ctx context.Context, httpClient *http.Client, logger logr.Logger, request Request) (bool, error) {
+	response := IsActiveResponse{}
+	err := p.call(ctx, httpClient, logger, exportIsActive, request, &response)
+	if err == nil && response.Error != "" {
+		err = fmt.Errorf("%s", response.Error)
+	}
+	return response.Active, err
+}
+
+// GetMetric calls keda_get_metric
+func (p *Plugin) GetMetric(ctx context.Context, httpClient *http.Client, logger logr.Logger, request Request) (float64, error) {
+	response := GetMetricResponse{}
+	err := p.call(ctx, httpClient, logger, exportGetMetric, request, &response)
+	if err == nil && response.Error != "" {
+		err = fmt.Errorf("%s", response.Error)
+	}
+	return response.Value, err
+}
+
+// call runs an export on a new instance of the module
+func (p *Plugin) call(ctx context.Context, httpClient *http.Client, logger logr.Logger, export string, request Request, response interface{}) error {
+	r, err := getRuntime()
+	if err != nil {
+		return err
+	}
+	request.ABIVersion = ABIVersion
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, callEnvironmentKey{}, &callEnvironment{httpClient: httpClient, logger: logger})
+	// an empty name allows concurrent instances of the same module
+	mod, err := r.InstantiateModule(ctx, p.compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return fmt.Errorf("error instantiating the wasm module: %s", err)
+	}
+	defer mod.Close(ctx)
+
+	ptr, err := writeToModule(ctx, mod, input)
+	if err != nil {
+		return err
+	}
+	results, err := mod.ExportedFunction(export).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return fmt.Errorf("error calling %s: %s", export, err)
+	}
+	output, err := readFromModule(mod, results[0])
+	if err != nil {
+		return fmt.Errorf("error reading the result of %s: %s", export, err)
+	}
+	if err := json.Unmarshal(output, response); err != nil {
+		return fmt.Errorf("error parsing the result of %s: %s", export, err)
+	}
+	return nil
+}
+
+// Close releases the compiled module
+func (p *Plugin) Close(ctx context.Context) error {
+	return p.compiled.Close(ctx)
+}
+
+func writeToModule(ctx context.Context, mod api.Module, content []byte) (uint32, error) {
+	results, err := mod.ExportedFunction(exportAlloc).Call(ctx, uint64(len(content)))
+	if err != nil {
+		return 0, fmt.Errorf("error calling %s: %s", exportAlloc, err)
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, content) {
+		return 0, fmt.Errorf("%s returned memory out of range", exportAlloc)
+	}
+	return ptr, nil
+}
+
+func readFromModule(mod api.Module, packed uint64) ([]byte, error) {
+	ptr, length := uint32(packed>>32), uint32(packed)
+	content, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		return nil, fmt.Errorf("memory out of range")
+	}
+	// the memory of the instance is released on close
+	return append([]byte{}, content...), nil
+}
+
+func getCallEnvironment(ctx context.Context) *callEnvironment {
+	if env, ok := ctx.Value(callEnvironmentKey{}).(*callEnvironment); ok {
+		return env
+	}
+	return &callEnvironment{httpClient: http.DefaultClient, logger: logr.Discard()}
+}
+
+func hostHTTPRequest(ctx context.Context, mod api.Module, stack []uint64) {
+	env := getCallEnvironment(ctx)
+	response := doHTTPRequest(ctx, env.httpClient, mod, stack[0], stack[1])
+
+	output, _ := json.Marshal(response)
+	ptr, err := writeToModule(ctx, mod, output)
+	if err != nil {
+		env.logger.Error(err, "error returning the http response to the wasm module")
+		stack[0] = 0
+		return
+	}
+	stack[0] = uint64(ptr)<<32 | uint64(len(output))
+}
+
+func doHTTPRequest(ctx context.Context, httpClient *http.Client, mod api.Module, ptr, length uint64) HTTPResponse {
+	input, ok := mod.Memory().Read(uint32(ptr), uint32(length))
+	if !ok {
+		return HTTPResponse{Error: "request out of memory range"}
+	}
+	request := HTTPRequest{}
+	if err := json.Unmarshal(input, &request); err != nil {
+		return HTTPResponse{Error: fmt.Sprintf("error parsing the request: %s", err)}
+	}
+	if request.Method == "" {
+		request.Method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, bytes.NewBufferString(request.Body))
+	if err != nil {
+		return HTTPResponse{Error: err.Error()}
+	}
+	for name, value := range request.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return HTTPResponse{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseSize))
+	if err != nil {
+		return HTTPResponse{Error: err.Error()}
+	}
+	headers := make(map[string]string, len(resp.Header))
+	for name := range resp.Header {
+		headers[name] = resp.Header.Get(name)
+	}
+	return HTTPResponse{StatusCode: resp.StatusCode, Headers: headers, Body: string(body)}
+}
+
+func hostLog(ctx context.Context, mod api.Module, stack []uint64) {
+	if message, ok := mod.Memory().Read(uint32(stack[0]), uint32(stack[1])); ok {
+		getCallEnvironment(ctx).logger.Info(string(message))
+	}
+}
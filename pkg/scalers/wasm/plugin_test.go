@@ -0,0 +1,184 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+// testModule assembles a module returning the given responses, keda_is_active performs the http request first
+func testModule(parseResponse, isActiveResponse, getMetricResponse string, httpRequest string) []byte {
+	const (
+		parseOffset    = 16
+		isActiveOffset = 1024
+		metricOffset   = 2048
+		requestOffset  = 3072
+		heapOffset     = 8192
+	)
+	packed := func(offset int, content string) []byte {
+		return append([]byte{0x42}, sleb128(int64(offset)<<32|int64(len(content)))...)
+	}
+
+	types := vec(
+		// (i32, i32) -> i64
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e},
+		// (i32) -> i32
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},
+	)
+	imports := vec(concat(name("keda"), name("http_request"), []byte{0x00, 0x00}))
+	functions := vec([]byte{0x01}, []byte{0x00}, []byte{0x00}, []byte{0x00})
+	memories := vec([]byte{0x00, 0x01})
+	globals := vec(concat([]byte{0x7f, 0x01, 0x41}, sleb128(heapOffset), []byte{0x0b}))
+	exports := vec(
+		concat(name("memory"), []byte{0x02, 0x00}),
+		concat(name(exportAlloc), []byte{0x00, 0x01}),
+		concat(name(exportParseMetadata), []byte{0x00, 0x02}),
+		concat(name(exportIsActive), []byte{0x00, 0x03}),
+		concat(name(exportGetMetric), []byte{0x00, 0x04}),
+	)
+	code := vec(
+		// bump allocator: return heap, heap += size
+		body([]byte{0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00}),
+		body(packed(parseOffset, parseResponse)),
+		body(concat(
+			[]byte{0x41}, sleb128(requestOffset), []byte{0x41}, sleb128(int64(len(httpRequest))),
+			[]byte{0x10, 0x00, 0x1a},
+			packed(isActiveOffset, isActiveResponse),
+		)),
+		body(packed(metricOffset, getMetricResponse)),
+	)
+	data := vec(
+		dataSegment(parseOffset, parseResponse),
+		dataSegment(isActiveOffset, isActiveResponse),
+		dataSegment(metricOffset, getMetricResponse),
+		dataSegment(requestOffset, httpRequest),
+	)
+
+	return concat(
+		[]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+		section(1, types), section(2, imports), section(3, functions), section(5, memories),
+		section(6, globals), section(7, exports), section(10, code), section(11, data),
+	)
+}
+
+func concat(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+func uleb128(v uint64) []byte {
+	var result []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		result = append(result, b)
+		if v == 0 {
+			return result
+		}
+	}
+}
+
+func sleb128(v int64) []byte {
+	var result []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(result, b)
+		}
+		result = append(result, b|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	return concat(uleb128(uint64(len(items))), concat(items...))
+}
+
+func name(s string) []byte {
+	return concat(uleb128(uint64(len(s))), []byte(s))
+}
+
+func section(id byte, content []byte) []byte {
+	return concat([]byte{id}, uleb128(uint64(len(content))), content)
+}
+
+func body(instructions []byte) []byte {
+	// no locals
+	content := concat([]byte{0x00}, instructions, []byte{0x0b})
+	return concat(uleb128(uint64(len(content))), content)
+}
+
+func dataSegment(offset int, content string) []byte {
+	return concat([]byte{0x00, 0x41}, sleb128(int64(offset)), []byte{0x0b}, name(content))
+}
+
+func TestPluginCalls(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/queue", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"length": 4}`)
+	}))
+	defer server.Close()
+
+	httpRequest, _ := json.Marshal(HTTPRequest{URL: server.URL + "/queue", Headers: map[string]string{"Authorization": "Bearer token"}})
+	module := testModule(`{"metricName":"queue","targetValue":5}`, `{"active":true}`, `{"value":12.5}`, string(httpRequest))
+
+	ctx := context.Background()
+	plugin, err := Compile(ctx, module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Close(ctx)
+
+	request := Request{Metadata: map[string]string{"queue": "jobs"}}
+	parsed, err := plugin.ParseMetadata(ctx, http.DefaultClient, logr.Discard(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, ParseMetadataResponse{MetricName: "queue", TargetValue: 5}, parsed)
+
+	active, err := plugin.IsActive(ctx, http.DefaultClient, logr.Discard(), request)
+	assert.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, 1, requests)
+
+	value, err := plugin.GetMetric(ctx, http.DefaultClient, logr.Discard(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, value)
+}
+
+func TestPluginErrors(t *testing.T) {
+	ctx := context.Background()
+	plugin, err := Compile(ctx, testModule(`{"error":"no queue given"}`, `{"active":`, `{}`, `{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Close(ctx)
+
+	_, err = plugin.ParseMetadata(ctx, http.DefaultClient, logr.Discard(), Request{})
+	assert.EqualError(t, err, "no queue given")
+
+	_, err = plugin.IsActive(ctx, http.DefaultClient, logr.Discard(), Request{})
+	assert.Error(t, err)
+}
+
+func TestCompileInvalidModule(t *testing.T) {
+	_, err := Compile(context.Background(), []byte("not wasm"))
+	assert.Error(t, err)
+
+	// a module without exports
+	_, err = Compile(context.Background(), []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00})
+	assert.Error(t, err)
+}
@@ -0,0 +1,301 @@
+package scalers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/wasm"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	// wasmCallTimeout bounds a call of the module, including the http requests it performs
+	wasmCallTimeout = 10 * time.Second
+)
+
+type wasmScaler struct {
+	metadata   *wasmMetadata
+	plugin     *wasm.Plugin
+	entry      *wasmPluginEntry
+	httpClient *http.Client
+	metricName string
+	target     float64
+}
+
+type wasmMetadata struct {
+	module           wasm.Reference
+	registryInsecure bool
+	credentials      *wasm.RegistryCredentials
+	// pluginMetadata and pluginAuthParams are passed to the module
+	pluginMetadata   map[string]string
+	pluginAuthParams map[string]string
+	scalerIndex      int
+}
+
+// wasmPluginCache shares the compiled modules between the scalers of a namespace pulling the same manifest with the
+// same registry credentials, a module is released when the last scaler using it is closed
+type wasmPluginCache struct {
+	sync.Mutex
+	entries map[string]*wasmPluginEntry
+}
+
+// wasmPluginEntry is loaded once by its first user, the other users wait for it to be ready
+type wasmPluginEntry struct {
+	key    string
+	ready  chan struct{}
+	plugin *wasm.Plugin
+	err    error
+	users  int
+}
+
+var wasmPlugins = &wasmPluginCache{entries: map[string]*wasmPluginEntry{}}
+
+var wasmLog = logf.Log.WithName("wasm_scaler")
+
+// NewWasmScaler creates a new scaler from a module distributed through an OCI registry
+func NewWasmScaler(ctx context.Context, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseWasmMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing wasm metadata: %s", err)
+	}
+
+	httpClient := createHTTPClient(config, config.GlobalHTTPTimeout, false)
+	entry, err := getWasmPlugin(ctx, httpClient, config.Namespace, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	scaler := &wasmScaler{
+		metadata:   meta,
+		plugin:     entry.plugin,
+		entry:      entry,
+		httpClient: httpClient,
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, wasmCallTimeout)
+	defer cancel()
+	parsed, err := scaler.plugin.ParseMetadata(callCtx, httpClient, wasmLog, scaler.request(""))
+	if err != nil {
+		wasmPlugins.release(ctx, entry)
+		return nil, fmt.Errorf("error parsing wasm metadata: %s", err)
+	}
+	if parsed.TargetValue <= 0 {
+		wasmPlugins.release(ctx, entry)
+		return nil, fmt.Errorf("the wasm module %s returned the targetValue %v, it must be greater than 0", meta.module, parsed.TargetValue)
+	}
+	name := parsed.MetricName
+	if name == "" {
+		name = path.Base(meta.module.Repository)
+	}
+	scaler.metricName = kedautil.NormalizeString(fmt.Sprintf("wasm-%s", name))
+	scaler.target = parsed.TargetValue
+
+	return scaler, nil
+}
+
+func parseWasmMetadata(config *ScalerConfig) (*wasmMetadata, error) {
+	meta := wasmMetadata{
+		pluginMetadata:   map[string]string{},
+		pluginAuthParams: map[string]string{},
+	}
+
+	if val, ok := config.TriggerMetadata["module"]; ok && val != "" {
+		ref, err := wasm.ParseReference(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing module: %s", err)
+		}
+		meta.module = ref
+	} else {
+		return nil, fmt.Errorf("no module given")
+	}
+
+	if val, ok := config.TriggerMetadata["registryInsecure"]; ok && val != "" {
+		registryInsecure, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing registryInsecure: %s", err)
+		}
+		meta.registryInsecure = registryInsecure
+	}
+
+	username, password := config.AuthParams["registryUsername"], config.AuthParams["registryPassword"]
+	if username != "" || password != "" {
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("both registryUsername and registryPassword must be given")
+		}
+		meta.credentials = &wasm.RegistryCredentials{Username: username, Password: password}
+	}
+
+	for key, value := range config.TriggerMetadata {
+		if key != "module" && key != "registryInsecure" {
+			meta.pluginMetadata[key] = value
+		}
+	}
+	for key, value := range config.AuthParams {
+		if key != "registryUsername" && key != "registryPassword" {
+			meta.pluginAuthParams[key] = value
+		}
+	}
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// getWasmPlugin resolves the tag of the module with the credentials of the trigger, so a tag moved to another
+// manifest is pulled again, and returns the shared entry of the manifest in the namespace
+func getWasmPlugin(ctx context.Context, httpClient *http.Client, namespace string, meta *wasmMetadata) (*wasmPluginEntry, error) {
+	ref, err := wasm.Resolve(ctx, httpClient, meta.module, meta.credentials, meta.registryInsecure)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving wasm module: %s", err)
+	}
+
+	return wasmPlugins.acquire(ctx, wasmPluginKey(namespace, meta.credentials, ref), func() (*wasm.Plugin, error) {
+		module, err := wasm.Pull(ctx, httpClient, ref, meta.credentials, meta.registryInsecure)
+		if err != nil {
+			return nil, fmt.Errorf("error pulling wasm module: %s", err)
+		}
+		plugin, err := wasm.Compile(ctx, module)
+		if err != nil {
+			return nil, fmt.Errorf("error loading wasm module %s: %s", meta.module, err)
+		}
+		return plugin, nil
+	})
+}
+
+// wasmPluginKey identifies the manifest pulled by a namespace, the credentials are hashed
+func wasmPluginKey(namespace string, credentials *wasm.RegistryCredentials, ref wasm.Reference) string {
+	var user string
+	if credentials != nil {
+		sum := sha256.Sum256([]byte(credentials.Username + "\x00" + credentials.Password))
+		user = hex.EncodeToString(sum[:])
+	}
+	return namespace + "/" + user + "/" + ref.Registry + "/" + ref.Repository + "@" + ref.Digest
+}
+
+// acquire returns the entry of the key, the first user loads it without holding the lock of the cache
+func (c *wasmPluginCache) acquire(ctx context.Context, key string, load func() (*wasm.Plugin, error)) (*wasmPluginEntry, error) {
+	c.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &wasmPluginEntry{key: key, ready: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	entry.users++
+	c.Unlock()
+
+	if !ok {
+		entry.plugin, entry.err = load()
+		if entry.err != nil {
+			// the next users load it again
+			c.Lock()
+			if c.entries[key] == entry {
+				delete(c.entries, key)
+			}
+			c.Unlock()
+		}
+		close(entry.ready)
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		c.release(ctx, entry)
+		return nil, ctx.Err()
+	}
+	if entry.err != nil {
+		c.release(ctx, entry)
+		return nil, entry.err
+	}
+	return entry, nil
+}
+
+// release drops a user of the entry, the module is closed and evicted with its last user
+func (c *wasmPluginCache) release(ctx context.Context, entry *wasmPluginEntry) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry.users--
+	if entry.users > 0 {
+		return
+	}
+	if c.entries[entry.key] == entry {
+		delete(c.entries, entry.key)
+	}
+	if entry.plugin != nil {
+		if err := entry.plugin.Close(ctx); err != nil {
+			wasmLog.Error(err, "error closing wasm module", "key", entry.key)
+		}
+	}
+}
+
+func (s *wasmScaler) request(metricName string) wasm.Request {
+	return wasm.Request{
+		Metadata:   s.metadata.pluginMetadata,
+		AuthParams: s.metadata.pluginAuthParams,
+		MetricName: metricName,
+	}
+}
+
+// IsActive asks the module whether the workload is active
+func (s *wasmScaler) IsActive(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, wasmCallTimeout)
+	defer cancel()
+
+	active, err := s.plugin.IsActive(ctx, s.httpClient, wasmLog, s.request(""))
+	if err != nil {
+		wasmLog.Error(err, "error calling keda_is_active", "module", s.metadata.module.String())
+		return false, err
+	}
+	return active, nil
+}
+
+// Close releases the compiled module, it is closed when no other scaler uses it
+func (s *wasmScaler) Close(ctx context.Context) error {
+	closeIdleConnections(s.httpClient)
+	if s.entry != nil {
+		wasmPlugins.release(ctx, s.entry)
+		s.entry = nil
+	}
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *wasmScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metricName),
+		},
+		Target: GetMetricTargetMili(s.target),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value returned by the module
+func (s *wasmScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	ctx, cancel := context.WithTimeout(ctx, wasmCallTimeout)
+	defer cancel()
+
+	value, err := s.plugin.GetMetric(ctx, s.httpClient, wasmLog, s.request(metricName))
+	if err != nil {
+		wasmLog.Error(err, "error calling keda_get_metric", "module", s.metadata.module.String())
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := GenerateMetricInMili(metricName, value)
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
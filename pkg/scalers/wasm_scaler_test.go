@@ -0,0 +1,196 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/wasm"
+)
+
+type parseWasmMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type wasmMetricIdentifier struct {
+	scalerIndex int
+	metricName  string
+	name        string
+}
+
+var testWasmMetadata = []parseWasmMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"module": "ghcr.io/org/queue-scaler:1.0", "queue": "jobs"}, map[string]string{"token": "secret"}, false},
+	// insecure registry
+	{map[string]string{"module": "registry.local:5000/queue-scaler", "registryInsecure": "true"}, map[string]string{}, false},
+	// malformed registryInsecure
+	{map[string]string{"module": "registry.local:5000/queue-scaler", "registryInsecure": "yes please"}, map[string]string{}, true},
+	// malformed module
+	{map[string]string{"module": "ghcr.io/org/queue-scaler@md5:abc"}, map[string]string{}, true},
+	// registry credentials
+	{map[string]string{"module": "ghcr.io/org/queue-scaler:1.0"}, map[string]string{"registryUsername": "user", "registryPassword": "secret"}, false},
+	// registry username without password
+	{map[string]string{"module": "ghcr.io/org/queue-scaler:1.0"}, map[string]string{"registryUsername": "user"}, true},
+}
+
+var wasmMetricIdentifiers = []wasmMetricIdentifier{
+	{0, "wasm-queue", "s0-wasm-queue"},
+	{1, "wasm-queue", "s1-wasm-queue"},
+}
+
+func TestWasmParseMetadata(t *testing.T) {
+	for _, testData := range testWasmMetadata {
+		_, err := parseWasmMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestWasmPluginParameters(t *testing.T) {
+	meta, err := parseWasmMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"module": "ghcr.io/org/queue-scaler:1.0", "registryInsecure": "false", "queue": "jobs"},
+		AuthParams:      map[string]string{"registryUsername": "user", "registryPassword": "secret", "token": "token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the registry parameters are not passed to the module
+	if len(meta.pluginMetadata) != 1 || meta.pluginMetadata["queue"] != "jobs" {
+		t.Error("Wrong metadata passed to the module:", meta.pluginMetadata)
+	}
+	if len(meta.pluginAuthParams) != 1 || meta.pluginAuthParams["token"] != "token" {
+		t.Error("Wrong authentication parameters passed to the module:", meta.pluginAuthParams)
+	}
+	if meta.credentials == nil || *meta.credentials != (wasm.RegistryCredentials{Username: "user", Password: "secret"}) {
+		t.Error("Wrong registry credentials:", meta.credentials)
+	}
+}
+
+func TestWasmGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range wasmMetricIdentifiers {
+		meta, err := parseWasmMetadata(&ScalerConfig{TriggerMetadata: testWasmMetadata[1].metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockWasmScaler := wasmScaler{metadata: meta, metricName: testData.metricName, target: 2.5}
+
+		metricSpec := mockWasmScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+		if target := metricSpec[0].External.Target.AverageValue.MilliValue(); target != 2500 {
+			t.Error("Wrong target value:", target)
+		}
+	}
+}
+
+func TestWasmPluginKey(t *testing.T) {
+	ref := wasm.Reference{Registry: "ghcr.io", Repository: "org/queue-scaler", Tag: "1.0", Digest: "sha256:aaaa"}
+	credentials := &wasm.RegistryCredentials{Username: "user", Password: "secret"}
+	key := wasmPluginKey("team-a", credentials, ref)
+
+	if key != wasmPluginKey("team-a", &wasm.RegistryCredentials{Username: "user", Password: "secret"}, ref) {
+		t.Error("Expected the same key for the same namespace, credentials and digest")
+	}
+	moved := ref
+	moved.Digest = "sha256:bbbb"
+	for _, other := range []string{
+		wasmPluginKey("team-b", credentials, ref),
+		wasmPluginKey("team-a", nil, ref),
+		wasmPluginKey("team-a", &wasm.RegistryCredentials{Username: "user", Password: "other"}, ref),
+		wasmPluginKey("team-a", credentials, moved),
+	} {
+		if other == key {
+			t.Error("Expected another key than", key)
+		}
+	}
+}
+
+func TestWasmPluginCache(t *testing.T) {
+	cache := &wasmPluginCache{entries: map[string]*wasmPluginEntry{}}
+	ctx := context.Background()
+	loads := map[string]int{}
+	loader := func(key string, err error) func() (*wasm.Plugin, error) {
+		return func() (*wasm.Plugin, error) {
+			loads[key]++
+			return nil, err
+		}
+	}
+
+	first, err := cache.acquire(ctx, "a", loader("a", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cache.acquire(ctx, "a", loader("a", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || loads["a"] != 1 {
+		t.Error("Expected the module to be loaded once, got", loads["a"])
+	}
+
+	// a failed load isn't cached
+	if _, err := cache.acquire(ctx, "b", loader("b", errors.New("pull failed"))); err == nil {
+		t.Error("Expected the load error")
+	}
+	if _, ok := cache.entries["b"]; ok {
+		t.Error("Expected the failed entry to be evicted")
+	}
+	if _, err := cache.acquire(ctx, "b", loader("b", nil)); err != nil || loads["b"] != 2 {
+		t.Error("Expected the module to be loaded again, got", loads["b"], err)
+	}
+
+	cache.release(ctx, first)
+	if _, ok := cache.entries["a"]; !ok {
+		t.Error("Expected the entry to be kept while a scaler uses it")
+	}
+	cache.release(ctx, second)
+	if _, ok := cache.entries["a"]; ok {
+		t.Error("Expected the entry to be evicted with its last user")
+	}
+}
+
+func TestWasmPluginCacheLoadsWithoutLock(t *testing.T) {
+	cache := &wasmPluginCache{entries: map[string]*wasmPluginEntry{}}
+	ctx := context.Background()
+	loading, unblock := make(chan struct{}), make(chan struct{})
+	done := make(chan *wasmPluginEntry)
+	go func() {
+		entry, _ := cache.acquire(ctx, "slow", func() (*wasm.Plugin, error) {
+			close(loading)
+			<-unblock
+			return nil, nil
+		})
+		done <- entry
+	}()
+	<-loading
+
+	// the other modules are loaded while the slow one is pulled
+	if _, err := cache.acquire(ctx, "fast", func() (*wasm.Plugin, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	// the waiters of the slow module give up with their context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := cache.acquire(canceled, "slow", func() (*wasm.Plugin, error) {
+		t.Error("Expected the module to be loaded once")
+		return nil, nil
+	}); err == nil {
+		t.Error("Expected the context error")
+	}
+
+	close(unblock)
+	if entry := <-done; entry == nil || entry.users != 1 {
+		t.Error("Expected the loaded entry to have one user, got", entry)
+	}
+}
@@ -0,0 +1,197 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// webhookEvents are the events the generic webhooks received by the notification server set
+var webhookEvents = notification.DefaultWebhooks
+
+type webhookScaler struct {
+	metadata     *webhookMetadata
+	subscription *notification.WebhookSubscription
+}
+
+type webhookMetadata struct {
+	// Trigger is the name of the trigger in the webhook path, namespaces/<namespace>/<trigger>
+	Trigger string `keda:"name=trigger"`
+	// Secret is the key of the HMAC-SHA256 signatures of the webhooks, or their token when they aren't signed
+	Secret string `keda:"name=secret, order=authParams"`
+	// EventType restricts the trigger to the CloudEvents of this type, any webhook activates it if it isn't set
+	EventType string `keda:"name=eventType, optional"`
+	// ValueLocation is the gjson path of the value in the webhook body or in the data of the CloudEvent, the value
+	// is 1 while the last event is active if it isn't set
+	ValueLocation string `keda:"name=valueLocation, optional"`
+	// ActiveWindow is how long the last event keeps the trigger active
+	ActiveWindow time.Duration `keda:"name=activeWindow, default=5m"`
+	TargetValue  float64       `keda:"name=targetValue, default=1"`
+
+	namespace   string
+	scalerIndex int
+}
+
+// Validate checks the trigger can be set from the webhook path
+func (m *webhookMetadata) Validate() error {
+	if strings.Contains(m.Trigger, "/") {
+		return fmt.Errorf("trigger must not contain a /")
+	}
+	if m.ValueLocation != "" {
+		if err := validateGJSONPath(m.ValueLocation); err != nil {
+			return fmt.Errorf("invalid valueLocation %q: %s", m.ValueLocation, err)
+		}
+	}
+	if m.ActiveWindow <= 0 {
+		return fmt.Errorf("activeWindow must be greater than 0")
+	}
+	if m.TargetValue <= 0 {
+		return fmt.Errorf("targetValue must be greater than 0")
+	}
+	return nil
+}
+
+var webhookLog = logf.Log.WithName("webhook_scaler")
+
+// NewWebhookScaler creates a new push scaler for the triggers set by generic webhooks, plain JSON or CloudEvents,
+// the webhooks are received by the notification server of the operator and activate the scale target as soon as
+// they arrive, for the systems which can call webhooks but can't be polled
+func NewWebhookScaler(config *ScalerConfig) (PushScaler, error) {
+	meta, err := parseWebhookMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing webhook metadata: %s", err)
+	}
+
+	// the subscription accepts the webhooks of the trigger from now on, until the scaler is closed
+	return &webhookScaler{
+		metadata:     meta,
+		subscription: webhookEvents.Subscribe(meta.namespace, meta.Trigger, meta.Secret, meta.EventType, meta.ActiveWindow),
+	}, nil
+}
+
+func parseWebhookMetadata(config *ScalerConfig) (*webhookMetadata, error) {
+	meta := &webhookMetadata{}
+	if err := config.TypedConfig(meta); err != nil {
+		return nil, err
+	}
+	meta.namespace = config.Namespace
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// getValue returns the value of the last event of the trigger if it is still active at now, and when it expires
+func (s *webhookScaler) getValue(ctx context.Context, now time.Time) (float64, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	event, ok := s.subscription.Last(now)
+	if !ok {
+		return 0, time.Time{}, nil
+	}
+	expiresAt := event.ReceivedAt.Add(s.metadata.ActiveWindow)
+	if s.metadata.ValueLocation == "" {
+		return 1, expiresAt, nil
+	}
+	value, err := GetValueFromResponse(event.Data, s.metadata.ValueLocation)
+	if err != nil {
+		webhookLog.V(1).Info("Skipping event without a valid value", "valueLocation", s.metadata.ValueLocation, "type", event.Type, "id", event.ID)
+		return 0, time.Time{}, nil
+	}
+	return value.AsApproximateFloat64(), expiresAt, nil
+}
+
+// IsActive returns true if the last event of the trigger is active and has a value
+func (s *webhookScaler) IsActive(ctx context.Context) (bool, error) {
+	value, _, err := s.getValue(ctx, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return value > 0, nil
+}
+
+// Run notifies the activity of the trigger on each webhook and once the last event expires, until ctx is done
+func (s *webhookScaler) Run(ctx context.Context, active chan<- bool) {
+	defer close(active)
+	notify := s.subscription.Notify()
+
+	expiry := time.NewTimer(0)
+	if !expiry.Stop() {
+		<-expiry.C
+	}
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-notify:
+		case <-expiry.C:
+		}
+
+		now := time.Now()
+		value, expiresAt, err := s.getValue(ctx, now)
+		if err != nil {
+			return
+		}
+		if !expiry.Stop() {
+			select {
+			case <-expiry.C:
+			default:
+			}
+		}
+		if !expiresAt.IsZero() {
+			expiry.Reset(expiresAt.Sub(now))
+		}
+		select {
+		case active <- value > 0:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close unsubscribes the trigger from its webhooks, their last event is dropped
+func (s *webhookScaler) Close(context.Context) error {
+	s.subscription.Unsubscribe()
+	return nil
+}
+
+func (s *webhookScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	metricName := kedautil.NormalizeString(fmt.Sprintf("webhook-%s", s.metadata.Trigger))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: GetMetricTargetMili(s.metadata.TargetValue),
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value of the last event of the trigger
+func (s *webhookScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, _, err := s.getValue(ctx, time.Now())
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *newMilliQuantity(value),
+		Timestamp:  metav1.Now(),
+	}
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
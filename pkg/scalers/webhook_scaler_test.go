@@ -0,0 +1,97 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/notification"
+)
+
+type parseWebhookMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+var testWebhookMetadata = []parseWebhookMetadataTestData{
+	// properly formed
+	{map[string]string{"trigger": "orders"}, map[string]string{"secret": "secret"}, false},
+	// event type, value location and window
+	{map[string]string{"trigger": "orders", "eventType": "shop.order.created", "valueLocation": "backlog.size", "activeWindow": "1m", "targetValue": "5"}, map[string]string{"secret": "secret"}, false},
+	// missing trigger
+	{map[string]string{}, map[string]string{"secret": "secret"}, true},
+	// missing secret
+	{map[string]string{"trigger": "orders"}, map[string]string{}, true},
+	// trigger with a slash
+	{map[string]string{"trigger": "shop/orders"}, map[string]string{"secret": "secret"}, true},
+	// invalid value location
+	{map[string]string{"trigger": "orders", "valueLocation": "backlog..size"}, map[string]string{"secret": "secret"}, true},
+	// invalid window
+	{map[string]string{"trigger": "orders", "activeWindow": "0s"}, map[string]string{"secret": "secret"}, true},
+	// invalid target value
+	{map[string]string{"trigger": "orders", "targetValue": "0"}, map[string]string{"secret": "secret"}, true},
+}
+
+func TestParseWebhookMetadata(t *testing.T) {
+	for i, testData := range testWebhookMetadata {
+		_, err := parseWebhookMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, Namespace: "shop"})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error for unit test #%v: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for unit test #%v", i)
+		}
+	}
+}
+
+func TestWebhookScalerRun(t *testing.T) {
+	webhooks := notification.NewWebhooks()
+	webhookEvents = webhooks
+	defer func() { webhookEvents = notification.DefaultWebhooks }()
+
+	scaler, err := NewWebhookScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"trigger": "orders", "eventType": "shop.order.created", "valueLocation": "backlog.size", "activeWindow": "200ms"},
+		AuthParams:      map[string]string{"secret": "secret"},
+		Namespace:       "shop",
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	active := make(chan bool)
+	go scaler.Run(ctx, active)
+
+	update := func(event notification.Event) bool {
+		event.ReceivedAt = time.Now()
+		return webhooks.Update("shop", "orders", nil, "", "secret", event)
+	}
+	created := notification.Event{Type: "shop.order.created", ID: "1", Data: []byte(`{"backlog": {"size": 12}}`)}
+	assert.Eventually(t, func() bool { return update(created) }, time.Second, time.Millisecond)
+	assert.True(t, <-active)
+
+	metrics, err := scaler.GetMetrics(ctx, "s0-webhook-orders", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), metrics[0].Value.Value())
+
+	// the events of other types are ignored
+	assert.True(t, update(notification.Event{Type: "shop.order.cancelled", ID: "2", Data: []byte(`{"backlog": {"size": 0}}`)}))
+	metrics, err = scaler.GetMetrics(ctx, "s0-webhook-orders", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), metrics[0].Value.Value())
+
+	// the trigger is deactivated once the last event expires
+	assert.False(t, <-active)
+	isActive, err := scaler.IsActive(ctx)
+	assert.NoError(t, err)
+	assert.False(t, isActive)
+
+	cancel()
+	_, open := <-active
+	assert.False(t, open, "the active channel must be closed once done")
+
+	// the webhooks aren't accepted once the scaler is closed
+	assert.NoError(t, scaler.Close(context.Background()))
+	assert.False(t, update(created))
+}
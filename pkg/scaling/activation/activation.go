@@ -0,0 +1,239 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package activation implements the activation strategies of ScaledObjects,
+// they decide which combination of active triggers activates the scale target.
+package activation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// StrategyAny activates the scale target if any trigger is active, it is the default
+	StrategyAny = "any"
+
+	// StrategyAll activates the scale target only if all triggers are active
+	StrategyAll = "all"
+)
+
+// Strategy decides whether the scale target is active based on the state of its triggers
+type Strategy struct {
+	all        bool
+	expression node
+	names      []string
+}
+
+// Parse parses the activation strategy: any, all or a boolean expression over trigger names
+// with && (and), || (or), ! (not) and parentheses, eg. `queue && (businessHours || manual)`
+func Parse(strategy string) (*Strategy, error) {
+	switch strings.TrimSpace(strategy) {
+	case "", StrategyAny:
+		return &Strategy{}, nil
+	case StrategyAll:
+		return &Strategy{all: true}, nil
+	}
+
+	p := &parser{input: strategy}
+	p.next()
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid activationStrategy %q: %s", strategy, err)
+	}
+	if p.token != "" {
+		return nil, fmt.Errorf("invalid activationStrategy %q: unexpected %q", strategy, p.token)
+	}
+	return &Strategy{expression: root, names: p.names}, nil
+}
+
+// TriggerNames returns the trigger names referenced by the expression
+func (s *Strategy) TriggerNames() []string {
+	return s.names
+}
+
+// IsAny returns true if the scale target is active as soon as any trigger is active
+func (s *Strategy) IsAny() bool {
+	return !s.all && s.expression == nil
+}
+
+// IsActive returns whether the scale target is active, active holds the state of every trigger by its name
+// and total is the number of defined triggers (some of them might have failed to build)
+func (s *Strategy) IsActive(active []bool, names []string, total int) bool {
+	switch {
+	case s.expression != nil:
+		state := make(map[string]bool, len(names))
+		for i, name := range names {
+			if name != "" {
+				state[name] = active[i]
+			}
+		}
+		return s.expression.eval(state)
+	case s.all:
+		if len(active) == 0 || len(active) < total {
+			return false
+		}
+		for _, a := range active {
+			if !a {
+				return false
+			}
+		}
+		return true
+	default:
+		for _, a := range active {
+			if a {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type node interface {
+	eval(state map[string]bool) bool
+}
+
+type triggerNode string
+
+func (n triggerNode) eval(state map[string]bool) bool {
+	return state[string(n)]
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n notNode) eval(state map[string]bool) bool {
+	return !n.operand.eval(state)
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(state map[string]bool) bool {
+	return n.left.eval(state) && n.right.eval(state)
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(state map[string]bool) bool {
+	return n.left.eval(state) || n.right.eval(state)
+}
+
+type parser struct {
+	input string
+	pos   int
+	token string
+	names []string
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.'
+}
+
+func (p *parser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		p.token = ""
+		return
+	}
+
+	start := p.pos
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "&&"), strings.HasPrefix(p.input[p.pos:], "||"):
+		p.pos += 2
+	case isNameRune(rune(p.input[p.pos])):
+		for p.pos < len(p.input) && isNameRune(rune(p.input[p.pos])) {
+			p.pos++
+		}
+	default:
+		p.pos++
+	}
+	p.token = p.input[start:p.pos]
+}
+
+// parseOr parses: and ('||' and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.token == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary ('&&' unary)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.token == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: '!' unary | '(' or ')' | name
+func (p *parser) parseUnary() (node, error) {
+	switch {
+	case p.token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case p.token == "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	case p.token == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.token != ")" {
+			return nil, fmt.Errorf("expected )")
+		}
+		p.next()
+		return inner, nil
+	case isNameRune(rune(p.token[0])):
+		name := p.token
+		p.names = append(p.names, name)
+		p.next()
+		return triggerNode(name), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", p.token)
+	}
+}
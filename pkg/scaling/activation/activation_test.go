@@ -0,0 +1,64 @@
+package activation
+
+import (
+	"reflect"
+	"testing"
+)
+
+type isActiveTestData struct {
+	strategy string
+	active   []bool
+	total    int
+	expected bool
+}
+
+var names = []string{"queue", "business-hours", "manual"}
+
+var isActiveTestDataset = []isActiveTestData{
+	{"", []bool{false, true, false}, 3, true},
+	{"any", []bool{false, false, false}, 3, false},
+	{"all", []bool{true, true, true}, 3, true},
+	{"all", []bool{true, false, true}, 3, false},
+	// one of the triggers failed to build
+	{"all", []bool{true, true, true}, 4, false},
+	{"queue && business-hours", []bool{true, true, false}, 3, true},
+	{"queue && business-hours", []bool{true, false, false}, 3, false},
+	{"queue && (business-hours || manual)", []bool{true, false, true}, 3, true},
+	{"queue && !manual", []bool{true, false, true}, 3, false},
+	{"!queue || manual", []bool{false, false, false}, 3, true},
+	{"unknown || queue", []bool{true, false, false}, 3, true},
+}
+
+func TestIsActive(t *testing.T) {
+	for _, testData := range isActiveTestDataset {
+		strategy, err := Parse(testData.strategy)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", testData.strategy, err)
+			continue
+		}
+		if active := strategy.IsActive(testData.active, names, testData.total); active != testData.expected {
+			t.Errorf("%q with %v: expected %v, got %v", testData.strategy, testData.active, testData.expected, active)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, strategy := range []string{"queue &&", "(queue", "queue manual", "queue & manual", "&& queue"} {
+		if _, err := Parse(strategy); err == nil {
+			t.Errorf("%q: expected error", strategy)
+		}
+	}
+}
+
+func TestTriggerNames(t *testing.T) {
+	strategy, err := Parse("queue && (business-hours || !manual)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"queue", "business-hours", "manual"}; !reflect.DeepEqual(strategy.TriggerNames(), expected) {
+		t.Errorf("expected %v, got %v", expected, strategy.TriggerNames())
+	}
+	if strategy.IsAny() {
+		t.Error("expected expression not to be any")
+	}
+}
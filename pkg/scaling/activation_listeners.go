@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// activationListeners relays the notifications of the scalers listening to the arrival of messages on their broker
+// to the wake up channel of a scale loop, the scale loop keeps polling if a listener fails
+type activationListeners struct {
+	listeners []scalers.ActivationListener
+	cancel    context.CancelFunc
+}
+
+// bindActivationListeners binds the listeners of the scale loop to the current Scalers of the object, the listeners
+// of the Scalers replaced since the last call, by a rebuild of the cache or a refresh of a Scaler, are restarted
+func (h *scaleHandler) bindActivationListeners(ctx context.Context, logger logr.Logger, scalableObject interface{}, listeners *activationListeners, wake chan<- struct{}) {
+	cache, err := h.GetScalersCache(ctx, scalableObject)
+	if err != nil {
+		// the error is reported by the check, the listeners are bound once the scalers are built
+		logger.V(1).Info("Error getting scalers, not binding the activation listeners", "error", err)
+		return
+	}
+	listeners.bind(ctx, logger, cache.GetActivationListeners(), wake)
+}
+
+// bind starts the listeners unless they are already running, the previous listeners are stopped
+func (a *activationListeners) bind(ctx context.Context, logger logr.Logger, listeners []scalers.ActivationListener, wake chan<- struct{}) {
+	if sameActivationListeners(a.listeners, listeners) {
+		return
+	}
+	a.stop()
+	a.listeners = listeners
+	if len(listeners) == 0 {
+		return
+	}
+
+	ctx, a.cancel = context.WithCancel(ctx)
+	for _, l := range listeners {
+		notify := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-notify:
+					select {
+					case wake <- struct{}{}:
+					default:
+						// a wake up is already pending
+					}
+				}
+			}
+		}()
+		go func(l scalers.ActivationListener) {
+			if err := l.ListenActivation(ctx, notify); err != nil {
+				logger.Error(err, "Error listening to activation notifications, falling back to polling")
+			}
+		}(l)
+	}
+}
+
+// stop stops the running listeners
+func (a *activationListeners) stop() {
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.listeners = nil
+}
+
+func sameActivationListeners(a, b []scalers.ActivationListener) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
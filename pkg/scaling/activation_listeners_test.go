@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// fakeActivationListener notifies once when it starts listening and counts its running listens
+type fakeActivationListener struct {
+	*mock_scalers.MockScaler
+	running int32
+}
+
+func (l *fakeActivationListener) ListenActivation(ctx context.Context, notify chan<- struct{}) error {
+	atomic.AddInt32(&l.running, 1)
+	defer atomic.AddInt32(&l.running, -1)
+	notify <- struct{}{}
+	<-ctx.Done()
+	return nil
+}
+
+func TestActivationListenersRestartWithTheScalers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	logger := logf.Log.WithName("test")
+	wake := make(chan struct{}, 1)
+	first := &fakeActivationListener{MockScaler: mock_scalers.NewMockScaler(ctrl)}
+	listeners := &activationListeners{}
+	defer listeners.stop()
+
+	listeners.bind(context.Background(), logger, []scalers.ActivationListener{first}, wake)
+	assert.Eventually(t, func() bool { return len(wake) == 1 }, 5*time.Second, 10*time.Millisecond)
+	<-wake
+
+	// the listener of the same scaler keeps running
+	listeners.bind(context.Background(), logger, []scalers.ActivationListener{first}, wake)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&first.running))
+	assert.Len(t, wake, 0)
+
+	// the listener of a refreshed scaler replaces the one of the closed scaler
+	refreshed := &fakeActivationListener{MockScaler: mock_scalers.NewMockScaler(ctrl)}
+	listeners.bind(context.Background(), logger, []scalers.ActivationListener{refreshed}, wake)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&first.running) == 0 && atomic.LoadInt32(&refreshed.running) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return len(wake) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	listeners.stop()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&refreshed.running) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
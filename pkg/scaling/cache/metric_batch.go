@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// maxMetricBatchAge is how long the values fetched at once by a MultiMetricScaler are served to its other metrics,
+// the HPA queries the metrics of a ScaledObject within its sync so they don't need to last longer
+const maxMetricBatchAge = 5 * time.Second
+
+// MetricBatch shares the values fetched in one call by a MultiMetricScaler between its metrics,
+// each value is served once so the next query of a metric fetches all of them again
+type MetricBatch struct {
+	lock sync.Mutex
+	// batches holds the values not served yet by metric selector
+	batches map[string]*metricBatchValues
+	now     func() time.Time
+}
+
+type metricBatchValues struct {
+	metrics map[string][]external_metrics.ExternalMetricValue
+	time    time.Time
+}
+
+// NewMetricBatch creates a MetricBatch without any values
+func NewMetricBatch() *MetricBatch {
+	return &MetricBatch{
+		batches: map[string]*metricBatchValues{},
+		now:     time.Now,
+	}
+}
+
+// get returns the values of metricName, from the last call of the scaler when they have not been served yet
+func (b *MetricBatch) get(ctx context.Context, scaler scalers.MultiMetricScaler, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := ""
+	if metricSelector != nil {
+		key = metricSelector.String()
+	}
+	name := strings.ToLower(metricName)
+	if batch, found := b.batches[key]; found && b.now().Sub(batch.time) < maxMetricBatchAge {
+		if metrics, found := batch.metrics[name]; found {
+			delete(batch.metrics, name)
+			return metrics, nil
+		}
+	}
+
+	all, err := scaler.GetAllMetrics(ctx, metricSelector)
+	if err != nil {
+		delete(b.batches, key)
+		return nil, err
+	}
+
+	batch := &metricBatchValues{metrics: map[string][]external_metrics.ExternalMetricValue{}, time: b.now()}
+	for _, metric := range all {
+		metricKey := strings.ToLower(metric.MetricName)
+		batch.metrics[metricKey] = append(batch.metrics[metricKey], metric)
+	}
+	b.batches[key] = batch
+
+	metrics, found := batch.metrics[name]
+	if !found {
+		return nil, fmt.Errorf("metric %s not returned by the scaler", metricName)
+	}
+	delete(batch.metrics, name)
+	return metrics, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+type fakeMultiMetricScaler struct {
+	scalers.Scaler
+	calls int
+	err   error
+}
+
+func (s *fakeMultiMetricScaler) GetAllMetrics(context.Context, labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []external_metrics.ExternalMetricValue{
+		{MetricName: "s0-backlog", Value: *resource.NewQuantity(int64(10*s.calls), resource.DecimalSI)},
+		{MetricName: "s0-backlog-1", Value: *resource.NewQuantity(int64(300*s.calls), resource.DecimalSI)},
+	}, nil
+}
+
+func TestMetricBatchServesEachValueOnce(t *testing.T) {
+	scaler := &fakeMultiMetricScaler{}
+	cache := ScalersCache{
+		Scalers: []ScalerBuilder{{Scaler: scaler, Batch: NewMetricBatch()}},
+		Logger:  logr.DiscardLogger{},
+	}
+	ctx := context.Background()
+
+	metrics, err := cache.GetMetricsForScaler(ctx, 0, "s0-backlog", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), metrics[0].Value.Value())
+	metrics, err = cache.GetMetricsForScaler(ctx, 0, "s0-backlog-1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), metrics[0].Value.Value())
+	assert.Equal(t, 1, scaler.calls)
+
+	// the value has been served, the next query fetches all the metrics again
+	metrics, err = cache.GetMetricsForScaler(ctx, 0, "s0-backlog", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), metrics[0].Value.Value())
+	assert.Equal(t, 2, scaler.calls)
+
+	_, err = cache.Scalers[0].Batch.get(ctx, scaler, "s0-unknown", nil)
+	assert.Error(t, err)
+}
+
+func TestMetricBatchExpires(t *testing.T) {
+	scaler := &fakeMultiMetricScaler{}
+	batch := NewMetricBatch()
+	now := time.Now()
+	batch.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := batch.get(ctx, scaler, "s0-backlog", nil)
+	assert.NoError(t, err)
+	now = now.Add(maxMetricBatchAge)
+	metrics, err := batch.get(ctx, scaler, "s0-backlog-1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(600), metrics[0].Value.Value())
+	assert.Equal(t, 2, scaler.calls)
+
+	// the batches are kept by metric selector
+	_, err = batch.get(ctx, scaler, "s0-backlog", labels.SelectorFromSet(labels.Set{"queue": "orders"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, scaler.calls)
+	_, err = batch.get(ctx, scaler, "s0-backlog", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, scaler.calls)
+}
+
+func TestMetricBatchDoesntKeepErrors(t *testing.T) {
+	scaler := &fakeMultiMetricScaler{err: errors.New("backend unavailable")}
+	batch := NewMetricBatch()
+
+	_, err := batch.get(context.Background(), scaler, "s0-backlog", nil)
+	assert.Error(t, err)
+	_, err = batch.get(context.Background(), scaler, "s0-backlog-1", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 2, scaler.calls)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+// minRateInterval is the minimal time between two samples used to compute a rate,
+// requests in a shorter interval get the last computed rate to avoid noisy values
+const minRateInterval = time.Second
+
+// RateTracker converts the metric values of a scaler to their per-second rate of change,
+// it is used by triggers with metricMode rate
+type RateTracker struct {
+	lock    sync.Mutex
+	samples map[string]rateSample
+	now     func() time.Time
+}
+
+type rateSample struct {
+	value float64
+	time  time.Time
+	rate  float64
+}
+
+// NewRateTracker creates a RateTracker without any samples
+func NewRateTracker() *RateTracker {
+	return &RateTracker{
+		samples: map[string]rateSample{},
+		now:     time.Now,
+	}
+}
+
+// apply replaces the metric values with the per-second delta since the previous sample,
+// the rate is 0 for the first sample and when the value decreases (eg. a counter reset)
+func (t *RateTracker) apply(metrics []external_metrics.ExternalMetricValue) []external_metrics.ExternalMetricValue {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	for i := range metrics {
+		value := float64(metrics[i].Value.MilliValue()) / 1000
+		previous, found := t.samples[metrics[i].MetricName]
+
+		var rate float64
+		switch {
+		case !found:
+			t.samples[metrics[i].MetricName] = rateSample{value: value, time: now}
+		case now.Sub(previous.time) < minRateInterval:
+			rate = previous.rate
+		default:
+			if value > previous.value {
+				rate = (value - previous.value) / now.Sub(previous.time).Seconds()
+			}
+			t.samples[metrics[i].MetricName] = rateSample{value: value, time: now, rate: rate}
+		}
+		metrics[i].Value = *resource.NewMilliQuantity(int64(math.Round(rate*1000)), resource.DecimalSI)
+	}
+	return metrics
+}
+
+// RateSample is the persisted state of the previous sample of a metric
+type RateSample struct {
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+	Rate  float64   `json:"rate"`
+}
+
+// Samples returns the previous samples of the metrics, keyed by metric name
+func (t *RateTracker) Samples() map[string]RateSample {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	samples := make(map[string]RateSample, len(t.samples))
+	for name, sample := range t.samples {
+		samples[name] = RateSample{Value: sample.value, Time: sample.time, Rate: sample.rate}
+	}
+	return samples
+}
+
+// RestoreSamples restores the samples returned by Samples, the samples in the future are dropped so a skewed clock
+// doesn't freeze the rate
+func (t *RateTracker) RestoreSamples(samples map[string]RateSample) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	for name, sample := range samples {
+		if sample.Time.After(now) {
+			continue
+		}
+		t.samples[name] = rateSample{value: sample.Value, time: sample.Time, rate: sample.Rate}
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+func TestRateTracker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tracker := NewRateTracker()
+	tracker.now = func() time.Time { return now }
+
+	sample := func(value int64) int64 {
+		metrics := tracker.apply([]external_metrics.ExternalMetricValue{{
+			MetricName: "s0-documents",
+			Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		}})
+		return metrics[0].Value.MilliValue()
+	}
+
+	// first sample
+	assert.Equal(t, int64(0), sample(1000))
+
+	// 300 documents in 10 seconds
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, int64(30000), sample(1300))
+
+	// too close to the previous sample, the last rate is kept
+	now = now.Add(100 * time.Millisecond)
+	assert.Equal(t, int64(30000), sample(1400))
+
+	// 0.5 documents per second, the sample in the short interval was skipped
+	now = now.Add(1900 * time.Millisecond)
+	assert.Equal(t, int64(500), sample(1301))
+
+	// counter reset
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, int64(0), sample(10))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+// RatioTracker divides the metric values of a scaler by the value of the Denominator trigger,
+// it is used by triggers with a ratio
+type RatioTracker struct {
+	// Denominator is the name of the trigger the values are divided by
+	Denominator string
+
+	valueIfZero *float64
+	maxAge      time.Duration
+
+	lock          sync.Mutex
+	lastValue     float64
+	lastValueTime time.Time
+	now           func() time.Time
+}
+
+// NewRatioTracker creates a RatioTracker, valueIfZero is reported when the denominator is 0 and
+// the last denominator value is used for maxAge when the Denominator trigger fails
+func NewRatioTracker(denominator string, valueIfZero *float64, maxAge time.Duration) *RatioTracker {
+	return &RatioTracker{
+		Denominator: denominator,
+		valueIfZero: valueIfZero,
+		maxAge:      maxAge,
+		now:         time.Now,
+	}
+}
+
+// denominatorValue returns the value of the denominator, or the last value while it isn't stale if err is set
+func (t *RatioTracker) denominatorValue(value float64, err error) (float64, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	if err == nil {
+		t.lastValue, t.lastValueTime = value, now
+		return value, nil
+	}
+	if !t.lastValueTime.IsZero() && now.Sub(t.lastValueTime) <= t.maxAge {
+		return t.lastValue, nil
+	}
+	return 0, fmt.Errorf("error getting the denominator %s: %s", t.Denominator, err)
+}
+
+// apply divides the metric values by the denominator returned by the Denominator trigger
+func (t *RatioTracker) apply(metrics []external_metrics.ExternalMetricValue, denominator float64, err error) ([]external_metrics.ExternalMetricValue, error) {
+	denominator, err = t.denominatorValue(denominator, err)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range metrics {
+		var ratio float64
+		if denominator == 0 {
+			if t.valueIfZero == nil {
+				return nil, fmt.Errorf("the denominator %s is 0", t.Denominator)
+			}
+			ratio = *t.valueIfZero
+		} else {
+			ratio = float64(metrics[i].Value.MilliValue()) / 1000 / denominator
+		}
+		metrics[i].Value = *resource.NewMilliQuantity(int64(math.Round(ratio*1000)), resource.DecimalSI)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+func TestRatioTracker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	valueIfZero := 1.0
+	tracker := NewRatioTracker("throughput", &valueIfZero, time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	divide := func(value int64, denominator float64, err error) (int64, error) {
+		metrics, err := tracker.apply([]external_metrics.ExternalMetricValue{{
+			MetricName: "s0-backlog",
+			Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		}}, denominator, err)
+		if err != nil {
+			return 0, err
+		}
+		return metrics[0].Value.MilliValue(), nil
+	}
+
+	value, err := divide(300, 40, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7500), value)
+
+	value, err = divide(300, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), value)
+
+	// the denominator fails, its last value is used
+	now = now.Add(30 * time.Second)
+	value, err = divide(600, 0, fmt.Errorf("prometheus unavailable"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), value)
+
+	value, err = divide(600, 20, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30000), value)
+
+	// the last value is stale
+	now = now.Add(61 * time.Second)
+	_, err = divide(600, 0, fmt.Errorf("prometheus unavailable"))
+	assert.Error(t, err)
+}
+
+func TestRatioTrackerWithoutValueIfZero(t *testing.T) {
+	tracker := NewRatioTracker("throughput", nil, 0)
+	_, err := tracker.apply([]external_metrics.ExternalMetricValue{{MetricName: "s0-backlog"}}, 0, nil)
+	assert.Error(t, err)
+
+	_, err = tracker.apply([]external_metrics.ExternalMetricValue{{MetricName: "s0-backlog"}}, 0, fmt.Errorf("prometheus unavailable"))
+	assert.Error(t, err)
+}
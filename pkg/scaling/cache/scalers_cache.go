@@ -19,13 +19,21 @@ package cache
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/activation"
+	"github.com/kedacore/keda/v2/pkg/scaling/schedule"
+	"github.com/kedacore/keda/v2/pkg/scaling/transform"
 	"k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/metrics/pkg/apis/external_metrics"
@@ -34,14 +42,65 @@ import (
 
 type ScalersCache struct {
 	Generation int64
-	Scalers    []ScalerBuilder
-	Logger     logr.Logger
-	Recorder   record.EventRecorder
+	// ValueFromChecksum identifies the metadataValueFrom values and the selected ClusterTriggerAuthentications
+	// the Scalers were built with
+	ValueFromChecksum string
+	// LogVerbosity is the LogVerbosityAnnotation the Scalers were built with
+	LogVerbosity int
+	// Expires is when the credentials the Scalers were built with expire, the Scalers are then rebuilt with
+	// re-resolved credentials, zero never expires
+	Expires time.Time
+	// SettingsGeneration is the generation of the settings of the operator the Scalers were built with, eg. the
+	// global HTTP timeout, they are rebuilt when the settings are reloaded
+	SettingsGeneration int64
+	// StateKey is the key the state of the Scalers is persisted under, empty doesn't persist it
+	StateKey string
+	Scalers  []ScalerBuilder
+	Logger   logr.Logger
+	Recorder record.EventRecorder
+
+	// metricSpecs are the results of GetMetricSpecForScaling, they are computed once per Scalers and reset when
+	// a Scaler is refreshed
+	metricSpecsLock   sync.Mutex
+	metricSpecs       []v2beta2.MetricSpec
+	metricSpecsCached bool
 }
 
 type ScalerBuilder struct {
 	Scaler  scalers.Scaler
 	Factory func() (scalers.Scaler, error)
+	// TriggerName is the name of the trigger the Scaler was built from, it can be empty
+	TriggerName string
+	// TriggerType is the type of the trigger the Scaler was built from
+	TriggerType string
+	// Redact removes the credentials of the Scaler from the messages of its errors, nil keeps the messages
+	Redact func(string) string
+	// Rate converts the metric values of the Scaler to their rate of change, nil keeps the absolute values
+	Rate *RateTracker
+	// Transform is applied to the metric values of the Scaler, nil keeps the raw values
+	Transform *transform.Expression
+	// MetricType overrides the target type of the external metrics of the Scaler, empty keeps the type of the Scaler
+	MetricType v2beta2.MetricTargetType
+	// Weight divides the targets of the metrics of the Scaler so the HPA computes Weight times its replica count,
+	// 0 keeps the targets
+	Weight float64
+	// Ratio divides the metric values of the Scaler by the value of another trigger, nil keeps the values
+	Ratio *RatioTracker
+	// BackendHost is the host the Scaler queries, it is empty when the trigger metadata doesn't name it
+	BackendHost string
+	// QueryKey identifies the queries of the Scaler, the Scalers with the same key share the metric values
+	// of their queries, empty doesn't share them
+	QueryKey string
+	// Batch shares the values of the metrics of a MultiMetricScaler fetched in one call, nil queries each metric
+	Batch *MetricBatch
+	// MaxMetricAge rejects the metric values of the Scaler older than it, 0 doesn't check their age
+	MaxMetricAge time.Duration
+	// Soak holds back the activation from zero until the Scaler is active for consecutive checks, nil activates
+	// on the first active check
+	Soak *SoakTracker
+	// Schedule is the time windows the Scaler is evaluated in, outside of them it is inactive and its metric
+	// values are 0, nil always evaluates it
+	Schedule *schedule.TriggerGate
 }
 
 func (c *ScalersCache) GetScalers() []scalers.Scaler {
@@ -62,27 +121,206 @@ func (c *ScalersCache) GetPushScalers() []scalers.PushScaler {
 	return result
 }
 
+// GetActivationListeners returns the Scalers able to listen to the arrival of messages on their broker
+func (c *ScalersCache) GetActivationListeners() []scalers.ActivationListener {
+	var result []scalers.ActivationListener
+	for _, s := range c.Scalers {
+		if l, ok := s.Scaler.(scalers.ActivationListener); ok {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
 func (c *ScalersCache) GetMetricsForScaler(ctx context.Context, id int, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
 	if id < 0 || id >= len(c.Scalers) {
 		return nil, fmt.Errorf("scaler with id %d not found. Len = %d", id, len(c.Scalers))
 	}
-	m, err := c.Scalers[id].Scaler.GetMetrics(ctx, metricName, metricSelector)
+	m, err := c.getMetricsForScaler(ctx, id, metricName, metricSelector)
 	if err == nil {
+		err = c.checkMetricAge(id, m)
+	}
+	if err != nil {
+		// the error is returned to the HPA, its message ends up in the HPA status
+		if message := c.redactError(id, err); message != err.Error() {
+			err = fmt.Errorf("%s", message)
+		}
+		return m, err
+	}
+	if c.Scalers[id].Ratio == nil {
 		return m, nil
 	}
 
+	denominator, err := c.getDenominatorValue(ctx, c.Scalers[id].Ratio.Denominator, metricSelector)
+	return c.Scalers[id].Ratio.apply(m, denominator, err)
+}
+
+func (c *ScalersCache) getMetricsForScaler(ctx context.Context, id int, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if c.isOutOfSchedule(id) {
+		return outOfScheduleMetrics(metricName), nil
+	}
+	m, err := c.querySharedMetrics(ctx, id, metricName, metricSelector)
+	if err == nil {
+		return c.transformMetrics(id, m)
+	}
+
 	ns, err := c.refreshScaler(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return ns.GetMetrics(ctx, metricName, metricSelector)
+	m, err = ns.GetMetrics(ctx, metricName, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+	return c.transformMetrics(id, m)
 }
 
-func (c *ScalersCache) IsScaledObjectActive(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (bool, bool, []external_metrics.ExternalMetricValue) {
-	isActive := false
+// isOutOfSchedule returns true if the scaler with id is outside of the windows of its schedule
+func (c *ScalersCache) isOutOfSchedule(id int) bool {
+	return !c.Scalers[id].Schedule.IsOpen(time.Now())
+}
+
+// outOfScheduleMetrics is the value of a metric outside of the schedule of its scaler,
+// the backend isn't queried as the value isn't used
+func outOfScheduleMetrics(metricName string) []external_metrics.ExternalMetricValue {
+	return []external_metrics.ExternalMetricValue{{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(0, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}}
+}
+
+// checkMetricAge fails when a metric value of the scaler with id is older than its MaxMetricAge,
+// the HPA mustn't scale on stale values presented as fresh ones
+func (c *ScalersCache) checkMetricAge(id int, metrics []external_metrics.ExternalMetricValue) error {
+	maxAge := c.Scalers[id].MaxMetricAge
+	if maxAge <= 0 {
+		return nil
+	}
+	for _, m := range metrics {
+		if age := time.Since(m.Timestamp.Time); age > maxAge {
+			return fmt.Errorf("the value of metric %s is stale, its age %s is above %s", m.MetricName, age.Round(time.Second), maxAge)
+		}
+	}
+	return nil
+}
+
+// querySharedMetrics returns the metrics of the scaler with id, the identical queries of the scalers
+// with the same QueryKey are coalesced
+func (c *ScalersCache) querySharedMetrics(ctx context.Context, id int, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if c.Scalers[id].QueryKey == "" {
+		return c.queryMetrics(ctx, id, metricName, metricSelector)
+	}
+
+	// the metric names start with the index of the trigger in its ScaledObject
+	key := fmt.Sprintf("%s\n%s\n%s", c.Scalers[id].QueryKey, metricIndexPrefix.ReplaceAllString(metricName, ""), metricSelector)
+	return sharedQueries.do(ctx, key, metricName, func() ([]external_metrics.ExternalMetricValue, error) {
+		return c.queryMetrics(ctx, id, metricName, metricSelector)
+	})
+}
+
+// queryMetrics returns the metrics of the scaler with id, the ones of a MultiMetricScaler come from its Batch
+func (c *ScalersCache) queryMetrics(ctx context.Context, id int, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	scaler := c.Scalers[id].Scaler
+	if multiMetricScaler, ok := scaler.(scalers.MultiMetricScaler); ok && c.Scalers[id].Batch != nil {
+		return c.Scalers[id].Batch.get(ctx, multiMetricScaler, metricName, metricSelector)
+	}
+	return scaler.GetMetrics(ctx, metricName, metricSelector)
+}
+
+// getDenominatorValue returns the sum of the external metric values of the trigger with the given name
+func (c *ScalersCache) getDenominatorValue(ctx context.Context, triggerName string, metricSelector labels.Selector) (float64, error) {
+	for i, s := range c.Scalers {
+		if s.TriggerName != triggerName {
+			continue
+		}
+		var value int64
+		for _, spec := range c.GetMetricSpecForScaler(ctx, i) {
+			if spec.External == nil {
+				continue
+			}
+			metrics, err := c.getMetricsForScaler(ctx, i, spec.External.Metric.Name, metricSelector)
+			if err != nil {
+				return 0, err
+			}
+			for _, m := range metrics {
+				value += m.Value.MilliValue()
+			}
+		}
+		return float64(value) / 1000, nil
+	}
+	return 0, fmt.Errorf("trigger %s not found", triggerName)
+}
+
+// isDenominator returns true if the scaler with id is the denominator of a ratio, its metrics aren't used on their own
+func (c *ScalersCache) isDenominator(id int) bool {
+	name := c.Scalers[id].TriggerName
+	if name == "" {
+		return false
+	}
+	for _, s := range c.Scalers {
+		if s.Ratio != nil && s.Ratio.Denominator == name {
+			return true
+		}
+	}
+	return false
+}
+
+// transformMetrics applies the metric mode and the transform of the scaler with id to the metric values
+func (c *ScalersCache) transformMetrics(id int, metrics []external_metrics.ExternalMetricValue) ([]external_metrics.ExternalMetricValue, error) {
+	if c.Scalers[id].Rate != nil {
+		metrics = c.Scalers[id].Rate.apply(metrics)
+	}
+
+	expression := c.Scalers[id].Transform
+	if expression == nil {
+		return metrics, nil
+	}
+
+	for i := range metrics {
+		value, err := expression.Evaluate(float64(metrics[i].Value.MilliValue()) / 1000)
+		if err != nil {
+			return nil, err
+		}
+		metrics[i].Value = *resource.NewMilliQuantity(int64(math.Round(value*1000)), resource.DecimalSI)
+	}
+	return metrics, nil
+}
+
+// IsExpired returns true if the credentials of the Scalers expired at now
+func (c *ScalersCache) IsExpired(now time.Time) bool {
+	return !c.Expires.IsZero() && now.After(c.Expires)
+}
+
+// redactError returns the message of the error of the Scaler with id without its credentials
+func (c *ScalersCache) redactError(id int, err error) string {
+	if id < len(c.Scalers) && c.Scalers[id].Redact != nil {
+		return c.Scalers[id].Redact(err.Error())
+	}
+	return err.Error()
+}
+
+// IsScaledObjectActive returns whether the ScaledObject is active, whether a trigger failed and the keys of the
+// triggers found active, see TriggerKey, with the default activation strategy only the first active trigger is found
+func (c *ScalersCache) IsScaledObjectActive(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (bool, bool, []string) {
+	strategy, err := activation.Parse(scaledObject.GetActivationStrategy())
+	if err != nil {
+		// the strategy is validated by the controller, fallback to the default
+		c.Logger.Error(err, "Error parsing activationStrategy, any active trigger activates the ScaledObject")
+		strategy, _ = activation.Parse(activation.StrategyAny)
+	}
+
 	isError := false
+	activeCondition := scaledObject.Status.Conditions.GetActiveCondition()
+	wasActive := activeCondition.IsTrue()
+	triggersActive := make([]bool, len(c.Scalers))
+	triggerNames := make([]string, len(c.Scalers))
 	for i, s := range c.Scalers {
+		triggerNames[i] = s.TriggerName
+		if c.isOutOfSchedule(i) {
+			continue
+		}
 		isTriggerActive, err := s.Scaler.IsActive(ctx)
 		if err != nil {
 			var ns scalers.Scaler
@@ -93,31 +331,83 @@ func (c *ScalersCache) IsScaledObjectActive(ctx context.Context, scaledObject *k
 		}
 
 		if err != nil {
-			c.Logger.V(1).Info("Error getting scale decision", "Error", err)
+			message := c.redactError(i, err)
+			c.Logger.V(1).Info("Error getting scale decision", "Error", message)
 			isError = true
-			c.Recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
-		} else if isTriggerActive {
-			isActive = true
+			c.Recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, message)
+			isTriggerActive = false
+		}
+		if s.Soak != nil {
+			isTriggerActive = s.Soak.observe(isTriggerActive, wasActive)
+		}
+		if isTriggerActive {
+			triggersActive[i] = true
 			if externalMetricsSpec := s.Scaler.GetMetricSpecForScaling(ctx)[0].External; externalMetricsSpec != nil {
 				c.Logger.V(1).Info("Scaler for scaledObject is active", "Metrics Name", externalMetricsSpec.Metric.Name)
 			}
 			if resourceMetricsSpec := s.Scaler.GetMetricSpecForScaling(ctx)[0].Resource; resourceMetricsSpec != nil {
 				c.Logger.V(1).Info("Scaler for scaledObject is active", "Metrics Name", resourceMetricsSpec.Name)
 			}
-			break
+			// with the default strategy the first active trigger is enough
+			if strategy.IsAny() {
+				break
+			}
 		}
 	}
 
-	return isActive, isError, []external_metrics.ExternalMetricValue{}
+	isActive := strategy.IsActive(triggersActive, triggerNames, len(scaledObject.Spec.Triggers))
+	var activeTriggers []string
+	for i, active := range triggersActive {
+		if active {
+			activeTriggers = append(activeTriggers, c.TriggerKey(i))
+		}
+	}
+	return isActive, isError, activeTriggers
+}
+
+// TriggerKey identifies the trigger of the Scaler with id in the status of the object, it is the name of the trigger,
+// the unnamed triggers are identified by their type and id
+func (c *ScalersCache) TriggerKey(id int) string {
+	if name := c.Scalers[id].TriggerName; name != "" {
+		return name
+	}
+	triggerType := c.Scalers[id].TriggerType
+	if triggerType == "" {
+		triggerType = "trigger"
+	}
+	return fmt.Sprintf("%s-%d", triggerType, id)
 }
 
 func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (bool, int64, int64) {
+	isActive, queueLength, maxValue, _ := c.CheckScaledJob(ctx, scaledJob)
+	return isActive, queueLength, maxValue
+}
+
+// CheckScaledJob returns like IsScaledJobActive whether the ScaledJob is active, its queue length and the max
+// number of Jobs, along with the urgency tier the queue lengths of its triggers reach, nil if none
+func (c *ScalersCache) CheckScaledJob(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (bool, int64, int64, *kedav1alpha1.UrgencyTier) {
+	state := c.GetScaledJobState(ctx, scaledJob)
+	return state.IsActive, state.QueueLength, state.MaxValue, state.UrgencyTier
+}
+
+// ScaledJobState is the result of a check of a ScaledJob, see GetScaledJobState
+type ScaledJobState struct {
+	IsActive    bool
+	QueueLength int64
+	MaxValue    int64
+	UrgencyTier *kedav1alpha1.UrgencyTier
+	// Metrics are the queue lengths of the triggers the check computed the state from
+	Metrics []TriggerMetrics
+}
+
+// GetScaledJobState checks the ScaledJob like CheckScaledJob and records the queue lengths of its triggers
+func (c *ScalersCache) GetScaledJobState(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) *ScaledJobState {
 	var queueLength int64
 	var maxValue int64
 	isActive := false
 
 	logger := logf.Log.WithName("scalemetrics")
-	scalersMetrics := c.getScaledJobMetrics(ctx, scaledJob)
+	scalersMetrics, triggerMetrics := c.getScaledJobMetrics(ctx, scaledJob)
 	switch scaledJob.Spec.ScalingStrategy.MultipleScalersCalculation {
 	case "min":
 		for _, metrics := range scalersMetrics {
@@ -163,12 +453,22 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 	maxValue = min(scaledJob.MaxReplicaCount(), maxValue)
 	logger.V(1).WithValues("ScaledJob", scaledJob.Name).Info("Checking if ScaleJob Scalers are active", "isActive", isActive, "maxValue", maxValue, "MultipleScalersCalculation", scaledJob.Spec.ScalingStrategy.MultipleScalersCalculation)
 
-	return isActive, queueLength, maxValue
+	return &ScaledJobState{
+		IsActive:    isActive,
+		QueueLength: queueLength,
+		MaxValue:    maxValue,
+		UrgencyTier: getUrgencyTier(scaledJob.Spec.UrgencyTiers, scalersMetrics),
+		Metrics:     triggerMetrics,
+	}
 }
 
 func (c *ScalersCache) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
 	var metrics []external_metrics.ExternalMetricValue
 	for i, s := range c.Scalers {
+		if c.isOutOfSchedule(i) {
+			metrics = append(metrics, outOfScheduleMetrics(metricName)...)
+			continue
+		}
 		m, err := s.Scaler.GetMetrics(ctx, metricName, metricSelector)
 		if err != nil {
 			ns, err := c.refreshScaler(ctx, i)
@@ -180,12 +480,76 @@ func (c *ScalersCache) GetMetrics(ctx context.Context, metricName string, metric
 				return metrics, err
 			}
 		}
+		m, err = c.transformMetrics(i, m)
+		if err != nil {
+			return metrics, err
+		}
 		metrics = append(metrics, m...)
 	}
 
 	return metrics, nil
 }
 
+// GetDesiredReplicaCount calculates the replica count the HPA would request based on the external metrics of all scalers,
+// it is used in dry-run mode when there is no HPA. Resource metrics (cpu, memory) are not taken into account.
+func (c *ScalersCache) GetDesiredReplicaCount(ctx context.Context, currentReplicas int32) (int32, error) {
+	return c.getDesiredReplicaCount(c.getTriggerMetrics(ctx, false), currentReplicas)
+}
+
+func (c *ScalersCache) getDesiredReplicaCount(metrics []TriggerMetrics, currentReplicas int32) (int32, error) {
+	var desiredReplicas int32
+	for _, trigger := range metrics {
+		spec := trigger.Spec
+		if spec.External == nil || c.isDenominator(trigger.Index) {
+			continue
+		}
+		if trigger.Err != nil {
+			return 0, trigger.Err
+		}
+		var value int64
+		for _, m := range trigger.Metrics {
+			value += m.Value.MilliValue()
+		}
+
+		var replicas int64
+		switch {
+		case spec.External.Target.AverageValue != nil && spec.External.Target.AverageValue.MilliValue() > 0:
+			replicas = divideWithCeil(value, spec.External.Target.AverageValue.MilliValue())
+		case spec.External.Target.Value != nil && spec.External.Target.Value.MilliValue() > 0:
+			current := int64(currentReplicas)
+			if current == 0 {
+				current = 1
+			}
+			replicas = divideWithCeil(value*current, spec.External.Target.Value.MilliValue())
+		}
+		if int32(replicas) > desiredReplicas {
+			desiredReplicas = int32(replicas)
+		}
+	}
+	return desiredReplicas, nil
+}
+
+// GetPartitionCount returns the highest partition count of the partitioned scalers,
+// it is 0 when none of the scalers is partitioned
+func (c *ScalersCache) GetPartitionCount(ctx context.Context) (int64, error) {
+	var partitionCount int64
+	for _, s := range c.Scalers {
+		partitioned, ok := s.Scaler.(scalers.PartitionedScaler)
+		if !ok {
+			continue
+		}
+
+		count, err := partitioned.GetPartitionCount(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if count > partitionCount {
+			partitionCount = count
+		}
+	}
+	return partitionCount, nil
+}
+
 func (c *ScalersCache) refreshScaler(ctx context.Context, id int) (scalers.Scaler, error) {
 	if id < 0 || id >= len(c.Scalers) {
 		return nil, fmt.Errorf("scaler with id %d not found. Len = %d", id, len(c.Scalers))
@@ -197,21 +561,128 @@ func (c *ScalersCache) refreshScaler(ctx context.Context, id int) (scalers.Scale
 		return nil, err
 	}
 
-	c.Scalers[id] = ScalerBuilder{
-		Scaler:  ns,
-		Factory: sb.Factory,
+	if err := carryState(sb.Scaler, ns); err != nil {
+		c.Logger.Error(err, "Error moving the state of the scaler to the refreshed scaler", "scalerIndex", id)
 	}
+
+	refreshed := sb
+	refreshed.Scaler = ns
+	c.Scalers[id] = refreshed
 	sb.Scaler.Close(ctx)
 
+	c.metricSpecsLock.Lock()
+	c.metricSpecs, c.metricSpecsCached = nil, false
+	c.metricSpecsLock.Unlock()
+
 	return ns, nil
 }
 
+// GetMetricSpecForScaling returns the metric specs of the Scalers for the HPA, they are built once per Scalers
+// and not on every reconcile
 func (c *ScalersCache) GetMetricSpecForScaling(ctx context.Context) []v2beta2.MetricSpec {
-	var spec []v2beta2.MetricSpec
-	for _, s := range c.Scalers {
-		spec = append(spec, s.Scaler.GetMetricSpecForScaling(ctx)...)
+	c.metricSpecsLock.Lock()
+	defer c.metricSpecsLock.Unlock()
+
+	if !c.metricSpecsCached {
+		for i := range c.Scalers {
+			if c.isDenominator(i) {
+				continue
+			}
+			c.metricSpecs = append(c.metricSpecs, c.GetMetricSpecForScaler(ctx, i)...)
+		}
+		c.metricSpecsCached = true
+	}
+
+	// the callers set the selectors of the specs, they get copies so the cached specs are kept as built
+	var specs []v2beta2.MetricSpec
+	for i := range c.metricSpecs {
+		specs = append(specs, *c.metricSpecs[i].DeepCopy())
 	}
-	return spec
+	return specs
+}
+
+// GetMetricSpecForScaler returns the metric specs of the scaler with the target type and the weight of its trigger
+func (c *ScalersCache) GetMetricSpecForScaler(ctx context.Context, id int) []v2beta2.MetricSpec {
+	if id < 0 || id >= len(c.Scalers) {
+		return nil
+	}
+	specs := c.Scalers[id].Scaler.GetMetricSpecForScaling(ctx)
+	specs = c.overrideMetricType(id, specs)
+	if weight := c.Scalers[id].Weight; weight > 0 {
+		for i := range specs {
+			specs[i] = weightMetricSpec(specs[i], weight)
+		}
+	}
+	return specs
+}
+
+// overrideMetricType returns the metric specs with the target type of the trigger of the scaler with id
+func (c *ScalersCache) overrideMetricType(id int, specs []v2beta2.MetricSpec) []v2beta2.MetricSpec {
+	metricType := c.Scalers[id].MetricType
+	if metricType == "" {
+		return specs
+	}
+
+	for i, spec := range specs {
+		if spec.External == nil || spec.External.Target.Type == metricType {
+			continue
+		}
+		external := spec.External.DeepCopy()
+		target := external.Target.AverageValue
+		if target == nil {
+			target = external.Target.Value
+		}
+		if target == nil {
+			continue
+		}
+
+		external.Target = v2beta2.MetricTarget{Type: metricType}
+		switch metricType {
+		case v2beta2.ValueMetricType:
+			external.Target.Value = target
+		case v2beta2.AverageValueMetricType:
+			external.Target.AverageValue = target
+		}
+		specs[i].External = external
+	}
+	return specs
+}
+
+// weightMetricSpec returns a copy of the metric spec with its target divided by weight, the targets are kept
+// above 0 so the HPA doesn't discard the metric
+func weightMetricSpec(spec v2beta2.MetricSpec, weight float64) v2beta2.MetricSpec {
+	weighted := spec.DeepCopy()
+	var target *v2beta2.MetricTarget
+	switch {
+	case weighted.External != nil:
+		target = &weighted.External.Target
+	case weighted.Resource != nil:
+		target = &weighted.Resource.Target
+	default:
+		return spec
+	}
+
+	if target.AverageValue != nil {
+		target.AverageValue = weightQuantity(target.AverageValue, weight)
+	}
+	if target.Value != nil {
+		target.Value = weightQuantity(target.Value, weight)
+	}
+	if target.AverageUtilization != nil {
+		utilization := int32(math.Max(math.Round(float64(*target.AverageUtilization)/weight), 1))
+		target.AverageUtilization = &utilization
+	}
+	return *weighted
+}
+
+// weightQuantity returns the quantity divided by weight, the whole results are integer quantities as the targets
+// of the ScaledJobs are read as integers
+func weightQuantity(quantity *resource.Quantity, weight float64) *resource.Quantity {
+	milli := int64(math.Max(math.Round(float64(quantity.MilliValue())/weight), 1))
+	if milli%1000 == 0 {
+		return resource.NewQuantity(milli/1000, resource.DecimalSI)
+	}
+	return resource.NewMilliQuantity(milli, resource.DecimalSI)
 }
 
 func (c *ScalersCache) Close(ctx context.Context) {
@@ -226,13 +697,20 @@ func (c *ScalersCache) Close(ctx context.Context) {
 }
 
 type scalerMetrics struct {
+	triggerName string
 	queueLength int64
 	maxValue    int64
 	isActive    bool
 }
 
-func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) []scalerMetrics {
+func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) ([]scalerMetrics, []TriggerMetrics) {
 	var scalersMetrics []scalerMetrics
+	var triggerMetrics []TriggerMetrics
+	record := func(id int, specs []v2beta2.MetricSpec, metrics []external_metrics.ExternalMetricValue, err error) {
+		for _, spec := range specs {
+			triggerMetrics = append(triggerMetrics, TriggerMetrics{Index: id, Spec: spec, Metrics: metrics, Err: err})
+		}
+	}
 	for i, s := range c.Scalers {
 		var queueLength int64
 		var targetAverageValue int64
@@ -247,6 +725,18 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 		// skip scaler that doesn't return any metric specs (usually External scaler with incorrect metadata)
 		// or skip cpu/memory resource scaler
 		if len(metricSpecs) < 1 || metricSpecs[0].External == nil {
+			record(i, metricSpecs, nil, nil)
+			continue
+		}
+		if s.Weight > 0 {
+			for j := range metricSpecs {
+				metricSpecs[j] = weightMetricSpec(metricSpecs[j], s.Weight)
+			}
+		}
+		// the scaler is inactive and has no queue outside of its schedule
+		if c.isOutOfSchedule(i) {
+			scalersMetrics = append(scalersMetrics, scalerMetrics{})
+			record(i, metricSpecs, outOfScheduleMetrics("queueLength"), nil)
 			continue
 		}
 
@@ -260,19 +750,34 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 		}
 
 		if err != nil {
-			scalerLogger.V(1).Info("Error getting scaler.IsActive, but continue", "Error", err)
-			c.Recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			message := c.redactError(i, err)
+			scalerLogger.V(1).Info("Error getting scaler.IsActive, but continue", "Error", message)
+			c.Recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, message)
+			if s.Soak != nil {
+				s.Soak.observe(false, false)
+			}
+			record(i, metricSpecs, nil, fmt.Errorf("%s", message))
 			continue
 		}
+		if s.Soak != nil {
+			activeCondition := scaledJob.Status.Conditions.GetActiveCondition()
+			isTriggerActive = s.Soak.observe(isTriggerActive, activeCondition.IsTrue())
+		}
 
 		targetAverageValue = getTargetAverageValue(metricSpecs)
 
 		metrics, err := s.Scaler.GetMetrics(ctx, "queueLength", nil)
+		if err == nil {
+			metrics, err = c.transformMetrics(i, metrics)
+		}
 		if err != nil {
-			scalerLogger.V(1).Info("Error getting scaler metrics, but continue", "Error", err)
-			c.Recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			message := c.redactError(i, err)
+			scalerLogger.V(1).Info("Error getting scaler metrics, but continue", "Error", message)
+			c.Recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, message)
+			record(i, metricSpecs, nil, fmt.Errorf("%s", message))
 			continue
 		}
+		record(i, metricSpecs, metrics, nil)
 
 		var metricValue int64
 
@@ -292,12 +797,13 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 			maxValue = min(scaledJob.MaxReplicaCount(), divideWithCeil(queueLength, targetAverageValue))
 		}
 		scalersMetrics = append(scalersMetrics, scalerMetrics{
+			triggerName: s.TriggerName,
 			queueLength: queueLength,
 			maxValue:    maxValue,
 			isActive:    isActive,
 		})
 	}
-	return scalersMetrics
+	return scalersMetrics, triggerMetrics
 }
 
 func getTargetAverageValue(metricSpecs []v2beta2.MetricSpec) int64 {
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+// sharedQueryTTL is how long the result of a query is shared with the identical queries of the other scalers,
+// it is shorter than a polling cycle so every cycle still gets a fresh value
+const sharedQueryTTL = 5 * time.Second
+
+// sharedQueries coalesces the identical metric queries of the scalers of all the ScaledObjects,
+// eg. the triggers of the ScaledObjects generated from the same template
+var sharedQueries = newQueryGroup(sharedQueryTTL)
+
+// metricIndexPrefix matches the sN- prefix of the external metric names
+var metricIndexPrefix = regexp.MustCompile(`^s\d+-`)
+
+// queryGroup runs the queries with the same key once, the callers asking while the query is in flight
+// or in the ttl after it succeeded get its result, failed queries aren't kept
+type queryGroup struct {
+	ttl time.Duration
+
+	lock  sync.Mutex
+	calls map[string]*queryCall
+}
+
+type queryCall struct {
+	done    chan struct{}
+	metrics []external_metrics.ExternalMetricValue
+	err     error
+}
+
+func newQueryGroup(ttl time.Duration) *queryGroup {
+	return &queryGroup{
+		ttl:   ttl,
+		calls: map[string]*queryCall{},
+	}
+}
+
+// do returns the metrics of the query with the key, named metricName for the caller
+func (g *queryGroup) do(ctx context.Context, key, metricName string, query func() ([]external_metrics.ExternalMetricValue, error)) ([]external_metrics.ExternalMetricValue, error) {
+	g.lock.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		call = &queryCall{done: make(chan struct{})}
+		g.calls[key] = call
+		g.lock.Unlock()
+		g.run(key, call, query)
+	} else {
+		g.lock.Unlock()
+	}
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	// the callers can modify their metrics, the other ScaledObjects name them after their own scaler index
+	metrics := make([]external_metrics.ExternalMetricValue, len(call.metrics))
+	for i, metric := range call.metrics {
+		metric.MetricName = metricName
+		metrics[i] = metric
+	}
+	return metrics, nil
+}
+
+func (g *queryGroup) run(key string, call *queryCall, query func() ([]external_metrics.ExternalMetricValue, error)) {
+	call.metrics, call.err = query()
+	close(call.done)
+
+	if call.err != nil || g.ttl <= 0 {
+		g.forget(key, call)
+		return
+	}
+	time.AfterFunc(g.ttl, func() { g.forget(key, call) })
+}
+
+func (g *queryGroup) forget(key string, call *queryCall) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+func TestQueryGroupCoalescesInFlightQueries(t *testing.T) {
+	group := newQueryGroup(0)
+	var queries int32
+	release := make(chan struct{})
+	query := func() ([]external_metrics.ExternalMetricValue, error) {
+		atomic.AddInt32(&queries, 1)
+		<-release
+		return []external_metrics.ExternalMetricValue{{MetricName: "s0-queue", Value: *resource.NewQuantity(7, resource.DecimalSI)}}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]external_metrics.ExternalMetricValue, 3)
+	for i, name := range []string{"s0-queue", "s1-queue", "s2-queue"} {
+		i, name := i, name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metrics, err := group.do(context.Background(), "key", name, query)
+			assert.NoError(t, err)
+			results[i] = metrics
+		}()
+	}
+	// let the followers join the query in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+	for i, name := range []string{"s0-queue", "s1-queue", "s2-queue"} {
+		assert.Equal(t, name, results[i][0].MetricName)
+		assert.Equal(t, int64(7), results[i][0].Value.Value())
+	}
+
+	// without ttl the next query runs again
+	_, err := group.do(context.Background(), "key", "s0-queue", query)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries))
+}
+
+func TestQueryGroupSharesResultsInTTL(t *testing.T) {
+	group := newQueryGroup(time.Minute)
+	queries := 0
+	query := func() ([]external_metrics.ExternalMetricValue, error) {
+		queries++
+		return []external_metrics.ExternalMetricValue{{MetricName: "s0-queue"}}, nil
+	}
+
+	_, err := group.do(context.Background(), "key", "s0-queue", query)
+	assert.NoError(t, err)
+	_, err = group.do(context.Background(), "key", "s3-queue", query)
+	assert.NoError(t, err)
+	_, err = group.do(context.Background(), "other", "s0-queue", query)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, queries)
+}
+
+func TestQueryGroupDoesntKeepErrors(t *testing.T) {
+	group := newQueryGroup(time.Minute)
+	queries := 0
+	query := func() ([]external_metrics.ExternalMetricValue, error) {
+		queries++
+		return nil, errors.New("backend unavailable")
+	}
+
+	_, err := group.do(context.Background(), "key", "s0-queue", query)
+	assert.Error(t, err)
+	_, err = group.do(context.Background(), "key", "s0-queue", query)
+	assert.Error(t, err)
+	assert.Equal(t, 2, queries)
+}
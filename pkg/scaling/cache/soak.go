@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// SoakTracker requires a trigger to stay active for consecutive checks before it activates its scale target from
+// zero, so a single spiky sample doesn't wake up the scale target. The HPA stabilization only applies above one
+// replica
+type SoakTracker struct {
+	requiredSamples int
+	window          time.Duration
+
+	lock sync.Mutex
+	// samples is the number of consecutive checks the trigger was active in, since is the time of the first one
+	samples int
+	since   time.Time
+	now     func() time.Time
+}
+
+// NewSoakTracker creates a SoakTracker requiring requiredSamples consecutive active checks over at least window
+func NewSoakTracker(requiredSamples int, window time.Duration) *SoakTracker {
+	return &SoakTracker{
+		requiredSamples: requiredSamples,
+		window:          window,
+		now:             time.Now,
+	}
+}
+
+// observe records the activity of the trigger in a check and returns whether it is active, wasActive is whether
+// the scale target was active in the previous check, then the trigger isn't held back
+func (t *SoakTracker) observe(active bool, wasActive bool) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !active {
+		t.samples, t.since = 0, time.Time{}
+		return false
+	}
+	now := t.now()
+	if t.samples == 0 {
+		t.since = now
+	}
+	t.samples++
+	if wasActive {
+		return true
+	}
+	return t.samples >= t.requiredSamples && now.Sub(t.since) >= t.window
+}
+
+// SoakSample is the persisted state of a SoakTracker, the active checks in a row
+type SoakSample struct {
+	Samples int       `json:"samples"`
+	Since   time.Time `json:"since"`
+}
+
+// sample returns the active checks in a row, nil when the trigger was inactive in the last check
+func (t *SoakTracker) sample() *SoakSample {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.samples == 0 {
+		return nil
+	}
+	return &SoakSample{Samples: t.samples, Since: t.since}
+}
+
+func (t *SoakTracker) restore(sample SoakSample) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.samples, t.since = sample.Samples, sample.Since
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoakTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewSoakTracker(3, 20*time.Second)
+	tracker.now = func() time.Time { return now }
+	check := func(active bool, wasActive bool) bool {
+		result := tracker.observe(active, wasActive)
+		now = now.Add(10 * time.Second)
+		return result
+	}
+
+	// a single spike doesn't activate the scale target
+	assert.False(t, check(true, false))
+	assert.False(t, check(false, false))
+
+	// 3 active checks in a row over 20 seconds
+	assert.False(t, check(true, false))
+	assert.False(t, check(true, false))
+	assert.True(t, check(true, false))
+
+	// an active scale target isn't held back
+	assert.False(t, check(false, true))
+	assert.True(t, check(true, true))
+}
+
+func TestSoakTrackerWindow(t *testing.T) {
+	now := time.Now()
+	tracker := NewSoakTracker(1, time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	assert.False(t, tracker.observe(true, false))
+	now = now.Add(30 * time.Second)
+	assert.False(t, tracker.observe(true, false))
+	now = now.Add(30 * time.Second)
+	assert.True(t, tracker.observe(true, false))
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// scalerState is the persisted state of a Scaler, the samples of its rate metric mode, its active checks in a row
+// and the state of a StatefulScaler
+type scalerState struct {
+	Rate   map[string]RateSample `json:"rate,omitempty"`
+	Soak   *SoakSample           `json:"soak,omitempty"`
+	Scaler []byte                `json:"scaler,omitempty"`
+}
+
+// stateKey identifies the state of the Scaler id, the state of a trigger whose type changed isn't restored
+func (c *ScalersCache) stateKey(id int) string {
+	return fmt.Sprintf("%d:%s", id, c.Scalers[id].TriggerType)
+}
+
+// State returns the state of the Scalers to persist across the restarts of the operator, nil when they have none
+func (c *ScalersCache) State() ([]byte, error) {
+	states := map[string]scalerState{}
+	for id, sb := range c.Scalers {
+		var state scalerState
+		if sb.Rate != nil {
+			state.Rate = sb.Rate.Samples()
+		}
+		if sb.Soak != nil {
+			state.Soak = sb.Soak.sample()
+		}
+		if s, ok := sb.Scaler.(scalers.StatefulScaler); ok {
+			data, err := s.State()
+			if err != nil {
+				return nil, fmt.Errorf("error getting the state of scaler %d: %s", id, err)
+			}
+			state.Scaler = data
+		}
+		if len(state.Rate) > 0 || state.Soak != nil || len(state.Scaler) > 0 {
+			states[c.stateKey(id)] = state
+		}
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(states)
+}
+
+// RestoreState restores the state returned by State, the state of the triggers removed since is dropped. The
+// Scalers whose state can't be restored start without it
+func (c *ScalersCache) RestoreState(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	states := map[string]scalerState{}
+	if err := json.Unmarshal(data, &states); err != nil {
+		c.Logger.Error(err, "Error parsing the state of the scalers")
+		return
+	}
+	for id, sb := range c.Scalers {
+		state, ok := states[c.stateKey(id)]
+		if !ok {
+			continue
+		}
+		if sb.Rate != nil && len(state.Rate) > 0 {
+			sb.Rate.RestoreSamples(state.Rate)
+		}
+		if sb.Soak != nil && state.Soak != nil {
+			sb.Soak.restore(*state.Soak)
+		}
+		if s, ok := sb.Scaler.(scalers.StatefulScaler); ok && len(state.Scaler) > 0 {
+			if err := s.RestoreState(state.Scaler); err != nil {
+				c.Logger.Error(err, "Error restoring the state of the scaler", "scalerIndex", id, "triggerType", sb.TriggerType)
+			}
+		}
+	}
+}
+
+// carryState moves the state of a refreshed Scaler to the new one
+func carryState(previous, refreshed scalers.Scaler) error {
+	from, ok := previous.(scalers.StatefulScaler)
+	if !ok {
+		return nil
+	}
+	to, ok := refreshed.(scalers.StatefulScaler)
+	if !ok {
+		return nil
+	}
+	state, err := from.State()
+	if err != nil || len(state) == 0 {
+		return err
+	}
+	return to.RestoreState(state)
+}
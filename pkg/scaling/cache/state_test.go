@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// statefulScaler keeps the state it is given
+type statefulScaler struct {
+	scalers.Scaler
+	state []byte
+}
+
+func (s *statefulScaler) State() ([]byte, error) {
+	return s.state, nil
+}
+
+func (s *statefulScaler) RestoreState(state []byte) error {
+	s.state = state
+	return nil
+}
+
+func (s *statefulScaler) Close(context.Context) error {
+	return nil
+}
+
+func TestScalersCacheState(t *testing.T) {
+	now := time.Now()
+	rate := NewRateTracker()
+	rate.now = func() time.Time { return now }
+	rate.apply([]external_metrics.ExternalMetricValue{{MetricName: "s0-documents", Value: *resource.NewQuantity(1000, resource.DecimalSI)}})
+
+	c := &ScalersCache{Logger: logr.Discard(), Scalers: []ScalerBuilder{
+		{Scaler: &statefulScaler{state: []byte("last")}, TriggerType: "metrics-api", Rate: rate},
+		{Scaler: &statefulScaler{}, TriggerType: "db2"},
+	}}
+	data, err := c.State()
+	assert.NoError(t, err)
+
+	restoredRate := NewRateTracker()
+	restoredRate.now = func() time.Time { return now.Add(10 * time.Second) }
+	restored := &ScalersCache{Logger: logr.Discard(), Scalers: []ScalerBuilder{
+		{Scaler: &statefulScaler{}, TriggerType: "metrics-api", Rate: restoredRate},
+		// the trigger type changed, its state isn't restored
+		{Scaler: &statefulScaler{}, TriggerType: "influxdb"},
+	}}
+	restored.RestoreState(data)
+	assert.Equal(t, []byte("last"), restored.Scalers[0].Scaler.(*statefulScaler).state)
+	assert.Nil(t, restored.Scalers[1].Scaler.(*statefulScaler).state)
+
+	// the rate is computed from the sample before the restart
+	metrics := restoredRate.apply([]external_metrics.ExternalMetricValue{{MetricName: "s0-documents", Value: *resource.NewQuantity(1300, resource.DecimalSI)}})
+	assert.Equal(t, int64(30000), metrics[0].Value.MilliValue())
+
+	empty := &ScalersCache{Logger: logr.Discard(), Scalers: []ScalerBuilder{{Scaler: &statefulScaler{}, TriggerType: "db2"}}}
+	data, err = empty.State()
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestRestoreRateSamplesInTheFuture(t *testing.T) {
+	now := time.Now()
+	rate := NewRateTracker()
+	rate.now = func() time.Time { return now }
+	rate.RestoreSamples(map[string]RateSample{
+		"s0-past":   {Value: 10, Time: now.Add(-time.Minute)},
+		"s0-future": {Value: 10, Time: now.Add(time.Minute)},
+	})
+	samples := rate.Samples()
+	assert.Contains(t, samples, "s0-past")
+	assert.NotContains(t, samples, "s0-future")
+}
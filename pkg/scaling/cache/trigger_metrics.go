@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// TriggerMetrics is a metric of the Scaler with Index and the values computed for it during a check of the object,
+// Metrics are only set for the external metrics and Err is the error computing them
+type TriggerMetrics struct {
+	Index   int
+	Spec    v2beta2.MetricSpec
+	Metrics []external_metrics.ExternalMetricValue
+	Err     error
+}
+
+// ScaledObjectState is the result of a check of a ScaledObject, see GetScaledObjectState
+type ScaledObjectState struct {
+	IsActive       bool
+	IsError        bool
+	ActiveTriggers []string
+
+	cache   *ScalersCache
+	once    sync.Once
+	metrics []TriggerMetrics
+}
+
+// GetScaledObjectState checks whether the ScaledObject is active like IsScaledObjectActive, the metrics of its
+// triggers are then queried once, on the first call to Metrics or GetDesiredReplicaCount, so all the users of the
+// check read the same values instead of querying the scalers again
+func (c *ScalersCache) GetScaledObjectState(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) *ScaledObjectState {
+	isActive, isError, activeTriggers := c.IsScaledObjectActive(ctx, scaledObject)
+	return &ScaledObjectState{
+		IsActive:       isActive,
+		IsError:        isError,
+		ActiveTriggers: activeTriggers,
+		cache:          c,
+	}
+}
+
+// Metrics returns the metrics of all the triggers, they are queried on the first call
+func (s *ScaledObjectState) Metrics(ctx context.Context) []TriggerMetrics {
+	s.once.Do(func() {
+		s.metrics = s.cache.getTriggerMetrics(ctx, true)
+	})
+	return s.metrics
+}
+
+// GetDesiredReplicaCount is GetDesiredReplicaCount computed from the metrics of the check
+func (s *ScaledObjectState) GetDesiredReplicaCount(ctx context.Context, currentReplicas int32) (int32, error) {
+	return s.cache.getDesiredReplicaCount(s.Metrics(ctx), currentReplicas)
+}
+
+// getTriggerMetrics queries the external metrics of the Scalers, the denominators of the ratios are skipped
+// unless withDenominators is set
+func (c *ScalersCache) getTriggerMetrics(ctx context.Context, withDenominators bool) []TriggerMetrics {
+	var result []TriggerMetrics
+	for i := range c.Scalers {
+		if !withDenominators && c.isDenominator(i) {
+			continue
+		}
+		for _, spec := range c.GetMetricSpecForScaler(ctx, i) {
+			trigger := TriggerMetrics{Index: i, Spec: spec}
+			if spec.External != nil {
+				trigger.Metrics, trigger.Err = c.GetMetricsForScaler(ctx, i, spec.External.Metric.Name, nil)
+			}
+			result = append(result, trigger)
+		}
+	}
+	return result
+}
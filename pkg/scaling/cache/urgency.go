@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// getUrgencyTier returns the tier with the highest threshold reached by the queue length of its trigger, the first
+// one of the tiers with the same threshold, nil if the queues don't reach any of them
+func getUrgencyTier(tiers []kedav1alpha1.UrgencyTier, scalersMetrics []scalerMetrics) *kedav1alpha1.UrgencyTier {
+	var urgent *kedav1alpha1.UrgencyTier
+	for i := range tiers {
+		tier := &tiers[i]
+		if urgent != nil && tier.Threshold <= urgent.Threshold {
+			continue
+		}
+		for _, metrics := range scalersMetrics {
+			if (tier.Trigger == "" || tier.Trigger == metrics.triggerName) && metrics.queueLength >= tier.Threshold {
+				urgent = tier
+				break
+			}
+		}
+	}
+	return urgent
+}
+
+// ValidateUrgencyTiers checks the urgency tiers of the ScaledJob, their names are unique and their triggers exist
+func ValidateUrgencyTiers(scaledJob *kedav1alpha1.ScaledJob) error {
+	triggerNames := map[string]bool{}
+	for _, trigger := range scaledJob.Spec.Triggers {
+		if trigger.Name != "" {
+			triggerNames[trigger.Name] = true
+		}
+	}
+
+	tierNames := map[string]bool{}
+	for _, tier := range scaledJob.Spec.UrgencyTiers {
+		if tier.Name == "" {
+			return fmt.Errorf("the urgency tiers must have a name")
+		}
+		if tierNames[tier.Name] {
+			return fmt.Errorf("the urgency tier %s is defined twice", tier.Name)
+		}
+		tierNames[tier.Name] = true
+		if tier.Threshold < 0 {
+			return fmt.Errorf("the threshold of the urgency tier %s can't be negative", tier.Name)
+		}
+		if tier.Trigger != "" && !triggerNames[tier.Trigger] {
+			return fmt.Errorf("the urgency tier %s references the unknown trigger %s", tier.Name, tier.Trigger)
+		}
+	}
+	return nil
+}
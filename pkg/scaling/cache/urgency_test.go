@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestGetUrgencyTier(t *testing.T) {
+	tiers := []kedav1alpha1.UrgencyTier{
+		{Name: "urgent", Trigger: "backlog-age", Threshold: 3600},
+		{Name: "busy", Threshold: 100},
+		{Name: "critical", Trigger: "backlog-age", Threshold: 7200},
+	}
+	metrics := func(queue, backlogAge int64) []scalerMetrics {
+		return []scalerMetrics{{triggerName: "queue", queueLength: queue}, {triggerName: "backlog-age", queueLength: backlogAge}}
+	}
+
+	assert.Nil(t, getUrgencyTier(tiers, metrics(10, 60)))
+	assert.Nil(t, getUrgencyTier(nil, metrics(1000, 10000)))
+	assert.Equal(t, "busy", getUrgencyTier(tiers, metrics(100, 60)).Name)
+	// the queue of another trigger doesn't reach the tier of backlog-age
+	assert.Equal(t, "busy", getUrgencyTier(tiers, []scalerMetrics{{triggerName: "queue", queueLength: 5000}}).Name)
+	assert.Equal(t, "urgent", getUrgencyTier(tiers, metrics(10, 3600)).Name)
+	assert.Equal(t, "critical", getUrgencyTier(tiers, metrics(10, 9000)).Name)
+}
+
+func TestValidateUrgencyTiers(t *testing.T) {
+	scaledJob := &kedav1alpha1.ScaledJob{Spec: kedav1alpha1.ScaledJobSpec{
+		Triggers:     []kedav1alpha1.ScaleTriggers{{Name: "backlog-age", Type: "prometheus"}, {Type: "rabbitmq"}},
+		UrgencyTiers: []kedav1alpha1.UrgencyTier{{Name: "urgent", Trigger: "backlog-age", Threshold: 3600}, {Name: "busy", Threshold: 100}},
+	}}
+	assert.NoError(t, ValidateUrgencyTiers(scaledJob))
+
+	invalid := [][]kedav1alpha1.UrgencyTier{
+		{{Threshold: 100}},
+		{{Name: "busy", Threshold: 100}, {Name: "busy", Threshold: 200}},
+		{{Name: "busy", Threshold: -1}},
+		{{Name: "urgent", Trigger: "queue", Threshold: 100}},
+	}
+	for _, tiers := range invalid {
+		scaledJob.Spec.UrgencyTiers = tiers
+		assert.Error(t, ValidateUrgencyTiers(scaledJob), "%v", tiers)
+	}
+}
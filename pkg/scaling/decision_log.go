@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/audit"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+// logScaledObjectDecision records the evaluation of the ScaledObject triggers in the decision log,
+// the values are the ones of the check, the scalers aren't queried again
+func (h *scaleHandler) logScaledObjectDecision(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, scalersCache *cache.ScalersCache, state *cache.ScaledObjectState) {
+	decision := audit.Decision{
+		Time:      time.Now(),
+		Kind:      "ScaledObject",
+		Namespace: scaledObject.Namespace,
+		Name:      scaledObject.Name,
+		Active:    state.IsActive,
+		Error:     state.IsError,
+		Triggers:  getTriggerDecisions(scalersCache, state.Metrics(ctx)),
+	}
+
+	currentReplicas, desiredReplicas, err := h.scaleExecutor.EstimateReplicaCount(ctx, scaledObject, state.IsActive, state.IsError, state.GetDesiredReplicaCount)
+	if err != nil {
+		h.logger.V(1).Info("Error estimating replica count for decision log", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "error", err)
+	} else {
+		decision.CurrentReplicas = &currentReplicas
+		decision.DesiredReplicas = &desiredReplicas
+	}
+
+	h.decisionLogger.Log(decision)
+}
+
+// logScaledJobDecision records the evaluation of the ScaledJob triggers in the decision log,
+// desired replicas are the number of jobs that should be running
+func (h *scaleHandler) logScaledJobDecision(scaledJob *kedav1alpha1.ScaledJob, scalersCache *cache.ScalersCache, state *cache.ScaledJobState, maxScale int64) {
+	desiredReplicas := int32(maxScale)
+	h.decisionLogger.Log(audit.Decision{
+		Time:            time.Now(),
+		Kind:            "ScaledJob",
+		Namespace:       scaledJob.Namespace,
+		Name:            scaledJob.Name,
+		Active:          state.IsActive,
+		DesiredReplicas: &desiredReplicas,
+		Triggers:        getTriggerDecisions(scalersCache, state.Metrics),
+	})
+}
+
+// getTriggerDecisions returns the decisions of the triggers from the metrics recorded by the check
+func getTriggerDecisions(scalersCache *cache.ScalersCache, metrics []cache.TriggerMetrics) []audit.TriggerDecision {
+	scalers := scalersCache.GetScalers()
+	triggers := []audit.TriggerDecision{}
+	for _, metric := range metrics {
+		spec := metric.Spec
+		trigger := audit.TriggerDecision{
+			Index: metric.Index,
+			Type:  strings.TrimPrefix(fmt.Sprintf("%T", scalers[metric.Index]), "*scalers."),
+		}
+
+		switch {
+		case spec.External != nil:
+			trigger.MetricName = spec.External.Metric.Name
+			trigger.TargetType = string(spec.External.Target.Type)
+			if spec.External.Target.AverageValue != nil {
+				trigger.Target = spec.External.Target.AverageValue.String()
+			} else if spec.External.Target.Value != nil {
+				trigger.Target = spec.External.Target.Value.String()
+			}
+
+			if metric.Err != nil {
+				trigger.Error = metric.Err.Error()
+			} else {
+				value := resource.NewMilliQuantity(0, resource.DecimalSI)
+				for _, m := range metric.Metrics {
+					value.Add(m.Value)
+				}
+				trigger.Value = value.String()
+			}
+		case spec.Resource != nil:
+			trigger.MetricName = string(spec.Resource.Name)
+			trigger.TargetType = string(spec.Resource.Target.Type)
+			if spec.Resource.Target.AverageUtilization != nil {
+				trigger.Target = fmt.Sprintf("%d", *spec.Resource.Target.AverageUtilization)
+			} else if spec.Resource.Target.AverageValue != nil {
+				trigger.Target = spec.Resource.Target.AverageValue.String()
+			}
+		case spec.ContainerResource != nil:
+			trigger.MetricName = fmt.Sprintf("%s/%s", spec.ContainerResource.Container, spec.ContainerResource.Name)
+			trigger.TargetType = string(spec.ContainerResource.Target.Type)
+			if spec.ContainerResource.Target.AverageUtilization != nil {
+				trigger.Target = fmt.Sprintf("%d", *spec.ContainerResource.Target.AverageUtilization)
+			} else if spec.ContainerResource.Target.AverageValue != nil {
+				trigger.Target = spec.ContainerResource.Target.AverageValue.String()
+			}
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers
+}
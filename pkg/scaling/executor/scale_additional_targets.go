@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// additionalScaleTargetRef is a workload selected by an AdditionalScaleTarget
+type additionalScaleTargetRef struct {
+	name          string
+	groupResource schema.GroupResource
+}
+
+// scaleAdditionalTargets scales the additionalScaleTargets of the ScaledObject to the replica count of its
+// scaleTargetRef multiplied by their ratio
+func (e *scaleExecutor) scaleAdditionalTargets(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) {
+	if len(scaledObject.Spec.AdditionalScaleTargets) == 0 {
+		return
+	}
+
+	_, replicas, err := e.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting the replica count of the scaleTarget for the additionalScaleTargets")
+		return
+	}
+
+	for index, target := range scaledObject.Spec.AdditionalScaleTargets {
+		ratio, err := parseScaleTargetRatio(target.Ratio)
+		if err != nil {
+			e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.ScaledObjectCheckFailed, "Invalid additionalScaleTargets[%d]: %s", index, err)
+			continue
+		}
+		desiredReplicas := int32(math.Ceil(float64(replicas) * ratio))
+
+		refs, err := e.resolveAdditionalScaleTarget(ctx, scaledObject, target)
+		if err != nil {
+			e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.ScaledObjectCheckFailed, "Invalid additionalScaleTargets[%d]: %s", index, err)
+			continue
+		}
+		for _, ref := range refs {
+			if err := e.scaleAdditionalTarget(ctx, logger, scaledObject.Namespace, ref, desiredReplicas); err != nil {
+				logger.Error(err, "Error scaling additional scale target", "additionalScaleTarget.Name", ref.name, "additionalScaleTarget.Resource", ref.groupResource.String())
+			}
+		}
+	}
+}
+
+// parseScaleTargetRatio parses the ratio of an AdditionalScaleTarget, empty means 1
+func parseScaleTargetRatio(ratio string) (float64, error) {
+	if ratio == "" {
+		return 1, nil
+	}
+	value, err := strconv.ParseFloat(ratio, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing ratio: %s", err)
+	}
+	if value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("ratio %s must be a non-negative number", ratio)
+	}
+	return value, nil
+}
+
+func (e *scaleExecutor) resolveAdditionalScaleTarget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, target kedav1alpha1.AdditionalScaleTarget) ([]additionalScaleTargetRef, error) {
+	switch {
+	case target.ScaleTargetRef != nil && target.Selector != nil:
+		return nil, fmt.Errorf("only one of scaleTargetRef and selector can be set")
+	case target.ScaleTargetRef != nil:
+		gvkr, err := kedautil.ParseGVKR(e.client.RESTMapper(), target.ScaleTargetRef.APIVersion, target.ScaleTargetRef.Kind)
+		if err != nil {
+			return nil, err
+		}
+		return []additionalScaleTargetRef{{name: target.ScaleTargetRef.Name, groupResource: gvkr.GroupResource()}}, nil
+	case target.Selector != nil:
+		if len(target.Selector.MatchLabels) == 0 && len(target.Selector.MatchExpressions) == 0 {
+			return nil, fmt.Errorf("selector must not be empty")
+		}
+		selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+		if err != nil {
+			return nil, err
+		}
+		deployments := &appsv1.DeploymentList{}
+		if err := e.client.List(ctx, deployments, runtimeclient.InNamespace(scaledObject.Namespace), runtimeclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+
+		targetGVKR := scaledObject.Status.ScaleTargetGVKR
+		primaryIsDeployment := targetGVKR != nil && targetGVKR.Group == "apps" && targetGVKR.Kind == "Deployment"
+		refs := make([]additionalScaleTargetRef, 0, len(deployments.Items))
+		for _, deployment := range deployments.Items {
+			if primaryIsDeployment && deployment.Name == scaledObject.Spec.ScaleTargetRef.Name {
+				continue
+			}
+			refs = append(refs, additionalScaleTargetRef{name: deployment.Name, groupResource: schema.GroupResource{Group: "apps", Resource: "deployments"}})
+		}
+		return refs, nil
+	default:
+		return nil, fmt.Errorf("one of scaleTargetRef and selector must be set")
+	}
+}
+
+func (e *scaleExecutor) scaleAdditionalTarget(ctx context.Context, logger logr.Logger, namespace string, ref additionalScaleTargetRef, replicas int32) error {
+	scale, err := e.scaleClient.Scales(namespace).Get(ctx, ref.groupResource, ref.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if scale.Spec.Replicas == replicas {
+		return nil
+	}
+
+	currentReplicas := scale.Spec.Replicas
+	scale.Spec.Replicas = replicas
+	if _, err := e.scaleClient.Scales(namespace).Update(ctx, ref.groupResource, scale, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	logger.Info("Successfully set additional scale target replicas count", "additionalScaleTarget.Name", ref.name,
+		"Original Replicas Count", currentReplicas, "New Replicas Count", replicas)
+	return nil
+}
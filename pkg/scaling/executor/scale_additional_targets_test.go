@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scale"
+)
+
+func TestParseScaleTargetRatio(t *testing.T) {
+	tests := []struct {
+		ratio    string
+		expected float64
+		isError  bool
+	}{
+		{"", 1, false},
+		{"0.5", 0.5, false},
+		{"2", 2, false},
+		{"0", 0, false},
+		{"-1", 0, true},
+		{"half", 0, true},
+		{"NaN", 0, true},
+	}
+	for _, test := range tests {
+		ratio, err := parseScaleTargetRatio(test.ratio)
+		if test.isError {
+			assert.Error(t, err, test.ratio)
+			continue
+		}
+		assert.NoError(t, err, test.ratio)
+		assert.Equal(t, test.expected, ratio, test.ratio)
+	}
+}
+
+func TestScaleAdditionalTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, record.NewFakeRecorder(1)).(*scaleExecutor)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "shard-0",
+			},
+			AdditionalScaleTargets: []v1alpha1.AdditionalScaleTarget{
+				{Selector: &v1.LabelSelector{MatchLabels: map[string]string{"app": "shard"}}},
+				{ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "cache", Kind: "StatefulSet"}, Ratio: "0.5"},
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	primaryReplicas := int32(3)
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &primaryReplicas,
+		},
+	})
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(1, appsv1.DeploymentList{
+		Items: []appsv1.Deployment{
+			{ObjectMeta: v1.ObjectMeta{Name: "shard-0"}},
+			{ObjectMeta: v1.ObjectMeta{Name: "shard-1"}},
+			{ObjectMeta: v1.ObjectMeta{Name: "shard-2"}},
+		},
+	})
+	client.EXPECT().RESTMapper().Return(nil)
+
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	statefulSets := schema.GroupResource{Group: "apps", Resource: "statefulsets"}
+	shard1 := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 1}}
+	shard2 := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 3}}
+	cache := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 0}}
+
+	mockScaleClient.EXPECT().Scales("namespace").Return(mockScaleInterface).Times(5)
+	mockScaleInterface.EXPECT().Get(gomock.Any(), deployments, "shard-1", gomock.Any()).Return(shard1, nil)
+	mockScaleInterface.EXPECT().Get(gomock.Any(), deployments, "shard-2", gomock.Any()).Return(shard2, nil)
+	mockScaleInterface.EXPECT().Get(gomock.Any(), statefulSets, "cache", gomock.Any()).Return(cache, nil)
+	// shard-2 already has the replica count of shard-0
+	mockScaleInterface.EXPECT().Update(gomock.Any(), deployments, gomock.Eq(shard1), gomock.Any())
+	mockScaleInterface.EXPECT().Update(gomock.Any(), statefulSets, gomock.Eq(cache), gomock.Any())
+
+	scaleExecutor.scaleAdditionalTargets(context.TODO(), logf.Log, &scaledObject)
+
+	assert.Equal(t, int32(3), shard1.Spec.Replicas)
+	assert.Equal(t, int32(3), shard2.Spec.Replicas)
+	assert.Equal(t, int32(2), cache.Spec.Replicas)
+}
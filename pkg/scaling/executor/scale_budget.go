@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/scaling/schedule"
+)
+
+// RecordBudget records the replicas of the scale target in the budget status of the ScaledObject and the replica count
+// the budget caps it at, an Event is recorded when the budget starts or stops capping the scale target
+func (e *scaleExecutor) RecordBudget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) {
+	if scaledObject.Spec.Budget == nil {
+		return
+	}
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	_, currentReplicas, err := e.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
+		return
+	}
+
+	now := time.Now()
+	original := scaledObject.DeepCopy()
+	patch := runtimeclient.MergeFrom(original)
+	if err := schedule.RecordBudget(scaledObject, currentReplicas, now); err != nil {
+		logger.Error(err, "Error recording the budget consumption")
+		return
+	}
+	// the cap is recorded against the replica bounds of the active schedule windows
+	scheduled, _, err := schedule.Apply(scaledObject, now)
+	if err != nil {
+		scheduled = scaledObject
+	}
+	capped, reason, _, err := schedule.BudgetCap(scheduled, now)
+	if err != nil {
+		logger.Error(err, "Error applying budget")
+		return
+	}
+
+	if scaledObject.Status.Budget == nil {
+		scaledObject.Status.Budget = &kedav1alpha1.BudgetStatus{}
+	}
+	previous := scaledObject.Status.Budget.ClampedReplicaCount
+	switch {
+	case capped != nil && (previous == nil || *previous != *capped):
+		logger.Info("Budget caps the scaleTarget", "Max Replicas Count", *capped, "Reason", reason)
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDABudgetClamped, "Budget caps %s %s/%s at %d replicas: %s", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, *capped, reason)
+	case capped == nil && previous != nil:
+		logger.Info("Budget doesn't cap the scaleTarget anymore")
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDABudgetReleased, "Budget doesn't cap %s %s/%s anymore", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name)
+	}
+	scaledObject.Status.Budget.ClampedReplicaCount = capped
+
+	// the status is only patched when it changed, not on every poll
+	if equality.Semantic.DeepEqual(original.Status.Budget, scaledObject.Status.Budget) {
+		return
+	}
+	if err := e.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+		logger.Error(err, "Failed to patch Objects Status")
+	}
+}
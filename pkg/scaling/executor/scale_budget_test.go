@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestRecordBudgetClampsAndReleases(t *testing.T) {
+	if err := v1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	hours := int32(1)
+	scaledObject := newPreProvisioningScaledObject(nil)
+	scaledObject.Spec.Budget = &v1alpha1.Budget{MaxReplicaHoursPerDay: &hours}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newPreProvisioningDeployment(2), scaledObject).Build()
+	recorder := record.NewFakeRecorder(2)
+	e := NewScaleExecutor(client, nil, nil, recorder)
+
+	e.RecordBudget(context.TODO(), scaledObject)
+	assert.NotNil(t, scaledObject.Status.Budget.LastUpdateTime)
+	assert.Nil(t, scaledObject.Status.Budget.ClampedReplicaCount)
+
+	scaledObject.Status.Budget.ReplicaSeconds = 3600
+	e.RecordBudget(context.TODO(), scaledObject)
+	assert.Equal(t, int32(1), *scaledObject.Status.Budget.ClampedReplicaCount)
+	assert.Equal(t, "Normal KEDABudgetClamped Budget caps apps/v1.Deployment shop/orders at 1 replicas: maxReplicaHoursPerDay=1 spent", <-recorder.Events)
+
+	hours = 10
+	e.RecordBudget(context.TODO(), scaledObject)
+	assert.Nil(t, scaledObject.Status.Budget.ClampedReplicaCount)
+	assert.Equal(t, "Normal KEDABudgetReleased Budget doesn't cap apps/v1.Deployment shop/orders anymore", <-recorder.Events)
+}
+
+func TestRecordBudgetPatchesChangedStatus(t *testing.T) {
+	if err := v1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	hours := int32(1)
+	scaledObject := newPreProvisioningScaledObject(nil)
+	scaledObject.Spec.Budget = &v1alpha1.Budget{MaxReplicaHoursPerDay: &hours}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newPreProvisioningDeployment(0), scaledObject).Build()
+	e := NewScaleExecutor(client, nil, nil, record.NewFakeRecorder(1))
+	resourceVersion := func() string {
+		stored := &v1alpha1.ScaledObject{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: scaledObject.Name, Namespace: scaledObject.Namespace}, stored); err != nil {
+			t.Fatal(err)
+		}
+		return stored.ResourceVersion
+	}
+
+	e.RecordBudget(context.TODO(), scaledObject)
+	assert.NotNil(t, scaledObject.Status.Budget)
+	patched := resourceVersion()
+
+	// the scale target stays scaled to zero, the status doesn't change
+	e.RecordBudget(context.TODO(), scaledObject)
+	assert.Equal(t, patched, resourceVersion())
+}
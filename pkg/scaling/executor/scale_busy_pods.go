@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	// defaultBusyPodsTimeout is how long the scale down waits for busy pods after the cooldown period
+	defaultBusyPodsTimeout = 600 * time.Second
+)
+
+// waitForBusyPods returns true while pods of the scale target are annotated with keda.sh/busy and the scale down
+// has to be delayed, the scale is fetched when it is nil and returned to be reused
+func (e *scaleExecutor) waitForBusyPods(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, cooldownPeriod time.Duration) (*autoscalingv1.Scale, bool) {
+	// without the last active time the deadline is unknown, like the cooldown period the busy pods are ignored
+	if scaledObject.Status.LastActiveTime == nil {
+		return scale, false
+	}
+
+	timeout := defaultBusyPodsTimeout
+	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.BusyPodsTimeoutSeconds != nil {
+		timeout = time.Duration(*scaledObject.Spec.Advanced.BusyPodsTimeoutSeconds) * time.Second
+	}
+	if scaledObject.Status.LastActiveTime.Add(cooldownPeriod + timeout).Before(time.Now()) {
+		return scale, false
+	}
+
+	if scale == nil {
+		var err error
+		scale, err = e.getScaleTargetScale(ctx, scaledObject)
+		if err != nil {
+			logger.Error(err, "Error getting the pod selector of the scaleTarget, the scale down is delayed")
+			return nil, true
+		}
+	}
+	if scale.Status.Selector == "" {
+		return scale, false
+	}
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		logger.Error(err, "Error parsing the pod selector of the scaleTarget", "selector", scale.Status.Selector)
+		return scale, false
+	}
+
+	pods := &corev1.PodList{}
+	if err := e.client.List(ctx, pods, runtimeclient.InNamespace(scaledObject.GetScaleTargetNamespace()), runtimeclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+		logger.Error(err, "Error listing the pods of the scaleTarget, the scale down is delayed")
+		return scale, true
+	}
+	for _, pod := range pods.Items {
+		if pod.Annotations[kedav1alpha1.PodBusyAnnotation] == "true" {
+			logger.V(1).Info("ScaleTarget has busy pods, the scale down is delayed", "Pod", pod.Name, "Timeout", timeout)
+			return scale, true
+		}
+	}
+	return scale, false
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scale"
+)
+
+func TestWaitForBusyPods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, record.NewFakeRecorder(1)).(*scaleExecutor)
+
+	cooldownPeriod := 5 * time.Minute
+	lastActiveTime := v1.NewTime(time.Now().Add(-cooldownPeriod - time.Minute))
+	timeout := int32(300)
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{Name: "name", Namespace: "namespace"},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "worker"},
+			Advanced:       &v1alpha1.AdvancedConfig{BusyPodsTimeoutSeconds: &timeout},
+		},
+		Status: v1alpha1.ScaledObjectStatus{LastActiveTime: &lastActiveTime},
+	}
+	scale := &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: "app=worker"}}
+
+	pods := func(annotations ...map[string]string) corev1.PodList {
+		list := corev1.PodList{}
+		for _, a := range annotations {
+			list.Items = append(list.Items, corev1.Pod{ObjectMeta: v1.ObjectMeta{Annotations: a}})
+		}
+		return list
+	}
+
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).SetArg(1, pods(nil, map[string]string{v1alpha1.PodBusyAnnotation: "true"}))
+	_, busy := scaleExecutor.waitForBusyPods(context.TODO(), logf.Log, scaledObject, scale, cooldownPeriod)
+	assert.True(t, busy)
+
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).SetArg(1, pods(nil, map[string]string{v1alpha1.PodBusyAnnotation: "false"}))
+	_, busy = scaleExecutor.waitForBusyPods(context.TODO(), logf.Log, scaledObject, scale, cooldownPeriod)
+	assert.False(t, busy)
+
+	// the timeout elapsed, the pods aren't listed
+	lastActiveTime = v1.NewTime(time.Now().Add(-cooldownPeriod - 6*time.Minute))
+	_, busy = scaleExecutor.waitForBusyPods(context.TODO(), logf.Log, scaledObject, scale, cooldownPeriod)
+	assert.False(t, busy)
+
+	// without the last active time the busy pods are ignored
+	scaledObject.Status.LastActiveTime = nil
+	_, busy = scaleExecutor.waitForBusyPods(context.TODO(), logf.Log, scaledObject, scale, cooldownPeriod)
+	assert.False(t, busy)
+}
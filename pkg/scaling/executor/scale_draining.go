@@ -0,0 +1,241 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	// defaultDrainingTimeout is how long the scale down waits for the pods to drain
+	defaultDrainingTimeout = 300 * time.Second
+
+	// podDeletionCostAnnotation ranks the pods removed by the ReplicaSet controller, the lower cost is removed first
+	podDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+
+	// drainingPodDeletionCost is the lowest cost, so the draining pods are the ones removed by the scale down
+	drainingPodDeletionCost = "-2147483648"
+)
+
+// podOrdinalRegexp matches the ordinal of the pods of a StatefulSet
+var podOrdinalRegexp = regexp.MustCompile(`-([0-9]+)$`)
+
+// drainPods labels the pods removed by the scale down to replicas with keda.sh/draining and returns true while they
+// aren't drained and the scale down has to be delayed, the scale is fetched when it is nil and returned to be reused
+func (e *scaleExecutor) drainPods(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, replicas int32) (*autoscalingv1.Scale, bool) {
+	draining := scaledObject.GetDraining()
+	if draining == nil {
+		return scale, false
+	}
+	timeout := defaultDrainingTimeout
+	if draining.TimeoutSeconds != nil {
+		timeout = time.Duration(*draining.TimeoutSeconds) * time.Second
+	}
+
+	scale, pods, err := e.listScaleTargetPods(ctx, scaledObject, scale)
+	if err != nil {
+		logger.Error(err, "Error listing the pods of the scaleTarget, the scale down is delayed")
+		return scale, true
+	}
+
+	removed := getRemovedPods(pods, replicas, scaledObject.Status.ScaleTargetKind)
+	if err := e.undrainPods(ctx, pods, removed); err != nil {
+		logger.Error(err, "Error removing the draining label of the pods kept by the scale down")
+	}
+
+	now := time.Now()
+	drained := true
+	for i := range pods {
+		pod := &pods[i]
+		if !removed[pod.Name] {
+			continue
+		}
+		since, err := time.Parse(time.RFC3339, pod.Annotations[kedav1alpha1.PodDrainingSinceAnnotation])
+		if pod.Labels[kedav1alpha1.PodDrainingLabel] != "true" || err != nil {
+			if err := e.labelDrainingPod(ctx, pod, now); err != nil {
+				logger.Error(err, "Error labeling the pod to drain, the scale down is delayed", "Pod", pod.Name)
+				return scale, true
+			}
+			since = now
+		}
+		if !isPodDrained(pod, draining.Condition) && now.Before(since.Add(timeout)) {
+			logger.V(1).Info("ScaleTarget has draining pods, the scale down is delayed", "Pod", pod.Name, "Timeout", timeout)
+			drained = false
+		}
+	}
+	return scale, !drained
+}
+
+// clearDrainingPods removes the draining label of the pods of the scale target once no scale down is pending,
+// eg. the triggers became active again while the pods were draining
+func (e *scaleExecutor) clearDrainingPods(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale) {
+	if scaledObject.GetDraining() == nil {
+		return
+	}
+	_, pods, err := e.listScaleTargetPods(ctx, scaledObject, scale)
+	if err != nil {
+		logger.Error(err, "Error listing the pods of the scaleTarget")
+		return
+	}
+	if err := e.undrainPods(ctx, pods, nil); err != nil {
+		logger.Error(err, "Error removing the draining label of the pods")
+	}
+}
+
+// listScaleTargetPods lists the pods of the scale target which aren't terminating
+func (e *scaleExecutor) listScaleTargetPods(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale) (*autoscalingv1.Scale, []corev1.Pod, error) {
+	if scale == nil {
+		var err error
+		if scale, err = e.getScaleTargetScale(ctx, scaledObject); err != nil {
+			return nil, nil, err
+		}
+	}
+	// without a pod selector the pods of the scale target are unknown, like the busy pods they are ignored
+	if scale.Status.Selector == "" {
+		return scale, nil, nil
+	}
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		return scale, nil, err
+	}
+
+	list := &corev1.PodList{}
+	if err := e.client.List(ctx, list, runtimeclient.InNamespace(scaledObject.GetScaleTargetNamespace()), runtimeclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return scale, nil, err
+	}
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	return scale, pods, nil
+}
+
+// getRemovedPods returns the names of the pods expected to be removed by the scale down to replicas, a StatefulSet
+// removes its highest ordinals while a ReplicaSet removes the not ready pods first and then the lowest deletion cost
+func getRemovedPods(pods []corev1.Pod, replicas int32, scaleTargetKind string) map[string]bool {
+	count := len(pods) - int(replicas)
+	if count <= 0 {
+		return nil
+	}
+
+	ranked := make([]corev1.Pod, len(pods))
+	copy(ranked, pods)
+	if scaleTargetKind == "apps/v1.StatefulSet" {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return podOrdinal(&ranked[i]) > podOrdinal(&ranked[j])
+		})
+	} else {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			// the pods already draining are kept removed, unless the draining pods outnumber the removed ones
+			if iDraining, jDraining := ranked[i].Labels[kedav1alpha1.PodDrainingLabel] == "true", ranked[j].Labels[kedav1alpha1.PodDrainingLabel] == "true"; iDraining != jDraining {
+				return iDraining
+			}
+			if iReady, jReady := isPodReady(&ranked[i]), isPodReady(&ranked[j]); iReady != jReady {
+				return !iReady
+			}
+			return ranked[j].CreationTimestamp.Before(&ranked[i].CreationTimestamp)
+		})
+	}
+
+	removed := make(map[string]bool, count)
+	for _, pod := range ranked[:count] {
+		removed[pod.Name] = true
+	}
+	return removed
+}
+
+// podOrdinal returns the ordinal of a pod of a StatefulSet, -1 if its name has none
+func podOrdinal(pod *corev1.Pod) int {
+	match := podOrdinalRegexp.FindStringSubmatch(pod.Name)
+	if match == nil {
+		return -1
+	}
+	ordinal, err := strconv.Atoi(match[1])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isPodDrained returns true once the pod signals it is drained
+func isPodDrained(pod *corev1.Pod, condition kedav1alpha1.DrainingCondition) bool {
+	if condition == kedav1alpha1.DrainingReadiness {
+		return !isPodReady(pod)
+	}
+	return pod.Annotations[kedav1alpha1.PodDrainedAnnotation] == "true"
+}
+
+// labelDrainingPod labels the pod with keda.sh/draining and lowers its deletion cost, so the ReplicaSet controller
+// removes it among the pods of the scale down
+func (e *scaleExecutor) labelDrainingPod(ctx context.Context, pod *corev1.Pod, now time.Time) error {
+	patch := runtimeclient.MergeFrom(pod.DeepCopy())
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Labels[kedav1alpha1.PodDrainingLabel] = "true"
+	pod.Annotations[kedav1alpha1.PodDrainingSinceAnnotation] = now.UTC().Format(time.RFC3339)
+	pod.Annotations[podDeletionCostAnnotation] = drainingPodDeletionCost
+	return e.client.Patch(ctx, pod, patch)
+}
+
+// undrainPods removes the draining label and annotations of the pods which aren't removed by the scale down,
+// the drained annotation is removed too so the pods drain again for the next scale down
+func (e *scaleExecutor) undrainPods(ctx context.Context, pods []corev1.Pod, removed map[string]bool) error {
+	for i := range pods {
+		pod := &pods[i]
+		if removed[pod.Name] || pod.Labels[kedav1alpha1.PodDrainingLabel] != "true" {
+			continue
+		}
+		patch := runtimeclient.MergeFrom(pod.DeepCopy())
+		delete(pod.Labels, kedav1alpha1.PodDrainingLabel)
+		delete(pod.Annotations, kedav1alpha1.PodDrainingSinceAnnotation)
+		delete(pod.Annotations, kedav1alpha1.PodDrainedAnnotation)
+		if pod.Annotations[podDeletionCostAnnotation] == drainingPodDeletionCost {
+			delete(pod.Annotations, podDeletionCostAnnotation)
+		}
+		if err := e.client.Patch(ctx, pod, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func newDrainingPod(name string, age time.Duration, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "chat"}, CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestGetRemovedPods(t *testing.T) {
+	draining := newDrainingPod("chat-draining", 3*time.Hour, true)
+	draining.Labels[v1alpha1.PodDrainingLabel] = "true"
+	pods := []corev1.Pod{*newDrainingPod("chat-old", 2*time.Hour, true), *newDrainingPod("chat-new", time.Hour, true), *newDrainingPod("chat-unready", 4*time.Hour, false), *draining}
+
+	assert.Nil(t, getRemovedPods(pods, 4, "apps/v1.Deployment"))
+	assert.Equal(t, map[string]bool{"chat-draining": true}, getRemovedPods(pods, 3, "apps/v1.Deployment"))
+	assert.Equal(t, map[string]bool{"chat-draining": true, "chat-unready": true, "chat-new": true}, getRemovedPods(pods, 1, "apps/v1.Deployment"))
+
+	statefulPods := []corev1.Pod{*newDrainingPod("chat-2", 0, true), *newDrainingPod("chat-10", 0, true), *newDrainingPod("chat-9", 0, false), *newDrainingPod("chat-0", 0, true)}
+	assert.Equal(t, map[string]bool{"chat-10": true, "chat-9": true}, getRemovedPods(statefulPods, 2, "apps/v1.StatefulSet"))
+}
+
+func TestDrainPods(t *testing.T) {
+	ctx := context.TODO()
+	timeout := int32(300)
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "chat", Namespace: "shop"},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "chat"},
+			Advanced:       &v1alpha1.AdvancedConfig{Draining: &v1alpha1.Draining{TimeoutSeconds: &timeout}},
+		},
+		Status: v1alpha1.ScaledObjectStatus{ScaleTargetKind: "apps/v1.Deployment"},
+	}
+	scale := &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: "app=chat"}}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newDrainingPod("chat-old", 2*time.Hour, true), newDrainingPod("chat-new", time.Hour, true)).Build()
+	e := &scaleExecutor{client: client, recorder: record.NewFakeRecorder(10), logger: logr.DiscardLogger{}}
+
+	getPod := func(name string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		assert.NoError(t, client.Get(ctx, runtimeclient.ObjectKey{Namespace: "shop", Name: name}, pod))
+		return pod
+	}
+
+	// the newest pod is labeled and waited for
+	_, draining := e.drainPods(ctx, logr.DiscardLogger{}, scaledObject, scale, 1)
+	assert.True(t, draining)
+	pod := getPod("chat-new")
+	assert.Equal(t, "true", pod.Labels[v1alpha1.PodDrainingLabel])
+	assert.Equal(t, drainingPodDeletionCost, pod.Annotations[podDeletionCostAnnotation])
+	assert.NotContains(t, getPod("chat-old").Labels, v1alpha1.PodDrainingLabel)
+
+	// the pod signals it is drained
+	patch := runtimeclient.MergeFrom(pod.DeepCopy())
+	pod.Annotations[v1alpha1.PodDrainedAnnotation] = "true"
+	assert.NoError(t, client.Patch(ctx, pod, patch))
+	_, draining = e.drainPods(ctx, logr.DiscardLogger{}, scaledObject, scale, 1)
+	assert.False(t, draining)
+
+	// the triggers became active again, the pod isn't draining anymore
+	e.clearDrainingPods(ctx, logr.DiscardLogger{}, scaledObject, scale)
+	pod = getPod("chat-new")
+	assert.NotContains(t, pod.Labels, v1alpha1.PodDrainingLabel)
+	assert.NotContains(t, pod.Annotations, v1alpha1.PodDrainedAnnotation)
+	assert.NotContains(t, pod.Annotations, podDeletionCostAnnotation)
+
+	// the readiness condition waits for the pod to be not ready, until the timeout
+	scaledObject.Spec.Advanced.Draining.Condition = v1alpha1.DrainingReadiness
+	_, draining = e.drainPods(ctx, logr.DiscardLogger{}, scaledObject, scale, 1)
+	assert.True(t, draining)
+	pod = getPod("chat-new")
+	patch = runtimeclient.MergeFrom(pod.DeepCopy())
+	pod.Annotations[v1alpha1.PodDrainingSinceAnnotation] = time.Now().Add(-6 * time.Minute).UTC().Format(time.RFC3339)
+	assert.NoError(t, client.Patch(ctx, pod, patch))
+	_, draining = e.drainPods(ctx, logr.DiscardLogger{}, scaledObject, scale, 1)
+	assert.False(t, draining)
+
+	// without draining the scale down isn't delayed
+	scaledObject.Spec.Advanced.Draining = nil
+	_, draining = e.drainPods(ctx, logr.DiscardLogger{}, scaledObject, scale, 0)
+	assert.False(t, draining)
+}
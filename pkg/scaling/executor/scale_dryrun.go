@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+const (
+	// Default maxReplicaCount, it matches the default of the HPA created by KEDA
+	defaultMaxReplicaCount int32 = 100
+)
+
+// DesiredReplicaCountFunc returns the replica count the HPA would request for the current metrics
+type DesiredReplicaCountFunc func(ctx context.Context, currentReplicas int32) (int32, error)
+
+// RequestDryRunScale computes the replica count KEDA and the HPA would scale the ScaledObject to,
+// the result is recorded in the ScaledObject status and in an Event, the scale target is never modified
+func (e *scaleExecutor) RequestDryRunScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, desiredReplicaCount DesiredReplicaCountFunc) {
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name,
+		"dryRun", true)
+
+	_, currentReplicas, err := e.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
+		return
+	}
+
+	replicas := e.getDryRunReplicaCount(ctx, logger, scaledObject, isActive, isError, currentReplicas, desiredReplicaCount)
+
+	if isActive {
+		if err := e.updateLastActiveTime(ctx, logger, scaledObject); err != nil {
+			logger.Error(err, "Error updating last active time")
+			return
+		}
+	}
+
+	if scaledObject.Status.DryRunReplicaCount == nil || *scaledObject.Status.DryRunReplicaCount != replicas {
+		logger.Info("Dry-run scale decision", "Current Replicas Count", currentReplicas, "Desired Replicas Count", replicas)
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetDryRun, "Dry-run: would scale %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
+
+		patch := runtimeclient.MergeFrom(scaledObject.DeepCopy())
+		scaledObject.Status.DryRunReplicaCount = &replicas
+		if err := e.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+			logger.Error(err, "Failed to patch Objects Status")
+			return
+		}
+	}
+
+	condition := scaledObject.Status.Conditions.GetActiveCondition()
+	if condition.IsUnknown() || condition.IsTrue() != isActive {
+		if isActive {
+			if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionTrue, "ScalerActive", "Triggers are active, scaling is not performed in dry-run mode"); err != nil {
+				logger.Error(err, "Error setting active condition when triggers are active")
+			}
+		} else {
+			if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionFalse, "ScalerNotActive", "Triggers are not active, scaling is not performed in dry-run mode"); err != nil {
+				logger.Error(err, "Error setting active condition when triggers are not active")
+			}
+		}
+	}
+}
+
+// EstimateReplicaCount returns the current replica count of the scale target and the replica count
+// KEDA and the HPA are expected to scale it to, the scale target is not modified
+func (e *scaleExecutor) EstimateReplicaCount(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, desiredReplicaCount DesiredReplicaCountFunc) (int32, int32, error) {
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	_, currentReplicas, err := e.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		return 0, 0, err
+	}
+	return currentReplicas, e.getDryRunReplicaCount(ctx, logger, scaledObject, isActive, isError, currentReplicas, desiredReplicaCount), nil
+}
+
+// getDryRunReplicaCount mirrors the decisions of RequestScale and of the HPA
+func (e *scaleExecutor) getDryRunReplicaCount(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, currentReplicas int32, desiredReplicaCount DesiredReplicaCountFunc) int32 {
+	minReplicas := int32(0)
+	if scaledObject.Spec.MinReplicaCount != nil {
+		minReplicas = *scaledObject.Spec.MinReplicaCount
+	}
+	maxReplicas := defaultMaxReplicaCount
+	if scaledObject.Spec.MaxReplicaCount != nil {
+		maxReplicas = *scaledObject.Spec.MaxReplicaCount
+	}
+
+	if !isActive {
+		switch {
+		case isError && scaledObject.Spec.Fallback.IsCurrentReplicasIfError():
+			return currentReplicas
+		case isError && scaledObject.Spec.Fallback != nil && scaledObject.Spec.Fallback.Replicas != 0:
+			return scaledObject.Spec.Fallback.Replicas
+		case (scaledObject.Spec.IdleReplicaCount != nil || minReplicas == 0) && !isCoolingDown(scaledObject):
+			_, replicas := getIdleOrMinimumReplicaCount(scaledObject)
+			return replicas
+		}
+	}
+
+	// the HPA is in charge between minReplicaCount (at least 1) and maxReplicaCount
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	replicas, err := desiredReplicaCount(ctx, currentReplicas)
+	if err != nil {
+		logger.Error(err, "Error computing desired replica count from metrics, keeping the current replica count")
+		replicas = currentReplicas
+	}
+	if replicas < minReplicas {
+		return minReplicas
+	}
+	if replicas > maxReplicas {
+		return maxReplicas
+	}
+	return replicas
+}
+
+// isCoolingDown returns true if a trigger was active during the last cooldownPeriod (or scaleToZeroGracePeriod)
+func isCoolingDown(scaledObject *kedav1alpha1.ScaledObject) bool {
+	cooldownPeriod := getScaleToZeroGracePeriod(scaledObject)
+	return scaledObject.Status.LastActiveTime != nil && scaledObject.Status.LastActiveTime.Add(cooldownPeriod).After(time.Now())
+}
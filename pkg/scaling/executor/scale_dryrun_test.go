@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scale"
+)
+
+func newDryRunScaledObject(minReplicas, maxReplicas int32) v1alpha1.ScaledObject {
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+			MinReplicaCount: &minReplicas,
+			MaxReplicaCount: &maxReplicas,
+			Advanced: &v1alpha1.AdvancedConfig{
+				DryRun: true,
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+	return scaledObject
+}
+
+func TestDryRunScaleRecordsDesiredReplicasWithoutScaling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	// no expectations, the scale target must not be touched
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+	scaledObject := newDryRunScaledObject(0, 5)
+
+	numberOfReplicas := int32(2)
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &numberOfReplicas,
+		},
+	})
+	client.EXPECT().Status().Times(3).Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	desiredReplicaCount := func(ctx context.Context, currentReplicas int32) (int32, error) {
+		assert.Equal(t, numberOfReplicas, currentReplicas)
+		return 8, nil
+	}
+	scaleExecutor.RequestDryRunScale(context.TODO(), &scaledObject, true, false, desiredReplicaCount)
+
+	assert.Equal(t, int32(5), *scaledObject.Status.DryRunReplicaCount)
+	assert.Equal(t, "Normal KEDAScaleTargetDryRun Dry-run: would scale  namespace/name from 2 to 5", <-recorder.Events)
+	condition := scaledObject.Status.Conditions.GetActiveCondition()
+	assert.True(t, condition.IsTrue())
+}
+
+func TestDryRunReplicaCount(t *testing.T) {
+	e := &scaleExecutor{logger: logr.DiscardLogger{}}
+	ctx := context.TODO()
+	desired := func(replicas int32) DesiredReplicaCountFunc {
+		return func(context.Context, int32) (int32, error) {
+			return replicas, nil
+		}
+	}
+
+	scaledObject := newDryRunScaledObject(0, 10)
+	assert.Equal(t, int32(0), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, false, false, 3, desired(4)))
+	assert.Equal(t, int32(4), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, true, false, 3, desired(4)))
+	assert.Equal(t, int32(1), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, true, false, 0, desired(0)))
+	assert.Equal(t, int32(10), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, true, false, 3, desired(40)))
+
+	scaledObject.Spec.Fallback = &v1alpha1.Fallback{FailureThreshold: 3, Replicas: 6}
+	assert.Equal(t, int32(6), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, false, true, 3, desired(4)))
+
+	now := v1.Now()
+	scaledObject.Status.LastActiveTime = &now
+	assert.Equal(t, int32(4), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, false, false, 3, desired(4)))
+
+	scaledObject = newDryRunScaledObject(2, 10)
+	assert.Equal(t, int32(2), e.getDryRunReplicaCount(ctx, e.logger, &scaledObject, false, false, 3, desired(1)))
+}
@@ -36,10 +36,16 @@ const (
 	defaultCooldownPeriod = 5 * 60 // 5 minutes
 )
 
-// ScaleExecutor contains methods RequestJobScale and RequestScale
+// ScaleExecutor contains methods RequestJobScale, RequestScale, RequestDryRunScale, RequestPreProvisioning, RecordBudget
+// and RecordShadowReplicaCount
 type ScaleExecutor interface {
-	RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64)
+	RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64, urgencyTier *kedav1alpha1.UrgencyTier)
 	RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool)
+	RequestDryRunScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, desiredReplicaCount DesiredReplicaCountFunc)
+	EstimateReplicaCount(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, desiredReplicaCount DesiredReplicaCountFunc) (int32, int32, error)
+	RequestPreProvisioning(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, desiredReplicaCount DesiredReplicaCountFunc)
+	RecordBudget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject)
+	RecordShadowReplicaCount(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, liveReplicas int32, shadowReplicas int32)
 }
 
 type scaleExecutor struct {
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	preActivationHook    = "preActivation"
+	postDeactivationHook = "postDeactivation"
+
+	defaultScalingHookTimeout = 10 * time.Second
+)
+
+// runScalingHook calls the hook of the ScaledObject if it is defined, it returns false
+// if the hook failed and its failure policy doesn't allow to continue
+func (e *scaleExecutor) runScalingHook(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, hookName string, replicas int32) bool {
+	hook := getScalingHook(scaledObject, hookName)
+	if hook == nil {
+		return true
+	}
+
+	err := callScalingHook(ctx, hook, &kedav1alpha1.ScalingHookRequest{
+		Hook:            hookName,
+		Namespace:       scaledObject.Namespace,
+		Name:            scaledObject.Name,
+		ScaleTargetKind: scaledObject.Status.ScaleTargetKind,
+		ScaleTargetName: scaledObject.Spec.ScaleTargetRef.Name,
+		Replicas:        replicas,
+	})
+	if err == nil {
+		logger.V(1).Info("Successfully called scaling hook", "hook", hookName)
+		return true
+	}
+
+	logger.Error(err, "Error calling scaling hook", "hook", hookName, "failurePolicy", hook.FailurePolicy)
+	e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScalingHookFailed, "Scaling hook %s failed: %s", hookName, err)
+	return hook.FailurePolicy == kedav1alpha1.ScalingHookIgnore
+}
+
+func getScalingHook(scaledObject *kedav1alpha1.ScaledObject, hookName string) *kedav1alpha1.ScalingHook {
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.ScalingHooks == nil {
+		return nil
+	}
+	switch hookName {
+	case preActivationHook:
+		return scaledObject.Spec.Advanced.ScalingHooks.PreActivation
+	case postDeactivationHook:
+		return scaledObject.Spec.Advanced.ScalingHooks.PostDeactivation
+	default:
+		return nil
+	}
+}
+
+func callScalingHook(ctx context.Context, hook *kedav1alpha1.ScalingHook, hookRequest *kedav1alpha1.ScalingHookRequest) error {
+	timeout := defaultScalingHookTimeout
+	if hook.TimeoutSeconds != nil && *hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(*hook.TimeoutSeconds) * time.Second
+	}
+
+	body, err := json.Marshal(hookRequest)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := kedautil.CreateHTTPClient(timeout, false).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("hook returned status code %d: %s", resp.StatusCode, message)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func scaledObjectWithHooks(hooks *v1alpha1.ScalingHooks) *v1alpha1.ScaledObject {
+	return &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{Name: "name", Namespace: "namespace"},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "target"},
+			Advanced:       &v1alpha1.AdvancedConfig{ScalingHooks: hooks},
+		},
+		Status: v1alpha1.ScaledObjectStatus{ScaleTargetKind: "apps/v1.Deployment"},
+	}
+}
+
+func TestRunScalingHookSendsRequest(t *testing.T) {
+	var received v1alpha1.ScalingHookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	recorder := record.NewFakeRecorder(1)
+	scaleExecutor := &scaleExecutor{recorder: recorder}
+	scaledObject := scaledObjectWithHooks(&v1alpha1.ScalingHooks{PreActivation: &v1alpha1.ScalingHook{URL: server.URL}})
+
+	assert.True(t, scaleExecutor.runScalingHook(context.TODO(), logr.DiscardLogger{}, scaledObject, preActivationHook, 3))
+	assert.Equal(t, v1alpha1.ScalingHookRequest{
+		Hook:            preActivationHook,
+		Namespace:       "namespace",
+		Name:            "name",
+		ScaleTargetKind: "apps/v1.Deployment",
+		ScaleTargetName: "target",
+		Replicas:        3,
+	}, received)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestRunScalingHookFailurePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for policy, expected := range map[v1alpha1.ScalingHookFailurePolicy]bool{"": false, v1alpha1.ScalingHookFail: false, v1alpha1.ScalingHookIgnore: true} {
+		recorder := record.NewFakeRecorder(1)
+		scaleExecutor := &scaleExecutor{recorder: recorder}
+		scaledObject := scaledObjectWithHooks(&v1alpha1.ScalingHooks{PreActivation: &v1alpha1.ScalingHook{URL: server.URL, FailurePolicy: policy}})
+
+		assert.Equal(t, expected, scaleExecutor.runScalingHook(context.TODO(), logr.DiscardLogger{}, scaledObject, preActivationHook, 1), "failurePolicy %q", policy)
+		assert.Len(t, recorder.Events, 1)
+	}
+}
+
+func TestRunScalingHookWithoutHook(t *testing.T) {
+	scaleExecutor := &scaleExecutor{recorder: record.NewFakeRecorder(1)}
+	scaledObject := scaledObjectWithHooks(&v1alpha1.ScalingHooks{PreActivation: &v1alpha1.ScalingHook{URL: "http://localhost:0"}})
+
+	assert.True(t, scaleExecutor.runScalingHook(context.TODO(), logr.DiscardLogger{}, scaledObject, postDeactivationHook, 0))
+	assert.True(t, scaleExecutor.runScalingHook(context.TODO(), logr.DiscardLogger{}, &v1alpha1.ScaledObject{}, preActivationHook, 1))
+}
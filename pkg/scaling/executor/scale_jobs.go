@@ -20,6 +20,7 @@ import (
 	"context"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	batchv1 "k8s.io/api/batch/v1"
@@ -36,9 +37,19 @@ import (
 const (
 	defaultSuccessfulJobsHistoryLimit = int32(100)
 	defaultFailedJobsHistoryLimit     = int32(100)
+
+	// defaultTargetDrainTime is the time in which the drain strategy plans to process the queue
+	defaultTargetDrainTime = 5 * time.Minute
+	// drainRecentJobCount is the number of recently succeeded Jobs the drain strategy averages
+	drainRecentJobCount = 10
+	// safeToEvictAnnotation tells the cluster-autoscaler whether it can evict a Pod to remove its node
+	safeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	// defaultJobBackoffLimit is the backoffLimit the Job controller sets when it isn't given
+	defaultJobBackoffLimit = 6
 )
 
-func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64) {
+// RequestJobScale creates the Jobs of the ScaledJob, they get the scheduling settings of urgencyTier if it isn't nil
+func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64, urgencyTier *kedav1alpha1.UrgencyTier) {
 	logger := e.logger.WithValues("scaledJob.Name", scaledJob.Name, "scaledJob.Namespace", scaledJob.Namespace)
 
 	runningJobCount := e.getRunningJobCount(ctx, scaledJob)
@@ -46,7 +57,13 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 	logger.Info("Scaling Jobs", "Number of running Jobs", runningJobCount)
 	logger.Info("Scaling Jobs", "Number of pending Jobs ", pendingJobCount)
 
-	effectiveMaxScale := NewScalingStrategy(logger, scaledJob).GetEffectiveMaxScale(maxScale, runningJobCount, pendingJobCount, scaledJob.MaxReplicaCount())
+	strategy := NewScalingStrategy(logger, scaledJob)
+	// the drain strategy plans with the durations of the recently finished Jobs
+	if drain, ok := strategy.(*drainScalingStrategy); ok {
+		drain.averageJobDuration = e.getAverageJobDuration(ctx, scaledJob)
+		logger.V(1).Info("Scaling Jobs", "Average Job duration", drain.averageJobDuration)
+	}
+	effectiveMaxScale := strategy.GetEffectiveMaxScale(maxScale, runningJobCount, pendingJobCount, scaledJob.MaxReplicaCount())
 
 	if effectiveMaxScale < 0 {
 		effectiveMaxScale = 0
@@ -60,11 +77,24 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 		if err != nil {
 			logger.Error(err, "Failed to update last active time")
 		}
-		e.createJobs(ctx, logger, scaledJob, scaleTo, effectiveMaxScale)
+		e.createJobs(ctx, logger, scaledJob, scaleTo, effectiveMaxScale, urgencyTier)
 	} else {
 		logger.V(1).Info("No change in activity")
 	}
 
+	// keep the minimal number of Jobs running, including the ones created by the active triggers
+	createdJobCount := int64(0)
+	if isActive {
+		createdJobCount = scaleTo
+		if createdJobCount > effectiveMaxScale {
+			createdJobCount = effectiveMaxScale
+		}
+	}
+	if missingJobCount := getMissingMinJobCount(scaledJob, runningJobCount+createdJobCount); missingJobCount > 0 {
+		logger.V(1).Info("Creating jobs to keep the minimal number of jobs running", "minReplicaCount", scaledJob.MinReplicaCount())
+		e.createJobs(ctx, logger, scaledJob, missingJobCount, missingJobCount, urgencyTier)
+	}
+
 	condition := scaledJob.Status.Conditions.GetActiveCondition()
 	if condition.IsUnknown() || condition.IsTrue() != isActive {
 		if isActive {
@@ -86,7 +116,7 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 	}
 }
 
-func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, scaleTo int64, maxScale int64) {
+func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, scaleTo int64, maxScale int64, urgencyTier *kedav1alpha1.UrgencyTier) {
 	scaledJob.Spec.JobTargetRef.Template.GenerateName = scaledJob.GetName() + "-"
 	if scaledJob.Spec.JobTargetRef.Template.Labels == nil {
 		scaledJob.Spec.JobTargetRef.Template.Labels = map[string]string{}
@@ -110,6 +140,10 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 	for key, value := range scaledJob.ObjectMeta.Labels {
 		labels[key] = value
 	}
+	if urgencyTier != nil {
+		logger.Info("Creating jobs", "Urgency tier", urgencyTier.Name)
+		labels[kedav1alpha1.UrgencyTierLabel] = urgencyTier.Name
+	}
 
 	for i := 0; i < int(scaleTo); i++ {
 		job := &batchv1.Job{
@@ -127,6 +161,7 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 			logger.V(1).Info("Job RestartPolicy is not set, setting it to 'OnFailure', to avoid setting it to the client's default value 'Always'")
 			job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
 		}
+		applyUrgencyTier(job, urgencyTier)
 
 		// Set ScaledJob instance as the owner and controller
 		err := controllerutil.SetControllerReference(scaledJob, job, e.reconcilerScheme)
@@ -143,13 +178,109 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 	e.recorder.Eventf(scaledJob, corev1.EventTypeNormal, eventreason.KEDAJobsCreated, "Created %d jobs", scaleTo)
 }
 
+// applyUrgencyTier sets the scheduling settings of the urgency tier on the Job and the template of its Pods
+func applyUrgencyTier(job *batchv1.Job, urgencyTier *kedav1alpha1.UrgencyTier) {
+	if urgencyTier == nil {
+		return
+	}
+	template := &job.Spec.Template
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[kedav1alpha1.UrgencyTierLabel] = urgencyTier.Name
+	if urgencyTier.PriorityClassName != "" {
+		// the priority is resolved from the class at admission, a Pod with another priority is rejected
+		template.Spec.PriorityClassName = urgencyTier.PriorityClassName
+		template.Spec.Priority = nil
+	}
+	if urgencyTier.PreemptionPolicy != nil {
+		preemptionPolicy := *urgencyTier.PreemptionPolicy
+		template.Spec.PreemptionPolicy = &preemptionPolicy
+	}
+	if urgencyTier.TTLSecondsAfterFinished != nil {
+		ttl := *urgencyTier.TTLSecondsAfterFinished
+		job.Spec.TTLSecondsAfterFinished = &ttl
+	}
+	if urgencyTier.SafeToEvict != nil {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[safeToEvictAnnotation] = strconv.FormatBool(*urgencyTier.SafeToEvict)
+	}
+}
+
+// getMissingMinJobCount returns the number of Jobs to create to reach MinReplicaCount, MaxReplicaCount is respected
+func getMissingMinJobCount(scaledJob *kedav1alpha1.ScaledJob, jobCount int64) int64 {
+	minJobCount := scaledJob.MinReplicaCount()
+	if maxJobCount := scaledJob.MaxReplicaCount(); minJobCount > maxJobCount {
+		minJobCount = maxJobCount
+	}
+	if jobCount >= minJobCount {
+		return 0
+	}
+	return minJobCount - jobCount
+}
+
+// getAverageJobDuration returns the average time from the creation to the completion of the recently succeeded Jobs,
+// it includes the time the Jobs were pending
+func (e *scaleExecutor) getAverageJobDuration(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) time.Duration {
+	opts := []client.ListOption{
+		client.InNamespace(scaledJob.GetNamespace()),
+		client.MatchingLabels(map[string]string{"scaledjob.keda.sh/name": scaledJob.GetName()}),
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := e.client.List(ctx, jobs, opts...); err != nil {
+		return 0
+	}
+
+	var completed []batchv1.Job
+	for _, job := range jobs.Items {
+		if job.Status.CompletionTime != nil && !job.Status.CompletionTime.Before(&job.CreationTimestamp) {
+			completed = append(completed, job)
+		}
+	}
+	if len(completed) == 0 {
+		return 0
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Status.CompletionTime.After(completed[j].Status.CompletionTime.Time)
+	})
+	if len(completed) > drainRecentJobCount {
+		completed = completed[:drainRecentJobCount]
+	}
+
+	var total time.Duration
+	for _, job := range completed {
+		total += job.Status.CompletionTime.Sub(job.CreationTimestamp.Time)
+	}
+	return total / time.Duration(len(completed))
+}
+
 func (e *scaleExecutor) isJobFinished(j *batchv1.Job) bool {
 	for _, c := range j.Status.Conditions {
 		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
 			return true
 		}
 	}
-	return false
+	// the Job controller sets the failed condition shortly after the backoffLimit is exhausted, the Job won't
+	// start another Pod in the meantime
+	return j.Status.Failed > getJobBackoffLimit(j)
+}
+
+// isJobRetrying returns whether the Pods of the unfinished Job j failed and it is retrying within its backoffLimit,
+// the item the failed Pod was processing is back in the queue and is processed by the retry
+func isJobRetrying(j *batchv1.Job) bool {
+	return j.Status.Failed > 0 && j.Status.Failed <= getJobBackoffLimit(j)
+}
+
+// getJobBackoffLimit returns the number of retries of the Job j, the Job controller defaults it to 6
+func getJobBackoffLimit(j *batchv1.Job) int32 {
+	if j.Spec.BackoffLimit != nil {
+		return *j.Spec.BackoffLimit
+	}
+	return defaultJobBackoffLimit
 }
 
 func (e *scaleExecutor) getRunningJobCount(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) int64 {
@@ -214,6 +345,10 @@ func (e *scaleExecutor) areAllPendingPodConditionsFulfilled(ctx context.Context,
 	var fulfilledConditionsCount int
 
 	for _, pod := range pods.Items {
+		// the failed attempts of a retrying Job don't tell whether the retry is pending
+		if pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
 		for _, pendingConditionType := range pendingPodConditions {
 			for _, podCondition := range pod.Status.Conditions {
 				if string(podCondition.Type) == pendingConditionType && podCondition.Status == corev1.ConditionTrue {
@@ -245,6 +380,12 @@ func (e *scaleExecutor) getPendingJobCount(ctx context.Context, scaledJob *kedav
 		job := job
 
 		if !e.isJobFinished(&job) {
+			// a retrying Job waiting for its backoff delay has no Pod yet, it will take the item it failed on back
+			// from the queue
+			if isJobRetrying(&job) && job.Status.Active == 0 {
+				pendingJobs++
+				continue
+			}
 			if len(scaledJob.Spec.ScalingStrategy.PendingPodConditions) > 0 {
 				if !e.areAllPendingPodConditionsFulfilled(ctx, &job, scaledJob.Spec.ScalingStrategy.PendingPodConditions) {
 					pendingJobs++
@@ -371,6 +512,13 @@ func NewScalingStrategy(logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) S
 	case "accurate":
 		logger.V(1).Info("Selecting Scale Strategy", "specified", scaledJob.Spec.ScalingStrategy.Strategy, "selected", "accurate")
 		return accurateScalingStrategy{}
+	case "drain":
+		targetDrainTime := defaultTargetDrainTime
+		if scaledJob.Spec.ScalingStrategy.TargetDrainTime != nil && *scaledJob.Spec.ScalingStrategy.TargetDrainTime > 0 {
+			targetDrainTime = time.Duration(*scaledJob.Spec.ScalingStrategy.TargetDrainTime) * time.Second
+		}
+		logger.V(1).Info("Selecting Scale Strategy", "specified", scaledJob.Spec.ScalingStrategy.Strategy, "selected", "drain", "targetDrainTime", targetDrainTime)
+		return &drainScalingStrategy{targetDrainTime: targetDrainTime}
 	default:
 		logger.V(1).Info("Selecting Scale Strategy", "specified", scaledJob.Spec.ScalingStrategy.Strategy, "selected", "default")
 		return defaultScalingStrategy{}
@@ -408,6 +556,27 @@ func (s accurateScalingStrategy) GetEffectiveMaxScale(maxScale, runningJobCount,
 	return maxScale - pendingJobCount
 }
 
+// drainScalingStrategy starts only as many Jobs as are needed to process the queue in targetDrainTime,
+// a Job slot processes targetDrainTime / averageJobDuration Jobs one after another
+type drainScalingStrategy struct {
+	targetDrainTime    time.Duration
+	averageJobDuration time.Duration
+}
+
+func (s *drainScalingStrategy) GetEffectiveMaxScale(maxScale, runningJobCount, pendingJobCount, maxReplicaCount int64) int64 {
+	// without finished Jobs there is nothing to plan with, every queued item gets a Job
+	if s.averageJobDuration <= 0 {
+		return maxScale - runningJobCount
+	}
+
+	jobsPerSlot := int64(s.targetDrainTime / s.averageJobDuration)
+	if jobsPerSlot < 1 {
+		jobsPerSlot = 1
+	}
+	requiredJobCount := (maxScale + jobsPerSlot - 1) / jobsPerSlot
+	return min(requiredJobCount, maxReplicaCount) - runningJobCount
+}
+
 func min(x, y int64) int64 {
 	if x > y {
 		return y
@@ -87,6 +87,22 @@ func TestDefaultScalingStrategy(t *testing.T) {
 	assert.Equal(t, int64(2), strategy.GetEffectiveMaxScale(2, 0, 0, 5))
 }
 
+func TestGetMissingMinJobCount(t *testing.T) {
+	minReplicaCount := int32(3)
+	maxReplicaCount := int32(2)
+	scaledJob := &kedav1alpha1.ScaledJob{}
+	assert.Equal(t, int64(0), getMissingMinJobCount(scaledJob, 0))
+
+	scaledJob.Spec.MinReplicaCount = &minReplicaCount
+	assert.Equal(t, int64(3), getMissingMinJobCount(scaledJob, 0))
+	assert.Equal(t, int64(1), getMissingMinJobCount(scaledJob, 2))
+	assert.Equal(t, int64(0), getMissingMinJobCount(scaledJob, 5))
+
+	// MaxReplicaCount wins
+	scaledJob.Spec.MaxReplicaCount = &maxReplicaCount
+	assert.Equal(t, int64(2), getMissingMinJobCount(scaledJob, 0))
+}
+
 func TestCustomScalingStrategy(t *testing.T) {
 	logger := logf.Log.WithName("ScaledJobTest")
 	customScalingQueueLengthDeduction := int32(1)
@@ -133,6 +149,54 @@ func TestAccurateScalingStrategy(t *testing.T) {
 	assert.Equal(t, int64(1), strategy.GetEffectiveMaxScale(5, 4, 2, 5))
 }
 
+func TestDrainScalingStrategy(t *testing.T) {
+	logger := logf.Log.WithName("ScaledJobTest")
+	targetDrainTime := int32(600)
+	scaledJob := getMockScaledJobWithStrategy("drain", "drain", 0, "0")
+	scaledJob.Spec.ScalingStrategy.TargetDrainTime = &targetDrainTime
+	strategy := NewScalingStrategy(logger, scaledJob)
+	assert.Equal(t, "*executor.drainScalingStrategy", fmt.Sprintf("%T", strategy))
+
+	// no finished Jobs, behaves like the default strategy
+	assert.Equal(t, int64(18), strategy.GetEffectiveMaxScale(20, 2, 0, 100))
+
+	// a Job takes 2 minutes, 5 Jobs per slot process the queue in 10 minutes
+	strategy.(*drainScalingStrategy).averageJobDuration = 2 * time.Minute
+	assert.Equal(t, int64(2), strategy.GetEffectiveMaxScale(20, 2, 0, 100))
+	assert.Equal(t, int64(0), strategy.GetEffectiveMaxScale(20, 4, 0, 100))
+	assert.Equal(t, int64(1), strategy.GetEffectiveMaxScale(20, 2, 0, 3))
+
+	// Jobs longer than the target drain time, every queued item gets a Job
+	strategy.(*drainScalingStrategy).averageJobDuration = 20 * time.Minute
+	assert.Equal(t, int64(18), strategy.GetEffectiveMaxScale(20, 2, 0, 100))
+}
+
+func TestGetAverageJobDuration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	created := metav1.NewTime(time.Date(2021, 11, 15, 12, 0, 0, 0, time.UTC))
+	completed := func(minutes int) *metav1.Time {
+		t := metav1.NewTime(created.Add(time.Duration(minutes) * time.Minute))
+		return &t
+	}
+	client := mock_client.NewMockClient(ctrl)
+	client.EXPECT().
+		List(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, list runtime.Object, _ ...runtimeclient.ListOption) {
+		j := list.(*batchv1.JobList)
+		j.Items = append(j.Items,
+			batchv1.Job{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, Status: batchv1.JobStatus{CompletionTime: completed(2)}},
+			batchv1.Job{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, Status: batchv1.JobStatus{CompletionTime: completed(4)}},
+			// still running
+			batchv1.Job{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}},
+		)
+	}).
+		Return(nil)
+
+	scaleExecutor := getMockScaleExecutor(client)
+	assert.Equal(t, 3*time.Minute, scaleExecutor.getAverageJobDuration(context.Background(), getMockScaledJobWithDefault()))
+}
+
 func TestCleanUpMixedCaseWithSortByTime(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -244,6 +308,71 @@ func TestGetPendingJobCount(t *testing.T) {
 	}
 }
 
+func TestGetPendingJobCountRetryingJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backoffLimit := int32(2)
+	failedPod := v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed, Conditions: []v1.PodCondition{getPodCondition(v1.PodScheduled)}}}
+	retryPod := v1.Pod{Status: v1.PodStatus{Phase: v1.PodRunning, Conditions: []v1.PodCondition{getPodCondition(v1.PodScheduled)}}}
+
+	testData := []struct {
+		status               batchv1.JobStatus
+		pods                 []v1.Pod
+		pendingPodConditions []string
+		pendingJobCount      int64
+		runningJobCount      int64
+	}{
+		// waiting for the backoff delay of the retry
+		{status: batchv1.JobStatus{Failed: 1}, pods: []v1.Pod{failedPod}, pendingJobCount: 1, runningJobCount: 1},
+		{status: batchv1.JobStatus{Failed: 1}, pods: []v1.Pod{failedPod}, pendingPodConditions: []string{"PodScheduled"}, pendingJobCount: 1, runningJobCount: 1},
+		// the retry is running
+		{status: batchv1.JobStatus{Failed: 1, Active: 1}, pods: []v1.Pod{failedPod, retryPod}, pendingPodConditions: []string{"PodScheduled"}, pendingJobCount: 0, runningJobCount: 1},
+		// the backoffLimit is exhausted, the Job is about to fail
+		{status: batchv1.JobStatus{Failed: 3}, pods: []v1.Pod{failedPod, failedPod, failedPod}, pendingJobCount: 0, runningJobCount: 0},
+	}
+
+	for i, test := range testData {
+		job := batchv1.Job{Spec: batchv1.JobSpec{BackoffLimit: &backoffLimit}, Status: test.status}
+		pods := test.pods
+		client := mock_client.NewMockClient(ctrl)
+		client.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, list runtime.Object, _ ...runtimeclient.ListOption) {
+			switch l := list.(type) {
+			case *batchv1.JobList:
+				l.Items = []batchv1.Job{job}
+			case *v1.PodList:
+				l.Items = pods
+			}
+		}).
+			Return(nil).AnyTimes()
+		scaleExecutor := getMockScaleExecutor(client)
+		scaledJob := getMockScaledJobWithPendingPodConditions(test.pendingPodConditions)
+
+		assert.Equal(t, test.pendingJobCount, scaleExecutor.getPendingJobCount(context.Background(), scaledJob), "case %d", i)
+		assert.Equal(t, test.runningJobCount, scaleExecutor.getRunningJobCount(context.Background(), scaledJob), "case %d", i)
+	}
+}
+
+func TestApplyUrgencyTier(t *testing.T) {
+	priority := int32(10)
+	job := &batchv1.Job{Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{PriorityClassName: "batch", Priority: &priority}}}}
+	applyUrgencyTier(job, nil)
+	assert.Equal(t, "batch", job.Spec.Template.Spec.PriorityClassName)
+	assert.Nil(t, job.Spec.Template.Labels)
+
+	preemptionPolicy := v1.PreemptNever
+	ttl := int32(600)
+	safeToEvict := false
+	applyUrgencyTier(job, &kedav1alpha1.UrgencyTier{Name: "urgent", PriorityClassName: "urgent-batch", PreemptionPolicy: &preemptionPolicy, TTLSecondsAfterFinished: &ttl, SafeToEvict: &safeToEvict})
+	assert.Equal(t, "urgent", job.Spec.Template.Labels[kedav1alpha1.UrgencyTierLabel])
+	assert.Equal(t, "urgent-batch", job.Spec.Template.Spec.PriorityClassName)
+	assert.Nil(t, job.Spec.Template.Spec.Priority)
+	assert.Equal(t, v1.PreemptNever, *job.Spec.Template.Spec.PreemptionPolicy)
+	assert.Equal(t, int32(600), *job.Spec.TTLSecondsAfterFinished)
+	assert.Equal(t, "false", job.Spec.Template.Annotations["cluster-autoscaler.kubernetes.io/safe-to-evict"])
+}
+
 type mockJobParameter struct {
 	Name             string
 	CompletionTime   string
@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+const (
+	// placeholderPodImage is the image of the placeholder pods, they only hold the resources of the missing replicas
+	placeholderPodImage = "k8s.gcr.io/pause:3.6"
+)
+
+// RequestPreProvisioning signals the node autoscalers the replicas the scale target is expected to be scaled up by,
+// the scale target is annotated or placeholder pods are created, its replica count is never modified
+func (e *scaleExecutor) RequestPreProvisioning(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, desiredReplicaCount DesiredReplicaCountFunc) {
+	preProvisioning := scaledObject.GetPreProvisioning()
+	if preProvisioning == nil {
+		return
+	}
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	_, currentReplicas, err := e.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
+		return
+	}
+	missingReplicas := e.getMissingReplicas(ctx, logger, scaledObject, isActive, isError, currentReplicas, desiredReplicaCount)
+
+	switch preProvisioning.Mode {
+	case kedav1alpha1.PreProvisioningPlaceholderPods:
+		err = e.reconcilePlaceholderPods(ctx, logger, scaledObject, missingReplicas)
+	default:
+		expectedReplicas := ""
+		if missingReplicas > 0 {
+			expectedReplicas = strconv.Itoa(int(currentReplicas + missingReplicas))
+		}
+		err = e.annotateScaleTarget(ctx, logger, scaledObject, expectedReplicas)
+	}
+	if err != nil {
+		logger.Error(err, "Error updating the pre-provisioning of the scaleTarget")
+		e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAPreProvisioningFailed, "Failed to pre-provision %d replicas of %s %s/%s: %s", missingReplicas, scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, err)
+	}
+}
+
+// getMissingReplicas returns how many replicas the scale target is expected to be scaled up by,
+// 0 unless it is activated or the step up reaches stepUpReplicas
+func (e *scaleExecutor) getMissingReplicas(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, currentReplicas int32, desiredReplicaCount DesiredReplicaCountFunc) int32 {
+	if !isActive {
+		return 0
+	}
+	stepUpReplicas := int32(1)
+	if preProvisioning := scaledObject.GetPreProvisioning(); preProvisioning.StepUpReplicas != nil && *preProvisioning.StepUpReplicas > 0 {
+		stepUpReplicas = *preProvisioning.StepUpReplicas
+	}
+
+	missingReplicas := e.getDryRunReplicaCount(ctx, logger, scaledObject, isActive, isError, currentReplicas, desiredReplicaCount) - currentReplicas
+	if missingReplicas <= 0 || (currentReplicas > 0 && missingReplicas < stepUpReplicas) {
+		return 0
+	}
+	return missingReplicas
+}
+
+// annotateScaleTarget sets the PreProvisioningReplicasAnnotation of the scale target to the expected replicas,
+// the annotation is removed when they are empty
+func (e *scaleExecutor) annotateScaleTarget(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, expectedReplicas string) error {
+	target, err := e.getScaleTargetObject(ctx, scaledObject)
+	if err != nil {
+		return err
+	}
+	if target.GetAnnotations()[kedav1alpha1.PreProvisioningReplicasAnnotation] == expectedReplicas {
+		return nil
+	}
+
+	patch := runtimeclient.MergeFrom(target.DeepCopy())
+	annotations := target.GetAnnotations()
+	if expectedReplicas == "" {
+		delete(annotations, kedav1alpha1.PreProvisioningReplicasAnnotation)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[kedav1alpha1.PreProvisioningReplicasAnnotation] = expectedReplicas
+	}
+	target.SetAnnotations(annotations)
+	if err := e.client.Patch(ctx, target, patch); err != nil {
+		return err
+	}
+	logger.V(1).Info("Updated the pre-provisioning annotation of the scaleTarget", "Expected Replicas Count", expectedReplicas)
+	return nil
+}
+
+// reconcilePlaceholderPods creates or deletes placeholder pods until there is one per missing replica
+func (e *scaleExecutor) reconcilePlaceholderPods(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, missingReplicas int32) error {
+	pods := &corev1.PodList{}
+	if err := e.client.List(ctx, pods, runtimeclient.InNamespace(scaledObject.Namespace), runtimeclient.MatchingLabels{kedav1alpha1.PreProvisioningPlaceholderLabel: scaledObject.Name}); err != nil {
+		return err
+	}
+	var placeholders []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil {
+			placeholders = append(placeholders, pod)
+		}
+	}
+
+	placeholderCount := int32(len(placeholders))
+	for ; placeholderCount > missingReplicas; placeholderCount-- {
+		if err := e.client.Delete(ctx, &placeholders[placeholderCount-1]); runtimeclient.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	if placeholderCount == missingReplicas {
+		return nil
+	}
+
+	priorityClassName := scaledObject.GetPreProvisioning().PriorityClassName
+	if priorityClassName == "" {
+		return errors.New("priorityClassName is required by the PlaceholderPods mode")
+	}
+	target, err := e.getScaleTargetObject(ctx, scaledObject)
+	if err != nil {
+		return err
+	}
+	template, err := getScaleTargetPodTemplate(target)
+	if err != nil {
+		return err
+	}
+	for ; placeholderCount < missingReplicas; placeholderCount++ {
+		if err := e.client.Create(ctx, newPlaceholderPod(scaledObject, template, priorityClassName)); err != nil {
+			return err
+		}
+	}
+	logger.Info("Created placeholder pods for the missing replicas of the scaleTarget", "Placeholder Pods Count", missingReplicas)
+	return nil
+}
+
+// getScaleTargetObject returns the scale target, whatever its kind
+func (e *scaleExecutor) getScaleTargetObject(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (*unstructured.Unstructured, error) {
+	target := &unstructured.Unstructured{}
+	target.SetGroupVersionKind(scaledObject.Status.ScaleTargetGVKR.GroupVersionKind())
+	err := e.client.Get(ctx, runtimeclient.ObjectKey{Name: scaledObject.Spec.ScaleTargetRef.Name, Namespace: scaledObject.GetScaleTargetNamespace()}, target)
+	return target, err
+}
+
+// getScaleTargetPodTemplate returns the spec.template of the scale target, eg. of a Deployment or a StatefulSet
+func getScaleTargetPodTemplate(target *unstructured.Unstructured) (*corev1.PodTemplateSpec, error) {
+	object, found, err := unstructured.NestedMap(target.Object, "spec", "template")
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s has no pod template to pre-provision", target.GetKind(), target.GetName())
+	}
+	template := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// newPlaceholderPod returns a pause pod requesting the resources of a replica with its scheduling constraints,
+// the labels of the template are not copied so the placeholder is never adopted by the scale target
+func newPlaceholderPod(scaledObject *kedav1alpha1.ScaledObject, template *corev1.PodTemplateSpec, priorityClassName string) *corev1.Pod {
+	requests := corev1.ResourceList{}
+	for _, container := range template.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	gracePeriod := int64(0)
+	automountToken := false
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    scaledObject.Name + "-pre-provisioning-",
+			Namespace:       scaledObject.Namespace,
+			Labels:          map[string]string{kedav1alpha1.PreProvisioningPlaceholderLabel: scaledObject.Name},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(scaledObject, kedav1alpha1.GroupVersion.WithKind("ScaledObject"))},
+		},
+		Spec: corev1.PodSpec{
+			PriorityClassName:             priorityClassName,
+			NodeSelector:                  template.Spec.NodeSelector,
+			Affinity:                      template.Spec.Affinity,
+			Tolerations:                   template.Spec.Tolerations,
+			RuntimeClassName:              template.Spec.RuntimeClassName,
+			TerminationGracePeriodSeconds: &gracePeriod,
+			AutomountServiceAccountToken:  &automountToken,
+			Containers: []corev1.Container{{
+				Name:      "pause",
+				Image:     placeholderPodImage,
+				Resources: corev1.ResourceRequirements{Requests: requests},
+			}},
+		},
+	}
+}
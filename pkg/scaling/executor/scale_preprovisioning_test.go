@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func newPreProvisioningScaledObject(preProvisioning *v1alpha1.PreProvisioning) *v1alpha1.ScaledObject {
+	maxReplicas := int32(10)
+	return &v1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop", UID: "uid"},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef:  &v1alpha1.ScaleTarget{Name: "orders"},
+			MaxReplicaCount: &maxReplicas,
+			Advanced:        &v1alpha1.AdvancedConfig{PreProvisioning: preProvisioning},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetKind: "apps/v1.Deployment",
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"},
+		},
+	}
+}
+
+func newPreProvisioningDeployment(replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "orders"}},
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{"pool": "workers"},
+					Containers: []corev1.Container{
+						{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}}},
+						{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func desiredReplicas(replicas int32) DesiredReplicaCountFunc {
+	return func(context.Context, int32) (int32, error) {
+		return replicas, nil
+	}
+}
+
+func TestPreProvisioningMissingReplicas(t *testing.T) {
+	e := &scaleExecutor{logger: logr.DiscardLogger{}}
+	ctx := context.TODO()
+	stepUpReplicas := int32(3)
+	scaledObject := newPreProvisioningScaledObject(&v1alpha1.PreProvisioning{StepUpReplicas: &stepUpReplicas})
+
+	assert.Equal(t, int32(0), e.getMissingReplicas(ctx, e.logger, scaledObject, false, false, 0, desiredReplicas(4)))
+	// the activation is always signaled
+	assert.Equal(t, int32(2), e.getMissingReplicas(ctx, e.logger, scaledObject, true, false, 0, desiredReplicas(2)))
+	assert.Equal(t, int32(0), e.getMissingReplicas(ctx, e.logger, scaledObject, true, false, 2, desiredReplicas(4)))
+	assert.Equal(t, int32(4), e.getMissingReplicas(ctx, e.logger, scaledObject, true, false, 2, desiredReplicas(6)))
+	assert.Equal(t, int32(8), e.getMissingReplicas(ctx, e.logger, scaledObject, true, false, 2, desiredReplicas(40)))
+	assert.Equal(t, int32(0), e.getMissingReplicas(ctx, e.logger, scaledObject, true, false, 6, desiredReplicas(2)))
+}
+
+func TestPreProvisioningAnnotatesScaleTarget(t *testing.T) {
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newPreProvisioningDeployment(2)).Build()
+	e := NewScaleExecutor(client, nil, nil, record.NewFakeRecorder(1))
+	scaledObject := newPreProvisioningScaledObject(&v1alpha1.PreProvisioning{})
+	deployment := &appsv1.Deployment{}
+	key := runtimeclient.ObjectKey{Name: "orders", Namespace: "shop"}
+
+	e.RequestPreProvisioning(context.TODO(), scaledObject, true, false, desiredReplicas(6))
+	assert.NoError(t, client.Get(context.TODO(), key, deployment))
+	assert.Equal(t, "6", deployment.Annotations[v1alpha1.PreProvisioningReplicasAnnotation])
+
+	e.RequestPreProvisioning(context.TODO(), scaledObject, true, false, desiredReplicas(2))
+	assert.NoError(t, client.Get(context.TODO(), key, deployment))
+	assert.NotContains(t, deployment.Annotations, v1alpha1.PreProvisioningReplicasAnnotation)
+}
+
+func TestPreProvisioningPlaceholderPods(t *testing.T) {
+	if err := v1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newPreProvisioningDeployment(2)).Build()
+	recorder := record.NewFakeRecorder(1)
+	e := NewScaleExecutor(client, nil, nil, recorder)
+	scaledObject := newPreProvisioningScaledObject(&v1alpha1.PreProvisioning{Mode: v1alpha1.PreProvisioningPlaceholderPods, PriorityClassName: "placeholder"})
+	listPlaceholders := func() []corev1.Pod {
+		pods := &corev1.PodList{}
+		assert.NoError(t, client.List(context.TODO(), pods, runtimeclient.MatchingLabels{v1alpha1.PreProvisioningPlaceholderLabel: "orders"}))
+		return pods.Items
+	}
+
+	e.RequestPreProvisioning(context.TODO(), scaledObject, true, false, desiredReplicas(5))
+	placeholders := listPlaceholders()
+	assert.Len(t, placeholders, 3)
+	pod := placeholders[0]
+	assert.Equal(t, "placeholder", pod.Spec.PriorityClassName)
+	assert.Equal(t, map[string]string{"pool": "workers"}, pod.Spec.NodeSelector)
+	assert.NotContains(t, pod.Labels, "app")
+	assert.Equal(t, "orders", pod.OwnerReferences[0].Name)
+	requests := pod.Spec.Containers[0].Resources.Requests
+	assert.Equal(t, int64(600), requests.Cpu().MilliValue())
+	assert.Equal(t, int64(1<<30), requests.Memory().Value())
+
+	e.RequestPreProvisioning(context.TODO(), scaledObject, true, false, desiredReplicas(3))
+	assert.Len(t, listPlaceholders(), 1)
+
+	e.RequestPreProvisioning(context.TODO(), scaledObject, false, false, desiredReplicas(3))
+	assert.Len(t, listPlaceholders(), 0)
+
+	scaledObject.Spec.Advanced.PreProvisioning.PriorityClassName = ""
+	e.RequestPreProvisioning(context.TODO(), scaledObject, true, false, desiredReplicas(5))
+	assert.Len(t, listPlaceholders(), 0)
+	assert.Equal(t, "Warning KEDAPreProvisioningFailed Failed to pre-provision 3 replicas of apps/v1.Deployment shop/orders: priorityClassName is required by the PlaceholderPods mode", <-recorder.Events)
+}
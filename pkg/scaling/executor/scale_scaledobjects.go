@@ -36,37 +36,10 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 		"scaledObject.Namespace", scaledObject.Namespace,
 		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
 
-	// Get the current replica count. As a special case, Deployments and StatefulSets fetch directly from the object so they can use the informer cache
-	// to reduce API calls. Everything else uses the scale subresource.
-	var currentScale *autoscalingv1.Scale
-	var currentReplicas int32
-	targetName := scaledObject.Spec.ScaleTargetRef.Name
-	targetGVKR := scaledObject.Status.ScaleTargetGVKR
-	switch {
-	case targetGVKR.Group == "apps" && targetGVKR.Kind == "Deployment":
-		deployment := &appsv1.Deployment{}
-		err := e.client.Get(ctx, client.ObjectKey{Name: targetName, Namespace: scaledObject.Namespace}, deployment)
-		if err != nil {
-			logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
-			return
-		}
-		currentReplicas = *deployment.Spec.Replicas
-	case targetGVKR.Group == "apps" && targetGVKR.Kind == "StatefulSet":
-		statefulSet := &appsv1.StatefulSet{}
-		err := e.client.Get(ctx, client.ObjectKey{Name: targetName, Namespace: scaledObject.Namespace}, statefulSet)
-		if err != nil {
-			logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
-			return
-		}
-		currentReplicas = *statefulSet.Spec.Replicas
-	default:
-		var err error
-		currentScale, err = e.getScaleTargetScale(ctx, scaledObject)
-		if err != nil {
-			logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
-			return
-		}
-		currentReplicas = currentScale.Spec.Replicas
+	currentScale, currentReplicas, err := e.getCurrentReplicas(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
+		return
 	}
 
 	// if scaledObject.Spec.MinReplicaCount is not set, then set the default value (0)
@@ -76,6 +49,8 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 	}
 
 	if isActive {
+		e.clearDrainingPods(ctx, logger, scaledObject, currentScale)
+
 		switch {
 		case scaledObject.Spec.IdleReplicaCount != nil && currentReplicas < minReplicas,
 			// triggers are active, Idle Replicas mode is enabled
@@ -102,6 +77,16 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 	} else {
 		// isActive == false
 		switch {
+		case isError && scaledObject.Spec.Fallback.IsCurrentReplicasIfError():
+			// there are no active triggers, but a scaler responded with an error
+			// AND
+			// the fallback keeps the current replicas count
+
+			// Don't scale the ScaleTarget to zero while the triggers can't be evaluated
+			logger.V(1).Info("ScaleTarget replicas count is kept because of a scaler error", "Replicas Count", currentReplicas)
+			if e := e.setFallbackCondition(ctx, logger, scaledObject, metav1.ConditionTrue, "FallbackExists", "At least one trigger is falling back on this scaled object"); e != nil {
+				logger.Error(e, "Error setting fallback condition")
+			}
 		case isError && scaledObject.Spec.Fallback != nil && scaledObject.Spec.Fallback.Replicas != 0:
 			// there are no active triggers, but a scaler responded with an error
 			// AND
@@ -140,9 +125,12 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			// AND
 			// nothing needs to be done (eg. deployment is scaled down)
 			logger.V(1).Info("ScaleTarget no change")
+			e.clearDrainingPods(ctx, logger, scaledObject, currentScale)
 		}
 	}
 
+	e.scaleAdditionalTargets(ctx, logger, scaledObject)
+
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
 	if condition.IsUnknown() || condition.IsTrue() != isActive {
 		if isActive {
@@ -160,27 +148,38 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 }
 
 func (e *scaleExecutor) doFallbackScaling(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, currentScale *autoscalingv1.Scale, logger logr.Logger, currentReplicas int32) {
+	fallbackCondition := scaledObject.Status.Conditions.GetFallbackCondition()
 	_, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, scaledObject.Spec.Fallback.Replicas)
 	if err == nil {
 		logger.Info("Successfully set ScaleTarget replicas count to ScaledObject fallback.replicas",
 			"Original Replicas Count", currentReplicas,
 			"New Replicas Count", scaledObject.Spec.Fallback.Replicas)
+		if !fallbackCondition.IsTrue() {
+			e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScaleTargetFallback, "Scaled %s %s/%s from %d to fallback %d", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, scaledObject.Spec.Fallback.Replicas)
+		}
 	}
 	if e := e.setFallbackCondition(ctx, logger, scaledObject, metav1.ConditionTrue, "FallbackExists", "At least one trigger is falling back on this scaled object"); e != nil {
 		logger.Error(e, "Error setting fallback condition")
 	}
 }
 
+// getScaleToZeroGracePeriod returns how long the triggers have to be inactive before the scale target is scaled
+// to zero or idle, the scaleToZeroGracePeriod if it is set and the cooldownPeriod otherwise
+func getScaleToZeroGracePeriod(scaledObject *kedav1alpha1.ScaledObject) time.Duration {
+	switch {
+	case scaledObject.Spec.ScaleToZeroGracePeriod != nil:
+		return time.Second * time.Duration(*scaledObject.Spec.ScaleToZeroGracePeriod)
+	case scaledObject.Spec.CooldownPeriod != nil:
+		return time.Second * time.Duration(*scaledObject.Spec.CooldownPeriod)
+	default:
+		return time.Second * time.Duration(defaultCooldownPeriod)
+	}
+}
+
 // An object will be scaled down to 0 only if it's passed its cooldown period
-// or if LastActiveTime is nil
+// (or scaleToZeroGracePeriod) or if LastActiveTime is nil
 func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale) {
-	var cooldownPeriod time.Duration
-
-	if scaledObject.Spec.CooldownPeriod != nil {
-		cooldownPeriod = time.Second * time.Duration(*scaledObject.Spec.CooldownPeriod)
-	} else {
-		cooldownPeriod = time.Second * time.Duration(defaultCooldownPeriod)
-	}
+	cooldownPeriod := getScaleToZeroGracePeriod(scaledObject)
 
 	// LastActiveTime can be nil if the ScaleTarget was scaled outside of KEDA.
 	// In this case we will ignore the cooldown period and scale it down
@@ -188,8 +187,30 @@ func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logge
 		scaledObject.Status.LastActiveTime.Add(cooldownPeriod).Before(time.Now()) {
 		// or last time a trigger was active was > cooldown period, so scale down.
 
+		var busy bool
+		if scale, busy = e.waitForBusyPods(ctx, logger, scaledObject, scale, cooldownPeriod); busy {
+			activeCondition := scaledObject.Status.Conditions.GetActiveCondition()
+			if !activeCondition.IsFalse() || activeCondition.Reason != "ScalerBusyPods" {
+				if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionFalse, "ScalerBusyPods", "Scale down is delayed because pods of the scale target are busy"); err != nil {
+					logger.Error(err, "Error in setting active condition")
+				}
+			}
+			return
+		}
+
 		idleValue, scaleToReplicas := getIdleOrMinimumReplicaCount(scaledObject)
 
+		var draining bool
+		if scale, draining = e.drainPods(ctx, logger, scaledObject, scale, scaleToReplicas); draining {
+			activeCondition := scaledObject.Status.Conditions.GetActiveCondition()
+			if !activeCondition.IsFalse() || activeCondition.Reason != "ScalerDrainingPods" {
+				if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionFalse, "ScalerDrainingPods", "Scale down is delayed until the pods to remove are drained"); err != nil {
+					logger.Error(err, "Error in setting active condition")
+				}
+			}
+			return
+		}
+
 		currentReplicas, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, scaleToReplicas)
 		if err == nil {
 			msg := "Successfully set ScaleTarget replicas count to ScaledObject"
@@ -201,14 +222,16 @@ func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logge
 			logger.Info(msg, "Original Replicas Count", currentReplicas, "New Replicas Count", scaleToReplicas)
 
 			e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetDeactivated,
-				"Deactivated %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, scaleToReplicas)
+				"Deactivated %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, scaleToReplicas)
+			// a failed postDeactivation hook can't undo the deactivation, it is only reported
+			e.runScalingHook(ctx, logger, scaledObject, postDeactivationHook, scaleToReplicas)
 			if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionFalse, "ScalerNotActive", "Scaling is not performed because triggers are not active"); err != nil {
 				logger.Error(err, "Error in setting active condition")
 				return
 			}
 		} else {
 			e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScaleTargetDeactivationFailed,
-				"Failed to deactivated %s %s/%s", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, scaleToReplicas)
+				"Failed to deactivated %s %s/%s", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, scaleToReplicas)
 		}
 	} else {
 		logger.V(1).Info("ScaleTarget cooling down",
@@ -233,13 +256,18 @@ func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Log
 		replicas = 1
 	}
 
+	if !e.runScalingHook(ctx, logger, scaledObject, preActivationHook, replicas) {
+		// the activation is retried in the next polling interval
+		return
+	}
+
 	currentReplicas, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, replicas)
 
 	if err == nil {
 		logger.Info("Successfully updated ScaleTarget",
 			"Original Replicas Count", currentReplicas,
 			"New Replicas Count", replicas)
-		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetActivated, "Scaled %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetActivated, "Scaled %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
 
 		// Scale was successful. Update lastScaleTime and lastActiveTime on the scaledObject
 		if err := e.updateLastActiveTime(ctx, logger, scaledObject); err != nil {
@@ -247,12 +275,39 @@ func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Log
 			return
 		}
 	} else {
-		e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScaleTargetActivationFailed, "Failed to scaled %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
+		e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScaleTargetActivationFailed, "Failed to scaled %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.GetScaleTargetNamespace(), scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
+	}
+}
+
+// getCurrentReplicas returns the current replica count of the scale target. As a special case, Deployments and StatefulSets fetch directly
+// from the object so they can use the informer cache to reduce API calls. Everything else uses the scale subresource, which is returned too.
+func (e *scaleExecutor) getCurrentReplicas(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (*autoscalingv1.Scale, int32, error) {
+	targetName := scaledObject.Spec.ScaleTargetRef.Name
+	targetGVKR := scaledObject.Status.ScaleTargetGVKR
+	switch {
+	case targetGVKR.Group == "apps" && targetGVKR.Kind == "Deployment":
+		deployment := &appsv1.Deployment{}
+		if err := e.client.Get(ctx, client.ObjectKey{Name: targetName, Namespace: scaledObject.GetScaleTargetNamespace()}, deployment); err != nil {
+			return nil, 0, err
+		}
+		return nil, *deployment.Spec.Replicas, nil
+	case targetGVKR.Group == "apps" && targetGVKR.Kind == "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		if err := e.client.Get(ctx, client.ObjectKey{Name: targetName, Namespace: scaledObject.GetScaleTargetNamespace()}, statefulSet); err != nil {
+			return nil, 0, err
+		}
+		return nil, *statefulSet.Spec.Replicas, nil
+	default:
+		currentScale, err := e.getScaleTargetScale(ctx, scaledObject)
+		if err != nil {
+			return nil, 0, err
+		}
+		return currentScale, currentScale.Spec.Replicas, nil
 	}
 }
 
 func (e *scaleExecutor) getScaleTargetScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (*autoscalingv1.Scale, error) {
-	return e.scaleClient.Scales(scaledObject.Namespace).Get(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
+	return e.scaleClient.Scales(scaledObject.GetScaleTargetNamespace()).Get(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
 }
 
 func (e *scaleExecutor) updateScaleOnScaleTarget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, replicas int32) (int32, error) {
@@ -269,7 +324,7 @@ func (e *scaleExecutor) updateScaleOnScaleTarget(ctx context.Context, scaledObje
 	currentReplicas := scale.Spec.Replicas
 	scale.Spec.Replicas = replicas
 
-	_, err := e.scaleClient.Scales(scaledObject.Namespace).Update(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scale, metav1.UpdateOptions{})
+	_, err := e.scaleClient.Scales(scaledObject.GetScaleTargetNamespace()).Update(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scale, metav1.UpdateOptions{})
 	return currentReplicas, err
 }
 
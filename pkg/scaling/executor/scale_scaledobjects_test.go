@@ -19,6 +19,7 @@ package executor
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -94,6 +95,57 @@ func TestScaleToFallbackReplicasWhenNotActiveAndIsError(t *testing.T) {
 	assert.Equal(t, true, condition.IsTrue())
 }
 
+func TestKeepCurrentReplicasWhenNotActiveAndIsErrorWithCurrentReplicasIfError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+			Fallback: &v1alpha1.Fallback{
+				FailureThreshold: 3,
+				Behavior:         v1alpha1.FallbackBehaviorCurrentReplicasIfError,
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+	numberOfReplicas := int32(2)
+
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &numberOfReplicas,
+		},
+	})
+
+	// the ScaleTarget is not scaled, only the conditions are patched
+	client.EXPECT().Status().Times(2).Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, true)
+
+	condition := scaledObject.Status.Conditions.GetFallbackCondition()
+	assert.Equal(t, true, condition.IsTrue())
+}
+
 func TestScaleToMinReplicasWhenNotActive(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	client := mock_client.NewMockClient(ctrl)
@@ -398,3 +450,21 @@ func TestScaleFromIdleToMinReplicasWhenActive(t *testing.T) {
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
 	assert.Equal(t, true, condition.IsTrue())
 }
+
+func TestGetScaleToZeroGracePeriod(t *testing.T) {
+	scaledObject := &v1alpha1.ScaledObject{}
+	assert.Equal(t, 5*time.Minute, getScaleToZeroGracePeriod(scaledObject))
+
+	cooldownPeriod := int32(60)
+	scaledObject.Spec.CooldownPeriod = &cooldownPeriod
+	assert.Equal(t, time.Minute, getScaleToZeroGracePeriod(scaledObject))
+
+	// only the scale to zero waits for the grace period
+	gracePeriod := int32(1800)
+	scaledObject.Spec.ScaleToZeroGracePeriod = &gracePeriod
+	assert.Equal(t, 30*time.Minute, getScaleToZeroGracePeriod(scaledObject))
+
+	lastActiveTime := v1.NewTime(time.Now().Add(-10 * time.Minute))
+	scaledObject.Status.LastActiveTime = &lastActiveTime
+	assert.True(t, isCoolingDown(scaledObject))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+// defaultShadowDuration is how long the shadow triggers are evaluated
+const defaultShadowDuration = 24 * time.Hour
+
+// RecordShadowReplicaCount records the replica counts computed from the shadow triggers and from the triggers in the
+// shadow status of the ScaledObject, an Event is recorded once the shadow triggers were evaluated for their duration
+func (e *scaleExecutor) RecordShadowReplicaCount(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, liveReplicas int32, shadowReplicas int32) {
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	patch := runtimeclient.MergeFrom(scaledObject.DeepCopy())
+	changed, completed := updateShadowStatus(scaledObject, liveReplicas, shadowReplicas, time.Now())
+	if !changed {
+		return
+	}
+	if completed {
+		logger.Info("Shadow triggers evaluated", "Max Replica Difference", scaledObject.Status.Shadow.MaxReplicaDifference)
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAShadowCompleted, "Shadow triggers evaluated since %s, their replica count differed by up to %d from the triggers",
+			scaledObject.Status.Shadow.StartTime.UTC().Format(time.RFC3339), scaledObject.Status.Shadow.MaxReplicaDifference)
+	}
+
+	if err := e.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+		logger.Error(err, "Failed to patch Objects Status")
+	}
+}
+
+// updateShadowStatus updates the shadow status of the ScaledObject, it returns whether the status changed and whether
+// the evaluation completed with this update, the evaluation restarts when the generation of the ScaledObject changes
+func updateShadowStatus(scaledObject *kedav1alpha1.ScaledObject, liveReplicas int32, shadowReplicas int32, now time.Time) (bool, bool) {
+	shadow := scaledObject.Spec.Shadow
+	if shadow == nil {
+		if scaledObject.Status.Shadow == nil {
+			return false, false
+		}
+		scaledObject.Status.Shadow = nil
+		return true, false
+	}
+
+	status := scaledObject.Status.Shadow
+	if status == nil || status.ObservedGeneration != scaledObject.Generation {
+		start := metav1.NewTime(now)
+		status = &kedav1alpha1.ShadowStatus{ObservedGeneration: scaledObject.Generation, StartTime: &start}
+		scaledObject.Status.Shadow = status
+	} else if status.Completed {
+		return false, false
+	}
+
+	duration := defaultShadowDuration
+	if shadow.DurationSeconds != nil {
+		duration = time.Duration(*shadow.DurationSeconds) * time.Second
+	}
+	difference := shadowReplicas - liveReplicas
+	if difference < 0 {
+		difference = -difference
+	}
+	completed := !now.Before(status.StartTime.Add(duration))
+	if status.ReplicaCount != nil && *status.ReplicaCount == shadowReplicas &&
+		status.LiveReplicaCount != nil && *status.LiveReplicaCount == liveReplicas && !completed {
+		return false, false
+	}
+
+	lastUpdate := metav1.NewTime(now)
+	status.LastUpdateTime = &lastUpdate
+	status.ReplicaCount = &shadowReplicas
+	status.LiveReplicaCount = &liveReplicas
+	if difference > status.MaxReplicaDifference {
+		status.MaxReplicaDifference = difference
+	}
+	status.Completed = completed
+	return true, completed
+}
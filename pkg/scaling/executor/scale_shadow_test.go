@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestUpdateShadowStatus(t *testing.T) {
+	duration := int32(3600)
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop", Generation: 3},
+		Spec: v1alpha1.ScaledObjectSpec{
+			Shadow: &v1alpha1.ShadowTriggers{Triggers: []v1alpha1.ScaleTriggers{{Type: "cron"}}, DurationSeconds: &duration},
+		},
+	}
+	start := time.Date(2021, 12, 20, 10, 0, 0, 0, time.UTC)
+
+	// the evaluation starts
+	changed, completed := updateShadowStatus(scaledObject, 4, 6, start)
+	assert.True(t, changed)
+	assert.False(t, completed)
+	status := scaledObject.Status.Shadow
+	assert.Equal(t, int64(3), status.ObservedGeneration)
+	assert.Equal(t, start, status.StartTime.Time)
+	assert.Equal(t, int32(6), *status.ReplicaCount)
+	assert.Equal(t, int32(4), *status.LiveReplicaCount)
+	assert.Equal(t, int32(2), status.MaxReplicaDifference)
+
+	// the same replica counts don't change the status
+	changed, _ = updateShadowStatus(scaledObject, 4, 6, start.Add(time.Minute))
+	assert.False(t, changed)
+
+	// the largest difference is kept
+	changed, _ = updateShadowStatus(scaledObject, 5, 4, start.Add(2*time.Minute))
+	assert.True(t, changed)
+	assert.Equal(t, int32(2), scaledObject.Status.Shadow.MaxReplicaDifference)
+	changed, _ = updateShadowStatus(scaledObject, 9, 4, start.Add(3*time.Minute))
+	assert.True(t, changed)
+	assert.Equal(t, int32(5), scaledObject.Status.Shadow.MaxReplicaDifference)
+
+	// the evaluation completes after its duration and stops
+	changed, completed = updateShadowStatus(scaledObject, 9, 4, start.Add(time.Hour))
+	assert.True(t, changed)
+	assert.True(t, completed)
+	assert.True(t, scaledObject.Status.Shadow.Completed)
+	changed, completed = updateShadowStatus(scaledObject, 1, 8, start.Add(2*time.Hour))
+	assert.False(t, changed)
+	assert.False(t, completed)
+
+	// a change of the ScaledObject restarts the evaluation
+	scaledObject.Generation = 4
+	changed, _ = updateShadowStatus(scaledObject, 1, 8, start.Add(3*time.Hour))
+	assert.True(t, changed)
+	assert.False(t, scaledObject.Status.Shadow.Completed)
+	assert.Equal(t, int32(7), scaledObject.Status.Shadow.MaxReplicaDifference)
+	assert.Equal(t, start.Add(3*time.Hour), scaledObject.Status.Shadow.StartTime.Time)
+
+	// without shadow triggers the status is removed
+	scaledObject.Spec.Shadow = nil
+	changed, _ = updateShadowStatus(scaledObject, 1, 1, start.Add(4*time.Hour))
+	assert.True(t, changed)
+	assert.Nil(t, scaledObject.Status.Shadow)
+}
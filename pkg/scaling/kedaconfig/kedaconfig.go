@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kedaconfig resolves the policy the KedaConfigs and ClusterKedaConfigs set on the ScaledObjects and
+// ScaledJobs of a namespace, ie. the defaults of their fields and the limits they are validated against.
+package kedaconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+)
+
+const (
+	// the defaults of the ScaledObjects and ScaledJobs, used to check the limits when the fields aren't set
+	defaultPollingInterval = 30
+	defaultMaxReplicaCount = 100
+)
+
+// Policy is the merge of the KedaConfigs of a namespace and of the ClusterKedaConfigs
+type Policy struct {
+	// Defaults of the KedaConfigs take precedence over the defaults of the ClusterKedaConfigs,
+	// within a kind the configs are applied in name order
+	Defaults kedav1alpha1.KedaConfigDefaults
+	// Limits are the most restrictive limits of the configs, RequiredAuthenticationProviders is nil if any provider
+	// is allowed and empty if the configs don't allow a common provider
+	Limits kedav1alpha1.KedaConfigLimits
+}
+
+// Resolve returns the Policy of the ScaledObjects and ScaledJobs of the namespace
+func Resolve(ctx context.Context, kubeClient client.Client, namespace string) (*Policy, error) {
+	configs := &kedav1alpha1.KedaConfigList{}
+	if err := kubeClient.List(ctx, configs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("error listing KedaConfigs: %s", err)
+	}
+	clusterConfigs := &kedav1alpha1.ClusterKedaConfigList{}
+	if err := kubeClient.List(ctx, clusterConfigs); err != nil {
+		return nil, fmt.Errorf("error listing ClusterKedaConfigs: %s", err)
+	}
+
+	sort.Slice(configs.Items, func(i, j int) bool { return configs.Items[i].Name < configs.Items[j].Name })
+	sort.Slice(clusterConfigs.Items, func(i, j int) bool { return clusterConfigs.Items[i].Name < clusterConfigs.Items[j].Name })
+
+	policy := &Policy{}
+	for _, config := range configs.Items {
+		policy.merge(config.Spec)
+	}
+	for _, config := range clusterConfigs.Items {
+		policy.merge(config.Spec)
+	}
+	return policy, nil
+}
+
+func (p *Policy) merge(spec kedav1alpha1.KedaConfigSpec) {
+	if defaults := spec.Defaults; defaults != nil {
+		p.Defaults.PollingInterval = defaultValue(p.Defaults.PollingInterval, defaults.PollingInterval)
+		p.Defaults.CooldownPeriod = defaultValue(p.Defaults.CooldownPeriod, defaults.CooldownPeriod)
+		p.Defaults.MaxReplicaCount = defaultValue(p.Defaults.MaxReplicaCount, defaults.MaxReplicaCount)
+	}
+
+	limits := spec.Limits
+	if limits == nil {
+		return
+	}
+	if limits.MaxReplicaCount != nil && (p.Limits.MaxReplicaCount == nil || *limits.MaxReplicaCount < *p.Limits.MaxReplicaCount) {
+		p.Limits.MaxReplicaCount = defaultValue(nil, limits.MaxReplicaCount)
+	}
+	if limits.MinPollingInterval != nil && (p.Limits.MinPollingInterval == nil || *limits.MinPollingInterval > *p.Limits.MinPollingInterval) {
+		p.Limits.MinPollingInterval = defaultValue(nil, limits.MinPollingInterval)
+	}
+	for _, scalerType := range limits.BannedScalerTypes {
+		if !containsString(p.Limits.BannedScalerTypes, scalerType) {
+			p.Limits.BannedScalerTypes = append(p.Limits.BannedScalerTypes, scalerType)
+		}
+	}
+	if len(limits.RequiredAuthenticationProviders) > 0 {
+		if p.Limits.RequiredAuthenticationProviders == nil {
+			p.Limits.RequiredAuthenticationProviders = append([]kedav1alpha1.AuthenticationProvider{}, limits.RequiredAuthenticationProviders...)
+		} else {
+			allowed := []kedav1alpha1.AuthenticationProvider{}
+			for _, provider := range p.Limits.RequiredAuthenticationProviders {
+				if containsProvider(limits.RequiredAuthenticationProviders, provider) {
+					allowed = append(allowed, provider)
+				}
+			}
+			p.Limits.RequiredAuthenticationProviders = allowed
+		}
+	}
+}
+
+// ApplyToScaledObject sets the defaults of the policy on the fields the ScaledObject doesn't set
+func (p *Policy) ApplyToScaledObject(scaledObject *kedav1alpha1.ScaledObject) {
+	scaledObject.Spec.PollingInterval = defaultValue(scaledObject.Spec.PollingInterval, p.Defaults.PollingInterval)
+	scaledObject.Spec.CooldownPeriod = defaultValue(scaledObject.Spec.CooldownPeriod, p.Defaults.CooldownPeriod)
+	scaledObject.Spec.MaxReplicaCount = defaultValue(scaledObject.Spec.MaxReplicaCount, p.Defaults.MaxReplicaCount)
+}
+
+// ApplyToScaledJob sets the defaults of the policy on the fields the ScaledJob doesn't set
+func (p *Policy) ApplyToScaledJob(scaledJob *kedav1alpha1.ScaledJob) {
+	scaledJob.Spec.PollingInterval = defaultValue(scaledJob.Spec.PollingInterval, p.Defaults.PollingInterval)
+	scaledJob.Spec.MaxReplicaCount = defaultValue(scaledJob.Spec.MaxReplicaCount, p.Defaults.MaxReplicaCount)
+}
+
+// ValidateScaledObject returns an error if the ScaledObject breaks the limits of the policy
+func (p *Policy) ValidateScaledObject(ctx context.Context, kubeClient client.Client, scaledObject *kedav1alpha1.ScaledObject) error {
+	return p.validate(ctx, kubeClient, scaledObject.Namespace, scaledObject.Spec.PollingInterval, scaledObject.Spec.MaxReplicaCount, scaledObject.Spec.Triggers)
+}
+
+// ValidateScaledJob returns an error if the ScaledJob breaks the limits of the policy
+func (p *Policy) ValidateScaledJob(ctx context.Context, kubeClient client.Client, scaledJob *kedav1alpha1.ScaledJob) error {
+	return p.validate(ctx, kubeClient, scaledJob.Namespace, scaledJob.Spec.PollingInterval, scaledJob.Spec.MaxReplicaCount, scaledJob.Spec.Triggers)
+}
+
+func (p *Policy) validate(ctx context.Context, kubeClient client.Client, namespace string, pollingInterval, maxReplicaCount *int32, triggers []kedav1alpha1.ScaleTriggers) error {
+	if limit := p.Limits.MaxReplicaCount; limit != nil {
+		value := int32(defaultMaxReplicaCount)
+		if maxReplicaCount != nil {
+			value = *maxReplicaCount
+		}
+		if value > *limit {
+			return fmt.Errorf("maxReplicaCount %d exceeds the limit %d of the KedaConfigs", value, *limit)
+		}
+	}
+	if limit := p.Limits.MinPollingInterval; limit != nil {
+		value := int32(defaultPollingInterval)
+		if pollingInterval != nil {
+			value = *pollingInterval
+		}
+		if value < *limit {
+			return fmt.Errorf("pollingInterval %d is below the limit %d of the KedaConfigs", value, *limit)
+		}
+	}
+
+	if len(p.Limits.BannedScalerTypes) == 0 && p.Limits.RequiredAuthenticationProviders == nil {
+		return nil
+	}
+	for _, trigger := range triggers {
+		trigger, err := resolver.ResolveTriggerTemplate(ctx, kubeClient, trigger)
+		if err != nil {
+			return err
+		}
+		if containsString(p.Limits.BannedScalerTypes, trigger.Type) {
+			return fmt.Errorf("trigger type %s is banned by the KedaConfigs", trigger.Type)
+		}
+		if p.Limits.RequiredAuthenticationProviders != nil && trigger.AuthenticationRef != nil {
+			if err := p.validateAuthenticationRef(ctx, kubeClient, namespace, trigger.AuthenticationRef); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateAuthenticationRef checks the referenced TriggerAuthentication only reads credentials from the allowed providers
+func (p *Policy) validateAuthenticationRef(ctx context.Context, kubeClient client.Client, namespace string, authRef *kedav1alpha1.ScaledObjectAuthRef) error {
+	authRef, err := resolver.SelectAuthRef(ctx, kubeClient, authRef)
+	if err != nil {
+		return err
+	}
+	var spec *kedav1alpha1.TriggerAuthenticationSpec
+	kind := authRef.Kind
+	switch kind {
+	case "", "TriggerAuthentication":
+		kind = "TriggerAuthentication"
+		triggerAuth := &kedav1alpha1.TriggerAuthentication{}
+		if err := kubeClient.Get(ctx, types.NamespacedName{Name: authRef.Name, Namespace: namespace}, triggerAuth); err != nil {
+			return fmt.Errorf("error getting TriggerAuthentication %s: %s", authRef.Name, err)
+		}
+		spec = &triggerAuth.Spec
+	case "ClusterTriggerAuthentication":
+		triggerAuth := &kedav1alpha1.ClusterTriggerAuthentication{}
+		if err := kubeClient.Get(ctx, types.NamespacedName{Name: authRef.Name}, triggerAuth); err != nil {
+			return fmt.Errorf("error getting ClusterTriggerAuthentication %s: %s", authRef.Name, err)
+		}
+		spec = &triggerAuth.Spec
+	default:
+		return fmt.Errorf("unknown trigger auth kind %s", kind)
+	}
+
+	for _, provider := range spec.GetProviders() {
+		if !containsProvider(p.Limits.RequiredAuthenticationProviders, provider) {
+			allowed := make([]string, 0, len(p.Limits.RequiredAuthenticationProviders))
+			for _, provider := range p.Limits.RequiredAuthenticationProviders {
+				allowed = append(allowed, string(provider))
+			}
+			return fmt.Errorf("%s %s reads credentials from %s, the KedaConfigs only allow [%s]", kind, authRef.Name, provider, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// defaultValue returns value, or a copy of def if value isn't set
+func defaultValue(value, def *int32) *int32 {
+	if value != nil || def == nil {
+		return value
+	}
+	v := *def
+	return &v
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsProvider(providers []kedav1alpha1.AuthenticationProvider, provider kedav1alpha1.AuthenticationProvider) bool {
+	for _, p := range providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
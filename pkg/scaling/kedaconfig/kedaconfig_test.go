@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kedaconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+const namespace = "shop"
+
+func testClient(t *testing.T) client.Client {
+	if err := kedav1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Error adding the KEDA types to the scheme: %s", err)
+	}
+	objects := []client.Object{
+		&kedav1alpha1.ClusterKedaConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: kedav1alpha1.KedaConfigSpec{
+				Defaults: &kedav1alpha1.KedaConfigDefaults{PollingInterval: int32Ptr(60), CooldownPeriod: int32Ptr(600)},
+				Limits: &kedav1alpha1.KedaConfigLimits{
+					MaxReplicaCount:                 int32Ptr(50),
+					MinPollingInterval:              int32Ptr(10),
+					BannedScalerTypes:               []string{"external"},
+					RequiredAuthenticationProviders: []kedav1alpha1.AuthenticationProvider{kedav1alpha1.AuthenticationProviderPodIdentity, kedav1alpha1.AuthenticationProviderHashiCorpVault},
+				},
+			},
+		},
+		&kedav1alpha1.KedaConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: namespace},
+			Spec: kedav1alpha1.KedaConfigSpec{
+				Defaults: &kedav1alpha1.KedaConfigDefaults{PollingInterval: int32Ptr(20), MaxReplicaCount: int32Ptr(5)},
+			},
+		},
+		&kedav1alpha1.KedaConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: namespace},
+			Spec: kedav1alpha1.KedaConfigSpec{
+				Defaults: &kedav1alpha1.KedaConfigDefaults{PollingInterval: int32Ptr(15)},
+				Limits: &kedav1alpha1.KedaConfigLimits{
+					// the cluster limit is lower, it can't be relaxed
+					MaxReplicaCount:                 int32Ptr(80),
+					MinPollingInterval:              int32Ptr(15),
+					BannedScalerTypes:               []string{"cron", "external"},
+					RequiredAuthenticationProviders: []kedav1alpha1.AuthenticationProvider{kedav1alpha1.AuthenticationProviderPodIdentity},
+				},
+			},
+		},
+		// the configs of other namespaces don't apply
+		&kedav1alpha1.KedaConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "dev"},
+			Spec: kedav1alpha1.KedaConfigSpec{
+				Limits: &kedav1alpha1.KedaConfigLimits{MaxReplicaCount: int32Ptr(1)},
+			},
+		},
+		&kedav1alpha1.TriggerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-identity", Namespace: namespace},
+			Spec:       kedav1alpha1.TriggerAuthenticationSpec{PodIdentity: &kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzure}},
+		},
+		&kedav1alpha1.TriggerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+			Spec: kedav1alpha1.TriggerAuthenticationSpec{
+				SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{{Parameter: "password", Name: "redis", Key: "password"}},
+			},
+		},
+		&kedav1alpha1.ClusterTriggerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault"},
+			Spec:       kedav1alpha1.TriggerAuthenticationSpec{HashiCorpVault: &kedav1alpha1.HashiCorpVault{Address: "http://vault:8200"}},
+		},
+		&kedav1alpha1.ClusterTriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+			Spec:       kedav1alpha1.ClusterTriggerTemplateSpec{Type: "cron", Metadata: map[string]string{"timezone": "Etc/UTC"}},
+		},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+}
+
+func TestResolve(t *testing.T) {
+	policy, err := Resolve(context.Background(), testClient(t), namespace)
+	assert.NoError(t, err)
+
+	assert.Equal(t, kedav1alpha1.KedaConfigDefaults{PollingInterval: int32Ptr(15), CooldownPeriod: int32Ptr(600), MaxReplicaCount: int32Ptr(5)}, policy.Defaults)
+	assert.Equal(t, int32Ptr(50), policy.Limits.MaxReplicaCount)
+	assert.Equal(t, int32Ptr(15), policy.Limits.MinPollingInterval)
+	assert.ElementsMatch(t, []string{"cron", "external"}, policy.Limits.BannedScalerTypes)
+	assert.Equal(t, []kedav1alpha1.AuthenticationProvider{kedav1alpha1.AuthenticationProviderPodIdentity}, policy.Limits.RequiredAuthenticationProviders)
+}
+
+func TestResolveClusterConfigs(t *testing.T) {
+	policy, err := Resolve(context.Background(), testClient(t), "other")
+	assert.NoError(t, err)
+
+	scaledObject := &kedav1alpha1.ScaledObject{Spec: kedav1alpha1.ScaledObjectSpec{Triggers: []kedav1alpha1.ScaleTriggers{{Type: "external"}}}}
+	policy.ApplyToScaledObject(scaledObject)
+	assert.Equal(t, int32Ptr(60), scaledObject.Spec.PollingInterval)
+	assert.Equal(t, int32Ptr(600), scaledObject.Spec.CooldownPeriod)
+	assert.Nil(t, scaledObject.Spec.MaxReplicaCount)
+	assert.Equal(t, int32Ptr(10), policy.Limits.MinPollingInterval)
+	assert.Error(t, policy.ValidateScaledObject(context.Background(), testClient(t), scaledObject), "the ClusterKedaConfigs apply to all namespaces")
+}
+
+func TestResolveDisjointProviders(t *testing.T) {
+	policy := &Policy{}
+	policy.merge(kedav1alpha1.KedaConfigSpec{Limits: &kedav1alpha1.KedaConfigLimits{
+		RequiredAuthenticationProviders: []kedav1alpha1.AuthenticationProvider{kedav1alpha1.AuthenticationProviderEnv},
+	}})
+	policy.merge(kedav1alpha1.KedaConfigSpec{Limits: &kedav1alpha1.KedaConfigLimits{
+		RequiredAuthenticationProviders: []kedav1alpha1.AuthenticationProvider{kedav1alpha1.AuthenticationProviderLDAP},
+	}})
+	assert.NotNil(t, policy.Limits.RequiredAuthenticationProviders)
+	assert.Empty(t, policy.Limits.RequiredAuthenticationProviders)
+}
+
+func TestApply(t *testing.T) {
+	policy := &Policy{Defaults: kedav1alpha1.KedaConfigDefaults{PollingInterval: int32Ptr(15), CooldownPeriod: int32Ptr(600), MaxReplicaCount: int32Ptr(5)}}
+
+	scaledObject := &kedav1alpha1.ScaledObject{Spec: kedav1alpha1.ScaledObjectSpec{PollingInterval: int32Ptr(45)}}
+	policy.ApplyToScaledObject(scaledObject)
+	assert.Equal(t, int32Ptr(45), scaledObject.Spec.PollingInterval)
+	assert.Equal(t, int32Ptr(600), scaledObject.Spec.CooldownPeriod)
+	assert.Equal(t, int32Ptr(5), scaledObject.Spec.MaxReplicaCount)
+
+	scaledJob := &kedav1alpha1.ScaledJob{Spec: kedav1alpha1.ScaledJobSpec{MaxReplicaCount: int32Ptr(3)}}
+	policy.ApplyToScaledJob(scaledJob)
+	assert.Equal(t, int32Ptr(15), scaledJob.Spec.PollingInterval)
+	assert.Equal(t, int32Ptr(3), scaledJob.Spec.MaxReplicaCount)
+
+	// the defaults are copied
+	*scaledJob.Spec.PollingInterval = 1
+	assert.Equal(t, int32Ptr(15), policy.Defaults.PollingInterval)
+}
+
+type validateTestData struct {
+	name            string
+	pollingInterval *int32
+	maxReplicaCount *int32
+	triggers        []kedav1alpha1.ScaleTriggers
+	isError         bool
+}
+
+var validateTestDataset = []validateTestData{
+	{"valid", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis"}}, false},
+	{"defaults", nil, nil, []kedav1alpha1.ScaleTriggers{{Type: "redis"}}, true},
+	{"max replica count", int32Ptr(30), int32Ptr(51), []kedav1alpha1.ScaleTriggers{{Type: "redis"}}, true},
+	{"polling interval", int32Ptr(14), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis"}}, true},
+	{"banned type", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis"}, {Type: "cron"}}, true},
+	{"banned template type", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "nightly"}}}, true},
+	{"allowed provider", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis", AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "pod-identity"}}}, false},
+	{"forbidden provider", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis", AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "secret"}}}, true},
+	{"forbidden cluster provider", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis", AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "vault", Kind: "ClusterTriggerAuthentication"}}}, true},
+	{"missing authentication", int32Ptr(30), int32Ptr(50), []kedav1alpha1.ScaleTriggers{{Type: "redis", AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "missing"}}}, true},
+}
+
+func TestValidate(t *testing.T) {
+	kubeClient := testClient(t)
+	policy, err := Resolve(context.Background(), kubeClient, namespace)
+	assert.NoError(t, err)
+	// the defaults aren't applied, the defaults of KEDA are checked against the limits
+	for _, testData := range validateTestDataset {
+		scaledObject := &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: namespace},
+			Spec:       kedav1alpha1.ScaledObjectSpec{PollingInterval: testData.pollingInterval, MaxReplicaCount: testData.maxReplicaCount, Triggers: testData.triggers},
+		}
+		err := policy.ValidateScaledObject(context.Background(), kubeClient, scaledObject)
+		assert.Equal(t, testData.isError, err != nil, "%s: %v", testData.name, err)
+
+		scaledJob := &kedav1alpha1.ScaledJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: namespace},
+			Spec:       kedav1alpha1.ScaledJobSpec{PollingInterval: testData.pollingInterval, MaxReplicaCount: testData.maxReplicaCount, Triggers: testData.triggers},
+		}
+		err = policy.ValidateScaledJob(context.Background(), kubeClient, scaledJob)
+		assert.Equal(t, testData.isError, err != nil, "%s: %v", testData.name, err)
+	}
+}
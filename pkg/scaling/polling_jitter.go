@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var (
+	// pollingJitter is set at startup and on the reloads of the operator configuration, it is the fraction of the
+	// pollingInterval the first checks of the scale loops are spread over, 0 starts them right away
+	pollingJitter     float64
+	pollingJitterLock sync.RWMutex
+)
+
+// SetPollingJitter sets the fraction of the pollingInterval the first checks of the scale loops are spread over,
+// the objects created together, eg. by GitOps, or restored by a restart of the operator then don't query their
+// backends at the same moment on every interval
+func SetPollingJitter(fraction float64) error {
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("the polling jitter must be between 0 and 1, got %v", fraction)
+	}
+	pollingJitterLock.Lock()
+	defer pollingJitterLock.Unlock()
+	pollingJitter = fraction
+	return nil
+}
+
+// pollingOffset returns the delay of the first check of the scale loop of an object, it is derived from the
+// namespace and the name of the object so an object keeps its offset across the restarts of its loop
+func pollingOffset(namespace, name string, pollingInterval time.Duration) time.Duration {
+	pollingJitterLock.RLock()
+	spread := time.Duration(float64(pollingInterval) * pollingJitter)
+	pollingJitterLock.RUnlock()
+	if spread <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(namespace + "/" + name))
+	return time.Duration(h.Sum64() % uint64(spread))
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollingOffset(t *testing.T) {
+	defer func() { pollingJitter = 0 }()
+	interval := 30 * time.Second
+
+	assert.Equal(t, time.Duration(0), pollingOffset("default", "app", interval))
+
+	assert.Error(t, SetPollingJitter(-0.1))
+	assert.Error(t, SetPollingJitter(1.5))
+	assert.NoError(t, SetPollingJitter(0.5))
+
+	// the offsets are stable and spread over half of the interval
+	assert.Equal(t, pollingOffset("default", "app", interval), pollingOffset("default", "app", interval))
+	offsets := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		offset := pollingOffset("default", fmt.Sprintf("app-%d", i), interval)
+		assert.GreaterOrEqual(t, offset, time.Duration(0))
+		assert.Less(t, offset, interval/2)
+		offsets[offset] = true
+	}
+	assert.Greater(t, len(offsets), 90)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+var (
+	replicaMetricLabels         = []string{"namespace", "scaledObject"}
+	scaledObjectDesiredReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "keda_operator",
+			Subsystem: "scaled_object",
+			Name:      "desired_replicas",
+			Help:      "Replica count of the scale target computed by KEDA from the triggers",
+		},
+		replicaMetricLabels,
+	)
+	scaledObjectHPADesiredReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "keda_operator",
+			Subsystem: "scaled_object",
+			Name:      "hpa_desired_replicas",
+			Help:      "Desired replica count reported by the HPA of the ScaledObject",
+		},
+		replicaMetricLabels,
+	)
+	scaledObjectReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "keda_operator",
+			Subsystem: "scaled_object",
+			Name:      "replicas",
+			Help:      "Current replica count of the scale target",
+		},
+		replicaMetricLabels,
+	)
+)
+
+func init() {
+	// served by the metrics endpoint of the operator
+	ctrlmetrics.Registry.MustRegister(scaledObjectDesiredReplicas, scaledObjectHPADesiredReplicas, scaledObjectReplicas)
+}
+
+// recordReplicaMetrics exposes the replica count computed by KEDA, the one wanted by the HPA and the current one,
+// so the lag between them can be observed and alerted on
+func (h *scaleHandler) recordReplicaMetrics(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, state *cache.ScaledObjectState) {
+	labels := prometheus.Labels{"namespace": scaledObject.Namespace, "scaledObject": scaledObject.Name}
+
+	currentReplicas, desiredReplicas, err := h.scaleExecutor.EstimateReplicaCount(ctx, scaledObject, state.IsActive, state.IsError, state.GetDesiredReplicaCount)
+	if err != nil {
+		h.logger.V(1).Info("Error estimating replica count for metrics", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "error", err)
+		scaledObjectDesiredReplicas.Delete(labels)
+		scaledObjectReplicas.Delete(labels)
+	} else {
+		scaledObjectDesiredReplicas.With(labels).Set(float64(desiredReplicas))
+		scaledObjectReplicas.With(labels).Set(float64(currentReplicas))
+	}
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	if err := h.client.Get(ctx, types.NamespacedName{Name: scaledObject.GetHPAName(), Namespace: scaledObject.GetScaleTargetNamespace()}, hpa); err != nil {
+		// dry run ScaledObjects don't have an HPA
+		scaledObjectHPADesiredReplicas.Delete(labels)
+		return
+	}
+	scaledObjectHPADesiredReplicas.With(labels).Set(float64(hpa.Status.DesiredReplicas))
+}
+
+// deleteReplicaMetrics removes the replica metrics of a deleted ScaledObject
+func deleteReplicaMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": name}
+	scaledObjectDesiredReplicas.Delete(labels)
+	scaledObjectHPADesiredReplicas.Delete(labels)
+	scaledObjectReplicas.Delete(labels)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/executor"
+)
+
+// estimatingExecutor returns fixed replica counts from EstimateReplicaCount
+type estimatingExecutor struct {
+	executor.ScaleExecutor
+	currentReplicas int32
+	desiredReplicas int32
+	err             error
+}
+
+func (e *estimatingExecutor) EstimateReplicaCount(context.Context, *kedav1alpha1.ScaledObject, bool, bool, executor.DesiredReplicaCountFunc) (int32, int32, error) {
+	return e.currentReplicas, e.desiredReplicas, e.err
+}
+
+func TestRecordReplicaMetrics(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"}}
+	hpa := &v2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda-hpa-orders", Namespace: "shop"},
+		Status:     v2beta2.HorizontalPodAutoscalerStatus{DesiredReplicas: 6},
+	}
+	labels := prometheus.Labels{"namespace": "shop", "scaledObject": "orders"}
+	scaleExecutor := &estimatingExecutor{currentReplicas: 2, desiredReplicas: 8}
+	h := &scaleHandler{
+		client:        fake.NewFakeClientWithScheme(scheme.Scheme, hpa),
+		logger:        logf.Log.WithName("test"),
+		scaleExecutor: scaleExecutor,
+	}
+
+	h.recordReplicaMetrics(context.Background(), scaledObject, &cache.ScaledObjectState{IsActive: true})
+	assert.Equal(t, 8.0, testutil.ToFloat64(scaledObjectDesiredReplicas.With(labels)))
+	assert.Equal(t, 6.0, testutil.ToFloat64(scaledObjectHPADesiredReplicas.With(labels)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(scaledObjectReplicas.With(labels)))
+
+	scaleExecutor.err = errors.New("scale target not found")
+	h.recordReplicaMetrics(context.Background(), scaledObject, &cache.ScaledObjectState{IsActive: true})
+	assert.Equal(t, 0, testutil.CollectAndCount(scaledObjectDesiredReplicas))
+	assert.Equal(t, 1, testutil.CollectAndCount(scaledObjectHPADesiredReplicas))
+
+	deleteReplicaMetrics("shop", "orders")
+	assert.Equal(t, 0, testutil.CollectAndCount(scaledObjectHPADesiredReplicas))
+	assert.Equal(t, 0, testutil.CollectAndCount(scaledObjectReplicas))
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialsCache keeps the secrets read from the external secret stores, eg. HashiCorp Vault, for the TTL set with
+// SetCredentialsCache, they are sealed by the sealer of the cache
+var credentialsCache = struct {
+	sync.Mutex
+	ttl     time.Duration
+	sealer  credentialsSealer
+	entries map[string]credentialsCacheEntry
+}{sealer: plainSealer{}, entries: map[string]credentialsCacheEntry{}}
+
+type credentialsCacheEntry struct {
+	sealed  []byte
+	expires time.Time
+}
+
+// credentialsSealer encrypts the cached secrets
+type credentialsSealer interface {
+	Seal(plaintext []byte) ([]byte, error)
+	Open(sealed []byte) ([]byte, error)
+}
+
+// plainSealer keeps the secrets as they are
+type plainSealer struct{}
+
+func (plainSealer) Seal(plaintext []byte) ([]byte, error) { return plaintext, nil }
+func (plainSealer) Open(sealed []byte) ([]byte, error)    { return sealed, nil }
+
+// SetCredentialsCache sets how long the secrets read from the external secret stores are cached, they are read again
+// once it elapsed and the scalers are rebuilt with them, 0 doesn't cache them and keeps the scalers. With a kmsKeyID
+// the cached secrets are encrypted with a data key wrapped by the AWS KMS key, the secrets and the data key aren't
+// kept in clear in the memory of the operator.
+func SetCredentialsCache(ttl time.Duration, kmsKeyID string) error {
+	if ttl < 0 {
+		return fmt.Errorf("the credentials cache TTL can't be negative, got %s", ttl)
+	}
+	var sealer credentialsSealer = plainSealer{}
+	if kmsKeyID != "" {
+		kmsSealer, err := newKMSSealer(kmsKeyID)
+		if err != nil {
+			return err
+		}
+		sealer = kmsSealer
+	}
+
+	credentialsCache.Lock()
+	defer credentialsCache.Unlock()
+	credentialsCache.ttl = ttl
+	credentialsCache.sealer = sealer
+	credentialsCache.entries = map[string]credentialsCacheEntry{}
+	return nil
+}
+
+// SetCredentialsTTL changes how long the resolved credentials are kept, the sealer and the cached credentials are
+// kept, the credentials cached before keep their expiry
+func SetCredentialsTTL(ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("the credentials cache TTL can't be negative, got %s", ttl)
+	}
+	credentialsCache.Lock()
+	defer credentialsCache.Unlock()
+	credentialsCache.ttl = ttl
+	return nil
+}
+
+// CredentialsTTL returns how long the resolved credentials are kept, 0 keeps them as long as the scalers
+func CredentialsTTL() time.Duration {
+	credentialsCache.Lock()
+	defer credentialsCache.Unlock()
+	return credentialsCache.ttl
+}
+
+// credentialsCacheKey identifies the secrets of a secret store configuration of a TriggerAuthentication, a change of
+// the TriggerAuthentication changes the key so its secrets are read again
+func credentialsCacheKey(kind, namespace, name string, spec interface{}) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s/%s/%s\n", kind, namespace, name)
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// getCachedCredentials returns the cached secrets of key, the expired ones are dropped
+func getCachedCredentials(key string, now time.Time) (map[string]string, bool) {
+	credentialsCache.Lock()
+	entry, ok := credentialsCache.entries[key]
+	if ok && now.After(entry.expires) {
+		delete(credentialsCache.entries, key)
+		ok = false
+	}
+	sealer := credentialsCache.sealer
+	credentialsCache.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	// the sealer can call the KMS, it isn't called with the lock held
+	plaintext, err := sealer.Open(entry.sealed)
+	if err != nil {
+		return nil, false
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, false
+	}
+	return secrets, true
+}
+
+// setCachedCredentials caches the secrets of key for the TTL of the cache
+func setCachedCredentials(key string, secrets map[string]string, now time.Time) error {
+	credentialsCache.Lock()
+	ttl, sealer := credentialsCache.ttl, credentialsCache.sealer
+	credentialsCache.Unlock()
+	if ttl <= 0 {
+		return nil
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	sealed, err := sealer.Seal(plaintext)
+	if err != nil {
+		return fmt.Errorf("error sealing the credentials: %s", err)
+	}
+
+	credentialsCache.Lock()
+	defer credentialsCache.Unlock()
+	for k, entry := range credentialsCache.entries {
+		if now.After(entry.expires) {
+			delete(credentialsCache.entries, k)
+		}
+	}
+	credentialsCache.entries[key] = credentialsCacheEntry{sealed: sealed, expires: now.Add(ttl)}
+	return nil
+}
+
+// minRedactedLength is the length of the shortest value redacted, the shorter ones are eg. ports or flags
+const minRedactedLength = 6
+
+// RedactCredentials replaces the values of the authParams in message, so the messages of the errors of a scaler
+// don't leak its credentials into the logs, the events and the status
+func RedactCredentials(message string, authParams map[string]string) string {
+	values := make([]string, 0, len(authParams))
+	for _, value := range authParams {
+		if len(value) >= minRedactedLength {
+			values = append(values, value)
+		}
+	}
+	// the longest values first, a value can contain another one
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		message = strings.ReplaceAll(message, value, "[REDACTED]")
+	}
+	return message
+}
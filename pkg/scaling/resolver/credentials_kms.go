@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// kmsSealer encrypts every value with its own AES-256-GCM data key generated by AWS KMS, the sealed value is the
+// length of the wrapped data key, the wrapped data key, the nonce and the ciphertext. The plaintext data key is
+// only kept while a value is sealed or opened.
+type kmsSealer struct {
+	client kmsiface.KMSAPI
+	keyID  string
+}
+
+// newKMSSealer creates a kmsSealer of the KMS key, the region and the credentials are read from the environment of
+// the operator
+func newKMSSealer(keyID string) (*kmsSealer, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("error creating the AWS session of the KMS: %s", err)
+	}
+	return &kmsSealer{client: kms.New(sess), keyID: keyID}, nil
+}
+
+func (s *kmsSealer) Seal(plaintext []byte) ([]byte, error) {
+	dataKey, err := s.client.GenerateDataKey(&kms.GenerateDataKeyInput{
+		KeyId:   aws.String(s.keyID),
+		KeySpec: aws.String(kms.DataKeySpecAes256),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating a data key: %s", err)
+	}
+	defer zero(dataKey.Plaintext)
+
+	gcm, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 2, 2+len(dataKey.CiphertextBlob)+len(nonce)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(sealed, uint16(len(dataKey.CiphertextBlob)))
+	sealed = append(sealed, dataKey.CiphertextBlob...)
+	sealed = append(sealed, nonce...)
+	return gcm.Seal(sealed, nonce, plaintext, nil), nil
+}
+
+func (s *kmsSealer) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < 2 {
+		return nil, fmt.Errorf("sealed value is too short")
+	}
+	keyLength := int(binary.BigEndian.Uint16(sealed))
+	if len(sealed) < 2+keyLength {
+		return nil, fmt.Errorf("sealed value is too short")
+	}
+	dataKey, err := s.client.Decrypt(&kms.DecryptInput{
+		KeyId:          aws.String(s.keyID),
+		CiphertextBlob: sealed[2 : 2+keyLength],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting the data key: %s", err)
+	}
+	defer zero(dataKey.Plaintext)
+
+	gcm, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[2+keyLength:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("sealed value is too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func zero(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
@@ -20,18 +20,29 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	vaultapi "github.com/hashicorp/vault/api"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 )
 
+// the failed token renewals are retried with a jittered exponential backoff between these bounds
+const (
+	vaultRenewRetryMin = time.Second
+	vaultRenewRetryMax = 2 * time.Minute
+)
+
 // HashicorpVaultHandler is specification of Hashi Corp Vault
 type HashicorpVaultHandler struct {
-	vault  *kedav1alpha1.HashiCorpVault
-	client *vaultapi.Client
-	stopCh chan struct{}
+	vault    *kedav1alpha1.HashiCorpVault
+	client   *vaultapi.Client
+	stopCh   chan struct{}
+	stopOnce sync.Once
 }
 
 // NewHashicorpVaultHandler creates a HashicorpVaultHandler object
@@ -44,6 +55,21 @@ func NewHashicorpVaultHandler(v *kedav1alpha1.HashiCorpVault) *HashicorpVaultHan
 // Initialize the Vault client
 func (vh *HashicorpVaultHandler) Initialize(logger logr.Logger) error {
 	config := vaultapi.DefaultConfig()
+	if config.Error != nil {
+		return config.Error
+	}
+	if vh.vault.TLS != nil {
+		err := config.ConfigureTLS(&vaultapi.TLSConfig{
+			CACert:        vh.vault.TLS.CAFile,
+			ClientCert:    vh.vault.TLS.ClientCertFile,
+			ClientKey:     vh.vault.TLS.ClientKeyFile,
+			TLSServerName: vh.vault.TLS.ServerName,
+			Insecure:      vh.vault.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return fmt.Errorf("error configuring the TLS connection to Vault: %s", err)
+		}
+	}
 	client, err := vaultapi.NewClient(config)
 	if err != nil {
 		return err
@@ -73,17 +99,15 @@ func (vh *HashicorpVaultHandler) Initialize(logger logr.Logger) error {
 		return err
 	}
 
-	renew := lookup.Data["renewable"].(bool)
-	if renew {
+	vh.client = client
+
+	if renew, _ := lookup.Data["renewable"].(bool); renew {
 		vh.stopCh = make(chan struct{})
 		go vh.renewToken(logger)
 	}
 
-	vh.client = client
-
 	return nil
 }
-
 func (vh *HashicorpVaultHandler) token(client *vaultapi.Client) (string, error) {
 	var token string
 
@@ -131,41 +155,77 @@ func (vh *HashicorpVaultHandler) token(client *vaultapi.Client) (string, error)
 	return token, nil
 }
 
+// renewToken keeps the token renewed until Stop is called, the failed renewals are retried with a jittered
+// exponential backoff so the handlers of many TriggerAuthentications don't retry against Vault in lockstep.
+// The tokens of the kubernetes authentication which can't be renewed anymore are replaced by a new login.
 func (vh *HashicorpVaultHandler) renewToken(logger logr.Logger) {
+	backoff := newVaultRenewBackoff()
+	for {
+		err := vh.watchToken(&backoff)
+		select {
+		case <-vh.stopCh:
+			return
+		default:
+		}
+
+		if err == nil {
+			// the token reached its max TTL
+			if vh.vault.Authentication != kedav1alpha1.VaultAuthenticationKubernetes {
+				logger.Info("Vault renew token: the token can't be renewed anymore")
+				return
+			}
+			var token string
+			if token, err = vh.token(vh.client); err == nil {
+				vh.client.SetToken(token)
+				continue
+			}
+		}
+
+		retry := backoff.Step()
+		logger.Error(err, "Vault renew token: failed, retrying", "retryAfter", retry)
+		select {
+		case <-vh.stopCh:
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+// watchToken renews the token until it can't be renewed anymore, a renewal error or Stop, backoff is reset after
+// each successful renewal
+func (vh *HashicorpVaultHandler) watchToken(backoff *wait.Backoff) error {
 	secret, err := vh.client.Auth().Token().RenewSelf(0)
 	if err != nil {
-		logger.Error(err, "Vault renew token: failed to create the payload")
+		return fmt.Errorf("error renewing the token: %s", err)
 	}
+	*backoff = newVaultRenewBackoff()
 
-	renewer, err := vh.client.NewLifetimeWatcher(&vaultapi.RenewerInput{
-		Secret: secret,
-		//Grace:  time.Duration(15 * time.Second),
-		//Increment: 60,
+	watcher, err := vh.client.NewLifetimeWatcher(&vaultapi.LifetimeWatcherInput{
+		Secret:        secret,
+		RenewBehavior: vaultapi.RenewBehaviorErrorOnErrors,
 	})
 	if err != nil {
-		logger.Error(err, "Vault renew token: cannot create the renewer")
+		return fmt.Errorf("cannot create the renewer: %s", err)
 	}
+	go watcher.Start()
+	defer watcher.Stop()
 
-	go renewer.Renew()
-	defer func() {
-		renewer.Stop()
-		close(vh.stopCh)
-	}()
-
-RenewWatcherLoop:
 	for {
 		select {
 		case <-vh.stopCh:
-			break RenewWatcherLoop
-		case err := <-renewer.DoneCh():
-			if err != nil {
-				logger.Error(err, "error renewing token")
-			}
-			break RenewWatcherLoop
+			return nil
+		case <-watcher.RenewCh():
+			*backoff = newVaultRenewBackoff()
+		case err := <-watcher.DoneCh():
+			return err
 		}
 	}
 }
 
+func newVaultRenewBackoff() wait.Backoff {
+	return wait.Backoff{Duration: vaultRenewRetryMin, Factor: 2, Jitter: 0.5, Steps: math.MaxInt32, Cap: vaultRenewRetryMax}
+}
+
 func (vh *HashicorpVaultHandler) Read(path string) (*vaultapi.Secret, error) {
 	return vh.client.Logical().Read(path)
 }
@@ -173,6 +233,6 @@ func (vh *HashicorpVaultHandler) Read(path string) (*vaultapi.Secret, error) {
 // Stop is responsible for stoping the renew token process
 func (vh *HashicorpVaultHandler) Stop() {
 	if vh.stopCh != nil {
-		vh.stopCh <- struct{}{}
+		vh.stopOnce.Do(func() { close(vh.stopCh) })
 	}
 }
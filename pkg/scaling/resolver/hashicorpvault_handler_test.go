@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// newVaultTestServer serves the token lookups of a Vault Enterprise namespace over TLS, the renewals fail
+func newVaultTestServer(t *testing.T, renewable bool, renewals *int32) (*httptest.Server, string) {
+	// the client doesn't retry the failed requests itself
+	os.Setenv("VAULT_MAX_RETRIES", "0")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Namespace") != "team-a" || r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			fmt.Fprintf(w, `{"data": {"renewable": %t, "ttl": 3600}}`, renewable)
+		case "/v1/auth/token/renew-self":
+			atomic.AddInt32(renewals, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"errors": ["internal error"]}`)
+		case "/v1/secret/data/keda":
+			fmt.Fprint(w, `{"data": {"data": {"password": "s3cr3t"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	caFile, err := ioutil.TempFile("", "vault-ca")
+	assert.NoError(t, err)
+	assert.NoError(t, pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	caFile.Close()
+	return server, caFile.Name()
+}
+
+func TestHashicorpVaultHandlerTLS(t *testing.T) {
+	var renewals int32
+	server, caFile := newVaultTestServer(t, false, &renewals)
+	defer server.Close()
+	defer os.Remove(caFile)
+	defer os.Unsetenv("VAULT_MAX_RETRIES")
+
+	vault := &kedav1alpha1.HashiCorpVault{
+		Address:        server.URL,
+		Authentication: kedav1alpha1.VaultAuthenticationToken,
+		Namespace:      "team-a",
+		Credential:     &kedav1alpha1.Credential{Token: "token"},
+		TLS:            &kedav1alpha1.VaultTLS{CAFile: caFile, ServerName: "example.com"},
+	}
+	handler := NewHashicorpVaultHandler(vault)
+	if !assert.NoError(t, handler.Initialize(logf.Log)) {
+		return
+	}
+	secret, err := handler.Read("secret/data/keda")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", resolveVaultSecret(logf.Log, secret.Data, "password"))
+	handler.Stop()
+
+	// the certificate of the server isn't trusted without the CA
+	vault.TLS = nil
+	assert.Error(t, NewHashicorpVaultHandler(vault).Initialize(logf.Log))
+
+	vault.TLS = &kedav1alpha1.VaultTLS{InsecureSkipVerify: true}
+	assert.NoError(t, NewHashicorpVaultHandler(vault).Initialize(logf.Log))
+
+	// a client certificate needs its key
+	vault.TLS = &kedav1alpha1.VaultTLS{CAFile: caFile, ClientCertFile: caFile}
+	assert.Error(t, NewHashicorpVaultHandler(vault).Initialize(logf.Log))
+}
+
+func TestHashicorpVaultHandlerRenewRetries(t *testing.T) {
+	var renewals int32
+	server, caFile := newVaultTestServer(t, true, &renewals)
+	defer server.Close()
+	defer os.Remove(caFile)
+	defer os.Unsetenv("VAULT_MAX_RETRIES")
+
+	handler := NewHashicorpVaultHandler(&kedav1alpha1.HashiCorpVault{
+		Address:        server.URL,
+		Authentication: kedav1alpha1.VaultAuthenticationToken,
+		Namespace:      "team-a",
+		Credential:     &kedav1alpha1.Credential{Token: "token"},
+		TLS:            &kedav1alpha1.VaultTLS{CAFile: caFile},
+	})
+	assert.NoError(t, handler.Initialize(logf.Log))
+
+	// the failed renewal is retried after the backoff
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&renewals) >= 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// the renewals stop, Stop can be called again
+	handler.Stop()
+	handler.Stop()
+	time.Sleep(100 * time.Millisecond)
+	current := atomic.LoadInt32(&renewals)
+	time.Sleep(2 * vaultRenewRetryMin)
+	assert.Equal(t, current, atomic.LoadInt32(&renewals))
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	defaultLDAPCacheDuration = 5 * time.Minute
+	ldapUsernamePlaceholder  = "{{username}}"
+)
+
+// ldapCache keeps the attributes read from the directories and the validated credentials
+var ldapCache = struct {
+	sync.Mutex
+	entries map[string]ldapCacheEntry
+}{entries: map[string]ldapCacheEntry{}}
+
+type ldapCacheEntry struct {
+	params  map[string]string
+	expires time.Time
+}
+
+// ldapConn is the part of ldap.Conn the handler uses
+type ldapConn interface {
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
+// LDAPHandler reads the parameters of a TriggerAuthentication from an LDAP directory
+type LDAPHandler struct {
+	ldap *kedav1alpha1.LDAP
+	dial func() (ldapConn, error)
+	now  func() time.Time
+}
+
+// NewLDAPHandler creates a LDAPHandler object
+func NewLDAPHandler(l *kedav1alpha1.LDAP) *LDAPHandler {
+	h := &LDAPHandler{
+		ldap: l,
+		now:  time.Now,
+	}
+	h.dial = h.connect
+	return h
+}
+
+// Resolve returns the parameters of the LDAP entries, the credentials of ValidateCredentials are looked up
+// in them and in the parameters resolved so far
+func (h *LDAPHandler) Resolve(resolved map[string]string) (map[string]string, error) {
+	spec, err := json.Marshal(h.ldap)
+	if err != nil {
+		return nil, err
+	}
+
+	readKey := "read\n" + string(spec)
+	params, cached := h.getCached(readKey)
+	var conn ldapConn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	if !cached {
+		if conn, err = h.dial(); err != nil {
+			return nil, err
+		}
+		if params, err = h.readEntries(conn); err != nil {
+			return nil, err
+		}
+		h.setCached(readKey, params)
+	}
+
+	validation := h.ldap.ValidateCredentials
+	if validation == nil {
+		return params, nil
+	}
+
+	username, password := lookupParameter(validation.UsernameParameter, params, resolved), lookupParameter(validation.PasswordParameter, params, resolved)
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("no %s and %s to validate", validation.UsernameParameter, validation.PasswordParameter)
+	}
+	// the password itself isn't kept in the key
+	validateKey := fmt.Sprintf("bind\n%s\n%s\n%x", spec, username, sha256.Sum256([]byte(password)))
+	if _, cached := h.getCached(validateKey); cached {
+		return params, nil
+	}
+
+	if conn == nil {
+		if conn, err = h.dial(); err != nil {
+			return nil, err
+		}
+	}
+	userDN := username
+	if validation.UserDNTemplate != "" {
+		userDN = strings.ReplaceAll(validation.UserDNTemplate, ldapUsernamePlaceholder, escapeDNValue(username))
+	}
+	if err := conn.Bind(userDN, password); err != nil {
+		return nil, fmt.Errorf("the credentials of %s are not valid: %s", userDN, err)
+	}
+	h.setCached(validateKey, nil)
+	return params, nil
+}
+
+// connect dials the directory and binds with the Credential
+func (h *LDAPHandler) connect() (ldapConn, error) {
+	tlsConfig, err := h.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := ldap.DialURL(h.ldap.Address, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	if h.ldap.TLS != nil && h.ldap.TLS.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if credential := h.ldap.Credential; credential != nil {
+		password, err := ioutil.ReadFile(credential.PasswordFile)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := conn.Bind(credential.BindDN, strings.TrimSpace(string(password))); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (h *LDAPHandler) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if h.ldap.TLS == nil {
+		return config, nil
+	}
+
+	config.InsecureSkipVerify = h.ldap.TLS.InsecureSkipVerify
+	if h.ldap.TLS.CAFile != "" {
+		ca, err := ioutil.ReadFile(h.ldap.TLS.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", h.ldap.TLS.CAFile)
+		}
+	}
+	return config, nil
+}
+
+// readEntries reads the attribute of each entry, an entry with a Filter is searched in the subtree of its DN
+func (h *LDAPHandler) readEntries(conn ldapConn) (map[string]string, error) {
+	params := make(map[string]string, len(h.ldap.Entries))
+	for _, e := range h.ldap.Entries {
+		scope, filter := ldap.ScopeBaseObject, "(objectClass=*)"
+		if e.Filter != "" {
+			scope, filter = ldap.ScopeWholeSubtree, e.Filter
+		}
+
+		result, err := conn.Search(ldap.NewSearchRequest(e.DN, scope, ldap.NeverDerefAliases, 2, 0, false, filter, []string{e.Attribute}, nil))
+		if err != nil {
+			return nil, fmt.Errorf("error searching %s: %s", e.DN, err)
+		}
+		if len(result.Entries) != 1 {
+			return nil, fmt.Errorf("%d entries found for %s, expected 1", len(result.Entries), e.DN)
+		}
+
+		value := result.Entries[0].GetAttributeValue(e.Attribute)
+		if value == "" {
+			return nil, fmt.Errorf("no attribute %s in %s", e.Attribute, result.Entries[0].DN)
+		}
+		params[e.Parameter] = value
+	}
+	return params, nil
+}
+
+func (h *LDAPHandler) cacheDuration() time.Duration {
+	if h.ldap.CacheDurationSeconds != nil {
+		return time.Duration(*h.ldap.CacheDurationSeconds) * time.Second
+	}
+	return defaultLDAPCacheDuration
+}
+
+func (h *LDAPHandler) getCached(key string) (map[string]string, bool) {
+	ldapCache.Lock()
+	defer ldapCache.Unlock()
+
+	entry, ok := ldapCache.entries[key]
+	if !ok || h.now().After(entry.expires) {
+		delete(ldapCache.entries, key)
+		return nil, false
+	}
+	return entry.params, true
+}
+
+func (h *LDAPHandler) setCached(key string, params map[string]string) {
+	duration := h.cacheDuration()
+	if duration <= 0 {
+		return
+	}
+
+	ldapCache.Lock()
+	defer ldapCache.Unlock()
+	ldapCache.entries[key] = ldapCacheEntry{params: params, expires: h.now().Add(duration)}
+}
+
+func lookupParameter(name string, params, resolved map[string]string) string {
+	if value, ok := params[name]; ok {
+		return value
+	}
+	return resolved[name]
+}
+
+// escapeDNValue escapes the special characters of an attribute value of a DN, RFC 4514
+func escapeDNValue(value string) string {
+	var b strings.Builder
+	for i, c := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, c),
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(value)-1 && c == ' ':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type fakeLDAPConn struct {
+	entries  map[string]*ldap.Entry
+	users    map[string]string
+	searches int
+	binds    []string
+}
+
+func (c *fakeLDAPConn) Bind(username, password string) error {
+	c.binds = append(c.binds, username)
+	if c.users[username] != password {
+		return fmt.Errorf("invalid credentials")
+	}
+	return nil
+}
+
+func (c *fakeLDAPConn) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.searches++
+	result := &ldap.SearchResult{}
+	if entry, ok := c.entries[searchRequest.BaseDN+searchRequest.Filter]; ok {
+		result.Entries = append(result.Entries, entry)
+	}
+	return result, nil
+}
+
+func (c *fakeLDAPConn) Close() {}
+
+func TestLDAPHandlerResolve(t *testing.T) {
+	conn := &fakeLDAPConn{
+		entries: map[string]*ldap.Entry{
+			"cn=postgres,ou=services,dc=example,dc=com(objectClass=*)": ldap.NewEntry("cn=postgres,ou=services,dc=example,dc=com", map[string][]string{"userPassword": {"secret"}}),
+			"ou=services,dc=example,dc=com(cn=redis)":                  ldap.NewEntry("cn=redis,ou=services,dc=example,dc=com", map[string][]string{"description": {"redis.svc:6379"}}),
+		},
+		users: map[string]string{"cn=keda,ou=services,dc=example,dc=com": "secret"},
+	}
+	now := time.Unix(1600000000, 0)
+	cacheDuration := int32(60)
+	spec := &kedav1alpha1.LDAP{
+		Address: "ldap://directory:389",
+		Entries: []kedav1alpha1.LDAPEntry{
+			{Parameter: "password", DN: "cn=postgres,ou=services,dc=example,dc=com", Attribute: "userPassword"},
+			{Parameter: "address", DN: "ou=services,dc=example,dc=com", Filter: "(cn=redis)", Attribute: "description"},
+		},
+		ValidateCredentials: &kedav1alpha1.LDAPCredentialValidation{
+			UsernameParameter: "username",
+			PasswordParameter: "password",
+			UserDNTemplate:    "cn={{username}},ou=services,dc=example,dc=com",
+		},
+		CacheDurationSeconds: &cacheDuration,
+	}
+	newHandler := func() *LDAPHandler {
+		h := NewLDAPHandler(spec)
+		h.dial = func() (ldapConn, error) { return conn, nil }
+		h.now = func() time.Time { return now }
+		return h
+	}
+
+	params, err := newHandler().Resolve(map[string]string{"username": "keda"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "secret", "address": "redis.svc:6379"}, params)
+	assert.Equal(t, 2, conn.searches)
+	assert.Equal(t, []string{"cn=keda,ou=services,dc=example,dc=com"}, conn.binds)
+
+	// the cache is used until it expires
+	_, err = newHandler().Resolve(map[string]string{"username": "keda"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, conn.searches)
+	assert.Len(t, conn.binds, 1)
+
+	_, err = newHandler().Resolve(map[string]string{"username": "other"})
+	assert.Error(t, err)
+	assert.Equal(t, "cn=other,ou=services,dc=example,dc=com", conn.binds[1])
+
+	now = now.Add(61 * time.Second)
+	_, err = newHandler().Resolve(map[string]string{"username": "keda"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, conn.searches)
+
+	// an entry is missing
+	spec.Entries = append(spec.Entries, kedav1alpha1.LDAPEntry{Parameter: "host", DN: "cn=missing,dc=example,dc=com", Attribute: "description"})
+	_, err = newHandler().Resolve(nil)
+	assert.Error(t, err)
+}
+
+func TestEscapeDNValue(t *testing.T) {
+	assert.Equal(t, "keda", escapeDNValue("keda"))
+	assert.Equal(t, `\#keda\,admin\=true\ `, escapeDNValue("#keda,admin=true "))
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// metadataTemplateData is the data the metadata templates are executed with, eg. `amqp://{{.Env.USER}}@rabbitmq`
+type metadataTemplateData struct {
+	Env map[string]string
+}
+
+// ExpandMetadataTemplates executes the templates of the metadata values with the environment of the scale target,
+// values without `{{` are returned as is and referencing a variable that isn't set is an error
+func ExpandMetadataTemplates(metadata map[string]string, env map[string]string) (map[string]string, error) {
+	expanded, copied := metadata, false
+	for key, value := range metadata {
+		if !strings.Contains(value, "{{") {
+			continue
+		}
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template of metadata %s: %s", key, err)
+		}
+		var result strings.Builder
+		if err := tmpl.Execute(&result, metadataTemplateData{Env: env}); err != nil {
+			return nil, fmt.Errorf("error expanding template of metadata %s: %s", key, err)
+		}
+
+		// copy the metadata before the first change, the trigger holds the original map
+		if !copied {
+			expanded, copied = make(map[string]string, len(metadata)), true
+			for k, v := range metadata {
+				expanded[k] = v
+			}
+		}
+		expanded[key] = result.String()
+	}
+	return expanded, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandMetadataTemplates(t *testing.T) {
+	env := map[string]string{"RABBITMQ_USER": "keda", "RABBITMQ_HOST": "rabbitmq.apps"}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		env      map[string]string
+		expected map[string]string
+		isError  bool
+	}{
+		{
+			name:     "no templates",
+			metadata: map[string]string{"queueName": "jobs", "hostFromEnv": "RABBITMQ_HOST"},
+			env:      env,
+			expected: map[string]string{"queueName": "jobs", "hostFromEnv": "RABBITMQ_HOST"},
+		},
+		{
+			name:     "composite value",
+			metadata: map[string]string{"queueName": "jobs", "host": "amqp://{{.Env.RABBITMQ_USER}}@{{ .Env.RABBITMQ_HOST }}:5672/vhost"},
+			env:      env,
+			expected: map[string]string{"queueName": "jobs", "host": "amqp://keda@rabbitmq.apps:5672/vhost"},
+		},
+		{
+			name:     "missing variable",
+			metadata: map[string]string{"host": "amqp://{{.Env.RABBITMQ_PASSWORD}}@rabbitmq"},
+			env:      env,
+			isError:  true,
+		},
+		{
+			name:     "no environment",
+			metadata: map[string]string{"host": "amqp://{{.Env.RABBITMQ_USER}}@rabbitmq"},
+			isError:  true,
+		},
+		{
+			name:     "invalid template",
+			metadata: map[string]string{"host": "amqp://{{.Env.RABBITMQ_USER@rabbitmq"},
+			env:      env,
+			isError:  true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			original := make(map[string]string, len(test.metadata))
+			for key, value := range test.metadata {
+				original[key] = value
+			}
+			expanded, err := ExpandMetadataTemplates(test.metadata, test.env)
+			if test.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected success but got error", err)
+			}
+			if diff := cmp.Diff(test.expected, expanded); diff != "" {
+				t.Errorf("Returned metadata is different: %s", diff)
+			}
+			if diff := cmp.Diff(original, test.metadata); diff != "" {
+				t.Errorf("Metadata of the trigger was modified: %s", diff)
+			}
+		})
+	}
+}
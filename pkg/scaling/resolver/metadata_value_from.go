@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// ResolveMetadataValueFrom returns the metadata of the trigger with the fields of its metadataValueFrom read from
+// the ConfigMaps and Secrets of the namespace, optional references that don't exist leave the field unset
+func ResolveMetadataValueFrom(ctx context.Context, client client.Client, trigger kedav1alpha1.ScaleTriggers, namespace string) (map[string]string, error) {
+	if len(trigger.MetadataValueFrom) == 0 {
+		return trigger.Metadata, nil
+	}
+
+	metadata := make(map[string]string, len(trigger.Metadata)+len(trigger.MetadataValueFrom))
+	for key, value := range trigger.Metadata {
+		metadata[key] = value
+	}
+	for key, source := range trigger.MetadataValueFrom {
+		value, found, err := resolveMetadataValueSource(ctx, client, source, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving metadataValueFrom %s: %s", key, err)
+		}
+		if found {
+			metadata[key] = value
+		}
+	}
+	return metadata, nil
+}
+
+func resolveMetadataValueSource(ctx context.Context, client client.Client, source kedav1alpha1.MetadataValueSource, namespace string) (string, bool, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil && source.SecretKeyRef != nil:
+		return "", false, fmt.Errorf("only one of configMapKeyRef and secretKeyRef can be set")
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+		configMap := &corev1.ConfigMap{}
+		if err := client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, configMap); err != nil {
+			if errors.IsNotFound(err) && optional {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+		if value, ok := configMap.Data[ref.Key]; ok {
+			return value, true, nil
+		}
+		if optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("key %s not found in ConfigMap %s", ref.Key, ref.Name)
+	case source.SecretKeyRef != nil:
+		ref := source.SecretKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+		secret := &corev1.Secret{}
+		if err := client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+			if errors.IsNotFound(err) && optional {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+		if value, ok := secret.Data[ref.Key]; ok {
+			return string(value), true, nil
+		}
+		if optional {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("key %s not found in Secret %s", ref.Key, ref.Name)
+	default:
+		return "", false, fmt.Errorf("one of configMapKeyRef and secretKeyRef must be set")
+	}
+}
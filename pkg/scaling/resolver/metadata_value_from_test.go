@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestResolveMetadataValueFrom(t *testing.T) {
+	const namespace = "apps"
+	optional := true
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "endpoints", Namespace: namespace},
+		Data:       map[string]string{"redis": "redis.apps:6379"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "queues", Namespace: namespace},
+		Data:       map[string][]byte{"list": []byte("jobs")},
+	}
+	client := fake.NewFakeClientWithScheme(scheme.Scheme, configMap, secret)
+
+	configMapRef := func(name, key string, optional *bool) kedav1alpha1.MetadataValueSource {
+		return kedav1alpha1.MetadataValueSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key, Optional: optional}}
+	}
+	secretRef := func(name, key string, optional *bool) kedav1alpha1.MetadataValueSource {
+		return kedav1alpha1.MetadataValueSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key, Optional: optional}}
+	}
+
+	tests := []struct {
+		name      string
+		metadata  map[string]string
+		valueFrom map[string]kedav1alpha1.MetadataValueSource
+		expected  map[string]string
+		isError   bool
+	}{
+		{
+			name:     "no metadataValueFrom",
+			metadata: map[string]string{"listLength": "5"},
+			expected: map[string]string{"listLength": "5"},
+		},
+		{
+			name:     "configMap and secret values",
+			metadata: map[string]string{"listLength": "5", "address": "localhost:6379"},
+			valueFrom: map[string]kedav1alpha1.MetadataValueSource{
+				"address":  configMapRef("endpoints", "redis", nil),
+				"listName": secretRef("queues", "list", nil),
+			},
+			expected: map[string]string{"listLength": "5", "address": "redis.apps:6379", "listName": "jobs"},
+		},
+		{
+			name:     "optional references",
+			metadata: map[string]string{"address": "localhost:6379"},
+			valueFrom: map[string]kedav1alpha1.MetadataValueSource{
+				"address":  configMapRef("endpoints", "notthere", &optional),
+				"listName": secretRef("notthere", "list", &optional),
+			},
+			expected: map[string]string{"address": "localhost:6379"},
+		},
+		{
+			name:      "missing configMap key",
+			valueFrom: map[string]kedav1alpha1.MetadataValueSource{"address": configMapRef("endpoints", "notthere", nil)},
+			isError:   true,
+		},
+		{
+			name:      "missing secret",
+			valueFrom: map[string]kedav1alpha1.MetadataValueSource{"listName": secretRef("notthere", "list", nil)},
+			isError:   true,
+		},
+		{
+			name:      "no reference",
+			valueFrom: map[string]kedav1alpha1.MetadataValueSource{"listName": {}},
+			isError:   true,
+		},
+		{
+			name: "both references",
+			valueFrom: map[string]kedav1alpha1.MetadataValueSource{"listName": {
+				ConfigMapKeyRef: configMapRef("endpoints", "redis", nil).ConfigMapKeyRef,
+				SecretKeyRef:    secretRef("queues", "list", nil).SecretKeyRef,
+			}},
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			trigger := kedav1alpha1.ScaleTriggers{Type: "redis", Metadata: test.metadata, MetadataValueFrom: test.valueFrom}
+			metadata, err := ResolveMetadataValueFrom(context.TODO(), client, trigger, namespace)
+			if test.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected success but got error", err)
+			}
+			if diff := cmp.Diff(test.expected, metadata); diff != "" {
+				t.Errorf("Returned metadata is different: %s", diff)
+			}
+		})
+	}
+}
@@ -22,10 +22,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/apis/duck"
@@ -48,6 +50,12 @@ const (
 func ResolveScaleTargetPodSpec(ctx context.Context, kubeClient client.Client, logger logr.Logger, scalableObject interface{}) (*corev1.PodTemplateSpec, string, error) {
 	switch obj := scalableObject.(type) {
 	case *kedav1alpha1.ScaledObject:
+		// the environment of a target in another namespace would be resolved from the secrets and config maps of
+		// the namespace of the ScaledObject, it isn't resolved
+		if obj.IsCrossNamespace() {
+			logger.V(1).Info("The ScaleTarget is in another namespace, therefore the environment properties aren't injected", "namespace", obj.GetScaleTargetNamespace(), "name", obj.Spec.ScaleTargetRef.Name)
+			return nil, "", nil
+		}
 		// Try to get a real object instance for better cache usage, but fall back to an Unstructured if needed.
 		podTemplateSpec := corev1.PodTemplateSpec{}
 		gvk := obj.Status.ScaleTargetGVKR.GroupVersionKind()
@@ -158,8 +166,14 @@ func resolveAuthRef(ctx context.Context, client client.Client, logger logr.Logge
 	result := make(map[string]string)
 	var podIdentity kedav1alpha1.PodIdentityProvider
 
-	if namespace != "" && triggerAuthRef != nil && triggerAuthRef.Name != "" {
-		triggerAuthSpec, triggerNamespace, err := getTriggerAuthSpec(ctx, client, triggerAuthRef, namespace)
+	if namespace != "" && triggerAuthRef != nil && (triggerAuthRef.Name != "" || triggerAuthRef.Selector != nil) {
+		var triggerAuthSpec *kedav1alpha1.TriggerAuthenticationSpec
+		var triggerNamespace string
+		selectedAuthRef, err := SelectAuthRef(ctx, client, triggerAuthRef)
+		if err == nil {
+			triggerAuthRef = selectedAuthRef
+			triggerAuthSpec, triggerNamespace, err = getTriggerAuthSpec(ctx, client, triggerAuthRef, namespace)
+		}
 		if err != nil {
 			logger.Error(err, "Error getting triggerAuth", "triggerAuthRef.Name", triggerAuthRef.Name)
 		} else {
@@ -186,28 +200,19 @@ func resolveAuthRef(ctx context.Context, client client.Client, logger logr.Logge
 				}
 			}
 			if triggerAuthSpec.HashiCorpVault != nil && len(triggerAuthSpec.HashiCorpVault.Secrets) > 0 {
-				vault := NewHashicorpVaultHandler(triggerAuthSpec.HashiCorpVault)
-				err := vault.Initialize(logger)
+				for parameter, value := range resolveVaultSecrets(logger, triggerAuthRef, triggerAuthSpec.HashiCorpVault, triggerNamespace) {
+					result[parameter] = value
+				}
+			}
+			if triggerAuthSpec.LDAP != nil {
+				params, err := NewLDAPHandler(triggerAuthSpec.LDAP).Resolve(result)
 				if err != nil {
-					logger.Error(err, "Error authenticate to Vault", "triggerAuthRef.Name", triggerAuthRef.Name)
+					logger.Error(err, "Error reading credentials from LDAP", "triggerAuthRef.Name", triggerAuthRef.Name,
+						"ldap.address", triggerAuthSpec.LDAP.Address)
 				} else {
-					for _, e := range triggerAuthSpec.HashiCorpVault.Secrets {
-						secret, err := vault.Read(e.Path)
-						if err != nil {
-							logger.Error(err, "Error trying to read secret from Vault", "triggerAuthRef.Name", triggerAuthRef.Name,
-								"secret.path", e.Path)
-						} else {
-							if secret == nil {
-								// sometimes there is no error, but `vault.Read(e.Path)` is not being able to parse the secret and returns nil
-								logger.Error(fmt.Errorf("unable to parse secret, is the provided path correct?"), "Error trying to read secret from Vault",
-									"triggerAuthRef.Name", triggerAuthRef.Name, "secret.path", e.Path)
-							} else {
-								result[e.Parameter] = resolveVaultSecret(logger, secret.Data, e.Key)
-							}
-						}
+					for k, v := range params {
+						result[k] = v
 					}
-
-					vault.Stop()
 				}
 			}
 		}
@@ -216,6 +221,85 @@ func resolveAuthRef(ctx context.Context, client client.Client, logger logr.Logge
 	return result, podIdentity
 }
 
+// resolveVaultSecrets reads the secrets of the TriggerAuthentication from HashiCorp Vault, they are cached for the
+// TTL of the credentials cache if all of them were read
+func resolveVaultSecrets(logger logr.Logger, triggerAuthRef *kedav1alpha1.ScaledObjectAuthRef, spec *kedav1alpha1.HashiCorpVault, namespace string) map[string]string {
+	key, err := credentialsCacheKey(triggerAuthRef.Kind, namespace, triggerAuthRef.Name, spec)
+	if err != nil {
+		logger.Error(err, "Error computing the credentials cache key", "triggerAuthRef.Name", triggerAuthRef.Name)
+	} else if secrets, ok := getCachedCredentials(key, time.Now()); ok {
+		return secrets
+	}
+
+	result := make(map[string]string, len(spec.Secrets))
+	vault := NewHashicorpVaultHandler(spec)
+	if err := vault.Initialize(logger); err != nil {
+		logger.Error(err, "Error authenticate to Vault", "triggerAuthRef.Name", triggerAuthRef.Name)
+		return result
+	}
+	defer vault.Stop()
+
+	complete := true
+	for _, e := range spec.Secrets {
+		secret, err := vault.Read(e.Path)
+		switch {
+		case err != nil:
+			logger.Error(err, "Error trying to read secret from Vault", "triggerAuthRef.Name", triggerAuthRef.Name,
+				"secret.path", e.Path)
+			complete = false
+		case secret == nil:
+			// sometimes there is no error, but `vault.Read(e.Path)` is not being able to parse the secret and returns nil
+			logger.Error(fmt.Errorf("unable to parse secret, is the provided path correct?"), "Error trying to read secret from Vault",
+				"triggerAuthRef.Name", triggerAuthRef.Name, "secret.path", e.Path)
+			complete = false
+		default:
+			result[e.Parameter] = resolveVaultSecret(logger, secret.Data, e.Key)
+		}
+	}
+
+	if complete && key != "" {
+		if err := setCachedCredentials(key, result, time.Now()); err != nil {
+			logger.Error(err, "Error caching the secrets read from Vault", "triggerAuthRef.Name", triggerAuthRef.Name)
+		}
+	}
+	return result
+}
+
+// SelectAuthRef returns the reference to the newest ClusterTriggerAuthentication matching the selector of the
+// authRef, the authRef is returned as is if it has a name
+func SelectAuthRef(ctx context.Context, kubeClient client.Client, authRef *kedav1alpha1.ScaledObjectAuthRef) (*kedav1alpha1.ScaledObjectAuthRef, error) {
+	if authRef.Name != "" || authRef.Selector == nil {
+		return authRef, nil
+	}
+	if authRef.Kind != "ClusterTriggerAuthentication" {
+		return nil, fmt.Errorf("only a ClusterTriggerAuthentication can be selected by labels, the kind is %s", authRef.Kind)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(authRef.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authenticationRef selector: %s", err)
+	}
+
+	triggerAuths := &kedav1alpha1.ClusterTriggerAuthenticationList{}
+	if err := kubeClient.List(ctx, triggerAuths, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	var newest *kedav1alpha1.ClusterTriggerAuthentication
+	for i, triggerAuth := range triggerAuths.Items {
+		if !triggerAuth.DeletionTimestamp.IsZero() {
+			continue
+		}
+		// the objects created in the same second are ordered by name
+		if newest == nil || newest.CreationTimestamp.Before(&triggerAuth.CreationTimestamp) ||
+			(newest.CreationTimestamp.Equal(&triggerAuth.CreationTimestamp) && newest.Name < triggerAuth.Name) {
+			newest = &triggerAuths.Items[i]
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("no ClusterTriggerAuthentication matches the selector %s", selector)
+	}
+	return &kedav1alpha1.ScaledObjectAuthRef{Name: newest.Name, Kind: authRef.Kind}, nil
+}
+
 var clusterObjectNamespaceCache *string
 
 func getClusterObjectNamespace() (string, error) {
@@ -260,6 +344,26 @@ func getTriggerAuthSpec(ctx context.Context, client client.Client, triggerAuthRe
 	return nil, "", fmt.Errorf("unknown trigger auth kind %s", triggerAuthRef.Kind)
 }
 
+// GetTriggerAuthVersion returns the UID and the generation of the TriggerAuthentication or the
+// ClusterTriggerAuthentication of triggerAuthRef, they change when it is recreated or its spec is updated
+func GetTriggerAuthVersion(ctx context.Context, kubeClient client.Client, triggerAuthRef *kedav1alpha1.ScaledObjectAuthRef, namespace string) (string, error) {
+	var triggerAuth client.Object
+	key := types.NamespacedName{Name: triggerAuthRef.Name}
+	switch triggerAuthRef.Kind {
+	case "", "TriggerAuthentication":
+		triggerAuth = &kedav1alpha1.TriggerAuthentication{}
+		key.Namespace = namespace
+	case "ClusterTriggerAuthentication":
+		triggerAuth = &kedav1alpha1.ClusterTriggerAuthentication{}
+	default:
+		return "", fmt.Errorf("unknown trigger auth kind %s", triggerAuthRef.Kind)
+	}
+	if err := kubeClient.Get(ctx, key, triggerAuth); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%d", triggerAuth.GetUID(), triggerAuth.GetGeneration()), nil
+}
+
 func resolveEnv(ctx context.Context, client client.Client, logger logr.Logger, container *corev1.Container, namespace string) (map[string]string, error) {
 	resolved := make(map[string]string)
 
@@ -19,6 +19,7 @@ package resolver
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -326,6 +327,33 @@ func TestResolveAuthRef(t *testing.T) {
 			expected:            map[string]string{"host": ""},
 			expectedPodIdentity: kedav1alpha1.PodIdentityProviderNone,
 		},
+		{
+			name: "clustertriggerauth selected by labels",
+			existing: []runtime.Object{
+				selectableClusterTriggerAuth("payments-v1", "rotated", time.Unix(1638000000, 0)),
+				selectableClusterTriggerAuth("payments-v3", "current", time.Unix(1638000100, 0)),
+				selectableClusterTriggerAuth("payments-v2", "rotated", time.Unix(1638000100, 0)),
+				&kedav1alpha1.ClusterTriggerAuthentication{
+					ObjectMeta: metav1.ObjectMeta{Name: "orders", CreationTimestamp: metav1.NewTime(time.Unix(1638000200, 0))},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: clusterNamespace, Name: secretName},
+					Data:       map[string][]byte{"rotated": []byte("old"), "current": []byte(secretData)},
+				},
+			},
+			soar: &kedav1alpha1.ScaledObjectAuthRef{
+				Kind:     "ClusterTriggerAuthentication",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			},
+			expected: map[string]string{"host": secretData},
+		},
+		{
+			name: "triggerauth selected by labels",
+			soar: &kedav1alpha1.ScaledObjectAuthRef{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			},
+			expected: map[string]string{},
+		},
 	}
 	for _, test := range tests {
 		test := test
@@ -343,6 +371,19 @@ func TestResolveAuthRef(t *testing.T) {
 	}
 }
 
+func selectableClusterTriggerAuth(name, secretKey string, created time.Time) *kedav1alpha1.ClusterTriggerAuthentication {
+	return &kedav1alpha1.ClusterTriggerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Labels:            map[string]string{"team": "payments"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: kedav1alpha1.TriggerAuthenticationSpec{
+			SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{{Parameter: "host", Name: secretName, Key: secretKey}},
+		},
+	}
+}
+
 func TestResolveDependentEnv(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// templatePlaceholder matches the ${parameter} placeholders of the template metadata
+var templatePlaceholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// ResolveTriggerTemplate fills the trigger from its ClusterTriggerTemplate, triggers without templateRef are returned as is
+func ResolveTriggerTemplate(ctx context.Context, client client.Client, trigger kedav1alpha1.ScaleTriggers) (kedav1alpha1.ScaleTriggers, error) {
+	if trigger.TemplateRef == nil {
+		if trigger.Type == "" {
+			return trigger, fmt.Errorf("trigger has no type and no templateRef")
+		}
+		return trigger, nil
+	}
+
+	template := &kedav1alpha1.ClusterTriggerTemplate{}
+	if err := client.Get(ctx, types.NamespacedName{Name: trigger.TemplateRef.Name}, template); err != nil {
+		return trigger, fmt.Errorf("error getting ClusterTriggerTemplate %s: %s", trigger.TemplateRef.Name, err)
+	}
+	return applyTriggerTemplate(trigger, template)
+}
+
+func applyTriggerTemplate(trigger kedav1alpha1.ScaleTriggers, template *kedav1alpha1.ClusterTriggerTemplate) (kedav1alpha1.ScaleTriggers, error) {
+	if trigger.Type != "" && trigger.Type != template.Spec.Type {
+		return trigger, fmt.Errorf("trigger type %s doesn't match the type %s of ClusterTriggerTemplate %s", trigger.Type, template.Spec.Type, template.Name)
+	}
+
+	values, err := templateParameterValues(trigger.TemplateRef, template)
+	if err != nil {
+		return trigger, err
+	}
+
+	metadata := make(map[string]string, len(template.Spec.Metadata)+len(trigger.Metadata))
+	for key, value := range template.Spec.Metadata {
+		var unknown []string
+		metadata[key] = templatePlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+			name := strings.TrimSpace(templatePlaceholder.FindStringSubmatch(placeholder)[1])
+			value, ok := values[name]
+			if !ok {
+				unknown = append(unknown, name)
+			}
+			return value
+		})
+		if len(unknown) > 0 {
+			return trigger, fmt.Errorf("metadata %s of ClusterTriggerTemplate %s references undeclared parameters %s", key, template.Name, strings.Join(unknown, ", "))
+		}
+	}
+	// the trigger overrides the template
+	for key, value := range trigger.Metadata {
+		metadata[key] = value
+	}
+
+	resolved := *trigger.DeepCopy()
+	resolved.Type = template.Spec.Type
+	resolved.Metadata = metadata
+	if resolved.AuthenticationRef == nil && template.Spec.AuthenticationRef != nil {
+		authRef := *template.Spec.AuthenticationRef
+		resolved.AuthenticationRef = &authRef
+	}
+	return resolved, nil
+}
+
+// templateParameterValues merges the values of the trigger with the defaults of the template
+func templateParameterValues(ref *kedav1alpha1.TriggerTemplateRef, template *kedav1alpha1.ClusterTriggerTemplate) (map[string]string, error) {
+	values := make(map[string]string, len(template.Spec.Parameters))
+	declared := make(map[string]bool, len(template.Spec.Parameters))
+	var missing []string
+	for _, parameter := range template.Spec.Parameters {
+		declared[parameter.Name] = true
+		if value, ok := ref.Parameters[parameter.Name]; ok {
+			values[parameter.Name] = value
+		} else if parameter.Default != nil {
+			values[parameter.Name] = *parameter.Default
+		} else {
+			missing = append(missing, parameter.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing parameters %s of ClusterTriggerTemplate %s", strings.Join(missing, ", "), template.Name)
+	}
+
+	var unknown []string
+	for name := range ref.Parameters {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameters %s of ClusterTriggerTemplate %s", strings.Join(unknown, ", "), template.Name)
+	}
+	return values, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestResolveTriggerTemplate(t *testing.T) {
+	if err := kedav1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Errorf("Expected Error because: %v", err)
+	}
+	defaultDatabase := "0"
+	template := &kedav1alpha1.ClusterTriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-backlog"},
+		Spec: kedav1alpha1.ClusterTriggerTemplateSpec{
+			Type: "redis",
+			Metadata: map[string]string{
+				"address":       "redis.${namespace}:6379",
+				"listName":      "${ list }",
+				"databaseIndex": "${database}",
+				"listLength":    "10",
+			},
+			Parameters: []kedav1alpha1.TriggerTemplateParameter{
+				{Name: "namespace"},
+				{Name: "list"},
+				{Name: "database", Default: &defaultDatabase},
+			},
+			AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "redis", Kind: "ClusterTriggerAuthentication"},
+		},
+	}
+	undeclared := &kedav1alpha1.ClusterTriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "undeclared"},
+		Spec: kedav1alpha1.ClusterTriggerTemplateSpec{
+			Type:     "redis",
+			Metadata: map[string]string{"listName": "${list}"},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme.Scheme, template, undeclared)
+
+	tests := []struct {
+		name     string
+		trigger  kedav1alpha1.ScaleTriggers
+		expected kedav1alpha1.ScaleTriggers
+		isError  bool
+	}{
+		{
+			name:     "trigger without template",
+			trigger:  kedav1alpha1.ScaleTriggers{Type: "cron", Metadata: map[string]string{"start": "0 8 * * *"}},
+			expected: kedav1alpha1.ScaleTriggers{Type: "cron", Metadata: map[string]string{"start": "0 8 * * *"}},
+		},
+		{
+			name:    "trigger without type and template",
+			trigger: kedav1alpha1.ScaleTriggers{Metadata: map[string]string{}},
+			isError: true,
+		},
+		{
+			name: "parameters and defaults",
+			trigger: kedav1alpha1.ScaleTriggers{
+				Name:        "backlog",
+				Metadata:    map[string]string{"listLength": "20"},
+				TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps", "list": "jobs"}},
+			},
+			expected: kedav1alpha1.ScaleTriggers{
+				Type:              "redis",
+				Name:              "backlog",
+				Metadata:          map[string]string{"address": "redis.apps:6379", "listName": "jobs", "databaseIndex": "0", "listLength": "20"},
+				AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "redis", Kind: "ClusterTriggerAuthentication"},
+				TemplateRef:       &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps", "list": "jobs"}},
+			},
+		},
+		{
+			name: "authenticationRef of the trigger",
+			trigger: kedav1alpha1.ScaleTriggers{
+				AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "own"},
+				TemplateRef:       &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps", "list": "jobs", "database": "2"}},
+			},
+			expected: kedav1alpha1.ScaleTriggers{
+				Type:              "redis",
+				Metadata:          map[string]string{"address": "redis.apps:6379", "listName": "jobs", "databaseIndex": "2", "listLength": "10"},
+				AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "own"},
+				TemplateRef:       &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps", "list": "jobs", "database": "2"}},
+			},
+		},
+		{
+			name:    "missing parameter",
+			trigger: kedav1alpha1.ScaleTriggers{TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps"}}},
+			isError: true,
+		},
+		{
+			name:    "unknown parameter",
+			trigger: kedav1alpha1.ScaleTriggers{TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps", "list": "jobs", "db": "1"}}},
+			isError: true,
+		},
+		{
+			name:    "type mismatch",
+			trigger: kedav1alpha1.ScaleTriggers{Type: "rabbitmq", TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "redis-backlog", Parameters: map[string]string{"namespace": "apps", "list": "jobs"}}},
+			isError: true,
+		},
+		{
+			name:    "undeclared placeholder",
+			trigger: kedav1alpha1.ScaleTriggers{TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "undeclared"}},
+			isError: true,
+		},
+		{
+			name:    "missing template",
+			trigger: kedav1alpha1.ScaleTriggers{TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "notthere"}},
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := ResolveTriggerTemplate(context.TODO(), client, test.trigger)
+			if test.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected success but got error", err)
+			}
+			if diff := cmp.Diff(test.expected, resolved); diff != "" {
+				t.Errorf("Returned trigger is different: %s", diff)
+			}
+		})
+	}
+}
@@ -18,12 +18,17 @@ package scaling
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,11 +38,16 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/audit"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
 	"github.com/kedacore/keda/v2/pkg/scaling/executor"
+	"github.com/kedacore/keda/v2/pkg/scaling/kedaconfig"
 	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	"github.com/kedacore/keda/v2/pkg/scaling/schedule"
+	"github.com/kedacore/keda/v2/pkg/scaling/transform"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 // ScaleHandler encapsulates the logic of calling the right scalers for
@@ -46,9 +56,15 @@ type ScaleHandler interface {
 	HandleScalableObject(ctx context.Context, scalableObject interface{}) error
 	DeleteScalableObject(ctx context.Context, scalableObject interface{}) error
 	GetScalersCache(ctx context.Context, scalableObject interface{}) (*cache.ScalersCache, error)
-	ClearScalersCache(ctx context.Context, name, namespace string)
+	ClearScalersCache(ctx context.Context, scalableObject interface{}) error
+	// Start blocks until the context is done then drains the scale loops, it implements manager.Runnable
+	Start(ctx context.Context) error
 }
 
+// scaleLoopsDrainTimeout bounds the wait for the in-flight checks of the scale loops on shutdown, they are canceled
+// past it. It is below the default graceful shutdown timeout of the manager.
+const scaleLoopsDrainTimeout = 20 * time.Second
+
 type scaleHandler struct {
 	client            client.Client
 	logger            logr.Logger
@@ -58,10 +74,19 @@ type scaleHandler struct {
 	recorder          record.EventRecorder
 	scalerCaches      map[string]*cache.ScalersCache
 	lock              *sync.RWMutex
+	decisionLogger    audit.DecisionLogger
+
+	// loopsCtx is the parent of the scale loops, it is canceled on shutdown once the in-flight checks are drained
+	loopsCtx    context.Context
+	cancelLoops context.CancelFunc
+	// checksLock is held for reading by the in-flight checks, stopping is set once they are drained
+	checksLock sync.RWMutex
+	stopping   bool
 }
 
-// NewScaleHandler creates a ScaleHandler object
-func NewScaleHandler(client client.Client, scaleClient scale.ScalesGetter, reconcilerScheme *runtime.Scheme, globalHTTPTimeout time.Duration, recorder record.EventRecorder) ScaleHandler {
+// NewScaleHandler creates a ScaleHandler object, the scaling decisions are recorded in decisionLogger if it isn't nil
+func NewScaleHandler(client client.Client, scaleClient scale.ScalesGetter, reconcilerScheme *runtime.Scheme, globalHTTPTimeout time.Duration, recorder record.EventRecorder, decisionLogger audit.DecisionLogger) ScaleHandler {
+	loopsCtx, cancelLoops := context.WithCancel(context.Background())
 	return &scaleHandler{
 		client:            client,
 		logger:            logf.Log.WithName("scalehandler"),
@@ -71,7 +96,73 @@ func NewScaleHandler(client client.Client, scaleClient scale.ScalesGetter, recon
 		recorder:          recorder,
 		scalerCaches:      map[string]*cache.ScalersCache{},
 		lock:              &sync.RWMutex{},
+		decisionLogger:    decisionLogger,
+		loopsCtx:          loopsCtx,
+		cancelLoops:       cancelLoops,
+	}
+}
+
+// Start waits for the shutdown of the manager then drains the scale loops: no new check is started, the in-flight
+// checks complete so their scaling and status updates are sent, or they are canceled after scaleLoopsDrainTimeout,
+// and the scalers are closed
+func (h *scaleHandler) Start(ctx context.Context) error {
+	<-ctx.Done()
+	h.drain(scaleLoopsDrainTimeout)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the handlers of the metrics are drained on every replica
+func (h *scaleHandler) NeedLeaderElection() bool {
+	return false
+}
+
+func (h *scaleHandler) drain(timeout time.Duration) {
+	drained := make(chan struct{})
+	go func() {
+		// the lock is acquired once the in-flight checks release it, the checks started after it are skipped
+		h.checksLock.Lock()
+		h.stopping = true
+		h.checksLock.Unlock()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		h.logger.Info("Timeout draining the scale loops, canceling the in-flight checks", "timeout", timeout)
+		h.cancelLoops()
+		select {
+		case <-drained:
+		case <-time.After(timeout):
+			h.logger.Info("The in-flight checks have not returned, closing the scalers", "timeout", timeout)
+		}
 	}
+	h.cancelLoops()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for key, cache := range h.scalerCaches {
+		// the state is saved once the checks are done so the next operator starts from the last one
+		h.saveState(context.Background(), cache)
+		cache.Close(context.Background())
+		delete(h.scalerCaches, key)
+	}
+	h.logger.Info("Scale loops drained")
+}
+
+// startCheck registers an evaluation of the scalers, it returns false once the handler is drained and then
+// endCheck must not be called
+func (h *scaleHandler) startCheck() bool {
+	h.checksLock.RLock()
+	if h.stopping {
+		h.checksLock.RUnlock()
+		return false
+	}
+	return true
+}
+
+func (h *scaleHandler) endCheck() {
+	h.checksLock.RUnlock()
 }
 
 func (h *scaleHandler) HandleScalableObject(ctx context.Context, scalableObject interface{}) error {
@@ -81,8 +172,15 @@ func (h *scaleHandler) HandleScalableObject(ctx context.Context, scalableObject
 		return err
 	}
 
+	if !h.startCheck() {
+		h.logger.V(1).Info("Not starting the scale loop, the handler is shutting down", "object", scalableObject)
+		return nil
+	}
+	h.endCheck()
+
 	key := withTriggers.GenerateIdenitifier()
-	ctx, cancel := context.WithCancel(ctx)
+	// the scale loops outlive the reconcile, they are stopped by DeleteScalableObject or drained on shutdown
+	ctx, cancel := context.WithCancel(h.loopsCtx)
 
 	// cancel the outdated ScaleLoop for the same ScaledObject (if exists)
 	value, loaded := h.scaleLoopContexts.LoadOrStore(key, cancel)
@@ -98,15 +196,17 @@ func (h *scaleHandler) HandleScalableObject(ctx context.Context, scalableObject
 
 	// a mutex is used to synchronize scale requests per scalableObject
 	scalingMutex := &sync.Mutex{}
+	// the activation listeners wake up the scale loop, pending wake ups are coalesced
+	wake := make(chan struct{}, 1)
 
 	// passing deep copy of ScaledObject/ScaledJob to the scaleLoop go routines, it's a precaution to not have global objects shared between threads
 	switch obj := scalableObject.(type) {
 	case *kedav1alpha1.ScaledObject:
 		go h.startPushScalers(ctx, withTriggers, obj.DeepCopy(), scalingMutex)
-		go h.startScaleLoop(ctx, withTriggers, obj.DeepCopy(), scalingMutex)
+		go h.startScaleLoop(ctx, withTriggers, obj.DeepCopy(), scalingMutex, wake)
 	case *kedav1alpha1.ScaledJob:
 		go h.startPushScalers(ctx, withTriggers, obj.DeepCopy(), scalingMutex)
-		go h.startScaleLoop(ctx, withTriggers, obj.DeepCopy(), scalingMutex)
+		go h.startScaleLoop(ctx, withTriggers, obj.DeepCopy(), scalingMutex, wake)
 	}
 	return nil
 }
@@ -118,6 +218,10 @@ func (h *scaleHandler) DeleteScalableObject(ctx context.Context, scalableObject
 		return err
 	}
 
+	if _, ok := scalableObject.(*kedav1alpha1.ScaledObject); ok {
+		deleteReplicaMetrics(withTriggers.Namespace, withTriggers.Name)
+	}
+
 	key := withTriggers.GenerateIdenitifier()
 	result, ok := h.scaleLoopContexts.Load(key)
 	if ok {
@@ -130,28 +234,72 @@ func (h *scaleHandler) DeleteScalableObject(ctx context.Context, scalableObject
 	} else {
 		h.logger.V(1).Info("ScaleObject was not found in controller cache", "key", key)
 	}
+	if stateKey, err := scalerStateKey(scalableObject); err == nil {
+		h.deleteState(ctx, stateKey)
+	}
 
 	return nil
 }
 
-// startScaleLoop blocks forever and checks the scaledObject based on its pollingInterval
-func (h *scaleHandler) startScaleLoop(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, scalableObject interface{}, scalingMutex sync.Locker) {
+// startScaleLoop blocks forever and checks the scaledObject based on its pollingInterval, a wake up checks it right
+// away, then the next wake ups wait for the end of the polling interval so a busy broker doesn't flood the scalers
+func (h *scaleHandler) startScaleLoop(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, scalableObject interface{}, scalingMutex sync.Locker, wake chan struct{}) {
 	logger := h.logger.WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name)
 
 	pollingInterval := withTriggers.GetPollingInterval()
 	logger.V(1).Info("Watching with pollingInterval", "PollingInterval", pollingInterval)
 
+	// the listeners are bound to the current Scalers, they are restarted after the checks which replaced them
+	listeners := &activationListeners{}
+	h.bindActivationListeners(ctx, logger, scalableObject, listeners, wake)
+
+	stop := func() {
+		logger.V(1).Info("Context canceled")
+		listeners.stop()
+		// the scalers are closed with a live context, the one of the loop is done
+		h.clearScalersCache(context.Background(), scalersCacheKey(withTriggers))
+		if obj, ok := scalableObject.(*kedav1alpha1.ScaledObject); ok {
+			h.clearShadowScalersCache(context.Background(), obj)
+		}
+	}
+
+	wakeCh := wake
+	if offset := pollingOffset(withTriggers.Namespace, withTriggers.Name, pollingInterval); offset > 0 {
+		logger.V(1).Info("Delaying the first check to spread the polling", "offset", offset)
+		tmr := time.NewTimer(offset)
+		select {
+		case <-tmr.C:
+		case <-wakeCh:
+			logger.V(1).Info("Woken up by an activation listener")
+			tmr.Stop()
+		case <-ctx.Done():
+			tmr.Stop()
+			stop()
+			return
+		}
+	}
+
+	key, lastSync := scalersCacheKey(withTriggers), time.Now()
 	for {
 		tmr := time.NewTimer(pollingInterval)
 		h.checkScalers(ctx, scalableObject, scalingMutex)
+		// the state of a deleted object isn't saved again
+		if ctx.Err() == nil {
+			lastSync = h.syncState(ctx, key, lastSync)
+			h.bindActivationListeners(ctx, logger, scalableObject, listeners, wake)
+		}
 
 		select {
 		case <-tmr.C:
 			tmr.Stop()
+			wakeCh = wake
+		case <-wakeCh:
+			logger.V(1).Info("Woken up by an activation listener")
+			tmr.Stop()
+			wakeCh = nil
 		case <-ctx.Done():
-			logger.V(1).Info("Context canceled")
-			h.ClearScalersCache(ctx, withTriggers.Name, withTriggers.Namespace)
 			tmr.Stop()
+			stop()
 			return
 		}
 	}
@@ -163,10 +311,12 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 		return nil, err
 	}
 
-	key := strings.ToLower(fmt.Sprintf("%s.%s.%s", withTriggers.Kind, withTriggers.Name, withTriggers.Namespace))
+	key := scalersCacheKey(withTriggers)
+	valueFromChecksum := h.metadataValueFromChecksum(ctx, withTriggers)
+	_, settings := scalerSettings(h.globalHTTPTimeout)
 
 	h.lock.RLock()
-	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation {
+	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation && cache.ValueFromChecksum == valueFromChecksum && cache.LogVerbosity == withTriggers.GetLogVerbosity() && cache.SettingsGeneration == settings && !cache.IsExpired(time.Now()) {
 		h.lock.RUnlock()
 		return cache, nil
 	}
@@ -174,9 +324,11 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 
 	h.lock.Lock()
 	defer h.lock.Unlock()
-	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation {
+	var previousState []byte
+	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation && cache.ValueFromChecksum == valueFromChecksum && cache.LogVerbosity == withTriggers.GetLogVerbosity() && cache.SettingsGeneration == settings && !cache.IsExpired(time.Now()) {
 		return cache, nil
 	} else if ok {
+		previousState = h.getState(cache)
 		cache.Close(ctx)
 	}
 
@@ -185,23 +337,93 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 		return nil, err
 	}
 
+	stateKey, err := scalerStateKey(scalableObject)
+	if err != nil {
+		return nil, err
+	}
+
 	scalers := h.buildScalers(ctx, withTriggers, podTemplateSpec, containerName)
 
 	h.scalerCaches[key] = &cache.ScalersCache{
-		Generation: withTriggers.Generation,
-		Scalers:    scalers,
-		Logger:     h.logger,
-		Recorder:   h.recorder,
+		Generation:         withTriggers.Generation,
+		ValueFromChecksum:  valueFromChecksum,
+		LogVerbosity:       withTriggers.GetLogVerbosity(),
+		Scalers:            scalers,
+		Logger:             kedautil.WithVerbosityBoost(h.logger, withTriggers.GetLogVerbosity()),
+		Recorder:           h.recorder,
+		Expires:            credentialsExpiry(time.Now()),
+		SettingsGeneration: settings,
+		StateKey:           stateKey,
 	}
+	h.restoreState(ctx, h.scalerCaches[key], previousState)
 
 	return h.scalerCaches[key], nil
 }
 
-func (h *scaleHandler) ClearScalersCache(ctx context.Context, name, namespace string) {
+// scalersCacheKey returns the key of the cache of the Scalers of an object
+func scalersCacheKey(withTriggers *kedav1alpha1.WithTriggers) string {
+	return strings.ToLower(fmt.Sprintf("%s.%s.%s", withTriggers.Kind, withTriggers.Name, withTriggers.Namespace))
+}
+
+// credentialsExpiry returns when the scalers have to be rebuilt with the credentials read again, never when the
+// credentials aren't cached with a TTL
+func credentialsExpiry(now time.Time) time.Time {
+	ttl := resolver.CredentialsTTL()
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// metadataValueFromChecksum hashes the values the metadataValueFrom of the triggers point to, the
+// ClusterTriggerAuthentications selected by their authenticationRef and the versions of the TriggerAuthentications,
+// so a change of the credentials rebuilds the scalers. The ConfigMaps, Secrets and TriggerAuthentications are read
+// from the informer cache of the client
+func (h *scaleHandler) metadataValueFromChecksum(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers) string {
+	hash := sha256.New()
+	found := false
+	for index, trigger := range withTriggers.Spec.Triggers {
+		if trigger.AuthenticationRef != nil {
+			found = true
+			authRef, err := resolver.SelectAuthRef(ctx, h.client, trigger.AuthenticationRef)
+			var version string
+			if err == nil {
+				version, err = resolver.GetTriggerAuthVersion(ctx, h.client, authRef, withTriggers.Namespace)
+			}
+			if err == nil {
+				fmt.Fprintf(hash, "%d:authenticationRef:%s:%s\n", index, authRef.Name, version)
+			} else {
+				fmt.Fprintf(hash, "%d:authenticationRef:%v\n", index, err)
+			}
+		}
+		if len(trigger.MetadataValueFrom) == 0 {
+			continue
+		}
+		found = true
+		metadata, err := resolver.ResolveMetadataValueFrom(ctx, h.client, trigger, withTriggers.Namespace)
+		// fmt prints the maps sorted by key
+		fmt.Fprintf(hash, "%d:%v:%v\n", index, metadata, err)
+	}
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ClearScalersCache closes the Scalers of the ScaledObject or ScaledJob and drops their cache
+func (h *scaleHandler) ClearScalersCache(ctx context.Context, scalableObject interface{}) error {
+	withTriggers, err := asDuckWithTriggers(scalableObject)
+	if err != nil {
+		return err
+	}
+	h.clearScalersCache(ctx, scalersCacheKey(withTriggers))
+	return nil
+}
+
+func (h *scaleHandler) clearScalersCache(ctx context.Context, key string) {
 	h.lock.Lock()
 	defer h.lock.Unlock()
 
-	key := strings.ToLower(fmt.Sprintf("%s.%s", name, namespace))
 	if cache, ok := h.scalerCaches[key]; ok {
 		cache.Close(ctx)
 		delete(h.scalerCaches, key)
@@ -226,14 +448,23 @@ func (h *scaleHandler) startPushScalers(ctx context.Context, withTriggers *kedav
 				case <-ctx.Done():
 					return
 				case active := <-activeCh:
+					if !h.startCheck() {
+						return
+					}
 					scalingMutex.Lock()
 					switch obj := scalableObject.(type) {
 					case *kedav1alpha1.ScaledObject:
-						h.scaleExecutor.RequestScale(ctx, obj, active, false)
+						scheduled := h.applySchedules(obj)
+						if obj.IsDryRun() {
+							h.scaleExecutor.RequestDryRunScale(ctx, scheduled, active, false, cache.GetDesiredReplicaCount)
+							break
+						}
+						h.scaleExecutor.RequestScale(ctx, scheduled, active, false)
 					case *kedav1alpha1.ScaledJob:
 						h.logger.Info("Warning: External Push Scaler does not support ScaledJob", "object", scalableObject)
 					}
 					scalingMutex.Unlock()
+					h.endCheck()
 				}
 			}
 		}(ps)
@@ -243,6 +474,11 @@ func (h *scaleHandler) startPushScalers(ctx context.Context, withTriggers *kedav
 // checkScalers contains the main logic for the ScaleHandler scaling logic.
 // It'll check each trigger active status then call RequestScale
 func (h *scaleHandler) checkScalers(ctx context.Context, scalableObject interface{}, scalingMutex sync.Locker) {
+	if !h.startCheck() {
+		return
+	}
+	defer h.endCheck()
+
 	cache, err := h.GetScalersCache(ctx, scalableObject)
 	if err != nil {
 		h.logger.Error(err, "Error getting scalers", "object", scalableObject)
@@ -258,55 +494,239 @@ func (h *scaleHandler) checkScalers(ctx context.Context, scalableObject interfac
 			h.logger.Error(err, "Error getting scaledObject", "object", scalableObject)
 			return
 		}
-		isActive, isError, _ := cache.IsScaledObjectActive(ctx, obj)
-		h.scaleExecutor.RequestScale(ctx, obj, isActive, isError)
+		if !h.applyKedaConfig(ctx, obj) {
+			return
+		}
+		// the metrics of the check are queried once and shared by the decision log, the metrics and the scaling
+		state := cache.GetScaledObjectState(ctx, obj)
+		isActive, isError := state.IsActive, state.IsError
+		h.recordTriggerActivity(ctx, obj, state.ActiveTriggers, isActive, isError)
+		if !obj.IsDryRun() {
+			h.scaleExecutor.RecordBudget(ctx, obj)
+		}
+		scheduled := h.applySchedules(obj)
+		if h.decisionLogger != nil {
+			h.logScaledObjectDecision(ctx, scheduled, cache, state)
+		}
+		h.recordReplicaMetrics(ctx, scheduled, state)
+		h.checkShadowTriggers(ctx, scheduled, state)
+		if obj.IsDryRun() {
+			h.scaleExecutor.RequestDryRunScale(ctx, scheduled, isActive, isError, state.GetDesiredReplicaCount)
+			return
+		}
+		// the node autoscalers are signaled before the scale target is activated
+		h.scaleExecutor.RequestPreProvisioning(ctx, scheduled, isActive, isError, state.GetDesiredReplicaCount)
+		h.scaleExecutor.RequestScale(ctx, scheduled, isActive, isError)
 	case *kedav1alpha1.ScaledJob:
 		err = h.client.Get(ctx, types.NamespacedName{Name: obj.Name, Namespace: obj.Namespace}, obj)
 		if err != nil {
 			h.logger.Error(err, "Error getting scaledJob", "object", scalableObject)
 			return
 		}
-		isActive, scaleTo, maxScale := cache.IsScaledJobActive(ctx, obj)
-		h.scaleExecutor.RequestJobScale(ctx, obj, isActive, scaleTo, maxScale)
+		if !h.applyKedaConfig(ctx, obj) {
+			return
+		}
+		// the scale loop is stopped by the controller, a paused ScaledJob might be checked once more before that
+		if obj.IsPaused() {
+			return
+		}
+		state := cache.GetScaledJobState(ctx, obj)
+		scaleTo, maxScale := h.shareScaledJobQueue(ctx, obj, state.QueueLength, state.MaxValue)
+		if h.decisionLogger != nil {
+			h.logScaledJobDecision(obj, cache, state, maxScale)
+		}
+		h.scaleExecutor.RequestJobScale(ctx, obj, state.IsActive, scaleTo, maxScale, state.UrgencyTier)
 	}
 }
 
+// applyKedaConfig sets the defaults of the KedaConfigs on the object fetched from the API server,
+// it returns false if the object breaks their limits so it isn't scaled until they are fixed
+func (h *scaleHandler) applyKedaConfig(ctx context.Context, scalableObject interface{}) bool {
+	withTriggers, err := asDuckWithTriggers(scalableObject)
+	if err != nil {
+		h.logger.Error(err, "error duck typing object into withTrigger")
+		return false
+	}
+	policy, err := kedaconfig.Resolve(ctx, h.client, withTriggers.Namespace)
+	if err != nil {
+		h.logger.Error(err, "Error resolving the KedaConfigs", "object", scalableObject)
+		return false
+	}
+
+	switch obj := scalableObject.(type) {
+	case *kedav1alpha1.ScaledObject:
+		policy.ApplyToScaledObject(obj)
+		err = policy.ValidateScaledObject(ctx, h.client, obj)
+	case *kedav1alpha1.ScaledJob:
+		policy.ApplyToScaledJob(obj)
+		err = policy.ValidateScaledJob(ctx, h.client, obj)
+	}
+	if err != nil {
+		h.logger.Error(err, "Object breaks the limits of the KedaConfigs, skipping scaling", "object", scalableObject)
+		return false
+	}
+	return true
+}
+
+// applySchedules returns the ScaledObject with the replica bounds of its active schedule windows capped by its budget,
+// the bounds of the ScaledObject are used if the schedules or the budget are invalid
+func (h *scaleHandler) applySchedules(scaledObject *kedav1alpha1.ScaledObject) *kedav1alpha1.ScaledObject {
+	now := time.Now()
+	scheduled, _, err := schedule.Apply(scaledObject, now)
+	if err != nil {
+		h.logger.Error(err, "Error applying schedules", "object", scaledObject)
+		return scaledObject
+	}
+	budgeted, _, err := schedule.ApplyBudget(scheduled, now)
+	if err != nil {
+		h.logger.Error(err, "Error applying budget", "object", scaledObject)
+		return scheduled
+	}
+	return budgeted
+}
+
 // buildScalers returns list of Scalers for the specified triggers
 func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, podTemplateSpec *corev1.PodTemplateSpec, containerName string) []cache.ScalerBuilder {
-	logger := h.logger.WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name)
+	logger := kedautil.WithVerbosityBoost(h.logger, withTriggers.GetLogVerbosity()).WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name)
 	var err error
 	resolvedEnv := make(map[string]string)
 	result := make([]cache.ScalerBuilder, 0, len(withTriggers.Spec.Triggers))
+	globalHTTPTimeout, _ := scalerSettings(h.globalHTTPTimeout)
 
 	for scalerIndex, t := range withTriggers.Spec.Triggers {
 		triggerName, trigger := scalerIndex, t
+		trigger, err = resolver.ResolveTriggerTemplate(ctx, h.client, trigger)
+		if err != nil {
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			h.logger.Error(err, "error resolving trigger template", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+			continue
+		}
+		var queryKey string
+		var authParams map[string]string
+		redact := func(message string) string {
+			return resolver.RedactCredentials(message, authParams)
+		}
 		factory := func() (scalers.Scaler, error) {
+			if err := checkEgressEnforced(trigger.Type, withTriggers.Namespace); err != nil {
+				return nil, err
+			}
+			metadata, err := resolver.ResolveMetadataValueFrom(ctx, h.client, trigger, withTriggers.Namespace)
+			if err != nil {
+				return nil, err
+			}
 			if podTemplateSpec != nil {
 				resolvedEnv, err = resolver.ResolveContainerEnv(ctx, h.client, logger, &podTemplateSpec.Spec, containerName, withTriggers.Namespace)
 				if err != nil {
 					return nil, fmt.Errorf("error resolving secrets for ScaleTarget: %s", err)
 				}
 			}
+			metadata, err = resolver.ExpandMetadataTemplates(metadata, resolvedEnv)
+			if err != nil {
+				return nil, err
+			}
 			config := &scalers.ScalerConfig{
 				Name:              withTriggers.Name,
 				Namespace:         withTriggers.Namespace,
-				TriggerMetadata:   trigger.Metadata,
+				TriggerMetadata:   metadata,
 				ResolvedEnv:       resolvedEnv,
 				AuthParams:        make(map[string]string),
-				GlobalHTTPTimeout: h.globalHTTPTimeout,
+				GlobalHTTPTimeout: globalHTTPTimeout,
 				ScalerIndex:       scalerIndex,
+				MetricType:        trigger.MetricType,
+				LogVerbosity:      withTriggers.GetLogVerbosity(),
+			}
+			config.RetryPolicy, err = kedautil.ParseRetryPolicy(metadata)
+			if err != nil {
+				return nil, err
+			}
+			config.HTTPTransport, err = kedautil.ParseHTTPTransportOptions(metadata)
+			if err != nil {
+				return nil, err
 			}
 
 			config.AuthParams, config.PodIdentity, err = resolver.ResolveAuthRefAndPodIdentity(ctx, h.client, logger, trigger.AuthenticationRef, podTemplateSpec, withTriggers.Namespace)
 			if err != nil {
 				return nil, err
 			}
+			authParams = config.AuthParams
+			queryKey = triggerQueryKey(trigger.Type, config)
 
 			return buildScaler(ctx, h.client, trigger.Type, config)
 		}
 
+		var expression *transform.Expression
+		if trigger.Transform != "" {
+			expression, err = transform.Parse(trigger.Transform)
+			if err != nil {
+				h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+				h.logger.Error(err, "error parsing trigger transform", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+				continue
+			}
+		}
+
+		var rate *cache.RateTracker
+		switch trigger.MetricMode {
+		case "", kedav1alpha1.MetricModeValue:
+		case kedav1alpha1.MetricModeRate:
+			rate = cache.NewRateTracker()
+		default:
+			err := fmt.Errorf("unknown metricMode %q, supported are %s and %s", trigger.MetricMode, kedav1alpha1.MetricModeValue, kedav1alpha1.MetricModeRate)
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			h.logger.Error(err, "error parsing trigger metricMode", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+			continue
+		}
+
+		ratio, err := parseTriggerRatio(trigger, withTriggers.Spec.Triggers)
+		if err != nil {
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			h.logger.Error(err, "error parsing trigger ratio", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+			continue
+		}
+
+		soak, err := parseTriggerSoak(trigger)
+		if err != nil {
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			h.logger.Error(err, "error parsing trigger activation soak", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+			continue
+		}
+
+		triggerSchedule, err := schedule.ParseTriggerGate(trigger.Schedule)
+		if err != nil {
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			h.logger.Error(err, "error parsing trigger schedule", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+			continue
+		}
+
+		var weight float64
+		if trigger.Weight != nil {
+			weight = trigger.Weight.AsApproximateFloat64()
+			if weight <= 0 {
+				err := fmt.Errorf("the trigger weight must be greater than 0")
+				h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+				h.logger.Error(err, "error parsing trigger weight", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+				continue
+			}
+		}
+
+		switch trigger.MetricType {
+		case "", autoscalingv2beta2.AverageValueMetricType, autoscalingv2beta2.ValueMetricType:
+		case autoscalingv2beta2.UtilizationMetricType:
+			if trigger.Type != "cpu" && trigger.Type != "memory" {
+				err := fmt.Errorf("metricType %s is supported only by cpu and memory triggers", trigger.MetricType)
+				h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+				h.logger.Error(err, "error parsing trigger metricType", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+				continue
+			}
+		default:
+			err := fmt.Errorf("unknown metricType %q, supported are %s, %s and %s", trigger.MetricType, autoscalingv2beta2.AverageValueMetricType, autoscalingv2beta2.ValueMetricType, autoscalingv2beta2.UtilizationMetricType)
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			h.logger.Error(err, "error parsing trigger metricType", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
+			continue
+		}
+
 		scaler, err := factory()
 		if err != nil {
+			err = fmt.Errorf("%s", redact(err.Error()))
 			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
 			h.logger.Error(err, "error resolving auth params", "scalerIndex", scalerIndex, "object", withTriggers, "trigger", triggerName)
 			if scaler != nil {
@@ -315,18 +735,214 @@ func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alp
 			continue
 		}
 
+		var batch *cache.MetricBatch
+		if _, ok := scaler.(scalers.MultiMetricScaler); ok {
+			batch = cache.NewMetricBatch()
+		}
+
+		var maxMetricAge time.Duration
+		if trigger.MaxMetricAge != nil && *trigger.MaxMetricAge > 0 {
+			maxMetricAge = time.Duration(*trigger.MaxMetricAge) * time.Second
+		}
+
 		result = append(result, cache.ScalerBuilder{
-			Scaler:  scaler,
-			Factory: factory,
+			Scaler:       scaler,
+			Factory:      factory,
+			TriggerName:  trigger.Name,
+			TriggerType:  trigger.Type,
+			Rate:         rate,
+			Transform:    expression,
+			MetricType:   trigger.MetricType,
+			Weight:       weight,
+			Ratio:        ratio,
+			BackendHost:  triggerBackendHost(trigger.Metadata),
+			QueryKey:     queryKey,
+			Batch:        batch,
+			MaxMetricAge: maxMetricAge,
+			Soak:         soak,
+			Schedule:     triggerSchedule,
+			Redact:       redact,
 		})
 	}
 
 	return result
 }
 
+// parseTriggerSoak returns the SoakTracker of the trigger, nil if it activates on the first active check
+func parseTriggerSoak(trigger kedav1alpha1.ScaleTriggers) (*cache.SoakTracker, error) {
+	requiredSamples, window := 1, time.Duration(0)
+	if trigger.RequiredConsecutiveSamples != nil {
+		if *trigger.RequiredConsecutiveSamples < 1 {
+			return nil, fmt.Errorf("requiredConsecutiveSamples must be greater than 0, got %d", *trigger.RequiredConsecutiveSamples)
+		}
+		requiredSamples = int(*trigger.RequiredConsecutiveSamples)
+	}
+	if trigger.ActivationWindow != nil {
+		if *trigger.ActivationWindow < 0 {
+			return nil, fmt.Errorf("activationWindow can't be negative, got %d", *trigger.ActivationWindow)
+		}
+		window = time.Duration(*trigger.ActivationWindow) * time.Second
+	}
+	if requiredSamples == 1 && window == 0 {
+		return nil, nil
+	}
+	return cache.NewSoakTracker(requiredSamples, window), nil
+}
+
+// parseTriggerRatio returns the RatioTracker of the trigger, nil if it has no ratio
+func parseTriggerRatio(trigger kedav1alpha1.ScaleTriggers, triggers []kedav1alpha1.ScaleTriggers) (*cache.RatioTracker, error) {
+	if trigger.Ratio == nil {
+		return nil, nil
+	}
+
+	denominator := trigger.Ratio.Denominator
+	if denominator == "" || denominator == trigger.Name {
+		return nil, fmt.Errorf("the ratio denominator must be the name of another trigger")
+	}
+	found := false
+	for _, t := range triggers {
+		found = found || t.Name == denominator
+	}
+	if !found {
+		return nil, fmt.Errorf("the ratio denominator %s is not a trigger", denominator)
+	}
+
+	var valueIfZero *float64
+	if trigger.Ratio.ValueIfZero != "" {
+		value, err := strconv.ParseFloat(trigger.Ratio.ValueIfZero, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ratio valueIfZero: %s", err)
+		}
+		valueIfZero = &value
+	}
+
+	var maxAge time.Duration
+	if trigger.Ratio.MaxDenominatorAgeSeconds != nil {
+		maxAge = time.Duration(*trigger.Ratio.MaxDenominatorAgeSeconds) * time.Second
+	}
+	return cache.NewRatioTracker(denominator, valueIfZero, maxAge), nil
+}
+
+// unsharedQueryTriggers are the trigger types whose values depend on the ScaledObject, not only on the trigger
+var unsharedQueryTriggers = map[string]bool{"alertmanager": true, "cpu": true, "memory": true, "object": true, "external": true, "external-push": true, "webhook": true}
+
+// triggerQueryKey hashes what the values of the trigger depend on, the triggers with the same key query the same
+// values from the same backend with the same credentials, it is empty for the triggers that can't share their values
+func triggerQueryKey(triggerType string, config *scalers.ScalerConfig) string {
+	if unsharedQueryTriggers[triggerType] {
+		return ""
+	}
+
+	hash := sha256.New()
+	env := map[string]string{}
+	for key, value := range config.TriggerMetadata {
+		if strings.HasSuffix(key, "FromEnv") {
+			env[value] = config.ResolvedEnv[value]
+		}
+	}
+	// fmt prints the maps sorted by key
+	fmt.Fprintf(hash, "%s\n%s\n%v\n%v\n%v\n", triggerType, config.MetricType, config.TriggerMetadata, config.AuthParams, env)
+	// the pod identities and the objects read by kubernetes-workload, tekton, argo-workflows, request-concurrency and
+	// simulator are the ones of the namespace
+	switch {
+	case config.PodIdentity != "" && config.PodIdentity != kedav1alpha1.PodIdentityProviderNone,
+		triggerType == "kubernetes-workload", triggerType == "tekton", triggerType == "argo-workflows", triggerType == "request-concurrency",
+		triggerType == "simulator":
+		fmt.Fprintf(hash, "%s\n%s\n", config.PodIdentity, config.Namespace)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// backendAddressKeys are the metadata keys the scalers read the address of their backend from
+var backendAddressKeys = []string{"serverAddress", "host", "address", "url", "brokerAddress", "bootstrapServers", "managementEndpoint", "serverURL", "scalerAddress", "queueURL", "brokerURL"}
+
+// triggerBackendHost returns the host of the backend queried by the trigger for the per host rate limits
+// of the metric queries, it is empty when the metadata doesn't name the backend
+func triggerBackendHost(metadata map[string]string) string {
+	for _, key := range backendAddressKeys {
+		address := strings.TrimSpace(strings.Split(metadata[key], ",")[0])
+		if address == "" {
+			continue
+		}
+		if u, err := url.Parse(address); err == nil && u.Host != "" {
+			return strings.ToLower(u.Host)
+		}
+		// host:port addresses, they can hold credentials
+		if i := strings.LastIndex(address, "@"); i >= 0 {
+			address = address[i+1:]
+		}
+		return strings.ToLower(address)
+	}
+	return ""
+}
+
+// egressEnforcedTriggers are the trigger types whose connections go through the shared HTTP client or the
+// EgressDialer, or which don't connect outside of the cluster. The clients of the other trigger types, eg. the
+// Kafka, SQL, MongoDB, Cassandra and cloud SDK clients, dial on their own and aren't covered by the egress policy.
+var egressEnforcedTriggers = map[string]bool{
+	"alertmanager":           true,
+	"arangodb":               true,
+	"argo-workflows":         true,
+	"artemis-queue":          true,
+	"azure-blob":             true,
+	"azure-log-analytics":    true,
+	"azure-pipelines":        true,
+	"azure-queue":            true,
+	"couchdb":                true,
+	"cpu":                    true,
+	"cron":                   true,
+	"druid":                  true,
+	"envoy-concurrency":      true,
+	"graphite":               true,
+	"honeycomb":              true,
+	"http-requests":          true,
+	"ibmmq":                  true,
+	"influxdb":               true,
+	"keda-federation":        true,
+	"kubernetes-workload":    true,
+	"memcached":              true,
+	"memory":                 true,
+	"metrics-api":            true,
+	"object":                 true,
+	"prometheus":             true,
+	"rabbitmq":               true,
+	"redis":                  true,
+	"redis-cluster":          true,
+	"redis-cluster-streams":  true,
+	"redis-sentinel":         true,
+	"redis-sentinel-streams": true,
+	"redis-streams":          true,
+	"request-concurrency":    true,
+	"selenium-grid":          true,
+	"simulator":              true,
+	"slo-burn-rate":          true,
+	"solace-event-queue":     true,
+	"stan":                   true,
+	"sumologic":              true,
+	"tekton":                 true,
+	"trino":                  true,
+	"wasm":                   true,
+	"webhook":                true,
+}
+
+// checkEgressEnforced refuses the trigger types not covered by the egress policy in the restricted namespaces,
+// their connections would bypass the allow-list of the namespace
+func checkEgressEnforced(triggerType, namespace string) error {
+	if kedautil.EgressRestricted(namespace) && !egressEnforcedTriggers[triggerType] {
+		return fmt.Errorf("trigger type %s is not allowed in namespace %s, its connections aren't covered by the egress policy", triggerType, namespace)
+	}
+	return nil
+}
+
 func buildScaler(ctx context.Context, client client.Client, triggerType string, config *scalers.ScalerConfig) (scalers.Scaler, error) {
 	// TRIGGERS-START
 	switch triggerType {
+	case "alertmanager":
+		return scalers.NewAlertmanagerScaler(config)
+	case "arangodb":
+		return scalers.NewArangoDBScaler(config)
+	case "argo-workflows":
+		return scalers.NewArgoWorkflowsScaler(client, config)
 	case "artemis-queue":
 		return scalers.NewArtemisQueueScaler(config)
 	case "aws-cloudwatch":
@@ -351,10 +967,18 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewAzureServiceBusScaler(ctx, config)
 	case "cassandra":
 		return scalers.NewCassandraScaler(config)
+	case "couchdb":
+		return scalers.NewCouchDBScaler(config)
 	case "cpu":
 		return scalers.NewCPUMemoryScaler(corev1.ResourceCPU, config)
 	case "cron":
 		return scalers.NewCronScaler(config)
+	case "db2":
+		return scalers.NewDb2Scaler(config)
+	case "druid":
+		return scalers.NewDruidScaler(config)
+	case "envoy-concurrency":
+		return scalers.NewEnvoyConcurrencyScaler(config)
 	case "external":
 		return scalers.NewExternalScaler(config)
 	case "external-push":
@@ -363,6 +987,10 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewPubSubScaler(config)
 	case "graphite":
 		return scalers.NewGraphiteScaler(config)
+	case "honeycomb":
+		return scalers.NewHoneycombScaler(config)
+	case "http-requests":
+		return scalers.NewHTTPRequestsScaler(config)
 	case "huawei-cloudeye":
 		return scalers.NewHuaweiCloudeyeScaler(config)
 	case "ibmmq":
@@ -371,10 +999,14 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewInfluxDBScaler(config)
 	case "kafka":
 		return scalers.NewKafkaScaler(config)
+	case "keda-federation":
+		return scalers.NewKedaFederationScaler(config)
 	case "kubernetes-workload":
 		return scalers.NewKubernetesWorkloadScaler(client, config)
 	case "liiklus":
 		return scalers.NewLiiklusScaler(config)
+	case "memcached":
+		return scalers.NewMemcachedScaler(config)
 	case "memory":
 		return scalers.NewCPUMemoryScaler(corev1.ResourceMemory, config)
 	case "metrics-api":
@@ -385,6 +1017,8 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewMSSQLScaler(config)
 	case "mysql":
 		return scalers.NewMySQLScaler(config)
+	case "object":
+		return scalers.NewObjectScaler(config)
 	case "openstack-metric":
 		return scalers.NewOpenstackMetricScaler(ctx, config)
 	case "openstack-swift":
@@ -407,12 +1041,28 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewRedisStreamsScaler(ctx, false, true, config)
 	case "redis-streams":
 		return scalers.NewRedisStreamsScaler(ctx, false, false, config)
+	case "request-concurrency":
+		return scalers.NewRequestConcurrencyScaler(client, config)
 	case "selenium-grid":
 		return scalers.NewSeleniumGridScaler(config)
+	case "simulator":
+		return scalers.NewSimulatorScaler(client, config)
+	case "slo-burn-rate":
+		return scalers.NewSLOBurnRateScaler(config)
 	case "solace-event-queue":
 		return scalers.NewSolaceScaler(config)
 	case "stan":
 		return scalers.NewStanScaler(config)
+	case "sumologic":
+		return scalers.NewSumoLogicScaler(config)
+	case "tekton":
+		return scalers.NewTektonScaler(client, config)
+	case "trino":
+		return scalers.NewTrinoScaler(config)
+	case "wasm":
+		return scalers.NewWasmScaler(ctx, config)
+	case "webhook":
+		return scalers.NewWebhookScaler(config)
 	default:
 		return nil, fmt.Errorf("no scaler found for type: %s", triggerType)
 	}
@@ -422,8 +1072,13 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 func asDuckWithTriggers(scalableObject interface{}) (*kedav1alpha1.WithTriggers, error) {
 	switch obj := scalableObject.(type) {
 	case *kedav1alpha1.ScaledObject:
+		// the objects built by hand, eg. from a reconcile request, have no TypeMeta
+		typeMeta := obj.TypeMeta
+		if typeMeta.Kind == "" {
+			typeMeta.Kind = "ScaledObject"
+		}
 		return &kedav1alpha1.WithTriggers{
-			TypeMeta:   obj.TypeMeta,
+			TypeMeta:   typeMeta,
 			ObjectMeta: obj.ObjectMeta,
 			Spec: kedav1alpha1.WithTriggersSpec{
 				PollingInterval: obj.Spec.PollingInterval,
@@ -431,8 +1086,12 @@ func asDuckWithTriggers(scalableObject interface{}) (*kedav1alpha1.WithTriggers,
 			},
 		}, nil
 	case *kedav1alpha1.ScaledJob:
+		typeMeta := obj.TypeMeta
+		if typeMeta.Kind == "" {
+			typeMeta.Kind = "ScaledJob"
+		}
 		return &kedav1alpha1.WithTriggers{
-			TypeMeta:   obj.TypeMeta,
+			TypeMeta:   typeMeta,
 			ObjectMeta: obj.ObjectMeta,
 			Spec: kedav1alpha1.WithTriggersSpec{
 				PollingInterval: obj.Spec.PollingInterval,
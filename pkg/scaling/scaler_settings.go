@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// httpTimeout replaces the global HTTP timeout the scale handlers are created with, 0 keeps it
+	httpTimeout time.Duration
+	// settingsGeneration is incremented when a setting the scalers are built with changes, the scalers built
+	// before are then rebuilt on their next check
+	settingsGeneration int64
+	settingsLock       sync.RWMutex
+)
+
+// SetHTTPTimeout replaces the global HTTP timeout of the scalers, 0 restores the one the scale handlers were created
+// with. The scalers are rebuilt with it
+func SetHTTPTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("the HTTP timeout can't be negative, got %s", timeout)
+	}
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	if timeout != httpTimeout {
+		httpTimeout = timeout
+		settingsGeneration++
+	}
+	return nil
+}
+
+// RebuildScalers rebuilds the scalers on their next check, eg. once the options of the HTTP transports changed
+func RebuildScalers() {
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+	settingsGeneration++
+}
+
+// scalerSettings returns the global HTTP timeout of the scalers built by a scale handler created with
+// defaultTimeout and the generation of the settings
+func scalerSettings(defaultTimeout time.Duration) (time.Duration, int64) {
+	settingsLock.RLock()
+	defer settingsLock.RUnlock()
+	if httpTimeout > 0 {
+		return httpTimeout, settingsGeneration
+	}
+	return defaultTimeout, settingsGeneration
+}
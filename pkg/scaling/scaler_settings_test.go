@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalerSettings(t *testing.T) {
+	defer func() { _ = SetHTTPTimeout(0) }()
+
+	timeout, generation := scalerSettings(3 * time.Second)
+	assert.Equal(t, 3*time.Second, timeout)
+
+	assert.Error(t, SetHTTPTimeout(-time.Second))
+	assert.NoError(t, SetHTTPTimeout(10*time.Second))
+	timeout, reloaded := scalerSettings(3 * time.Second)
+	assert.Equal(t, 10*time.Second, timeout)
+	assert.NotEqual(t, generation, reloaded)
+
+	// the same timeout doesn't rebuild the scalers
+	assert.NoError(t, SetHTTPTimeout(10*time.Second))
+	_, same := scalerSettings(3 * time.Second)
+	assert.Equal(t, reloaded, same)
+
+	RebuildScalers()
+	_, rebuilt := scalerSettings(3 * time.Second)
+	assert.NotEqual(t, reloaded, rebuilt)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/state"
+)
+
+// stateStoreTimeout bounds the calls to the state store, a slow store doesn't hold the scale loops
+const stateStoreTimeout = 5 * time.Second
+
+// scalerStateKey returns the key the state of the Scalers of an object is persisted under, the kind is the one of
+// the type of the object as its TypeMeta isn't always set, so the states saved by the ScaledObject and the ScaledJob
+// handlers for the objects with the same name never end up under the same key
+func scalerStateKey(scalableObject interface{}) (string, error) {
+	switch obj := scalableObject.(type) {
+	case *kedav1alpha1.ScaledObject:
+		return strings.ToLower(fmt.Sprintf("ScaledObject.%s.%s", obj.Name, obj.Namespace)), nil
+	case *kedav1alpha1.ScaledJob:
+		return strings.ToLower(fmt.Sprintf("ScaledJob.%s.%s", obj.Name, obj.Namespace)), nil
+	default:
+		return "", fmt.Errorf("unknown scalable object type %v", scalableObject)
+	}
+}
+
+// getState returns the state of the Scalers of a cache, nil when it isn't persisted
+func (h *scaleHandler) getState(c *cache.ScalersCache) []byte {
+	if state.GetStore() == nil || c.StateKey == "" {
+		return nil
+	}
+	data, err := c.State()
+	if err != nil {
+		h.logger.Error(err, "Error getting the state of the scalers", "key", c.StateKey)
+		return nil
+	}
+	return data
+}
+
+// restoreState restores the state of the Scalers of a new cache, previous is the state of the cache it replaces so
+// the state of the last check isn't lost, the persisted state is loaded when it is nil
+func (h *scaleHandler) restoreState(ctx context.Context, c *cache.ScalersCache, previous []byte) {
+	store := state.GetStore()
+	if store == nil || c.StateKey == "" {
+		return
+	}
+	if previous != nil {
+		c.RestoreState(previous)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, stateStoreTimeout)
+	defer cancel()
+	data, err := store.Load(ctx, c.StateKey)
+	if err != nil {
+		h.logger.Error(err, "Error loading the state of the scalers, starting without it", "key", c.StateKey)
+		return
+	}
+	c.RestoreState(data)
+}
+
+// saveState persists the state of the Scalers of a cache, nothing is saved when they have none
+func (h *scaleHandler) saveState(ctx context.Context, c *cache.ScalersCache) {
+	data := h.getState(c)
+	if data == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, stateStoreTimeout)
+	defer cancel()
+	if err := state.GetStore().Save(ctx, c.StateKey, data); err != nil {
+		h.logger.Error(err, "Error saving the state of the scalers", "key", c.StateKey)
+	}
+}
+
+// syncState saves the state of the Scalers of the object of key once the sync interval elapsed since lastSync,
+// it returns the time of the last save
+func (h *scaleHandler) syncState(ctx context.Context, key string, lastSync time.Time) time.Time {
+	if state.GetStore() == nil || time.Since(lastSync) < state.SyncInterval() {
+		return lastSync
+	}
+
+	h.lock.RLock()
+	c, ok := h.scalerCaches[key]
+	h.lock.RUnlock()
+	if !ok {
+		return lastSync
+	}
+	h.saveState(ctx, c)
+	return time.Now()
+}
+
+// deleteState removes the persisted state of the Scalers of the object of key
+func (h *scaleHandler) deleteState(ctx context.Context, key string) {
+	store := state.GetStore()
+	if store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, stateStoreTimeout)
+	defer cancel()
+	if err := store.Delete(ctx, key); err != nil {
+		h.logger.Error(err, "Error deleting the state of the scalers", "key", key)
+	}
+}
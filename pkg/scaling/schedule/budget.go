@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	// defaultMaxReplicaCount is the maxReplicas of the HPA when the ScaledObject doesn't set it
+	defaultMaxReplicaCount int32 = 100
+
+	// maxBudgetRecordInterval is the longest interval recorded at once, the replicas of the scale target
+	// are unknown when it wasn't recorded for longer, eg. while the operator was down
+	maxBudgetRecordInterval = 10 * time.Minute
+)
+
+type pricingWindow struct {
+	location *time.Location
+	start    cron.Schedule
+	end      cron.Schedule
+	max      int32
+}
+
+// ValidateBudget checks that the budget is correctly specified
+func ValidateBudget(budget *kedav1alpha1.Budget) error {
+	if budget == nil {
+		return nil
+	}
+	if budget.MaxReplicaHoursPerDay != nil && *budget.MaxReplicaHoursPerDay < 0 {
+		return fmt.Errorf("maxReplicaHoursPerDay=%d of the budget must not be negative", *budget.MaxReplicaHoursPerDay)
+	}
+	if _, err := budgetLocation(budget); err != nil {
+		return err
+	}
+	_, err := parsePricingWindows(budget.PricingWindows)
+	return err
+}
+
+// RecordBudget adds the replica-seconds consumed since the last record to the budget status of the ScaledObject,
+// the consumption restarts at the beginning of each day. A scale target without replicas consumes nothing, the record
+// is then cleared so the status doesn't change while it stays scaled to zero. It does nothing if the budget has no
+// MaxReplicaHoursPerDay.
+func RecordBudget(scaledObject *kedav1alpha1.ScaledObject, replicas int32, now time.Time) error {
+	budget := scaledObject.Spec.Budget
+	if budget == nil || budget.MaxReplicaHoursPerDay == nil {
+		return nil
+	}
+	location, err := budgetLocation(budget)
+	if err != nil {
+		return err
+	}
+
+	t := now.In(location)
+	day := t.Format("2006-01-02")
+	status := scaledObject.Status.Budget
+	if status == nil {
+		status = &kedav1alpha1.BudgetStatus{}
+		scaledObject.Status.Budget = status
+	}
+	var since time.Time
+	if status.LastUpdateTime != nil {
+		since = status.LastUpdateTime.Time
+	}
+	if status.Day != day {
+		status.Day = day
+		status.ReplicaSeconds = 0
+		if startOfDay := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location); since.Before(startOfDay) {
+			since = startOfDay
+		}
+	}
+
+	if elapsed := now.Sub(since); !since.IsZero() && elapsed > 0 && elapsed <= maxBudgetRecordInterval {
+		status.ReplicaSeconds += int64(replicas) * int64(elapsed/time.Second)
+	}
+	if replicas == 0 {
+		status.LastUpdateTime = nil
+		return nil
+	}
+	status.LastUpdateTime = &metav1.Time{Time: now}
+	return nil
+}
+
+// BudgetCap returns the replica count the budget caps the ScaledObject at with the reason of the cap, nil if it doesn't
+// lower its MaxReplicaCount, and the time of the next start or end of a pricing window or day
+func BudgetCap(scaledObject *kedav1alpha1.ScaledObject, now time.Time) (*int32, string, time.Time, error) {
+	budget := scaledObject.Spec.Budget
+	if budget == nil {
+		return nil, "", time.Time{}, nil
+	}
+	location, err := budgetLocation(budget)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	windows, err := parsePricingWindows(budget.PricingWindows)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	maxReplicas := defaultMaxReplicaCount
+	if scaledObject.Spec.MaxReplicaCount != nil {
+		maxReplicas = *scaledObject.Spec.MaxReplicaCount
+	}
+	// the budget never caps the replicas below the minimum, at least 1 like the HPA
+	floor := int32(1)
+	if scaledObject.Spec.MinReplicaCount != nil && *scaledObject.Spec.MinReplicaCount > floor {
+		floor = *scaledObject.Spec.MinReplicaCount
+	}
+
+	capped := maxReplicas
+	var reason string
+	var nextChange time.Time
+	for i, w := range windows {
+		t := now.In(w.location)
+		nextStart, nextEnd := w.start.Next(t), w.end.Next(t)
+		if nextEnd.Before(nextStart) && w.max < capped {
+			capped = w.max
+			reason = fmt.Sprintf("pricing window %s", pricingWindowName(budget.PricingWindows[i], i))
+		}
+		nextChange = earliest(nextChange, nextStart, nextEnd)
+	}
+
+	if budget.MaxReplicaHoursPerDay != nil {
+		t := now.In(location)
+		nextChange = earliest(nextChange, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location))
+		status := scaledObject.Status.Budget
+		if status != nil && status.Day == t.Format("2006-01-02") && status.ReplicaSeconds >= int64(*budget.MaxReplicaHoursPerDay)*3600 && floor < capped {
+			capped = floor
+			reason = fmt.Sprintf("maxReplicaHoursPerDay=%d spent", *budget.MaxReplicaHoursPerDay)
+		}
+	}
+
+	if capped < floor {
+		capped = floor
+	}
+	if capped >= maxReplicas {
+		return nil, "", nextChange, nil
+	}
+	return &capped, reason, nextChange, nil
+}
+
+// ApplyBudget returns a copy of the ScaledObject with the MaxReplicaCount capped by its budget and the time the cap
+// can change next. The ScaledObject itself is returned if the budget doesn't cap it.
+func ApplyBudget(scaledObject *kedav1alpha1.ScaledObject, now time.Time) (*kedav1alpha1.ScaledObject, time.Time, error) {
+	capped, _, nextChange, err := BudgetCap(scaledObject, now)
+	if err != nil || capped == nil {
+		return scaledObject, nextChange, err
+	}
+	budgeted := scaledObject.DeepCopy()
+	budgeted.Spec.MaxReplicaCount = capped
+	return budgeted, nextChange, nil
+}
+
+func budgetLocation(budget *kedav1alpha1.Budget) (*time.Location, error) {
+	if budget.Timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(budget.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone of the budget: %s", err)
+	}
+	return location, nil
+}
+
+func parsePricingWindows(windows []kedav1alpha1.PricingWindow) ([]pricingWindow, error) {
+	parsed := make([]pricingWindow, 0, len(windows))
+	for i, w := range windows {
+		name := pricingWindowName(w, i)
+		location := time.UTC
+		if w.Timezone != "" {
+			var err error
+			location, err = time.LoadLocation(w.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone of pricing window %s: %s", name, err)
+			}
+		}
+		start, err := parser.Parse(w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of pricing window %s: %s", name, err)
+		}
+		end, err := parser.Parse(w.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of pricing window %s: %s", name, err)
+		}
+		if w.MaxReplicaCount < 0 {
+			return nil, fmt.Errorf("maxReplicaCount=%d of pricing window %s must not be negative", w.MaxReplicaCount, name)
+		}
+		parsed = append(parsed, pricingWindow{location: location, start: start, end: end, max: w.MaxReplicaCount})
+	}
+	return parsed, nil
+}
+
+func pricingWindowName(w kedav1alpha1.PricingWindow, i int) string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return fmt.Sprintf("%d", i)
+}
+
+func earliest(current time.Time, values ...time.Time) time.Time {
+	for _, value := range values {
+		if !value.IsZero() && (current.IsZero() || value.Before(current)) {
+			current = value
+		}
+	}
+	return current
+}
@@ -0,0 +1,129 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func newBudgetScaledObject(budget *kedav1alpha1.Budget) *kedav1alpha1.ScaledObject {
+	return &kedav1alpha1.ScaledObject{
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			MinReplicaCount: int32Ptr(2),
+			MaxReplicaCount: int32Ptr(20),
+			Budget:          budget,
+		},
+	}
+}
+
+func TestRecordBudget(t *testing.T) {
+	scaledObject := newBudgetScaledObject(&kedav1alpha1.Budget{MaxReplicaHoursPerDay: int32Ptr(10)})
+	start := time.Date(2021, 12, 20, 23, 30, 0, 0, time.UTC)
+
+	// the first record only starts the consumption
+	if err := RecordBudget(scaledObject, 4, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordBudget(scaledObject, 4, start.Add(30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if status := scaledObject.Status.Budget; status.Day != "2021-12-20" || status.ReplicaSeconds != 120 {
+		t.Errorf("expected 120 replica-seconds on 2021-12-20, got %d on %s", status.ReplicaSeconds, status.Day)
+	}
+
+	// intervals longer than maxBudgetRecordInterval aren't recorded
+	if err := RecordBudget(scaledObject, 4, start.Add(12*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if status := scaledObject.Status.Budget; status.ReplicaSeconds != 120 {
+		t.Errorf("expected 120 replica-seconds, got %d", status.ReplicaSeconds)
+	}
+
+	// the next day only counts the seconds after midnight
+	if err := RecordBudget(scaledObject, 3, start.Add(30*time.Minute+20*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if status := scaledObject.Status.Budget; status.Day != "2021-12-21" || status.ReplicaSeconds != 60 {
+		t.Errorf("expected 60 replica-seconds on 2021-12-21, got %d on %s", status.ReplicaSeconds, status.Day)
+	}
+
+	// the scale target without replicas clears the record, the consumption restarts with the next replicas
+	if err := RecordBudget(scaledObject, 0, start.Add(31*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if status := scaledObject.Status.Budget; status.ReplicaSeconds != 60 || status.LastUpdateTime != nil {
+		t.Errorf("expected 60 replica-seconds without record time, got %d at %v", status.ReplicaSeconds, status.LastUpdateTime)
+	}
+	if err := RecordBudget(scaledObject, 2, start.Add(40*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if status := scaledObject.Status.Budget; status.ReplicaSeconds != 60 || status.LastUpdateTime == nil {
+		t.Errorf("expected 60 replica-seconds with a record time, got %d at %v", status.ReplicaSeconds, status.LastUpdateTime)
+	}
+}
+
+func TestBudgetCap(t *testing.T) {
+	budget := &kedav1alpha1.Budget{
+		MaxReplicaHoursPerDay: int32Ptr(10),
+		PricingWindows: []kedav1alpha1.PricingWindow{
+			{Name: "peak", Start: "0 17 * * *", End: "0 21 * * *", MaxReplicaCount: 5},
+			{Name: "night", Start: "0 22 * * *", End: "0 23 * * *", MaxReplicaCount: 1},
+		},
+	}
+	scaledObject := newBudgetScaledObject(budget)
+
+	capped, _, nextChange, err := BudgetCap(scaledObject, time.Date(2021, 12, 20, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capped != nil || !nextChange.Equal(time.Date(2021, 12, 20, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected no cap until 17:00, got %v until %s", capped, nextChange)
+	}
+
+	capped, reason, nextChange, _ := BudgetCap(scaledObject, time.Date(2021, 12, 20, 18, 0, 0, 0, time.UTC))
+	if capped == nil || *capped != 5 || reason != "pricing window peak" || !nextChange.Equal(time.Date(2021, 12, 20, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the peak window to cap at 5 until 21:00, got %v (%s) until %s", capped, reason, nextChange)
+	}
+
+	// the budget never caps below minReplicaCount
+	capped, _, _, _ = BudgetCap(scaledObject, time.Date(2021, 12, 20, 22, 30, 0, 0, time.UTC))
+	if capped == nil || *capped != 2 {
+		t.Errorf("expected the night window to cap at minReplicaCount 2, got %v", capped)
+	}
+
+	scaledObject.Status.Budget = &kedav1alpha1.BudgetStatus{Day: "2021-12-20", ReplicaSeconds: 36000, LastUpdateTime: &metav1.Time{}}
+	capped, reason, nextChange, _ = BudgetCap(scaledObject, time.Date(2021, 12, 20, 12, 0, 0, 0, time.UTC))
+	if capped == nil || *capped != 2 || reason != "maxReplicaHoursPerDay=10 spent" || !nextChange.Equal(time.Date(2021, 12, 20, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the spent budget to cap at 2, got %v (%s) until %s", capped, reason, nextChange)
+	}
+	budgeted, _, _ := ApplyBudget(scaledObject, time.Date(2021, 12, 20, 12, 0, 0, 0, time.UTC))
+	if *budgeted.Spec.MaxReplicaCount != 2 || *scaledObject.Spec.MaxReplicaCount != 20 {
+		t.Errorf("expected a copy capped at 2, got %d and the original %d", *budgeted.Spec.MaxReplicaCount, *scaledObject.Spec.MaxReplicaCount)
+	}
+
+	// the consumption of the previous day doesn't count
+	capped, _, nextChange, _ = BudgetCap(scaledObject, time.Date(2021, 12, 21, 1, 0, 0, 0, time.UTC))
+	if capped != nil || !nextChange.Equal(time.Date(2021, 12, 21, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected no cap on the next day, got %v until %s", capped, nextChange)
+	}
+}
+
+func TestValidateBudgetInvalid(t *testing.T) {
+	invalid := []*kedav1alpha1.Budget{
+		{MaxReplicaHoursPerDay: int32Ptr(-1)},
+		{Timezone: "Mars/Olympus"},
+		{PricingWindows: []kedav1alpha1.PricingWindow{{Start: "0 17 * *", End: "0 21 * * *", MaxReplicaCount: 5}}},
+		{PricingWindows: []kedav1alpha1.PricingWindow{{Start: "0 17 * * *", End: "0 21 * * *", MaxReplicaCount: -1}}},
+	}
+	for i, budget := range invalid {
+		if err := ValidateBudget(budget); err == nil {
+			t.Errorf("%d: expected error", i)
+		}
+	}
+	if err := ValidateBudget(nil); err != nil {
+		t.Error(err)
+	}
+}
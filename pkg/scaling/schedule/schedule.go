@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule implements the schedule windows of ScaledObjects,
+// they override the replica bounds of the scale target during time ranges.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+type window struct {
+	location *time.Location
+	start    cron.Schedule
+	end      cron.Schedule
+	min      *int32
+	max      *int32
+}
+
+// Validate checks that the schedule windows are correctly specified
+func Validate(windows []kedav1alpha1.ScheduleWindow) error {
+	_, err := parseWindows(windows)
+	return err
+}
+
+// Apply returns a copy of the ScaledObject with the replica bounds of its active schedule windows
+// and the time of the next start or end of a window. If more windows are active, the highest bounds win.
+// The ScaledObject itself is returned if it doesn't define any schedule window.
+func Apply(scaledObject *kedav1alpha1.ScaledObject, now time.Time) (*kedav1alpha1.ScaledObject, time.Time, error) {
+	if len(scaledObject.Spec.Schedules) == 0 {
+		return scaledObject, time.Time{}, nil
+	}
+
+	windows, err := parseWindows(scaledObject.Spec.Schedules)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var min, max *int32
+	var nextChange time.Time
+	for _, w := range windows {
+		t := now.In(w.location)
+		nextStart, nextEnd := w.start.Next(t), w.end.Next(t)
+		if nextEnd.Before(nextStart) {
+			min, max = highest(min, w.min), highest(max, w.max)
+		}
+		for _, next := range []time.Time{nextStart, nextEnd} {
+			if !next.IsZero() && (nextChange.IsZero() || next.Before(nextChange)) {
+				nextChange = next
+			}
+		}
+	}
+
+	scheduled := scaledObject.DeepCopy()
+	if min != nil {
+		scheduled.Spec.MinReplicaCount = min
+	}
+	if max != nil {
+		scheduled.Spec.MaxReplicaCount = max
+	}
+	// a window raising the minimum above the maximum raises the maximum too
+	if scheduled.Spec.MinReplicaCount != nil && scheduled.Spec.MaxReplicaCount != nil && *scheduled.Spec.MinReplicaCount > *scheduled.Spec.MaxReplicaCount {
+		scheduled.Spec.MaxReplicaCount = scheduled.Spec.MinReplicaCount
+	}
+	return scheduled, nextChange, nil
+}
+
+func parseWindows(windows []kedav1alpha1.ScheduleWindow) ([]window, error) {
+	parsed := make([]window, 0, len(windows))
+	for i, w := range windows {
+		name := w.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+
+		location := time.UTC
+		if w.Timezone != "" {
+			var err error
+			location, err = time.LoadLocation(w.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone of schedule %s: %s", name, err)
+			}
+		}
+		start, err := parser.Parse(w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of schedule %s: %s", name, err)
+		}
+		end, err := parser.Parse(w.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of schedule %s: %s", name, err)
+		}
+		if w.MinReplicaCount == nil && w.MaxReplicaCount == nil {
+			return nil, fmt.Errorf("schedule %s must override minReplicaCount or maxReplicaCount", name)
+		}
+		if w.MinReplicaCount != nil && *w.MinReplicaCount < 0 {
+			return nil, fmt.Errorf("minReplicaCount=%d of schedule %s must not be negative", *w.MinReplicaCount, name)
+		}
+		if w.MinReplicaCount != nil && w.MaxReplicaCount != nil && *w.MinReplicaCount > *w.MaxReplicaCount {
+			return nil, fmt.Errorf("minReplicaCount=%d of schedule %s must be less than maxReplicaCount=%d", *w.MinReplicaCount, name, *w.MaxReplicaCount)
+		}
+
+		parsed = append(parsed, window{location: location, start: start, end: end, min: w.MinReplicaCount, max: w.MaxReplicaCount})
+	}
+	return parsed, nil
+}
+
+func highest(current, value *int32) *int32 {
+	if value == nil || (current != nil && *current >= *value) {
+		return current
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+type applyTestData struct {
+	name       string
+	now        time.Time
+	min        int32
+	max        int32
+	nextChange time.Time
+}
+
+var businessHours = []kedav1alpha1.ScheduleWindow{
+	{Name: "business-hours", Start: "0 8 * * 1-5", End: "0 18 * * 1-5", MinReplicaCount: int32Ptr(5)},
+	{Name: "batch", Timezone: "Europe/Prague", Start: "0 1 * * *", End: "0 3 * * *", MinReplicaCount: int32Ptr(2), MaxReplicaCount: int32Ptr(30)},
+}
+
+// 2021-11-15 is a Monday
+var applyTestDataset = []applyTestData{
+	{"outside of windows", time.Date(2021, 11, 15, 7, 0, 0, 0, time.UTC), 1, 10, time.Date(2021, 11, 15, 8, 0, 0, 0, time.UTC)},
+	{"window start", time.Date(2021, 11, 15, 8, 0, 0, 0, time.UTC), 5, 10, time.Date(2021, 11, 15, 18, 0, 0, 0, time.UTC)},
+	{"weekend", time.Date(2021, 11, 20, 12, 0, 0, 0, time.UTC), 1, 10, time.Date(2021, 11, 21, 0, 0, 0, 0, time.UTC)},
+	// 01:30 in Prague is 00:30 UTC
+	{"timezone", time.Date(2021, 11, 16, 0, 30, 0, 0, time.UTC), 2, 30, time.Date(2021, 11, 16, 2, 0, 0, 0, time.UTC)},
+}
+
+func TestApply(t *testing.T) {
+	for _, testData := range applyTestDataset {
+		scaledObject := &kedav1alpha1.ScaledObject{
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				MinReplicaCount: int32Ptr(1),
+				MaxReplicaCount: int32Ptr(10),
+				Schedules:       businessHours,
+			},
+		}
+		scheduled, nextChange, err := Apply(scaledObject, testData.now)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", testData.name, err)
+			continue
+		}
+		if *scheduled.Spec.MinReplicaCount != testData.min || *scheduled.Spec.MaxReplicaCount != testData.max {
+			t.Errorf("%s: expected bounds %d-%d, got %d-%d", testData.name, testData.min, testData.max, *scheduled.Spec.MinReplicaCount, *scheduled.Spec.MaxReplicaCount)
+		}
+		if !nextChange.Equal(testData.nextChange) {
+			t.Errorf("%s: expected next change at %s, got %s", testData.name, testData.nextChange, nextChange)
+		}
+		if *scaledObject.Spec.MinReplicaCount != 1 {
+			t.Errorf("%s: the original ScaledObject was modified", testData.name)
+		}
+	}
+}
+
+func TestApplyRaisesMaxReplicaCount(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			MaxReplicaCount: int32Ptr(3),
+			Schedules:       []kedav1alpha1.ScheduleWindow{{Start: "0 0 * * *", End: "59 23 * * *", MinReplicaCount: int32Ptr(5)}},
+		},
+	}
+	scheduled, _, err := Apply(scaledObject, time.Date(2021, 11, 15, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *scheduled.Spec.MaxReplicaCount != 5 {
+		t.Errorf("expected maxReplicaCount 5, got %d", *scheduled.Spec.MaxReplicaCount)
+	}
+}
+
+func TestApplyWithoutSchedules(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	scheduled, nextChange, err := Apply(scaledObject, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheduled != scaledObject || !nextChange.IsZero() {
+		t.Error("expected the ScaledObject to be returned unchanged")
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	invalid := [][]kedav1alpha1.ScheduleWindow{
+		{{Start: "0 8 * * *", End: "0 18 * * *"}},
+		{{Start: "0 8 * *", End: "0 18 * * *", MinReplicaCount: int32Ptr(1)}},
+		{{Start: "0 8 * * *", End: "0 25 * * *", MinReplicaCount: int32Ptr(1)}},
+		{{Timezone: "Mars/Olympus", Start: "0 8 * * *", End: "0 18 * * *", MinReplicaCount: int32Ptr(1)}},
+		{{Start: "0 8 * * *", End: "0 18 * * *", MinReplicaCount: int32Ptr(5), MaxReplicaCount: int32Ptr(2)}},
+		{{Start: "0 8 * * *", End: "0 18 * * *", MinReplicaCount: int32Ptr(-1)}},
+	}
+	for i, windows := range invalid {
+		if err := Validate(windows); err == nil {
+			t.Errorf("%d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type triggerWindow struct {
+	location *time.Location
+	start    cron.Schedule
+	end      cron.Schedule
+}
+
+// TriggerGate is the schedule of a trigger, the trigger is only evaluated when one of its windows is open
+type TriggerGate struct {
+	windows []triggerWindow
+}
+
+// ParseTriggerGate returns the TriggerGate of the schedule windows of a trigger, nil if it has no windows
+func ParseTriggerGate(windows []kedav1alpha1.TriggerScheduleWindow) (*TriggerGate, error) {
+	if len(windows) == 0 {
+		return nil, nil
+	}
+
+	gate := &TriggerGate{windows: make([]triggerWindow, 0, len(windows))}
+	for i, w := range windows {
+		location := time.UTC
+		if w.Timezone != "" {
+			var err error
+			location, err = time.LoadLocation(w.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone of trigger schedule %d: %s", i, err)
+			}
+		}
+		start, err := parser.Parse(w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of trigger schedule %d: %s", i, err)
+		}
+		end, err := parser.Parse(w.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of trigger schedule %d: %s", i, err)
+		}
+		gate.windows = append(gate.windows, triggerWindow{location: location, start: start, end: end})
+	}
+	return gate, nil
+}
+
+// IsOpen returns true if one of the windows is open at now, a nil TriggerGate is always open
+func (g *TriggerGate) IsOpen(now time.Time) bool {
+	if g == nil {
+		return true
+	}
+	for _, w := range g.windows {
+		t := now.In(w.location)
+		// a window is open when it ends before it starts again
+		if w.end.Next(t).Before(w.start.Next(t)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type triggerGateTestData struct {
+	name string
+	now  time.Time
+	open bool
+}
+
+// 2021-11-15 is a Monday, 09:00 in New York is 14:00 UTC
+var triggerGateTestDataset = []triggerGateTestData{
+	{"before the window", time.Date(2021, 11, 15, 13, 59, 0, 0, time.UTC), false},
+	{"window start", time.Date(2021, 11, 15, 14, 0, 0, 0, time.UTC), true},
+	{"within the window", time.Date(2021, 11, 15, 20, 0, 0, 0, time.UTC), true},
+	{"window end", time.Date(2021, 11, 15, 22, 0, 0, 0, time.UTC), false},
+	{"weekend", time.Date(2021, 11, 20, 15, 0, 0, 0, time.UTC), false},
+	{"second window", time.Date(2021, 11, 20, 2, 0, 0, 0, time.UTC), true},
+}
+
+func TestTriggerGateIsOpen(t *testing.T) {
+	gate, err := ParseTriggerGate([]kedav1alpha1.TriggerScheduleWindow{
+		{Timezone: "America/New_York", Start: "0 9 * * 1-5", End: "0 17 * * 1-5"},
+		{Start: "0 1 * * 6", End: "0 3 * * 6"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, testData := range triggerGateTestDataset {
+		if open := gate.IsOpen(testData.now); open != testData.open {
+			t.Errorf("%s: expected open %v, got %v", testData.name, testData.open, open)
+		}
+	}
+}
+
+func TestTriggerGateWithoutWindows(t *testing.T) {
+	gate, err := ParseTriggerGate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gate.IsOpen(time.Now()) {
+		t.Error("expected a trigger without schedule to be always open")
+	}
+}
+
+func TestParseTriggerGateErrors(t *testing.T) {
+	invalid := [][]kedav1alpha1.TriggerScheduleWindow{
+		{{Timezone: "Mars/Olympus", Start: "0 9 * * *", End: "0 17 * * *"}},
+		{{Start: "every morning", End: "0 17 * * *"}},
+		{{Start: "0 9 * * *", End: ""}},
+	}
+	for _, windows := range invalid {
+		if _, err := ParseTriggerGate(windows); err == nil {
+			t.Errorf("expected an error parsing %v", windows)
+		}
+	}
+}
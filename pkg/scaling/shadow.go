@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+)
+
+// checkShadowTriggers computes the replica counts of the shadow triggers and of the triggers of the ScaledObject
+// and records them, the shadow triggers are only evaluated, the scale target isn't scaled on them
+func (h *scaleHandler) checkShadowTriggers(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, liveState *cache.ScaledObjectState) {
+	if scaledObject.Spec.Shadow == nil {
+		h.clearShadowScalersCache(ctx, scaledObject)
+		if scaledObject.Status.Shadow != nil {
+			h.scaleExecutor.RecordShadowReplicaCount(ctx, scaledObject, 0, 0)
+		}
+		return
+	}
+	// the evaluation of the current generation is completed, the triggers aren't queried anymore
+	if status := scaledObject.Status.Shadow; status != nil && status.Completed && status.ObservedGeneration == scaledObject.Generation {
+		h.clearShadowScalersCache(ctx, scaledObject)
+		return
+	}
+
+	shadowCache, err := h.getShadowScalersCache(ctx, scaledObject)
+	if err != nil {
+		h.logger.Error(err, "Error getting shadow scalers", "object", scaledObject)
+		return
+	}
+	shadowScaledObject := scaledObject.DeepCopy()
+	shadowScaledObject.Spec.Triggers = scaledObject.Spec.Shadow.Triggers
+	shadowIsActive, shadowIsError, _ := shadowCache.IsScaledObjectActive(ctx, shadowScaledObject)
+
+	_, liveReplicas, err := h.scaleExecutor.EstimateReplicaCount(ctx, scaledObject, liveState.IsActive, liveState.IsError, liveState.GetDesiredReplicaCount)
+	if err != nil {
+		h.logger.V(1).Info("Error estimating replica count of the triggers", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "error", err)
+		return
+	}
+	_, shadowReplicas, err := h.scaleExecutor.EstimateReplicaCount(ctx, shadowScaledObject, shadowIsActive, shadowIsError, shadowCache.GetDesiredReplicaCount)
+	if err != nil {
+		h.logger.V(1).Info("Error estimating replica count of the shadow triggers", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "error", err)
+		return
+	}
+	h.scaleExecutor.RecordShadowReplicaCount(ctx, scaledObject, liveReplicas, shadowReplicas)
+}
+
+// getShadowScalersCache returns the scalers of the shadow triggers, they are rebuilt when the ScaledObject changes
+func (h *scaleHandler) getShadowScalersCache(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (*cache.ScalersCache, error) {
+	withTriggers, err := asDuckWithTriggers(scaledObject)
+	if err != nil {
+		return nil, err
+	}
+	withTriggers.Spec.Triggers = scaledObject.Spec.Shadow.Triggers
+
+	key := shadowScalersCacheKey(scaledObject)
+	valueFromChecksum := h.metadataValueFromChecksum(ctx, withTriggers)
+	_, settings := scalerSettings(h.globalHTTPTimeout)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation && cache.ValueFromChecksum == valueFromChecksum && cache.SettingsGeneration == settings && !cache.IsExpired(time.Now()) {
+		return cache, nil
+	} else if ok {
+		cache.Close(ctx)
+	}
+
+	podTemplateSpec, containerName, err := resolver.ResolveScaleTargetPodSpec(ctx, h.client, h.logger, scaledObject)
+	if err != nil {
+		return nil, err
+	}
+
+	h.scalerCaches[key] = &cache.ScalersCache{
+		Generation:         withTriggers.Generation,
+		ValueFromChecksum:  valueFromChecksum,
+		Scalers:            h.buildScalers(ctx, withTriggers, podTemplateSpec, containerName),
+		Logger:             h.logger,
+		Recorder:           h.recorder,
+		Expires:            credentialsExpiry(time.Now()),
+		SettingsGeneration: settings,
+	}
+	return h.scalerCaches[key], nil
+}
+
+// clearShadowScalersCache closes the scalers of the shadow triggers
+func (h *scaleHandler) clearShadowScalersCache(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) {
+	key := shadowScalersCacheKey(scaledObject)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if cache, ok := h.scalerCaches[key]; ok {
+		cache.Close(ctx)
+		delete(h.scalerCaches, key)
+	}
+}
+
+func shadowScalersCacheKey(scaledObject *kedav1alpha1.ScaledObject) string {
+	return strings.ToLower(fmt.Sprintf("shadow.%s.%s", scaledObject.Name, scaledObject.Namespace))
+}
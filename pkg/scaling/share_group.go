@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// shareScaledJobQueue returns the share of the queue length and of the max scale of a ScaledJob of a ShareGroup, the
+// queue read by its triggers is consumed by all the ScaledJobs of the group so each of them only creates the Jobs of
+// its Weight. The paused ScaledJobs don't take a share
+func (h *scaleHandler) shareScaledJobQueue(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, queueLength, maxScale int64) (int64, int64) {
+	group := scaledJob.Spec.ScalingStrategy.ShareGroup
+	if group == "" {
+		return queueLength, maxScale
+	}
+
+	scaledJobs := &kedav1alpha1.ScaledJobList{}
+	if err := h.client.List(ctx, scaledJobs, client.InNamespace(scaledJob.Namespace)); err != nil {
+		// the whole queue is over-provisioned rather than left without Jobs
+		h.logger.Error(err, "Error listing the ScaledJobs of the share group, not sharing the queue", "scaledJob.Namespace", scaledJob.Namespace, "scaledJob.Name", scaledJob.Name, "shareGroup", group)
+		return queueLength, maxScale
+	}
+
+	weight := scaledJob.Spec.ScalingStrategy.ShareWeight()
+	totalWeight := weight
+	for i := range scaledJobs.Items {
+		member := &scaledJobs.Items[i]
+		if member.Name == scaledJob.Name || member.Spec.ScalingStrategy.ShareGroup != group || member.IsPaused() {
+			continue
+		}
+		totalWeight += member.Spec.ScalingStrategy.ShareWeight()
+	}
+
+	share := func(value int64) int64 {
+		// rounded up so the small queues aren't left without Jobs, the group over-provisions by less than one Job
+		// per member
+		return (value*weight + totalWeight - 1) / totalWeight
+	}
+	h.logger.V(1).Info("Sharing the queue of the ScaledJob", "scaledJob.Namespace", scaledJob.Namespace, "scaledJob.Name", scaledJob.Name,
+		"shareGroup", group, "weight", weight, "totalWeight", totalWeight, "queueLength", queueLength, "maxScale", maxScale)
+	return share(queueLength), share(maxScale)
+}
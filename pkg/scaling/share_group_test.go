@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestShareScaledJobQueue(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+
+	scaledJob := func(name, namespace, group string, weight int32, paused bool) *kedav1alpha1.ScaledJob {
+		sj := &kedav1alpha1.ScaledJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       kedav1alpha1.ScaledJobSpec{ScalingStrategy: kedav1alpha1.ScalingStrategy{ShareGroup: group}},
+		}
+		if weight > 0 {
+			sj.Spec.ScalingStrategy.Weight = &weight
+		}
+		if paused {
+			sj.Annotations = map[string]string{kedav1alpha1.PausedAnnotation: "true"}
+		}
+		return sj
+	}
+	small := scaledJob("small", "ci", "builds", 0, false)
+	large := scaledJob("large", "ci", "builds", 3, false)
+	alone := scaledJob("alone", "ci", "", 0, false)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		small, large, alone,
+		scaledJob("paused", "ci", "builds", 4, true),
+		scaledJob("other-group", "ci", "tests", 0, false),
+		scaledJob("other-namespace", "qa", "builds", 0, false),
+	).Build()
+	h := NewScaleHandler(kubeClient, nil, scheme, 0, record.NewFakeRecorder(1), nil).(*scaleHandler)
+
+	queueLength, maxScale := h.shareScaledJobQueue(context.Background(), large, 10, 5)
+	assert.Equal(t, int64(8), queueLength)
+	assert.Equal(t, int64(4), maxScale)
+
+	queueLength, maxScale = h.shareScaledJobQueue(context.Background(), small, 10, 5)
+	assert.Equal(t, int64(3), queueLength)
+	assert.Equal(t, int64(2), maxScale)
+
+	// the small queues aren't left without Jobs
+	queueLength, _ = h.shareScaledJobQueue(context.Background(), small, 1, 1)
+	assert.Equal(t, int64(1), queueLength)
+
+	queueLength, maxScale = h.shareScaledJobQueue(context.Background(), alone, 10, 5)
+	assert.Equal(t, int64(10), queueLength)
+	assert.Equal(t, int64(5), maxScale)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	configMapPrefix = "keda-state-"
+	// configMapDataKey is the key of the state in the binary data of the ConfigMaps
+	configMapDataKey = "state"
+	// configMapKeyAnnotation is the key of the state, the names of the ConfigMaps are sanitized
+	configMapKeyAnnotation = "keda.sh/state-key"
+	// maxConfigMapName is the maximal length of the names of the ConfigMaps, the longer ones are hashed
+	maxConfigMapName = 253
+)
+
+var invalidConfigMapNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+type configMapStore struct {
+	client    client.Client
+	reader    client.Reader
+	namespace string
+}
+
+// NewConfigMapStore creates a Store keeping every key in a ConfigMap of namespace. The ConfigMaps are read with
+// reader, eg. the API reader of the manager so the ConfigMaps of the cluster aren't watched
+func NewConfigMapStore(kubeClient client.Client, reader client.Reader, namespace string) (Store, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("the namespace of the ConfigMaps of the state store must be given")
+	}
+	return &configMapStore{client: kubeClient, reader: reader, namespace: namespace}, nil
+}
+
+func (s *configMapStore) Load(ctx context.Context, key string) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.reader.Get(ctx, types.NamespacedName{Name: configMapName(key), Namespace: s.namespace}, configMap)
+	switch {
+	case errors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("error reading the state ConfigMap: %s", err)
+	}
+	return configMap.BinaryData[configMapDataKey], nil
+}
+
+func (s *configMapStore) Save(ctx context.Context, key string, data []byte) error {
+	configMap := &corev1.ConfigMap{}
+	err := s.reader.Get(ctx, types.NamespacedName{Name: configMapName(key), Namespace: s.namespace}, configMap)
+	switch {
+	case errors.IsNotFound(err):
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        configMapName(key),
+				Namespace:   s.namespace,
+				Labels:      map[string]string{"app.kubernetes.io/managed-by": "keda-operator"},
+				Annotations: map[string]string{configMapKeyAnnotation: key},
+			},
+			BinaryData: map[string][]byte{configMapDataKey: data},
+		}
+		if err := s.client.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("error creating the state ConfigMap: %s", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("error reading the state ConfigMap: %s", err)
+	}
+
+	configMap.BinaryData = map[string][]byte{configMapDataKey: data}
+	if err := s.client.Update(ctx, configMap); err != nil {
+		return fmt.Errorf("error updating the state ConfigMap: %s", err)
+	}
+	return nil
+}
+
+func (s *configMapStore) Delete(ctx context.Context, key string) error {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName(key), Namespace: s.namespace}}
+	if err := s.client.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting the state ConfigMap: %s", err)
+	}
+	return nil
+}
+
+// configMapName returns the name of the ConfigMap of key, the keys too long for a name are hashed
+func configMapName(key string) string {
+	name := configMapPrefix + invalidConfigMapNameChars.ReplaceAllString(key, "-")
+	if len(name) <= maxConfigMapName {
+		return name
+	}
+	hash := sha256.Sum256([]byte(key))
+	suffix := "-" + hex.EncodeToString(hash[:8])
+	return name[:maxConfigMapName-len(suffix)] + suffix
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapStore(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewClientBuilder().Build()
+	store, err := NewConfigMapStore(kubeClient, kubeClient, "keda")
+	assert.NoError(t, err)
+
+	data, err := store.Load(ctx, "scaledobject.orders.shop")
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+
+	assert.NoError(t, store.Save(ctx, "scaledobject.orders.shop", []byte("first")))
+	assert.NoError(t, store.Save(ctx, "scaledobject.orders.shop", []byte("second")))
+	data, err = store.Load(ctx, "scaledobject.orders.shop")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Name: "keda-state-scaledobject.orders.shop", Namespace: "keda"}, configMap))
+	assert.Equal(t, "scaledobject.orders.shop", configMap.Annotations[configMapKeyAnnotation])
+
+	assert.NoError(t, store.Delete(ctx, "scaledobject.orders.shop"))
+	assert.NoError(t, store.Delete(ctx, "scaledobject.orders.shop"))
+	data, err = store.Load(ctx, "scaledobject.orders.shop")
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+
+	_, err = NewConfigMapStore(kubeClient, kubeClient, "")
+	assert.Error(t, err)
+}
+
+func TestConfigMapName(t *testing.T) {
+	assert.Equal(t, "keda-state-scaledjob.my-job.shop", configMapName("scaledjob.my_job.shop"))
+
+	long := configMapName("scaledobject." + strings.Repeat("a", 300) + ".shop")
+	assert.Len(t, long, maxConfigMapName)
+	assert.NotEqual(t, long, configMapName("scaledobject."+strings.Repeat("a", 300)+".other"))
+}
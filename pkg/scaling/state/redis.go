@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisKeyPrefix namespaces the keys of the state in the Redis database
+const redisKeyPrefix = "keda:state:"
+
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a Store keeping every key in the Redis server at address
+func NewRedisStore(address, password string) (Store, error) {
+	if address == "" {
+		return nil, fmt.Errorf("the address of the Redis server of the state store must be given")
+	}
+	return &redisStore{client: redis.NewClient(&redis.Options{Addr: address, Password: password})}, nil
+}
+
+func (s *redisStore) Load(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("error reading the state from Redis: %s", err)
+	}
+	return data, nil
+}
+
+func (s *redisStore) Save(ctx context.Context, key string, data []byte) error {
+	if err := s.client.Set(ctx, redisKeyPrefix+key, data, 0).Err(); err != nil {
+		return fmt.Errorf("error writing the state to Redis: %s", err)
+	}
+	return nil
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, redisKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("error deleting the state from Redis: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package state persists the state the scalers and the metric modes of the triggers keep between their checks, eg.
+// the previous samples of the rate metric mode or the last value of a query, so it survives the restarts of the
+// operator. The state of a ScaledObject and of a ScaledJob are persisted under distinct keys, by the handler of
+// their kind.
+//
+// Only the scalers implementing scalers.StatefulScaler have a state, no scaler keeps a circuit-breaker state or
+// last-seen offsets between its checks (eg. the Kafka scaler queries the offsets on every check), so there are
+// none to persist.
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store persists the state of the ScaledObjects and ScaledJobs, one value per key
+type Store interface {
+	// Load returns the value of key, nil when it isn't stored
+	Load(ctx context.Context, key string) ([]byte, error)
+	// Save stores the value of key, replacing the previous one
+	Save(ctx context.Context, key string, data []byte) error
+	// Delete removes key, it isn't an error when it isn't stored
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	// store is set at startup from the flags of the operator, nil doesn't persist the state
+	store Store
+	// syncInterval is the minimal time between two saves of the state of a ScaledObject or ScaledJob
+	syncInterval time.Duration
+	storeLock    sync.RWMutex
+)
+
+// SetStore sets the store the state is persisted to, the state of a ScaledObject or ScaledJob is saved at most once
+// per interval. A nil store doesn't persist the state
+func SetStore(s Store, interval time.Duration) error {
+	if s != nil && interval <= 0 {
+		return fmt.Errorf("the sync interval of the state store must be greater than 0, got %s", interval)
+	}
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	store, syncInterval = s, interval
+	return nil
+}
+
+// SetSyncInterval changes the minimal time between two saves of the state, the store is kept
+func SetSyncInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("the sync interval of the state store must be greater than 0, got %s", interval)
+	}
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	syncInterval = interval
+	return nil
+}
+
+// GetStore returns the store the state is persisted to, nil when the state isn't persisted
+func GetStore() Store {
+	storeLock.RLock()
+	defer storeLock.RUnlock()
+	return store
+}
+
+// SyncInterval returns the minimal time between two saves of the state of a ScaledObject or ScaledJob
+func SyncInterval() time.Duration {
+	storeLock.RLock()
+	defer storeLock.RUnlock()
+	return syncInterval
+}
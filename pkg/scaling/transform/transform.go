@@ -0,0 +1,327 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package transform implements the arithmetic expressions applied to trigger values,
+// eg. `value * 0.001 + 5` or `ceil(value / 10)`.
+package transform
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ValueVariable is the name of the raw trigger value in the expression
+const ValueVariable = "value"
+
+// Expression is a parsed transform expression
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses the expression, supported are numbers, the value variable, + - * / operators,
+// parentheses and functions abs, ceil, floor, round, min and max
+func Parse(expression string) (*Expression, error) {
+	p := &parser{input: expression}
+	p.next()
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid transform %q: %s", expression, err)
+	}
+	if p.token.kind != tokenEOF {
+		return nil, fmt.Errorf("invalid transform %q: unexpected %q at position %d", expression, p.token.text, p.token.pos)
+	}
+	return &Expression{source: expression, root: root}, nil
+}
+
+// Evaluate returns the result of the expression for the value
+func (e *Expression) Evaluate(value float64) (float64, error) {
+	result, err := e.root.eval(value)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("transform %q returned %v for value %v", e.source, result, value)
+	}
+	return result, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+type node interface {
+	eval(value float64) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(float64) (float64, error) {
+	return float64(n), nil
+}
+
+type valueNode struct{}
+
+func (valueNode) eval(value float64) (float64, error) {
+	return value, nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n negateNode) eval(value float64) (float64, error) {
+	v, err := n.operand.eval(value)
+	return -v, err
+}
+
+type binaryNode struct {
+	op          byte
+	left, right node
+}
+
+func (n binaryNode) eval(value float64) (float64, error) {
+	l, err := n.left.eval(value)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(value)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	default:
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+}
+
+type functionNode struct {
+	name string
+	args []node
+}
+
+var functions = map[string]struct {
+	arity int
+	fn    func(args []float64) float64
+}{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"min":   {2, func(a []float64) float64 { return math.Min(a[0], a[1]) }},
+	"max":   {2, func(a []float64) float64 { return math.Max(a[0], a[1]) }},
+}
+
+func (n functionNode) eval(value float64) (float64, error) {
+	args := make([]float64, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(value)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+	return functions[n.name].fn(args), nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenInvalid
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type parser struct {
+	input string
+	pos   int
+	token token
+}
+
+func (p *parser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.token = token{kind: tokenEOF, pos: start}
+		return
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		p.token = token{kind: tokenNumber, text: p.input[start:p.pos], pos: start}
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.input) && unicode.IsLetter(rune(p.input[p.pos])) {
+			p.pos++
+		}
+		p.token = token{kind: tokenIdent, text: strings.ToLower(p.input[start:p.pos]), pos: start}
+	case strings.IndexByte("+-*/", c) >= 0:
+		p.pos++
+		p.token = token{kind: tokenOperator, text: string(c), pos: start}
+	case c == '(':
+		p.pos++
+		p.token = token{kind: tokenLParen, text: "(", pos: start}
+	case c == ')':
+		p.pos++
+		p.token = token{kind: tokenRParen, text: ")", pos: start}
+	case c == ',':
+		p.pos++
+		p.token = token{kind: tokenComma, text: ",", pos: start}
+	default:
+		p.pos++
+		p.token = token{kind: tokenInvalid, text: string(c), pos: start}
+	}
+}
+
+// parseExpression parses: term (('+' | '-') term)*
+func (p *parser) parseExpression() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.token.kind == tokenOperator && (p.token.text == "+" || p.token.text == "-") {
+		op := p.token.text[0]
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseTerm parses: unary (('*' | '/') unary)*
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.token.kind == tokenOperator && (p.token.text == "*" || p.token.text == "/") {
+		op := p.token.text[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: '-' unary | primary
+func (p *parser) parseUnary() (node, error) {
+	if p.token.kind == tokenOperator && p.token.text == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: number | 'value' | function '(' args ')' | '(' expression ')'
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.token
+	switch tok.kind {
+	case tokenNumber:
+		p.next()
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return numberNode(n), nil
+	case tokenIdent:
+		p.next()
+		if tok.text == ValueVariable {
+			return valueNode{}, nil
+		}
+		f, ok := functions[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown identifier %q at position %d", tok.text, tok.pos)
+		}
+		if p.token.kind != tokenLParen {
+			return nil, fmt.Errorf("expected ( after %s at position %d", tok.text, p.token.pos)
+		}
+		p.next()
+		var args []node
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.token.kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if p.token.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", p.token.pos)
+		}
+		p.next()
+		if len(args) != f.arity {
+			return nil, fmt.Errorf("%s expects %d argument(s), got %d", tok.text, f.arity, len(args))
+		}
+		return functionNode{name: tok.text, args: args}, nil
+	case tokenLParen:
+		p.next()
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if p.token.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", p.token.pos)
+		}
+		p.next()
+		return inner, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
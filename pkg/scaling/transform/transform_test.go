@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"testing"
+)
+
+type evaluateTestData struct {
+	expression string
+	value      float64
+	expected   float64
+}
+
+var evaluateTestDataset = []evaluateTestData{
+	{"value", 42, 42},
+	{"value * 0.001 + 5", 2000, 7},
+	{"value * (0.001 + 5)", 2, 10.002},
+	{"-value + 10", 4, 6},
+	{"ceil(value / 10)", 11, 2},
+	{"floor(value / 10)", 19, 1},
+	{"round(value)", 2.5, 3},
+	{"max(value, 1)", 0, 1},
+	{"min(value - 2, 100)", 500, 100},
+	{"abs(value - 10)", 4, 6},
+	{"VALUE / 2", 9, 4.5},
+	{"  1 + 2 * 3 ", 0, 7},
+}
+
+var invalidExpressions = []string{
+	"",
+	"value +",
+	"value * (2",
+	"foo",
+	"ceil value",
+	"min(value)",
+	"value % 2",
+	"1..2",
+	"value 2",
+}
+
+func TestEvaluate(t *testing.T) {
+	for _, testData := range evaluateTestDataset {
+		expression, err := Parse(testData.expression)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", testData.expression, err)
+			continue
+		}
+		result, err := expression.Evaluate(testData.value)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", testData.expression, err)
+			continue
+		}
+		if result != testData.expected {
+			t.Errorf("%q with value %v: expected %v, got %v", testData.expression, testData.value, testData.expected, result)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expression := range invalidExpressions {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("%q: expected error", expression)
+		}
+	}
+}
+
+func TestEvaluateDivisionByZero(t *testing.T) {
+	expression, err := Parse("10 / value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expression.Evaluate(0); err == nil {
+		t.Error("expected division by zero error")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	// activationReasonTriggersActive is the reason of an activation by the triggers
+	activationReasonTriggersActive = "TriggersActive"
+	// activationReasonTriggersNotActive is the reason of a deactivation when no trigger is active anymore
+	activationReasonTriggersNotActive = "TriggersNotActive"
+	// activationReasonTriggersError is the reason of a deactivation when the triggers failed
+	activationReasonTriggersError = "TriggersError"
+)
+
+// recordTriggerActivity records the last active time of the active triggers and the last activation or deactivation of
+// the ScaledObject in its status, the Active condition of the status is still the one of the previous check
+func (h *scaleHandler) recordTriggerActivity(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, activeTriggers []string, isActive bool, isError bool) {
+	patch := runtimeclient.MergeFrom(scaledObject.DeepCopy())
+	if !updateTriggerActivity(scaledObject, activeTriggers, isActive, isError, time.Now()) {
+		return
+	}
+	if err := h.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+		h.logger.Error(err, "Failed to patch the trigger activity of the ScaledObject", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+	}
+}
+
+// updateTriggerActivity updates the trigger activity in the status of the ScaledObject, it returns whether the status
+// changed, the triggers of a deactivation are the ones active since the last activation
+func updateTriggerActivity(scaledObject *kedav1alpha1.ScaledObject, activeTriggers []string, isActive bool, isError bool, now time.Time) bool {
+	status := &scaledObject.Status
+	nowTime := metav1.NewTime(now)
+	changed := false
+
+	if len(activeTriggers) > 0 && status.TriggersLastActiveTime == nil {
+		status.TriggersLastActiveTime = map[string]metav1.Time{}
+	}
+	for _, trigger := range activeTriggers {
+		status.TriggersLastActiveTime[trigger] = nowTime
+		changed = true
+	}
+
+	activeCondition := status.Conditions.GetActiveCondition()
+	wasActive := activeCondition.IsTrue()
+	switch {
+	case isActive && !wasActive:
+		status.LastActivation = &kedav1alpha1.ActivationEvent{
+			Time:     nowTime,
+			Triggers: activeTriggers,
+			Reason:   activationReasonTriggersActive,
+		}
+		changed = true
+	case !isActive && wasActive:
+		reason := activationReasonTriggersNotActive
+		if isError {
+			reason = activationReasonTriggersError
+		}
+		var triggers []string
+		for trigger, lastActiveTime := range status.TriggersLastActiveTime {
+			if status.LastActivation == nil || !lastActiveTime.Before(&status.LastActivation.Time) {
+				triggers = append(triggers, trigger)
+			}
+		}
+		sort.Strings(triggers)
+		status.LastDeactivation = &kedav1alpha1.ActivationEvent{
+			Time:     nowTime,
+			Triggers: triggers,
+			Reason:   reason,
+		}
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestUpdateTriggerActivity(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	start := time.Now().Truncate(time.Second)
+
+	// an inactive check of a new ScaledObject records nothing
+	assert.False(t, updateTriggerActivity(scaledObject, nil, false, false, start))
+	assert.Nil(t, scaledObject.Status.LastActivation)
+
+	// the activation records the active triggers
+	assert.True(t, updateTriggerActivity(scaledObject, []string{"queue"}, true, false, start))
+	assert.Equal(t, &kedav1alpha1.ActivationEvent{Time: metav1.NewTime(start), Triggers: []string{"queue"}, Reason: "TriggersActive"}, scaledObject.Status.LastActivation)
+	scaledObject.Status.Conditions = *kedav1alpha1.GetInitializedConditions()
+	scaledObject.Status.Conditions.SetActiveCondition(metav1.ConditionTrue, "ScalerActive", "")
+
+	// the checks while active only update the last active times
+	assert.True(t, updateTriggerActivity(scaledObject, []string{"cron"}, true, false, start.Add(time.Minute)))
+	assert.Equal(t, metav1.NewTime(start), scaledObject.Status.LastActivation.Time)
+	assert.Equal(t, metav1.NewTime(start.Add(time.Minute)), scaledObject.Status.TriggersLastActiveTime["cron"])
+	assert.Equal(t, metav1.NewTime(start), scaledObject.Status.TriggersLastActiveTime["queue"])
+
+	// the deactivation records the triggers active since the activation
+	assert.True(t, updateTriggerActivity(scaledObject, nil, false, true, start.Add(2*time.Minute)))
+	assert.Equal(t, &kedav1alpha1.ActivationEvent{Time: metav1.NewTime(start.Add(2 * time.Minute)), Triggers: []string{"cron", "queue"}, Reason: "TriggersError"}, scaledObject.Status.LastDeactivation)
+	scaledObject.Status.Conditions.SetActiveCondition(metav1.ConditionFalse, "ScalerNotActive", "")
+
+	// a trigger active before the last activation isn't part of the next deactivation
+	assert.True(t, updateTriggerActivity(scaledObject, []string{"queue"}, true, false, start.Add(3*time.Minute)))
+	scaledObject.Status.Conditions.SetActiveCondition(metav1.ConditionTrue, "ScalerActive", "")
+	assert.True(t, updateTriggerActivity(scaledObject, nil, false, false, start.Add(4*time.Minute)))
+	assert.Equal(t, []string{"queue"}, scaledObject.Status.LastDeactivation.Triggers)
+	assert.Equal(t, "TriggersNotActive", scaledObject.Status.LastDeactivation.Reason)
+}
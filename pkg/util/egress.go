@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// EgressPolicy is the allow-list of the destinations the scalers of each namespace can connect to,
+// the entries are host names, `*.` prefixed domains matching their subdomains, IPs and CIDRs
+type EgressPolicy struct {
+	// Default is the allow-list of the namespaces which aren't listed, they are unrestricted if it is unset
+	Default []string `json:"default,omitempty"`
+	// Namespaces are the allow-lists of the namespaces, an empty list denies all the destinations
+	Namespaces map[string][]string `json:"namespaces,omitempty"`
+
+	defaultRules   *egressRules
+	namespaceRules map[string]*egressRules
+}
+
+type egressRules struct {
+	hosts    []string
+	networks []*net.IPNet
+}
+
+// egressPolicy is set at startup, the scalers are unrestricted if it is nil
+var egressPolicy *EgressPolicy
+
+// SetEgressPolicy sets the policy enforced by the dialers of the scalers
+func SetEgressPolicy(policy *EgressPolicy) {
+	egressPolicy = policy
+}
+
+// EgressRestricted returns whether the connections of the scalers of the namespace are restricted by the EgressPolicy
+func EgressRestricted(namespace string) bool {
+	return egressPolicy.rulesFor(namespace) != nil
+}
+
+// LoadEgressPolicy reads the EgressPolicy from a YAML or JSON file
+func LoadEgressPolicy(path string) (*EgressPolicy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the egress policy: %s", err)
+	}
+	return ParseEgressPolicy(data)
+}
+
+// ParseEgressPolicy parses an EgressPolicy from YAML or JSON
+func ParseEgressPolicy(data []byte) (*EgressPolicy, error) {
+	policy := &EgressPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("error parsing the egress policy: %s", err)
+	}
+
+	var err error
+	if policy.Default != nil {
+		if policy.defaultRules, err = parseEgressRules(policy.Default); err != nil {
+			return nil, fmt.Errorf("invalid default egress policy: %s", err)
+		}
+	}
+	policy.namespaceRules = make(map[string]*egressRules, len(policy.Namespaces))
+	for namespace, entries := range policy.Namespaces {
+		if policy.namespaceRules[namespace], err = parseEgressRules(entries); err != nil {
+			return nil, fmt.Errorf("invalid egress policy of namespace %s: %s", namespace, err)
+		}
+	}
+	return policy, nil
+}
+
+func parseEgressRules(entries []string) (*egressRules, error) {
+	rules := &egressRules{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			return nil, fmt.Errorf("empty destination")
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %s: %s", entry, err)
+			}
+			rules.networks = append(rules.networks, network)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			rules.networks = append(rules.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		default:
+			if strings.Contains(strings.TrimPrefix(entry, "*."), "*") || strings.Contains(entry, ":") {
+				return nil, fmt.Errorf("invalid host %s", entry)
+			}
+			rules.hosts = append(rules.hosts, strings.ToLower(entry))
+		}
+	}
+	return rules, nil
+}
+
+// rulesFor returns the rules of the namespace, nil if the namespace is unrestricted
+func (p *EgressPolicy) rulesFor(namespace string) *egressRules {
+	if p == nil {
+		return nil
+	}
+	if rules, ok := p.namespaceRules[namespace]; ok {
+		return rules
+	}
+	return p.defaultRules
+}
+
+func (r *egressRules) allowsHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range r.hosts {
+		if strings.HasPrefix(pattern, "*.") {
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *egressRules) allowsIP(ip net.IP) bool {
+	for _, network := range r.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// EgressDialer dials the connections of the scalers of a namespace, the destinations which aren't allowed by the
+// EgressPolicy are refused. The host names allowed by IP are resolved and their allowed IPs are dialed, so a name
+// can't be rebound to another IP between the check and the connection.
+type EgressDialer struct {
+	namespace string
+	dialer    *net.Dialer
+}
+
+// NewEgressDialer creates the dialer of the scalers of the namespace, a timeout <= 0 means no timeout
+func NewEgressDialer(namespace string, timeout time.Duration) *EgressDialer {
+	return &EgressDialer{namespace: namespace, dialer: &net.Dialer{Timeout: timeout}}
+}
+
+// Dial connects to the address if the EgressPolicy allows it
+func (d *EgressDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// DialContext connects to the address if the EgressPolicy allows it
+func (d *EgressDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	rules := egressPolicy.rulesFor(d.namespace)
+	if rules == nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if rules.allowsHost(host) {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else if len(rules.networks) > 0 {
+		addrs, err := d.dialer.Resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	err = fmt.Errorf("connection to %s is not allowed by the egress policy of namespace %s", address, d.namespace)
+	for _, ip := range ips {
+		if !rules.allowsIP(ip) {
+			continue
+		}
+		var conn net.Conn
+		if conn, err = d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
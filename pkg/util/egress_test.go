@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testEgressPolicy = `
+default:
+  - "*.svc.cluster.local"
+namespaces:
+  team-a:
+    - 127.0.0.0/8
+    - api.example.com
+  team-b: []
+`
+
+type egressTestData struct {
+	namespace string
+	host      string
+	allowed   bool
+}
+
+var egressTestDataset = []egressTestData{
+	{"team-a", "api.example.com", true},
+	{"team-a", "API.example.com.", true},
+	{"team-a", "other.example.com", false},
+	{"team-a", "127.0.0.1", true},
+	{"team-a", "10.0.0.1", false},
+	{"team-b", "api.example.com", false},
+	{"team-c", "redis.team-c.svc.cluster.local", true},
+	{"team-c", "svc.cluster.local", false},
+	{"team-c", "api.example.com", false},
+}
+
+func TestEgressPolicyRules(t *testing.T) {
+	policy, err := ParseEgressPolicy([]byte(testEgressPolicy))
+	assert.NoError(t, err)
+	for _, testData := range egressTestDataset {
+		rules := policy.rulesFor(testData.namespace)
+		allowed := rules.allowsHost(testData.host)
+		if ip := net.ParseIP(testData.host); ip != nil {
+			allowed = rules.allowsIP(ip)
+		}
+		assert.Equal(t, testData.allowed, allowed, "%s in namespace %s", testData.host, testData.namespace)
+	}
+
+	// without a default the namespaces which aren't listed are unrestricted
+	policy, err = ParseEgressPolicy([]byte(`namespaces: {"team-a": ["api.example.com"]}`))
+	assert.NoError(t, err)
+	assert.Nil(t, policy.rulesFor("team-c"))
+	var unset *EgressPolicy
+	assert.Nil(t, unset.rulesFor("team-a"))
+}
+
+func TestParseInvalidEgressPolicy(t *testing.T) {
+	for _, policy := range []string{
+		`namespaces: {"team-a": ["10.0.0.0/33"]}`,
+		`namespaces: {"team-a": ["api.*.example.com"]}`,
+		`namespaces: {"team-a": ["api.example.com:443"]}`,
+		`namespaces: {"team-a": [""]}`,
+		`default: ["*.example.com"]
+unknown: true`,
+	} {
+		_, err := ParseEgressPolicy([]byte(policy))
+		assert.Error(t, err, policy)
+	}
+}
+
+func TestEgressDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	policy, err := ParseEgressPolicy([]byte(testEgressPolicy))
+	assert.NoError(t, err)
+	SetEgressPolicy(policy)
+	defer SetEgressPolicy(nil)
+
+	// localhost is resolved and its IP is allowed by the CIDR
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	conn, err := NewEgressDialer("team-a", 0).DialContext(context.Background(), "tcp", net.JoinHostPort("localhost", port))
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+
+	resp, err := CreateHTTPClientForNamespace("team-a", 0, false).Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+	_, err = CreateHTTPClientForNamespace("team-b", 0, false).Get(server.URL)
+	assert.Error(t, err)
+}
@@ -31,18 +31,29 @@ type HTTPDoer interface {
 
 // CreateHTTPClient returns a new HTTP client with the timeout set to
 // timeoutMS milliseconds, or 300 milliseconds if timeoutMS <= 0.
-// unsafeSsl parameter allows to avoid tls cert validation if it's required
+// unsafeSsl parameter allows to avoid tls cert validation if it's required,
+// the transport is tuned with the options set by SetHTTPTransportOptions
 func CreateHTTPClient(timeout time.Duration, unsafeSsl bool) *http.Client {
 	// default the timeout to 300ms
 	if timeout <= 0 {
 		timeout = 300 * time.Millisecond
 	}
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: unsafeSsl},
+	}
+	GetHTTPTransportOptions().Apply(transport)
 	httpClient := &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: unsafeSsl},
-		},
+		Timeout:   timeout,
+		Transport: transport,
 	}
 
 	return httpClient
 }
+
+// CreateHTTPClientForNamespace returns a new HTTP client like CreateHTTPClient,
+// its connections are restricted by the egress policy of the namespace
+func CreateHTTPClientForNamespace(namespace string, timeout time.Duration, unsafeSsl bool) *http.Client {
+	httpClient := CreateHTTPClient(timeout, unsafeSsl)
+	httpClient.Transport.(*http.Transport).DialContext = NewEgressDialer(namespace, 0).DialContext
+	return httpClient
+}
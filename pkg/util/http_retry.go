@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// RetryPolicy retries the HTTP requests of a scaler failing with a 5xx or a connection error, the retries are
+// made within the timeout of the HTTP client
+type RetryPolicy struct {
+	Retries int
+	// Backoff is the wait before the first retry, it is doubled at each retry
+	Backoff time.Duration
+}
+
+// ParseRetryPolicy parses the retries and retryBackoff trigger metadata, no request is retried by default
+func ParseRetryPolicy(metadata map[string]string) (RetryPolicy, error) {
+	policy := RetryPolicy{Backoff: defaultRetryBackoff}
+	if val, ok := metadata["retries"]; ok && val != "" {
+		retries, err := strconv.Atoi(val)
+		if err != nil || retries < 0 {
+			return policy, fmt.Errorf("retries must be a positive integer, got %q", val)
+		}
+		policy.Retries = retries
+	}
+	if val, ok := metadata["retryBackoff"]; ok && val != "" {
+		backoff, err := time.ParseDuration(val)
+		if err != nil || backoff <= 0 {
+			return policy, fmt.Errorf("retryBackoff must be a positive duration, got %q", val)
+		}
+		policy.Backoff = backoff
+	}
+	return policy, nil
+}
+
+// NewRetryTransport returns a RoundTripper retrying the requests of base with the policy, base is returned as is
+// if the policy makes no retries
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if policy.Retries <= 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, policy: policy}
+}
+
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// CloseIdleConnections closes the idle connections of the base transport, so http.Client.CloseIdleConnections
+// releases them through the retries
+func (t *retryTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// RoundTrip implements http.RoundTripper, the requests with a body are only retried if it can be read again
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.policy.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.Retries || !retryableResponse(req.Context(), resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+		if resp != nil {
+			// the connection is reused once the body is read
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryableResponse returns whether the failure of a request is transient
+func retryableResponse(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
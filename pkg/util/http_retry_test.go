@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+	policy, err := ParseRetryPolicy(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, RetryPolicy{Backoff: defaultRetryBackoff}, policy)
+
+	policy, err = ParseRetryPolicy(map[string]string{"retries": "3", "retryBackoff": "250ms"})
+	assert.NoError(t, err)
+	assert.Equal(t, RetryPolicy{Retries: 3, Backoff: 250 * time.Millisecond}, policy)
+
+	_, err = ParseRetryPolicy(map[string]string{"retries": "-1"})
+	assert.Error(t, err)
+	_, err = ParseRetryPolicy(map[string]string{"retryBackoff": "100"})
+	assert.Error(t, err)
+}
+
+func TestRetryTransport(t *testing.T) {
+	var bodies []string
+	statuses := []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryTransport(http.DefaultTransport, RetryPolicy{Retries: 2, Backoff: time.Millisecond})}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("query"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"query", "query", "query"}, bodies)
+
+	// the last response is returned once the retries are exhausted
+	bodies, statuses = nil, []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusNotFound}
+	client.Transport = NewRetryTransport(http.DefaultTransport, RetryPolicy{Retries: 1, Backoff: time.Millisecond})
+	resp, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Len(t, bodies, 2)
+
+	// the client errors aren't retried
+	bodies = nil
+	resp, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Len(t, bodies, 1)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HTTPTransportOptions tune the connection pool of the transports of the HTTP clients, the zero values keep the
+// defaults of net/http
+type HTTPTransportOptions struct {
+	// MaxIdleConns is the number of idle connections kept per host, the clients of the scalers mostly query a
+	// single backend so it also bounds the idle connections of the transport
+	MaxIdleConns int
+	// IdleConnTimeout is how long an idle connection is kept before it is closed
+	IdleConnTimeout time.Duration
+	// HTTP2 attempts HTTP/2 with the TLS backends, nil keeps the setting of the operator
+	HTTP2 *bool
+}
+
+var (
+	// httpTransportOptions are set at startup from the flags of the operator and on the reloads of its
+	// configuration, they apply to all the HTTP clients
+	httpTransportOptions     HTTPTransportOptions
+	httpTransportOptionsLock sync.RWMutex
+)
+
+// SetHTTPTransportOptions sets the options of the transports of all the HTTP clients created afterwards, the
+// triggers can override them with their metadata, see ParseHTTPTransportOptions
+func SetHTTPTransportOptions(options HTTPTransportOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	httpTransportOptionsLock.Lock()
+	defer httpTransportOptionsLock.Unlock()
+	httpTransportOptions = options
+	return nil
+}
+
+// GetHTTPTransportOptions returns the options of the transports of the HTTP clients set by SetHTTPTransportOptions
+func GetHTTPTransportOptions() HTTPTransportOptions {
+	httpTransportOptionsLock.RLock()
+	defer httpTransportOptionsLock.RUnlock()
+	return httpTransportOptions
+}
+
+// ParseHTTPTransportOptions parses the httpMaxIdleConns, httpIdleConnTimeout and httpEnableHTTP2 trigger metadata,
+// the options not given keep the settings of the operator
+func ParseHTTPTransportOptions(metadata map[string]string) (HTTPTransportOptions, error) {
+	options := HTTPTransportOptions{}
+	if val, ok := metadata["httpMaxIdleConns"]; ok && val != "" {
+		maxIdleConns, err := strconv.Atoi(val)
+		if err != nil {
+			return options, fmt.Errorf("httpMaxIdleConns must be an integer, got %q", val)
+		}
+		options.MaxIdleConns = maxIdleConns
+	}
+	if val, ok := metadata["httpIdleConnTimeout"]; ok && val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil {
+			return options, fmt.Errorf("httpIdleConnTimeout must be a duration, got %q", val)
+		}
+		options.IdleConnTimeout = timeout
+	}
+	if val, ok := metadata["httpEnableHTTP2"]; ok && val != "" {
+		http2, err := strconv.ParseBool(val)
+		if err != nil {
+			return options, fmt.Errorf("httpEnableHTTP2 must be a bool, got %q", val)
+		}
+		options.HTTP2 = &http2
+	}
+	return options, options.validate()
+}
+
+func (o HTTPTransportOptions) validate() error {
+	if o.MaxIdleConns < 0 {
+		return fmt.Errorf("the max idle connections can't be negative, got %d", o.MaxIdleConns)
+	}
+	if o.IdleConnTimeout < 0 {
+		return fmt.Errorf("the idle connection timeout can't be negative, got %s", o.IdleConnTimeout)
+	}
+	return nil
+}
+
+// Apply sets the options given on the transport, the others are left as they are
+func (o HTTPTransportOptions) Apply(transport *http.Transport) {
+	if o.MaxIdleConns > 0 {
+		transport.MaxIdleConns = o.MaxIdleConns
+		transport.MaxIdleConnsPerHost = o.MaxIdleConns
+	}
+	if o.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.HTTP2 != nil {
+		// the transports with a custom TLS config only attempt HTTP/2 when forced
+		transport.ForceAttemptHTTP2 = *o.HTTP2
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHTTPTransportOptions(t *testing.T) {
+	options, err := ParseHTTPTransportOptions(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, HTTPTransportOptions{}, options)
+
+	options, err = ParseHTTPTransportOptions(map[string]string{"httpMaxIdleConns": "50", "httpIdleConnTimeout": "90s", "httpEnableHTTP2": "true"})
+	assert.NoError(t, err)
+	assert.Equal(t, 50, options.MaxIdleConns)
+	assert.Equal(t, 90*time.Second, options.IdleConnTimeout)
+	assert.True(t, *options.HTTP2)
+
+	_, err = ParseHTTPTransportOptions(map[string]string{"httpMaxIdleConns": "-1"})
+	assert.Error(t, err)
+	_, err = ParseHTTPTransportOptions(map[string]string{"httpIdleConnTimeout": "90"})
+	assert.Error(t, err)
+	_, err = ParseHTTPTransportOptions(map[string]string{"httpEnableHTTP2": "yes please"})
+	assert.Error(t, err)
+}
+
+func TestHTTPTransportOptions(t *testing.T) {
+	defer func() { httpTransportOptions = HTTPTransportOptions{} }()
+
+	// the defaults of net/http are kept
+	transport := CreateHTTPClient(time.Second, false).Transport.(*http.Transport)
+	assert.Equal(t, 0, transport.MaxIdleConnsPerHost)
+	assert.False(t, transport.ForceAttemptHTTP2)
+
+	enabled, disabled := true, false
+	assert.Error(t, SetHTTPTransportOptions(HTTPTransportOptions{IdleConnTimeout: -time.Second}))
+	assert.NoError(t, SetHTTPTransportOptions(HTTPTransportOptions{MaxIdleConns: 20, IdleConnTimeout: time.Minute, HTTP2: &enabled}))
+	transport = CreateHTTPClient(time.Second, false).Transport.(*http.Transport)
+	assert.Equal(t, 20, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.True(t, transport.ForceAttemptHTTP2)
+
+	// the options of a trigger override the ones of the operator
+	HTTPTransportOptions{MaxIdleConns: 100, HTTP2: &disabled}.Apply(transport)
+	assert.Equal(t, 100, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+}
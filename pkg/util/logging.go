@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// verboseLogger lowers the verbosity level of the messages by boost, so the debug messages of a single object are
+// logged without raising the verbosity of the whole operator
+type verboseLogger struct {
+	logr.Logger
+	boost int
+}
+
+// WithVerbosityBoost returns a logger logging the messages of level V(n) at level V(n-boost)
+func WithVerbosityBoost(logger logr.Logger, boost int) logr.Logger {
+	if boost <= 0 {
+		return logger
+	}
+	return verboseLogger{Logger: logger, boost: boost}
+}
+
+// V implements logr.Logger, the levels are additive so only the remaining boost is applied to the next calls
+func (l verboseLogger) V(level int) logr.Logger {
+	consumed := level
+	if consumed > l.boost {
+		consumed = l.boost
+	}
+	return WithVerbosityBoost(l.Logger.V(level-consumed), l.boost-consumed)
+}
+
+// WithValues implements logr.Logger
+func (l verboseLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithValues(keysAndValues...), boost: l.boost}
+}
+
+// WithName implements logr.Logger
+func (l verboseLogger) WithName(name string) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithName(name), boost: l.boost}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+// levelLogger records the level of the messages, only the ones up to maxLevel are enabled
+type levelLogger struct {
+	level    int
+	maxLevel int
+	logged   *[]int
+}
+
+func (l levelLogger) Enabled() bool { return l.level <= l.maxLevel }
+
+func (l levelLogger) Info(string, ...interface{}) {
+	if l.Enabled() {
+		*l.logged = append(*l.logged, l.level)
+	}
+}
+
+func (l levelLogger) Error(error, string, ...interface{}) { *l.logged = append(*l.logged, l.level) }
+
+func (l levelLogger) V(level int) logr.Logger {
+	l.level += level
+	return l
+}
+
+func (l levelLogger) WithValues(...interface{}) logr.Logger { return l }
+
+func (l levelLogger) WithName(string) logr.Logger { return l }
+
+func TestWithVerbosityBoost(t *testing.T) {
+	var logged []int
+	logger := WithVerbosityBoost(levelLogger{logged: &logged}, 2).WithName("scaler").WithValues("scalerIndex", 0)
+
+	logger.Info("info")
+	logger.V(1).Info("debug")
+	logger.V(1).V(1).Info("trace")
+	logger.V(3).Info("more")
+	assert.Equal(t, []int{0, 0, 0}, logged)
+
+	logged = nil
+	WithVerbosityBoost(levelLogger{logged: &logged, maxLevel: 1}, 0).V(1).Info("debug")
+	assert.Equal(t, []int{1}, logged)
+}
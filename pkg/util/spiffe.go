@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// spiffeSourceTimeout bounds the wait for the first SVID of the Workload API
+const spiffeSourceTimeout = 30 * time.Second
+
+// spiffeSource is the X509 source of the operator shared by the scalers, the Workload API streams
+// the rotated SVIDs and bundles to it
+var spiffeSource = struct {
+	sync.Mutex
+	source *workloadapi.X509Source
+}{}
+
+// NewSPIFFETLSConfig returns a *tls.Config presenting the SVID of the operator obtained from the SPIFFE
+// Workload API, its socket is read from SPIFFE_ENDPOINT_SOCKET. The backend is authorized with serverID,
+// a SPIFFE ID or a trust domain, any SPIFFE ID of the bundles is authorized when it is empty.
+func NewSPIFFETLSConfig(serverID string) (*tls.Config, error) {
+	authorizer, err := parseSPIFFEAuthorizer(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := getSPIFFESource()
+	if err != nil {
+		return nil, err
+	}
+	return newSPIFFETLSConfig(source, source, authorizer), nil
+}
+
+func newSPIFFETLSConfig(svid x509svid.Source, bundles x509bundle.Source, authorizer tlsconfig.Authorizer) *tls.Config {
+	// the certificates are read from the sources on each handshake, the rotated ones are used
+	return tlsconfig.MTLSClientConfig(svid, bundles, authorizer)
+}
+
+func getSPIFFESource() (*workloadapi.X509Source, error) {
+	spiffeSource.Lock()
+	defer spiffeSource.Unlock()
+
+	if spiffeSource.source != nil {
+		return spiffeSource.source, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spiffeSourceTimeout)
+	defer cancel()
+	source, err := workloadapi.NewX509Source(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the SVID from the SPIFFE Workload API: %s", err)
+	}
+	spiffeSource.source = source
+	return source, nil
+}
+
+// parseSPIFFEAuthorizer authorizes the SPIFFE ID serverID, or the members of the trust domain serverID
+func parseSPIFFEAuthorizer(serverID string) (tlsconfig.Authorizer, error) {
+	if serverID == "" {
+		return tlsconfig.AuthorizeAny(), nil
+	}
+	if id, err := spiffeid.FromString(serverID); err == nil && id.Path() != "" {
+		return tlsconfig.AuthorizeID(id), nil
+	}
+
+	trustDomain, err := spiffeid.TrustDomainFromString(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a SPIFFE ID nor a trust domain: %s", serverID, err)
+	}
+	return tlsconfig.AuthorizeMemberOf(trustDomain), nil
+}
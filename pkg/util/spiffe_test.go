@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+func TestParseSPIFFEAuthorizer(t *testing.T) {
+	scaler := spiffeid.RequireFromString("spiffe://example.org/scaler")
+	other := spiffeid.RequireFromString("spiffe://example.org/other")
+	foreign := spiffeid.RequireFromString("spiffe://example.com/scaler")
+
+	tests := []struct {
+		serverID   string
+		authorized []spiffeid.ID
+		rejected   []spiffeid.ID
+	}{
+		{"", []spiffeid.ID{scaler, other, foreign}, nil},
+		{"spiffe://example.org/scaler", []spiffeid.ID{scaler}, []spiffeid.ID{other, foreign}},
+		{"spiffe://example.org", []spiffeid.ID{scaler, other}, []spiffeid.ID{foreign}},
+		{"example.org", []spiffeid.ID{scaler, other}, []spiffeid.ID{foreign}},
+	}
+	for _, test := range tests {
+		authorizer, err := parseSPIFFEAuthorizer(test.serverID)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %s", test.serverID, err)
+		}
+		for _, id := range test.authorized {
+			if err := authorizer(id, nil); err != nil {
+				t.Errorf("%q: expected %s to be authorized, got %s", test.serverID, id, err)
+			}
+		}
+		for _, id := range test.rejected {
+			if err := authorizer(id, nil); err == nil {
+				t.Errorf("%q: expected %s to be rejected", test.serverID, id)
+			}
+		}
+	}
+
+	if _, err := parseSPIFFEAuthorizer("spiffe://Example.org/scaler"); err == nil {
+		t.Error("expected an error for an invalid SPIFFE ID")
+	}
+}
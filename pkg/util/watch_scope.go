@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ParseWatchNamespaces parses a comma separated list of namespaces, an empty result means all namespaces
+func ParseWatchNamespaces(value string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// ConfigureWatchNamespaces restricts the manager cache to the passed namespaces
+func ConfigureWatchNamespaces(options *manager.Options, namespaces []string) {
+	switch len(namespaces) {
+	case 0:
+		options.Namespace = ""
+	case 1:
+		options.Namespace = namespaces[0]
+	default:
+		options.Namespace = ""
+		options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+	}
+}
+
+// ParseShardSelector parses the label selector used to select the ScaledObjects and ScaledJobs of a shard,
+// an empty value selects everything
+func ParseShardSelector(value string) (labels.Selector, error) {
+	if strings.TrimSpace(value) == "" {
+		return labels.Everything(), nil
+	}
+	return labels.Parse(value)
+}
+
+// IsInShard returns true if the object matches the shard selector
+func IsInShard(selector labels.Selector, obj client.Object) bool {
+	return selector == nil || selector.Empty() || selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// ShardPredicate filters the events of objects which don't belong to the shard, the updates moving an object out
+// of the shard pass so the shard stops scaling it
+func ShardPredicate(selector labels.Selector) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return IsInShard(selector, e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return IsInShard(selector, e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return IsInShard(selector, e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return IsInShard(selector, e.ObjectOld) || IsInShard(selector, e.ObjectNew)
+		},
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestParseWatchNamespaces(t *testing.T) {
+	tests := map[string][]string{
+		"":             nil,
+		"keda":         {"keda"},
+		"keda, apps,":  {"keda", "apps"},
+		" , ":          nil,
+		"a,b,c":        {"a", "b", "c"},
+		"  spaced  ,b": {"spaced", "b"},
+	}
+	for value, expected := range tests {
+		if namespaces := ParseWatchNamespaces(value); !reflect.DeepEqual(namespaces, expected) {
+			t.Errorf("%q: expected %v, got %v", value, expected, namespaces)
+		}
+	}
+}
+
+func TestConfigureWatchNamespaces(t *testing.T) {
+	options := manager.Options{}
+	ConfigureWatchNamespaces(&options, []string{"keda"})
+	if options.Namespace != "keda" || options.NewCache != nil {
+		t.Errorf("expected single namespace cache, got %q", options.Namespace)
+	}
+
+	options = manager.Options{}
+	ConfigureWatchNamespaces(&options, []string{"a", "b"})
+	if options.Namespace != "" || options.NewCache == nil {
+		t.Error("expected multi namespace cache")
+	}
+}
+
+func TestIsInShard(t *testing.T) {
+	so := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"shard": "a"}},
+	}
+
+	everything, err := ParseShardSelector("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsInShard(everything, so) || !IsInShard(nil, so) {
+		t.Error("expected empty selector to match")
+	}
+
+	shardA, _ := ParseShardSelector("shard=a")
+	shardB, _ := ParseShardSelector("shard in (b,c)")
+	if !IsInShard(shardA, so) {
+		t.Error("expected shard=a to match")
+	}
+	if IsInShard(shardB, so) {
+		t.Error("expected shard in (b,c) not to match")
+	}
+
+	if _, err := ParseShardSelector("shard in ("); err == nil {
+		t.Error("expected invalid selector to fail")
+	}
+}
+
+func TestShardPredicate(t *testing.T) {
+	shardA, _ := ParseShardSelector("shard=a")
+	p := ShardPredicate(shardA)
+	inA := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"shard": "a"}}}
+	inB := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"shard": "b"}}}
+
+	if !p.Create(event.CreateEvent{Object: inA}) || p.Create(event.CreateEvent{Object: inB}) {
+		t.Error("expected only the creations in the shard to pass")
+	}
+	// the moves in and out of the shard pass, so it starts or stops scaling the object
+	if !p.Update(event.UpdateEvent{ObjectOld: inB, ObjectNew: inA}) {
+		t.Error("expected the move into the shard to pass")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: inA, ObjectNew: inB}) {
+		t.Error("expected the move out of the shard to pass")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: inB, ObjectNew: inB}) {
+		t.Error("expected the updates in another shard not to pass")
+	}
+}
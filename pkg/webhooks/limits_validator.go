@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/kedaconfig"
+)
+
+// the paths the LimitsValidator is served at by the webhook server
+const (
+	ScaledObjectValidatorPath = "/validate-keda-sh-v1alpha1-scaledobject"
+	ScaledJobValidatorPath    = "/validate-keda-sh-v1alpha1-scaledjob"
+)
+
+// +kubebuilder:webhook:path=/validate-keda-sh-v1alpha1-scaledobject,mutating=false,failurePolicy=ignore,sideEffects=None,groups=keda.sh,resources=scaledobjects,verbs=create;update,versions=v1alpha1,name=vscaledobject.keda.sh,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-keda-sh-v1alpha1-scaledjob,mutating=false,failurePolicy=ignore,sideEffects=None,groups=keda.sh,resources=scaledjobs,verbs=create;update,versions=v1alpha1,name=vscaledjob.keda.sh,admissionReviewVersions=v1
+
+// LimitsValidator is a validating admission webhook denying the ScaledObjects and ScaledJobs which break the limits
+// of their KedaConfigs and ClusterKedaConfigs. The defaults of the configs are applied before the limits are
+// checked, as the controllers do. The objects being deleted are allowed so their finalizers can be removed, and
+// the ones admitted before a limit was tightened are reported by their Ready condition.
+type LimitsValidator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *LimitsValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler
+func (v *LimitsValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	switch req.Kind.Kind {
+	case "ScaledObject":
+		scaledObject := &kedav1alpha1.ScaledObject{}
+		if err := v.decoder.Decode(req, scaledObject); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if scaledObject.Namespace == "" {
+			scaledObject.Namespace = req.Namespace
+		}
+		if scaledObject.GetDeletionTimestamp() != nil {
+			return admission.Allowed("")
+		}
+		policy, err := kedaconfig.Resolve(ctx, v.Client, scaledObject.Namespace)
+		if err != nil {
+			return admission.Allowed("").WithWarnings(fmt.Sprintf("the KedaConfig limits have not been checked: %s", err))
+		}
+		policy.ApplyToScaledObject(scaledObject)
+		if err := policy.ValidateScaledObject(ctx, v.Client, scaledObject); err != nil {
+			return admission.Denied(fmt.Sprintf("the ScaledObject breaks the limits of the KedaConfigs: %s", err))
+		}
+	case "ScaledJob":
+		scaledJob := &kedav1alpha1.ScaledJob{}
+		if err := v.decoder.Decode(req, scaledJob); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if scaledJob.Namespace == "" {
+			scaledJob.Namespace = req.Namespace
+		}
+		if scaledJob.GetDeletionTimestamp() != nil {
+			return admission.Allowed("")
+		}
+		policy, err := kedaconfig.Resolve(ctx, v.Client, scaledJob.Namespace)
+		if err != nil {
+			return admission.Allowed("").WithWarnings(fmt.Sprintf("the KedaConfig limits have not been checked: %s", err))
+		}
+		policy.ApplyToScaledJob(scaledJob)
+		if err := policy.ValidateScaledJob(ctx, v.Client, scaledJob); err != nil {
+			return admission.Denied(fmt.Sprintf("the ScaledJob breaks the limits of the KedaConfigs: %s", err))
+		}
+	}
+	return admission.Allowed("")
+}
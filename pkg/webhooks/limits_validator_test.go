@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type limitsValidatorTestData struct {
+	name    string
+	kind    string
+	object  runtime.Object
+	allowed bool
+}
+
+var cronTriggers = []kedav1alpha1.ScaleTriggers{
+	{Type: "cron", Metadata: map[string]string{"timezone": "UTC", "start": "0 8 * * *", "end": "0 18 * * *", "desiredReplicas": "5"}},
+}
+
+var deletedAt = metav1.Now()
+
+var limitsValidatorTestDataset = []limitsValidatorTestData{
+	{"scaledobject within the limits", "ScaledObject", &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec:       kedav1alpha1.ScaledObjectSpec{MaxReplicaCount: int32Ptr(20), Triggers: cronTriggers},
+	}, true},
+	{"scaledobject above the max replica count", "ScaledObject", &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec:       kedav1alpha1.ScaledObjectSpec{MaxReplicaCount: int32Ptr(50), Triggers: cronTriggers},
+	}, false},
+	// the default of the KedaConfig is within the limits
+	{"scaledobject with the default max replica count", "ScaledObject", &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec:       kedav1alpha1.ScaledObjectSpec{Triggers: cronTriggers},
+	}, true},
+	{"scaledobject being deleted", "ScaledObject", &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop", DeletionTimestamp: &deletedAt},
+		Spec:       kedav1alpha1.ScaledObjectSpec{MaxReplicaCount: int32Ptr(50), Triggers: cronTriggers},
+	}, true},
+	{"scaledjob within the limits", "ScaledJob", &kedav1alpha1.ScaledJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec:       kedav1alpha1.ScaledJobSpec{MaxReplicaCount: int32Ptr(20), Triggers: cronTriggers},
+	}, true},
+	{"scaledjob above the max replica count", "ScaledJob", &kedav1alpha1.ScaledJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec:       kedav1alpha1.ScaledJobSpec{MaxReplicaCount: int32Ptr(50), Triggers: cronTriggers},
+	}, false},
+	{"scaledjob in a namespace without limits", "ScaledJob", &kedav1alpha1.ScaledJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "dev"},
+		Spec:       kedav1alpha1.ScaledJobSpec{MaxReplicaCount: int32Ptr(50), Triggers: cronTriggers},
+	}, true},
+}
+
+func TestLimitsValidatorHandle(t *testing.T) {
+	defaulter := newTestDefaulter(t)
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	assert.NoError(t, err)
+	validator := &LimitsValidator{Client: defaulter.Client}
+	assert.NoError(t, validator.InjectDecoder(decoder))
+
+	for _, testData := range limitsValidatorTestDataset {
+		raw, _ := json.Marshal(testData.object)
+		namespace := testData.object.(metav1.Object).GetNamespace()
+		resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: testData.kind},
+			Operation: admissionv1.Update,
+			Namespace: namespace,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+		assert.Equal(t, testData.allowed, resp.Allowed, testData.name)
+		if !testData.allowed {
+			assert.Contains(t, string(resp.Result.Reason), "breaks the limits of the KedaConfigs", testData.name)
+		}
+	}
+}
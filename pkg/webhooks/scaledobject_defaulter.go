@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/kedaconfig"
+)
+
+// ScaledObjectDefaulterPath is the path the ScaledObjectDefaulter is served at by the webhook server
+const ScaledObjectDefaulterPath = "/mutate-keda-sh-v1alpha1-scaledobject"
+
+// +kubebuilder:webhook:path=/mutate-keda-sh-v1alpha1-scaledobject,mutating=true,failurePolicy=ignore,sideEffects=None,groups=keda.sh,resources=scaledobjects,verbs=create;update,versions=v1alpha1,name=mscaledobject.keda.sh,admissionReviewVersions=v1
+
+// deprecatedMetadata is a trigger metadata key which is going to be removed, normalize rewrites it to its
+// replacement, the key is only reported if normalize is nil or returns false
+type deprecatedMetadata struct {
+	triggerType string
+	key         string
+	message     string
+	normalize   func(metadata map[string]string) bool
+}
+
+// deprecatedTriggerMetadata are the deprecated metadata keys of the scalers
+var deprecatedTriggerMetadata = []deprecatedMetadata{
+	{
+		triggerType: "rabbitmq",
+		key:         "queueLength",
+		message:     "use mode: QueueLength and value instead",
+		normalize: func(metadata map[string]string) bool {
+			if _, ok := metadata["mode"]; ok {
+				return false
+			}
+			if _, ok := metadata["value"]; ok {
+				return false
+			}
+			metadata["mode"], metadata["value"] = "QueueLength", metadata["queueLength"]
+			delete(metadata, "queueLength")
+			return true
+		},
+	},
+}
+
+// ScaledObjectDefaulter is a mutating admission webhook normalizing the deprecated trigger metadata of the
+// ScaledObjects and setting the defaults of their KedaConfigs and ClusterKedaConfigs. The changes, the deprecated
+// options, the invalid trigger metadata and the breaches of the KedaConfig limits are returned as warnings so they
+// are reported at apply time.
+// The defaults are set when the ScaledObject is applied, the later changes of the KedaConfigs only apply to the
+// fields it doesn't set.
+type ScaledObjectDefaulter struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (d *ScaledObjectDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler
+func (d *ScaledObjectDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	if err := d.decoder.Decode(req, scaledObject); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if scaledObject.Namespace == "" {
+		scaledObject.Namespace = req.Namespace
+	}
+
+	warnings := d.Default(ctx, scaledObject)
+	marshaled, err := json.Marshal(scaledObject)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	resp := admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+	resp.Warnings = warnings
+	return resp
+}
+
+// Default normalizes the deprecated trigger metadata and sets the KedaConfig defaults of the ScaledObject, it
+// returns the warnings of the changes, of the options which are going to be removed and of the invalid triggers
+func (d *ScaledObjectDefaulter) Default(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) []string {
+	var warnings []string
+	for i, trigger := range scaledObject.Spec.Triggers {
+		for _, deprecated := range deprecatedTriggerMetadata {
+			if trigger.Type != deprecated.triggerType {
+				continue
+			}
+			if _, ok := trigger.Metadata[deprecated.key]; !ok {
+				continue
+			}
+			if deprecated.normalize != nil && deprecated.normalize(trigger.Metadata) {
+				warnings = append(warnings, fmt.Sprintf("spec.triggers[%d].metadata.%s is deprecated and has been rewritten, %s", i, deprecated.key, deprecated.message))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("spec.triggers[%d].metadata.%s is deprecated and will be removed, %s", i, deprecated.key, deprecated.message))
+			}
+		}
+	}
+	for _, issue := range ValidateTriggers(scaledObject.Namespace, scaledObject.Name, scaledObject.Spec.Triggers) {
+		warnings = append(warnings, issue.String())
+	}
+
+	policy, err := kedaconfig.Resolve(ctx, d.Client, scaledObject.Namespace)
+	if err != nil {
+		return append(warnings, fmt.Sprintf("the KedaConfig defaults have not been set: %s", err))
+	}
+	policy.ApplyToScaledObject(scaledObject)
+	if err := policy.ValidateScaledObject(ctx, d.Client, scaledObject); err != nil {
+		warnings = append(warnings, fmt.Sprintf("the ScaledObject will not be scaled: %s", err))
+	}
+	return warnings
+}
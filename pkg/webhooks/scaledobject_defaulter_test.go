@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func newTestDefaulter(t *testing.T) *ScaledObjectDefaulter {
+	if err := kedav1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Error adding the KEDA types to the scheme: %s", err)
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&kedav1alpha1.KedaConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "shop"},
+		Spec: kedav1alpha1.KedaConfigSpec{
+			Defaults: &kedav1alpha1.KedaConfigDefaults{PollingInterval: int32Ptr(15), MaxReplicaCount: int32Ptr(10)},
+			Limits:   &kedav1alpha1.KedaConfigLimits{MaxReplicaCount: int32Ptr(20)},
+		},
+	}).Build()
+
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	assert.NoError(t, err)
+	defaulter := &ScaledObjectDefaulter{Client: kubeClient}
+	assert.NoError(t, defaulter.InjectDecoder(decoder))
+	return defaulter
+}
+
+func TestScaledObjectDefaulterHandle(t *testing.T) {
+	defaulter := newTestDefaulter(t)
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject"},
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef:  &kedav1alpha1.ScaleTarget{Name: "orders"},
+			PollingInterval: int32Ptr(60),
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{Type: "rabbitmq", Metadata: map[string]string{"host": "amqp://rabbitmq", "queueName": "orders", "queueLength": "20"}},
+				{Type: "rabbitmq", Metadata: map[string]string{"host": "amqp://rabbitmq", "queueName": "refunds", "queueLength": "20", "mode": "QueueLength"}},
+				{Type: "cron", Metadata: map[string]string{"timezone": "UTC", "start": "0 8 * * *", "end": "0 18 * * *", "desiredReplicas": "5"}},
+			},
+		},
+	}
+	raw, _ := json.Marshal(scaledObject)
+	resp := defaulter.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "shop",
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, []string{
+		"spec.triggers[0].metadata.queueLength is deprecated and has been rewritten, use mode: QueueLength and value instead",
+		"spec.triggers[1].metadata.queueLength is deprecated and will be removed, use mode: QueueLength and value instead",
+		"spec.triggers[1] (rabbitmq): error parsing rabbitmq metadata: unable to parse trigger: queueLength is deprecated; configure only mode and value",
+	}, resp.Warnings)
+
+	patches := map[string]interface{}{}
+	for _, patch := range resp.Patches {
+		patches[patch.Operation+" "+patch.Path] = patch.Value
+	}
+	assert.Equal(t, map[string]interface{}{
+		"add /spec/triggers/0/metadata/mode":           "QueueLength",
+		"add /spec/triggers/0/metadata/value":          "20",
+		"remove /spec/triggers/0/metadata/queueLength": nil,
+		// the pollingInterval of the ScaledObject is kept
+		"add /spec/maxReplicaCount": float64(10),
+	}, patches)
+}
+
+func TestScaledObjectDefaulterWarnsAboutLimits(t *testing.T) {
+	defaulter := newTestDefaulter(t)
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			MaxReplicaCount: int32Ptr(50),
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{Type: "cron", Metadata: map[string]string{"timezone": "UTC", "start": "0 8 * * *", "end": "0 18 * * *", "desiredReplicas": "5"}},
+			},
+		},
+	}
+	warnings := defaulter.Default(context.Background(), scaledObject)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "the ScaledObject will not be scaled")
+	assert.Equal(t, int32(15), *scaledObject.Spec.PollingInterval)
+}
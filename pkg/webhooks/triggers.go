@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/schedule"
+	"github.com/kedacore/keda/v2/pkg/scaling/transform"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// TriggerIssue is an invalid field of a trigger found by ValidateTriggers
+type TriggerIssue struct {
+	Index   int
+	Type    string
+	Message string
+	// Unresolved is set when the trigger reads values which are only resolved in the cluster, from its
+	// authenticationRef, templateRef, metadataValueFrom or the env of the scale target, so the issue may be caused
+	// by the missing values
+	Unresolved bool
+}
+
+func (i TriggerIssue) String() string {
+	if i.Unresolved {
+		return fmt.Sprintf("spec.triggers[%d] (%s): %s (some values of the trigger are resolved in the cluster)", i.Index, i.Type, i.Message)
+	}
+	return fmt.Sprintf("spec.triggers[%d] (%s): %s", i.Index, i.Type, i.Message)
+}
+
+// ValidateTriggers parses the triggers with the parsers of their scalers without connecting to the backends and
+// without resolving the values stored in the cluster, so it can be run on the manifests before they are applied
+func ValidateTriggers(namespace, name string, triggers []kedav1alpha1.ScaleTriggers) []TriggerIssue {
+	var issues []TriggerIssue
+	for i, trigger := range triggers {
+		unresolved := isUnresolved(trigger)
+		if trigger.Type == "" {
+			if trigger.TemplateRef == nil {
+				issues = append(issues, TriggerIssue{Index: i, Message: "the type of the trigger is not set"})
+			}
+			continue
+		}
+
+		metadata := make(map[string]string, len(trigger.Metadata))
+		for key, value := range trigger.Metadata {
+			metadata[key] = value
+		}
+		config := &scalers.ScalerConfig{
+			Name:            name,
+			Namespace:       namespace,
+			TriggerMetadata: metadata,
+			ResolvedEnv:     make(map[string]string),
+			AuthParams:      make(map[string]string),
+			ScalerIndex:     i,
+			MetricType:      trigger.MetricType,
+		}
+		var err error
+		if config.RetryPolicy, err = kedautil.ParseRetryPolicy(metadata); err != nil {
+			issues = append(issues, TriggerIssue{Index: i, Type: trigger.Type, Message: err.Error(), Unresolved: unresolved})
+		}
+		if err = scalers.ValidateTriggerMetadata(trigger.Type, config); err != nil {
+			issues = append(issues, TriggerIssue{Index: i, Type: trigger.Type, Message: err.Error(), Unresolved: unresolved})
+		}
+		if trigger.Transform != "" {
+			if _, err = transform.Parse(trigger.Transform); err != nil {
+				issues = append(issues, TriggerIssue{Index: i, Type: trigger.Type, Message: fmt.Sprintf("invalid transform: %s", err)})
+			}
+		}
+		if _, err = schedule.ParseTriggerGate(trigger.Schedule); err != nil {
+			issues = append(issues, TriggerIssue{Index: i, Type: trigger.Type, Message: err.Error()})
+		}
+	}
+	return issues
+}
+
+func isUnresolved(trigger kedav1alpha1.ScaleTriggers) bool {
+	if trigger.AuthenticationRef != nil || trigger.TemplateRef != nil || len(trigger.MetadataValueFrom) > 0 {
+		return true
+	}
+	for key, value := range trigger.Metadata {
+		if strings.HasSuffix(key, "FromEnv") || strings.Contains(value, "{{") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestValidateTriggers(t *testing.T) {
+	selenium := map[string]string{"url": "https://selenium-hub:4444/graphql", "browserName": "chrome", "unsafeSsl": "ture"}
+	issues := ValidateTriggers("shop", "orders", []kedav1alpha1.ScaleTriggers{
+		{Type: "cron", Metadata: map[string]string{"timezone": "UTC", "start": "0 8 * * *", "end": "0 18 * * *", "desiredReplicas": "5"}},
+		{Type: "selenium-grid", Metadata: selenium},
+		{Type: "postgresql", Metadata: map[string]string{"query": "SELECT 1", "targetQueryValue": "1"}, AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "postgres"}},
+		{TemplateRef: &kedav1alpha1.TriggerTemplateRef{Name: "queue"}},
+		{Metadata: map[string]string{}},
+		{Type: "cron", Metadata: map[string]string{"timezone": "UTC", "start": "0 8 * * *", "end": "0 18 * * *", "desiredReplicas": "5"}, Transform: "value *"},
+	})
+
+	assert.Len(t, issues, 4)
+	assert.Equal(t, 1, issues[0].Index)
+	assert.Contains(t, issues[0].Message, "error parsing unsafeSsl")
+	assert.False(t, issues[0].Unresolved)
+	// the password of the authenticationRef isn't resolved offline
+	assert.Equal(t, 2, issues[1].Index)
+	assert.True(t, issues[1].Unresolved)
+	assert.Equal(t, TriggerIssue{Index: 4, Message: "the type of the trigger is not set"}, issues[2])
+	assert.Equal(t, 5, issues[3].Index)
+	assert.Contains(t, issues[3].String(), "spec.triggers[5] (cron): invalid transform")
+
+	// the triggers are parsed on a copy of their metadata
+	assert.Equal(t, "ture", selenium["unsafeSsl"])
+	assert.Len(t, selenium, 3)
+}